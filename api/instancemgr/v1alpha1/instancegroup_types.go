@@ -16,9 +16,14 @@ limitations under the License.
 package v1alpha1
 
 import (
+	"encoding/base64"
 	"fmt"
+	"net/url"
+	"path"
 	"reflect"
+	"regexp"
 	"strings"
+	"time"
 
 	"github.com/aws/aws-sdk-go/aws/arn"
 	"github.com/keikoproj/instance-manager/controllers/common"
@@ -26,8 +31,11 @@ import (
 
 	"github.com/pkg/errors"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/validation"
 	ctrl "sigs.k8s.io/controller-runtime"
 )
 
@@ -66,7 +74,17 @@ const (
 	EKSManagedProvisionerName = "eks-managed"
 	EKSFargateProvisionerName = "eks-fargate"
 
-	NodesReady InstanceGroupConditionType = "NodesReady"
+	NodesReady              InstanceGroupConditionType = "NodesReady"
+	Paused                  InstanceGroupConditionType = "Paused"
+	InsufficientPermissions InstanceGroupConditionType = "InsufficientPermissions"
+	ScalingActivityFailed   InstanceGroupConditionType = "ScalingActivityFailed"
+	DeprecatedInstanceType  InstanceGroupConditionType = "DeprecatedInstanceType"
+	EndpointUnreachable     InstanceGroupConditionType = "ClusterEndpointUnreachable"
+
+	// conditions reflecting the progress of creating the AWS resources of an instance group
+	RoleCreated                 InstanceGroupConditionType = "RoleCreated"
+	ScalingConfigurationCreated InstanceGroupConditionType = "ScalingConfigurationCreated"
+	ScalingGroupCreated         InstanceGroupConditionType = "ScalingGroupCreated"
 
 	ForbidConcurrencyPolicy  = "forbid"
 	AllowConcurrencyPolicy   = "allow"
@@ -81,6 +99,7 @@ const (
 
 	ImageLatestValue = "latest"
 	ImageSSMPrefix   = "ssm://"
+	ImageTagPrefix   = "tag:"
 )
 
 type ContainerRuntime string
@@ -93,7 +112,19 @@ const (
 	DockerRuntime     ContainerRuntime = "dockerd"
 	ContainerDRuntime ContainerRuntime = "containerd"
 
-	UpgradeLockedAnnotationKey = "instancemgr.keikoproj.io/lock-upgrades"
+	UpgradeLockedAnnotationKey     = "instancemgr.keikoproj.io/lock-upgrades"
+	MaintenanceWindowAnnotationKey = "instancemgr.keikoproj.io/maintenance-window"
+	PausedAnnotationKey            = "instancemgr.keikoproj.io/pause"
+	CacheBypassAnnotationKey       = "instancemgr.keikoproj.io/bypass-cache"
+
+	ManagedAmiTypeCustom             = "CUSTOM"
+	ManagedAmiTypeAmazonLinux2Prefix = "AL2_"
+	ManagedAmiTypeAL2023Prefix       = "AL2023_"
+	ManagedAmiTypeBottlerocketPrefix = "BOTTLEROCKET_"
+
+	// AWS limits for the update config of a managed node group
+	ManagedMaxUnavailableLimit           = 100
+	ManagedMaxUnavailablePercentageLimit = 100
 )
 
 var (
@@ -115,15 +146,30 @@ var (
 		},
 	}
 	DefaultCRDStrategyMaxRetries = 3
-
-	AllowedContainerRuntimes            = []ContainerRuntime{ContainerDRuntime, DockerRuntime}
-	AllowedFileSystemTypes              = []string{FileSystemTypeXFS, FileSystemTypeEXT4}
-	AllowedMixedPolicyStrategies        = []string{LaunchTemplateStrategyCapacityOptimized, LaunchTemplateStrategyLowestPrice}
-	AllowedInstancePools                = []string{SubFamilyFlexibleInstancePool}
-	LifecycleHookAllowedTransitions     = []string{LifecycleHookTransitionLaunch, LifecycleHookTransitionTerminate}
-	LifecycleHookAllowedDefaultResult   = []string{LifecycleHookResultAbandon, LifecycleHookResultContinue}
-	LaunchTemplatePlacementTenancyTypes = []string{HostPlacementTenancyType, DefaultPlacementTenancyType, DedicatedPlacementTenancyType}
-	log                                 = ctrl.Log.WithName("v1alpha1")
+	// DefaultDrainTimeout is how long a rolling update waits for evicted pods to terminate before terminating their nodes
+	DefaultDrainTimeout = 10 * time.Minute
+
+	AllowedContainerRuntimes              = []ContainerRuntime{ContainerDRuntime, DockerRuntime}
+	AllowedFileSystemTypes                = []string{FileSystemTypeXFS, FileSystemTypeEXT4}
+	AllowedMixedPolicyStrategies          = []string{LaunchTemplateStrategyCapacityOptimized, LaunchTemplateStrategyLowestPrice}
+	AllowedInstancePools                  = []string{SubFamilyFlexibleInstancePool}
+	AllowedWeightedCapacityUnits          = []string{WeightedCapacityUnitInstance, WeightedCapacityUnitVCPU}
+	LifecycleHookAllowedTransitions       = []string{LifecycleHookTransitionLaunch, LifecycleHookTransitionTerminate}
+	LifecycleHookAllowedDefaultResult     = []string{LifecycleHookResultAbandon, LifecycleHookResultContinue}
+	LifecycleHookNotificationServices     = []string{"sns", "sqs"}
+	LaunchTemplatePlacementTenancyTypes   = []string{HostPlacementTenancyType, DefaultPlacementTenancyType, DedicatedPlacementTenancyType}
+	AllowedHealthCheckTypes               = []string{HealthCheckTypeEC2, HealthCheckTypeELB}
+	AllowedTaintEffects                   = []string{string(corev1.TaintEffectNoSchedule), string(corev1.TaintEffectPreferNoSchedule), string(corev1.TaintEffectNoExecute)}
+	AllowedMetricsGranularities           = []string{MetricsGranularityOneMinute}
+	AllowedCapacityReservationPreferences = []string{CapacityReservationPreferenceOpen, CapacityReservationPreferenceNone}
+	AllowedReservedResources              = []string{"cpu", "memory", "ephemeral-storage", "pid"}
+	AllowedEvictionSignals                = []string{"memory.available", "nodefs.available", "nodefs.inodesFree", "imagefs.available", "imagefs.inodesFree", "pid.available"}
+	log                                   = ctrl.Log.WithName("v1alpha1")
+
+	// release versions of managed node groups are <kubernetes version>-<build date> for amazon linux AMI types, and
+	// <bottlerocket version>-<commit> for bottlerocket AMI types
+	AmazonLinuxReleaseVersionRegex  = regexp.MustCompile(`^\d+\.\d+\.\d+-(\d{8})$`)
+	BottlerocketReleaseVersionRegex = regexp.MustCompile(`^\d+\.\d+\.\d+-[0-9a-f]{8}$`)
 )
 
 // InstanceGroup is the Schema for the instancegroups API
@@ -160,16 +206,50 @@ type AwsUpgradeStrategy struct {
 	Type              string                 `json:"type,omitempty"`
 	CRDType           *CRDUpdateStrategy     `json:"crd,omitempty"`
 	RollingUpdateType *RollingUpdateStrategy `json:"rollingUpdate,omitempty"`
+	// InstanceRefresh configures instance refreshes started by the instance-refresh annotation
+	InstanceRefresh *InstanceRefreshStrategy `json:"instanceRefresh,omitempty"`
+}
+
+// InstanceRefreshStrategy controls how an instance refresh rolls through the scaling group
+type InstanceRefreshStrategy struct {
+	// CheckpointPercentages are the ascending percentages of replaced instances at which the refresh pauses
+	CheckpointPercentages []int64 `json:"checkpointPercentages,omitempty"`
+	// CheckpointDelay is the time in seconds to wait after reaching a checkpoint, 0 to 172800
+	CheckpointDelay int64 `json:"checkpointDelay,omitempty"`
 }
 
 type RollingUpdateStrategy struct {
 	MaxUnavailable *intstr.IntOrString `json:"maxUnavailable,omitempty"`
+	// BatchSize limits how many nodes are cordoned and drained concurrently before they are terminated
+	BatchSize *intstr.IntOrString `json:"batchSize,omitempty"`
+	// DrainTimeout is the number of seconds to wait for the evicted pods of a batch to terminate before its nodes are
+	// terminated regardless
+	DrainTimeout int `json:"drainTimeout,omitempty"`
 }
 
 func (s *RollingUpdateStrategy) GetMaxUnavailable() *intstr.IntOrString {
 	return s.MaxUnavailable
 }
 
+func (s *RollingUpdateStrategy) GetBatchSize() *intstr.IntOrString {
+	return s.BatchSize
+}
+
+func (s *RollingUpdateStrategy) SetBatchSize(value *intstr.IntOrString) {
+	s.BatchSize = value
+}
+
+func (s *RollingUpdateStrategy) GetDrainTimeout() time.Duration {
+	if s.DrainTimeout == 0 {
+		return DefaultDrainTimeout
+	}
+	return time.Duration(s.DrainTimeout) * time.Second
+}
+
+func (s *RollingUpdateStrategy) SetDrainTimeout(seconds int) {
+	s.DrainTimeout = seconds
+}
+
 func (s *RollingUpdateStrategy) SetMaxUnavailable(value *intstr.IntOrString) {
 	s.MaxUnavailable = value
 }
@@ -202,6 +282,31 @@ type EKSManagedSpec struct {
 type BootstrapOptions struct {
 	MaxPods          int64            `json:"maxPods,omitempty"`
 	ContainerRuntime ContainerRuntime `json:"containerRuntime,omitempty"`
+	// SystemReserved is rendered into the kubelet --system-reserved flag, e.g. cpu: 100m
+	SystemReserved map[string]string `json:"systemReserved,omitempty"`
+	// KubeReserved is rendered into the kubelet --kube-reserved flag, e.g. memory: 500Mi
+	KubeReserved map[string]string `json:"kubeReserved,omitempty"`
+	// EvictionHard is rendered into the kubelet --eviction-hard flag, e.g. memory.available: 100Mi
+	EvictionHard map[string]string `json:"evictionHard,omitempty"`
+	// UserDataShebang replaces the default #!/bin/bash line of the amazonlinux2 userData, and may be followed by
+	// additional preamble lines, e.g. set -euo pipefail
+	UserDataShebang string `json:"userDataShebang,omitempty"`
+	// CredentialProvider configures a kubelet image credential provider, e.g. to pull images from ECR in other accounts
+	CredentialProvider *CredentialProviderSpec `json:"credentialProvider,omitempty"`
+}
+
+// CredentialProviderSpec defines a kubelet image credential provider plugin
+type CredentialProviderSpec struct {
+	// Name is the file name of the provider binary, e.g. ecr-credential-provider
+	Name string `json:"name"`
+	// BinDir is the directory of the provider binary on amazonlinux2 and al2023 nodes, defaults to /etc/eks/image-credential-provider
+	BinDir string `json:"binDir,omitempty"`
+	// MatchImages are the image patterns the provider is invoked for, e.g. *.dkr.ecr.*.amazonaws.com
+	MatchImages []string `json:"matchImages"`
+	// DefaultCacheDuration is how long credentials are cached when the provider does not specify it, defaults to 12h
+	DefaultCacheDuration string `json:"defaultCacheDuration,omitempty"`
+	// Args are passed to the provider binary on amazonlinux2 and al2023 nodes
+	Args []string `json:"args,omitempty"`
 }
 
 type WarmPoolSpec struct {
@@ -215,48 +320,98 @@ type EKSSpec struct {
 	WarmPool         *WarmPoolSpec            `json:"warmPool,omitempty"`
 	Type             ScalingConfigurationType `json:"type,omitempty"`
 	EKSConfiguration *EKSConfiguration        `json:"configuration"`
+	// DependsOn lists instance groups, as name or namespace/name, whose nodes must be ready before this group is reconciled
+	DependsOn []string `json:"dependsOn,omitempty"`
+	// NodeReadinessTimeout is the time in seconds nodes may remain not ready before the instance group errors, overrides the controller default
+	NodeReadinessTimeout int64 `json:"nodeReadinessTimeout,omitempty"`
 }
 
 type EKSConfiguration struct {
-	EksClusterName              string                    `json:"clusterName,omitempty"`
-	KeyPairName                 string                    `json:"keyPairName,omitempty"`
-	Image                       string                    `json:"image,omitempty"`
-	InstanceType                string                    `json:"instanceType,omitempty"`
-	NodeSecurityGroups          []string                  `json:"securityGroups,omitempty"`
-	Volumes                     []NodeVolume              `json:"volumes,omitempty"`
-	Subnets                     []string                  `json:"subnets,omitempty"`
-	SuspendedProcesses          []string                  `json:"suspendProcesses,omitempty"`
-	BootstrapArguments          string                    `json:"bootstrapArguments,omitempty"`
-	BootstrapOptions            *BootstrapOptions         `json:"bootstrapOptions,omitempty"`
-	SpotPrice                   string                    `json:"spotPrice,omitempty"`
-	Tags                        []map[string]string       `json:"tags,omitempty"`
-	Labels                      map[string]string         `json:"labels,omitempty"`
-	Taints                      []corev1.Taint            `json:"taints,omitempty"`
-	UserData                    []UserDataStage           `json:"userData,omitempty"`
-	ExistingRoleName            string                    `json:"roleName,omitempty"`
-	ExistingInstanceProfileName string                    `json:"instanceProfileName,omitempty"`
-	ManagedPolicies             []string                  `json:"managedPolicies,omitempty"`
-	MetricsCollection           []string                  `json:"metricsCollection,omitempty"`
-	LifecycleHooks              []LifecycleHookSpec       `json:"lifecycleHooks,omitempty"`
-	MixedInstancesPolicy        *MixedInstancesPolicySpec `json:"mixedInstancesPolicy,omitempty"`
-	LicenseSpecifications       []string                  `json:"licenseSpecifications,omitempty"`
-	Placement                   *PlacementSpec            `json:"placement,omitempty"`
-	MetadataOptions             *MetadataOptions          `json:"metadataOptions,omitempty"`
+	EksClusterName                   string                    `json:"clusterName,omitempty"`
+	KeyPairName                      string                    `json:"keyPairName,omitempty"`
+	Image                            string                    `json:"image,omitempty"`
+	InstanceType                     string                    `json:"instanceType,omitempty"`
+	NodeSecurityGroups               []string                  `json:"securityGroups,omitempty"`
+	Volumes                          []NodeVolume              `json:"volumes,omitempty"`
+	Subnets                          []string                  `json:"subnets,omitempty"`
+	SuspendedProcesses               []string                  `json:"suspendProcesses,omitempty"`
+	BootstrapArguments               string                    `json:"bootstrapArguments,omitempty"`
+	BootstrapOptions                 *BootstrapOptions         `json:"bootstrapOptions,omitempty"`
+	SpotPrice                        string                    `json:"spotPrice,omitempty"`
+	SpotMarketOptions                bool                      `json:"spotMarketOptions,omitempty"`
+	Tags                             []map[string]string       `json:"tags,omitempty"`
+	Labels                           map[string]string         `json:"labels,omitempty"`
+	Taints                           []corev1.Taint            `json:"taints,omitempty"`
+	UserData                         []UserDataStage           `json:"userData,omitempty"`
+	ExistingRoleName                 string                    `json:"roleName,omitempty"`
+	ExistingInstanceProfileName      string                    `json:"instanceProfileName,omitempty"`
+	ExistingLaunchTemplateName       string                    `json:"launchTemplateName,omitempty"`
+	InstanceProfileNamePrefix        string                    `json:"instanceProfileNamePrefix,omitempty"`
+	ManagedPolicies                  []string                  `json:"managedPolicies,omitempty"`
+	MetricsCollection                []string                  `json:"metricsCollection,omitempty"`
+	MetricsGranularity               string                    `json:"metricsGranularity,omitempty"`
+	LifecycleHooks                   []LifecycleHookSpec       `json:"lifecycleHooks,omitempty"`
+	MixedInstancesPolicy             *MixedInstancesPolicySpec `json:"mixedInstancesPolicy,omitempty"`
+	LicenseSpecifications            []string                  `json:"licenseSpecifications,omitempty"`
+	Placement                        *PlacementSpec            `json:"placement,omitempty"`
+	MetadataOptions                  *MetadataOptions          `json:"metadataOptions,omitempty"`
+	CapacityReservationId            string                    `json:"capacityReservationId,omitempty"`
+	CapacityReservationPreference    string                    `json:"capacityReservationPreference,omitempty"`
+	CapacityRebalance                *bool                     `json:"capacityRebalance,omitempty"`
+	HealthCheckType                  string                    `json:"healthCheckType,omitempty"`
+	HealthCheckGracePeriod           int64                     `json:"healthCheckGracePeriod,omitempty"`
+	DefaultCooldown                  int64                     `json:"defaultCooldown,omitempty"`
+	TargetGroupARNs                  []string                  `json:"targetGroupARNs,omitempty"`
+	LoadBalancerNames                []string                  `json:"loadBalancerNames,omitempty"`
+	SourceDestCheck                  *bool                     `json:"sourceDestCheck,omitempty"`
+	NewInstancesProtectedFromScaleIn *bool                     `json:"newInstancesProtectedFromScaleIn,omitempty"`
+	// MaintenancePolicy is the scaling group's instance maintenance policy, it also applies to instance refreshes
+	MaintenancePolicy *MaintenancePolicySpec `json:"maintenancePolicy,omitempty"`
+	// ClusterCA is the base64 encoded cluster certificate authority passed to bootstrap, overrides the discovered value
+	ClusterCA string `json:"clusterCA,omitempty"`
+	// ApiServerEndpoint is the cluster API server URL passed to bootstrap, overrides the discovered value
+	ApiServerEndpoint string `json:"apiServerEndpoint,omitempty"`
+}
+
+// MaintenancePolicySpec controls the healthy capacity of a scaling group while instances are replaced
+type MaintenancePolicySpec struct {
+	// MinHealthyPercentage is the percentage of the desired capacity to keep in service, 0 to 100
+	MinHealthyPercentage *int64 `json:"minHealthyPercentage"`
+	// MaxHealthyPercentage is the percentage of the desired capacity which can be in service or pending, 100 to 200
+	MaxHealthyPercentage *int64 `json:"maxHealthyPercentage"`
 }
 
 const (
 	LaunchTemplateStrategyCapacityOptimized = "CapacityOptimized"
 	LaunchTemplateStrategyLowestPrice       = "LowestPrice"
 	SubFamilyFlexibleInstancePool           = "SubFamilyFlexible"
+	WeightedCapacityUnitInstance            = "Instance"
+	WeightedCapacityUnitVCPU                = "VCPU"
+
+	HealthCheckTypeEC2 = "EC2"
+	HealthCheckTypeELB = "ELB"
+
+	MetricsGranularityOneMinute = "1Minute"
+
+	CapacityReservationPreferenceOpen = "open"
+	CapacityReservationPreferenceNone = "none"
 )
 
 type MixedInstancesPolicySpec struct {
-	Strategy      *string             `json:"strategy,omitempty"`
-	SpotPools     *int64              `json:"spotPools,omitempty"`
-	BaseCapacity  *int64              `json:"baseCapacity,omitempty"`
-	SpotRatio     *intstr.IntOrString `json:"spotRatio,omitempty"`
-	InstancePool  *string             `json:"instancePool,omitempty"`
-	InstanceTypes []*InstanceTypeSpec `json:"instanceTypes,omitempty"`
+	Strategy     *string `json:"strategy,omitempty"`
+	SpotPools    *int64  `json:"spotPools,omitempty"`
+	BaseCapacity *int64  `json:"baseCapacity,omitempty"`
+	// BaseCapacityPercentage expresses the on-demand base capacity as a percentage of the group's desired capacity
+	BaseCapacityPercentage *int                `json:"baseCapacityPercentage,omitempty"`
+	SpotRatio              *intstr.IntOrString `json:"spotRatio,omitempty"`
+	InstancePool           *string             `json:"instancePool,omitempty"`
+	// InstancePoolFamilies groups the instance classes which can substitute each other in a SubFamilyFlexible pool,
+	// e.g. [m5, m5a, m5n], classes which are not part of a group are only pooled with their own class
+	InstancePoolFamilies [][]string          `json:"instancePoolFamilies,omitempty"`
+	InstanceTypes        []*InstanceTypeSpec `json:"instanceTypes,omitempty"`
+	// WeightedCapacityUnit is the unit the group's capacity is expressed in, with VCPU the overrides are weighted by
+	// their vCPU count and minSize, maxSize and baseCapacity are interpreted as vCPUs rather than instances
+	WeightedCapacityUnit *string `json:"weightedCapacityUnit,omitempty"`
 }
 
 type PlacementSpec struct {
@@ -269,19 +424,31 @@ type MetadataOptions struct {
 	HttpEndpoint    string `json:"httpEndpoint,omitempty"`
 	HttpTokens      string `json:"httpTokens,omitempty"`
 	HttpPutHopLimit int64  `json:"httpPutHopLimit,omitempty"`
+	// InstanceMetadataTags exposes instance tags via the instance metadata service, only valid for LaunchTemplates
+	InstanceMetadataTags bool `json:"instanceMetadataTags,omitempty"`
 }
 
 type InstanceTypeSpec struct {
 	Type   string `json:"type"`
 	Weight int64  `json:"weight,omitempty"`
+	// Image overrides the group's AMI for this instance type, e.g. an arm64 AMI for graviton types
+	Image string `json:"image,omitempty"`
 }
 
 const (
-	LifecycleHookResultAbandon           = "ABANDON"
-	LifecycleHookResultContinue          = "CONTINUE"
-	LifecycleHookTransitionLaunch        = "Launch"
-	LifecycleHookTransitionTerminate     = "Terminate"
-	LifecycleHookDefaultHeartbeatTimeout = 300
+	MetadataOptionEnabled  = "enabled"
+	MetadataOptionDisabled = "disabled"
+)
+
+const (
+	LifecycleHookResultAbandon              = "ABANDON"
+	LifecycleHookResultContinue             = "CONTINUE"
+	LifecycleHookTransitionLaunch           = "Launch"
+	LifecycleHookTransitionTerminate        = "Terminate"
+	LifecycleHookDefaultHeartbeatTimeout    = 300
+	LifecycleHookDefaultInstanceIdParameter = "InstanceId"
+	LifecycleHookMinHeartbeatTimeout        = 30
+	LifecycleHookMaxHeartbeatTimeout        = 172800
 )
 
 type LifecycleHookSpec struct {
@@ -292,12 +459,26 @@ type LifecycleHookSpec struct {
 	NotificationArn  string `json:"notificationArn,omitempty"`
 	Metadata         string `json:"metadata,omitempty"`
 	RoleArn          string `json:"roleArn,omitempty"`
+	// SSMDocument is an SSM automation document executed for every instance entering the hook's transition, the
+	// lifecycle action of the instance is completed once the execution ends
+	SSMDocument *LifecycleHookDocument `json:"ssmDocument,omitempty"`
+}
+
+type LifecycleHookDocument struct {
+	Name       string              `json:"name"`
+	Version    string              `json:"version,omitempty"`
+	Parameters map[string][]string `json:"parameters,omitempty"`
+	// InstanceIdParameter is the document parameter which receives the ID of the instance, defaults to InstanceId
+	InstanceIdParameter string `json:"instanceIdParameter,omitempty"`
 }
 
 type UserDataStage struct {
 	Name  string `json:"name,omitempty"`
 	Stage string `json:"stage"`
 	Data  string `json:"data"`
+	// Priority orders stages within the same boot stage, lower priorities are rendered first and stages with an equal
+	// priority keep their spec order
+	Priority int `json:"priority,omitempty"`
 }
 
 type NodeVolume struct {
@@ -324,6 +505,8 @@ type EKSFargateSpec struct {
 	Subnets             []string              `json:"subnets,omitempty"`
 	Selectors           []EKSFargateSelectors `json:"selectors"`
 	Tags                []map[string]string   `json:"tags,omitempty"`
+	// ManagedPolicies are additional managed policy names or ARNs attached to the default pod execution role
+	ManagedPolicies []string `json:"managedPolicies,omitempty"`
 }
 
 type EKSManagedConfiguration struct {
@@ -339,6 +522,14 @@ type EKSManagedConfiguration struct {
 	AmiType            string              `json:"amiType,omitempty"`
 	ReleaseVersion     string              `json:"releaseVersion,omitempty"`
 	Version            string              `json:"version,omitempty"`
+	Taints             []corev1.Taint      `json:"taints,omitempty"`
+	// UpdateConfig controls how many nodes EKS may replace at a time during managed node group updates
+	UpdateConfig *EKSManagedUpdateConfig `json:"updateConfig,omitempty"`
+}
+
+type EKSManagedUpdateConfig struct {
+	MaxUnavailable           int64 `json:"maxUnavailable,omitempty"`
+	MaxUnavailablePercentage int64 `json:"maxUnavailablePercentage,omitempty"`
 }
 
 type EKSFargateSelectors struct {
@@ -346,6 +537,25 @@ type EKSFargateSelectors struct {
 	Labels    map[string]string `json:"labels,omitempty"`
 }
 
+const (
+	// MaxStateHistory is the number of most recent state transitions retained in status
+	MaxStateHistory = 10
+
+	// AWS limits for selectors of a fargate profile
+	FargateMaxSelectors      = 5
+	FargateMaxSelectorLabels = 5
+
+	// AWS limits for tags of a resource
+	TagMaxCount       = 50
+	TagKeyMaxLength   = 128
+	TagValueMaxLength = 256
+	TagReservedPrefix = "aws:"
+
+	// IAM resources are limited to 50 tags, 3 of which are used by the controller to identify the instance group, further
+	// custom tags are not applied to IAM resources
+	IAMTagMaxCount = 47
+)
+
 // InstanceGroupStatus defines the schema of resource Status
 type InstanceGroupStatus struct {
 	CurrentState                  string                   `json:"currentState,omitempty"`
@@ -359,23 +569,75 @@ type InstanceGroupStatus struct {
 	StrategyResourceName          string                   `json:"strategyResourceName,omitempty"`
 	StrategyResourceNamespace     string                   `json:"strategyResourceNamespace,omitempty"`
 	StrategyRetryCount            int                      `json:"strategyRetryCount,omitempty"`
+	InstanceRefreshId             string                   `json:"instanceRefreshId,omitempty"`
 	UsingSpotRecommendation       bool                     `json:"usingSpotRecommendation,omitempty"`
 	Lifecycle                     string                   `json:"lifecycle,omitempty"`
 	ConfigHash                    string                   `json:"configMD5,omitempty"`
+	DriftDetected                 bool                     `json:"driftDetected,omitempty"`
+	AppliedGeneration             int64                    `json:"appliedGeneration,omitempty"`
+	SpotInterruptions             int                      `json:"spotInterruptions,omitempty"`
+	LastSpotInterruptionTime      *metav1.Time             `json:"lastSpotInterruptionTime,omitempty"`
+	RenderedUserDataHash          string                   `json:"renderedUserDataHash,omitempty"`
+	LastForceUpgradeToken         string                   `json:"lastForceUpgradeToken,omitempty"`
+	NodesNotReadySince            *metav1.Time             `json:"nodesNotReadySince,omitempty"`
 	Conditions                    []InstanceGroupCondition `json:"conditions,omitempty"`
 	Provisioner                   string                   `json:"provisioner,omitempty"`
 	Strategy                      string                   `json:"strategy,omitempty"`
+	StateHistory                  []StateTransition        `json:"stateHistory,omitempty"`
+	// AttachedTargetGroupARNs are the target groups attached by the controller, only these are detached when removed from the spec
+	AttachedTargetGroupARNs []string `json:"attachedTargetGroupARNs,omitempty"`
+	// AttachedLoadBalancerNames are the load balancers attached by the controller, only these are detached when removed from the spec
+	AttachedLoadBalancerNames []string `json:"attachedLoadBalancerNames,omitempty"`
+	// InstanceProfileName is the instance-profile created by the controller
+	InstanceProfileName string `json:"instanceProfileName,omitempty"`
+	// RetiredInstanceProfileNames are instance-profiles created by the controller under a previous name, they are deleted
+	// once the group's nodes no longer use them
+	RetiredInstanceProfileNames []string `json:"retiredInstanceProfileNames,omitempty"`
+	// WeightedCapacityUnit is the unit the capacity of the scaling group was created with, it cannot be changed while the
+	// scaling group exists
+	WeightedCapacityUnit string `json:"weightedCapacityUnit,omitempty"`
+	// DumpedUserDataHash is the hash of the rendered userData last published as an event
+	DumpedUserDataHash string `json:"dumpedUserDataHash,omitempty"`
+	// RetiredLaunchTemplateNames are launch templates created by the controller before switching to an existing launch
+	// template, they are deleted once the scaling group no longer references them
+	RetiredLaunchTemplateNames []string `json:"retiredLaunchTemplateNames,omitempty"`
+	// LastSpotInterruptionUIDs are the UIDs of the counted spot interruption events observed at LastSpotInterruptionTime
+	LastSpotInterruptionUIDs []string `json:"lastSpotInterruptionUIDs,omitempty"`
+}
+
+// StateTransition records a change of the reconcile state of an InstanceGroup
+type StateTransition struct {
+	State     ReconcileState `json:"state,omitempty"`
+	Timestamp metav1.Time    `json:"timestamp,omitempty"`
+	Reason    string         `json:"reason,omitempty"`
 }
 
 type InstanceGroupConditionType string
 
 type ValidationOverrides struct {
 	scalingConfigurationOverride *ScalingConfigurationType
+	// defaultVolumesOverride replaces the default root volume of instance groups which do not specify volumes
+	defaultVolumesOverride []NodeVolume
+	// instanceProfileNamePrefixOverride is the instance-profile name prefix of instance groups which do not specify one
+	instanceProfileNamePrefixOverride string
 }
 
-func NewValidationOverrides(defaultScalingConfiguration *ScalingConfigurationType) *ValidationOverrides {
+func NewValidationOverrides(defaultScalingConfiguration *ScalingConfigurationType, defaultVolumes []NodeVolume, defaultProfileNamePrefix string) *ValidationOverrides {
 	return &ValidationOverrides{
-		scalingConfigurationOverride: defaultScalingConfiguration,
+		scalingConfigurationOverride:      defaultScalingConfiguration,
+		defaultVolumesOverride:            defaultVolumes,
+		instanceProfileNamePrefixOverride: defaultProfileNamePrefix,
+	}
+}
+
+// DefaultVolumes returns the volumes used when an instance group does not specify any
+func DefaultVolumes() []NodeVolume {
+	return []NodeVolume{
+		{
+			Name: "/dev/xvda",
+			Type: "gp2",
+			Size: 32,
+		},
 	}
 }
 func NewInstanceGroupCondition(cType InstanceGroupConditionType, status corev1.ConditionStatus) InstanceGroupCondition {
@@ -389,6 +651,8 @@ func NewInstanceGroupCondition(cType InstanceGroupConditionType, status corev1.C
 type InstanceGroupCondition struct {
 	Type   InstanceGroupConditionType `json:"type,omitempty"`
 	Status corev1.ConditionStatus     `json:"status,omitempty"`
+	// Message is a human readable explanation of the condition
+	Message string `json:"message,omitempty"`
 }
 
 func (ig *InstanceGroup) GetEKSConfiguration() *EKSConfiguration {
@@ -400,6 +664,26 @@ func (ig *InstanceGroup) GetEKSSpec() *EKSSpec {
 func (ig *InstanceGroup) NamespacedName() string {
 	return fmt.Sprintf("%v/%v", ig.GetNamespace(), ig.GetName())
 }
+
+// GetDependencies returns the instance groups which must have ready nodes before the instance group is reconciled,
+// dependencies without a namespace are in the instance group's namespace
+func (ig *InstanceGroup) GetDependencies() []types.NamespacedName {
+	spec := ig.GetEKSSpec()
+	if spec == nil {
+		return nil
+	}
+
+	dependencies := make([]types.NamespacedName, 0, len(spec.DependsOn))
+	for _, dependency := range spec.DependsOn {
+		name := types.NamespacedName{Namespace: ig.GetNamespace(), Name: dependency}
+		if parts := strings.SplitN(dependency, "/", 2); len(parts) == 2 {
+			name = types.NamespacedName{Namespace: parts[0], Name: parts[1]}
+		}
+		dependencies = append(dependencies, name)
+	}
+	return dependencies
+}
+
 func (ig *InstanceGroup) GetStatus() *InstanceGroupStatus {
 	return &ig.Status
 }
@@ -419,6 +703,139 @@ func (ig *InstanceGroup) Locked() bool {
 	return false
 }
 
+// Paused returns true if reconciliation of the instance group is suspended by the pause annotation
+func (ig *InstanceGroup) Paused() bool {
+	return strings.EqualFold(ig.GetAnnotations()[PausedAnnotationKey], "true")
+}
+
+// IsCacheBypassed returns true if reconciles of the instance group should not use cached AWS API responses
+func (ig *InstanceGroup) IsCacheBypassed() bool {
+	return strings.EqualFold(ig.GetAnnotations()[CacheBypassAnnotationKey], "true")
+}
+
+// InMaintenanceWindow returns true if disruptive updates are allowed at time t, which is always the case
+// when a maintenance window is not set or cannot be parsed
+func (ig *InstanceGroup) InMaintenanceWindow(t time.Time) bool {
+	annotations := ig.GetAnnotations()
+	val, ok := annotations[MaintenanceWindowAnnotationKey]
+	if !ok || common.StringEmpty(val) {
+		return true
+	}
+	window, err := ParseMaintenanceWindow(val)
+	if err != nil {
+		return true
+	}
+	return window.Contains(t)
+}
+
+// MaintenanceWindow is a daily UTC time range, optionally limited to specific days of the week
+// +kubebuilder:object:generate=false
+type MaintenanceWindow struct {
+	Days  map[time.Weekday]bool
+	Start time.Duration
+	End   time.Duration
+}
+
+var maintenanceWindowDays = map[string]time.Weekday{
+	"sun": time.Sunday,
+	"mon": time.Monday,
+	"tue": time.Tuesday,
+	"wed": time.Wednesday,
+	"thu": time.Thursday,
+	"fri": time.Friday,
+	"sat": time.Saturday,
+}
+
+// ParseMaintenanceWindow parses a window in the form '[days] HH:MM-HH:MM', where days is a comma separated list
+// of days or day ranges, e.g. 'Mon-Fri 22:00-02:00' or 'Sat,Sun 00:00-06:00'
+func ParseMaintenanceWindow(spec string) (*MaintenanceWindow, error) {
+	var (
+		window = &MaintenanceWindow{
+			Days: make(map[time.Weekday]bool),
+		}
+		timeRange string
+	)
+
+	fields := strings.Fields(spec)
+	switch len(fields) {
+	case 1:
+		timeRange = fields[0]
+	case 2:
+		timeRange = fields[1]
+		for _, part := range strings.Split(fields[0], ",") {
+			days := strings.Split(part, "-")
+			if len(days) > 2 {
+				return nil, errors.Errorf("invalid day range '%v'", part)
+			}
+			from, ok := maintenanceWindowDays[strings.ToLower(days[0])]
+			if !ok {
+				return nil, errors.Errorf("invalid day '%v'", days[0])
+			}
+			to := from
+			if len(days) == 2 {
+				if to, ok = maintenanceWindowDays[strings.ToLower(days[1])]; !ok {
+					return nil, errors.Errorf("invalid day '%v'", days[1])
+				}
+			}
+			for d := from; ; d = (d + 1) % 7 {
+				window.Days[d] = true
+				if d == to {
+					break
+				}
+			}
+		}
+	default:
+		return nil, errors.Errorf("expected '[days] HH:MM-HH:MM', got '%v'", spec)
+	}
+
+	times := strings.Split(timeRange, "-")
+	if len(times) != 2 {
+		return nil, errors.Errorf("invalid time range '%v', expected HH:MM-HH:MM", timeRange)
+	}
+	for i, s := range times {
+		t, err := time.Parse("15:04", s)
+		if err != nil {
+			return nil, errors.Errorf("invalid time '%v', expected HH:MM", s)
+		}
+		offset := time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute
+		if i == 0 {
+			window.Start = offset
+		} else {
+			window.End = offset
+		}
+	}
+	return window, nil
+}
+
+// Contains returns true if t is within the maintenance window, windows ending before they start cross midnight
+// and are matched against the day they started on
+func (w *MaintenanceWindow) Contains(t time.Time) bool {
+	var (
+		utc    = t.UTC()
+		day    = utc.Weekday()
+		offset = time.Duration(utc.Hour())*time.Hour + time.Duration(utc.Minute())*time.Minute
+	)
+
+	dayAllowed := func(d time.Weekday) bool {
+		return len(w.Days) == 0 || w.Days[d]
+	}
+
+	switch {
+	case w.Start < w.End:
+		return dayAllowed(day) && offset >= w.Start && offset < w.End
+	case w.Start > w.End:
+		if offset >= w.Start {
+			return dayAllowed(day)
+		}
+		if offset < w.End {
+			return dayAllowed((day + 6) % 7)
+		}
+		return false
+	default:
+		return dayAllowed(day)
+	}
+}
+
 func (s *EKSSpec) Validate(overrides *ValidationOverrides) error {
 	var (
 		configuration = s.EKSConfiguration
@@ -455,6 +872,20 @@ func (s *EKSSpec) Validate(overrides *ValidationOverrides) error {
 				return errors.Errorf("validation failed, field 'availabilityZone' is only valid for LaunchTemplates")
 			}
 		}
+		if s.EKSConfiguration.GetMetadataOptions() != nil && s.EKSConfiguration.GetMetadataOptions().InstanceMetadataTags {
+			return errors.Errorf("validation failed, field 'instanceMetadataTags' is only valid for LaunchTemplates")
+		}
+		if !common.StringEmpty(s.EKSConfiguration.CapacityReservationId) || !common.StringEmpty(s.EKSConfiguration.CapacityReservationPreference) {
+			return errors.Errorf("validation failed, capacity reservations are only valid for LaunchTemplates")
+		}
+		if !common.StringEmpty(s.EKSConfiguration.ExistingLaunchTemplateName) {
+			return errors.Errorf("validation failed, field 'launchTemplateName' is only valid for LaunchTemplates")
+		}
+	}
+
+	// launch templates only request spot instances at spotPrice when opted in with spotMarketOptions
+	if s.IsLaunchTemplate() && configuration.MixedInstancesPolicy == nil && !common.StringEmpty(configuration.SpotPrice) && !configuration.SpotMarketOptions {
+		return errors.Errorf("validation failed, field 'spotPrice' is only valid for LaunchConfigurations, use 'mixedInstancesPolicy.spotRatio' with LaunchTemplates")
 	}
 
 	for _, v := range configuration.Volumes {
@@ -479,6 +910,17 @@ func (s *EKSSpec) Validate(overrides *ValidationOverrides) error {
 		}
 	}
 
+	if s.NodeReadinessTimeout < 0 {
+		return errors.Errorf("validation failed, 'nodeReadinessTimeout' must be non-negative, got '%v'", s.NodeReadinessTimeout)
+	}
+
+	for _, dependency := range s.DependsOn {
+		parts := strings.Split(dependency, "/")
+		if len(parts) > 2 || common.ContainsString(parts, "") {
+			return errors.Errorf("validation failed, 'dependsOn' entry '%v' must be a name or namespace/name", dependency)
+		}
+	}
+
 	if s.HasWarmPool() {
 		if configuration.MixedInstancesPolicy != nil {
 			return errors.Errorf("validation failed, cannot use warmPool with MixedInstancesPolicy")
@@ -521,6 +963,25 @@ func contains(s []ContainerRuntime, e ContainerRuntime) bool {
 	return false
 }
 
+// validateTaints returns an error if a taint has no key or an unknown effect, a key may be used with multiple effects but
+// only once per effect
+func validateTaints(taints []corev1.Taint) error {
+	for i, t := range taints {
+		if common.StringEmpty(t.Key) {
+			return errors.Errorf("validation failed, 'taints' must have a key")
+		}
+		if !common.ContainsString(AllowedTaintEffects, string(t.Effect)) {
+			return errors.Errorf("validation failed, effect of taint '%v' must be one of %+v", t.Key, AllowedTaintEffects)
+		}
+		for _, other := range taints[:i] {
+			if t.MatchTaint(&other) {
+				return errors.Errorf("validation failed, taint '%v' with effect '%v' must be unique", t.Key, t.Effect)
+			}
+		}
+	}
+	return nil
+}
+
 func (c *EKSConfiguration) Validate() error {
 	if common.StringEmpty(c.EksClusterName) {
 		return errors.Errorf("validation failed, 'clusterName' is a required parameter")
@@ -542,21 +1003,99 @@ func (c *EKSConfiguration) Validate() error {
 		c.MetricsCollection = metrics
 	}
 
-	for _, m := range c.SuspendedProcesses {
-		processes := make([]string, 0)
-		if strings.EqualFold(m, "all") {
-			continue
-		}
-		if common.ContainsString(awsprovider.DefaultSuspendProcesses, m) {
-			processes = append(processes, m)
-		}
-		c.SuspendedProcesses = processes
+	if len(c.SuspendedProcesses) > 0 {
+		c.SuspendedProcesses = NormalizeSuspendProcesses(c.SuspendedProcesses)
+	}
+
+	if err := validateTaints(c.Taints); err != nil {
+		return err
 	}
 
 	if c.BootstrapOptions != nil {
 		if c.BootstrapOptions.ContainerRuntime != "" && !contains(AllowedContainerRuntimes, c.BootstrapOptions.ContainerRuntime) {
 			return errors.Errorf("validation failed, 'bootstrapOptions.containerRuntime' must be one of %+v", AllowedContainerRuntimes)
 		}
+		if err := c.BootstrapOptions.Validate(); err != nil {
+			return err
+		}
+	}
+
+	if !common.StringEmpty(c.HealthCheckType) {
+		if !common.ContainsEqualFold(AllowedHealthCheckTypes, c.HealthCheckType) {
+			return errors.Errorf("validation failed, 'healthCheckType' must be one of %+v", AllowedHealthCheckTypes)
+		}
+		c.HealthCheckType = strings.ToUpper(c.HealthCheckType)
+	}
+
+	for _, tg := range c.TargetGroupARNs {
+		if !IsTargetGroupARN(tg) {
+			return errors.Errorf("validation failed, '%v' in 'targetGroupARNs' must be a valid target group ARN", tg)
+		}
+	}
+
+	for _, lb := range c.LoadBalancerNames {
+		if common.StringEmpty(lb) {
+			return errors.Errorf("validation failed, 'loadBalancerNames' must not contain empty values")
+		}
+	}
+
+	if len(c.Tags) > TagMaxCount {
+		return errors.Errorf("validation failed, 'tags' cannot contain more than %v tags", TagMaxCount)
+	}
+	for _, tag := range c.Tags {
+		if err := ValidateTag(tag["key"], tag["value"]); err != nil {
+			return errors.Errorf("validation failed, %v", err)
+		}
+	}
+
+	if c.HealthCheckGracePeriod < 0 {
+		return errors.Errorf("validation failed, 'healthCheckGracePeriod' must be a positive value")
+	}
+
+	if c.DefaultCooldown < 0 {
+		return errors.Errorf("validation failed, 'defaultCooldown' must be a positive value")
+	}
+
+	if c.MaintenancePolicy != nil {
+		if err := c.MaintenancePolicy.Validate(); err != nil {
+			return err
+		}
+	}
+
+	if !common.StringEmpty(c.ClusterCA) {
+		if _, err := base64.StdEncoding.DecodeString(c.ClusterCA); err != nil {
+			return errors.Errorf("validation failed, 'clusterCA' must be base64 encoded")
+		}
+	}
+
+	if !common.StringEmpty(c.ApiServerEndpoint) {
+		u, err := url.Parse(c.ApiServerEndpoint)
+		if err != nil || !strings.EqualFold(u.Scheme, "https") || common.StringEmpty(u.Host) {
+			return errors.Errorf("validation failed, 'apiServerEndpoint' must be a valid https URL, got '%v'", c.ApiServerEndpoint)
+		}
+	}
+
+	if common.StringEmpty(c.MetricsGranularity) {
+		c.MetricsGranularity = MetricsGranularityOneMinute
+	}
+	if !common.ContainsEqualFold(AllowedMetricsGranularities, c.MetricsGranularity) {
+		return errors.Errorf("validation failed, 'metricsGranularity' must be one of %+v", AllowedMetricsGranularities)
+	}
+
+	if !common.StringEmpty(c.CapacityReservationId) && !common.StringEmpty(c.CapacityReservationPreference) {
+		return errors.Errorf("validation failed, 'capacityReservationId' and 'capacityReservationPreference' are mutually exclusive")
+	}
+	if !common.StringEmpty(c.CapacityReservationPreference) {
+		if !common.ContainsEqualFold(AllowedCapacityReservationPreferences, c.CapacityReservationPreference) {
+			return errors.Errorf("validation failed, 'capacityReservationPreference' must be one of %+v", AllowedCapacityReservationPreferences)
+		}
+		c.CapacityReservationPreference = strings.ToLower(c.CapacityReservationPreference)
+	}
+
+	if c.MetadataOptions != nil && c.MetadataOptions.InstanceMetadataTags {
+		if strings.EqualFold(c.MetadataOptions.HttpEndpoint, MetadataOptionDisabled) {
+			return errors.Errorf("validation failed, 'instanceMetadataTags' requires 'httpEndpoint' to be enabled")
+		}
 	}
 
 	hooks := []LifecycleHookSpec{}
@@ -564,6 +1103,9 @@ func (c *EKSConfiguration) Validate() error {
 		if h.HeartbeatTimeout == 0 {
 			h.HeartbeatTimeout = LifecycleHookDefaultHeartbeatTimeout
 		}
+		if h.HeartbeatTimeout < LifecycleHookMinHeartbeatTimeout || h.HeartbeatTimeout > LifecycleHookMaxHeartbeatTimeout {
+			return errors.Errorf("validation failed, 'heartbeatTimeout' of lifecycle hook '%v' must be between %v and %v seconds", h.Name, LifecycleHookMinHeartbeatTimeout, LifecycleHookMaxHeartbeatTimeout)
+		}
 		if common.StringEmpty(h.DefaultResult) {
 			h.DefaultResult = LifecycleHookResultAbandon
 		}
@@ -583,11 +1125,33 @@ func (c *EKSConfiguration) Validate() error {
 		if common.StringEmpty(h.Name) {
 			return errors.Errorf("validation failed, 'name' is a required parameter")
 		}
-		if !common.StringEmpty(h.NotificationArn) && !arn.IsARN(h.NotificationArn) {
-			return errors.Errorf("validation failed, 'notificationArn' must be a valid IAM role ARN")
+		for _, existing := range hooks {
+			if existing.Name == h.Name {
+				return errors.Errorf("validation failed, lifecycle hook name '%v' must be unique", h.Name)
+			}
+		}
+		if !common.StringEmpty(h.NotificationArn) {
+			notificationArn, err := arn.Parse(h.NotificationArn)
+			if err != nil || !common.ContainsEqualFold(LifecycleHookNotificationServices, notificationArn.Service) {
+				return errors.Errorf("validation failed, 'notificationArn' of lifecycle hook '%v' must be a valid SNS topic or SQS queue ARN, got '%v'", h.Name, h.NotificationArn)
+			}
+		}
+		if !common.StringEmpty(h.RoleArn) {
+			roleArn, err := arn.Parse(h.RoleArn)
+			if err != nil || roleArn.Service != "iam" {
+				return errors.Errorf("validation failed, 'roleArn' of lifecycle hook '%v' must be a valid IAM role ARN, got '%v'", h.Name, h.RoleArn)
+			}
 		}
-		if !common.StringEmpty(h.RoleArn) && !arn.IsARN(h.NotificationArn) {
-			return errors.Errorf("validation failed, 'roleArn' must be a valid IAM role ARN")
+		if doc := h.SSMDocument; doc != nil {
+			if common.StringEmpty(doc.Name) {
+				return errors.Errorf("validation failed, 'ssmDocument.name' of lifecycle hook '%v' is a required parameter", h.Name)
+			}
+			if common.StringEmpty(doc.InstanceIdParameter) {
+				doc.InstanceIdParameter = LifecycleHookDefaultInstanceIdParameter
+			}
+			if _, ok := doc.Parameters[doc.InstanceIdParameter]; ok {
+				return errors.Errorf("validation failed, 'ssmDocument.parameters' of lifecycle hook '%v' cannot contain the instance ID parameter '%v'", h.Name, doc.InstanceIdParameter)
+			}
 		}
 		hooks = append(hooks, h)
 	}
@@ -596,14 +1160,24 @@ func (c *EKSConfiguration) Validate() error {
 	if common.StringEmpty(c.Image) {
 		return errors.Errorf("validation failed, 'image' is a required parameter")
 	}
+	if strings.HasPrefix(c.Image, ImageTagPrefix) {
+		if key, _, ok := strings.Cut(strings.TrimPrefix(c.Image, ImageTagPrefix), "="); !ok || common.StringEmpty(key) {
+			return errors.Errorf("validation failed, 'image' must be in the form tag:key=value to resolve an AMI by tag, got '%v'", c.Image)
+		}
+	}
 	if common.StringEmpty(c.InstanceType) {
 		return errors.Errorf("validation failed, 'instanceType' is a required parameter")
 	}
-	if common.StringEmpty(c.KeyPairName) {
-		return errors.Errorf("validation failed, 'keyPair' is a required parameter")
+	if len(c.InstanceProfileNamePrefix) >= awsprovider.MaxInstanceProfileNameLength {
+		return errors.Errorf("validation failed, 'instanceProfileNamePrefix' must be less than %v characters", awsprovider.MaxInstanceProfileNameLength)
 	}
 
+	deviceNames := make(map[string]bool)
 	for _, v := range c.Volumes {
+		if deviceNames[v.Name] {
+			return errors.Errorf("validation failed, volume device name '%v' must be unique", v.Name)
+		}
+		deviceNames[v.Name] = true
 
 		if v.Iops != 0 && !common.ContainsEqualFold(awsprovider.AllowedVolumeTypesWithProvisionedIOPS, v.Type) {
 			return errors.Errorf("cannot apply IOPS configuration for volumeType '%v', only types '%v' supported", v.Type, awsprovider.AllowedVolumeTypesWithProvisionedIOPS)
@@ -620,16 +1194,13 @@ func (c *EKSConfiguration) Validate() error {
 	}
 
 	if len(c.Volumes) == 0 {
-		c.Volumes = []NodeVolume{
-			{
-				Name: "/dev/xvda",
-				Type: "gp2",
-				Size: 32,
-			},
-		}
+		c.Volumes = DefaultVolumes()
 	}
 
 	if c.MixedInstancesPolicy != nil {
+		if !common.StringEmpty(c.SpotPrice) {
+			return errors.Errorf("validation failed, cannot use spotPrice with mixedInstancesPolicy, use 'mixedInstancesPolicy.spotRatio' instead")
+		}
 		if err := c.MixedInstancesPolicy.Validate(); err != nil {
 			return err
 		}
@@ -650,6 +1221,20 @@ func (c *EKSConfiguration) Validate() error {
 	return nil
 }
 
+// ValidateTag returns an error if a tag does not meet the AWS limits for tag keys and values
+func ValidateTag(key, value string) error {
+	if common.StringEmpty(key) || len(key) > TagKeyMaxLength {
+		return errors.Errorf("tag key '%v' must be between 1 and %v characters", key, TagKeyMaxLength)
+	}
+	if strings.HasPrefix(strings.ToLower(key), TagReservedPrefix) {
+		return errors.Errorf("tag key '%v' cannot start with the reserved prefix '%v'", key, TagReservedPrefix)
+	}
+	if len(value) > TagValueMaxLength {
+		return errors.Errorf("value of tag '%v' cannot be longer than %v characters", key, TagValueMaxLength)
+	}
+	return nil
+}
+
 func (p *PlacementSpec) Validate() error {
 
 	if p == nil {
@@ -693,11 +1278,58 @@ func (m *MixedInstancesPolicySpec) Validate() error {
 			return errors.Errorf("validation failed, can only use spotPools with LowestPrice strategy")
 		}
 	}
+	if m.BaseCapacityPercentage != nil {
+		if m.BaseCapacity != nil {
+			return errors.Errorf("validation failed, mixedInstancesPolicy.baseCapacity and mixedInstancesPolicy.baseCapacityPercentage are mutually exclusive")
+		}
+		if pct := *m.BaseCapacityPercentage; pct < 0 || pct > 100 {
+			return errors.Errorf("validation failed, mixedInstancesPolicy.baseCapacityPercentage must be between 0 and 100, got '%v'", pct)
+		}
+	}
+	if len(m.InstancePoolFamilies) > 0 {
+		if m.InstancePool == nil {
+			return errors.Errorf("validation failed, 'instancePoolFamilies' can only be used with 'instancePool'")
+		}
+		seen := make(map[string]bool)
+		for i, family := range m.InstancePoolFamilies {
+			if len(family) == 0 {
+				return errors.Errorf("validation failed, 'instancePoolFamilies[%d]' must not be empty", i)
+			}
+			for _, class := range family {
+				class = strings.ToLower(class)
+				if common.StringEmpty(class) || strings.Contains(class, ".") {
+					return errors.Errorf("validation failed, 'instancePoolFamilies[%d]' must contain instance classes, e.g. m5, got '%v'", i, class)
+				}
+				if seen[class] {
+					return errors.Errorf("validation failed, instance class '%v' can only be part of a single instance pool family", class)
+				}
+				seen[class] = true
+			}
+		}
+	}
+
+	if m.WeightedCapacityUnit != nil {
+		unit := common.StringValue(m.WeightedCapacityUnit)
+		if !common.ContainsEqualFold(AllowedWeightedCapacityUnits, unit) {
+			return errors.Errorf("validation failed, mixedInstancesPolicy.weightedCapacityUnit must be one of %+v, got '%v'", AllowedWeightedCapacityUnits, unit)
+		}
+		if strings.EqualFold(unit, WeightedCapacityUnitVCPU) {
+			for _, t := range m.InstanceTypes {
+				if t.Weight > 1 {
+					return errors.Errorf("validation failed, 'weight' of instance type '%v' cannot be used with weightedCapacityUnit '%v'", t.Type, unit)
+				}
+			}
+		}
+	}
+
 	if m.InstanceTypes != nil {
 		for _, t := range m.InstanceTypes {
 			if t.Weight == 0 {
 				t.Weight = 1
 			}
+			if !common.StringEmpty(t.Image) && !strings.HasPrefix(t.Image, "ami-") {
+				return errors.Errorf("validation failed, 'image' of instance type '%v' must be an AMI ID, got '%v'", t.Type, t.Image)
+			}
 		}
 	} else if m.InstancePool == nil {
 		return errors.Errorf("validation failed, must provide either instancePool or instanceTypes when using mixedInstancesPolicy")
@@ -738,6 +1370,12 @@ func (ig *InstanceGroup) Validate(overrides *ValidationOverrides) error {
 		}
 	}
 
+	if strings.EqualFold(s.Provisioner, EKSManagedProvisionerName) {
+		if err := ig.GetEKSManagedConfiguration().Validate(); err != nil {
+			return err
+		}
+	}
+
 	if strings.EqualFold(s.Provisioner, EKSFargateProvisionerName) {
 		if err := s.EKSFargateSpec.Validate(); err != nil {
 			return err
@@ -751,6 +1389,18 @@ func (ig *InstanceGroup) Validate(overrides *ValidationOverrides) error {
 		config := ig.GetEKSConfiguration()
 		spec := ig.GetEKSSpec()
 
+		// a default volume from the controller configuration is validated like any other volume
+		if config != nil && len(config.Volumes) == 0 && overrides != nil && len(overrides.defaultVolumesOverride) > 0 {
+			config.Volumes = make([]NodeVolume, len(overrides.defaultVolumesOverride))
+			for i := range overrides.defaultVolumesOverride {
+				overrides.defaultVolumesOverride[i].DeepCopyInto(&config.Volumes[i])
+			}
+		}
+
+		if config != nil && common.StringEmpty(config.InstanceProfileNamePrefix) && overrides != nil {
+			config.InstanceProfileNamePrefix = overrides.instanceProfileNamePrefixOverride
+		}
+
 		if err := spec.Validate(overrides); err != nil {
 			return err
 		}
@@ -760,6 +1410,18 @@ func (ig *InstanceGroup) Validate(overrides *ValidationOverrides) error {
 		}
 	}
 
+	for _, dependency := range ig.GetDependencies() {
+		if dependency.String() == ig.NamespacedName() {
+			return errors.Errorf("validation failed, instance group cannot depend on itself")
+		}
+	}
+
+	if val, ok := ig.GetAnnotations()[MaintenanceWindowAnnotationKey]; ok {
+		if _, err := ParseMaintenanceWindow(val); err != nil {
+			return errors.Wrapf(err, "validation failed, invalid '%v' annotation", MaintenanceWindowAnnotationKey)
+		}
+	}
+
 	if s.AwsUpgradeStrategy.Type == "" {
 		s.AwsUpgradeStrategy.Type = RollingUpdateStrategyName
 	}
@@ -782,17 +1444,116 @@ func (ig *InstanceGroup) Validate(overrides *ValidationOverrides) error {
 		s.AwsUpgradeStrategy.RollingUpdateType = DefaultRollingUpdateStrategy
 	}
 
+	if rollingUpdate := s.AwsUpgradeStrategy.RollingUpdateType; rollingUpdate != nil && rollingUpdate.BatchSize != nil {
+		if batchSize := rollingUpdate.BatchSize; batchSize.Type == intstr.Int && batchSize.IntVal < 0 {
+			return errors.Errorf("validation failed, strategy.rollingUpdate.batchSize must be non-negative, got '%v'", batchSize.IntVal)
+		}
+	}
+	if rollingUpdate := s.AwsUpgradeStrategy.RollingUpdateType; rollingUpdate != nil && rollingUpdate.DrainTimeout < 0 {
+		return errors.Errorf("validation failed, strategy.rollingUpdate.drainTimeout must be non-negative, got '%v'", rollingUpdate.DrainTimeout)
+	}
+
+	if refresh := s.AwsUpgradeStrategy.InstanceRefresh; refresh != nil {
+		if err := refresh.Validate(); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 func (c *EKSConfiguration) GetRoleName() string {
 	return c.ExistingRoleName
 }
+
+// IsVCPUWeighted returns true when the capacity of the group is expressed in vCPUs
+func (m *MixedInstancesPolicySpec) IsVCPUWeighted() bool {
+	return m != nil && strings.EqualFold(common.StringValue(m.WeightedCapacityUnit), WeightedCapacityUnitVCPU)
+}
+
+// GetWeightedCapacityUnit returns the unit the capacity of the group is expressed in
+func (m *MixedInstancesPolicySpec) GetWeightedCapacityUnit() string {
+	if m.IsVCPUWeighted() {
+		return WeightedCapacityUnitVCPU
+	}
+	return WeightedCapacityUnitInstance
+}
+
+// IsWeighted returns true when instances of the group may count for more than a single unit of capacity
+func (m *MixedInstancesPolicySpec) IsWeighted() bool {
+	if m == nil {
+		return false
+	}
+	if m.IsVCPUWeighted() {
+		return true
+	}
+	for _, t := range m.InstanceTypes {
+		if t.Weight > 1 {
+			return true
+		}
+	}
+	return false
+}
+
 func (c *EKSConfiguration) GetMixedInstancesPolicy() *MixedInstancesPolicySpec {
 	return c.MixedInstancesPolicy
 }
 func (c *EKSConfiguration) GetMetadataOptions() *MetadataOptions {
 	return c.MetadataOptions
 }
+func (c *EKSConfiguration) GetCapacityReservationId() string {
+	return c.CapacityReservationId
+}
+func (c *EKSConfiguration) GetCapacityReservationPreference() string {
+	return c.CapacityReservationPreference
+}
+func (c *EKSConfiguration) GetCapacityRebalance() *bool {
+	return c.CapacityRebalance
+}
+func (c *EKSConfiguration) GetNewInstancesProtectedFromScaleIn() *bool {
+	return c.NewInstancesProtectedFromScaleIn
+}
+func (c *EKSConfiguration) GetSourceDestCheck() *bool {
+	return c.SourceDestCheck
+}
+func (c *EKSConfiguration) GetHealthCheckType() string {
+	return c.HealthCheckType
+}
+func (c *EKSConfiguration) GetHealthCheckGracePeriod() int64 {
+	return c.HealthCheckGracePeriod
+}
+func (c *EKSConfiguration) GetDefaultCooldown() int64 {
+	return c.DefaultCooldown
+}
+func (c *EKSConfiguration) GetMaintenancePolicy() *MaintenancePolicySpec {
+	return c.MaintenancePolicy
+}
+func (c *EKSConfiguration) GetClusterCA() string {
+	return c.ClusterCA
+}
+func (c *EKSConfiguration) GetApiServerEndpoint() string {
+	return c.ApiServerEndpoint
+}
+func (c *EKSConfiguration) GetExistingLaunchTemplateName() string {
+	return c.ExistingLaunchTemplateName
+}
+func (c *EKSConfiguration) GetMetricsGranularity() string {
+	if common.StringEmpty(c.MetricsGranularity) {
+		return MetricsGranularityOneMinute
+	}
+	return c.MetricsGranularity
+}
+func (c *EKSConfiguration) GetTargetGroupARNs() []string {
+	return c.TargetGroupARNs
+}
+func (c *EKSConfiguration) SetTargetGroupARNs(arns []string) {
+	c.TargetGroupARNs = arns
+}
+func (c *EKSConfiguration) GetLoadBalancerNames() []string {
+	return c.LoadBalancerNames
+}
+func (c *EKSConfiguration) SetLoadBalancerNames(names []string) {
+	c.LoadBalancerNames = names
+}
 func (c *EKSConfiguration) GetPlacement() *PlacementSpec {
 	return c.Placement
 }
@@ -802,20 +1563,35 @@ func (c *EKSConfiguration) GetLifecycleHooks() []LifecycleHookSpec {
 func (c *EKSConfiguration) SetLifecycleHooks(hooks []LifecycleHookSpec) {
 	c.LifecycleHooks = hooks
 }
+
+// ExistInSlice returns true if the hook is in the slice, SSM documents are not part of the scaling group's hooks and are
+// not compared
 func (h LifecycleHookSpec) ExistInSlice(hooks []LifecycleHookSpec) bool {
+	h.SSMDocument = nil
 	for _, hook := range hooks {
+		hook.SSMDocument = nil
 		if reflect.DeepEqual(hook, h) {
 			return true
 		}
 	}
 	return false
 }
+
+func (h LifecycleHookSpec) HasSSMDocument() bool {
+	return h.SSMDocument != nil
+}
 func (c *EKSConfiguration) GetImage() string {
 	return c.Image
 }
 func (c *EKSConfiguration) GetInstanceProfileName() string {
 	return c.ExistingInstanceProfileName
 }
+func (c *EKSConfiguration) GetInstanceProfileNamePrefix() string {
+	return c.InstanceProfileNamePrefix
+}
+func (c *EKSConfiguration) SetInstanceProfileNamePrefix(prefix string) {
+	c.InstanceProfileNamePrefix = prefix
+}
 func (c *EKSConfiguration) HasExistingRole() bool {
 	return c.ExistingRoleName != ""
 }
@@ -865,6 +1641,83 @@ func (c *EKSConfiguration) GetBootstrapArguments() string {
 	return c.BootstrapArguments
 }
 
+func (p *MaintenancePolicySpec) Validate() error {
+	if p.MinHealthyPercentage == nil || p.MaxHealthyPercentage == nil {
+		return errors.Errorf("validation failed, 'maintenancePolicy' must specify both 'minHealthyPercentage' and 'maxHealthyPercentage'")
+	}
+	var (
+		minHealthy = *p.MinHealthyPercentage
+		maxHealthy = *p.MaxHealthyPercentage
+	)
+	if minHealthy < 0 || minHealthy > 100 {
+		return errors.Errorf("validation failed, 'maintenancePolicy.minHealthyPercentage' must be between 0 and 100, got '%v'", minHealthy)
+	}
+	if maxHealthy < 100 || maxHealthy > 200 {
+		return errors.Errorf("validation failed, 'maintenancePolicy.maxHealthyPercentage' must be between 100 and 200, got '%v'", maxHealthy)
+	}
+	if maxHealthy-minHealthy > 100 {
+		return errors.Errorf("validation failed, 'maintenancePolicy' difference between 'minHealthyPercentage' and 'maxHealthyPercentage' cannot be greater than 100")
+	}
+	return nil
+}
+
+func (o *BootstrapOptions) Validate() error {
+	if !common.StringEmpty(o.UserDataShebang) && !strings.HasPrefix(o.UserDataShebang, "#!") {
+		return errors.Errorf("validation failed, 'bootstrapOptions.userDataShebang' must start with '#!', got '%v'", o.UserDataShebang)
+	}
+
+	for name, reserved := range map[string]map[string]string{"systemReserved": o.SystemReserved, "kubeReserved": o.KubeReserved} {
+		for k, v := range reserved {
+			if !common.ContainsString(AllowedReservedResources, k) {
+				return errors.Errorf("validation failed, 'bootstrapOptions.%v' resource '%v' must be one of %+v", name, k, AllowedReservedResources)
+			}
+			if _, err := resource.ParseQuantity(v); err != nil {
+				return errors.Errorf("validation failed, 'bootstrapOptions.%v.%v' value '%v' is not a valid quantity", name, k, v)
+			}
+		}
+	}
+
+	for k, v := range o.EvictionHard {
+		if !common.ContainsString(AllowedEvictionSignals, k) {
+			return errors.Errorf("validation failed, 'bootstrapOptions.evictionHard' signal '%v' must be one of %+v", k, AllowedEvictionSignals)
+		}
+		if strings.HasSuffix(v, "%") {
+			if _, err := intstr.GetScaledValueFromIntOrPercent(&intstr.IntOrString{Type: intstr.String, StrVal: v}, 100, false); err != nil {
+				return errors.Errorf("validation failed, 'bootstrapOptions.evictionHard.%v' value '%v' is not a valid percentage", k, v)
+			}
+			continue
+		}
+		if _, err := resource.ParseQuantity(v); err != nil {
+			return errors.Errorf("validation failed, 'bootstrapOptions.evictionHard.%v' value '%v' is not a valid quantity or percentage", k, v)
+		}
+	}
+
+	if o.CredentialProvider != nil {
+		if err := o.CredentialProvider.Validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *CredentialProviderSpec) Validate() error {
+	if common.StringEmpty(p.Name) || p.Name == "." || p.Name == ".." || strings.ContainsAny(p.Name, "/ ") {
+		return errors.Errorf("validation failed, 'bootstrapOptions.credentialProvider.name' must be the file name of the provider binary, got '%v'", p.Name)
+	}
+	if !common.StringEmpty(p.BinDir) && (!path.IsAbs(p.BinDir) || strings.ContainsAny(p.BinDir, " '")) {
+		return errors.Errorf("validation failed, 'bootstrapOptions.credentialProvider.binDir' must be an absolute path, got '%v'", p.BinDir)
+	}
+	if len(p.MatchImages) == 0 {
+		return errors.Errorf("validation failed, 'bootstrapOptions.credentialProvider.matchImages' must contain at least one image pattern")
+	}
+	if !common.StringEmpty(p.DefaultCacheDuration) {
+		if _, err := time.ParseDuration(p.DefaultCacheDuration); err != nil {
+			return errors.Errorf("validation failed, 'bootstrapOptions.credentialProvider.defaultCacheDuration' value '%v' is not a valid duration", p.DefaultCacheDuration)
+		}
+	}
+	return nil
+}
+
 func (c *EKSConfiguration) GetBootstrapOptions() *BootstrapOptions {
 	return c.BootstrapOptions
 }
@@ -902,9 +1755,28 @@ func (c *EKSConfiguration) GetSpotPrice() string {
 func (c *EKSConfiguration) SetSpotPrice(price string) {
 	c.SpotPrice = price
 }
+func (c *EKSConfiguration) GetSpotMarketOptions() bool {
+	return c.SpotMarketOptions
+}
 func (c *EKSConfiguration) SetSubnets(subnets []string) {
 	c.Subnets = subnets
 }
+
+// NormalizeSuspendProcesses drops unknown and duplicate scaling processes, specific processes are ignored when 'all' is
+// listed
+func NormalizeSuspendProcesses(processes []string) []string {
+	normalized := make([]string, 0)
+	for _, p := range processes {
+		if strings.EqualFold(p, "all") {
+			return []string{"all"}
+		}
+		if common.ContainsString(awsprovider.DefaultSuspendProcesses, p) && !common.ContainsString(normalized, p) {
+			normalized = append(normalized, p)
+		}
+	}
+	return normalized
+}
+
 func (c *EKSConfiguration) SetSuspendProcesses(suspendProcesses []string) {
 	c.SuspendedProcesses = suspendProcesses
 }
@@ -923,6 +1795,9 @@ func (spec *WarmPoolSpec) GetMinSize() int64 {
 func (spec *EKSSpec) GetType() ScalingConfigurationType {
 	return spec.Type
 }
+func (spec *EKSSpec) GetNodeReadinessTimeout() int64 {
+	return spec.NodeReadinessTimeout
+}
 
 func (conf *EKSManagedConfiguration) SetSubnets(subnets []string) {
 	conf.Subnets = subnets
@@ -936,6 +1811,81 @@ func (conf *EKSManagedConfiguration) GetLabels() map[string]string {
 	return conf.NodeLabels
 }
 
+func (conf *EKSManagedConfiguration) GetTaints() []corev1.Taint {
+	return conf.Taints
+}
+
+func (conf *EKSManagedConfiguration) GetUpdateConfig() *EKSManagedUpdateConfig {
+	return conf.UpdateConfig
+}
+
+// Validate returns an error if a taint or the update config is invalid, or if the release version does not match the format
+// of the AMI type's release versions, which AWS would only reject when the node group is created
+func (conf *EKSManagedConfiguration) Validate() error {
+	if conf == nil {
+		return nil
+	}
+
+	if err := validateTaints(conf.Taints); err != nil {
+		return err
+	}
+
+	if err := conf.UpdateConfig.Validate(); err != nil {
+		return err
+	}
+
+	if common.StringEmpty(conf.ReleaseVersion) || common.StringEmpty(conf.AmiType) {
+		return nil
+	}
+
+	var (
+		amiType        = strings.ToUpper(conf.AmiType)
+		releaseVersion = conf.ReleaseVersion
+	)
+	switch {
+	case amiType == ManagedAmiTypeCustom:
+		return errors.Errorf("validation failed, 'releaseVersion' cannot be used with amiType '%v'", conf.AmiType)
+	case strings.HasPrefix(amiType, ManagedAmiTypeBottlerocketPrefix):
+		if !BottlerocketReleaseVersionRegex.MatchString(releaseVersion) || isAmazonLinuxReleaseVersion(releaseVersion) {
+			return errors.Errorf("validation failed, 'releaseVersion' must be a bottlerocket release version (e.g. 1.14.3-764e37e4) for amiType '%v', got '%v'", conf.AmiType, releaseVersion)
+		}
+	case strings.HasPrefix(amiType, ManagedAmiTypeAmazonLinux2Prefix), strings.HasPrefix(amiType, ManagedAmiTypeAL2023Prefix):
+		if !isAmazonLinuxReleaseVersion(releaseVersion) {
+			return errors.Errorf("validation failed, 'releaseVersion' must be an amazon linux release version (e.g. 1.27.3-20230816) for amiType '%v', got '%v'", conf.AmiType, releaseVersion)
+		}
+	}
+	return nil
+}
+
+func (c *EKSManagedUpdateConfig) Validate() error {
+	if c == nil {
+		return nil
+	}
+
+	if c.MaxUnavailable != 0 && c.MaxUnavailablePercentage != 0 {
+		return errors.New("validation failed, 'maxUnavailable' and 'maxUnavailablePercentage' are mutually exclusive")
+	}
+
+	if c.MaxUnavailable < 0 || c.MaxUnavailable > ManagedMaxUnavailableLimit {
+		return errors.Errorf("validation failed, 'maxUnavailable' must be between 1 and %v, got %v", ManagedMaxUnavailableLimit, c.MaxUnavailable)
+	}
+
+	if c.MaxUnavailablePercentage < 0 || c.MaxUnavailablePercentage > ManagedMaxUnavailablePercentageLimit {
+		return errors.Errorf("validation failed, 'maxUnavailablePercentage' must be between 1 and %v, got %v", ManagedMaxUnavailablePercentageLimit, c.MaxUnavailablePercentage)
+	}
+	return nil
+}
+
+// isAmazonLinuxReleaseVersion returns true if the release version ends with a valid build date
+func isAmazonLinuxReleaseVersion(releaseVersion string) bool {
+	match := AmazonLinuxReleaseVersionRegex.FindStringSubmatch(releaseVersion)
+	if match == nil {
+		return false
+	}
+	_, err := time.Parse("20060102", match[1])
+	return err == nil
+}
+
 func (ig *InstanceGroup) GetEKSManagedConfiguration() *EKSManagedConfiguration {
 	return ig.Spec.EKSManagedSpec.EKSManagedConfiguration
 }
@@ -960,6 +1910,10 @@ func (s *AwsUpgradeStrategy) SetRollingUpdateType(ru *RollingUpdateStrategy) {
 	s.RollingUpdateType = ru
 }
 
+func (s *AwsUpgradeStrategy) GetInstanceRefresh() *InstanceRefreshStrategy {
+	return s.InstanceRefresh
+}
+
 func (s *AwsUpgradeStrategy) GetCRDType() *CRDUpdateStrategy {
 	return s.CRDType
 }
@@ -968,6 +1922,26 @@ func (s *AwsUpgradeStrategy) SetCRDType(crd *CRDUpdateStrategy) {
 	s.CRDType = crd
 }
 
+func (r *InstanceRefreshStrategy) Validate() error {
+	var previous int64
+	for _, p := range r.CheckpointPercentages {
+		if p < 1 || p > 100 {
+			return errors.Errorf("validation failed, strategy.instanceRefresh.checkpointPercentages must be between 1 and 100, got '%v'", p)
+		}
+		if p <= previous {
+			return errors.Errorf("validation failed, strategy.instanceRefresh.checkpointPercentages must be unique and in ascending order")
+		}
+		previous = p
+	}
+	if r.CheckpointDelay < 0 || r.CheckpointDelay > 172800 {
+		return errors.Errorf("validation failed, strategy.instanceRefresh.checkpointDelay must be between 0 and 172800, got '%v'", r.CheckpointDelay)
+	}
+	if r.CheckpointDelay != 0 && len(r.CheckpointPercentages) == 0 {
+		return errors.Errorf("validation failed, strategy.instanceRefresh.checkpointDelay requires checkpointPercentages")
+	}
+	return nil
+}
+
 func (c *CRDUpdateStrategy) Validate() error {
 	if c.GetSpec() == "" {
 		return errors.New("spec is empty")
@@ -1057,6 +2031,14 @@ func (status *InstanceGroupStatus) SetStrategyRetryCount(n int) {
 	status.StrategyRetryCount = n
 }
 
+func (status *InstanceGroupStatus) GetInstanceRefreshId() string {
+	return status.InstanceRefreshId
+}
+
+func (status *InstanceGroupStatus) SetInstanceRefreshId(id string) {
+	status.InstanceRefreshId = id
+}
+
 func (status *InstanceGroupStatus) GetActiveLaunchConfigurationName() string {
 	return status.ActiveLaunchConfigurationName
 }
@@ -1092,6 +2074,137 @@ func (status *InstanceGroupStatus) SetConfigHash(hash string) {
 	status.ConfigHash = hash
 }
 
+func (status *InstanceGroupStatus) GetDriftDetected() bool {
+	return status.DriftDetected
+}
+
+func (status *InstanceGroupStatus) SetDriftDetected(condition bool) {
+	status.DriftDetected = condition
+}
+
+func (status *InstanceGroupStatus) GetAppliedGeneration() int64 {
+	return status.AppliedGeneration
+}
+
+func (status *InstanceGroupStatus) SetAppliedGeneration(generation int64) {
+	status.AppliedGeneration = generation
+}
+
+func (status *InstanceGroupStatus) GetSpotInterruptions() int {
+	return status.SpotInterruptions
+}
+
+func (status *InstanceGroupStatus) IncSpotInterruptions(count int) {
+	status.SpotInterruptions += count
+}
+
+func (status *InstanceGroupStatus) GetLastSpotInterruptionTime() time.Time {
+	if status.LastSpotInterruptionTime == nil {
+		return time.Time{}
+	}
+	return status.LastSpotInterruptionTime.Time
+}
+
+func (status *InstanceGroupStatus) SetLastSpotInterruptionTime(t time.Time) {
+	status.LastSpotInterruptionTime = &metav1.Time{Time: t}
+}
+
+func (status *InstanceGroupStatus) GetLastSpotInterruptionUIDs() []string {
+	return status.LastSpotInterruptionUIDs
+}
+
+func (status *InstanceGroupStatus) SetLastSpotInterruptionUIDs(uids []string) {
+	status.LastSpotInterruptionUIDs = uids
+}
+
+func (status *InstanceGroupStatus) GetNodesNotReadySince() time.Time {
+	if status.NodesNotReadySince == nil {
+		return time.Time{}
+	}
+	return status.NodesNotReadySince.Time
+}
+
+// SetNodesNotReadySince records when the nodes of the instance group stopped being ready, a zero time clears it
+func (status *InstanceGroupStatus) SetNodesNotReadySince(t time.Time) {
+	if t.IsZero() {
+		status.NodesNotReadySince = nil
+		return
+	}
+	status.NodesNotReadySince = &metav1.Time{Time: t}
+}
+
+func (status *InstanceGroupStatus) GetRenderedUserDataHash() string {
+	return status.RenderedUserDataHash
+}
+
+func (status *InstanceGroupStatus) SetRenderedUserDataHash(hash string) {
+	status.RenderedUserDataHash = hash
+}
+
+func (status *InstanceGroupStatus) GetDumpedUserDataHash() string {
+	return status.DumpedUserDataHash
+}
+
+func (status *InstanceGroupStatus) SetDumpedUserDataHash(hash string) {
+	status.DumpedUserDataHash = hash
+}
+
+func (status *InstanceGroupStatus) GetAttachedTargetGroupARNs() []string {
+	return status.AttachedTargetGroupARNs
+}
+
+func (status *InstanceGroupStatus) SetAttachedTargetGroupARNs(arns []string) {
+	status.AttachedTargetGroupARNs = arns
+}
+
+func (status *InstanceGroupStatus) GetAttachedLoadBalancerNames() []string {
+	return status.AttachedLoadBalancerNames
+}
+
+func (status *InstanceGroupStatus) SetAttachedLoadBalancerNames(names []string) {
+	status.AttachedLoadBalancerNames = names
+}
+
+func (status *InstanceGroupStatus) GetInstanceProfileName() string {
+	return status.InstanceProfileName
+}
+
+func (status *InstanceGroupStatus) SetInstanceProfileName(name string) {
+	status.InstanceProfileName = name
+}
+
+func (status *InstanceGroupStatus) GetRetiredInstanceProfileNames() []string {
+	return status.RetiredInstanceProfileNames
+}
+
+func (status *InstanceGroupStatus) SetRetiredInstanceProfileNames(names []string) {
+	status.RetiredInstanceProfileNames = names
+}
+
+func (status *InstanceGroupStatus) GetRetiredLaunchTemplateNames() []string {
+	return status.RetiredLaunchTemplateNames
+}
+
+func (status *InstanceGroupStatus) SetRetiredLaunchTemplateNames(names []string) {
+	status.RetiredLaunchTemplateNames = names
+}
+
+func (status *InstanceGroupStatus) GetWeightedCapacityUnit() string {
+	return status.WeightedCapacityUnit
+}
+
+func (status *InstanceGroupStatus) SetWeightedCapacityUnit(unit string) {
+	status.WeightedCapacityUnit = unit
+}
+
+func (status *InstanceGroupStatus) GetLastForceUpgradeToken() string {
+	return status.LastForceUpgradeToken
+}
+
+func (status *InstanceGroupStatus) SetLastForceUpgradeToken(token string) {
+	status.LastForceUpgradeToken = token
+}
+
 func (status *InstanceGroupStatus) GetNodesReadyCondition() corev1.ConditionStatus {
 	for _, c := range status.Conditions {
 		if c.Type == NodesReady {
@@ -1177,6 +2290,56 @@ func (status *InstanceGroupStatus) SetLifecycle(phase string) {
 	status.Lifecycle = phase
 }
 
+func (status *InstanceGroupStatus) GetStateHistory() []StateTransition {
+	return status.StateHistory
+}
+
+// AppendStateTransition records a transition, trimming the oldest entries beyond MaxStateHistory
+func (status *InstanceGroupStatus) AppendStateTransition(transition StateTransition) {
+	status.StateHistory = append(status.StateHistory, transition)
+	if len(status.StateHistory) > MaxStateHistory {
+		trimmed := make([]StateTransition, MaxStateHistory)
+		copy(trimmed, status.StateHistory[len(status.StateHistory)-MaxStateHistory:])
+		status.StateHistory = trimmed
+	}
+}
+
+// CompactStateHistory collapses the transitions recorded during a reconcile into a single transition from the persisted
+// state, so that intermediate states do not modify the status. The persisted history is kept unchanged if the reconcile
+// ended in the persisted state
+func (status *InstanceGroupStatus) CompactStateHistory(persisted *InstanceGroupStatus) {
+	var (
+		transition = StateTransition{
+			State:     ReconcileState(status.CurrentState),
+			Timestamp: metav1.Now(),
+		}
+		last = len(status.StateHistory) - 1
+	)
+
+	if last >= 0 && string(status.StateHistory[last].State) == status.CurrentState {
+		transition = status.StateHistory[last]
+	}
+
+	status.StateHistory = make([]StateTransition, len(persisted.StateHistory))
+	copy(status.StateHistory, persisted.StateHistory)
+	if len(status.StateHistory) == 0 {
+		status.StateHistory = nil
+	}
+
+	if status.CurrentState != persisted.CurrentState {
+		status.AppendStateTransition(transition)
+	}
+}
+
+// SetStateTransitionReason sets the reason of the latest transition if it led to the current state
+func (status *InstanceGroupStatus) SetStateTransitionReason(reason string) {
+	last := len(status.StateHistory) - 1
+	if last < 0 || string(status.StateHistory[last].State) != status.CurrentState {
+		return
+	}
+	status.StateHistory[last].Reason = reason
+}
+
 func (status *InstanceGroupStatus) GetConditions() []InstanceGroupCondition {
 	return status.Conditions
 }
@@ -1185,6 +2348,50 @@ func (status *InstanceGroupStatus) SetConditions(conditions []InstanceGroupCondi
 	status.Conditions = conditions
 }
 
+// SetCondition adds a condition, or replaces the existing condition of the same type
+func (status *InstanceGroupStatus) SetCondition(condition InstanceGroupCondition) {
+	for i, c := range status.Conditions {
+		if c.Type == condition.Type {
+			status.Conditions[i] = condition
+			return
+		}
+	}
+	status.Conditions = append(status.Conditions, condition)
+}
+
+// RemoveCondition removes the condition of the given type if it exists
+func (status *InstanceGroupStatus) RemoveCondition(conditionType InstanceGroupConditionType) {
+	conditions := make([]InstanceGroupCondition, 0, len(status.Conditions))
+	for _, c := range status.Conditions {
+		if c.Type != conditionType {
+			conditions = append(conditions, c)
+		}
+	}
+	if len(conditions) != len(status.Conditions) {
+		status.Conditions = conditions
+	}
+}
+
+// GetConditionStatus returns the status of the condition of the given type, or unknown if it does not exist
+func (status *InstanceGroupStatus) GetConditionStatus(conditionType InstanceGroupConditionType) corev1.ConditionStatus {
+	for _, c := range status.Conditions {
+		if c.Type == conditionType {
+			return c.Status
+		}
+	}
+	return corev1.ConditionUnknown
+}
+
+// GetConditionMessage returns the message of the condition of the given type, or an empty string if it does not exist
+func (status *InstanceGroupStatus) GetConditionMessage(conditionType InstanceGroupConditionType) string {
+	for _, c := range status.Conditions {
+		if c.Type == conditionType {
+			return c.Message
+		}
+	}
+	return ""
+}
+
 func (strategy *AwsUpgradeStrategy) GetType() string {
 	return strategy.Type
 }
@@ -1203,6 +2410,12 @@ func (ig *InstanceGroup) SetState(s ReconcileState) {
 		"state", s,
 		"previousState", ig.Status.CurrentState,
 	)
+	if ig.Status.CurrentState != string(s) {
+		ig.Status.AppendStateTransition(StateTransition{
+			State:     s,
+			Timestamp: metav1.Now(),
+		})
+	}
 	ig.Status.CurrentState = string(s)
 }
 
@@ -1215,6 +2428,29 @@ func (ig *InstanceGroup) GetEKSFargateSpec() *EKSFargateSpec {
 }
 
 func (spec *EKSFargateSpec) Validate() error {
+	if len(spec.Selectors) == 0 {
+		return errors.Errorf("validation failed, 'selectors' must contain at least one selector")
+	}
+	if len(spec.Selectors) > FargateMaxSelectors {
+		return errors.Errorf("validation failed, 'selectors' must not contain more than %v selectors", FargateMaxSelectors)
+	}
+	for _, s := range spec.Selectors {
+		if errs := validation.IsDNS1123Label(s.Namespace); len(errs) > 0 {
+			return errors.Errorf("validation failed, selector namespace '%v' must be a valid DNS label", s.Namespace)
+		}
+		if len(s.Labels) > FargateMaxSelectorLabels {
+			return errors.Errorf("validation failed, selector for namespace '%v' must not contain more than %v labels", s.Namespace, FargateMaxSelectorLabels)
+		}
+	}
+	if !common.StringEmpty(spec.PodExecutionRoleArn) {
+		roleArn, err := arn.Parse(spec.PodExecutionRoleArn)
+		if err != nil || roleArn.Service != "iam" {
+			return errors.Errorf("validation failed, 'podExecutionRoleArn' must be a valid IAM role ARN, got '%v'", spec.PodExecutionRoleArn)
+		}
+		if len(spec.ManagedPolicies) > 0 {
+			return errors.Errorf("validation failed, 'managedPolicies' cannot be used with 'podExecutionRoleArn'")
+		}
+	}
 	return nil
 }
 
@@ -1229,6 +2465,9 @@ func (spec *EKSFargateSpec) SetClusterName(name string) {
 func (spec *EKSFargateSpec) GetPodExecutionRoleArn() string {
 	return spec.PodExecutionRoleArn
 }
+func (spec *EKSFargateSpec) HasExistingRole() bool {
+	return spec.PodExecutionRoleArn != ""
+}
 
 func (spec *EKSFargateSpec) SetPodExecutionRoleArn(arn string) {
 	spec.PodExecutionRoleArn = arn
@@ -1257,3 +2496,20 @@ func (spec *EKSFargateSpec) GetTags() []map[string]string {
 func (spec *EKSFargateSpec) SetTags(tags []map[string]string) {
 	spec.Tags = tags
 }
+
+func (spec *EKSFargateSpec) GetManagedPolicies() []string {
+	return spec.ManagedPolicies
+}
+
+func (spec *EKSFargateSpec) SetManagedPolicies(policies []string) {
+	spec.ManagedPolicies = policies
+}
+
+// IsTargetGroupARN returns true if s is an elastic load balancing target group ARN
+func IsTargetGroupARN(s string) bool {
+	parsed, err := arn.Parse(s)
+	if err != nil {
+		return false
+	}
+	return parsed.Service == "elasticloadbalancing" && strings.HasPrefix(parsed.Resource, "targetgroup/")
+}
@@ -14,10 +14,16 @@ limitations under the License.
 package v1alpha1
 
 import (
+	"reflect"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
+	corev1 "k8s.io/api/core/v1"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 )
 
 type EksUnitTest struct {
@@ -36,6 +42,7 @@ func (u *EksUnitTest) Run(t *testing.T) string {
 
 func TestInstanceGroupSpecValidate(t *testing.T) {
 	launchconfiguration := LaunchConfiguration
+	subFamilyFlexible := SubFamilyFlexibleInstancePool
 	type args struct {
 		instancegroup *InstanceGroup
 		overrides     *ValidationOverrides
@@ -113,6 +120,90 @@ func TestInstanceGroupSpecValidate(t *testing.T) {
 			},
 			want: "validation failed, 'LicenseSpecifications[0]' must be a valid IAM role ARN",
 		},
+		{
+			name: "eks with spotPrice and mixedInstancesPolicy",
+			args: args{
+				instancegroup: MockInstanceGroup("eks", "rollingUpdate", &EKSSpec{
+					MaxSize: 1,
+					MinSize: 1,
+					Type:    "LaunchTemplate",
+					EKSConfiguration: &EKSConfiguration{
+						EksClusterName:     "my-eks-cluster",
+						NodeSecurityGroups: []string{"sg-123456789"},
+						Image:              "ami-12345",
+						InstanceType:       "m5.large",
+						KeyPairName:        "thisShouldBeOptional",
+						Subnets:            []string{"subnet-1111111", "subnet-222222"},
+						SpotPrice:          "0.05",
+						MixedInstancesPolicy: &MixedInstancesPolicySpec{
+							InstanceTypes: []*InstanceTypeSpec{{Type: "m5.xlarge"}},
+						},
+					},
+				}, nil, nil),
+			},
+			want: "validation failed, cannot use spotPrice with mixedInstancesPolicy, use 'mixedInstancesPolicy.spotRatio' instead",
+		},
+		{
+			name: "eks with spotPrice and launch template",
+			args: args{
+				instancegroup: MockInstanceGroup("eks", "rollingUpdate", &EKSSpec{
+					MaxSize: 1,
+					MinSize: 1,
+					Type:    "LaunchTemplate",
+					EKSConfiguration: &EKSConfiguration{
+						EksClusterName:     "my-eks-cluster",
+						NodeSecurityGroups: []string{"sg-123456789"},
+						Image:              "ami-12345",
+						InstanceType:       "m5.large",
+						KeyPairName:        "thisShouldBeOptional",
+						Subnets:            []string{"subnet-1111111", "subnet-222222"},
+						SpotPrice:          "0.05",
+					},
+				}, nil, nil),
+			},
+			want: "validation failed, field 'spotPrice' is only valid for LaunchConfigurations, use 'mixedInstancesPolicy.spotRatio' with LaunchTemplates",
+		},
+		{
+			name: "eks with spotPrice and launch template spot market options",
+			args: args{
+				instancegroup: MockInstanceGroup("eks", "rollingUpdate", &EKSSpec{
+					MaxSize: 1,
+					MinSize: 1,
+					Type:    "LaunchTemplate",
+					EKSConfiguration: &EKSConfiguration{
+						EksClusterName:     "my-eks-cluster",
+						NodeSecurityGroups: []string{"sg-123456789"},
+						Image:              "ami-12345",
+						InstanceType:       "m5.large",
+						KeyPairName:        "thisShouldBeOptional",
+						Subnets:            []string{"subnet-1111111", "subnet-222222"},
+						SpotPrice:          "0.05",
+						SpotMarketOptions:  true,
+					},
+				}, nil, nil),
+			},
+			want: "",
+		},
+		{
+			name: "eks with spotPrice and launch configuration",
+			args: args{
+				instancegroup: MockInstanceGroup("eks", "rollingUpdate", &EKSSpec{
+					MaxSize: 1,
+					MinSize: 1,
+					Type:    "LaunchConfiguration",
+					EKSConfiguration: &EKSConfiguration{
+						EksClusterName:     "my-eks-cluster",
+						NodeSecurityGroups: []string{"sg-123456789"},
+						Image:              "ami-12345",
+						InstanceType:       "m5.large",
+						KeyPairName:        "thisShouldBeOptional",
+						Subnets:            []string{"subnet-1111111", "subnet-222222"},
+						SpotPrice:          "0.05",
+					},
+				}, nil, nil),
+			},
+			want: "",
+		},
 		{
 			name: "eks with invalid container runtime",
 			args: args{
@@ -133,6 +224,66 @@ func TestInstanceGroupSpecValidate(t *testing.T) {
 			},
 			want: "validation failed, 'bootstrapOptions.containerRuntime' must be one of [containerd dockerd]",
 		},
+		{
+			name: "eks with valid kubelet reservations",
+			args: args{
+				instancegroup: MockInstanceGroup("eks", "rollingUpdate", &EKSSpec{
+					MaxSize: 1,
+					MinSize: 1,
+					Type:    "LaunchTemplate",
+					EKSConfiguration: &EKSConfiguration{
+						BootstrapOptions:   &BootstrapOptions{SystemReserved: map[string]string{"cpu": "100m"}, EvictionHard: map[string]string{"memory.available": "100Mi", "nodefs.available": "10%"}},
+						EksClusterName:     "my-eks-cluster",
+						NodeSecurityGroups: []string{"sg-123456789"},
+						Image:              "ami-12345",
+						InstanceType:       "m5.large",
+						KeyPairName:        "thisShouldBeOptional",
+						Subnets:            []string{"subnet-1111111", "subnet-222222"},
+					},
+				}, nil, nil),
+			},
+			want: "",
+		},
+		{
+			name: "eks with invalid reserved resource",
+			args: args{
+				instancegroup: MockInstanceGroup("eks", "rollingUpdate", &EKSSpec{
+					MaxSize: 1,
+					MinSize: 1,
+					Type:    "LaunchTemplate",
+					EKSConfiguration: &EKSConfiguration{
+						BootstrapOptions:   &BootstrapOptions{KubeReserved: map[string]string{"gpu": "1"}},
+						EksClusterName:     "my-eks-cluster",
+						NodeSecurityGroups: []string{"sg-123456789"},
+						Image:              "ami-12345",
+						InstanceType:       "m5.large",
+						KeyPairName:        "thisShouldBeOptional",
+						Subnets:            []string{"subnet-1111111", "subnet-222222"},
+					},
+				}, nil, nil),
+			},
+			want: "validation failed, 'bootstrapOptions.kubeReserved' resource 'gpu' must be one of [cpu memory ephemeral-storage pid]",
+		},
+		{
+			name: "eks with invalid eviction signal value",
+			args: args{
+				instancegroup: MockInstanceGroup("eks", "rollingUpdate", &EKSSpec{
+					MaxSize: 1,
+					MinSize: 1,
+					Type:    "LaunchTemplate",
+					EKSConfiguration: &EKSConfiguration{
+						BootstrapOptions:   &BootstrapOptions{EvictionHard: map[string]string{"memory.available": "lots"}},
+						EksClusterName:     "my-eks-cluster",
+						NodeSecurityGroups: []string{"sg-123456789"},
+						Image:              "ami-12345",
+						InstanceType:       "m5.large",
+						KeyPairName:        "thisShouldBeOptional",
+						Subnets:            []string{"subnet-1111111", "subnet-222222"},
+					},
+				}, nil, nil),
+			},
+			want: "validation failed, 'bootstrapOptions.evictionHard.memory.available' value 'lots' is not a valid quantity or percentage",
+		},
 		{
 			name: "eks with valid Placement",
 			args: args{
@@ -437,100 +588,1432 @@ func TestInstanceGroupSpecValidate(t *testing.T) {
 			want: "validation failed, HostResourceGroupArn must be a valid dedicated HostResourceGroup ARN",
 		},
 		{
-			name: "default to launch config instead of launch template",
+			name: "eks with lifecycle hook heartbeat timeout out of range",
 			args: args{
-				instancegroup: MockInstanceGroup("eks-fargate", "managed", nil, nil, basicFargateSpec()),
-				overrides: &ValidationOverrides{
-					scalingConfigurationOverride: &launchconfiguration,
-				},
+				instancegroup: MockInstanceGroup("eks", "rollingUpdate", &EKSSpec{
+					MaxSize: 1,
+					MinSize: 1,
+					Type:    "LaunchTemplate",
+					EKSConfiguration: &EKSConfiguration{
+						EksClusterName:     "my-eks-cluster",
+						NodeSecurityGroups: []string{"sg-123456789"},
+						Image:              "ami-12345",
+						InstanceType:       "m5.large",
+						KeyPairName:        "thisShouldBeOptional",
+						Subnets:            []string{"subnet-1111111", "subnet-222222"},
+						LifecycleHooks: []LifecycleHookSpec{
+							{Name: "my-hook", Lifecycle: "launch", HeartbeatTimeout: 172801},
+						},
+					},
+				}, nil, nil),
 			},
-			want: "",
+			want: "validation failed, 'heartbeatTimeout' of lifecycle hook 'my-hook' must be between 30 and 172800 seconds",
 		},
-	}
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			got := testFunction(t, tt.args)
-			if got != tt.want {
-				t.Errorf("%v: got %v, want %v", tt.name, got, tt.want)
-			}
-		})
-
-	}
-}
-
-func TestScalingConfigOverride(t *testing.T) {
-	launchconfiguration := LaunchConfiguration
-	launchtemplate := LaunchTemplate
-	var invalidScalingConfigType ScalingConfigurationType = "invalid-scaling-config-type"
-
-	type args struct {
-		instancegroup *InstanceGroup
-		overrides     *ValidationOverrides
-	}
-	testFunction := func(t *testing.T, args args) string {
-		testCase := EksUnitTest{
-			InstanceGroup: args.instancegroup,
-			Overrides:     args.overrides,
-		}
-		return testCase.Run(t)
-	}
-	tests := []struct {
-		name          string
-		args          args
-		want          ScalingConfigurationType
-		expectedError bool
-	}{
 		{
-			name: "override default to launchconfig instead of launchtemplate",
+			name: "eks with lifecycle hook heartbeat timeout below minimum",
 			args: args{
-				instancegroup: MockInstanceGroup("eks", "managed", MockEKSSpec(), nil, basicFargateSpec()),
-				overrides: &ValidationOverrides{
-					scalingConfigurationOverride: &launchconfiguration,
-				},
+				instancegroup: MockInstanceGroup("eks", "rollingUpdate", &EKSSpec{
+					MaxSize: 1,
+					MinSize: 1,
+					Type:    "LaunchTemplate",
+					EKSConfiguration: &EKSConfiguration{
+						EksClusterName:     "my-eks-cluster",
+						NodeSecurityGroups: []string{"sg-123456789"},
+						Image:              "ami-12345",
+						InstanceType:       "m5.large",
+						KeyPairName:        "thisShouldBeOptional",
+						Subnets:            []string{"subnet-1111111", "subnet-222222"},
+						LifecycleHooks: []LifecycleHookSpec{
+							{Name: "my-hook", Lifecycle: "launch", HeartbeatTimeout: 10},
+						},
+					},
+				}, nil, nil),
 			},
-			want:          LaunchConfiguration,
-			expectedError: false,
+			want: "validation failed, 'heartbeatTimeout' of lifecycle hook 'my-hook' must be between 30 and 172800 seconds",
 		},
 		{
-			name: "no default overrides",
+			name: "eks with duplicate lifecycle hook names",
 			args: args{
-				instancegroup: MockInstanceGroup("eks", "managed", MockEKSSpec(), nil, basicFargateSpec()),
-				overrides:     &ValidationOverrides{},
+				instancegroup: MockInstanceGroup("eks", "rollingUpdate", &EKSSpec{
+					MaxSize: 1,
+					MinSize: 1,
+					Type:    "LaunchTemplate",
+					EKSConfiguration: &EKSConfiguration{
+						EksClusterName:     "my-eks-cluster",
+						NodeSecurityGroups: []string{"sg-123456789"},
+						Image:              "ami-12345",
+						InstanceType:       "m5.large",
+						KeyPairName:        "thisShouldBeOptional",
+						Subnets:            []string{"subnet-1111111", "subnet-222222"},
+						LifecycleHooks: []LifecycleHookSpec{
+							{Name: "my-hook", Lifecycle: "launch"},
+							{Name: "my-hook", Lifecycle: "terminate"},
+						},
+					},
+				}, nil, nil),
 			},
-			want:          LaunchTemplate,
-			expectedError: false,
+			want: "validation failed, lifecycle hook name 'my-hook' must be unique",
 		},
 		{
-			name: "override default to launchtemplate",
+			name: "eks with lifecycle hook notification and role",
 			args: args{
-				instancegroup: MockInstanceGroup("eks", "managed", MockEKSSpec(), nil, basicFargateSpec()),
-				overrides: &ValidationOverrides{
-					scalingConfigurationOverride: &launchtemplate,
-				},
+				instancegroup: MockInstanceGroup("eks", "rollingUpdate", &EKSSpec{
+					MaxSize: 1,
+					MinSize: 1,
+					Type:    "LaunchTemplate",
+					EKSConfiguration: &EKSConfiguration{
+						EksClusterName:     "my-eks-cluster",
+						NodeSecurityGroups: []string{"sg-123456789"},
+						Image:              "ami-12345",
+						InstanceType:       "m5.large",
+						KeyPairName:        "thisShouldBeOptional",
+						Subnets:            []string{"subnet-1111111", "subnet-222222"},
+						LifecycleHooks: []LifecycleHookSpec{
+							{Name: "my-hook", Lifecycle: "launch", NotificationArn: "arn:aws:sqs:us-west-2:123456789012:my-queue", RoleArn: "arn:aws:iam::123456789012:role/my-role"},
+						},
+					},
+				}, nil, nil),
 			},
-			want:          LaunchTemplate,
-			expectedError: false,
+			want: "",
 		},
 		{
-			name: "override default to an invalid scaling config type",
+			name: "eks with lifecycle hook invalid roleArn",
 			args: args{
-				instancegroup: MockInstanceGroup("eks", "managed", MockEKSSpec(), nil, basicFargateSpec()),
-				overrides: &ValidationOverrides{
-					scalingConfigurationOverride: &invalidScalingConfigType,
-				},
-			},
-			want:          LaunchTemplate,
-			expectedError: true,
-		},
-	}
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			err := testFunction(t, tt.args)
-			if (!tt.expectedError && err != "") || (tt.expectedError && err == "") {
-				t.Errorf("%v: got: %v, expectedError: %v", tt.name, err, tt.expectedError)
-			}
-			got := tt.args.instancegroup.Spec.EKSSpec.Type
-			if got != tt.want {
+				instancegroup: MockInstanceGroup("eks", "rollingUpdate", &EKSSpec{
+					MaxSize: 1,
+					MinSize: 1,
+					Type:    "LaunchTemplate",
+					EKSConfiguration: &EKSConfiguration{
+						EksClusterName:     "my-eks-cluster",
+						NodeSecurityGroups: []string{"sg-123456789"},
+						Image:              "ami-12345",
+						InstanceType:       "m5.large",
+						KeyPairName:        "thisShouldBeOptional",
+						Subnets:            []string{"subnet-1111111", "subnet-222222"},
+						LifecycleHooks: []LifecycleHookSpec{
+							{Name: "my-hook", Lifecycle: "launch", NotificationArn: "arn:aws:sns:us-west-2:123456789012:my-topic", RoleArn: "my-role"},
+						},
+					},
+				}, nil, nil),
+			},
+			want: "validation failed, 'roleArn' of lifecycle hook 'my-hook' must be a valid IAM role ARN, got 'my-role'",
+		},
+		{
+			name: "eks with lifecycle hook non-iam roleArn",
+			args: args{
+				instancegroup: MockInstanceGroup("eks", "rollingUpdate", &EKSSpec{
+					MaxSize: 1,
+					MinSize: 1,
+					Type:    "LaunchTemplate",
+					EKSConfiguration: &EKSConfiguration{
+						EksClusterName:     "my-eks-cluster",
+						NodeSecurityGroups: []string{"sg-123456789"},
+						Image:              "ami-12345",
+						InstanceType:       "m5.large",
+						KeyPairName:        "thisShouldBeOptional",
+						Subnets:            []string{"subnet-1111111", "subnet-222222"},
+						LifecycleHooks: []LifecycleHookSpec{
+							{Name: "my-hook", Lifecycle: "launch", RoleArn: "arn:aws:sns:us-west-2:123456789012:my-topic"},
+						},
+					},
+				}, nil, nil),
+			},
+			want: "validation failed, 'roleArn' of lifecycle hook 'my-hook' must be a valid IAM role ARN, got 'arn:aws:sns:us-west-2:123456789012:my-topic'",
+		},
+		{
+			name: "eks with lifecycle hook non-queue notificationArn",
+			args: args{
+				instancegroup: MockInstanceGroup("eks", "rollingUpdate", &EKSSpec{
+					MaxSize: 1,
+					MinSize: 1,
+					Type:    "LaunchTemplate",
+					EKSConfiguration: &EKSConfiguration{
+						EksClusterName:     "my-eks-cluster",
+						NodeSecurityGroups: []string{"sg-123456789"},
+						Image:              "ami-12345",
+						InstanceType:       "m5.large",
+						KeyPairName:        "thisShouldBeOptional",
+						Subnets:            []string{"subnet-1111111", "subnet-222222"},
+						LifecycleHooks: []LifecycleHookSpec{
+							{Name: "my-hook", Lifecycle: "launch", NotificationArn: "arn:aws:iam::123456789012:role/my-role"},
+						},
+					},
+				}, nil, nil),
+			},
+			want: "validation failed, 'notificationArn' of lifecycle hook 'my-hook' must be a valid SNS topic or SQS queue ARN, got 'arn:aws:iam::123456789012:role/my-role'",
+		},
+		{
+			name: "eks with lifecycle hook ssm document",
+			args: args{
+				instancegroup: MockInstanceGroup("eks", "rollingUpdate", &EKSSpec{
+					MaxSize: 1,
+					MinSize: 1,
+					Type:    "LaunchTemplate",
+					EKSConfiguration: &EKSConfiguration{
+						EksClusterName:     "my-eks-cluster",
+						NodeSecurityGroups: []string{"sg-123456789"},
+						Image:              "ami-12345",
+						InstanceType:       "m5.large",
+						KeyPairName:        "thisShouldBeOptional",
+						Subnets:            []string{"subnet-1111111", "subnet-222222"},
+						LifecycleHooks: []LifecycleHookSpec{
+							{Name: "my-hook", Lifecycle: "launch", SSMDocument: &LifecycleHookDocument{Name: "my-document", Parameters: map[string][]string{"Mode": {"strict"}}}},
+						},
+					},
+				}, nil, nil),
+			},
+			want: "",
+		},
+		{
+			name: "eks with lifecycle hook ssm document without name",
+			args: args{
+				instancegroup: MockInstanceGroup("eks", "rollingUpdate", &EKSSpec{
+					MaxSize: 1,
+					MinSize: 1,
+					Type:    "LaunchTemplate",
+					EKSConfiguration: &EKSConfiguration{
+						EksClusterName:     "my-eks-cluster",
+						NodeSecurityGroups: []string{"sg-123456789"},
+						Image:              "ami-12345",
+						InstanceType:       "m5.large",
+						KeyPairName:        "thisShouldBeOptional",
+						Subnets:            []string{"subnet-1111111", "subnet-222222"},
+						LifecycleHooks: []LifecycleHookSpec{
+							{Name: "my-hook", Lifecycle: "launch", SSMDocument: &LifecycleHookDocument{}},
+						},
+					},
+				}, nil, nil),
+			},
+			want: "validation failed, 'ssmDocument.name' of lifecycle hook 'my-hook' is a required parameter",
+		},
+		{
+			name: "eks with lifecycle hook ssm document overriding the instance id parameter",
+			args: args{
+				instancegroup: MockInstanceGroup("eks", "rollingUpdate", &EKSSpec{
+					MaxSize: 1,
+					MinSize: 1,
+					Type:    "LaunchTemplate",
+					EKSConfiguration: &EKSConfiguration{
+						EksClusterName:     "my-eks-cluster",
+						NodeSecurityGroups: []string{"sg-123456789"},
+						Image:              "ami-12345",
+						InstanceType:       "m5.large",
+						KeyPairName:        "thisShouldBeOptional",
+						Subnets:            []string{"subnet-1111111", "subnet-222222"},
+						LifecycleHooks: []LifecycleHookSpec{
+							{Name: "my-hook", Lifecycle: "launch", SSMDocument: &LifecycleHookDocument{Name: "my-document", Parameters: map[string][]string{"InstanceId": {"i-1234"}}}},
+						},
+					},
+				}, nil, nil),
+			},
+			want: "validation failed, 'ssmDocument.parameters' of lifecycle hook 'my-hook' cannot contain the instance ID parameter 'InstanceId'",
+		},
+		{
+			name: "eks with instance metadata tags and disabled metadata endpoint",
+			args: args{
+				instancegroup: MockInstanceGroup("eks", "rollingUpdate", &EKSSpec{
+					MaxSize: 1,
+					MinSize: 1,
+					Type:    "LaunchTemplate",
+					EKSConfiguration: &EKSConfiguration{
+						EksClusterName:     "my-eks-cluster",
+						NodeSecurityGroups: []string{"sg-123456789"},
+						Image:              "ami-12345",
+						InstanceType:       "m5.large",
+						KeyPairName:        "thisShouldBeOptional",
+						Subnets:            []string{"subnet-1111111", "subnet-222222"},
+						MetadataOptions: &MetadataOptions{
+							HttpEndpoint:         "disabled",
+							InstanceMetadataTags: true,
+						},
+					},
+				}, nil, nil),
+			},
+			want: "validation failed, 'instanceMetadataTags' requires 'httpEndpoint' to be enabled",
+		},
+		{
+			name: "eks with invalid taint effect",
+			args: args{
+				instancegroup: MockInstanceGroup("eks", "rollingUpdate", &EKSSpec{
+					MaxSize: 1,
+					MinSize: 1,
+					Type:    "LaunchTemplate",
+					EKSConfiguration: &EKSConfiguration{
+						EksClusterName:     "my-eks-cluster",
+						NodeSecurityGroups: []string{"sg-123456789"},
+						Image:              "ami-12345",
+						InstanceType:       "m5.large",
+						KeyPairName:        "thisShouldBeOptional",
+						Subnets:            []string{"subnet-1111111", "subnet-222222"},
+						Taints: []corev1.Taint{
+							{Key: "dedicated", Value: "infra", Effect: "NoScheduleAtAll"},
+						},
+					},
+				}, nil, nil),
+			},
+			want: "validation failed, effect of taint 'dedicated' must be one of [NoSchedule PreferNoSchedule NoExecute]",
+		},
+		{
+			name: "eks with taint without key",
+			args: args{
+				instancegroup: MockInstanceGroup("eks", "rollingUpdate", &EKSSpec{
+					MaxSize: 1,
+					MinSize: 1,
+					Type:    "LaunchTemplate",
+					EKSConfiguration: &EKSConfiguration{
+						EksClusterName:     "my-eks-cluster",
+						NodeSecurityGroups: []string{"sg-123456789"},
+						Image:              "ami-12345",
+						InstanceType:       "m5.large",
+						KeyPairName:        "thisShouldBeOptional",
+						Subnets:            []string{"subnet-1111111", "subnet-222222"},
+						Taints: []corev1.Taint{
+							{Value: "infra", Effect: corev1.TaintEffectNoSchedule},
+						},
+					},
+				}, nil, nil),
+			},
+			want: "validation failed, 'taints' must have a key",
+		},
+		{
+			name: "eks with taint without value",
+			args: args{
+				instancegroup: MockInstanceGroup("eks", "rollingUpdate", &EKSSpec{
+					MaxSize: 1,
+					MinSize: 1,
+					Type:    "LaunchTemplate",
+					EKSConfiguration: &EKSConfiguration{
+						EksClusterName:     "my-eks-cluster",
+						NodeSecurityGroups: []string{"sg-123456789"},
+						Image:              "ami-12345",
+						InstanceType:       "m5.large",
+						KeyPairName:        "thisShouldBeOptional",
+						Subnets:            []string{"subnet-1111111", "subnet-222222"},
+						Taints: []corev1.Taint{
+							{Key: "dedicated", Effect: corev1.TaintEffectNoSchedule},
+						},
+					},
+				}, nil, nil),
+			},
+			want: "",
+		},
+		{
+			name: "eks with taint key with multiple effects",
+			args: args{
+				instancegroup: MockInstanceGroup("eks", "rollingUpdate", &EKSSpec{
+					MaxSize: 1,
+					MinSize: 1,
+					Type:    "LaunchTemplate",
+					EKSConfiguration: &EKSConfiguration{
+						EksClusterName:     "my-eks-cluster",
+						NodeSecurityGroups: []string{"sg-123456789"},
+						Image:              "ami-12345",
+						InstanceType:       "m5.large",
+						KeyPairName:        "thisShouldBeOptional",
+						Subnets:            []string{"subnet-1111111", "subnet-222222"},
+						Taints: []corev1.Taint{
+							{Key: "dedicated", Value: "infra", Effect: corev1.TaintEffectNoSchedule},
+							{Key: "dedicated", Value: "infra", Effect: corev1.TaintEffectNoExecute},
+						},
+					},
+				}, nil, nil),
+			},
+			want: "",
+		},
+		{
+			name: "eks with duplicate taint",
+			args: args{
+				instancegroup: MockInstanceGroup("eks", "rollingUpdate", &EKSSpec{
+					MaxSize: 1,
+					MinSize: 1,
+					Type:    "LaunchTemplate",
+					EKSConfiguration: &EKSConfiguration{
+						EksClusterName:     "my-eks-cluster",
+						NodeSecurityGroups: []string{"sg-123456789"},
+						Image:              "ami-12345",
+						InstanceType:       "m5.large",
+						KeyPairName:        "thisShouldBeOptional",
+						Subnets:            []string{"subnet-1111111", "subnet-222222"},
+						Taints: []corev1.Taint{
+							{Key: "dedicated", Value: "infra", Effect: corev1.TaintEffectNoSchedule},
+							{Key: "dedicated", Value: "system", Effect: corev1.TaintEffectNoSchedule},
+						},
+					},
+				}, nil, nil),
+			},
+			want: "validation failed, taint 'dedicated' with effect 'NoSchedule' must be unique",
+		},
+		{
+			name: "eks with capacity reservation id and preference",
+			args: args{
+				instancegroup: MockInstanceGroup("eks", "rollingUpdate", &EKSSpec{
+					MaxSize: 1,
+					MinSize: 1,
+					Type:    "LaunchTemplate",
+					EKSConfiguration: &EKSConfiguration{
+						EksClusterName:                "my-eks-cluster",
+						NodeSecurityGroups:            []string{"sg-123456789"},
+						Image:                         "ami-12345",
+						InstanceType:                  "m5.large",
+						KeyPairName:                   "thisShouldBeOptional",
+						Subnets:                       []string{"subnet-1111111", "subnet-222222"},
+						CapacityReservationId:         "cr-0123456789abcdef0",
+						CapacityReservationPreference: "open",
+					},
+				}, nil, nil),
+			},
+			want: "validation failed, 'capacityReservationId' and 'capacityReservationPreference' are mutually exclusive",
+		},
+		{
+			name: "eks with invalid capacity reservation preference",
+			args: args{
+				instancegroup: MockInstanceGroup("eks", "rollingUpdate", &EKSSpec{
+					MaxSize: 1,
+					MinSize: 1,
+					Type:    "LaunchTemplate",
+					EKSConfiguration: &EKSConfiguration{
+						EksClusterName:                "my-eks-cluster",
+						NodeSecurityGroups:            []string{"sg-123456789"},
+						Image:                         "ami-12345",
+						InstanceType:                  "m5.large",
+						KeyPairName:                   "thisShouldBeOptional",
+						Subnets:                       []string{"subnet-1111111", "subnet-222222"},
+						CapacityReservationPreference: "always",
+					},
+				}, nil, nil),
+			},
+			want: "validation failed, 'capacityReservationPreference' must be one of [open none]",
+		},
+		{
+			name: "eks with capacity reservation in launch configuration",
+			args: args{
+				instancegroup: MockInstanceGroup("eks", "rollingUpdate", &EKSSpec{
+					MaxSize: 1,
+					MinSize: 1,
+					Type:    "LaunchConfiguration",
+					EKSConfiguration: &EKSConfiguration{
+						EksClusterName:        "my-eks-cluster",
+						NodeSecurityGroups:    []string{"sg-123456789"},
+						Image:                 "ami-12345",
+						InstanceType:          "m5.large",
+						KeyPairName:           "thisShouldBeOptional",
+						Subnets:               []string{"subnet-1111111", "subnet-222222"},
+						CapacityReservationId: "cr-0123456789abcdef0",
+					},
+				}, nil, nil),
+			},
+			want: "validation failed, capacity reservations are only valid for LaunchTemplates",
+		},
+		{
+			name: "eks with existing launch template in launch configuration",
+			args: args{
+				instancegroup: MockInstanceGroup("eks", "rollingUpdate", &EKSSpec{
+					MaxSize: 1,
+					MinSize: 1,
+					Type:    "LaunchConfiguration",
+					EKSConfiguration: &EKSConfiguration{
+						EksClusterName:             "my-eks-cluster",
+						NodeSecurityGroups:         []string{"sg-123456789"},
+						Image:                      "ami-12345",
+						InstanceType:               "m5.large",
+						KeyPairName:                "thisShouldBeOptional",
+						Subnets:                    []string{"subnet-1111111", "subnet-222222"},
+						ExistingLaunchTemplateName: "my-launch-template",
+					},
+				}, nil, nil),
+			},
+			want: "validation failed, field 'launchTemplateName' is only valid for LaunchTemplates",
+		},
+		{
+			name: "eks with invalid dependency",
+			args: args{
+				instancegroup: MockInstanceGroup("eks", "rollingUpdate", &EKSSpec{
+					MaxSize: 1,
+					MinSize: 1,
+					Type:    "LaunchTemplate",
+					EKSConfiguration: &EKSConfiguration{
+						EksClusterName:     "my-eks-cluster",
+						NodeSecurityGroups: []string{"sg-123456789"},
+						Image:              "ami-12345",
+						InstanceType:       "m5.large",
+						KeyPairName:        "thisShouldBeOptional",
+						Subnets:            []string{"subnet-1111111", "subnet-222222"},
+					},
+					DependsOn: []string{"system/instance-group/1"},
+				}, nil, nil),
+			},
+			want: "validation failed, 'dependsOn' entry 'system/instance-group/1' must be a name or namespace/name",
+		},
+		{
+			name: "eks with negative node readiness timeout",
+			args: args{
+				instancegroup: MockInstanceGroup("eks", "rollingUpdate", &EKSSpec{
+					MaxSize: 1,
+					MinSize: 1,
+					Type:    "LaunchConfiguration",
+					EKSConfiguration: &EKSConfiguration{
+						EksClusterName:     "my-eks-cluster",
+						NodeSecurityGroups: []string{"sg-123456789"},
+						Image:              "ami-12345",
+						InstanceType:       "m5.large",
+						KeyPairName:        "thisShouldBeOptional",
+						Subnets:            []string{"subnet-1111111", "subnet-222222"},
+					},
+					NodeReadinessTimeout: -1,
+				}, nil, nil),
+			},
+			want: "validation failed, 'nodeReadinessTimeout' must be non-negative, got '-1'",
+		},
+		{
+			name: "eks with invalid image tag",
+			args: args{
+				instancegroup: MockInstanceGroup("eks", "rollingUpdate", &EKSSpec{
+					MaxSize: 1,
+					MinSize: 1,
+					Type:    "LaunchConfiguration",
+					EKSConfiguration: &EKSConfiguration{
+						EksClusterName:     "my-eks-cluster",
+						NodeSecurityGroups: []string{"sg-123456789"},
+						Image:              "tag:channel",
+						InstanceType:       "m5.large",
+						KeyPairName:        "thisShouldBeOptional",
+						Subnets:            []string{"subnet-1111111", "subnet-222222"},
+					},
+				}, nil, nil),
+			},
+			want: "validation failed, 'image' must be in the form tag:key=value to resolve an AMI by tag, got 'tag:channel'",
+		},
+		{
+			name: "eks with invalid cluster ca",
+			args: args{
+				instancegroup: MockInstanceGroup("eks", "rollingUpdate", &EKSSpec{
+					MaxSize: 1,
+					MinSize: 1,
+					Type:    "LaunchConfiguration",
+					EKSConfiguration: &EKSConfiguration{
+						EksClusterName:     "my-eks-cluster",
+						NodeSecurityGroups: []string{"sg-123456789"},
+						Image:              "ami-12345",
+						InstanceType:       "m5.large",
+						KeyPairName:        "thisShouldBeOptional",
+						Subnets:            []string{"subnet-1111111", "subnet-222222"},
+						ClusterCA:          "not base64!",
+					},
+				}, nil, nil),
+			},
+			want: "validation failed, 'clusterCA' must be base64 encoded",
+		},
+		{
+			name: "eks with invalid api server endpoint",
+			args: args{
+				instancegroup: MockInstanceGroup("eks", "rollingUpdate", &EKSSpec{
+					MaxSize: 1,
+					MinSize: 1,
+					Type:    "LaunchConfiguration",
+					EKSConfiguration: &EKSConfiguration{
+						EksClusterName:     "my-eks-cluster",
+						NodeSecurityGroups: []string{"sg-123456789"},
+						Image:              "ami-12345",
+						InstanceType:       "m5.large",
+						KeyPairName:        "thisShouldBeOptional",
+						Subnets:            []string{"subnet-1111111", "subnet-222222"},
+						ApiServerEndpoint:  "api.example.internal",
+					},
+				}, nil, nil),
+			},
+			want: "validation failed, 'apiServerEndpoint' must be a valid https URL, got 'api.example.internal'",
+		},
+		{
+			name: "eks with cluster overrides",
+			args: args{
+				instancegroup: MockInstanceGroup("eks", "rollingUpdate", &EKSSpec{
+					MaxSize: 1,
+					MinSize: 1,
+					Type:    "LaunchConfiguration",
+					EKSConfiguration: &EKSConfiguration{
+						EksClusterName:     "my-eks-cluster",
+						NodeSecurityGroups: []string{"sg-123456789"},
+						Image:              "ami-12345",
+						InstanceType:       "m5.large",
+						KeyPairName:        "thisShouldBeOptional",
+						Subnets:            []string{"subnet-1111111", "subnet-222222"},
+						ClusterCA:          "dGVzdA==",
+						ApiServerEndpoint:  "https://api.example.internal",
+					},
+				}, nil, nil),
+			},
+			want: "",
+		},
+		{
+			name: "eks with capacity reservation id",
+			args: args{
+				instancegroup: MockInstanceGroup("eks", "rollingUpdate", &EKSSpec{
+					MaxSize: 1,
+					MinSize: 1,
+					Type:    "LaunchTemplate",
+					EKSConfiguration: &EKSConfiguration{
+						EksClusterName:        "my-eks-cluster",
+						NodeSecurityGroups:    []string{"sg-123456789"},
+						Image:                 "ami-12345",
+						InstanceType:          "m5.large",
+						KeyPairName:           "thisShouldBeOptional",
+						Subnets:               []string{"subnet-1111111", "subnet-222222"},
+						CapacityReservationId: "cr-0123456789abcdef0",
+					},
+				}, nil, nil),
+			},
+			want: "",
+		},
+		{
+			name: "eks with instance metadata tags in launch configuration",
+			args: args{
+				instancegroup: MockInstanceGroup("eks", "rollingUpdate", &EKSSpec{
+					MaxSize: 1,
+					MinSize: 1,
+					Type:    "LaunchConfiguration",
+					EKSConfiguration: &EKSConfiguration{
+						EksClusterName:     "my-eks-cluster",
+						NodeSecurityGroups: []string{"sg-123456789"},
+						Image:              "ami-12345",
+						InstanceType:       "m5.large",
+						KeyPairName:        "thisShouldBeOptional",
+						Subnets:            []string{"subnet-1111111", "subnet-222222"},
+						MetadataOptions: &MetadataOptions{
+							HttpEndpoint:         "enabled",
+							InstanceMetadataTags: true,
+						},
+					},
+				}, nil, nil),
+			},
+			want: "validation failed, field 'instanceMetadataTags' is only valid for LaunchTemplates",
+		},
+		{
+			name: "eks with instance metadata tags",
+			args: args{
+				instancegroup: MockInstanceGroup("eks", "rollingUpdate", &EKSSpec{
+					MaxSize: 1,
+					MinSize: 1,
+					Type:    "LaunchTemplate",
+					EKSConfiguration: &EKSConfiguration{
+						EksClusterName:     "my-eks-cluster",
+						NodeSecurityGroups: []string{"sg-123456789"},
+						Image:              "ami-12345",
+						InstanceType:       "m5.large",
+						KeyPairName:        "thisShouldBeOptional",
+						Subnets:            []string{"subnet-1111111", "subnet-222222"},
+						MetadataOptions: &MetadataOptions{
+							HttpEndpoint:         "enabled",
+							InstanceMetadataTags: true,
+						},
+					},
+				}, nil, nil),
+			},
+			want: "",
+		},
+		{
+			name: "eks with invalid health check type",
+			args: args{
+				instancegroup: MockInstanceGroup("eks", "rollingUpdate", &EKSSpec{
+					MaxSize: 1,
+					MinSize: 1,
+					Type:    "LaunchTemplate",
+					EKSConfiguration: &EKSConfiguration{
+						EksClusterName:     "my-eks-cluster",
+						NodeSecurityGroups: []string{"sg-123456789"},
+						Image:              "ami-12345",
+						InstanceType:       "m5.large",
+						KeyPairName:        "thisShouldBeOptional",
+						Subnets:            []string{"subnet-1111111", "subnet-222222"},
+						HealthCheckType:    "TCP",
+					},
+				}, nil, nil),
+			},
+			want: "validation failed, 'healthCheckType' must be one of [EC2 ELB]",
+		},
+		{
+			name: "eks with negative health check grace period",
+			args: args{
+				instancegroup: MockInstanceGroup("eks", "rollingUpdate", &EKSSpec{
+					MaxSize: 1,
+					MinSize: 1,
+					Type:    "LaunchTemplate",
+					EKSConfiguration: &EKSConfiguration{
+						EksClusterName:         "my-eks-cluster",
+						NodeSecurityGroups:     []string{"sg-123456789"},
+						Image:                  "ami-12345",
+						InstanceType:           "m5.large",
+						KeyPairName:            "thisShouldBeOptional",
+						Subnets:                []string{"subnet-1111111", "subnet-222222"},
+						HealthCheckType:        "elb",
+						HealthCheckGracePeriod: -1,
+					},
+				}, nil, nil),
+			},
+			want: "validation failed, 'healthCheckGracePeriod' must be a positive value",
+		},
+		{
+			name: "eks with negative default cooldown",
+			args: args{
+				instancegroup: MockInstanceGroup("eks", "rollingUpdate", &EKSSpec{
+					MaxSize: 1,
+					MinSize: 1,
+					Type:    "LaunchTemplate",
+					EKSConfiguration: &EKSConfiguration{
+						EksClusterName:     "my-eks-cluster",
+						NodeSecurityGroups: []string{"sg-123456789"},
+						Image:              "ami-12345",
+						InstanceType:       "m5.large",
+						KeyPairName:        "thisShouldBeOptional",
+						Subnets:            []string{"subnet-1111111", "subnet-222222"},
+						DefaultCooldown:    -1,
+					},
+				}, nil, nil),
+			},
+			want: "validation failed, 'defaultCooldown' must be a positive value",
+		},
+		{
+			name: "eks with invalid metrics granularity",
+			args: args{
+				instancegroup: MockInstanceGroup("eks", "rollingUpdate", &EKSSpec{
+					MaxSize: 1,
+					MinSize: 1,
+					Type:    "LaunchTemplate",
+					EKSConfiguration: &EKSConfiguration{
+						EksClusterName:     "my-eks-cluster",
+						NodeSecurityGroups: []string{"sg-123456789"},
+						Image:              "ami-12345",
+						InstanceType:       "m5.large",
+						KeyPairName:        "thisShouldBeOptional",
+						Subnets:            []string{"subnet-1111111", "subnet-222222"},
+						MetricsGranularity: "5Minute",
+					},
+				}, nil, nil),
+			},
+			want: "validation failed, 'metricsGranularity' must be one of [1Minute]",
+		},
+		{
+			name: "eks with elb health check",
+			args: args{
+				instancegroup: MockInstanceGroup("eks", "rollingUpdate", &EKSSpec{
+					MaxSize: 1,
+					MinSize: 1,
+					Type:    "LaunchTemplate",
+					EKSConfiguration: &EKSConfiguration{
+						EksClusterName:         "my-eks-cluster",
+						NodeSecurityGroups:     []string{"sg-123456789"},
+						Image:                  "ami-12345",
+						InstanceType:           "m5.large",
+						KeyPairName:            "thisShouldBeOptional",
+						Subnets:                []string{"subnet-1111111", "subnet-222222"},
+						HealthCheckType:        "elb",
+						HealthCheckGracePeriod: 300,
+					},
+				}, nil, nil),
+			},
+			want: "",
+		},
+		{
+			name: "eks with invalid target group arn",
+			args: args{
+				instancegroup: MockInstanceGroup("eks", "rollingUpdate", &EKSSpec{
+					MaxSize: 1,
+					MinSize: 1,
+					Type:    "LaunchTemplate",
+					EKSConfiguration: &EKSConfiguration{
+						EksClusterName:     "my-eks-cluster",
+						NodeSecurityGroups: []string{"sg-123456789"},
+						Image:              "ami-12345",
+						InstanceType:       "m5.large",
+						KeyPairName:        "thisShouldBeOptional",
+						Subnets:            []string{"subnet-1111111", "subnet-222222"},
+						TargetGroupARNs:    []string{"arn:aws:elasticloadbalancing:us-west-2:123456789012:loadbalancer/app/my-lb/50dc6c495c0c9188"},
+					},
+				}, nil, nil),
+			},
+			want: "validation failed, 'arn:aws:elasticloadbalancing:us-west-2:123456789012:loadbalancer/app/my-lb/50dc6c495c0c9188' in 'targetGroupARNs' must be a valid target group ARN",
+		},
+		{
+			name: "eks with target groups and load balancers",
+			args: args{
+				instancegroup: MockInstanceGroup("eks", "rollingUpdate", &EKSSpec{
+					MaxSize: 1,
+					MinSize: 1,
+					Type:    "LaunchTemplate",
+					EKSConfiguration: &EKSConfiguration{
+						EksClusterName:     "my-eks-cluster",
+						NodeSecurityGroups: []string{"sg-123456789"},
+						Image:              "ami-12345",
+						InstanceType:       "m5.large",
+						KeyPairName:        "thisShouldBeOptional",
+						Subnets:            []string{"subnet-1111111", "subnet-222222"},
+						TargetGroupARNs:    []string{"arn:aws:elasticloadbalancing:us-west-2:123456789012:targetgroup/my-targets/73e2d6bc24d8a067"},
+						LoadBalancerNames:  []string{"my-classic-lb"},
+					},
+				}, nil, nil),
+			},
+			want: "",
+		},
+		{
+			name: "eks with instance type image override",
+			args: args{
+				instancegroup: MockInstanceGroup("eks", "rollingUpdate", &EKSSpec{
+					MaxSize: 1,
+					MinSize: 1,
+					Type:    "LaunchTemplate",
+					EKSConfiguration: &EKSConfiguration{
+						EksClusterName:     "my-eks-cluster",
+						NodeSecurityGroups: []string{"sg-123456789"},
+						Image:              "ami-12345",
+						InstanceType:       "m5.large",
+						KeyPairName:        "thisShouldBeOptional",
+						Subnets:            []string{"subnet-1111111", "subnet-222222"},
+						MixedInstancesPolicy: &MixedInstancesPolicySpec{
+							InstanceTypes: []*InstanceTypeSpec{
+								{
+									Type:  "m6g.large",
+									Image: "ami-67890",
+								},
+							},
+						},
+					},
+				}, nil, nil),
+			},
+			want: "",
+		},
+		{
+			name: "eks with invalid instance type image override",
+			args: args{
+				instancegroup: MockInstanceGroup("eks", "rollingUpdate", &EKSSpec{
+					MaxSize: 1,
+					MinSize: 1,
+					Type:    "LaunchTemplate",
+					EKSConfiguration: &EKSConfiguration{
+						EksClusterName:     "my-eks-cluster",
+						NodeSecurityGroups: []string{"sg-123456789"},
+						Image:              "ami-12345",
+						InstanceType:       "m5.large",
+						KeyPairName:        "thisShouldBeOptional",
+						Subnets:            []string{"subnet-1111111", "subnet-222222"},
+						MixedInstancesPolicy: &MixedInstancesPolicySpec{
+							InstanceTypes: []*InstanceTypeSpec{
+								{
+									Type:  "m6g.large",
+									Image: "ssm://some-parameter",
+								},
+							},
+						},
+					},
+				}, nil, nil),
+			},
+			want: "validation failed, 'image' of instance type 'm6g.large' must be an AMI ID, got 'ssm://some-parameter'",
+		},
+		{
+			name: "eks with instance pool families",
+			args: args{
+				instancegroup: MockInstanceGroup("eks", "rollingUpdate", &EKSSpec{
+					MaxSize: 1,
+					MinSize: 1,
+					Type:    "LaunchTemplate",
+					EKSConfiguration: &EKSConfiguration{
+						EksClusterName:       "my-eks-cluster",
+						NodeSecurityGroups:   []string{"sg-123456789"},
+						Image:                "ami-12345",
+						InstanceType:         "m5.large",
+						KeyPairName:          "thisShouldBeOptional",
+						Subnets:              []string{"subnet-1111111", "subnet-222222"},
+						MixedInstancesPolicy: &MixedInstancesPolicySpec{InstancePool: &subFamilyFlexible, InstancePoolFamilies: [][]string{{"m5", "m5a", "m5n"}, {"c5", "c5a"}}},
+					},
+				}, nil, nil),
+			},
+			want: "",
+		},
+		{
+			name: "eks with instance pool families without instance pool",
+			args: args{
+				instancegroup: MockInstanceGroup("eks", "rollingUpdate", &EKSSpec{
+					MaxSize: 1,
+					MinSize: 1,
+					Type:    "LaunchTemplate",
+					EKSConfiguration: &EKSConfiguration{
+						EksClusterName:       "my-eks-cluster",
+						NodeSecurityGroups:   []string{"sg-123456789"},
+						Image:                "ami-12345",
+						InstanceType:         "m5.large",
+						KeyPairName:          "thisShouldBeOptional",
+						Subnets:              []string{"subnet-1111111", "subnet-222222"},
+						MixedInstancesPolicy: &MixedInstancesPolicySpec{InstanceTypes: []*InstanceTypeSpec{{Type: "m5a.large"}}, InstancePoolFamilies: [][]string{{"m5", "m5a"}}},
+					},
+				}, nil, nil),
+			},
+			want: "validation failed, 'instancePoolFamilies' can only be used with 'instancePool'",
+		},
+		{
+			name: "eks with instance pool families containing instance types",
+			args: args{
+				instancegroup: MockInstanceGroup("eks", "rollingUpdate", &EKSSpec{
+					MaxSize: 1,
+					MinSize: 1,
+					Type:    "LaunchTemplate",
+					EKSConfiguration: &EKSConfiguration{
+						EksClusterName:       "my-eks-cluster",
+						NodeSecurityGroups:   []string{"sg-123456789"},
+						Image:                "ami-12345",
+						InstanceType:         "m5.large",
+						KeyPairName:          "thisShouldBeOptional",
+						Subnets:              []string{"subnet-1111111", "subnet-222222"},
+						MixedInstancesPolicy: &MixedInstancesPolicySpec{InstancePool: &subFamilyFlexible, InstancePoolFamilies: [][]string{{"m5.large", "m5a"}}},
+					},
+				}, nil, nil),
+			},
+			want: "validation failed, 'instancePoolFamilies[0]' must contain instance classes, e.g. m5, got 'm5.large'",
+		},
+		{
+			name: "eks with overlapping instance pool families",
+			args: args{
+				instancegroup: MockInstanceGroup("eks", "rollingUpdate", &EKSSpec{
+					MaxSize: 1,
+					MinSize: 1,
+					Type:    "LaunchTemplate",
+					EKSConfiguration: &EKSConfiguration{
+						EksClusterName:       "my-eks-cluster",
+						NodeSecurityGroups:   []string{"sg-123456789"},
+						Image:                "ami-12345",
+						InstanceType:         "m5.large",
+						KeyPairName:          "thisShouldBeOptional",
+						Subnets:              []string{"subnet-1111111", "subnet-222222"},
+						MixedInstancesPolicy: &MixedInstancesPolicySpec{InstancePool: &subFamilyFlexible, InstancePoolFamilies: [][]string{{"m5", "m5a"}, {"m5a", "m5n"}}},
+					},
+				}, nil, nil),
+			},
+			want: "validation failed, instance class 'm5a' can only be part of a single instance pool family",
+		},
+		{
+			name: "eks with vcpu weighted capacity",
+			args: args{
+				instancegroup: MockInstanceGroup("eks", "rollingUpdate", &EKSSpec{
+					MaxSize: 16,
+					MinSize: 4,
+					Type:    "LaunchTemplate",
+					EKSConfiguration: &EKSConfiguration{
+						EksClusterName:       "my-eks-cluster",
+						NodeSecurityGroups:   []string{"sg-123456789"},
+						Image:                "ami-12345",
+						InstanceType:         "m5.large",
+						KeyPairName:          "thisShouldBeOptional",
+						Subnets:              []string{"subnet-1111111", "subnet-222222"},
+						MixedInstancesPolicy: &MixedInstancesPolicySpec{InstancePool: &subFamilyFlexible, WeightedCapacityUnit: aws.String("VCPU")},
+					},
+				}, nil, nil),
+			},
+			want: "",
+		},
+		{
+			name: "eks with unknown weighted capacity unit",
+			args: args{
+				instancegroup: MockInstanceGroup("eks", "rollingUpdate", &EKSSpec{
+					MaxSize: 16,
+					MinSize: 4,
+					Type:    "LaunchTemplate",
+					EKSConfiguration: &EKSConfiguration{
+						EksClusterName:       "my-eks-cluster",
+						NodeSecurityGroups:   []string{"sg-123456789"},
+						Image:                "ami-12345",
+						InstanceType:         "m5.large",
+						KeyPairName:          "thisShouldBeOptional",
+						Subnets:              []string{"subnet-1111111", "subnet-222222"},
+						MixedInstancesPolicy: &MixedInstancesPolicySpec{InstancePool: &subFamilyFlexible, WeightedCapacityUnit: aws.String("memory")},
+					},
+				}, nil, nil),
+			},
+			want: "validation failed, mixedInstancesPolicy.weightedCapacityUnit must be one of [Instance VCPU], got 'memory'",
+		},
+		{
+			name: "eks with vcpu weighted capacity and instance type weights",
+			args: args{
+				instancegroup: MockInstanceGroup("eks", "rollingUpdate", &EKSSpec{
+					MaxSize: 16,
+					MinSize: 4,
+					Type:    "LaunchTemplate",
+					EKSConfiguration: &EKSConfiguration{
+						EksClusterName:       "my-eks-cluster",
+						NodeSecurityGroups:   []string{"sg-123456789"},
+						Image:                "ami-12345",
+						InstanceType:         "m5.large",
+						KeyPairName:          "thisShouldBeOptional",
+						Subnets:              []string{"subnet-1111111", "subnet-222222"},
+						MixedInstancesPolicy: &MixedInstancesPolicySpec{InstanceTypes: []*InstanceTypeSpec{{Type: "m5.xlarge", Weight: 2}}, WeightedCapacityUnit: aws.String("VCPU")},
+					},
+				}, nil, nil),
+			},
+			want: "validation failed, 'weight' of instance type 'm5.xlarge' cannot be used with weightedCapacityUnit 'VCPU'",
+		},
+		{
+			name: "eks with userData shebang",
+			args: args{
+				instancegroup: MockInstanceGroup("eks", "rollingUpdate", &EKSSpec{
+					MaxSize: 1,
+					MinSize: 1,
+					Type:    "LaunchTemplate",
+					EKSConfiguration: &EKSConfiguration{
+						EksClusterName:     "my-eks-cluster",
+						NodeSecurityGroups: []string{"sg-123456789"},
+						Image:              "ami-12345",
+						InstanceType:       "m5.large",
+						KeyPairName:        "thisShouldBeOptional",
+						Subnets:            []string{"subnet-1111111", "subnet-222222"},
+						BootstrapOptions: &BootstrapOptions{
+							UserDataShebang: "#!/bin/sh\nset -eu",
+						},
+					},
+				}, nil, nil),
+			},
+			want: "",
+		},
+		{
+			name: "eks with invalid userData shebang",
+			args: args{
+				instancegroup: MockInstanceGroup("eks", "rollingUpdate", &EKSSpec{
+					MaxSize: 1,
+					MinSize: 1,
+					Type:    "LaunchTemplate",
+					EKSConfiguration: &EKSConfiguration{
+						EksClusterName:     "my-eks-cluster",
+						NodeSecurityGroups: []string{"sg-123456789"},
+						Image:              "ami-12345",
+						InstanceType:       "m5.large",
+						KeyPairName:        "thisShouldBeOptional",
+						Subnets:            []string{"subnet-1111111", "subnet-222222"},
+						BootstrapOptions: &BootstrapOptions{
+							UserDataShebang: "/bin/bash",
+						},
+					},
+				}, nil, nil),
+			},
+			want: "validation failed, 'bootstrapOptions.userDataShebang' must start with '#!', got '/bin/bash'",
+		},
+		{
+			name: "eks with maintenance policy",
+			args: args{
+				instancegroup: MockInstanceGroup("eks", "rollingUpdate", &EKSSpec{
+					MaxSize: 1,
+					MinSize: 1,
+					Type:    "LaunchTemplate",
+					EKSConfiguration: &EKSConfiguration{
+						EksClusterName:     "my-eks-cluster",
+						NodeSecurityGroups: []string{"sg-123456789"},
+						Image:              "ami-12345",
+						InstanceType:       "m5.large",
+						KeyPairName:        "thisShouldBeOptional",
+						Subnets:            []string{"subnet-1111111", "subnet-222222"},
+						MaintenancePolicy:  &MaintenancePolicySpec{MinHealthyPercentage: aws.Int64(90), MaxHealthyPercentage: aws.Int64(110)},
+					},
+				}, nil, nil),
+			},
+			want: "",
+		},
+		{
+			name: "eks with partial maintenance policy",
+			args: args{
+				instancegroup: MockInstanceGroup("eks", "rollingUpdate", &EKSSpec{
+					MaxSize: 1,
+					MinSize: 1,
+					Type:    "LaunchTemplate",
+					EKSConfiguration: &EKSConfiguration{
+						EksClusterName:     "my-eks-cluster",
+						NodeSecurityGroups: []string{"sg-123456789"},
+						Image:              "ami-12345",
+						InstanceType:       "m5.large",
+						KeyPairName:        "thisShouldBeOptional",
+						Subnets:            []string{"subnet-1111111", "subnet-222222"},
+						MaintenancePolicy:  &MaintenancePolicySpec{MinHealthyPercentage: aws.Int64(90)},
+					},
+				}, nil, nil),
+			},
+			want: "validation failed, 'maintenancePolicy' must specify both 'minHealthyPercentage' and 'maxHealthyPercentage'",
+		},
+		{
+			name: "eks with invalid maintenance policy min",
+			args: args{
+				instancegroup: MockInstanceGroup("eks", "rollingUpdate", &EKSSpec{
+					MaxSize: 1,
+					MinSize: 1,
+					Type:    "LaunchTemplate",
+					EKSConfiguration: &EKSConfiguration{
+						EksClusterName:     "my-eks-cluster",
+						NodeSecurityGroups: []string{"sg-123456789"},
+						Image:              "ami-12345",
+						InstanceType:       "m5.large",
+						KeyPairName:        "thisShouldBeOptional",
+						Subnets:            []string{"subnet-1111111", "subnet-222222"},
+						MaintenancePolicy:  &MaintenancePolicySpec{MinHealthyPercentage: aws.Int64(101), MaxHealthyPercentage: aws.Int64(110)},
+					},
+				}, nil, nil),
+			},
+			want: "validation failed, 'maintenancePolicy.minHealthyPercentage' must be between 0 and 100, got '101'",
+		},
+		{
+			name: "eks with invalid maintenance policy max",
+			args: args{
+				instancegroup: MockInstanceGroup("eks", "rollingUpdate", &EKSSpec{
+					MaxSize: 1,
+					MinSize: 1,
+					Type:    "LaunchTemplate",
+					EKSConfiguration: &EKSConfiguration{
+						EksClusterName:     "my-eks-cluster",
+						NodeSecurityGroups: []string{"sg-123456789"},
+						Image:              "ami-12345",
+						InstanceType:       "m5.large",
+						KeyPairName:        "thisShouldBeOptional",
+						Subnets:            []string{"subnet-1111111", "subnet-222222"},
+						MaintenancePolicy:  &MaintenancePolicySpec{MinHealthyPercentage: aws.Int64(90), MaxHealthyPercentage: aws.Int64(80)},
+					},
+				}, nil, nil),
+			},
+			want: "validation failed, 'maintenancePolicy.maxHealthyPercentage' must be between 100 and 200, got '80'",
+		},
+		{
+			name: "eks with maintenance policy range too large",
+			args: args{
+				instancegroup: MockInstanceGroup("eks", "rollingUpdate", &EKSSpec{
+					MaxSize: 1,
+					MinSize: 1,
+					Type:    "LaunchTemplate",
+					EKSConfiguration: &EKSConfiguration{
+						EksClusterName:     "my-eks-cluster",
+						NodeSecurityGroups: []string{"sg-123456789"},
+						Image:              "ami-12345",
+						InstanceType:       "m5.large",
+						KeyPairName:        "thisShouldBeOptional",
+						Subnets:            []string{"subnet-1111111", "subnet-222222"},
+						MaintenancePolicy:  &MaintenancePolicySpec{MinHealthyPercentage: aws.Int64(50), MaxHealthyPercentage: aws.Int64(200)},
+					},
+				}, nil, nil),
+			},
+			want: "validation failed, 'maintenancePolicy' difference between 'minHealthyPercentage' and 'maxHealthyPercentage' cannot be greater than 100",
+		},
+		{
+			name: "eks with more tags than can be applied to a managed role",
+			args: args{
+				instancegroup: MockInstanceGroup("eks", "rollingUpdate", &EKSSpec{
+					MaxSize: 1,
+					MinSize: 1,
+					Type:    "LaunchTemplate",
+					EKSConfiguration: &EKSConfiguration{
+						EksClusterName:     "my-eks-cluster",
+						NodeSecurityGroups: []string{"sg-123456789"},
+						Image:              "ami-12345",
+						InstanceType:       "m5.large",
+						KeyPairName:        "thisShouldBeOptional",
+						Subnets:            []string{"subnet-1111111", "subnet-222222"},
+						Tags:               MockTags(48),
+					},
+				}, nil, nil),
+			},
+			want: "",
+		},
+		{
+			name: "eks with many tags and an existing role",
+			args: args{
+				instancegroup: MockInstanceGroup("eks", "rollingUpdate", &EKSSpec{
+					MaxSize: 1,
+					MinSize: 1,
+					Type:    "LaunchTemplate",
+					EKSConfiguration: &EKSConfiguration{
+						EksClusterName:     "my-eks-cluster",
+						NodeSecurityGroups: []string{"sg-123456789"},
+						Image:              "ami-12345",
+						InstanceType:       "m5.large",
+						KeyPairName:        "thisShouldBeOptional",
+						Subnets:            []string{"subnet-1111111", "subnet-222222"},
+						Tags:               MockTags(48),
+						ExistingRoleName:   "some-role",
+					},
+				}, nil, nil),
+			},
+			want: "",
+		},
+		{
+			name: "eks with duplicate volume device names",
+			args: args{
+				instancegroup: MockInstanceGroup("eks", "rollingUpdate", &EKSSpec{
+					MaxSize: 1,
+					MinSize: 1,
+					Type:    "LaunchTemplate",
+					EKSConfiguration: &EKSConfiguration{
+						EksClusterName:     "my-eks-cluster",
+						NodeSecurityGroups: []string{"sg-123456789"},
+						Image:              "ami-12345",
+						InstanceType:       "m5.large",
+						KeyPairName:        "thisShouldBeOptional",
+						Subnets:            []string{"subnet-1111111", "subnet-222222"},
+						Volumes: []NodeVolume{
+							{
+								Name: "/dev/xvda",
+								Type: "gp3",
+								Size: 32,
+							},
+							{
+								Name: "/dev/xvda",
+								Type: "gp3",
+								Size: 100,
+							},
+						},
+					},
+				}, nil, nil),
+			},
+			want: "validation failed, volume device name '/dev/xvda' must be unique",
+		},
+		{
+			name: "eks-fargate with empty selectors",
+			args: args{
+				instancegroup: MockInstanceGroup("eks-fargate", "managed", nil, nil, &EKSFargateSpec{
+					ClusterName: "my-eks-cluster",
+					Selectors:   []EKSFargateSelectors{},
+				}),
+			},
+			want: "validation failed, 'selectors' must contain at least one selector",
+		},
+		{
+			name: "eks-fargate with invalid selector namespace",
+			args: args{
+				instancegroup: MockInstanceGroup("eks-fargate", "managed", nil, nil, &EKSFargateSpec{
+					ClusterName: "my-eks-cluster",
+					Selectors: []EKSFargateSelectors{
+						{
+							Namespace: "Invalid_Namespace",
+						},
+					},
+				}),
+			},
+			want: "validation failed, selector namespace 'Invalid_Namespace' must be a valid DNS label",
+		},
+		{
+			name: "eks-fargate with too many selectors",
+			args: args{
+				instancegroup: MockInstanceGroup("eks-fargate", "managed", nil, nil, &EKSFargateSpec{
+					ClusterName: "my-eks-cluster",
+					Selectors: []EKSFargateSelectors{
+						{Namespace: "ns-1"}, {Namespace: "ns-2"}, {Namespace: "ns-3"}, {Namespace: "ns-4"}, {Namespace: "ns-5"}, {Namespace: "ns-6"},
+					},
+				}),
+			},
+			want: "validation failed, 'selectors' must not contain more than 5 selectors",
+		},
+		{
+			name: "eks-fargate with invalid pod execution role arn",
+			args: args{
+				instancegroup: MockInstanceGroup("eks-fargate", "managed", nil, nil, &EKSFargateSpec{
+					ClusterName:         "my-eks-cluster",
+					PodExecutionRoleArn: "my-role",
+					Selectors: []EKSFargateSelectors{
+						{
+							Namespace: "default",
+						},
+					},
+				}),
+			},
+			want: "validation failed, 'podExecutionRoleArn' must be a valid IAM role ARN, got 'my-role'",
+		},
+		{
+			name: "eks-fargate with managed policies and pod execution role arn",
+			args: args{
+				instancegroup: MockInstanceGroup("eks-fargate", "managed", nil, nil, &EKSFargateSpec{
+					ClusterName:         "my-eks-cluster",
+					PodExecutionRoleArn: "arn:aws:iam::123456789012:role/MyPodRole",
+					ManagedPolicies:     []string{"CloudWatchAgentServerPolicy"},
+					Selectors: []EKSFargateSelectors{
+						{
+							Namespace: "default",
+						},
+					},
+				}),
+			},
+			want: "validation failed, 'managedPolicies' cannot be used with 'podExecutionRoleArn'",
+		},
+		{
+			name: "eks with reserved tag key prefix",
+			args: args{
+				instancegroup: MockInstanceGroup("eks", "rollingUpdate", &EKSSpec{
+					MaxSize: 1,
+					MinSize: 1,
+					Type:    "LaunchTemplate",
+					EKSConfiguration: &EKSConfiguration{
+						EksClusterName:     "my-eks-cluster",
+						NodeSecurityGroups: []string{"sg-123456789"},
+						Image:              "ami-12345",
+						InstanceType:       "m5.large",
+						KeyPairName:        "thisShouldBeOptional",
+						Subnets:            []string{"subnet-1111111", "subnet-222222"},
+						Tags: []map[string]string{
+							{"key": "aws:cost-center", "value": "1234"},
+						},
+					},
+				}, nil, nil),
+			},
+			want: "validation failed, tag key 'aws:cost-center' cannot start with the reserved prefix 'aws:'",
+		},
+		{
+			name: "eks with tag value exceeding limit",
+			args: args{
+				instancegroup: MockInstanceGroup("eks", "rollingUpdate", &EKSSpec{
+					MaxSize: 1,
+					MinSize: 1,
+					Type:    "LaunchTemplate",
+					EKSConfiguration: &EKSConfiguration{
+						EksClusterName:     "my-eks-cluster",
+						NodeSecurityGroups: []string{"sg-123456789"},
+						Image:              "ami-12345",
+						InstanceType:       "m5.large",
+						KeyPairName:        "thisShouldBeOptional",
+						Subnets:            []string{"subnet-1111111", "subnet-222222"},
+						Tags: []map[string]string{
+							{"key": "cost-center", "value": strings.Repeat("a", 257)},
+						},
+					},
+				}, nil, nil),
+			},
+			want: "validation failed, value of tag 'cost-center' cannot be longer than 256 characters",
+		},
+		{
+			name: "eks with too many tags",
+			args: args{
+				instancegroup: MockInstanceGroup("eks", "rollingUpdate", &EKSSpec{
+					MaxSize: 1,
+					MinSize: 1,
+					Type:    "LaunchTemplate",
+					EKSConfiguration: &EKSConfiguration{
+						EksClusterName:     "my-eks-cluster",
+						NodeSecurityGroups: []string{"sg-123456789"},
+						Image:              "ami-12345",
+						InstanceType:       "m5.large",
+						KeyPairName:        "thisShouldBeOptional",
+						Subnets:            []string{"subnet-1111111", "subnet-222222"},
+						Tags:               make([]map[string]string, TagMaxCount+1),
+					},
+				}, nil, nil),
+			},
+			want: "validation failed, 'tags' cannot contain more than 50 tags",
+		},
+		{
+			name: "default to launch config instead of launch template",
+			args: args{
+				instancegroup: MockInstanceGroup("eks-fargate", "managed", nil, nil, basicFargateSpec()),
+				overrides: &ValidationOverrides{
+					scalingConfigurationOverride: &launchconfiguration,
+				},
+			},
+			want: "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := testFunction(t, tt.args)
+			if got != tt.want {
+				t.Errorf("%v: got %v, want %v", tt.name, got, tt.want)
+			}
+		})
+
+	}
+}
+
+func TestValidateTag(t *testing.T) {
+	tests := []struct {
+		name  string
+		key   string
+		value string
+		want  string
+	}{
+		{name: "valid tag", key: "cost-center", value: "1234"},
+		{name: "empty key", key: "", value: "1234", want: "tag key '' must be between 1 and 128 characters"},
+		{name: "reserved prefix", key: "AWS:cost-center", value: "1234", want: "tag key 'AWS:cost-center' cannot start with the reserved prefix 'aws:'"},
+		{name: "value exceeding limit", key: "cost-center", value: strings.Repeat("a", 257), want: "value of tag 'cost-center' cannot be longer than 256 characters"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got string
+			if err := ValidateTag(tt.key, tt.value); err != nil {
+				got = err.Error()
+			}
+			if got != tt.want {
+				t.Errorf("%v: got %v, want %v", tt.name, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScalingConfigOverride(t *testing.T) {
+	launchconfiguration := LaunchConfiguration
+	launchtemplate := LaunchTemplate
+	var invalidScalingConfigType ScalingConfigurationType = "invalid-scaling-config-type"
+
+	type args struct {
+		instancegroup *InstanceGroup
+		overrides     *ValidationOverrides
+	}
+	testFunction := func(t *testing.T, args args) string {
+		testCase := EksUnitTest{
+			InstanceGroup: args.instancegroup,
+			Overrides:     args.overrides,
+		}
+		return testCase.Run(t)
+	}
+	tests := []struct {
+		name          string
+		args          args
+		want          ScalingConfigurationType
+		expectedError bool
+	}{
+		{
+			name: "override default to launchconfig instead of launchtemplate",
+			args: args{
+				instancegroup: MockInstanceGroup("eks", "managed", MockEKSSpec(), nil, basicFargateSpec()),
+				overrides: &ValidationOverrides{
+					scalingConfigurationOverride: &launchconfiguration,
+				},
+			},
+			want:          LaunchConfiguration,
+			expectedError: false,
+		},
+		{
+			name: "no default overrides",
+			args: args{
+				instancegroup: MockInstanceGroup("eks", "managed", MockEKSSpec(), nil, basicFargateSpec()),
+				overrides:     &ValidationOverrides{},
+			},
+			want:          LaunchTemplate,
+			expectedError: false,
+		},
+		{
+			name: "override default to launchtemplate",
+			args: args{
+				instancegroup: MockInstanceGroup("eks", "managed", MockEKSSpec(), nil, basicFargateSpec()),
+				overrides: &ValidationOverrides{
+					scalingConfigurationOverride: &launchtemplate,
+				},
+			},
+			want:          LaunchTemplate,
+			expectedError: false,
+		},
+		{
+			name: "override default to an invalid scaling config type",
+			args: args{
+				instancegroup: MockInstanceGroup("eks", "managed", MockEKSSpec(), nil, basicFargateSpec()),
+				overrides: &ValidationOverrides{
+					scalingConfigurationOverride: &invalidScalingConfigType,
+				},
+			},
+			want:          LaunchTemplate,
+			expectedError: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := testFunction(t, tt.args)
+			if (!tt.expectedError && err != "") || (tt.expectedError && err == "") {
+				t.Errorf("%v: got: %v, expectedError: %v", tt.name, err, tt.expectedError)
+			}
+			got := tt.args.instancegroup.Spec.EKSSpec.Type
+			if got != tt.want {
 				t.Errorf("%v: got %v, want %v", tt.name, got, tt.want)
 			}
 
@@ -574,11 +2057,118 @@ func TestLockedAnnotation(t *testing.T) {
 	}
 }
 
+func TestMaintenanceWindowAnnotation(t *testing.T) {
+	// 2021-06-05 is a Saturday
+	saturday := time.Date(2021, 6, 5, 23, 30, 0, 0, time.UTC)
+	sunday := time.Date(2021, 6, 6, 1, 30, 0, 0, time.UTC)
+	monday := time.Date(2021, 6, 7, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		annotation string
+		time       time.Time
+		expected   bool
+		invalid    bool
+	}{
+		{name: "No window", annotation: "", time: monday, expected: true},
+		{name: "Inside daily window", annotation: "11:00-13:00", time: monday, expected: true},
+		{name: "Outside daily window", annotation: "13:00-15:00", time: monday, expected: false},
+		{name: "Inside window crossing midnight", annotation: "Sat 23:00-02:00", time: sunday, expected: true},
+		{name: "Outside window crossing midnight", annotation: "Sun 23:00-02:00", time: sunday, expected: false},
+		{name: "Inside day range", annotation: "Fri-Sun 23:00-23:59", time: saturday, expected: true},
+		{name: "Outside day list", annotation: "Sat,Sun 11:00-13:00", time: monday, expected: false},
+		{name: "Invalid day", annotation: "Funday 11:00-13:00", time: monday, expected: true, invalid: true},
+		{name: "Invalid time range", annotation: "11:00", time: monday, expected: true, invalid: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			testIg := MockInstanceGroup("eks", "rollingUpdate", MockEKSSpec(), nil, nil)
+			if test.annotation != "" {
+				testIg.SetAnnotations(map[string]string{
+					MaintenanceWindowAnnotationKey: test.annotation,
+				})
+			}
+			res := testIg.InMaintenanceWindow(test.time)
+			if res != test.expected {
+				t.Errorf("%v: got %v, expected %v", test.name, res, test.expected)
+			}
+			err := testIg.Validate(&ValidationOverrides{})
+			if test.invalid != (err != nil) {
+				t.Errorf("%v: got validation error %v, expected invalid %v", test.name, err, test.invalid)
+			}
+		})
+	}
+}
+
+func TestStateHistory(t *testing.T) {
+	testIg := MockInstanceGroup("eks", "rollingUpdate", MockEKSSpec(), nil, nil)
+	states := []ReconcileState{ReconcileInit, ReconcileModifying, ReconcileInitUpgrade}
+
+	total := MaxStateHistory + 5
+	for i := 0; i < total; i++ {
+		testIg.SetState(states[i%len(states)])
+	}
+
+	// setting the current state again is not a transition
+	testIg.SetState(testIg.GetState())
+
+	history := testIg.GetStatus().GetStateHistory()
+	if len(history) != MaxStateHistory {
+		t.Fatalf("got %v history entries, expected %v", len(history), MaxStateHistory)
+	}
+
+	for i, transition := range history {
+		expected := states[(total-MaxStateHistory+i)%len(states)]
+		if transition.State != expected {
+			t.Errorf("entry %v: got state %v, expected %v", i, transition.State, expected)
+		}
+	}
+
+	testIg.GetStatus().SetStateTransitionReason("some-reason")
+	if reason := history[MaxStateHistory-1].Reason; reason != "some-reason" {
+		t.Errorf("got reason %v, expected some-reason", reason)
+	}
+}
+
+func TestCompactStateHistory(t *testing.T) {
+	testIg := MockInstanceGroup("eks", "rollingUpdate", MockEKSSpec(), nil, nil)
+	testIg.SetState(ReconcileReady)
+	persisted := testIg.GetStatus().DeepCopy()
+
+	// a reconcile which ends in the persisted state does not modify the history
+	testIg.SetState(ReconcileInit)
+	testIg.SetState(ReconcileModifying)
+	testIg.SetState(ReconcileReady)
+	testIg.GetStatus().CompactStateHistory(persisted)
+	if history := testIg.GetStatus().GetStateHistory(); len(history) != 1 || history[0].State != ReconcileReady {
+		t.Fatalf("got history %v, expected only the persisted transition", history)
+	}
+
+	// a reconcile which ends in another state records a single transition with its reason
+	testIg.SetState(ReconcileInit)
+	testIg.SetState(ReconcileErr)
+	testIg.GetStatus().SetStateTransitionReason("some-reason")
+	testIg.GetStatus().CompactStateHistory(persisted)
+	history := testIg.GetStatus().GetStateHistory()
+	if len(history) != 2 {
+		t.Fatalf("got %v history entries, expected 2", len(history))
+	}
+	if history[1].State != ReconcileErr || history[1].Reason != "some-reason" {
+		t.Errorf("got transition %v, expected %v with reason some-reason", history[1], ReconcileErr)
+	}
+}
+
 func basicFargateSpec() *EKSFargateSpec {
 	return &EKSFargateSpec{
 		ClusterName:         "",
 		PodExecutionRoleArn: "",
 		Subnets:             []string{"subnet-1111111", "subnet-222222"},
+		Selectors: []EKSFargateSelectors{
+			{
+				Namespace: "default",
+			},
+		},
 		Tags: []map[string]string{
 			{
 				"key":   "a-key",
@@ -588,6 +2178,368 @@ func basicFargateSpec() *EKSFargateSpec {
 	}
 }
 
+func TestInstanceGroupDependencies(t *testing.T) {
+	ig := MockInstanceGroup("eks", "rollingUpdate", MockEKSSpec(), nil, nil)
+	ig.SetName("workload")
+	ig.SetNamespace("default")
+	ig.Spec.EKSSpec.Type = LaunchTemplate
+	ig.Spec.EKSSpec.DependsOn = []string{"system", "kube-system/system"}
+
+	want := []types.NamespacedName{
+		{Namespace: "default", Name: "system"},
+		{Namespace: "kube-system", Name: "system"},
+	}
+	if got := ig.GetDependencies(); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	if err := ig.Validate(&ValidationOverrides{}); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	ig.Spec.EKSSpec.DependsOn = []string{"workload"}
+	if err := ig.Validate(&ValidationOverrides{}); err == nil || err.Error() != "validation failed, instance group cannot depend on itself" {
+		t.Errorf("got %v, want self dependency error", err)
+	}
+}
+
+func TestInstanceRefreshStrategyValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		refresh *InstanceRefreshStrategy
+		want    string
+	}{
+		{
+			name:    "checkpoints with delay",
+			refresh: &InstanceRefreshStrategy{CheckpointPercentages: []int64{20, 50, 100}, CheckpointDelay: 600},
+		},
+		{
+			name:    "checkpoint percentage out of range",
+			refresh: &InstanceRefreshStrategy{CheckpointPercentages: []int64{50, 150}},
+			want:    "validation failed, strategy.instanceRefresh.checkpointPercentages must be between 1 and 100, got '150'",
+		},
+		{
+			name:    "checkpoint percentages not ascending",
+			refresh: &InstanceRefreshStrategy{CheckpointPercentages: []int64{50, 20}},
+			want:    "validation failed, strategy.instanceRefresh.checkpointPercentages must be unique and in ascending order",
+		},
+		{
+			name:    "checkpoint delay out of range",
+			refresh: &InstanceRefreshStrategy{CheckpointPercentages: []int64{50}, CheckpointDelay: 172801},
+			want:    "validation failed, strategy.instanceRefresh.checkpointDelay must be between 0 and 172800, got '172801'",
+		},
+		{
+			name:    "checkpoint delay without percentages",
+			refresh: &InstanceRefreshStrategy{CheckpointDelay: 600},
+			want:    "validation failed, strategy.instanceRefresh.checkpointDelay requires checkpointPercentages",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ig := MockInstanceGroup("eks", "rollingUpdate", MockEKSSpec(), nil, nil)
+			ig.Spec.EKSSpec.Type = LaunchTemplate
+			ig.Spec.AwsUpgradeStrategy.InstanceRefresh = tt.refresh
+			got := aws.StringValue(nil)
+			if err := ig.Validate(&ValidationOverrides{}); err != nil {
+				got = err.Error()
+			}
+			if got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSuspendProcessesValidate(t *testing.T) {
+	tests := []struct {
+		name      string
+		processes []string
+		want      []string
+	}{
+		{name: "specific processes", processes: []string{"Launch", "Terminate"}, want: []string{"Launch", "Terminate"}},
+		{name: "duplicate processes", processes: []string{"Launch", "Launch", "AZRebalance"}, want: []string{"Launch", "AZRebalance"}},
+		{name: "unknown processes", processes: []string{"Launch", "Unknown"}, want: []string{"Launch"}},
+		{name: "all with specific processes", processes: []string{"Launch", "All", "Terminate"}, want: []string{"all"}},
+		{name: "all", processes: []string{"all"}, want: []string{"all"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ig := MockInstanceGroup("eks", "rollingUpdate", MockEKSSpec(), nil, nil)
+			ig.Spec.EKSSpec.Type = LaunchTemplate
+			ig.Spec.EKSSpec.EKSConfiguration.SuspendedProcesses = tt.processes
+			if err := ig.Validate(&ValidationOverrides{}); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := ig.GetEKSConfiguration().GetSuspendProcesses(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCredentialProviderValidate(t *testing.T) {
+	tests := []struct {
+		name     string
+		provider *CredentialProviderSpec
+		want     string
+	}{
+		{
+			name:     "valid provider",
+			provider: &CredentialProviderSpec{Name: "ecr-credential-provider", BinDir: "/opt/bin", MatchImages: []string{"*.dkr.ecr.*.amazonaws.com"}, DefaultCacheDuration: "30m"},
+		},
+		{
+			name:     "provider name is a path",
+			provider: &CredentialProviderSpec{Name: "../ecr-credential-provider", MatchImages: []string{"*.dkr.ecr.*.amazonaws.com"}},
+			want:     "validation failed, 'bootstrapOptions.credentialProvider.name' must be the file name of the provider binary, got '../ecr-credential-provider'",
+		},
+		{
+			name:     "relative bin dir",
+			provider: &CredentialProviderSpec{Name: "ecr-credential-provider", BinDir: "opt/bin", MatchImages: []string{"*.dkr.ecr.*.amazonaws.com"}},
+			want:     "validation failed, 'bootstrapOptions.credentialProvider.binDir' must be an absolute path, got 'opt/bin'",
+		},
+		{
+			name:     "missing match images",
+			provider: &CredentialProviderSpec{Name: "ecr-credential-provider"},
+			want:     "validation failed, 'bootstrapOptions.credentialProvider.matchImages' must contain at least one image pattern",
+		},
+		{
+			name:     "invalid cache duration",
+			provider: &CredentialProviderSpec{Name: "ecr-credential-provider", MatchImages: []string{"*.dkr.ecr.*.amazonaws.com"}, DefaultCacheDuration: "12 hours"},
+			want:     "validation failed, 'bootstrapOptions.credentialProvider.defaultCacheDuration' value '12 hours' is not a valid duration",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			options := &BootstrapOptions{CredentialProvider: tt.provider}
+			got := aws.StringValue(nil)
+			if err := options.Validate(); err != nil {
+				got = err.Error()
+			}
+			if got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEKSManagedConfigurationValidate(t *testing.T) {
+	tests := []struct {
+		name           string
+		amiType        string
+		releaseVersion string
+		updateConfig   *EKSManagedUpdateConfig
+		taints         []corev1.Taint
+		want           string
+	}{
+		{
+			name:           "amazon linux release version",
+			amiType:        "AL2_x86_64",
+			releaseVersion: "1.27.3-20230816",
+		},
+		{
+			name:           "bottlerocket release version",
+			amiType:        "BOTTLEROCKET_ARM_64",
+			releaseVersion: "1.14.3-764e37e4",
+		},
+		{
+			name:           "release version without ami type",
+			releaseVersion: "1.14.3-764e37e4",
+		},
+		{
+			name:           "bottlerocket release version with amazon linux ami type",
+			amiType:        "AL2_x86_64",
+			releaseVersion: "1.14.3-764e37e4",
+			want:           "validation failed, 'releaseVersion' must be an amazon linux release version (e.g. 1.27.3-20230816) for amiType 'AL2_x86_64', got '1.14.3-764e37e4'",
+		},
+		{
+			name:           "amazon linux release version with bottlerocket ami type",
+			amiType:        "BOTTLEROCKET_x86_64",
+			releaseVersion: "1.27.3-20230816",
+			want:           "validation failed, 'releaseVersion' must be a bottlerocket release version (e.g. 1.14.3-764e37e4) for amiType 'BOTTLEROCKET_x86_64', got '1.27.3-20230816'",
+		},
+		{
+			name:           "release version with custom ami type",
+			amiType:        "CUSTOM",
+			releaseVersion: "1.27.3-20230816",
+			want:           "validation failed, 'releaseVersion' cannot be used with amiType 'CUSTOM'",
+		},
+		{
+			name:         "update config with max unavailable",
+			updateConfig: &EKSManagedUpdateConfig{MaxUnavailable: 2},
+		},
+		{
+			name:         "update config with max unavailable percentage",
+			updateConfig: &EKSManagedUpdateConfig{MaxUnavailablePercentage: 25},
+		},
+		{
+			name:         "update config with both max unavailable fields",
+			updateConfig: &EKSManagedUpdateConfig{MaxUnavailable: 2, MaxUnavailablePercentage: 25},
+			want:         "validation failed, 'maxUnavailable' and 'maxUnavailablePercentage' are mutually exclusive",
+		},
+		{
+			name:         "update config with max unavailable out of range",
+			updateConfig: &EKSManagedUpdateConfig{MaxUnavailable: 101},
+			want:         "validation failed, 'maxUnavailable' must be between 1 and 100, got 101",
+		},
+		{
+			name:         "update config with negative max unavailable percentage",
+			updateConfig: &EKSManagedUpdateConfig{MaxUnavailablePercentage: -5},
+			want:         "validation failed, 'maxUnavailablePercentage' must be between 1 and 100, got -5",
+		},
+		{
+			name:   "taint with valid effect",
+			taints: []corev1.Taint{{Key: "dedicated", Value: "infra", Effect: corev1.TaintEffectNoSchedule}},
+		},
+		{
+			name:   "taint with invalid effect",
+			taints: []corev1.Taint{{Key: "dedicated", Value: "infra", Effect: "NO_SCHEDULE"}},
+			want:   "validation failed, effect of taint 'dedicated' must be one of [NoSchedule PreferNoSchedule NoExecute]",
+		},
+		{
+			name:   "duplicate taint",
+			taints: []corev1.Taint{{Key: "dedicated", Value: "infra", Effect: corev1.TaintEffectNoSchedule}, {Key: "dedicated", Effect: corev1.TaintEffectNoSchedule}},
+			want:   "validation failed, taint 'dedicated' with effect 'NoSchedule' must be unique",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ig := MockInstanceGroup("eks-managed", "managed", nil, &EKSManagedSpec{
+				MaxSize: 1,
+				MinSize: 1,
+				EKSManagedConfiguration: &EKSManagedConfiguration{
+					EksClusterName: "my-eks-cluster",
+					AmiType:        tt.amiType,
+					ReleaseVersion: tt.releaseVersion,
+					UpdateConfig:   tt.updateConfig,
+					Taints:         tt.taints,
+				},
+			}, nil)
+			got := aws.StringValue(nil)
+			if err := ig.Validate(&ValidationOverrides{}); err != nil {
+				got = err.Error()
+			}
+			if got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDefaultVolumesOverride(t *testing.T) {
+	defaultVolumes := []NodeVolume{
+		{
+			Name:      "/dev/xvda",
+			Type:      "gp3",
+			Size:      50,
+			Encrypted: aws.Bool(true),
+		},
+	}
+	tests := []struct {
+		name        string
+		scalingType ScalingConfigurationType
+		volumes     []NodeVolume
+		overrides   *ValidationOverrides
+		want        []NodeVolume
+		wantErr     string
+	}{
+		{
+			name:      "hardcoded default without override",
+			overrides: NewValidationOverrides(nil, nil, ""),
+			want:      DefaultVolumes(),
+		},
+		{
+			name:      "configured default volume",
+			overrides: NewValidationOverrides(nil, defaultVolumes, ""),
+			want:      defaultVolumes,
+		},
+		{
+			name:      "instance group volumes win over configured default",
+			volumes:   []NodeVolume{{Name: "/dev/xvdb", Type: "gp2", Size: 20}},
+			overrides: NewValidationOverrides(nil, defaultVolumes, ""),
+			want:      []NodeVolume{{Name: "/dev/xvdb", Type: "gp2", Size: 20}},
+		},
+		{
+			name:        "configured default volume is validated",
+			scalingType: LaunchConfiguration,
+			overrides:   NewValidationOverrides(nil, defaultVolumes, ""),
+			wantErr:     "validation failed, volume type 'gp3' is unsupported",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			spec := MockEKSSpec()
+			spec.EKSConfiguration.Volumes = tt.volumes
+			if tt.scalingType != "" {
+				spec.Type = tt.scalingType
+			}
+			ig := MockInstanceGroup("eks", "rollingUpdate", spec, nil, nil)
+			err := ig.Validate(tt.overrides)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Errorf("got %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := ig.GetEKSConfiguration().Volumes; !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestInstanceProfileNamePrefixOverride(t *testing.T) {
+	tests := []struct {
+		name      string
+		prefix    string
+		overrides *ValidationOverrides
+		want      string
+		wantErr   string
+	}{
+		{
+			name:      "no prefix without override",
+			overrides: NewValidationOverrides(nil, nil, ""),
+		},
+		{
+			name:      "configured default prefix",
+			overrides: NewValidationOverrides(nil, nil, "corp-"),
+			want:      "corp-",
+		},
+		{
+			name:      "instance group prefix wins over configured default",
+			prefix:    "team-",
+			overrides: NewValidationOverrides(nil, nil, "corp-"),
+			want:      "team-",
+		},
+		{
+			name:      "configured default prefix is validated",
+			overrides: NewValidationOverrides(nil, nil, strings.Repeat("p", 128)),
+			wantErr:   "validation failed, 'instanceProfileNamePrefix' must be less than 128 characters",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			spec := MockEKSSpec()
+			spec.EKSConfiguration.InstanceProfileNamePrefix = tt.prefix
+			ig := MockInstanceGroup("eks", "rollingUpdate", spec, nil, nil)
+			err := ig.Validate(tt.overrides)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Errorf("got %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := ig.GetEKSConfiguration().GetInstanceProfileNamePrefix(); got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
 func MockInstanceGroup(provisioner, strategy string, eksSpec *EKSSpec, eksManagedSpec *EKSManagedSpec, eksFargateSpec *EKSFargateSpec) *InstanceGroup {
 	return &InstanceGroup{
 		Spec: InstanceGroupSpec{
@@ -616,3 +2568,14 @@ func MockEKSSpec() *EKSSpec {
 		},
 	}
 }
+
+func MockTags(count int) []map[string]string {
+	tags := make([]map[string]string, 0, count)
+	for i := 0; i < count; i++ {
+		tags = append(tags, map[string]string{
+			"key":   "tag-" + strconv.Itoa(i),
+			"value": "value",
+		})
+	}
+	return tags
+}
@@ -38,6 +38,11 @@ func (in *AwsUpgradeStrategy) DeepCopyInto(out *AwsUpgradeStrategy) {
 		*out = new(RollingUpdateStrategy)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.InstanceRefresh != nil {
+		in, out := &in.InstanceRefresh, &out.InstanceRefresh
+		*out = new(InstanceRefreshStrategy)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AwsUpgradeStrategy.
@@ -53,6 +58,32 @@ func (in *AwsUpgradeStrategy) DeepCopy() *AwsUpgradeStrategy {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *BootstrapOptions) DeepCopyInto(out *BootstrapOptions) {
 	*out = *in
+	if in.SystemReserved != nil {
+		in, out := &in.SystemReserved, &out.SystemReserved
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.KubeReserved != nil {
+		in, out := &in.KubeReserved, &out.KubeReserved
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.EvictionHard != nil {
+		in, out := &in.EvictionHard, &out.EvictionHard
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.CredentialProvider != nil {
+		in, out := &in.CredentialProvider, &out.CredentialProvider
+		*out = new(CredentialProviderSpec)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BootstrapOptions.
@@ -85,6 +116,31 @@ func (in *CRDUpdateStrategy) DeepCopy() *CRDUpdateStrategy {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CredentialProviderSpec) DeepCopyInto(out *CredentialProviderSpec) {
+	*out = *in
+	if in.MatchImages != nil {
+		in, out := &in.MatchImages, &out.MatchImages
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Args != nil {
+		in, out := &in.Args, &out.Args
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CredentialProviderSpec.
+func (in *CredentialProviderSpec) DeepCopy() *CredentialProviderSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(CredentialProviderSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *EKSConfiguration) DeepCopyInto(out *EKSConfiguration) {
 	*out = *in
@@ -113,7 +169,7 @@ func (in *EKSConfiguration) DeepCopyInto(out *EKSConfiguration) {
 	if in.BootstrapOptions != nil {
 		in, out := &in.BootstrapOptions, &out.BootstrapOptions
 		*out = new(BootstrapOptions)
-		**out = **in
+		(*in).DeepCopyInto(*out)
 	}
 	if in.Tags != nil {
 		in, out := &in.Tags, &out.Tags
@@ -160,7 +216,9 @@ func (in *EKSConfiguration) DeepCopyInto(out *EKSConfiguration) {
 	if in.LifecycleHooks != nil {
 		in, out := &in.LifecycleHooks, &out.LifecycleHooks
 		*out = make([]LifecycleHookSpec, len(*in))
-		copy(*out, *in)
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
 	}
 	if in.MixedInstancesPolicy != nil {
 		in, out := &in.MixedInstancesPolicy, &out.MixedInstancesPolicy
@@ -182,6 +240,36 @@ func (in *EKSConfiguration) DeepCopyInto(out *EKSConfiguration) {
 		*out = new(MetadataOptions)
 		**out = **in
 	}
+	if in.CapacityRebalance != nil {
+		in, out := &in.CapacityRebalance, &out.CapacityRebalance
+		*out = new(bool)
+		**out = **in
+	}
+	if in.TargetGroupARNs != nil {
+		in, out := &in.TargetGroupARNs, &out.TargetGroupARNs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.LoadBalancerNames != nil {
+		in, out := &in.LoadBalancerNames, &out.LoadBalancerNames
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.SourceDestCheck != nil {
+		in, out := &in.SourceDestCheck, &out.SourceDestCheck
+		*out = new(bool)
+		**out = **in
+	}
+	if in.NewInstancesProtectedFromScaleIn != nil {
+		in, out := &in.NewInstancesProtectedFromScaleIn, &out.NewInstancesProtectedFromScaleIn
+		*out = new(bool)
+		**out = **in
+	}
+	if in.MaintenancePolicy != nil {
+		in, out := &in.MaintenancePolicy, &out.MaintenancePolicy
+		*out = new(MaintenancePolicySpec)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EKSConfiguration.
@@ -244,6 +332,11 @@ func (in *EKSFargateSpec) DeepCopyInto(out *EKSFargateSpec) {
 			}
 		}
 	}
+	if in.ManagedPolicies != nil {
+		in, out := &in.ManagedPolicies, &out.ManagedPolicies
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EKSFargateSpec.
@@ -289,6 +382,18 @@ func (in *EKSManagedConfiguration) DeepCopyInto(out *EKSManagedConfiguration) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.Taints != nil {
+		in, out := &in.Taints, &out.Taints
+		*out = make([]v1.Taint, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.UpdateConfig != nil {
+		in, out := &in.UpdateConfig, &out.UpdateConfig
+		*out = new(EKSManagedUpdateConfig)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EKSManagedConfiguration.
@@ -321,6 +426,21 @@ func (in *EKSManagedSpec) DeepCopy() *EKSManagedSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EKSManagedUpdateConfig) DeepCopyInto(out *EKSManagedUpdateConfig) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EKSManagedUpdateConfig.
+func (in *EKSManagedUpdateConfig) DeepCopy() *EKSManagedUpdateConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(EKSManagedUpdateConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *EKSSpec) DeepCopyInto(out *EKSSpec) {
 	*out = *in
@@ -334,6 +454,11 @@ func (in *EKSSpec) DeepCopyInto(out *EKSSpec) {
 		*out = new(EKSConfiguration)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.DependsOn != nil {
+		in, out := &in.DependsOn, &out.DependsOn
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EKSSpec.
@@ -454,11 +579,51 @@ func (in *InstanceGroupSpec) DeepCopy() *InstanceGroupSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *InstanceGroupStatus) DeepCopyInto(out *InstanceGroupStatus) {
 	*out = *in
+	if in.LastSpotInterruptionTime != nil {
+		in, out := &in.LastSpotInterruptionTime, &out.LastSpotInterruptionTime
+		*out = (*in).DeepCopy()
+	}
+	if in.NodesNotReadySince != nil {
+		in, out := &in.NodesNotReadySince, &out.NodesNotReadySince
+		*out = (*in).DeepCopy()
+	}
 	if in.Conditions != nil {
 		in, out := &in.Conditions, &out.Conditions
 		*out = make([]InstanceGroupCondition, len(*in))
 		copy(*out, *in)
 	}
+	if in.StateHistory != nil {
+		in, out := &in.StateHistory, &out.StateHistory
+		*out = make([]StateTransition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.AttachedTargetGroupARNs != nil {
+		in, out := &in.AttachedTargetGroupARNs, &out.AttachedTargetGroupARNs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.AttachedLoadBalancerNames != nil {
+		in, out := &in.AttachedLoadBalancerNames, &out.AttachedLoadBalancerNames
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.RetiredInstanceProfileNames != nil {
+		in, out := &in.RetiredInstanceProfileNames, &out.RetiredInstanceProfileNames
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.RetiredLaunchTemplateNames != nil {
+		in, out := &in.RetiredLaunchTemplateNames, &out.RetiredLaunchTemplateNames
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.LastSpotInterruptionUIDs != nil {
+		in, out := &in.LastSpotInterruptionUIDs, &out.LastSpotInterruptionUIDs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InstanceGroupStatus.
@@ -471,6 +636,26 @@ func (in *InstanceGroupStatus) DeepCopy() *InstanceGroupStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *InstanceRefreshStrategy) DeepCopyInto(out *InstanceRefreshStrategy) {
+	*out = *in
+	if in.CheckpointPercentages != nil {
+		in, out := &in.CheckpointPercentages, &out.CheckpointPercentages
+		*out = make([]int64, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InstanceRefreshStrategy.
+func (in *InstanceRefreshStrategy) DeepCopy() *InstanceRefreshStrategy {
+	if in == nil {
+		return nil
+	}
+	out := new(InstanceRefreshStrategy)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *InstanceTypeSpec) DeepCopyInto(out *InstanceTypeSpec) {
 	*out = *in
@@ -486,9 +671,44 @@ func (in *InstanceTypeSpec) DeepCopy() *InstanceTypeSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LifecycleHookDocument) DeepCopyInto(out *LifecycleHookDocument) {
+	*out = *in
+	if in.Parameters != nil {
+		in, out := &in.Parameters, &out.Parameters
+		*out = make(map[string][]string, len(*in))
+		for key, val := range *in {
+			var outVal []string
+			if val == nil {
+				(*out)[key] = nil
+			} else {
+				in, out := &val, &outVal
+				*out = make([]string, len(*in))
+				copy(*out, *in)
+			}
+			(*out)[key] = outVal
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LifecycleHookDocument.
+func (in *LifecycleHookDocument) DeepCopy() *LifecycleHookDocument {
+	if in == nil {
+		return nil
+	}
+	out := new(LifecycleHookDocument)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *LifecycleHookSpec) DeepCopyInto(out *LifecycleHookSpec) {
 	*out = *in
+	if in.SSMDocument != nil {
+		in, out := &in.SSMDocument, &out.SSMDocument
+		*out = new(LifecycleHookDocument)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LifecycleHookSpec.
@@ -501,6 +721,31 @@ func (in *LifecycleHookSpec) DeepCopy() *LifecycleHookSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MaintenancePolicySpec) DeepCopyInto(out *MaintenancePolicySpec) {
+	*out = *in
+	if in.MinHealthyPercentage != nil {
+		in, out := &in.MinHealthyPercentage, &out.MinHealthyPercentage
+		*out = new(int64)
+		**out = **in
+	}
+	if in.MaxHealthyPercentage != nil {
+		in, out := &in.MaxHealthyPercentage, &out.MaxHealthyPercentage
+		*out = new(int64)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MaintenancePolicySpec.
+func (in *MaintenancePolicySpec) DeepCopy() *MaintenancePolicySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(MaintenancePolicySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *MetadataOptions) DeepCopyInto(out *MetadataOptions) {
 	*out = *in
@@ -534,6 +779,11 @@ func (in *MixedInstancesPolicySpec) DeepCopyInto(out *MixedInstancesPolicySpec)
 		*out = new(int64)
 		**out = **in
 	}
+	if in.BaseCapacityPercentage != nil {
+		in, out := &in.BaseCapacityPercentage, &out.BaseCapacityPercentage
+		*out = new(int)
+		**out = **in
+	}
 	if in.SpotRatio != nil {
 		in, out := &in.SpotRatio, &out.SpotRatio
 		*out = new(intstr.IntOrString)
@@ -544,6 +794,17 @@ func (in *MixedInstancesPolicySpec) DeepCopyInto(out *MixedInstancesPolicySpec)
 		*out = new(string)
 		**out = **in
 	}
+	if in.InstancePoolFamilies != nil {
+		in, out := &in.InstancePoolFamilies, &out.InstancePoolFamilies
+		*out = make([][]string, len(*in))
+		for i := range *in {
+			if (*in)[i] != nil {
+				in, out := &(*in)[i], &(*out)[i]
+				*out = make([]string, len(*in))
+				copy(*out, *in)
+			}
+		}
+	}
 	if in.InstanceTypes != nil {
 		in, out := &in.InstanceTypes, &out.InstanceTypes
 		*out = make([]*InstanceTypeSpec, len(*in))
@@ -555,6 +816,11 @@ func (in *MixedInstancesPolicySpec) DeepCopyInto(out *MixedInstancesPolicySpec)
 			}
 		}
 	}
+	if in.WeightedCapacityUnit != nil {
+		in, out := &in.WeightedCapacityUnit, &out.WeightedCapacityUnit
+		*out = new(string)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MixedInstancesPolicySpec.
@@ -640,6 +906,11 @@ func (in *RollingUpdateStrategy) DeepCopyInto(out *RollingUpdateStrategy) {
 		*out = new(intstr.IntOrString)
 		**out = **in
 	}
+	if in.BatchSize != nil {
+		in, out := &in.BatchSize, &out.BatchSize
+		*out = new(intstr.IntOrString)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RollingUpdateStrategy.
@@ -652,6 +923,22 @@ func (in *RollingUpdateStrategy) DeepCopy() *RollingUpdateStrategy {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StateTransition) DeepCopyInto(out *StateTransition) {
+	*out = *in
+	in.Timestamp.DeepCopyInto(&out.Timestamp)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StateTransition.
+func (in *StateTransition) DeepCopy() *StateTransition {
+	if in == nil {
+		return nil
+	}
+	out := new(StateTransition)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *UserDataStage) DeepCopyInto(out *UserDataStage) {
 	*out = *in
@@ -675,6 +962,13 @@ func (in *ValidationOverrides) DeepCopyInto(out *ValidationOverrides) {
 		*out = new(ScalingConfigurationType)
 		**out = **in
 	}
+	if in.defaultVolumesOverride != nil {
+		in, out := &in.defaultVolumesOverride, &out.defaultVolumesOverride
+		*out = make([]NodeVolume, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ValidationOverrides.
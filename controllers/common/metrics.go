@@ -1,6 +1,8 @@
 package common
 
 import (
+	"sync"
+
 	"github.com/prometheus/client_golang/prometheus"
 )
 
@@ -16,8 +18,20 @@ type MetricsCollector struct {
 	throttleCounter  *prometheus.CounterVec
 	statusGauge      *prometheus.GaugeVec
 	lastUpgradeGauge *prometheus.GaugeVec
+
+	spotInterruptionCounter *prometheus.CounterVec
+
+	groupDesiredGauge    *prometheus.GaugeVec
+	groupMinGauge        *prometheus.GaugeVec
+	groupMaxGauge        *prometheus.GaugeVec
+	groupReadyNodesGauge *prometheus.GaugeVec
+
+	// groupProvisioners maps instance groups to the provisioner label of their scaling gauges
+	groupProvisioners *sync.Map
 }
 
+var groupScalingLabels = []string{"namespace", "name", "provisioner"}
+
 func NewMetricsCollector() *MetricsCollector {
 	return &MetricsCollector{
 		successCounter: prometheus.NewCounterVec(
@@ -52,6 +66,47 @@ func NewMetricsCollector() *MetricsCollector {
 			},
 			[]string{"instancegroup", "status"},
 		),
+		spotInterruptionCounter: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: namespace,
+				Name:      "spot_interruptions_total",
+				Help:      "number of spot interruption warnings received by instance group",
+			},
+			[]string{"instancegroup"},
+		),
+		groupDesiredGauge: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Name:      "group_desired",
+				Help:      "desired capacity of the scaling group of an instance group",
+			},
+			groupScalingLabels,
+		),
+		groupMinGauge: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Name:      "group_min",
+				Help:      "minimum size of the scaling group of an instance group",
+			},
+			groupScalingLabels,
+		),
+		groupMaxGauge: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Name:      "group_max",
+				Help:      "maximum size of the scaling group of an instance group",
+			},
+			groupScalingLabels,
+		),
+		groupReadyNodesGauge: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Name:      "group_ready_nodes",
+				Help:      "number of ready nodes of an instance group",
+			},
+			groupScalingLabels,
+		),
+		groupProvisioners: &sync.Map{},
 	}
 }
 
@@ -60,6 +115,11 @@ func (c MetricsCollector) Collect(ch chan<- prometheus.Metric) {
 	c.failureCounter.Collect(ch)
 	c.throttleCounter.Collect(ch)
 	c.statusGauge.Collect(ch)
+	c.spotInterruptionCounter.Collect(ch)
+	c.groupDesiredGauge.Collect(ch)
+	c.groupMinGauge.Collect(ch)
+	c.groupMaxGauge.Collect(ch)
+	c.groupReadyNodesGauge.Collect(ch)
 }
 
 func (c MetricsCollector) Describe(ch chan<- *prometheus.Desc) {
@@ -67,6 +127,11 @@ func (c MetricsCollector) Describe(ch chan<- *prometheus.Desc) {
 	c.failureCounter.Describe(ch)
 	c.throttleCounter.Describe(ch)
 	c.statusGauge.Describe(ch)
+	c.spotInterruptionCounter.Describe(ch)
+	c.groupDesiredGauge.Describe(ch)
+	c.groupMinGauge.Describe(ch)
+	c.groupMaxGauge.Describe(ch)
+	c.groupReadyNodesGauge.Describe(ch)
 }
 
 func (c *MetricsCollector) SetInstanceGroup(instanceGroup, state string) {
@@ -85,6 +150,7 @@ func (c *MetricsCollector) UnsetInstanceGroup() {
 	c.failureCounter.Reset()
 	c.throttleCounter.Reset()
 	c.statusGauge.Reset()
+	c.spotInterruptionCounter.Reset()
 }
 
 func (c *MetricsCollector) IncSuccess(instanceGroup string) {
@@ -98,3 +164,31 @@ func (c *MetricsCollector) IncFail(instanceGroup, reason string) {
 func (c *MetricsCollector) IncThrottle(serviceName, operationName string) {
 	c.throttleCounter.With(prometheus.Labels{"service": serviceName, "operation": operationName}).Inc()
 }
+
+func (c *MetricsCollector) AddSpotInterruptions(instanceGroup string, count int) {
+	c.spotInterruptionCounter.With(prometheus.Labels{"instancegroup": instanceGroup}).Add(float64(count))
+}
+
+// SetInstanceGroupScaling sets the scaling gauges of an instance group, series of a previous provisioner are removed
+func (c *MetricsCollector) SetInstanceGroupScaling(namespace, name, provisioner string, desired, min, max, readyNodes int) {
+	previous, ok := c.groupProvisioners.Load(namespace + "/" + name)
+	if ok && previous.(string) != provisioner {
+		c.UnsetInstanceGroupScaling(namespace, name)
+	}
+	c.groupProvisioners.Store(namespace+"/"+name, provisioner)
+	labels := prometheus.Labels{"namespace": namespace, "name": name, "provisioner": provisioner}
+	c.groupDesiredGauge.With(labels).Set(float64(desired))
+	c.groupMinGauge.With(labels).Set(float64(min))
+	c.groupMaxGauge.With(labels).Set(float64(max))
+	c.groupReadyNodesGauge.With(labels).Set(float64(readyNodes))
+}
+
+// UnsetInstanceGroupScaling removes the scaling gauges of a deleted instance group
+func (c *MetricsCollector) UnsetInstanceGroupScaling(namespace, name string) {
+	c.groupProvisioners.Delete(namespace + "/" + name)
+	labels := prometheus.Labels{"namespace": namespace, "name": name}
+	c.groupDesiredGauge.DeletePartialMatch(labels)
+	c.groupMinGauge.DeletePartialMatch(labels)
+	c.groupMaxGauge.DeletePartialMatch(labels)
+	c.groupReadyNodesGauge.DeletePartialMatch(labels)
+}
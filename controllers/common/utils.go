@@ -151,7 +151,13 @@ func AppendUniqueIndex(slice []interface{}, i interface{}, idx string, override
 			}
 		}
 
+		// elements without an index value cannot be matched, e.g. unnamed userData stages
+		if StringEmpty(fieldStr) {
+			break
+		}
+
 		for ix, ele := range slice {
+			fieldStr2 = ""
 			compareIdxVal := reflect.ValueOf(ele)
 			for _, e := range compareIdxVal.MapKeys() {
 				if strings.EqualFold(e.String(), idx) {
@@ -17,6 +17,7 @@ package controllers
 
 import (
 	"context"
+	"fmt"
 	"reflect"
 	"strings"
 	"sync"
@@ -32,10 +33,14 @@ import (
 	"github.com/keikoproj/instance-manager/controllers/provisioners/eksfargate"
 	"github.com/keikoproj/instance-manager/controllers/provisioners/eksmanaged"
 	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
 	corev1 "k8s.io/api/core/v1"
 	kerrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
@@ -44,6 +49,7 @@ import (
 type InstanceGroupReconciler struct {
 	client.Client
 	SpotRecommendationTime      float64
+	SpotRecommendationSource    kubeprovider.SpotRecommendationSource
 	ConfigNamespace             string
 	NodeRelabel                 bool
 	Log                         logr.Logger
@@ -56,6 +62,20 @@ type InstanceGroupReconciler struct {
 	Metrics                     *common.MetricsCollector
 	DisableWinClusterInjection  bool
 	DefaultScalingConfiguration *v1alpha1.ScalingConfigurationType
+	DefaultProfileNamePrefix    string
+	EnableUserDataDump          bool
+	ReconcileShortcutInterval   time.Duration
+	ReconcileShortcutCount      int
+	Tracer                      trace.Tracer
+	ThrottleBackoff             time.Duration
+	NodeReadinessTimeout        time.Duration
+	LegacyRoleLabelCutoff       string
+	ManagedPollInterval         time.Duration
+	ManagedWaitTimeout          time.Duration
+	throttleAttempts            map[string]int
+	throttleLock                sync.Mutex
+	reconcileRecords            map[string]provisioners.ReconcileRecord
+	reconcileLock               sync.Mutex
 }
 
 type InstanceGroupAuthenticator struct {
@@ -71,6 +91,14 @@ const (
 	ErrorReasonDefaultsApplyFailed     = "ApplyDefaults"
 	ErrorReasonValidationFailed        = "ResourceValidation"
 	ErrorReasonReconcileFailed         = "HandleReconcile"
+	ErrorReasonDependencyCycle         = "DependencyCycle"
+
+	// DefaultThrottleBackoff is the base delay before an instance group throttled by AWS is reconciled again
+	DefaultThrottleBackoff = 30 * time.Second
+	// MaxThrottleBackoff limits the exponential backoff of instance groups which are repeatedly throttled
+	MaxThrottleBackoff = 10 * time.Minute
+	// DefaultRequeueInterval is the delay before an instance group in a retryable state is reconciled again
+	DefaultRequeueInterval = 10 * time.Second
 )
 
 func (r *InstanceGroupReconciler) Finalize(instanceGroup *v1alpha1.InstanceGroup) {
@@ -108,7 +136,9 @@ func (r *InstanceGroupReconciler) SetFinalizer(instanceGroup *v1alpha1.InstanceG
 }
 
 // +kubebuilder:rbac:groups=core,resources=namespaces,verbs=list;get;watch
-// +kubebuilder:rbac:groups=core,resources=nodes,verbs=list;patch;watch
+// +kubebuilder:rbac:groups=core,resources=nodes,verbs=get;list;patch;watch
+// +kubebuilder:rbac:groups=core,resources=pods,verbs=list
+// +kubebuilder:rbac:groups=core,resources=pods/eviction,verbs=create
 // +kubebuilder:rbac:groups=core,resources=events,verbs=get;list;watch;create
 // +kubebuilder:rbac:groups=core,resources=configmaps,verbs=get;list;create;update;patch;watch
 // +kubebuilder:rbac:groups=apiextensions.k8s.io,resources=customresourcedefinitions,verbs=get;list;watch;create;update;patch;delete
@@ -124,6 +154,8 @@ func (r *InstanceGroupReconciler) Reconcile(ctxt context.Context, req ctrl.Reque
 		if kerrors.IsNotFound(err) {
 			r.Log.Info("instancegroup not found", "instancegroup", req.NamespacedName)
 			r.Metrics.UnsetInstanceGroup()
+			r.Metrics.UnsetInstanceGroupScaling(req.Namespace, req.Name)
+			r.DeleteReconcileRecord(req.NamespacedName.String())
 			return ctrl.Result{}, nil
 		}
 		r.Log.Error(err, "reconcile failed", "instancegroup", req.NamespacedName)
@@ -135,20 +167,46 @@ func (r *InstanceGroupReconciler) Reconcile(ctxt context.Context, req ctrl.Reque
 	// set/unset finalizer
 	r.SetFinalizer(instanceGroup)
 
+	// paused instance groups are not reconciled until the annotation is removed, deletion is never paused
+	if instanceGroup.Paused() && instanceGroup.GetDeletionTimestamp().IsZero() {
+		r.Log.Info("reconcile event skipped, instancegroup is paused", "instancegroup", req.NamespacedName)
+		instanceGroup.GetStatus().SetCondition(v1alpha1.NewInstanceGroupCondition(v1alpha1.Paused, corev1.ConditionTrue))
+		r.PatchStatus(instanceGroup, statusPatch)
+		return ctrl.Result{}, nil
+	}
+	instanceGroup.GetStatus().RemoveCondition(v1alpha1.Paused)
+
 	input := provisioners.ProvisionerInput{
 		AwsWorker:                  r.Auth.Aws,
 		Kubernetes:                 r.Auth.Kubernetes,
 		Configuration:              r.ConfigMap,
 		InstanceGroup:              instanceGroup,
-		Log:                        r.Log,
+		Log:                        provisioners.GetInstanceGroupLogger(r.Log, instanceGroup),
 		ConfigRetention:            r.ConfigRetention,
 		Metrics:                    r.Metrics,
 		DisableWinClusterInjection: r.DisableWinClusterInjection,
+		NodeRelabel:                r.NodeRelabel,
+		SpotRecommendationSource:   r.SpotRecommendationSource,
+		ReconcileShortcutInterval:  r.ReconcileShortcutInterval,
+		ReconcileShortcutCount:     r.ReconcileShortcutCount,
+		LastReconcile:              r.GetReconcileRecord(instanceGroup.NamespacedName()),
+		NodeReadinessTimeout:       r.NodeReadinessTimeout,
+		EnableUserDataDump:         r.EnableUserDataDump,
+		LegacyRoleLabelCutoff:      r.LegacyRoleLabelCutoff,
+		ManagedPollInterval:        r.ManagedPollInterval,
+		ManagedWaitTimeout:         r.ManagedWaitTimeout,
+	}
+
+	// cached responses may be stale, e.g. during an upgrade, this reconcile describes fresh state without affecting others
+	if instanceGroup.IsCacheBypassed() {
+		r.Log.Info("reconcile event bypasses the aws api cache", "instancegroup", req.NamespacedName)
+		input.AwsWorker = r.Auth.Aws.WithoutCache()
 	}
 
 	var (
-		status     = instanceGroup.GetStatus()
-		configHash = kubeprovider.ConfigmapHash(r.ConfigMap)
+		status         = instanceGroup.GetStatus()
+		configHash     = kubeprovider.ConfigmapHash(r.ConfigMap)
+		defaultVolumes []v1alpha1.NodeVolume
 	)
 	status.SetConfigHash(configHash)
 
@@ -169,6 +227,12 @@ func (r *InstanceGroupReconciler) Reconcile(ctxt context.Context, req ctrl.Reque
 				return ctrl.Result{}, err
 			}
 
+			if defaultVolumes, err = defaultConfig.GetDefaultVolumes(); err != nil {
+				r.Log.Error(err, "failed to get default volumes", "instancegroup", instanceGroup.NamespacedName())
+				r.Metrics.IncFail(instanceGroup.NamespacedName(), ErrorReasonDefaultsApplyFailed)
+				return ctrl.Result{}, err
+			}
+
 			input.InstanceGroup = defaultConfig.InstanceGroup
 		} else {
 			// unset config hash if namespace is excluded
@@ -185,6 +249,7 @@ func (r *InstanceGroupReconciler) Reconcile(ctxt context.Context, req ctrl.Reque
 	}
 
 	r.Log.Info("reconcile event started", "instancegroup", req.NamespacedName, "provisioner", provisionerKind)
+	input.Log.V(1).Info("reconciling instance group", "instancegroup", req.NamespacedName, "spec", RedactSpec(input.InstanceGroup.Spec), "status", input.InstanceGroup.Status)
 	var ctx CloudDeployer
 	switch {
 	case strings.EqualFold(provisionerKind, eks.ProvisionerName):
@@ -195,28 +260,102 @@ func (r *InstanceGroupReconciler) Reconcile(ctxt context.Context, req ctrl.Reque
 		ctx = eksfargate.New(input)
 	}
 
-	// for igs without any config type mentioned, allow overriding the default.
-	overrides := v1alpha1.NewValidationOverrides(r.DefaultScalingConfiguration)
+	// for igs without any config type, volumes or instance-profile name prefix mentioned, allow overriding the defaults.
+	overrides := v1alpha1.NewValidationOverrides(r.DefaultScalingConfiguration, defaultVolumes, r.DefaultProfileNamePrefix)
 
 	if err = input.InstanceGroup.Validate(overrides); err != nil {
 		ctx.SetState(v1alpha1.ReconcileErr)
+		input.InstanceGroup.GetStatus().SetStateTransitionReason(ErrorReasonValidationFailed)
 		r.PatchStatus(input.InstanceGroup, statusPatch)
 		r.Metrics.IncFail(instanceGroup.NamespacedName(), ErrorReasonValidationFailed)
 		return ctrl.Result{}, errors.Wrapf(err, "provisioner %v reconcile failed", provisionerKind)
 	}
 
-	if err = HandleReconcileRequest(ctx); err != nil {
+	// deletion never waits for dependencies
+	if input.InstanceGroup.GetDeletionTimestamp().IsZero() {
+		if err = r.ValidateDependencies(input.InstanceGroup); err != nil {
+			ctx.SetState(v1alpha1.ReconcileErr)
+			input.InstanceGroup.GetStatus().SetStateTransitionReason(ErrorReasonValidationFailed)
+			r.PatchStatus(input.InstanceGroup, statusPatch)
+			r.Metrics.IncFail(instanceGroup.NamespacedName(), ErrorReasonValidationFailed)
+			return ctrl.Result{}, errors.Wrapf(err, "provisioner %v reconcile failed", provisionerKind)
+		}
+
+		ready, err := r.DependenciesReady(input.InstanceGroup)
+		if err != nil {
+			ctx.SetState(v1alpha1.ReconcileErr)
+			input.InstanceGroup.GetStatus().SetStateTransitionReason(ErrorReasonDependencyCycle)
+			r.PatchStatus(input.InstanceGroup, statusPatch)
+			r.Metrics.IncFail(instanceGroup.NamespacedName(), ErrorReasonDependencyCycle)
+			return ctrl.Result{}, errors.Wrapf(err, "provisioner %v reconcile failed", provisionerKind)
+		}
+		if !ready {
+			r.Log.Info("reconcile event requeued, waiting for dependencies", "instancegroup", req.NamespacedName, "dependencies", input.InstanceGroup.GetDependencies())
+			r.PatchStatus(input.InstanceGroup, statusPatch)
+			return ctrl.Result{RequeueAfter: GetRequeueInterval(ctx)}, nil
+		}
+	}
+
+	// the desired state is hashed before cloud discovery resolves values such as the latest image
+	reconcileHash := provisioners.GetReconcileHash(input.InstanceGroup)
+	if shortcutter, ok := ctx.(ReconcileShortcutter); ok && !input.InstanceGroup.IsCacheBypassed() && shortcutter.ReconcileShortcut() {
+		r.Log.Info("reconcile event ended with shortcut", "instancegroup", req.NamespacedName, "provisioner", provisionerKind)
+		r.IncrementShortcutReconciles(instanceGroup.NamespacedName())
+		r.SetScalingMetrics(input.InstanceGroup, ctx)
+		r.PatchStatus(input.InstanceGroup, statusPatch)
+		r.Metrics.IncSuccess(instanceGroup.NamespacedName())
+		return ctrl.Result{}, nil
+	}
+
+	attributes := []attribute.KeyValue{
+		attribute.String("instancegroup", req.NamespacedName.String()),
+		attribute.String("provisioner", provisionerKind),
+	}
+	spanCtx, span := r.GetTracer().Start(ctxt, "Reconcile", trace.WithAttributes(attributes...))
+	err = HandleReconcileRequest(spanCtx, r.GetTracer(), ctx, attributes...)
+	span.End()
+	r.SetPermissionsCondition(input.InstanceGroup, err)
+	r.SetScalingMetrics(input.InstanceGroup, ctx)
+	if err != nil {
 		ctx.SetState(v1alpha1.ReconcileErr)
+		input.InstanceGroup.GetStatus().SetStateTransitionReason(ErrorReasonReconcileFailed)
 		r.PatchStatus(input.InstanceGroup, statusPatch)
 		r.Metrics.IncFail(instanceGroup.NamespacedName(), ErrorReasonReconcileFailed)
+		if requeueAfter := r.GetErrorRequeue(instanceGroup.NamespacedName(), err); requeueAfter > 0 {
+			// the rate limiter would retry within milliseconds and add to the throttled request rate
+			r.Log.Error(err, "reconcile throttled by AWS", "instancegroup", req.NamespacedName, "provisioner", provisionerKind, "requeueAfter", requeueAfter)
+			return ctrl.Result{RequeueAfter: requeueAfter}, nil
+		}
 		return ctrl.Result{}, errors.Wrapf(err, "provisioner %v reconcile failed", provisionerKind)
 	}
+	r.GetErrorRequeue(instanceGroup.NamespacedName(), nil)
+
+	if deferrer, ok := ctx.(ReconcileDeferrer); ok && deferrer.IsDeferred() {
+		// rate limited requeue, backs off exponentially while the cluster remains unavailable
+		r.Log.Info("reconcile event deferred", "instancegroup", req.NamespacedName, "provisioner", provisionerKind)
+		r.PatchStatus(input.InstanceGroup, statusPatch)
+		r.Metrics.IncSuccess(instanceGroup.NamespacedName())
+		return ctrl.Result{Requeue: true}, nil
+	}
 
 	if provisioners.IsRetryable(input.InstanceGroup) {
-		r.Log.Info("reconcile event ended with requeue", "instancegroup", req.NamespacedName, "provisioner", provisionerKind)
+		requeueAfter := GetRequeueInterval(ctx)
+		r.Log.Info("reconcile event ended with requeue", "instancegroup", req.NamespacedName, "provisioner", provisionerKind, "requeueAfter", requeueAfter)
+		r.PatchStatus(input.InstanceGroup, statusPatch)
+		r.Metrics.IncSuccess(instanceGroup.NamespacedName())
+		return ctrl.Result{RequeueAfter: requeueAfter}, nil
+	}
+
+	if waiter, ok := ctx.(ReconcileWaiter); ok && waiter.IsWaiting() {
+		requeueAfter := GetRequeueInterval(ctx)
+		r.Log.Info("reconcile event ended with requeue, waiting for ongoing operations", "instancegroup", req.NamespacedName, "provisioner", provisionerKind, "requeueAfter", requeueAfter)
 		r.PatchStatus(input.InstanceGroup, statusPatch)
 		r.Metrics.IncSuccess(instanceGroup.NamespacedName())
-		return ctrl.Result{RequeueAfter: 10 * time.Second}, nil
+		return ctrl.Result{RequeueAfter: requeueAfter}, nil
+	}
+
+	if r.ReconcileShortcutInterval > 0 && input.InstanceGroup.GetState() == v1alpha1.ReconcileReady {
+		r.SetReconciled(instanceGroup.NamespacedName(), reconcileHash, GetDesiredCapacity(ctx), time.Now())
 	}
 
 	r.Log.Info("reconcile event ended", "instancegroup", req.NamespacedName, "provisioner", provisionerKind)
@@ -226,7 +365,253 @@ func (r *InstanceGroupReconciler) Reconcile(ctxt context.Context, req ctrl.Reque
 	return ctrl.Result{}, nil
 }
 
+// RedactSpec returns a copy of an instance group spec whose userData stages are redacted, so that it can be logged
+func RedactSpec(spec v1alpha1.InstanceGroupSpec) *v1alpha1.InstanceGroupSpec {
+	redacted := spec.DeepCopy()
+	if redacted.EKSSpec == nil || redacted.EKSSpec.EKSConfiguration == nil {
+		return redacted
+	}
+	for i, stage := range redacted.EKSSpec.EKSConfiguration.UserData {
+		redacted.EKSSpec.EKSConfiguration.UserData[i].Data = eks.RedactUserData(stage.Data)
+	}
+	return redacted
+}
+
+// GetRequeueInterval returns the delay before an instance group in a retryable state is reconciled again
+func GetRequeueInterval(ctx CloudDeployer) time.Duration {
+	if requeuer, ok := ctx.(ReconcileRequeuer); ok {
+		if interval := requeuer.GetRequeueInterval(); interval > 0 {
+			return interval
+		}
+	}
+	return DefaultRequeueInterval
+}
+
+// GetDesiredCapacity returns the desired capacity discovered by a reconcile, or zero if it was not discovered
+func GetDesiredCapacity(ctx CloudDeployer) int {
+	if reporter, ok := ctx.(ScalingMetricsReporter); ok {
+		if desired, _, ok := reporter.GetScalingMetrics(); ok {
+			return desired
+		}
+	}
+	return 0
+}
+
+// SetScalingMetrics updates the scaling gauges of an instance group after a reconcile, the gauges are removed once the
+// instance group is deleted
+func (r *InstanceGroupReconciler) SetScalingMetrics(instanceGroup *v1alpha1.InstanceGroup, ctx CloudDeployer) {
+	var (
+		namespace   = instanceGroup.GetNamespace()
+		name        = instanceGroup.GetName()
+		provisioner = strings.ToLower(instanceGroup.Spec.Provisioner)
+		status      = instanceGroup.GetStatus()
+	)
+
+	if ctx.GetState() == v1alpha1.ReconcileDeleted {
+		r.Metrics.UnsetInstanceGroupScaling(namespace, name)
+		return
+	}
+
+	reporter, ok := ctx.(ScalingMetricsReporter)
+	if !ok {
+		return
+	}
+
+	desired, readyNodes, ok := reporter.GetScalingMetrics()
+	if !ok {
+		return
+	}
+	r.Metrics.SetInstanceGroupScaling(namespace, name, provisioner, desired, status.GetCurrentMin(), status.GetCurrentMax(), readyNodes)
+}
+
+// DependenciesReady returns true once the nodes of all dependencies of an instance group are ready, and an error if the
+// instance group depends on itself through its dependencies
+func (r *InstanceGroupReconciler) DependenciesReady(instanceGroup *v1alpha1.InstanceGroup) (bool, error) {
+	if cycle := r.dependencyCycle(instanceGroup); len(cycle) > 0 {
+		return false, errors.Errorf("dependency cycle detected: %v", strings.Join(cycle, " -> "))
+	}
+
+	for _, dependency := range instanceGroup.GetDependencies() {
+		dependencyGroup := &v1alpha1.InstanceGroup{}
+		if err := r.Get(context.Background(), dependency, dependencyGroup); err != nil {
+			if kerrors.IsNotFound(err) {
+				r.Log.Info("dependency not found", "instancegroup", instanceGroup.NamespacedName(), "dependency", dependency.String())
+				return false, nil
+			}
+			return false, errors.Wrapf(err, "failed to get dependency %v", dependency.String())
+		}
+		if dependencyGroup.GetStatus().GetNodesReadyCondition() != corev1.ConditionTrue {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// ValidateDependencies rejects dependencies on instance groups of other provisioners, only the eks provisioner reports the
+// NodesReady condition a dependent group waits for
+func (r *InstanceGroupReconciler) ValidateDependencies(instanceGroup *v1alpha1.InstanceGroup) error {
+	for _, dependency := range instanceGroup.GetDependencies() {
+		dependencyGroup := &v1alpha1.InstanceGroup{}
+		if err := r.Get(context.Background(), dependency, dependencyGroup); err != nil {
+			if kerrors.IsNotFound(err) {
+				continue
+			}
+			return errors.Wrapf(err, "failed to get dependency %v", dependency.String())
+		}
+		if provisioner := dependencyGroup.Spec.Provisioner; !strings.EqualFold(provisioner, v1alpha1.EKSProvisionerName) {
+			return errors.Errorf("validation failed, dependency %v uses provisioner '%v', only '%v' instance groups can be depended on", dependency.String(), provisioner, v1alpha1.EKSProvisionerName)
+		}
+	}
+	return nil
+}
+
+// dependencyCycle returns the chain of dependencies leading back to the instance group, or nil if there is none
+func (r *InstanceGroupReconciler) dependencyCycle(instanceGroup *v1alpha1.InstanceGroup) []string {
+	var (
+		origin  = instanceGroup.NamespacedName()
+		visited = make(map[string]bool)
+		visit   func(ig *v1alpha1.InstanceGroup, path []string) []string
+	)
+
+	visit = func(ig *v1alpha1.InstanceGroup, path []string) []string {
+		for _, dependency := range ig.GetDependencies() {
+			name := dependency.String()
+			if name == origin {
+				return append(path, name)
+			}
+			if visited[name] {
+				continue
+			}
+			visited[name] = true
+
+			dependencyGroup := &v1alpha1.InstanceGroup{}
+			if err := r.Get(context.Background(), dependency, dependencyGroup); err != nil {
+				continue
+			}
+			if cycle := visit(dependencyGroup, append(path, name)); cycle != nil {
+				return cycle
+			}
+		}
+		return nil
+	}
+	return visit(instanceGroup, []string{origin})
+}
+
+// SetPermissionsCondition sets the InsufficientPermissions condition and publishes an event when a reconcile failed because
+// the controller is missing an IAM permission, the condition is removed once a reconcile succeeds
+func (r *InstanceGroupReconciler) SetPermissionsCondition(instanceGroup *v1alpha1.InstanceGroup, err error) {
+	status := instanceGroup.GetStatus()
+	action, denied := awsprovider.GetAccessDeniedAction(err)
+	if !denied {
+		if err == nil {
+			status.RemoveCondition(v1alpha1.InsufficientPermissions)
+		}
+		return
+	}
+
+	if common.StringEmpty(action) {
+		action = "unknown"
+	}
+	r.Log.Info("controller is missing IAM permissions", "instancegroup", instanceGroup.NamespacedName(), "action", action)
+
+	condition := v1alpha1.NewInstanceGroupCondition(v1alpha1.InsufficientPermissions, corev1.ConditionTrue)
+	condition.Message = fmt.Sprintf("access denied on action %v", action)
+	status.SetCondition(condition)
+
+	publisher := kubeprovider.EventPublisher{
+		Client:          r.Auth.Kubernetes.Kubernetes,
+		Name:            instanceGroup.GetName(),
+		Namespace:       instanceGroup.GetNamespace(),
+		UID:             instanceGroup.GetUID(),
+		ResourceVersion: instanceGroup.GetResourceVersion(),
+	}
+	publisher.Publish(kubeprovider.InsufficientPermissionsEvent, "instancegroup", instanceGroup.NamespacedName(), "action", action, "error", err.Error())
+}
+
+// GetErrorRequeue returns the delay before an instance group which failed to reconcile is requeued. AWS throttling errors
+// back off exponentially from ThrottleBackoff with jitter for every consecutive throttled reconcile, other errors return 0
+// and are requeued by the controller's rate limiter. A nil error resets the backoff of the instance group
+func (r *InstanceGroupReconciler) GetErrorRequeue(name string, err error) time.Duration {
+	r.throttleLock.Lock()
+	defer r.throttleLock.Unlock()
+
+	if !awsprovider.IsThrottlingError(err) {
+		delete(r.throttleAttempts, name)
+		return 0
+	}
+
+	if r.throttleAttempts == nil {
+		r.throttleAttempts = make(map[string]int)
+	}
+	attempts := r.throttleAttempts[name]
+	r.throttleAttempts[name] = attempts + 1
+
+	backoff := r.ThrottleBackoff
+	if backoff <= 0 {
+		backoff = DefaultThrottleBackoff
+	}
+	for i := 0; i < attempts && backoff < MaxThrottleBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > MaxThrottleBackoff {
+		backoff = MaxThrottleBackoff
+	}
+	return wait.Jitter(backoff, 0.5)
+}
+
+// GetReconcileRecord returns the last full reconcile of an instance group, which is zero if the instance group has not
+// been fully reconciled by this controller
+func (r *InstanceGroupReconciler) GetReconcileRecord(name string) provisioners.ReconcileRecord {
+	r.reconcileLock.Lock()
+	defer r.reconcileLock.Unlock()
+	return r.reconcileRecords[name]
+}
+
+// SetReconciled records a full reconcile of an instance group with the given hash and discovered desired capacity,
+// resetting its count of shortcut reconciles
+func (r *InstanceGroupReconciler) SetReconciled(name, hash string, desired int, t time.Time) {
+	r.reconcileLock.Lock()
+	defer r.reconcileLock.Unlock()
+	if r.reconcileRecords == nil {
+		r.reconcileRecords = make(map[string]provisioners.ReconcileRecord)
+	}
+	r.reconcileRecords[name] = provisioners.ReconcileRecord{
+		Hash:    hash,
+		Time:    t,
+		Desired: desired,
+	}
+}
+
+// IncrementShortcutReconciles counts a reconcile of an instance group which skipped cloud discovery
+func (r *InstanceGroupReconciler) IncrementShortcutReconciles(name string) {
+	r.reconcileLock.Lock()
+	defer r.reconcileLock.Unlock()
+	if record, ok := r.reconcileRecords[name]; ok {
+		record.Shortcuts++
+		r.reconcileRecords[name] = record
+	}
+}
+
+// DeleteReconcileRecord forgets the last full reconcile of a deleted instance group
+func (r *InstanceGroupReconciler) DeleteReconcileRecord(name string) {
+	r.reconcileLock.Lock()
+	defer r.reconcileLock.Unlock()
+	delete(r.reconcileRecords, name)
+}
+
+// GetTracer returns the tracer of reconcile spans, spans are not recorded when tracing is disabled
+func (r *InstanceGroupReconciler) GetTracer() trace.Tracer {
+	if r.Tracer == nil {
+		return noop.NewTracerProvider().Tracer(TracerName)
+	}
+	return r.Tracer
+}
+
 func (r *InstanceGroupReconciler) PatchStatus(instanceGroup *v1alpha1.InstanceGroup, patch client.Patch) {
+	// intermediate states of a reconcile are not recorded, otherwise every reconcile would modify the status
+	if base, ok := kubeprovider.PatchBase(patch); ok {
+		instanceGroup.GetStatus().CompactStateHistory(base.GetStatus())
+	}
 	patchData, _ := patch.Data(instanceGroup)
 	r.Log.Info("patching resource status", "instancegroup", instanceGroup.NamespacedName(), "patch", string(patchData), "resourceVersion", instanceGroup.GetResourceVersion())
 	if err := r.Status().Patch(context.Background(), instanceGroup, patch); err != nil {
@@ -0,0 +1,789 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/autoscaling"
+	"github.com/aws/aws-sdk-go/service/autoscaling/autoscalingiface"
+	"github.com/aws/aws-sdk-go/service/eks"
+	"github.com/keikoproj/aws-sdk-go-cache/cache"
+	v1alpha1 "github.com/keikoproj/instance-manager/api/instancemgr/v1alpha1"
+	"github.com/keikoproj/instance-manager/controllers/common"
+	awsprovider "github.com/keikoproj/instance-manager/controllers/providers/aws"
+	kubeprovider "github.com/keikoproj/instance-manager/controllers/providers/kubernetes"
+	"github.com/keikoproj/instance-manager/controllers/provisioners"
+	"github.com/keikoproj/instance-manager/controllers/provisioners/eksmanaged"
+	"github.com/onsi/gomega"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/util/workqueue"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func MockReconciler(objects ...*v1alpha1.InstanceGroup) *InstanceGroupReconciler {
+	scheme := runtime.NewScheme()
+	_ = v1alpha1.AddToScheme(scheme)
+
+	builder := fake.NewClientBuilder().WithScheme(scheme)
+	for _, obj := range objects {
+		builder = builder.WithObjects(obj)
+	}
+
+	return &InstanceGroupReconciler{
+		Client:         builder.Build(),
+		Log:            ctrl.Log.WithName("test"),
+		Auth:           &InstanceGroupAuthenticator{},
+		ConfigMap:      &corev1.ConfigMap{},
+		Namespaces:     make(map[string]corev1.Namespace),
+		NamespacesLock: &sync.RWMutex{},
+		Metrics:        common.NewMetricsCollector(),
+	}
+}
+
+func TestReconcilePaused(t *testing.T) {
+	var (
+		g   = gomega.NewGomegaWithT(t)
+		req = ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "paused-ig"}}
+	)
+
+	ig := &v1alpha1.InstanceGroup{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "paused-ig",
+			Namespace: "default",
+			Annotations: map[string]string{
+				v1alpha1.PausedAnnotationKey: "true",
+			},
+		},
+		Spec: v1alpha1.InstanceGroupSpec{
+			Provisioner: v1alpha1.EKSProvisionerName,
+			EKSSpec: &v1alpha1.EKSSpec{
+				EKSConfiguration: &v1alpha1.EKSConfiguration{},
+			},
+		},
+		Status: v1alpha1.InstanceGroupStatus{
+			CurrentState: string(v1alpha1.ReconcileReady),
+		},
+	}
+	r := MockReconciler(ig)
+
+	getInstanceGroup := func() *v1alpha1.InstanceGroup {
+		current := &v1alpha1.InstanceGroup{}
+		g.Expect(r.Get(context.Background(), req.NamespacedName, current)).To(gomega.Succeed())
+		return current
+	}
+
+	// paused instance groups are not reconciled, even though the spec is invalid
+	result, err := r.Reconcile(context.Background(), req)
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(result).To(gomega.Equal(ctrl.Result{}))
+
+	paused := getInstanceGroup()
+	g.Expect(paused.GetState()).To(gomega.Equal(v1alpha1.ReconcileReady))
+	g.Expect(paused.GetStatus().GetConditions()).To(gomega.ContainElement(v1alpha1.NewInstanceGroupCondition(v1alpha1.Paused, corev1.ConditionTrue)))
+
+	// reconciling again while paused does not duplicate the condition
+	_, err = r.Reconcile(context.Background(), req)
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(getInstanceGroup().GetStatus().GetConditions()).To(gomega.HaveLen(1))
+
+	// once resumed the instance group is reconciled and the condition is removed
+	paused = getInstanceGroup()
+	delete(paused.Annotations, v1alpha1.PausedAnnotationKey)
+	g.Expect(r.Update(context.Background(), paused)).To(gomega.Succeed())
+
+	_, err = r.Reconcile(context.Background(), req)
+	g.Expect(err).To(gomega.HaveOccurred())
+
+	resumed := getInstanceGroup()
+	g.Expect(resumed.GetState()).To(gomega.Equal(v1alpha1.ReconcileErr))
+	g.Expect(resumed.GetStatus().GetConditions()).NotTo(gomega.ContainElement(v1alpha1.NewInstanceGroupCondition(v1alpha1.Paused, corev1.ConditionTrue)))
+}
+
+func TestReconcileBypassCache(t *testing.T) {
+	var (
+		g        = gomega.NewGomegaWithT(t)
+		req      = ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "uncached-ig"}}
+		requests int32
+	)
+
+	// the node group is being created, the reconcile only describes it and requeues
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		fmt.Fprint(w, `{"nodegroup":{"nodegroupName":"uncached-ig","status":"CREATING","scalingConfig":{"minSize":1,"maxSize":3}}}`)
+	}))
+	defer server.Close()
+
+	cacheCfg := cache.NewConfig(awsprovider.CacheDefaultTTL, awsprovider.CacheBackgroundPruningInterval, awsprovider.CacheMaxItems, awsprovider.CacheItemsToPrune)
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(cacheCfg.NewCacheCollector("test"))
+	sess, err := session.NewSession(aws.NewConfig().
+		WithRegion("us-west-2").
+		WithEndpoint(server.URL).
+		WithCredentials(credentials.NewStaticCredentials("id", "secret", "")))
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	cache.AddCaching(sess, cacheCfg)
+	cacheCfg.SetCacheTTL("eks", "DescribeNodegroup", time.Hour)
+
+	ig := &v1alpha1.InstanceGroup{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "uncached-ig",
+			Namespace: "default",
+		},
+		Spec: v1alpha1.InstanceGroupSpec{
+			Provisioner: v1alpha1.EKSManagedProvisionerName,
+			EKSManagedSpec: &v1alpha1.EKSManagedSpec{
+				MaxSize: 3,
+				MinSize: 1,
+				EKSManagedConfiguration: &v1alpha1.EKSManagedConfiguration{
+					EksClusterName:     "my-cluster",
+					VolSize:            20,
+					InstanceType:       "m5.large",
+					NodeRole:           "some-iam-role",
+					NodeSecurityGroups: []string{"sg-122222"},
+					AmiType:            "AL2_x86_64",
+					Subnets:            []string{"subnet-122222"},
+				},
+			},
+			AwsUpgradeStrategy: v1alpha1.AwsUpgradeStrategy{
+				Type: "managed",
+			},
+		},
+	}
+	r := MockReconciler(ig)
+	r.Auth.Aws = awsprovider.AwsWorker{
+		EksClient: eks.New(sess),
+	}
+
+	cacheHits := func() float64 {
+		var hits float64
+		families, err := registry.Gather()
+		g.Expect(err).NotTo(gomega.HaveOccurred())
+		for _, family := range families {
+			if family.GetName() != "test_aws_api_cache_activity" {
+				continue
+			}
+			for _, metric := range family.GetMetric() {
+				for _, label := range metric.GetLabel() {
+					if label.GetName() == "action" && label.GetValue() == "hit" {
+						hits += metric.GetCounter().GetValue()
+					}
+				}
+			}
+		}
+		return hits
+	}
+
+	reconcile := func() {
+		result, err := r.Reconcile(context.Background(), req)
+		g.Expect(err).NotTo(gomega.HaveOccurred())
+		g.Expect(result.RequeueAfter).To(gomega.BeNumerically(">", 0))
+	}
+
+	// cloud discovery describes the node group twice, the second describe is served by the cache
+	reconcile()
+	g.Expect(atomic.LoadInt32(&requests)).To(gomega.Equal(int32(1)))
+	g.Expect(cacheHits()).To(gomega.Equal(float64(1)))
+
+	reconcile()
+	g.Expect(atomic.LoadInt32(&requests)).To(gomega.Equal(int32(1)))
+	g.Expect(cacheHits()).To(gomega.Equal(float64(3)))
+
+	current := &v1alpha1.InstanceGroup{}
+	g.Expect(r.Get(context.Background(), req.NamespacedName, current)).To(gomega.Succeed())
+	current.Annotations = map[string]string{v1alpha1.CacheBypassAnnotationKey: "true"}
+	g.Expect(r.Update(context.Background(), current)).To(gomega.Succeed())
+
+	// every describe of the annotated reconcile is sent to AWS and no cache hit is counted
+	reconcile()
+	g.Expect(atomic.LoadInt32(&requests)).To(gomega.Equal(int32(3)))
+	g.Expect(cacheHits()).To(gomega.Equal(float64(3)))
+
+	// the responses of the annotated reconcile refreshed the cache shared with other instance groups
+	_, err = eks.New(sess).DescribeNodegroup(&eks.DescribeNodegroupInput{
+		ClusterName:   aws.String("my-cluster"),
+		NodegroupName: aws.String("uncached-ig"),
+	})
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(atomic.LoadInt32(&requests)).To(gomega.Equal(int32(3)))
+	g.Expect(cacheHits()).To(gomega.Equal(float64(4)))
+}
+
+type MockCloudDeployer struct {
+	State     v1alpha1.ReconcileState
+	UpdateErr error
+}
+
+func (d *MockCloudDeployer) CloudDiscovery() error { return nil }
+func (d *MockCloudDeployer) StateDiscovery()       { d.State = v1alpha1.ReconcileInitUpdate }
+func (d *MockCloudDeployer) Create() error         { return nil }
+func (d *MockCloudDeployer) Delete() error         { return nil }
+func (d *MockCloudDeployer) UpgradeNodes() error   { d.State = v1alpha1.ReconcileModified; return nil }
+func (d *MockCloudDeployer) BootstrapNodes() error { return nil }
+func (d *MockCloudDeployer) GetState() v1alpha1.ReconcileState {
+	return d.State
+}
+func (d *MockCloudDeployer) SetState(state v1alpha1.ReconcileState) { d.State = state }
+func (d *MockCloudDeployer) IsReady() bool                          { return d.State == v1alpha1.ReconcileModified }
+func (d *MockCloudDeployer) Locked() bool                           { return false }
+func (d *MockCloudDeployer) Update() error {
+	if d.UpdateErr != nil {
+		return d.UpdateErr
+	}
+	d.State = v1alpha1.ReconcileInitUpgrade
+	return nil
+}
+
+func TestHandleReconcileRequestSpans(t *testing.T) {
+	var (
+		g        = gomega.NewGomegaWithT(t)
+		recorder = tracetest.NewSpanRecorder()
+		provider = sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+		tracer   = provider.Tracer(TracerName)
+		igName   = attribute.String("instancegroup", "default/my-ig")
+	)
+
+	spanNames := func() []string {
+		names := make([]string, 0)
+		for _, span := range recorder.Ended() {
+			names = append(names, span.Name())
+			g.Expect(span.Attributes()).To(gomega.ContainElement(igName))
+		}
+		return names
+	}
+
+	deployer := &MockCloudDeployer{}
+	err := HandleReconcileRequest(context.Background(), tracer, deployer, igName)
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(deployer.GetState()).To(gomega.Equal(v1alpha1.ReconcileReady))
+	g.Expect(spanNames()).To(gomega.Equal([]string{"CloudDiscovery", "StateDiscovery", "Update", "UpgradeNodes", "BootstrapNodes"}))
+
+	// failed phases are recorded with an error status
+	recorder = tracetest.NewSpanRecorder()
+	provider.RegisterSpanProcessor(recorder)
+	deployer = &MockCloudDeployer{UpdateErr: errors.New("update failed")}
+	err = HandleReconcileRequest(context.Background(), tracer, deployer, igName)
+	g.Expect(err).To(gomega.HaveOccurred())
+	g.Expect(spanNames()).To(gomega.Equal([]string{"CloudDiscovery", "StateDiscovery", "Update"}))
+	g.Expect(recorder.Ended()[2].Status().Code).To(gomega.Equal(codes.Error))
+}
+
+type MockScalingCloudDeployer struct {
+	MockCloudDeployer
+	Desired    int
+	ReadyNodes int
+}
+
+func (d *MockScalingCloudDeployer) GetScalingMetrics() (int, int, bool) {
+	return d.Desired, d.ReadyNodes, true
+}
+
+func TestSetScalingMetrics(t *testing.T) {
+	var (
+		g     = gomega.NewGomegaWithT(t)
+		req   = ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "scaling-ig"}}
+		names = []string{"instance_manager_group_desired", "instance_manager_group_min", "instance_manager_group_max", "instance_manager_group_ready_nodes"}
+	)
+
+	ig := &v1alpha1.InstanceGroup{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "scaling-ig",
+			Namespace: "default",
+		},
+		Spec: v1alpha1.InstanceGroupSpec{
+			Provisioner: v1alpha1.EKSProvisionerName,
+		},
+		Status: v1alpha1.InstanceGroupStatus{
+			CurrentMin: 1,
+			CurrentMax: 5,
+		},
+	}
+	r := MockReconciler()
+
+	deployer := &MockScalingCloudDeployer{Desired: 3, ReadyNodes: 2}
+	err := HandleReconcileRequest(context.Background(), r.GetTracer(), deployer)
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	r.SetScalingMetrics(ig, deployer)
+
+	expected := `
+# HELP instance_manager_group_desired desired capacity of the scaling group of an instance group
+# TYPE instance_manager_group_desired gauge
+instance_manager_group_desired{name="scaling-ig",namespace="default",provisioner="eks"} 3
+# HELP instance_manager_group_max maximum size of the scaling group of an instance group
+# TYPE instance_manager_group_max gauge
+instance_manager_group_max{name="scaling-ig",namespace="default",provisioner="eks"} 5
+# HELP instance_manager_group_min minimum size of the scaling group of an instance group
+# TYPE instance_manager_group_min gauge
+instance_manager_group_min{name="scaling-ig",namespace="default",provisioner="eks"} 1
+# HELP instance_manager_group_ready_nodes number of ready nodes of an instance group
+# TYPE instance_manager_group_ready_nodes gauge
+instance_manager_group_ready_nodes{name="scaling-ig",namespace="default",provisioner="eks"} 2
+`
+	g.Expect(testutil.CollectAndCompare(r.Metrics, strings.NewReader(expected), names...)).To(gomega.Succeed())
+
+	// the series of a previous provisioner are removed when the provisioner changes
+	ig.Spec.Provisioner = v1alpha1.EKSManagedProvisionerName
+	r.SetScalingMetrics(ig, deployer)
+	g.Expect(testutil.CollectAndCount(r.Metrics, names...)).To(gomega.Equal(4))
+	g.Expect(testutil.CollectAndCompare(r.Metrics, strings.NewReader(strings.ReplaceAll(expected, `provisioner="eks"`, `provisioner="eks-managed"`)), names...)).To(gomega.Succeed())
+
+	// the gauges are removed once the instance group is deleted
+	deployer.SetState(v1alpha1.ReconcileDeleted)
+	r.SetScalingMetrics(ig, deployer)
+	g.Expect(testutil.CollectAndCount(r.Metrics, names...)).To(gomega.BeZero())
+
+	// and when the deleted instance group is no longer found
+	r.SetScalingMetrics(ig, &MockScalingCloudDeployer{Desired: 3, ReadyNodes: 2})
+	g.Expect(testutil.CollectAndCount(r.Metrics, names...)).To(gomega.Equal(4))
+	_, err = r.Reconcile(context.Background(), req)
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(testutil.CollectAndCount(r.Metrics, names...)).To(gomega.BeZero())
+}
+
+func TestReconcileShortcutScalingMetrics(t *testing.T) {
+	var (
+		g         = gomega.NewGomegaWithT(t)
+		req       = ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "shortcut-ig"}}
+		clientSet = kubefake.NewSimpleClientset()
+		names     = []string{"instance_manager_group_desired", "instance_manager_group_min", "instance_manager_group_max", "instance_manager_group_ready_nodes"}
+	)
+
+	ig := &v1alpha1.InstanceGroup{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "shortcut-ig",
+			Namespace: "default",
+		},
+		Spec: v1alpha1.InstanceGroupSpec{
+			Provisioner: v1alpha1.EKSProvisionerName,
+			EKSSpec: &v1alpha1.EKSSpec{
+				MaxSize: 5,
+				MinSize: 1,
+				EKSConfiguration: &v1alpha1.EKSConfiguration{
+					Image:              "ami-123456789012",
+					EksClusterName:     "my-cluster",
+					InstanceType:       "m5.large",
+					NodeSecurityGroups: []string{"sg-122222"},
+					Subnets:            []string{"subnet-122222"},
+				},
+			},
+			AwsUpgradeStrategy: v1alpha1.AwsUpgradeStrategy{
+				Type: v1alpha1.RollingUpdateStrategyName,
+			},
+		},
+		Status: v1alpha1.InstanceGroupStatus{
+			CurrentState: string(v1alpha1.ReconcileReady),
+			CurrentMin:   1,
+			CurrentMax:   5,
+		},
+	}
+	r := MockReconciler(ig)
+	r.ReconcileShortcutInterval = time.Minute
+	r.ReconcileShortcutCount = 5
+	r.Auth.Kubernetes = kubeprovider.KubernetesClientSet{Kubernetes: clientSet}
+
+	for _, id := range []string{"i-1111", "i-2222"} {
+		node := &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:   id,
+				Labels: map[string]string{"node.kubernetes.io/role": "shortcut-ig"},
+			},
+			Status: corev1.NodeStatus{
+				Conditions: []corev1.NodeCondition{{Type: corev1.NodeReady, Status: corev1.ConditionTrue}},
+			},
+		}
+		_, err := clientSet.CoreV1().Nodes().Create(context.Background(), node, metav1.CreateOptions{})
+		g.Expect(err).NotTo(gomega.HaveOccurred())
+	}
+
+	// the last full reconcile of the validated instance group discovered a desired capacity of 3
+	reconciled := ig.DeepCopy()
+	reconciled.GetStatus().SetConfigHash(kubeprovider.ConfigmapHash(r.ConfigMap))
+	g.Expect(reconciled.Validate(v1alpha1.NewValidationOverrides(r.DefaultScalingConfiguration, nil, r.DefaultProfileNamePrefix))).To(gomega.Succeed())
+	r.SetReconciled(req.NamespacedName.String(), provisioners.GetReconcileHash(reconciled), 3, time.Now())
+
+	result, err := r.Reconcile(context.Background(), req)
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(result).To(gomega.Equal(ctrl.Result{}))
+	g.Expect(r.GetReconcileRecord(req.NamespacedName.String()).Shortcuts).To(gomega.Equal(1))
+
+	expected := `
+# HELP instance_manager_group_desired desired capacity of the scaling group of an instance group
+# TYPE instance_manager_group_desired gauge
+instance_manager_group_desired{name="shortcut-ig",namespace="default",provisioner="eks"} 3
+# HELP instance_manager_group_max maximum size of the scaling group of an instance group
+# TYPE instance_manager_group_max gauge
+instance_manager_group_max{name="shortcut-ig",namespace="default",provisioner="eks"} 5
+# HELP instance_manager_group_min minimum size of the scaling group of an instance group
+# TYPE instance_manager_group_min gauge
+instance_manager_group_min{name="shortcut-ig",namespace="default",provisioner="eks"} 1
+# HELP instance_manager_group_ready_nodes number of ready nodes of an instance group
+# TYPE instance_manager_group_ready_nodes gauge
+instance_manager_group_ready_nodes{name="shortcut-ig",namespace="default",provisioner="eks"} 2
+`
+	g.Expect(testutil.CollectAndCompare(r.Metrics, strings.NewReader(expected), names...)).To(gomega.Succeed())
+}
+
+type MockAutoScalingClient struct {
+	autoscalingiface.AutoScalingAPI
+	PutWarmPoolErr    error
+	AutoScalingGroups []*autoscaling.Group
+}
+
+func (a *MockAutoScalingClient) PutWarmPool(input *autoscaling.PutWarmPoolInput) (*autoscaling.PutWarmPoolOutput, error) {
+	return &autoscaling.PutWarmPoolOutput{}, a.PutWarmPoolErr
+}
+
+func (a *MockAutoScalingClient) DescribeAutoScalingInstances(input *autoscaling.DescribeAutoScalingInstancesInput) (*autoscaling.DescribeAutoScalingInstancesOutput, error) {
+	out := &autoscaling.DescribeAutoScalingInstancesOutput{}
+	for _, group := range a.AutoScalingGroups {
+		for _, instance := range group.Instances {
+			if common.ContainsString(aws.StringValueSlice(input.InstanceIds), aws.StringValue(instance.InstanceId)) {
+				out.AutoScalingInstances = append(out.AutoScalingInstances, &autoscaling.InstanceDetails{
+					AutoScalingGroupName: group.AutoScalingGroupName,
+					InstanceId:           instance.InstanceId,
+				})
+			}
+		}
+	}
+	return out, nil
+}
+
+func (a *MockAutoScalingClient) DescribeAutoScalingGroups(input *autoscaling.DescribeAutoScalingGroupsInput) (*autoscaling.DescribeAutoScalingGroupsOutput, error) {
+	out := &autoscaling.DescribeAutoScalingGroupsOutput{}
+	for _, group := range a.AutoScalingGroups {
+		if common.ContainsString(aws.StringValueSlice(input.AutoScalingGroupNames), aws.StringValue(group.AutoScalingGroupName)) {
+			out.AutoScalingGroups = append(out.AutoScalingGroups, group)
+		}
+	}
+	return out, nil
+}
+
+func TestSpotInterruptionForcesFullReconcile(t *testing.T) {
+	var (
+		g         = gomega.NewGomegaWithT(t)
+		r         = MockReconciler()
+		clientSet = kubefake.NewSimpleClientset()
+		asgMock   = &MockAutoScalingClient{
+			AutoScalingGroups: []*autoscaling.Group{
+				{
+					AutoScalingGroupName: aws.String("my-asg"),
+					Instances: []*autoscaling.Instance{
+						{InstanceId: aws.String("i-1234")},
+					},
+					Tags: []*autoscaling.TagDescription{
+						{Key: aws.String(provisioners.TagInstanceGroupName), Value: aws.String("my-ig")},
+						{Key: aws.String(provisioners.TagInstanceGroupNamespace), Value: aws.String("default")},
+					},
+				},
+			},
+		}
+	)
+
+	r.Auth.Kubernetes = kubeprovider.KubernetesClientSet{Kubernetes: clientSet}
+	r.Auth.Aws = awsprovider.AwsWorker{AsgClient: asgMock}
+
+	node := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "node-1"},
+		Spec:       corev1.NodeSpec{ProviderID: "aws:///us-west-2a/i-1234"},
+	}
+	_, err := clientSet.CoreV1().Nodes().Create(context.Background(), node, metav1.CreateOptions{})
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+
+	r.SetReconciled("default/my-ig", "some-hash", 1, time.Now())
+
+	// the interrupted node's instance group is fully reconciled so that the interruption is counted
+	event := &corev1.Event{
+		ObjectMeta: metav1.ObjectMeta{Name: "node-1.interruption", Namespace: "default"},
+		Reason:     kubeprovider.SpotInterruptionReason,
+		InvolvedObject: corev1.ObjectReference{
+			Kind: "Node",
+			Name: "node-1",
+		},
+	}
+	requests := r.spotEventReconciler(event)
+	g.Expect(requests).To(gomega.Equal([]ctrl.Request{{NamespacedName: types.NamespacedName{Namespace: "default", Name: "my-ig"}}}))
+	g.Expect(r.GetReconcileRecord("default/my-ig")).To(gomega.BeZero())
+}
+
+func TestSetPermissionsCondition(t *testing.T) {
+	var (
+		g         = gomega.NewGomegaWithT(t)
+		r         = MockReconciler()
+		clientSet = kubefake.NewSimpleClientset()
+		asgMock   = &MockAutoScalingClient{
+			PutWarmPoolErr: awserr.New("AccessDenied", "User: arn:aws:sts::123456789012:assumed-role/instance-manager/i-1234 is not authorized to perform: autoscaling:PutWarmPool on resource: arn:aws:autoscaling:us-west-2:123456789012:autoScalingGroup:*", nil),
+		}
+		w = awsprovider.AwsWorker{AsgClient: asgMock}
+	)
+
+	r.Auth.Kubernetes = kubeprovider.KubernetesClientSet{Kubernetes: clientSet}
+	ig := &v1alpha1.InstanceGroup{
+		ObjectMeta: metav1.ObjectMeta{Name: "my-ig", Namespace: "default"},
+	}
+
+	err := errors.Wrap(w.UpdateWarmPool("my-asg", 0, 1), "failed to update warm pool")
+	r.SetPermissionsCondition(ig, err)
+
+	conditions := ig.GetStatus().GetConditions()
+	g.Expect(conditions).To(gomega.HaveLen(1))
+	g.Expect(conditions[0].Type).To(gomega.Equal(v1alpha1.InsufficientPermissions))
+	g.Expect(conditions[0].Status).To(gomega.Equal(corev1.ConditionTrue))
+	g.Expect(conditions[0].Message).To(gomega.ContainSubstring("autoscaling:PutWarmPool"))
+
+	events, err := clientSet.CoreV1().Events("default").List(context.Background(), metav1.ListOptions{})
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(events.Items).To(gomega.HaveLen(1))
+	g.Expect(events.Items[0].Reason).To(gomega.Equal(string(kubeprovider.InsufficientPermissionsEvent)))
+	g.Expect(events.Items[0].Message).To(gomega.ContainSubstring("autoscaling:PutWarmPool"))
+
+	// other failures leave the condition in place until a reconcile succeeds
+	r.SetPermissionsCondition(ig, errors.New("some other failure"))
+	g.Expect(ig.GetStatus().GetConditions()).To(gomega.HaveLen(1))
+
+	r.SetPermissionsCondition(ig, nil)
+	g.Expect(ig.GetStatus().GetConditions()).To(gomega.BeEmpty())
+}
+
+func TestDependenciesReady(t *testing.T) {
+	var (
+		g = gomega.NewGomegaWithT(t)
+	)
+
+	system := &v1alpha1.InstanceGroup{
+		ObjectMeta: metav1.ObjectMeta{Name: "system", Namespace: "kube-system"},
+		Spec: v1alpha1.InstanceGroupSpec{
+			Provisioner: v1alpha1.EKSProvisionerName,
+			EKSSpec:     &v1alpha1.EKSSpec{},
+		},
+	}
+	workload := &v1alpha1.InstanceGroup{
+		ObjectMeta: metav1.ObjectMeta{Name: "workload", Namespace: "default"},
+		Spec: v1alpha1.InstanceGroupSpec{
+			Provisioner: v1alpha1.EKSProvisionerName,
+			EKSSpec: &v1alpha1.EKSSpec{
+				DependsOn: []string{"kube-system/system"},
+			},
+		},
+	}
+	r := MockReconciler(system, workload)
+
+	g.Expect(r.ValidateDependencies(workload)).To(gomega.Succeed())
+
+	// the workload group waits until the system group's nodes are ready
+	ready, err := r.DependenciesReady(workload)
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(ready).To(gomega.BeFalse())
+
+	system.GetStatus().SetCondition(v1alpha1.NewInstanceGroupCondition(v1alpha1.NodesReady, corev1.ConditionTrue))
+	g.Expect(r.Status().Update(context.Background(), system)).To(gomega.Succeed())
+
+	ready, err = r.DependenciesReady(workload)
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(ready).To(gomega.BeTrue())
+
+	// missing dependencies are waited for
+	workload.Spec.EKSSpec.DependsOn = append(workload.Spec.EKSSpec.DependsOn, "missing")
+	ready, err = r.DependenciesReady(workload)
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(ready).To(gomega.BeFalse())
+
+	// a dependency cycle through another group is rejected
+	workload.Spec.EKSSpec.DependsOn = []string{"kube-system/system"}
+	g.Expect(r.Update(context.Background(), workload)).To(gomega.Succeed())
+	g.Expect(r.Get(context.Background(), types.NamespacedName{Namespace: "kube-system", Name: "system"}, system)).To(gomega.Succeed())
+	system.Spec.EKSSpec.DependsOn = []string{"default/workload"}
+	g.Expect(r.Update(context.Background(), system)).To(gomega.Succeed())
+
+	_, err = r.DependenciesReady(workload)
+	g.Expect(err).To(gomega.MatchError(gomega.ContainSubstring("default/workload -> kube-system/system -> default/workload")))
+}
+
+func TestValidateDependencies(t *testing.T) {
+	var (
+		g = gomega.NewGomegaWithT(t)
+	)
+
+	managed := &v1alpha1.InstanceGroup{
+		ObjectMeta: metav1.ObjectMeta{Name: "managed", Namespace: "default"},
+		Spec: v1alpha1.InstanceGroupSpec{
+			Provisioner:    v1alpha1.EKSManagedProvisionerName,
+			EKSManagedSpec: &v1alpha1.EKSManagedSpec{},
+		},
+	}
+	fargate := &v1alpha1.InstanceGroup{
+		ObjectMeta: metav1.ObjectMeta{Name: "fargate", Namespace: "default"},
+		Spec: v1alpha1.InstanceGroupSpec{
+			Provisioner:    v1alpha1.EKSFargateProvisionerName,
+			EKSFargateSpec: &v1alpha1.EKSFargateSpec{},
+		},
+	}
+	workload := &v1alpha1.InstanceGroup{
+		ObjectMeta: metav1.ObjectMeta{Name: "workload", Namespace: "default"},
+		Spec: v1alpha1.InstanceGroupSpec{
+			Provisioner: v1alpha1.EKSProvisionerName,
+			EKSSpec:     &v1alpha1.EKSSpec{},
+		},
+	}
+	r := MockReconciler(managed, fargate, workload)
+
+	// groups of other provisioners never report ready nodes and cannot be depended on
+	workload.Spec.EKSSpec.DependsOn = []string{"managed"}
+	g.Expect(r.ValidateDependencies(workload)).To(gomega.MatchError(gomega.ContainSubstring("dependency default/managed uses provisioner 'eks-managed'")))
+
+	workload.Spec.EKSSpec.DependsOn = []string{"default/fargate"}
+	g.Expect(r.ValidateDependencies(workload)).To(gomega.MatchError(gomega.ContainSubstring("dependency default/fargate uses provisioner 'eks-fargate'")))
+
+	// missing dependencies are validated once they exist
+	workload.Spec.EKSSpec.DependsOn = []string{"missing"}
+	g.Expect(r.ValidateDependencies(workload)).To(gomega.Succeed())
+}
+
+func TestGetErrorRequeue(t *testing.T) {
+	var (
+		g          = gomega.NewGomegaWithT(t)
+		r          = MockReconciler()
+		throttled  = errors.Wrap(awserr.New("RequestLimitExceeded", "Request limit exceeded.", nil), "failed to describe subnets")
+		generic    = errors.New("some failure")
+		rateLimits = workqueue.DefaultControllerRateLimiter()
+	)
+
+	r.ThrottleBackoff = 10 * time.Second
+
+	// generic errors are left to the controller's rate limiter
+	g.Expect(r.GetErrorRequeue("default/my-ig", generic)).To(gomega.BeZero())
+
+	first := r.GetErrorRequeue("default/my-ig", throttled)
+	g.Expect(first).To(gomega.BeNumerically(">", rateLimits.When("default/my-ig")))
+	g.Expect(first).To(gomega.BeNumerically(">=", 10*time.Second))
+	g.Expect(first).To(gomega.BeNumerically("<=", 15*time.Second))
+
+	// consecutive throttled reconciles back off exponentially
+	second := r.GetErrorRequeue("default/my-ig", throttled)
+	g.Expect(second).To(gomega.BeNumerically(">=", 20*time.Second))
+	g.Expect(second).To(gomega.BeNumerically("<=", 30*time.Second))
+
+	// other instance groups are not affected
+	g.Expect(r.GetErrorRequeue("default/other-ig", throttled)).To(gomega.BeNumerically("<=", 15*time.Second))
+
+	for i := 0; i < 10; i++ {
+		r.GetErrorRequeue("default/my-ig", throttled)
+	}
+	g.Expect(r.GetErrorRequeue("default/my-ig", throttled)).To(gomega.BeNumerically("<=", MaxThrottleBackoff*3/2))
+
+	// a successful reconcile resets the backoff
+	g.Expect(r.GetErrorRequeue("default/my-ig", nil)).To(gomega.BeZero())
+	g.Expect(r.GetErrorRequeue("default/my-ig", throttled)).To(gomega.BeNumerically("<=", 15*time.Second))
+}
+
+func TestReconcileRecords(t *testing.T) {
+	var (
+		g   = gomega.NewGomegaWithT(t)
+		r   = MockReconciler()
+		now = time.Now()
+	)
+
+	// shortcuts are not counted before a full reconcile
+	r.IncrementShortcutReconciles("default/my-ig")
+	g.Expect(r.GetReconcileRecord("default/my-ig")).To(gomega.BeZero())
+
+	r.SetReconciled("default/my-ig", "some-hash", 3, now)
+	r.IncrementShortcutReconciles("default/my-ig")
+	r.IncrementShortcutReconciles("default/my-ig")
+	g.Expect(r.GetReconcileRecord("default/my-ig")).To(gomega.Equal(provisioners.ReconcileRecord{Hash: "some-hash", Time: now, Shortcuts: 2, Desired: 3}))
+	g.Expect(r.GetReconcileRecord("default/other-ig")).To(gomega.BeZero())
+
+	// a full reconcile resets the count of shortcuts
+	r.SetReconciled("default/my-ig", "other-hash", 3, now)
+	g.Expect(r.GetReconcileRecord("default/my-ig").Shortcuts).To(gomega.BeZero())
+
+	r.DeleteReconcileRecord("default/my-ig")
+	g.Expect(r.GetReconcileRecord("default/my-ig")).To(gomega.BeZero())
+}
+
+func TestRedactSpec(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+
+	spec := v1alpha1.InstanceGroupSpec{
+		Provisioner: v1alpha1.EKSProvisionerName,
+		EKSSpec: &v1alpha1.EKSSpec{
+			EKSConfiguration: &v1alpha1.EKSConfiguration{
+				UserData: []v1alpha1.UserDataStage{
+					{Name: "login", Stage: "PreBootstrap", Data: "export API_TOKEN=abc123\necho done"},
+				},
+			},
+		},
+	}
+
+	redacted := RedactSpec(spec)
+	g.Expect(redacted.EKSSpec.EKSConfiguration.UserData[0].Data).To(gomega.Equal("export API_TOKEN=REDACTED\necho done"))
+	g.Expect(redacted.EKSSpec.EKSConfiguration.UserData[0].Name).To(gomega.Equal("login"))
+
+	// the logged spec is a copy, the reconciled spec keeps its userData
+	g.Expect(spec.EKSSpec.EKSConfiguration.UserData[0].Data).To(gomega.Equal("export API_TOKEN=abc123\necho done"))
+
+	// specs without userData are logged as is
+	g.Expect(RedactSpec(v1alpha1.InstanceGroupSpec{Provisioner: v1alpha1.EKSManagedProvisionerName})).To(gomega.Equal(&v1alpha1.InstanceGroupSpec{Provisioner: v1alpha1.EKSManagedProvisionerName}))
+}
+
+func TestGetRequeueInterval(t *testing.T) {
+	var (
+		g  = gomega.NewGomegaWithT(t)
+		ig = &v1alpha1.InstanceGroup{
+			ObjectMeta: metav1.ObjectMeta{Name: "managed-ig", Namespace: "default"},
+			Spec: v1alpha1.InstanceGroupSpec{
+				Provisioner:    eksmanaged.ProvisionerName,
+				EKSManagedSpec: &v1alpha1.EKSManagedSpec{},
+			},
+		}
+	)
+
+	// provisioners without a requeue interval use the default
+	g.Expect(GetRequeueInterval(&MockCloudDeployer{})).To(gomega.Equal(DefaultRequeueInterval))
+
+	input := provisioners.ProvisionerInput{
+		InstanceGroup:       ig,
+		Log:                 ctrl.Log.WithName("test"),
+		ManagedPollInterval: 45 * time.Second,
+	}
+	g.Expect(GetRequeueInterval(eksmanaged.New(input))).To(gomega.Equal(45 * time.Second))
+
+	input.ManagedPollInterval = 0
+	g.Expect(GetRequeueInterval(eksmanaged.New(input))).To(gomega.Equal(eksmanaged.DefaultPollInterval))
+}
@@ -1,7 +1,18 @@
 package controllers
 
 import (
+	"context"
+	"time"
+
 	v1alpha "github.com/keikoproj/instance-manager/api/instancemgr/v1alpha1"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	// TracerName is the instrumentation name of reconcile spans
+	TracerName = "github.com/keikoproj/instance-manager/controllers"
 )
 
 // CloudDeployer is a common interface that should be fulfilled by each provisioner
@@ -19,19 +30,60 @@ type CloudDeployer interface {
 	Locked() bool                     // Returns true if instanceGroup is locked
 }
 
-func HandleReconcileRequest(d CloudDeployer) error {
+// ReconcileShortcutter is implemented by provisioners which can skip cloud discovery for unchanged instance groups
+type ReconcileShortcutter interface {
+	ReconcileShortcut() bool // Returns true if the reconcile can end without cloud discovery
+}
+
+// ReconcileDeferrer is implemented by provisioners which can defer a reconcile until the cluster is able to accept changes
+type ReconcileDeferrer interface {
+	IsDeferred() bool // Returns true if the reconcile was deferred and should be retried with backoff
+}
+
+// ReconcileWaiter is implemented by provisioners which poll operations that continue after an instance group is ready or
+// has failed
+type ReconcileWaiter interface {
+	IsWaiting() bool // Returns true if the reconcile should be requeued until an ongoing operation completes
+}
+
+// ScalingMetricsReporter is implemented by provisioners which discover the scaling group and nodes of an instance group
+type ScalingMetricsReporter interface {
+	GetScalingMetrics() (desired, readyNodes int, ok bool) // Returns the desired capacity and ready nodes, ok is false if they were not discovered
+}
+
+// ReconcileRequeuer is implemented by provisioners which control how often an instance group in an ongoing state is requeued
+type ReconcileRequeuer interface {
+	GetRequeueInterval() time.Duration // Returns the delay before a retryable reconcile is requeued, the default is used when 0
+}
+
+// HandleReconcileRequest runs the reconcile phases of a provisioner, each phase is wrapped in a span carrying the given attributes
+func HandleReconcileRequest(ctx context.Context, tracer trace.Tracer, d CloudDeployer, attributes ...attribute.KeyValue) error {
+	phase := func(name string, f func() error) error {
+		_, span := tracer.Start(ctx, name, trace.WithAttributes(attributes...))
+		defer span.End()
+		if err := f(); err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			return err
+		}
+		return nil
+	}
+
 	// Cloud Discovery
-	err := d.CloudDiscovery()
+	err := phase("CloudDiscovery", d.CloudDiscovery)
 	if err != nil {
 		return err
 	}
 
 	// State Discovery
-	d.StateDiscovery()
+	phase("StateDiscovery", func() error {
+		d.StateDiscovery()
+		return nil
+	})
 
 	// CRUD Delete
 	if d.GetState() == v1alpha.ReconcileInitDelete {
-		err = d.Delete()
+		err = phase("Delete", d.Delete)
 		if err != nil {
 			return err
 		}
@@ -39,7 +91,7 @@ func HandleReconcileRequest(d CloudDeployer) error {
 
 	// CRUD Create
 	if d.GetState() == v1alpha.ReconcileInitCreate {
-		err = d.Create()
+		err = phase("Create", d.Create)
 		if err != nil {
 			return err
 		}
@@ -47,7 +99,7 @@ func HandleReconcileRequest(d CloudDeployer) error {
 
 	// CRUD Update
 	if d.GetState() == v1alpha.ReconcileInitUpdate {
-		err = d.Update()
+		err = phase("Update", d.Update)
 		if err != nil {
 			return err
 		}
@@ -60,7 +112,7 @@ func HandleReconcileRequest(d CloudDeployer) error {
 			d.SetState(v1alpha.ReconcileLocked)
 			return nil
 		}
-		err = d.UpgradeNodes()
+		err = phase("UpgradeNodes", d.UpgradeNodes)
 		if err != nil {
 			return err
 		}
@@ -74,7 +126,7 @@ func HandleReconcileRequest(d CloudDeployer) error {
 	// Bootstrap Nodes
 	if d.IsReady() {
 
-		err = d.BootstrapNodes()
+		err = phase("BootstrapNodes", d.BootstrapNodes)
 		if err != nil {
 			return err
 		}
@@ -85,7 +137,7 @@ func HandleReconcileRequest(d CloudDeployer) error {
 				d.SetState(v1alpha.ReconcileLocked)
 				return nil
 			}
-			err = d.UpgradeNodes()
+			err = phase("UpgradeNodes", d.UpgradeNodes)
 			if err != nil {
 				return err
 			}
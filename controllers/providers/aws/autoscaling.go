@@ -19,6 +19,7 @@ import (
 	"strings"
 
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/aws-sdk-go/aws/request"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/autoscaling"
@@ -104,6 +105,67 @@ func (w *AwsWorker) DeleteLifecycleHook(asgName, hookName string) error {
 	return nil
 }
 
+// CompleteLifecycleAction completes the lifecycle action of an instance, actions which were already completed are ignored
+func (w *AwsWorker) CompleteLifecycleAction(asgName, hookName, instanceId, result string) error {
+	_, err := w.AsgClient.CompleteLifecycleAction(&autoscaling.CompleteLifecycleActionInput{
+		AutoScalingGroupName:  aws.String(asgName),
+		LifecycleHookName:     aws.String(hookName),
+		InstanceId:            aws.String(instanceId),
+		LifecycleActionResult: aws.String(result),
+	})
+	if err != nil {
+		if aerr, ok := err.(awserr.Error); ok && common.ContainsEqualFoldSubstring(aerr.Message(), LifecycleActionNotFoundErrorMessage) {
+			return nil
+		}
+		return err
+	}
+	return nil
+}
+
+func (w *AwsWorker) AttachLoadBalancerTargetGroups(asgName string, arns []string) error {
+	_, err := w.AsgClient.AttachLoadBalancerTargetGroups(&autoscaling.AttachLoadBalancerTargetGroupsInput{
+		AutoScalingGroupName: aws.String(asgName),
+		TargetGroupARNs:      aws.StringSlice(arns),
+	})
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (w *AwsWorker) DetachLoadBalancerTargetGroups(asgName string, arns []string) error {
+	_, err := w.AsgClient.DetachLoadBalancerTargetGroups(&autoscaling.DetachLoadBalancerTargetGroupsInput{
+		AutoScalingGroupName: aws.String(asgName),
+		TargetGroupARNs:      aws.StringSlice(arns),
+	})
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (w *AwsWorker) AttachLoadBalancers(asgName string, names []string) error {
+	_, err := w.AsgClient.AttachLoadBalancers(&autoscaling.AttachLoadBalancersInput{
+		AutoScalingGroupName: aws.String(asgName),
+		LoadBalancerNames:    aws.StringSlice(names),
+	})
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (w *AwsWorker) DetachLoadBalancers(asgName string, names []string) error {
+	_, err := w.AsgClient.DetachLoadBalancers(&autoscaling.DetachLoadBalancersInput{
+		AutoScalingGroupName: aws.String(asgName),
+		LoadBalancerNames:    aws.StringSlice(names),
+	})
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
 func (w *AwsWorker) DescribeLifecycleHooks(asgName string) ([]*autoscaling.LifecycleHook, error) {
 	out, err := w.AsgClient.DescribeLifecycleHooks(&autoscaling.DescribeLifecycleHooksInput{
 		AutoScalingGroupName: aws.String(asgName),
@@ -114,6 +176,18 @@ func (w *AwsWorker) DescribeLifecycleHooks(asgName string) ([]*autoscaling.Lifec
 	return out.LifecycleHooks, nil
 }
 
+// DescribeScalingActivities returns the most recent scaling activities of a scaling group, newest first
+func (w *AwsWorker) DescribeScalingActivities(asgName string, maxRecords int64) ([]*autoscaling.Activity, error) {
+	out, err := w.AsgClient.DescribeScalingActivities(&autoscaling.DescribeScalingActivitiesInput{
+		AutoScalingGroupName: aws.String(asgName),
+		MaxRecords:           aws.Int64(maxRecords),
+	})
+	if err != nil {
+		return []*autoscaling.Activity{}, err
+	}
+	return out.Activities, nil
+}
+
 func (w *AwsWorker) CreateLaunchConfig(input *autoscaling.CreateLaunchConfigurationInput) error {
 	_, err := w.AsgClient.CreateLaunchConfiguration(input)
 	if err != nil {
@@ -170,6 +244,29 @@ func (w *AwsWorker) UpdateScalingGroup(input *autoscaling.UpdateAutoScalingGroup
 	return nil
 }
 
+// StartInstanceRefresh starts replacing the instances of a scaling group and returns the ID of the instance refresh
+func (w *AwsWorker) StartInstanceRefresh(name string, preferences *autoscaling.RefreshPreferences) (string, error) {
+	out, err := w.AsgClient.StartInstanceRefresh(&autoscaling.StartInstanceRefreshInput{
+		AutoScalingGroupName: aws.String(name),
+		Preferences:          preferences,
+	})
+	if err != nil {
+		return "", err
+	}
+	return aws.StringValue(out.InstanceRefreshId), nil
+}
+
+// DescribeInstanceRefreshes returns the instance refreshes of a scaling group, newest first
+func (w *AwsWorker) DescribeInstanceRefreshes(name string) ([]*autoscaling.InstanceRefresh, error) {
+	out, err := w.AsgClient.DescribeInstanceRefreshes(&autoscaling.DescribeInstanceRefreshesInput{
+		AutoScalingGroupName: aws.String(name),
+	})
+	if err != nil {
+		return []*autoscaling.InstanceRefresh{}, err
+	}
+	return out.InstanceRefreshes, nil
+}
+
 func (w *AwsWorker) DeleteScalingGroup(name string) error {
 	input := &autoscaling.DeleteAutoScalingGroupInput{
 		AutoScalingGroupName: aws.String(name),
@@ -231,6 +328,27 @@ func (w *AwsWorker) DescribeAutoscalingGroups() ([]*autoscaling.Group, error) {
 	return scalingGroups, nil
 }
 
+// DescribeAutoscalingGroupsByTag returns the scaling groups which have a tag matching key and value, groups are
+// filtered while paginating to avoid keeping every scaling group in the account in memory
+func (w *AwsWorker) DescribeAutoscalingGroupsByTag(key, value string) ([]*autoscaling.Group, error) {
+	scalingGroups := []*autoscaling.Group{}
+	err := w.AsgClient.DescribeAutoScalingGroupsPages(&autoscaling.DescribeAutoScalingGroupsInput{}, func(page *autoscaling.DescribeAutoScalingGroupsOutput, lastPage bool) bool {
+		for _, group := range page.AutoScalingGroups {
+			for _, tag := range group.Tags {
+				if aws.StringValue(tag.Key) == key && strings.EqualFold(aws.StringValue(tag.Value), value) {
+					scalingGroups = append(scalingGroups, group)
+					break
+				}
+			}
+		}
+		return page.NextToken != nil
+	})
+	if err != nil {
+		return scalingGroups, err
+	}
+	return scalingGroups, nil
+}
+
 func (w *AwsWorker) DescribeAutoscalingLaunchConfigs() ([]*autoscaling.LaunchConfiguration, error) {
 	launchConfigurations := []*autoscaling.LaunchConfiguration{}
 	err := w.AsgClient.DescribeLaunchConfigurationsPages(&autoscaling.DescribeLaunchConfigurationsInput{}, func(page *autoscaling.DescribeLaunchConfigurationsOutput, lastPage bool) bool {
@@ -243,13 +361,13 @@ func (w *AwsWorker) DescribeAutoscalingLaunchConfigs() ([]*autoscaling.LaunchCon
 	return launchConfigurations, nil
 }
 
-func (w *AwsWorker) EnableMetrics(asgName string, metrics []string) error {
+func (w *AwsWorker) EnableMetrics(asgName string, metrics []string, granularity string) error {
 	if common.SliceEmpty(metrics) {
 		return nil
 	}
 	_, err := w.AsgClient.EnableMetricsCollection(&autoscaling.EnableMetricsCollectionInput{
 		AutoScalingGroupName: aws.String(asgName),
-		Granularity:          aws.String("1Minute"),
+		Granularity:          aws.String(granularity),
 		Metrics:              aws.StringSlice(metrics),
 	})
 	if err != nil {
@@ -287,3 +405,29 @@ func GetScalingGroupTagsByName(name string, client autoscalingiface.AutoScalingA
 	}
 	return tags, nil
 }
+
+// GetScalingGroupTagsByInstanceId returns the tags of the scaling group an instance belongs to, or no tags if the
+// instance is not part of a scaling group
+func GetScalingGroupTagsByInstanceId(id string, client autoscalingiface.AutoScalingAPI) ([]*autoscaling.TagDescription, error) {
+	tags := []*autoscaling.TagDescription{}
+	instances, err := client.DescribeAutoScalingInstances(&autoscaling.DescribeAutoScalingInstancesInput{
+		InstanceIds: aws.StringSlice([]string{id}),
+	})
+	if err != nil {
+		return tags, err
+	}
+	if len(instances.AutoScalingInstances) == 0 {
+		return tags, nil
+	}
+
+	out, err := client.DescribeAutoScalingGroups(&autoscaling.DescribeAutoScalingGroupsInput{
+		AutoScalingGroupNames: []*string{instances.AutoScalingInstances[0].AutoScalingGroupName},
+	})
+	if err != nil {
+		return tags, err
+	}
+	if len(out.AutoScalingGroups) == 0 {
+		return tags, nil
+	}
+	return out.AutoScalingGroups[0].Tags, nil
+}
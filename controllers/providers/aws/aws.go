@@ -23,14 +23,21 @@ import (
 	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/client"
 	"github.com/aws/aws-sdk-go/aws/ec2metadata"
+	"github.com/aws/aws-sdk-go/aws/request"
 	"github.com/aws/aws-sdk-go/service/autoscaling"
 	"github.com/aws/aws-sdk-go/service/autoscaling/autoscalingiface"
 	"github.com/aws/aws-sdk-go/service/ec2"
 	"github.com/aws/aws-sdk-go/service/ec2/ec2iface"
+	"github.com/aws/aws-sdk-go/service/eks"
 	"github.com/aws/aws-sdk-go/service/eks/eksiface"
+	"github.com/aws/aws-sdk-go/service/iam"
 	"github.com/aws/aws-sdk-go/service/iam/iamiface"
+	"github.com/aws/aws-sdk-go/service/ssm"
 	"github.com/aws/aws-sdk-go/service/ssm/ssmiface"
+	"github.com/keikoproj/instance-manager/controllers/common"
 	"github.com/pkg/errors"
 	ctrl "sigs.k8s.io/controller-runtime"
 )
@@ -52,6 +59,7 @@ const (
 	DescribeClusterTTL                time.Duration = 180 * time.Second
 	DescribeSecurityGroupsTTL         time.Duration = 180 * time.Second
 	DescribeSubnetsTTL                time.Duration = 180 * time.Second
+	DescribeKeyPairsTTL               time.Duration = 180 * time.Second
 	DescribeLaunchTemplatesTTL        time.Duration = 60 * time.Second
 	DescribeLaunchTemplateVersionsTTL time.Duration = 60 * time.Second
 	DescribeInstanceTypesTTL          time.Duration = 24 * time.Hour
@@ -66,9 +74,13 @@ const (
 	LaunchTemplateStrategyLowestPrice       = "lowest-price"
 	LaunchTemplateAllocationStrategy        = "prioritized"
 	LaunchTemplateLatestVersionKey          = "$Latest"
+	LaunchTemplateDefaultVersionKey         = "$Default"
 	IAMPolicyPrefix                         = "arn:aws:iam::aws:policy"
 	LaunchConfigurationNotFoundErrorMessage = "Launch configuration name not found"
-	defaultPolicyArn                        = "arn:aws:iam::aws:policy/AmazonEKSFargatePodExecutionRolePolicy"
+	LifecycleActionNotFoundErrorMessage     = "No active Lifecycle Action found"
+	DefaultFargatePolicyArn                 = "arn:aws:iam::aws:policy/AmazonEKSFargatePodExecutionRolePolicy"
+	MaxRoleNameLength                       = 64
+	MaxInstanceProfileNameLength            = 128
 )
 
 var (
@@ -110,6 +122,10 @@ var (
 	AllowedVolumeTypesWithProvisionedThroughput = []string{"gp3"}
 	LifecycleHookTransitionLaunch               = "autoscaling:EC2_INSTANCE_LAUNCHING"
 	LifecycleHookTransitionTerminate            = "autoscaling:EC2_INSTANCE_TERMINATING"
+
+	// AccessDeniedErrorCodes are returned by AWS APIs when the caller is missing an IAM permission
+	AccessDeniedErrorCodes  = []string{"AccessDenied", "AccessDeniedException", "UnauthorizedOperation", "Client.UnauthorizedOperation"}
+	accessDeniedActionRegex = regexp.MustCompile(`not authorized to perform:\s+([\w-]+:\w+)`)
 )
 
 type AwsWorker struct {
@@ -120,18 +136,105 @@ type AwsWorker struct {
 	SsmClient   ssmiface.SSMAPI
 	Ec2Metadata *ec2metadata.EC2Metadata
 	Parameters  map[string]interface{}
+
+	// InstanceProfilePropagationDelay, WaiterDuration and WaiterRetries override the package defaults when set
+	InstanceProfilePropagationDelay time.Duration
+	WaiterDuration                  time.Duration
+	WaiterRetries                   int
+}
+
+// cacheHandlerName is the name aws-sdk-go gives the unnamed validate handler added by cache.AddCaching
+const cacheHandlerName = "__anonymous"
+
+// isCachedOperation returns true for the operations the cache handler looks up, other operations only flush the cache
+func isCachedOperation(operationName string) bool {
+	return strings.HasPrefix(operationName, "Describe") ||
+		strings.HasPrefix(operationName, "List") ||
+		strings.HasPrefix(operationName, "Get")
+}
+
+// withoutCache returns a copy of a client whose cached operations skip the cache handler of the session, the lookup
+// and its hit metric are never reached while responses still replace the cached ones, other operations still flush
+// the shared cache
+func withoutCache(c *client.Client) *client.Client {
+	var (
+		uncached      = *c
+		validate      = c.Handlers.Copy().Validate
+		withoutLookup = c.Handlers.Copy().Validate
+	)
+	withoutLookup.RemoveByName(cacheHandlerName)
+
+	uncached.Handlers = c.Handlers.Copy()
+	uncached.Handlers.Validate.Clear()
+	uncached.Handlers.Validate.PushBackNamed(request.NamedHandler{
+		Name: "instancemgr.SkipCacheHandler",
+		Fn: func(r *request.Request) {
+			if isCachedOperation(r.Operation.Name) {
+				withoutLookup.Run(r)
+				return
+			}
+			validate.Run(r)
+		},
+	})
+	return &uncached
+}
+
+// WithoutCache returns a copy of the worker whose calls are always sent to AWS, the cache shared with other workers is
+// still refreshed by their responses
+func (w AwsWorker) WithoutCache() AwsWorker {
+	if c, ok := w.AsgClient.(*autoscaling.AutoScaling); ok {
+		w.AsgClient = &autoscaling.AutoScaling{Client: withoutCache(c.Client)}
+	}
+	if c, ok := w.EksClient.(*eks.EKS); ok {
+		w.EksClient = &eks.EKS{Client: withoutCache(c.Client)}
+	}
+	if c, ok := w.IamClient.(*iam.IAM); ok {
+		w.IamClient = &iam.IAM{Client: withoutCache(c.Client)}
+	}
+	if c, ok := w.Ec2Client.(*ec2.EC2); ok {
+		w.Ec2Client = &ec2.EC2{Client: withoutCache(c.Client)}
+	}
+	if c, ok := w.SsmClient.(*ssm.SSM); ok {
+		w.SsmClient = &ssm.SSM{Client: withoutCache(c.Client)}
+	}
+	return w
+}
+
+func (w *AwsWorker) GetInstanceProfilePropagationDelay() time.Duration {
+	if w.InstanceProfilePropagationDelay > 0 {
+		return w.InstanceProfilePropagationDelay
+	}
+	return DefaultInstanceProfilePropagationDelay
+}
+
+func (w *AwsWorker) GetWaiterDuration() time.Duration {
+	if w.WaiterDuration > 0 {
+		return w.WaiterDuration
+	}
+	return DefaultWaiterDuration
+}
+
+func (w *AwsWorker) GetWaiterRetries() int {
+	if w.WaiterRetries > 0 {
+		return w.WaiterRetries
+	}
+	return DefaultWaiterRetries
 }
 
 func (w *AwsWorker) WithRetries(f func() bool) error {
-	var counter int
+	var (
+		counter  int
+		retries  = w.GetWaiterRetries()
+		duration = w.GetWaiterDuration()
+	)
 	for {
-		if counter >= DefaultWaiterRetries {
+		if counter >= retries {
 			break
 		}
 		if f() {
 			return nil
 		}
-		time.Sleep(DefaultWaiterDuration)
+		time.Sleep(duration)
 		counter++
 	}
 	return errors.New("waiter timed out")
@@ -156,6 +259,24 @@ func (w *AwsWorker) compactTags(tags []map[string]string) map[string]string {
 	return compacted
 }
 
+// GetAccessDeniedAction returns true if an error was caused by missing IAM permissions, along with the denied IAM action
+// when the error message names it, e.g. autoscaling:PutWarmPool
+func GetAccessDeniedAction(err error) (string, bool) {
+	awsErr, ok := errors.Cause(err).(awserr.Error)
+	if !ok || !common.ContainsString(AccessDeniedErrorCodes, awsErr.Code()) {
+		return "", false
+	}
+	if match := accessDeniedActionRegex.FindStringSubmatch(awsErr.Message()); len(match) > 1 {
+		return match[1], true
+	}
+	return "", true
+}
+
+// IsThrottlingError returns true if an error was caused by AWS throttling the request rate, e.g. RequestLimitExceeded
+func IsThrottlingError(err error) bool {
+	return request.IsErrorThrottle(errors.Cause(err))
+}
+
 func GetTagValueByKey(tags []*autoscaling.TagDescription, key string) string {
 	for _, tag := range tags {
 		k := aws.StringValue(tag.Key)
@@ -263,6 +384,15 @@ func GetInstanceTypeInfo(instanceTypes []*ec2.InstanceTypeInfo, instanceType str
 	return nil
 }
 
+// IsPreviousGenerationInstanceType returns true if AWS lists the instance type as a previous generation type
+func IsPreviousGenerationInstanceType(instanceTypes []*ec2.InstanceTypeInfo, instanceType string) bool {
+	i := GetInstanceTypeInfo(instanceTypes, instanceType)
+	if i == nil || i.CurrentGeneration == nil {
+		return false
+	}
+	return !aws.BoolValue(i.CurrentGeneration)
+}
+
 func GetInstanceTypeArchitectures(instanceTypes []*ec2.InstanceTypeInfo, instanceType string) []string {
 	i := GetInstanceTypeInfo(instanceTypes, instanceType)
 	if i != nil {
@@ -222,3 +222,54 @@ func (w *AwsWorker) GetLabelsUpdatePayload(existing, new map[string]string) (*ek
 
 	return payload, true
 }
+
+// GetTaintsUpdatePayload returns the taints to add, update or remove, taints are identified by their key and effect.
+// nil desired taints leave the existing taints alone, an empty list removes them
+func (w *AwsWorker) GetTaintsUpdatePayload(existing, new []*eks.Taint) (*eks.UpdateTaintsPayload, bool) {
+	var (
+		removeTaints    = make([]*eks.Taint, 0)
+		addUpdateTaints = make([]*eks.Taint, 0)
+	)
+
+	if new == nil {
+		return &eks.UpdateTaintsPayload{}, false
+	}
+
+	findTaint := func(taints []*eks.Taint, t *eks.Taint) *eks.Taint {
+		for _, taint := range taints {
+			if aws.StringValue(taint.Key) == aws.StringValue(t.Key) && aws.StringValue(taint.Effect) == aws.StringValue(t.Effect) {
+				return taint
+			}
+		}
+		return nil
+	}
+
+	payload := &eks.UpdateTaintsPayload{}
+	for _, t := range new {
+		// handle new taints and taint value updates
+		if e := findTaint(existing, t); e == nil || aws.StringValue(e.Value) != aws.StringValue(t.Value) {
+			addUpdateTaints = append(addUpdateTaints, t)
+		}
+	}
+
+	for _, t := range existing {
+		// handle removals
+		if findTaint(new, t) == nil {
+			removeTaints = append(removeTaints, t)
+		}
+	}
+
+	if len(addUpdateTaints) > 0 {
+		payload.AddOrUpdateTaints = addUpdateTaints
+	}
+
+	if len(removeTaints) > 0 {
+		payload.RemoveTaints = removeTaints
+	}
+
+	if payload.RemoveTaints == nil && payload.AddOrUpdateTaints == nil {
+		return payload, false
+	}
+
+	return payload, true
+}
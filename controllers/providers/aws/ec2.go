@@ -16,6 +16,7 @@ limitations under the License.
 package aws
 
 import (
+	"fmt"
 	"strings"
 
 	"github.com/aws/aws-sdk-go/aws"
@@ -26,6 +27,7 @@ import (
 	"github.com/aws/aws-sdk-go/service/ec2/ec2iface"
 	"github.com/keikoproj/aws-sdk-go-cache/cache"
 	"github.com/keikoproj/instance-manager/controllers/common"
+	"github.com/pkg/errors"
 )
 
 // GetAwsEc2Client returns an EC2 client
@@ -40,6 +42,7 @@ func GetAwsEc2Client(region string, cacheCfg *cache.Config, maxRetries int, coll
 	cache.AddCaching(sess, cacheCfg)
 	cacheCfg.SetCacheTTL("ec2", "DescribeSecurityGroups", DescribeSecurityGroupsTTL)
 	cacheCfg.SetCacheTTL("ec2", "DescribeSubnets", DescribeSubnetsTTL)
+	cacheCfg.SetCacheTTL("ec2", "DescribeKeyPairs", DescribeKeyPairsTTL)
 	cacheCfg.SetCacheTTL("ec2", "DescribeInstanceTypes", DescribeInstanceTypesTTL)
 	cacheCfg.SetExcludeFlushing("ec2", "DescribeInstanceTypes", true)
 	cacheCfg.SetCacheTTL("ec2", "DescribeInstanceTypeOfferings", DescribeInstanceTypeOfferingTTL)
@@ -162,6 +165,100 @@ func (w *AwsWorker) DeleteLaunchTemplateVersions(name string, versions []string)
 	return nil
 }
 
+// GetNewestImageByTag returns the ID of the most recently created available AMI owned by the account which has the given
+// tag, or an empty string if no AMI matches
+func (w *AwsWorker) GetNewestImageByTag(key, value string) (string, error) {
+	out, err := w.Ec2Client.DescribeImages(&ec2.DescribeImagesInput{
+		Owners: aws.StringSlice([]string{"self"}),
+		Filters: []*ec2.Filter{
+			{
+				Name:   aws.String(fmt.Sprintf("tag:%v", key)),
+				Values: aws.StringSlice([]string{value}),
+			},
+			{
+				Name:   aws.String("state"),
+				Values: aws.StringSlice([]string{ec2.ImageStateAvailable}),
+			},
+		},
+	})
+	if err != nil {
+		return "", err
+	}
+
+	var newest *ec2.Image
+	for _, image := range out.Images {
+		// creation dates are ISO 8601 timestamps in the same format, and compare chronologically as strings
+		if newest == nil || aws.StringValue(image.CreationDate) > aws.StringValue(newest.CreationDate) {
+			newest = image
+		}
+	}
+	if newest == nil {
+		return "", nil
+	}
+	return aws.StringValue(newest.ImageId), nil
+}
+
+func (w *AwsWorker) KeyPairExists(name string) (bool, error) {
+	out, err := w.Ec2Client.DescribeKeyPairs(&ec2.DescribeKeyPairsInput{
+		Filters: []*ec2.Filter{
+			{
+				Name:   aws.String("key-name"),
+				Values: []*string{aws.String(name)},
+			},
+		},
+	})
+	if err != nil {
+		return false, err
+	}
+	return len(out.KeyPairs) > 0, nil
+}
+
+func (w *AwsWorker) DescribeInstancesById(ids []string) ([]*ec2.Instance, error) {
+	instances := []*ec2.Instance{}
+	err := w.Ec2Client.DescribeInstancesPages(
+		&ec2.DescribeInstancesInput{
+			InstanceIds: aws.StringSlice(ids),
+		},
+		func(page *ec2.DescribeInstancesOutput, lastPage bool) bool {
+			for _, reservation := range page.Reservations {
+				instances = append(instances, reservation.Instances...)
+			}
+			return page.NextToken != nil
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+	return instances, nil
+}
+
+func (w *AwsWorker) SetSourceDestCheck(id string, enabled bool) error {
+	_, err := w.Ec2Client.ModifyInstanceAttribute(&ec2.ModifyInstanceAttributeInput{
+		InstanceId: aws.String(id),
+		SourceDestCheck: &ec2.AttributeBooleanValue{
+			Value: aws.Bool(enabled),
+		},
+	})
+	return err
+}
+
+func (w *AwsWorker) DescribeSubnetsById(ids []string) ([]*ec2.Subnet, error) {
+	subnets := []*ec2.Subnet{}
+	err := w.Ec2Client.DescribeSubnetsPages(
+		&ec2.DescribeSubnetsInput{
+			SubnetIds: aws.StringSlice(ids),
+		},
+		func(page *ec2.DescribeSubnetsOutput, lastPage bool) bool {
+			subnets = append(subnets, page.Subnets...)
+			return page.NextToken != nil
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+	return subnets, nil
+}
+
 func (w *AwsWorker) SubnetByName(name, vpc string) (*ec2.Subnet, error) {
 	subnets := []*ec2.Subnet{}
 	filteredSubnets := []*ec2.Subnet{}
@@ -202,6 +299,50 @@ func (w *AwsWorker) SubnetByName(name, vpc string) (*ec2.Subnet, error) {
 	return filteredSubnets[0], nil
 }
 
+func (w *AwsWorker) SecurityGroupByTag(key, value, vpc string) (*ec2.SecurityGroup, error) {
+	groups := []*ec2.SecurityGroup{}
+	filteredGroups := []*ec2.SecurityGroup{}
+	err := w.Ec2Client.DescribeSecurityGroupsPages(
+		&ec2.DescribeSecurityGroupsInput{
+			Filters: []*ec2.Filter{
+				{
+					Name:   aws.String("vpc-id"),
+					Values: []*string{aws.String(vpc)},
+				},
+				{
+					Name:   aws.String(fmt.Sprintf("tag:%v", key)),
+					Values: []*string{aws.String(value)},
+				},
+			},
+		},
+		func(page *ec2.DescribeSecurityGroupsOutput, lastPage bool) bool {
+			groups = append(groups, page.SecurityGroups...)
+			return page.NextToken != nil
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, g := range groups {
+		for _, tag := range g.Tags {
+			if aws.StringValue(tag.Key) == key && aws.StringValue(tag.Value) == value {
+				filteredGroups = append(filteredGroups, g)
+				break
+			}
+		}
+	}
+
+	switch len(filteredGroups) {
+	case 0:
+		return nil, errors.Errorf("no security group found with tag %v=%v", key, value)
+	case 1:
+		return filteredGroups[0], nil
+	default:
+		return nil, errors.Errorf("found %v security groups with tag %v=%v, expected exactly one", len(filteredGroups), key, value)
+	}
+}
+
 func (w *AwsWorker) SecurityGroupByName(name, vpc string) (*ec2.SecurityGroup, error) {
 	groups := []*ec2.SecurityGroup{}
 	filteredGroups := []*ec2.SecurityGroup{}
@@ -108,13 +108,19 @@ func (w *AwsWorker) DeleteManagedNodeGroup() error {
 	return nil
 }
 
-func (w *AwsWorker) UpdateManagedNodeGroup(nodeGroup *eks.Nodegroup, desired int64, nodeLabels map[string]string) error {
+// UpdateManagedNodeGroup updates the scaling config, labels, taints and update config of a managed node group in place,
+// without replacing its nodes
+func (w *AwsWorker) UpdateManagedNodeGroup(nodeGroup *eks.Nodegroup, desired int64, nodeLabels map[string]string, nodeTaints []*eks.Taint) error {
 	input := &eks.UpdateNodegroupConfigInput{}
 
 	if labels, ok := w.GetLabelsUpdatePayload(aws.StringValueMap(nodeGroup.Labels), nodeLabels); ok {
 		input.Labels = labels
 	}
 
+	if taints, ok := w.GetTaintsUpdatePayload(nodeGroup.Taints, nodeTaints); ok {
+		input.Taints = taints
+	}
+
 	input.ClusterName = aws.String(w.Parameters["ClusterName"].(string))
 	input.NodegroupName = aws.String(w.Parameters["NodegroupName"].(string))
 	input.ScalingConfig = &eks.NodegroupScalingConfig{
@@ -123,6 +129,10 @@ func (w *AwsWorker) UpdateManagedNodeGroup(nodeGroup *eks.Nodegroup, desired int
 		DesiredSize: aws.Int64(desired),
 	}
 
+	if updateConfig := w.Parameters["UpdateConfig"].(*eks.NodegroupUpdateConfig); IsNodeGroupUpdateConfigDrifted(nodeGroup.UpdateConfig, updateConfig) {
+		input.UpdateConfig = updateConfig
+	}
+
 	_, err := w.EksClient.UpdateNodegroupConfig(input)
 	if err != nil {
 		return err
@@ -149,9 +159,11 @@ func (w *AwsWorker) CreateManagedNodeGroup() error {
 			MinSize:     aws.Int64(w.Parameters["MinSize"].(int64)),
 			DesiredSize: aws.Int64(w.Parameters["MinSize"].(int64)),
 		},
-		Subnets: aws.StringSlice(w.Parameters["Subnets"].([]string)),
-		Tags:    aws.StringMap(w.compactTags(w.Parameters["Tags"].([]map[string]string))),
-		Version: aws.String(w.Parameters["Version"].(string)),
+		Subnets:      aws.StringSlice(w.Parameters["Subnets"].([]string)),
+		Tags:         aws.StringMap(w.compactTags(w.Parameters["Tags"].([]map[string]string))),
+		Taints:       w.Parameters["Taints"].([]*eks.Taint),
+		UpdateConfig: w.Parameters["UpdateConfig"].(*eks.NodegroupUpdateConfig),
+		Version:      aws.String(w.Parameters["Version"].(string)),
 	}
 
 	_, err := w.EksClient.CreateNodegroup(input)
@@ -161,6 +173,18 @@ func (w *AwsWorker) CreateManagedNodeGroup() error {
 	return nil
 }
 
+// IsNodeGroupUpdateConfigDrifted returns true if a desired update config is set and does not match the current one
+func IsNodeGroupUpdateConfigDrifted(current, desired *eks.NodegroupUpdateConfig) bool {
+	if desired == nil {
+		return false
+	}
+	if current == nil {
+		return true
+	}
+	return aws.Int64Value(current.MaxUnavailable) != aws.Int64Value(desired.MaxUnavailable) ||
+		aws.Int64Value(current.MaxUnavailablePercentage) != aws.Int64Value(desired.MaxUnavailablePercentage)
+}
+
 func (w *AwsWorker) DeriveEksVpcID(clusterName string) (string, error) {
 	out, err := w.EksClient.DescribeCluster(&eks.DescribeClusterInput{Name: aws.String(clusterName)})
 	if err != nil {
@@ -1,9 +1,17 @@
 package aws
 
 import (
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/autoscaling"
+	"github.com/aws/aws-sdk-go/service/autoscaling/autoscalingiface"
 	"github.com/aws/aws-sdk-go/service/eks"
+	"github.com/aws/aws-sdk-go/service/iam"
 	"github.com/onsi/gomega"
-	"testing"
+	"github.com/pkg/errors"
 )
 
 func TestClusterDns(t *testing.T) {
@@ -17,3 +25,133 @@ func TestClusterDns(t *testing.T) {
 	g.Expect(ip).To(gomega.Equal("172.16.0.10"))
 
 }
+
+func TestWithRetriesConfiguredWaiter(t *testing.T) {
+	var (
+		g = gomega.NewGomegaWithT(t)
+	)
+
+	awsWorker := AwsWorker{
+		WaiterRetries:                   3,
+		WaiterDuration:                  time.Millisecond * 1,
+		InstanceProfilePropagationDelay: time.Minute * 5,
+	}
+
+	var calls int
+	err := awsWorker.WithRetries(func() bool {
+		calls++
+		return false
+	})
+	g.Expect(err).To(gomega.HaveOccurred())
+	g.Expect(calls).To(gomega.Equal(3))
+
+	calls = 0
+	err = awsWorker.WithRetries(func() bool {
+		calls++
+		return calls == 2
+	})
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(calls).To(gomega.Equal(2))
+
+	// a profile older than the default delay is still propagating under the configured delay
+	profile := &iam.InstanceProfile{CreateDate: aws.Time(time.Now().Add(-time.Minute))}
+	g.Expect(awsWorker.IsInstanceProfilePropagated(profile)).To(gomega.BeFalse())
+	g.Expect((&AwsWorker{}).IsInstanceProfilePropagated(profile)).To(gomega.BeTrue())
+}
+
+func TestGetAccessDeniedAction(t *testing.T) {
+	var (
+		g = gomega.NewGomegaWithT(t)
+	)
+
+	err := errors.Wrap(awserr.New("AccessDenied", "User: arn:aws:sts::123456789012:assumed-role/instance-manager is not authorized to perform: iam:CreateRole on resource: role/my-role", nil), "failed to create role")
+	action, denied := GetAccessDeniedAction(err)
+	g.Expect(denied).To(gomega.BeTrue())
+	g.Expect(action).To(gomega.Equal("iam:CreateRole"))
+
+	// ec2 does not name the denied action
+	action, denied = GetAccessDeniedAction(awserr.New("UnauthorizedOperation", "You are not authorized to perform this operation.", nil))
+	g.Expect(denied).To(gomega.BeTrue())
+	g.Expect(action).To(gomega.BeEmpty())
+
+	_, denied = GetAccessDeniedAction(awserr.New("ValidationError", "not authorized to perform: iam:CreateRole", nil))
+	g.Expect(denied).To(gomega.BeFalse())
+	_, denied = GetAccessDeniedAction(nil)
+	g.Expect(denied).To(gomega.BeFalse())
+}
+
+func TestIsThrottlingError(t *testing.T) {
+	var (
+		g = gomega.NewGomegaWithT(t)
+	)
+
+	g.Expect(IsThrottlingError(errors.Wrap(awserr.New("RequestLimitExceeded", "Request limit exceeded.", nil), "failed to describe subnets"))).To(gomega.BeTrue())
+	g.Expect(IsThrottlingError(awserr.New("Throttling", "Rate exceeded", nil))).To(gomega.BeTrue())
+	g.Expect(IsThrottlingError(awserr.New("ValidationError", "invalid", nil))).To(gomega.BeFalse())
+	g.Expect(IsThrottlingError(errors.New("some failure"))).To(gomega.BeFalse())
+	g.Expect(IsThrottlingError(nil)).To(gomega.BeFalse())
+}
+
+type mockAutoScalingClient struct {
+	autoscalingiface.AutoScalingAPI
+	instances                  []*autoscaling.InstanceDetails
+	groups                     []*autoscaling.Group
+	describeGroupsInput        *autoscaling.DescribeAutoScalingGroupsInput
+	describeGroupsCallCount    int
+	describeInstancesCallCount int
+}
+
+func (m *mockAutoScalingClient) DescribeAutoScalingInstances(input *autoscaling.DescribeAutoScalingInstancesInput) (*autoscaling.DescribeAutoScalingInstancesOutput, error) {
+	m.describeInstancesCallCount++
+	out := &autoscaling.DescribeAutoScalingInstancesOutput{}
+	for _, instance := range m.instances {
+		if aws.StringValue(instance.InstanceId) == aws.StringValue(input.InstanceIds[0]) {
+			out.AutoScalingInstances = append(out.AutoScalingInstances, instance)
+		}
+	}
+	return out, nil
+}
+
+func (m *mockAutoScalingClient) DescribeAutoScalingGroups(input *autoscaling.DescribeAutoScalingGroupsInput) (*autoscaling.DescribeAutoScalingGroupsOutput, error) {
+	m.describeGroupsCallCount++
+	m.describeGroupsInput = input
+	out := &autoscaling.DescribeAutoScalingGroupsOutput{}
+	for _, group := range m.groups {
+		if aws.StringValue(group.AutoScalingGroupName) == aws.StringValue(input.AutoScalingGroupNames[0]) {
+			out.AutoScalingGroups = append(out.AutoScalingGroups, group)
+		}
+	}
+	return out, nil
+}
+
+func TestGetScalingGroupTagsByInstanceId(t *testing.T) {
+	var (
+		g    = gomega.NewGomegaWithT(t)
+		tags = []*autoscaling.TagDescription{
+			{Key: aws.String("instancegroups.keikoproj.io/InstanceGroup"), Value: aws.String("my-group")},
+		}
+	)
+
+	client := &mockAutoScalingClient{
+		instances: []*autoscaling.InstanceDetails{
+			{InstanceId: aws.String("i-1234"), AutoScalingGroupName: aws.String("my-asg")},
+		},
+		groups: []*autoscaling.Group{
+			{AutoScalingGroupName: aws.String("other-asg")},
+			{AutoScalingGroupName: aws.String("my-asg"), Tags: tags},
+		},
+	}
+
+	// only the scaling group of the instance is described
+	result, err := GetScalingGroupTagsByInstanceId("i-1234", client)
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(result).To(gomega.Equal(tags))
+	g.Expect(aws.StringValueSlice(client.describeGroupsInput.AutoScalingGroupNames)).To(gomega.Equal([]string{"my-asg"}))
+
+	// an instance which is not part of a scaling group has no tags
+	result, err = GetScalingGroupTagsByInstanceId("i-2345", client)
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(result).To(gomega.BeEmpty())
+	g.Expect(client.describeInstancesCallCount).To(gomega.Equal(2))
+	g.Expect(client.describeGroupsCallCount).To(gomega.Equal(1))
+}
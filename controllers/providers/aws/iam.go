@@ -76,7 +76,7 @@ func (w *AwsWorker) RoleExist(name string) (*iam.Role, bool) {
 	return out, true
 }
 
-func (w *AwsWorker) DeleteScalingGroupRole(name string, managedPolicies []string) error {
+func (w *AwsWorker) DeleteScalingGroupRole(name, profileName string, managedPolicies []string) error {
 	for _, policy := range managedPolicies {
 		_, err := w.IamClient.DetachRolePolicy(&iam.DetachRolePolicyInput{
 			RoleName:  aws.String(name),
@@ -87,31 +87,12 @@ func (w *AwsWorker) DeleteScalingGroupRole(name string, managedPolicies []string
 		}
 	}
 
-	_, err := w.IamClient.RemoveRoleFromInstanceProfile(&iam.RemoveRoleFromInstanceProfileInput{
-		InstanceProfileName: aws.String(name),
-		RoleName:            aws.String(name),
-	})
-	if err != nil {
-		if aerr, ok := err.(awserr.Error); ok {
-			if aerr.Code() != iam.ErrCodeNoSuchEntityException {
-				return err
-			}
-		}
-	}
-
-	_, err = w.IamClient.DeleteInstanceProfile(&iam.DeleteInstanceProfileInput{
-		InstanceProfileName: aws.String(name),
-	})
-	if err != nil {
-		if aerr, ok := err.(awserr.Error); ok {
-			if aerr.Code() != iam.ErrCodeNoSuchEntityException {
-				return err
-			}
-		}
+	if err := w.DeleteInstanceProfile(profileName, name); err != nil {
+		return err
 	}
 
 	// must wait until all policies are detached
-	err = w.WithRetries(func() bool {
+	err := w.WithRetries(func() bool {
 		_, err := w.IamClient.DeleteRole(&iam.DeleteRoleInput{
 			RoleName: aws.String(name),
 		})
@@ -132,6 +113,34 @@ func (w *AwsWorker) DeleteScalingGroupRole(name string, managedPolicies []string
 	return nil
 }
 
+// DeleteInstanceProfile removes a role from an instance-profile and deletes the instance-profile, an instance-profile
+// which does not exist is ignored
+func (w *AwsWorker) DeleteInstanceProfile(profileName, roleName string) error {
+	_, err := w.IamClient.RemoveRoleFromInstanceProfile(&iam.RemoveRoleFromInstanceProfileInput{
+		InstanceProfileName: aws.String(profileName),
+		RoleName:            aws.String(roleName),
+	})
+	if err != nil {
+		if aerr, ok := err.(awserr.Error); ok {
+			if aerr.Code() != iam.ErrCodeNoSuchEntityException {
+				return err
+			}
+		}
+	}
+
+	_, err = w.IamClient.DeleteInstanceProfile(&iam.DeleteInstanceProfileInput{
+		InstanceProfileName: aws.String(profileName),
+	})
+	if err != nil {
+		if aerr, ok := err.(awserr.Error); ok {
+			if aerr.Code() != iam.ErrCodeNoSuchEntityException {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
 func (w *AwsWorker) AttachManagedPolicies(name string, managedPolicies []string) error {
 	for _, policy := range managedPolicies {
 		_, err := w.IamClient.AttachRolePolicy(&iam.AttachRolePolicyInput{
@@ -176,7 +185,9 @@ func (w *AwsWorker) ListRolePolicies(name string) ([]*iam.AttachedPolicy, error)
 	return policies, nil
 }
 
-func (w *AwsWorker) CreateScalingGroupRole(name string) (*iam.Role, *iam.InstanceProfile, error) {
+// CreateScalingGroupRole creates the role and instance-profile of a scaling group if they do not exist, tags are only
+// applied when the resources are created
+func (w *AwsWorker) CreateScalingGroupRole(name, profileName string, tags []*iam.Tag) (*iam.Role, *iam.InstanceProfile, error) {
 	var (
 		assumeRolePolicyDocument = `{
 			"Version": "2012-10-17",
@@ -195,6 +206,7 @@ func (w *AwsWorker) CreateScalingGroupRole(name string) (*iam.Role, *iam.Instanc
 		out, err := w.IamClient.CreateRole(&iam.CreateRoleInput{
 			RoleName:                 aws.String(name),
 			AssumeRolePolicyDocument: aws.String(assumeRolePolicyDocument),
+			Tags:                     tags,
 		})
 		if err != nil {
 			return createdRole, createdProfile, errors.Wrap(err, "failed to create role")
@@ -204,18 +216,23 @@ func (w *AwsWorker) CreateScalingGroupRole(name string) (*iam.Role, *iam.Instanc
 		createdRole = role
 	}
 
-	if instanceProfile, ok := w.InstanceProfileExist(name); !ok {
+	if instanceProfile, ok := w.InstanceProfileExist(profileName); !ok {
 		out, err := w.IamClient.CreateInstanceProfile(&iam.CreateInstanceProfileInput{
-			InstanceProfileName: aws.String(name),
+			InstanceProfileName: aws.String(profileName),
+			Tags:                tags,
 		})
 		if err != nil {
 			return createdRole, createdProfile, errors.Wrap(err, "failed to create instance-profile")
 		}
 		createdProfile = out.InstanceProfile
-		time.Sleep(DefaultInstanceProfilePropagationDelay)
+	} else {
+		createdProfile = instanceProfile
+	}
 
-		_, err = w.IamClient.AddRoleToInstanceProfile(&iam.AddRoleToInstanceProfileInput{
-			InstanceProfileName: aws.String(name),
+	// attach the role if it is missing, e.g. a previous attempt to attach has failed
+	if createdProfile == nil || len(createdProfile.Roles) == 0 {
+		_, err := w.IamClient.AddRoleToInstanceProfile(&iam.AddRoleToInstanceProfileInput{
+			InstanceProfileName: aws.String(profileName),
 			RoleName:            aws.String(name),
 		})
 		if err != nil {
@@ -225,18 +242,34 @@ func (w *AwsWorker) CreateScalingGroupRole(name string) (*iam.Role, *iam.Instanc
 				}
 			}
 		}
-
-	} else {
-		createdProfile = instanceProfile
 	}
 
 	return createdRole, createdProfile, nil
 }
 
+// IsInstanceProfilePropagated returns true if enough time has passed since the instance-profile was created for it to be usable
+func (w *AwsWorker) IsInstanceProfilePropagated(profile *iam.InstanceProfile) bool {
+	if profile == nil {
+		return true
+	}
+	return w.GetPropagationDelayRemaining(profile.CreateDate) == 0
+}
+
+// GetPropagationDelayRemaining returns how long an IAM resource created at createDate still needs before it is usable
+func (w *AwsWorker) GetPropagationDelayRemaining(createDate *time.Time) time.Duration {
+	if createDate == nil {
+		return 0
+	}
+	if remaining := w.GetInstanceProfilePropagationDelay() - time.Since(aws.TimeValue(createDate)); remaining > 0 {
+		return remaining
+	}
+	return 0
+}
+
 func (w *AwsWorker) DetachDefaultPolicyFromDefaultRole() error {
 	var roleName = w.Parameters["DefaultRoleName"].(string)
 	rolePolicy := &iam.DetachRolePolicyInput{
-		PolicyArn: aws.String(defaultPolicyArn),
+		PolicyArn: aws.String(DefaultFargatePolicyArn),
 		RoleName:  aws.String(roleName),
 	}
 	_, err := w.IamClient.DetachRolePolicy(rolePolicy)
@@ -282,12 +315,9 @@ func (w *AwsWorker) CreateDefaultFargateRole() error {
 func (w *AwsWorker) AttachDefaultPolicyToDefaultRole() error {
 	var roleName = w.Parameters["DefaultRoleName"].(string)
 	rolePolicy := &iam.AttachRolePolicyInput{
-		PolicyArn: aws.String(defaultPolicyArn),
+		PolicyArn: aws.String(DefaultFargatePolicyArn),
 		RoleName:  aws.String(roleName),
 	}
 	_, err := w.IamClient.AttachRolePolicy(rolePolicy)
-	if err == nil {
-		time.Sleep(DefaultInstanceProfilePropagationDelay)
-	}
 	return err
 }
@@ -17,6 +17,8 @@ type architectureMap map[string]string
 const (
 	EksOptimisedAmiPath           = "/aws/service/eks/optimized-ami/%s/amazon-linux-2/%s/image_id"
 	EksOptimisedAmazonLinux2Arm64 = "/aws/service/eks/optimized-ami/%s/amazon-linux-2-arm64/%s/image_id"
+	EksOptimisedAmazonLinux2023   = "/aws/service/eks/optimized-ami/%s/amazon-linux-2023/x86_64/standard/%s/image_id"
+	EksOptimisedAL2023Arm64       = "/aws/service/eks/optimized-ami/%s/amazon-linux-2023/arm64/standard/%s/image_id"
 	EksOptimisedBottlerocket      = "/aws/service/bottlerocket/aws-k8s-%s/x86_64/%s/image_id"
 	EksOptimisedBottlerocketArm64 = "/aws/service/bottlerocket/aws-k8s-%s/arm64/%s/image_id"
 	EksOptimisedWindowsCore       = "/aws/service/ami-windows-%s/Windows_Server-2019-English-Core-EKS_Optimized-%s/image_id"
@@ -29,6 +31,10 @@ var (
 			"x86_64": EksOptimisedAmiPath,
 			"arm64":  EksOptimisedAmazonLinux2Arm64,
 		},
+		"al2023": architectureMap{
+			"x86_64": EksOptimisedAmazonLinux2023,
+			"arm64":  EksOptimisedAL2023Arm64,
+		},
 		"bottlerocket": architectureMap{
 			"x86_64": EksOptimisedBottlerocket,
 			"arm64":  EksOptimisedBottlerocketArm64,
@@ -40,6 +46,7 @@ var (
 	LatestIdentifiers = map[string]string{
 		"bottlerocket": "latest",
 		"amazonlinux2": "recommended",
+		"al2023":       "recommended",
 		"windows":      "latest",
 	}
 )
@@ -83,3 +90,35 @@ func (w *AwsWorker) GetEksSsmAmi(OSFamily string, arch string, kubernetesVersion
 	}
 	return aws.StringValue(output.Parameter.Value), nil
 }
+
+// StartAutomationExecution starts an execution of an automation document and returns its ID, the client token makes
+// repeated calls for the same token return the same execution
+func (w *AwsWorker) StartAutomationExecution(document, version string, parameters map[string][]string, clientToken string) (string, error) {
+	input := &ssm.StartAutomationExecutionInput{
+		DocumentName: aws.String(document),
+		ClientToken:  aws.String(clientToken),
+		Parameters:   make(map[string][]*string),
+	}
+	if !common.StringEmpty(version) {
+		input.DocumentVersion = aws.String(version)
+	}
+	for k, v := range parameters {
+		input.Parameters[k] = aws.StringSlice(v)
+	}
+
+	output, err := w.SsmClient.StartAutomationExecution(input)
+	if err != nil {
+		return "", err
+	}
+	return aws.StringValue(output.AutomationExecutionId), nil
+}
+
+func (w *AwsWorker) GetAutomationExecutionStatus(executionId string) (string, error) {
+	output, err := w.SsmClient.GetAutomationExecution(&ssm.GetAutomationExecutionInput{
+		AutomationExecutionId: aws.String(executionId),
+	})
+	if err != nil {
+		return "", err
+	}
+	return aws.StringValue(output.AutomationExecution.AutomationExecutionStatus), nil
+}
@@ -51,6 +51,13 @@ var (
 	NodesReadyEvent                 EventKind = "InstanceGroupNodesReady"
 	NodesNotReadyEvent              EventKind = "InstanceGroupNodesNotReady"
 	InstanceGroupUpgradeFailedEvent EventKind = "InstanceGroupUpgradeFailed"
+	ConfigurationDriftEvent         EventKind = "ConfigurationDrift"
+	EndpointUnreachableEvent        EventKind = "ClusterEndpointUnreachable"
+	ClusterNotActiveEvent           EventKind = "ClusterNotActive"
+	UserDataRenderedEvent           EventKind = "UserDataRendered"
+	InsufficientPermissionsEvent    EventKind = "InsufficientPermissions"
+	ScalingActivityFailedEvent      EventKind = "ScalingActivityFailed"
+	DeprecatedInstanceTypeEvent     EventKind = "DeprecatedInstanceType"
 
 	EventLevels = map[EventKind]string{
 		InstanceGroupCreatedEvent:       EventLevelNormal,
@@ -58,14 +65,28 @@ var (
 		NodesNotReadyEvent:              EventLevelWarning,
 		NodesReadyEvent:                 EventLevelNormal,
 		InstanceGroupUpgradeFailedEvent: EventLevelWarning,
+		ConfigurationDriftEvent:         EventLevelWarning,
+		EndpointUnreachableEvent:        EventLevelWarning,
+		ClusterNotActiveEvent:           EventLevelWarning,
+		UserDataRenderedEvent:           EventLevelNormal,
+		InsufficientPermissionsEvent:    EventLevelWarning,
+		ScalingActivityFailedEvent:      EventLevelWarning,
+		DeprecatedInstanceTypeEvent:     EventLevelWarning,
 	}
 
 	EventMessages = map[EventKind]string{
 		InstanceGroupCreatedEvent:       "instance group has been successfully created",
 		InstanceGroupDeletedEvent:       "instance group has been successfully deleted",
 		InstanceGroupUpgradeFailedEvent: "instance group has failed upgrading",
+		ConfigurationDriftEvent:         "instance group scaling group was modified outside of the controller",
 		NodesNotReadyEvent:              "instance group nodes are not ready",
 		NodesReadyEvent:                 "instance group nodes are ready",
+		EndpointUnreachableEvent:        "instance group subnets cannot reach the cluster's private-only endpoint",
+		ClusterNotActiveEvent:           "instance group reconcile is deferred until the cluster is active",
+		UserDataRenderedEvent:           "instance group userData has been rendered",
+		InsufficientPermissionsEvent:    "instance group reconcile failed, the controller is missing IAM permissions",
+		ScalingActivityFailedEvent:      "instance group scaling group failed a scaling activity",
+		DeprecatedInstanceTypeEvent:     "instance group uses previous generation instance types",
 	}
 )
 
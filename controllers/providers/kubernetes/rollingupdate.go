@@ -16,13 +16,28 @@ limitations under the License.
 package kubernetes
 
 import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/keikoproj/instance-manager/controllers/common"
 	awsprovider "github.com/keikoproj/instance-manager/controllers/providers/aws"
+	"github.com/pkg/errors"
 	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
 	ctrl "sigs.k8s.io/controller-runtime"
 )
 
 const (
 	RollingUpdateStrategyName = "rollingupdate"
+
+	// DrainStartedAnnotationKey records when a node was cordoned to be drained by a rolling update
+	DrainStartedAnnotationKey = "instancemgr.keikoproj.io/drain-started"
+
+	mirrorPodAnnotation = "kubernetes.io/config.mirror"
 )
 
 var (
@@ -31,9 +46,12 @@ var (
 
 type RollingUpdateRequest struct {
 	AwsWorker        awsprovider.AwsWorker
+	Kubernetes       kubernetes.Interface
 	ClusterNodes     *corev1.NodeList
 	ScalingGroupName string
 	MaxUnavailable   int
+	BatchSize        int
+	DrainTimeout     time.Duration
 	DesiredCapacity  int
 	AllInstances     []string
 	UpdateTargets    []string
@@ -45,7 +63,20 @@ func ProcessRollingUpgradeStrategy(req *RollingUpdateRequest) (bool, error) {
 		"scalinggroup", req.ScalingGroupName,
 		"targets", req.UpdateTargets,
 		"maxunavailable", req.MaxUnavailable,
+		"batchsize", req.BatchSize,
 	)
+
+	// nodes which were cordoned for a rotation they are no longer a target of are returned to service
+	abandoned := make([]string, 0)
+	for _, instance := range req.AllInstances {
+		if !common.ContainsString(req.UpdateTargets, instance) {
+			abandoned = append(abandoned, instance)
+		}
+	}
+	if err := UncordonDrainingNodes(req.Kubernetes, req.ClusterNodes, abandoned); err != nil {
+		return false, err
+	}
+
 	if len(req.UpdateTargets) == 0 {
 		log.Info("no updatable instances", "scalinggroup", req.ScalingGroupName)
 		return true, nil
@@ -78,6 +109,29 @@ func ProcessRollingUpgradeStrategy(req *RollingUpdateRequest) (bool, error) {
 		terminateTargets = req.UpdateTargets
 	}
 
+	// when a batch size is set, only cordon and drain a batch of the targets before terminating them
+	if req.BatchSize > 0 {
+		// the batch is persisted on its nodes, a batch which is already draining is completed before starting another
+		if draining := DrainingInstances(req.ClusterNodes, req.UpdateTargets); len(draining) > 0 {
+			terminateTargets = draining
+		}
+		if req.BatchSize < len(terminateTargets) {
+			terminateTargets = terminateTargets[:req.BatchSize]
+		}
+
+		log.Info("draining targets", "scalinggroup", req.ScalingGroupName, "targets", terminateTargets)
+		drained, err := DrainInstances(req.Kubernetes, req.ClusterNodes, terminateTargets, req.DrainTimeout)
+		if err != nil {
+			// drain failures, e.g. evictions blocked by disruption budgets, are retryable
+			log.Info("failed to drain targets", "reason", err.Error(), "scalinggroup", req.ScalingGroupName, "targets", terminateTargets)
+			return false, nil
+		}
+		if !drained {
+			log.Info("waiting for evicted pods to terminate", "scalinggroup", req.ScalingGroupName, "targets", terminateTargets)
+			return false, nil
+		}
+	}
+
 	log.Info("terminating targets", "scalinggroup", req.ScalingGroupName, "targets", terminateTargets)
 	if err := req.AwsWorker.TerminateScalingInstances(terminateTargets); err != nil {
 		// terminate failures are retryable
@@ -86,3 +140,149 @@ func ProcessRollingUpgradeStrategy(req *RollingUpdateRequest) (bool, error) {
 	}
 	return false, nil
 }
+
+// DrainInstances cordons all nodes matching instanceIds before evicting their pods, nodes are cordoned first so that
+// evicted pods are not rescheduled onto another node in the same batch. Returns true once the evicted pods have terminated,
+// or the nodes have been draining for longer than the drain timeout
+func DrainInstances(kube kubernetes.Interface, nodes *corev1.NodeList, instanceIds []string, timeout time.Duration) (bool, error) {
+	targets := make([]corev1.Node, 0)
+	for _, node := range nodes.Items {
+		if common.ContainsString(instanceIds, common.GetLastElementBy(node.Spec.ProviderID, "/")) {
+			targets = append(targets, node)
+		}
+	}
+
+	for _, node := range targets {
+		if err := CordonNode(kube, node); err != nil {
+			return false, errors.Wrapf(err, "failed to cordon node %v", node.GetName())
+		}
+	}
+
+	drained := true
+	for _, node := range targets {
+		// the timeout is checked first, evictions blocked by disruption budgets would otherwise keep the node draining
+		started, _ := time.Parse(time.RFC3339, node.GetAnnotations()[DrainStartedAnnotationKey])
+		if !started.IsZero() && time.Since(started) > timeout {
+			log.Info("drain timed out", "node", node.GetName(), "timeout", timeout)
+			continue
+		}
+
+		remaining, err := DrainNode(kube, node.GetName())
+		if err != nil {
+			return false, errors.Wrapf(err, "failed to drain node %v", node.GetName())
+		}
+		if remaining > 0 {
+			drained = false
+		}
+	}
+	return drained, nil
+}
+
+// DrainingInstances returns the instanceIds whose nodes have been cordoned to be drained by a rolling update
+func DrainingInstances(nodes *corev1.NodeList, instanceIds []string) []string {
+	draining := make([]string, 0)
+	if nodes == nil {
+		return draining
+	}
+	for _, instance := range instanceIds {
+		for _, node := range nodes.Items {
+			if common.GetLastElementBy(node.Spec.ProviderID, "/") == instance && HasAnnotation(node.GetAnnotations(), DrainStartedAnnotationKey) {
+				draining = append(draining, instance)
+				break
+			}
+		}
+	}
+	return draining
+}
+
+// UncordonDrainingNodes marks nodes matching instanceIds which were cordoned by a rolling update as schedulable again
+func UncordonDrainingNodes(kube kubernetes.Interface, nodes *corev1.NodeList, instanceIds []string) error {
+	if nodes == nil {
+		return nil
+	}
+	for _, node := range nodes.Items {
+		if !common.ContainsString(instanceIds, common.GetLastElementBy(node.Spec.ProviderID, "/")) {
+			continue
+		}
+		if err := UncordonNode(kube, node); err != nil {
+			return errors.Wrapf(err, "failed to uncordon node %v", node.GetName())
+		}
+	}
+	return nil
+}
+
+// UncordonNode marks a node which was cordoned by a rolling update as schedulable and removes its drain annotation
+func UncordonNode(kube kubernetes.Interface, node corev1.Node) error {
+	if !HasAnnotation(node.GetAnnotations(), DrainStartedAnnotationKey) {
+		return nil
+	}
+
+	log.Info("uncordoning node, rotation is no longer in progress", "node", node.GetName())
+	patch := []byte(fmt.Sprintf(`{"metadata":{"annotations":{%q:null}},"spec":{"unschedulable":false}}`, DrainStartedAnnotationKey))
+	_, err := kube.CoreV1().Nodes().Patch(context.Background(), node.GetName(), types.StrategicMergePatchType, patch, metav1.PatchOptions{})
+	return err
+}
+
+// CordonNode marks a node as unschedulable and records when it started draining
+func CordonNode(kube kubernetes.Interface, node corev1.Node) error {
+	if node.Spec.Unschedulable && HasAnnotation(node.GetAnnotations(), DrainStartedAnnotationKey) {
+		return nil
+	}
+
+	patch := []byte(`{"spec":{"unschedulable":true}}`)
+	if !HasAnnotation(node.GetAnnotations(), DrainStartedAnnotationKey) {
+		patch = []byte(fmt.Sprintf(`{"metadata":{"annotations":{%q:%q}},"spec":{"unschedulable":true}}`, DrainStartedAnnotationKey, time.Now().UTC().Format(time.RFC3339)))
+	}
+	_, err := kube.CoreV1().Nodes().Patch(context.Background(), node.GetName(), types.StrategicMergePatchType, patch, metav1.PatchOptions{})
+	return err
+}
+
+// DrainNode evicts all pods running on a node, except for daemonset and mirror pods which cannot be rescheduled, and returns
+// the number of evictable pods which have not terminated yet
+func DrainNode(kube kubernetes.Interface, name string) (int, error) {
+	pods, err := kube.CoreV1().Pods(metav1.NamespaceAll).List(context.Background(), metav1.ListOptions{
+		FieldSelector: fmt.Sprintf("spec.nodeName=%v", name),
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	var remaining int
+	for _, pod := range pods.Items {
+		if pod.Spec.NodeName != name || !isEvictablePod(pod) {
+			continue
+		}
+		remaining++
+
+		// pods which are already terminating have been evicted
+		if pod.GetDeletionTimestamp() != nil {
+			continue
+		}
+
+		eviction := &policyv1.Eviction{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      pod.GetName(),
+				Namespace: pod.GetNamespace(),
+			},
+		}
+		if err := kube.CoreV1().Pods(pod.GetNamespace()).EvictV1(context.Background(), eviction); err != nil {
+			return 0, errors.Wrapf(err, "failed to evict pod %v/%v", pod.GetNamespace(), pod.GetName())
+		}
+	}
+	return remaining, nil
+}
+
+func isEvictablePod(pod corev1.Pod) bool {
+	if pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+		return false
+	}
+	if HasAnnotation(pod.GetAnnotations(), mirrorPodAnnotation) {
+		return false
+	}
+	for _, owner := range pod.GetOwnerReferences() {
+		if owner.Kind == "DaemonSet" {
+			return false
+		}
+	}
+	return true
+}
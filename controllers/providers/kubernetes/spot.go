@@ -22,6 +22,9 @@ import (
 	"sort"
 	"time"
 
+	"github.com/keikoproj/instance-manager/controllers/common"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
 )
@@ -29,6 +32,7 @@ import (
 const (
 	SpotRecommendationReason  = "SpotRecommendationGiven"
 	SpotRecommendationVersion = "v1alpha1"
+	SpotInterruptionReason    = "SpotInterruption"
 )
 
 type SpotRecommendation struct {
@@ -40,10 +44,53 @@ type SpotRecommendation struct {
 
 type SpotReccomendationList []SpotRecommendation
 
-func GetSpotRecommendation(kube kubernetes.Interface, identifier string) (SpotRecommendation, error) {
+// SpotRecommendationSource identifies the events published by a spot advisor
+type SpotRecommendationSource struct {
+	Reason             string
+	InvolvedObjectKind string
+}
+
+// DefaultSpotRecommendationSource matches events published by the minion-manager spot advisor
+var DefaultSpotRecommendationSource = SpotRecommendationSource{
+	Reason: SpotRecommendationReason,
+}
+
+func NewSpotRecommendationSource(reason, involvedObjectKind string) (SpotRecommendationSource, error) {
+	if reason == "" {
+		return SpotRecommendationSource{}, errors.New("spot recommendation event reason cannot be empty")
+	}
+	return SpotRecommendationSource{
+		Reason:             reason,
+		InvolvedObjectKind: involvedObjectKind,
+	}, nil
+}
+
+// Matches returns true if an event with the provided reason and involved object kind was published by the source
+func (s SpotRecommendationSource) Matches(reason, involvedObjectKind string) bool {
+	if reason != s.Reason {
+		return false
+	}
+	if s.InvolvedObjectKind != "" && involvedObjectKind != s.InvolvedObjectKind {
+		return false
+	}
+	return true
+}
+
+func (s SpotRecommendationSource) fieldSelector(identifier string) string {
+	selector := fmt.Sprintf("reason=%v,involvedObject.name=%v", s.Reason, identifier)
+	if s.InvolvedObjectKind != "" {
+		selector = fmt.Sprintf("%v,involvedObject.kind=%v", selector, s.InvolvedObjectKind)
+	}
+	return selector
+}
+
+func GetSpotRecommendation(kube kubernetes.Interface, source SpotRecommendationSource, identifier string) (SpotRecommendation, error) {
 	var recommendations SpotReccomendationList
 
-	fieldSelector := fmt.Sprintf("reason=%v,involvedObject.name=%v", SpotRecommendationReason, identifier)
+	if source.Reason == "" {
+		source = DefaultSpotRecommendationSource
+	}
+	fieldSelector := source.fieldSelector(identifier)
 
 	eventList, err := kube.CoreV1().Events("").List(context.Background(), metav1.ListOptions{
 		FieldSelector: fieldSelector,
@@ -54,6 +101,9 @@ func GetSpotRecommendation(kube kubernetes.Interface, identifier string) (SpotRe
 
 	recommendation := &SpotRecommendation{}
 	for _, event := range eventList.Items {
+		if !source.Matches(event.Reason, event.InvolvedObject.Kind) || event.InvolvedObject.Name != identifier {
+			continue
+		}
 		err := json.Unmarshal([]byte(event.Message), recommendation)
 		if err != nil {
 			return SpotRecommendation{}, err
@@ -81,3 +131,39 @@ func (p SpotReccomendationList) Less(i, j int) bool {
 func (p SpotReccomendationList) Swap(i, j int) {
 	p[i], p[j] = p[j], p[i]
 }
+
+// GetSpotInterruptions returns spot interruption warning events for nodes in nodeNames which occurred at or after since
+func GetSpotInterruptions(kube kubernetes.Interface, nodeNames []string, since time.Time) ([]corev1.Event, error) {
+	interruptions := make([]corev1.Event, 0)
+
+	fieldSelector := fmt.Sprintf("reason=%v,involvedObject.kind=Node", SpotInterruptionReason)
+
+	eventList, err := kube.CoreV1().Events("").List(context.Background(), metav1.ListOptions{
+		FieldSelector: fieldSelector,
+	})
+	if err != nil {
+		return interruptions, err
+	}
+
+	for _, event := range eventList.Items {
+		if event.Reason != SpotInterruptionReason || !common.ContainsString(nodeNames, event.InvolvedObject.Name) {
+			continue
+		}
+		if GetEventTime(event).Before(since) {
+			continue
+		}
+		interruptions = append(interruptions, event)
+	}
+	return interruptions, nil
+}
+
+// GetEventTime returns the last time an event was observed
+func GetEventTime(event corev1.Event) time.Time {
+	if !event.LastTimestamp.IsZero() {
+		return event.LastTimestamp.Time
+	}
+	if !event.EventTime.IsZero() {
+		return event.EventTime.Time
+	}
+	return event.CreationTimestamp.Time
+}
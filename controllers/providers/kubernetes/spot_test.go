@@ -0,0 +1,32 @@
+package kubernetes
+
+import (
+	"testing"
+)
+
+func TestNewSpotRecommendationSource(t *testing.T) {
+	if _, err := NewSpotRecommendationSource("", "AutoScalingGroup"); err == nil {
+		t.Fatalf("expected error for empty reason")
+	}
+
+	source, err := NewSpotRecommendationSource("CustomSpotAdvice", "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		reason   string
+		kind     string
+		expected bool
+	}{
+		{name: "custom reason", reason: "CustomSpotAdvice", kind: "AutoScalingGroup", expected: true},
+		{name: "default reason", reason: SpotRecommendationReason, kind: "AutoScalingGroup", expected: false},
+	}
+
+	for _, tc := range tests {
+		if result := source.Matches(tc.reason, tc.kind); result != tc.expected {
+			t.Errorf("%v: expected %t, got %t", tc.name, tc.expected, result)
+		}
+	}
+}
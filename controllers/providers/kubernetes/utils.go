@@ -107,6 +107,28 @@ func IsNodeReady(n corev1.Node) bool {
 	return false
 }
 
+// IsDaemonSetPodsRunning returns true if every daemonset in daemonSets has a running pod scheduled on nodeName
+func IsDaemonSetPodsRunning(pods *corev1.PodList, nodeName string, daemonSets []string) bool {
+	running := make([]string, 0)
+	for _, pod := range pods.Items {
+		if pod.Spec.NodeName != nodeName || pod.Status.Phase != corev1.PodRunning {
+			continue
+		}
+		for _, owner := range pod.GetOwnerReferences() {
+			if owner.Kind == "DaemonSet" && common.ContainsString(daemonSets, owner.Name) {
+				running = append(running, owner.Name)
+			}
+		}
+	}
+
+	for _, name := range daemonSets {
+		if !common.ContainsString(running, name) {
+			return false
+		}
+	}
+	return true
+}
+
 func AddAnnotation(u *unstructured.Unstructured, key, value string) {
 	annotations := u.GetAnnotations()
 	if annotations == nil {
@@ -299,6 +321,14 @@ func (s *statusPatch) Data(obj client.Object) ([]byte, error) {
 	return jsonpatch.CreateMergePatch(originalJSON, modifiedJSON)
 }
 
+// PatchBase returns the instance group a status patch was created from
+func PatchBase(patch client.Patch) (v1alpha1.InstanceGroup, bool) {
+	if p, ok := patch.(*statusPatch); ok {
+		return p.from, true
+	}
+	return v1alpha1.InstanceGroup{}, false
+}
+
 func MergePatch(obj v1alpha1.InstanceGroup) client.Patch {
 	obj.Spec = v1alpha1.InstanceGroupSpec{}
 	return &statusPatch{obj}
@@ -17,6 +17,7 @@ package provisioners
 
 import (
 	"fmt"
+	"regexp"
 	"strings"
 
 	"k8s.io/apimachinery/pkg/labels"
@@ -37,6 +38,9 @@ var (
 	EKSLifecycleHooksPath = fmt.Sprintf("%v.lifecycleHooks", EKSConfigurationPath)
 	EKSUserDataPath       = fmt.Sprintf("%v.userData", EKSConfigurationPath)
 
+	// BoundaryPathRegex matches dotted field paths rooted at spec, e.g. spec.eks.configuration.tags
+	BoundaryPathRegex = regexp.MustCompile(`^spec(\.[a-zA-Z0-9_-]+)+$`)
+
 	// MergeSchema defines the key to merge by
 	MergeSchema = map[string]string{
 		EKSTagsPath:           "key",
@@ -75,6 +79,9 @@ func NewProvisionerConfiguration(config *corev1.ConfigMap, instanceGroup *v1alph
 	if err := c.Unmarshal(config); err != nil {
 		return c, errors.Wrap(err, "failed to unmarshal configuration")
 	}
+	if err := c.Boundaries.Validate(); err != nil {
+		return c, errors.Wrap(err, "failed to validate boundaries")
+	}
 	instanceGroup.DeepCopyInto(c.InstanceGroup)
 	return c, nil
 }
@@ -90,6 +97,32 @@ type ResourceFieldBoundary struct {
 	Shared     SharedBoundaries `yaml:"shared,omitempty"`
 }
 
+// Validate returns an error if a field path is malformed or appears in more than one boundary category
+func (b *ResourceFieldBoundary) Validate() error {
+	var (
+		categories = map[string][]string{
+			"restricted":           b.Restricted,
+			"shared.mergeOverride": b.Shared.MergeOverride,
+			"shared.merge":         b.Shared.Merge,
+			"shared.replace":       b.Shared.Replace,
+		}
+		seen = make(map[string]string)
+	)
+
+	for _, category := range []string{"restricted", "shared.mergeOverride", "shared.merge", "shared.replace"} {
+		for _, path := range categories[category] {
+			if !BoundaryPathRegex.MatchString(path) {
+				return errors.Errorf("boundary path '%v' in '%v' must be a dotted path rooted at 'spec'", path, category)
+			}
+			if existing, ok := seen[path]; ok && existing != category {
+				return errors.Errorf("boundary path '%v' cannot be in both '%v' and '%v'", path, existing, category)
+			}
+			seen[path] = category
+		}
+	}
+	return nil
+}
+
 type Conditional struct {
 	AnnotationSelector string                 `yaml:"annotationSelector,omitempty"`
 	Defaults           map[string]interface{} `yaml:"defaults,omitempty"`
@@ -162,6 +195,35 @@ func (c *ProvisionerConfiguration) SetDefaults() error {
 	return nil
 }
 
+// GetDefaultVolumes returns the volumes configured as defaults, the last matching conditional that sets volumes takes
+// precedence over the defaults
+func (c *ProvisionerConfiguration) GetDefaultVolumes() ([]v1alpha1.NodeVolume, error) {
+	var volumes = common.FieldValue(EKSVolumesPath, c.Defaults)
+
+	applicableConditionals, err := getMatchingConditionals(c.InstanceGroup, c.Conditionals)
+	if err != nil {
+		return nil, err
+	}
+	for _, conditional := range applicableConditionals {
+		if conditionalValue := common.FieldValue(EKSVolumesPath, conditional.Defaults); conditionalValue != nil {
+			volumes = conditionalValue
+		}
+	}
+	if volumes == nil {
+		return nil, nil
+	}
+
+	raw, err := yaml.Marshal(volumes)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshal default volumes")
+	}
+	var defaultVolumes []v1alpha1.NodeVolume
+	if err := yaml.Unmarshal(raw, &defaultVolumes); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal default volumes")
+	}
+	return defaultVolumes, nil
+}
+
 func (c *ProvisionerConfiguration) setRestrictedFields(unstructuredInstanceGroup map[string]interface{}) error {
 	// apply restricted paths to instance group
 	var applicableConditionals, err = getMatchingConditionals(c.InstanceGroup, c.Conditionals)
@@ -208,6 +270,8 @@ func isConflict(defaultVal, resourceVal interface{}) bool {
 	return false
 }
 
+// getMatchingConditionals returns the conditionals whose annotationSelector matches the instance group's annotations,
+// requirements on a missing annotation follow label selector semantics, e.g. 'notin' and '!key' match while 'in' and 'key' do not
 func getMatchingConditionals(ig *v1alpha1.InstanceGroup, conditionals []Conditional) ([]Conditional, error) {
 	var applicableConditionals = make([]Conditional, 0)
 	var annotationLabels = &SelectableAnnotations{Annotations: ig.Annotations}
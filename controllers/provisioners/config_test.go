@@ -172,6 +172,41 @@ spec:
 	g.Expect(c.InstanceGroup.Spec.EKSSpec.EKSConfiguration.KeyPairName).To(gomega.Equal("TestKeyPair"))
 }
 
+func TestSetDefaultsRestrictedContainerRuntime(t *testing.T) {
+	var (
+		g = gomega.NewGomegaWithT(t)
+	)
+
+	// A restricted default can pin the container runtime cluster-wide
+
+	mockBoundaries := `
+    restricted:
+    - spec.eks.configuration.bootstrapOptions.containerRuntime`
+
+	mockDefaults := `
+spec:
+  eks:
+    configuration:
+      bootstrapOptions:
+        containerRuntime: containerd`
+
+	cm := MockConfigMap(MockConfigData("boundaries", mockBoundaries, "defaults", mockDefaults))
+	cr := MockResource()
+	cr.Spec.EKSSpec.EKSConfiguration.BootstrapOptions = &v1alpha1.BootstrapOptions{
+		ContainerRuntime: v1alpha1.DockerRuntime,
+		MaxPods:          20,
+	}
+	c, err := NewProvisionerConfiguration(cm, cr)
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+
+	err = c.SetDefaults()
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(c.InstanceGroup.Spec.EKSSpec.EKSConfiguration.BootstrapOptions).To(gomega.Equal(&v1alpha1.BootstrapOptions{
+		ContainerRuntime: v1alpha1.ContainerDRuntime,
+		MaxPods:          20,
+	}))
+}
+
 func TestSetDefaultsWithRestrictedConditional(t *testing.T) {
 	var (
 		g = gomega.NewGomegaWithT(t)
@@ -472,6 +507,46 @@ spec:
 	g.Expect(err).To(gomega.HaveOccurred())
 }
 
+func TestSetDefaultsWithConflictingBoundaries(t *testing.T) {
+	var (
+		g = gomega.NewGomegaWithT(t)
+	)
+
+	// A field path can only appear in a single boundary category
+	mockBoundaries := `
+    restricted:
+    - spec.eks.configuration.securityGroups
+    shared:
+      merge:
+      - spec.eks.configuration.labels
+      - spec.eks.configuration.securityGroups`
+
+	cm := MockConfigMap(MockConfigData("boundaries", mockBoundaries))
+	cr := MockResource()
+	_, err := NewProvisionerConfiguration(cm, cr)
+	g.Expect(err).To(gomega.HaveOccurred())
+	g.Expect(err.Error()).To(gomega.ContainSubstring("cannot be in both 'restricted' and 'shared.merge'"))
+
+	// Field paths must be rooted at spec
+	for _, path := range []string{"metadata.labels", "spec", "spec..eks", "spec.eks.configuration."} {
+		mockBoundaries = `
+    restricted:
+    - ` + path
+		cm = MockConfigMap(MockConfigData("boundaries", mockBoundaries))
+		_, err = NewProvisionerConfiguration(cm, cr)
+		g.Expect(err).To(gomega.HaveOccurred())
+	}
+
+	// Repeating a path within the same category is allowed
+	mockBoundaries = `
+    restricted:
+    - spec.eks.configuration.securityGroups
+    - spec.eks.configuration.securityGroups`
+	cm = MockConfigMap(MockConfigData("boundaries", mockBoundaries))
+	_, err = NewProvisionerConfiguration(cm, cr)
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+}
+
 func TestSetDefaultsWithInvalidConditionalSelector(t *testing.T) {
 	var (
 		g = gomega.NewGomegaWithT(t)
@@ -521,6 +596,66 @@ spec:
 	g.Expect(err).To(gomega.HaveOccurred())
 }
 
+func TestGetMatchingConditionals(t *testing.T) {
+	var (
+		g = gomega.NewGomegaWithT(t)
+	)
+
+	const (
+		osFamily = "instancemgr.keikoproj.io/os-family"
+		arch     = "instancemgr.keikoproj.io/arch"
+	)
+
+	tests := []struct {
+		selector    string
+		annotations map[string]string
+		expected    bool
+	}{
+		// equality
+		{selector: osFamily + "=windows", annotations: map[string]string{osFamily: "windows"}, expected: true},
+		{selector: osFamily + "=windows", annotations: map[string]string{osFamily: "bottlerocket"}, expected: false},
+		{selector: osFamily + "=windows", annotations: map[string]string{}, expected: false},
+		{selector: osFamily + "!=windows", annotations: map[string]string{osFamily: "windows"}, expected: false},
+		{selector: osFamily + "!=windows", annotations: map[string]string{}, expected: true},
+		// in
+		{selector: osFamily + " in (windows,bottlerocket)", annotations: map[string]string{osFamily: "bottlerocket"}, expected: true},
+		{selector: osFamily + " in (windows,bottlerocket)", annotations: map[string]string{osFamily: "amazonlinux2"}, expected: false},
+		{selector: osFamily + " in (windows,bottlerocket)", annotations: map[string]string{}, expected: false},
+		// notin
+		{selector: osFamily + " notin (windows,bottlerocket)", annotations: map[string]string{osFamily: "windows"}, expected: false},
+		{selector: osFamily + " notin (windows,bottlerocket)", annotations: map[string]string{osFamily: "amazonlinux2"}, expected: true},
+		{selector: osFamily + " notin (windows,bottlerocket)", annotations: map[string]string{}, expected: true},
+		// exists
+		{selector: osFamily, annotations: map[string]string{osFamily: "windows"}, expected: true},
+		{selector: osFamily, annotations: map[string]string{osFamily: ""}, expected: true},
+		{selector: osFamily, annotations: map[string]string{arch: "arm64"}, expected: false},
+		{selector: osFamily, annotations: nil, expected: false},
+		// does not exist
+		{selector: "!" + osFamily, annotations: map[string]string{osFamily: "windows"}, expected: false},
+		{selector: "!" + osFamily, annotations: map[string]string{arch: "arm64"}, expected: true},
+		{selector: "!" + osFamily, annotations: nil, expected: true},
+		// all requirements must match
+		{selector: arch + " in (arm64), !" + osFamily, annotations: map[string]string{arch: "arm64"}, expected: true},
+		{selector: arch + " in (arm64), !" + osFamily, annotations: map[string]string{arch: "arm64", osFamily: "windows"}, expected: false},
+		{selector: arch + ", " + osFamily + " notin (windows)", annotations: map[string]string{osFamily: "bottlerocket"}, expected: false},
+	}
+
+	for i, tc := range tests {
+		t.Logf("Test #%v - %+v", i, tc)
+		ig := MockResource()
+		ig.SetAnnotations(tc.annotations)
+		conditionals := []Conditional{{AnnotationSelector: tc.selector}}
+
+		matching, err := getMatchingConditionals(ig, conditionals)
+		g.Expect(err).NotTo(gomega.HaveOccurred())
+		if tc.expected {
+			g.Expect(matching).To(gomega.Equal(conditionals))
+		} else {
+			g.Expect(matching).To(gomega.BeEmpty())
+		}
+	}
+}
+
 func TestSetDefaultsWithSharedConditionalMerge(t *testing.T) {
 	var (
 		g = gomega.NewGomegaWithT(t)
@@ -572,6 +707,49 @@ spec:
 	}))
 }
 
+func TestSetDefaultsSharedMergeUserData(t *testing.T) {
+	var (
+		g = gomega.NewGomegaWithT(t)
+	)
+
+	// Platform userData stages are merged with the resource stages by name, favoring the default on conflict
+	mockBoundaries := `
+    shared:
+      merge:
+      - spec.eks.configuration.userData`
+
+	mockDefaults := `
+spec:
+  eks:
+    configuration:
+      userData:
+      - name: platform-agent
+        stage: PreBootstrap
+        data: cGxhdGZvcm0tYWdlbnQ=`
+
+	cm := MockConfigMap(MockConfigData("boundaries", mockBoundaries, "defaults", mockDefaults))
+	cr := MockResource()
+	cr.Spec.EKSSpec.EKSConfiguration.UserData = []v1alpha1.UserDataStage{
+		{Name: "tune-sysctl", Stage: v1alpha1.PreBootstrapStage, Data: "c3lzY3Rs"},
+		{Name: "platform-agent", Stage: v1alpha1.PreBootstrapStage, Data: "b3ZlcnJpZGU="},
+		{Stage: v1alpha1.PostBootstrapStage, Data: "cG9zdC0x"},
+		{Stage: v1alpha1.PostBootstrapStage, Data: "cG9zdC0y"},
+	}
+	c, err := NewProvisionerConfiguration(cm, cr)
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+
+	err = c.SetDefaults()
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+
+	// the platform stage cannot be overridden and unnamed stages are never deduplicated
+	g.Expect(c.InstanceGroup.Spec.EKSSpec.EKSConfiguration.UserData).To(gomega.Equal([]v1alpha1.UserDataStage{
+		{Name: "platform-agent", Stage: v1alpha1.PreBootstrapStage, Data: "cGxhdGZvcm0tYWdlbnQ="},
+		{Name: "tune-sysctl", Stage: v1alpha1.PreBootstrapStage, Data: "c3lzY3Rs"},
+		{Stage: v1alpha1.PostBootstrapStage, Data: "cG9zdC0x"},
+		{Stage: v1alpha1.PostBootstrapStage, Data: "cG9zdC0y"},
+	}))
+}
+
 func TestSetDefaultsSharedMergeOverride(t *testing.T) {
 	var (
 		g = gomega.NewGomegaWithT(t)
@@ -896,6 +1074,61 @@ spec:
 	g.Expect(c.InstanceGroup.Spec.EKSSpec.EKSConfiguration.KeyPairName).To(gomega.Equal("TestKeyPair"))
 }
 
+func TestGetDefaultVolumes(t *testing.T) {
+	var (
+		g = gomega.NewGomegaWithT(t)
+	)
+
+	mockDefaults := `
+spec:
+  eks:
+    configuration:
+      volumes:
+      - name: /dev/xvda
+        type: gp3
+        size: 50
+        encrypted: true`
+
+	mockConditionals := `
+- annotationSelector: 'instancemgr.keikoproj.io/os-family = bottlerocket'
+  defaults:
+    spec:
+      eks:
+        configuration:
+          volumes:
+          - name: /dev/xvdb
+            type: gp3
+            size: 100`
+
+	encrypted := true
+	expected := MockVolume("/dev/xvda", "gp3", 50)
+	expected.Encrypted = &encrypted
+
+	// volumes in the defaults are returned even though they are not in a boundary
+	cm := MockConfigMap(MockConfigData("defaults", mockDefaults, "conditionals", mockConditionals))
+	cr := MockResource()
+	c, err := NewProvisionerConfiguration(cm, cr)
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	volumes, err := c.GetDefaultVolumes()
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(volumes).To(gomega.Equal([]v1alpha1.NodeVolume{expected}))
+
+	// a matching conditional takes precedence
+	cr.Annotations = map[string]string{"instancemgr.keikoproj.io/os-family": "bottlerocket"}
+	c, err = NewProvisionerConfiguration(cm, cr)
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	volumes, err = c.GetDefaultVolumes()
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(volumes).To(gomega.Equal([]v1alpha1.NodeVolume{MockVolume("/dev/xvdb", "gp3", 100)}))
+
+	// no default volumes configured
+	c, err = NewProvisionerConfiguration(MockConfigMap(MockConfigData("defaults", "spec: {}")), MockResource())
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	volumes, err = c.GetDefaultVolumes()
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(volumes).To(gomega.BeNil())
+}
+
 func TestUnmarshalConfiguration(t *testing.T) {
 	var (
 		g = gomega.NewGomegaWithT(t)
@@ -909,11 +1142,11 @@ shared:
   mergeOverride:
   - spec.eks.configuration.volumes
   merge:
-  - spec.eks.configuration.volumes
+  - spec.eks.configuration.securityGroups
   - spec.eks.configuration.tags
   replace:
-  - spec.eks.configuration.taints
-  - spec.eks.configuration.labels`
+  - spec.strategy
+  - spec.eks.configuration.userData`
 
 	mockDefaults := `
 spec:
@@ -941,9 +1174,9 @@ spec:
 	c, err := NewProvisionerConfiguration(cm, &v1alpha1.InstanceGroup{})
 	g.Expect(err).NotTo(gomega.HaveOccurred())
 	g.Expect(c.Boundaries.Restricted).To(gomega.ConsistOf("spec.eks.configuration.taints", "spec.eks.configuration.labels"))
-	g.Expect(c.Boundaries.Shared.Merge).To(gomega.ConsistOf("spec.eks.configuration.volumes", "spec.eks.configuration.tags"))
+	g.Expect(c.Boundaries.Shared.Merge).To(gomega.ConsistOf("spec.eks.configuration.securityGroups", "spec.eks.configuration.tags"))
 	g.Expect(c.Boundaries.Shared.MergeOverride).To(gomega.ConsistOf("spec.eks.configuration.volumes"))
-	g.Expect(c.Boundaries.Shared.Replace).To(gomega.ConsistOf("spec.eks.configuration.taints", "spec.eks.configuration.labels"))
+	g.Expect(c.Boundaries.Shared.Replace).To(gomega.ConsistOf("spec.strategy", "spec.eks.configuration.userData"))
 	g.Expect(c.Defaults).To(gomega.Equal(expectedDefaults))
 }
 
@@ -23,6 +23,7 @@ import (
 	"github.com/keikoproj/instance-manager/controllers/common"
 	awsprovider "github.com/keikoproj/instance-manager/controllers/providers/aws"
 	kubeprovider "github.com/keikoproj/instance-manager/controllers/providers/kubernetes"
+	"github.com/keikoproj/instance-manager/controllers/provisioners"
 	"github.com/keikoproj/instance-manager/controllers/provisioners/eks/scaling"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -53,8 +54,17 @@ type DiscoveredState struct {
 	Publisher            kubeprovider.EventPublisher
 	Cluster              *eks.Cluster
 	VPCId                string
-	InstancePool         InstancePoolSpec
-	InstanceTypeInfo     []*ec2.InstanceTypeInfo
+	Subnets              []string
+	// SubnetInfo holds the subnets described during discovery, these are reused by checks which need subnet attributes
+	SubnetInfo       []*ec2.Subnet
+	InstancePool     InstancePoolSpec
+	InstanceTypeInfo []*ec2.InstanceTypeInfo
+	// PendingLifecycleActions is the number of instances waiting on a lifecycle hook automation execution
+	PendingLifecycleActions int
+	// NodeReadinessTimedOut is set when the nodes remained not ready for longer than the node readiness timeout
+	NodeReadinessTimedOut bool
+	// RoleNodes are the ready nodes selected by the role label during a reconcile shortcut, which skips cloud discovery
+	RoleNodes *corev1.NodeList
 }
 
 func (ctx *EksInstanceGroupContext) CloudDiscovery() error {
@@ -78,6 +88,13 @@ func (ctx *EksInstanceGroupContext) CloudDiscovery() error {
 
 	status.SetLifecycle(v1alpha1.LifecycleStateNormal)
 
+	// an unsupported os family must not block the deletion of the instance group
+	if instanceGroup.GetDeletionTimestamp().IsZero() {
+		if err := ctx.ValidateOsFamily(); err != nil {
+			return err
+		}
+	}
+
 	if spec.IsLaunchConfiguration() {
 		input := &scaling.DiscoverConfigurationInput{
 			TargetConfigName: status.GetActiveLaunchConfigurationName(),
@@ -99,6 +116,11 @@ func (ctx *EksInstanceGroupContext) CloudDiscovery() error {
 		input := &scaling.DiscoverConfigurationInput{
 			TargetConfigName: status.GetActiveLaunchTemplateName(),
 		}
+		if name := configuration.GetExistingLaunchTemplateName(); !common.StringEmpty(name) {
+			input.TargetConfigName = name
+			// the launch template owned before switching to an existing launch template is no longer used
+			ctx.RetireLaunchTemplate(status.GetActiveLaunchTemplateName())
+		}
 
 		var (
 			config *scaling.LaunchTemplate
@@ -125,18 +147,26 @@ func (ctx *EksInstanceGroupContext) CloudDiscovery() error {
 	state.SetClusterNodes(nodes)
 
 	var roleName, instanceProfileName string
-	if configuration.HasExistingRole() {
+	switch {
+	case configuration.HasExistingRole():
 		roleName = configuration.GetRoleName()
 		instanceProfileName = configuration.GetInstanceProfileName()
-	} else {
-		roleName = ctx.ResourcePrefix
-		instanceProfileName = ctx.ResourcePrefix
-		if len(roleName) > 63 {
-			// use a hash of the actual name in case we exceed the max length
-			roleName = common.StringMD5(roleName)
-			instanceProfileName = common.StringMD5(instanceProfileName)
+	case ctx.HasSharedInstanceProfile():
+		instanceProfileName = ctx.GetSharedInstanceProfileName()
+		profile, ok := ctx.AwsWorker.InstanceProfileExist(instanceProfileName)
+		if !ok {
+			return errors.Errorf("shared instance-profile '%v' does not exist", instanceProfileName)
+		}
+		if len(profile.Roles) > 0 {
+			roleName = aws.StringValue(profile.Roles[0].RoleName)
+		}
+	default:
+		roleName = ctx.GetManagedRoleName()
+		instanceProfileName = ctx.GetManagedInstanceProfileName()
+		// the created instance-profile is discovered by its recorded name, which differs while it is being renamed
+		if name := status.GetInstanceProfileName(); !common.StringEmpty(name) {
+			instanceProfileName = name
 		}
-
 	}
 
 	// cache the instancegroup IAM role if it exists
@@ -144,7 +174,7 @@ func (ctx *EksInstanceGroupContext) CloudDiscovery() error {
 		state.SetRole(val)
 		status.SetNodesArn(aws.StringValue(val.Arn))
 
-		if !configuration.HasExistingRole() {
+		if !configuration.HasExistingRole() && !ctx.HasSharedInstanceProfile() {
 			policies, err := ctx.AwsWorker.ListRolePolicies(roleName)
 			if err != nil {
 				return errors.Wrap(err, "failed to list attached role policies")
@@ -157,7 +187,7 @@ func (ctx *EksInstanceGroupContext) CloudDiscovery() error {
 		state.SetInstanceProfile(val)
 	}
 
-	scalingGroups, err := ctx.AwsWorker.DescribeAutoscalingGroups()
+	scalingGroups, err := ctx.AwsWorker.DescribeAutoscalingGroupsByTag(provisioners.TagClusterName, clusterName)
 	if err != nil {
 		return errors.Wrap(err, "failed to describe autoscaling groups")
 	}
@@ -168,14 +198,43 @@ func (ctx *EksInstanceGroupContext) CloudDiscovery() error {
 	}
 	state.SetCluster(cluster)
 
+	if err := ctx.ValidateBootstrapOptions(); err != nil {
+		return err
+	}
+
 	vpcID := aws.StringValue(cluster.ResourcesVpcConfig.VpcId)
 	state.SetVPCId(vpcID)
 
+	// subnets are not needed for deletion, a subnet which no longer resolves must not block it
+	if instanceGroup.GetDeletionTimestamp().IsZero() {
+		subnets, err := ctx.ResolveSubnets()
+		if err != nil {
+			return errors.Wrap(err, "failed to resolve subnets")
+		}
+		if zones := ctx.GetAvailabilityZones(); len(zones) > 0 && len(subnets) == 0 {
+			return errors.Errorf("no subnets found in availability zones %v", strings.Join(zones, ","))
+		}
+		state.SetSubnets(subnets)
+	}
+
+	ctx.discoverEndpointReachability()
+
+	if keyName := configuration.KeyPairName; !common.StringEmpty(keyName) {
+		exists, err := ctx.AwsWorker.KeyPairExists(keyName)
+		if err != nil {
+			return errors.Wrap(err, "failed to describe key pairs")
+		}
+		if !exists {
+			return errors.Errorf("key pair '%v' referenced in 'keyPairName' does not exist in the region", keyName)
+		}
+	}
+
 	instanceTypes, err := ctx.AwsWorker.DescribeInstanceTypes()
 	if err != nil {
 		return errors.Wrap(err, "failed to discover instance types")
 	}
 	state.SetInstanceTypeInfo(instanceTypes)
+	ctx.discoverDeprecatedInstanceTypes()
 
 	if strings.EqualFold(configuration.Image, v1alpha1.ImageLatestValue) {
 		latestAmiId, err := ctx.GetEksLatestAmi()
@@ -196,6 +255,19 @@ func (ctx *EksInstanceGroupContext) CloudDiscovery() error {
 		ctx.Log.V(4).Info("Updating Image ID with ami", "ami_id", amiId)
 	}
 
+	if strings.HasPrefix(configuration.Image, v1alpha1.ImageTagPrefix) {
+		key, value, _ := strings.Cut(strings.TrimPrefix(configuration.Image, v1alpha1.ImageTagPrefix), "=")
+		amiId, err := ctx.AwsWorker.GetNewestImageByTag(key, value)
+		if err != nil {
+			return errors.Wrap(err, "failed to discover ami")
+		}
+		if common.StringEmpty(amiId) {
+			return errors.Errorf("no AMI owned by the account found with tag '%v=%v'", key, value)
+		}
+		configuration.Image = amiId
+		ctx.Log.V(4).Info("Updating Image ID with tagged ami", "ami_id", amiId)
+	}
+
 	// All information needed to creating the scaling group must happen before this line.
 	// find all owned scaling groups
 	ownedScalingGroups := ctx.findOwnedScalingGroups(scalingGroups)
@@ -218,6 +290,16 @@ func (ctx *EksInstanceGroupContext) CloudDiscovery() error {
 		ctx.Log.Error(err, "failed to discover spot price")
 	}
 
+	err = ctx.discoverSpotInterruptions()
+	if err != nil {
+		ctx.Log.Error(err, "failed to discover spot interruptions")
+	}
+
+	err = ctx.discoverScalingActivities()
+	if err != nil {
+		ctx.Log.Error(err, "failed to discover scaling activities")
+	}
+
 	spotPrice := configuration.GetSpotPrice()
 	if !common.StringEmpty(spotPrice) {
 		status.SetLifecycle(v1alpha1.LifecycleStateSpot)
@@ -228,6 +310,9 @@ func (ctx *EksInstanceGroupContext) CloudDiscovery() error {
 		return errors.Wrap(err, "failed to describe lifecycle hooks")
 	}
 
+	// compare against the last applied state before it is overwritten with discovered values
+	status.SetDriftDetected(ctx.ScalingGroupDrifted(targetScalingGroup))
+
 	// update status with scaling group info
 	status.SetActiveScalingGroupName(asgName)
 	status.SetCurrentMin(int(aws.Int64Value(targetScalingGroup.MinSize)))
@@ -248,9 +333,13 @@ func (ctx *EksInstanceGroupContext) CloudDiscovery() error {
 	}
 
 	if spec.IsLaunchTemplate() {
+		targetConfigName := state.ScalingConfiguration.Name()
+		if name := configuration.GetExistingLaunchTemplateName(); !common.StringEmpty(name) {
+			targetConfigName = name
+		}
 		state.ScalingConfiguration, err = scaling.NewLaunchTemplate(instanceGroup.NamespacedName(), ctx.AwsWorker, &scaling.DiscoverConfigurationInput{
 			ScalingGroup:     targetScalingGroup,
-			TargetConfigName: state.ScalingConfiguration.Name(),
+			TargetConfigName: targetConfigName,
 		})
 		if err != nil {
 			return errors.Wrap(err, "failed to discover launch templates")
@@ -260,8 +349,12 @@ func (ctx *EksInstanceGroupContext) CloudDiscovery() error {
 		if err != nil {
 			return errors.Wrap(err, "failed to discover launch templates")
 		}
+		var poolFamilies [][]string
+		if mixedInstancesPolicy != nil {
+			poolFamilies = mixedInstancesPolicy.InstancePoolFamilies
+		}
 		var (
-			pool             = subFamilyFlexiblePool(offerings, instanceTypes)
+			pool             = subFamilyFlexiblePool(offerings, instanceTypes, poolFamilies)
 			resource         = state.ScalingConfiguration.Resource()
 			resourceName     = state.ScalingConfiguration.Name()
 			template         = scaling.ConvertToLaunchTemplate(resource)
@@ -274,13 +367,23 @@ func (ctx *EksInstanceGroupContext) CloudDiscovery() error {
 		status.SetLatestTemplateVersion(latestVersionStr)
 	}
 
-	// delete old launch configurations
-	state.ScalingConfiguration.Delete(&scaling.DeleteConfigurationInput{
+	// delete old launch configurations, only the versions created by the controller are deleted from an existing launch
+	// template
+	deleteInput := &scaling.DeleteConfigurationInput{
 		Name:           state.ScalingConfiguration.Name(),
 		Prefix:         ctx.ResourcePrefix,
 		DeleteAll:      false,
 		RetainVersions: ctx.ConfigRetention,
-	})
+		ScalingGroup:   state.GetScalingGroup(),
+	}
+	if ctx.HasExistingLaunchTemplate() {
+		deleteInput.VersionDescription = ctx.GetLaunchTemplateVersionDescription()
+	}
+	state.ScalingConfiguration.Delete(deleteInput)
+
+	if err := ctx.DeleteRetiredLaunchTemplates(state.GetScalingGroup()); err != nil {
+		ctx.Log.Error(err, "failed to delete retired launch templates")
+	}
 
 	switch status.GetNodesReadyCondition() {
 	case corev1.ConditionTrue:
@@ -320,6 +423,32 @@ func (d *DiscoveredState) GetVPCId() string {
 	return d.VPCId
 }
 
+func (d *DiscoveredState) SetSubnets(subnets []string) {
+	d.Subnets = subnets
+}
+
+func (d *DiscoveredState) GetSubnets() []string {
+	return d.Subnets
+}
+
+func (d *DiscoveredState) SetSubnetInfo(subnets []*ec2.Subnet) {
+	d.SubnetInfo = subnets
+}
+
+func (d *DiscoveredState) GetSubnetInfo() []*ec2.Subnet {
+	return d.SubnetInfo
+}
+
+// GetSubnetById returns a subnet described during discovery, or nil if the subnet was not described
+func (d *DiscoveredState) GetSubnetById(id string) *ec2.Subnet {
+	for _, subnet := range d.SubnetInfo {
+		if strings.EqualFold(aws.StringValue(subnet.SubnetId), id) {
+			return subnet
+		}
+	}
+	return nil
+}
+
 func (d *DiscoveredState) GetClusterVersion() string {
 	if d.Cluster == nil {
 		return ""
@@ -341,6 +470,24 @@ func (d *DiscoveredState) GetClusterEndpoint() string {
 	return aws.StringValue(d.Cluster.Endpoint)
 }
 
+// IsPrivateEndpointOnly returns true if the cluster's api server endpoint is only reachable from within the cluster vpc
+func (d *DiscoveredState) IsPrivateEndpointOnly() bool {
+	if d.Cluster == nil || d.Cluster.ResourcesVpcConfig == nil {
+		return false
+	}
+	vpcConfig := d.Cluster.ResourcesVpcConfig
+	return aws.BoolValue(vpcConfig.EndpointPrivateAccess) && !aws.BoolValue(vpcConfig.EndpointPublicAccess)
+}
+
+// IsClusterActive returns false if the cluster reports a status other than ACTIVE, e.g. while the control plane is updating,
+// an unknown status is treated as active
+func (d *DiscoveredState) IsClusterActive() bool {
+	if d.Cluster == nil || d.Cluster.Status == nil {
+		return true
+	}
+	return strings.EqualFold(aws.StringValue(d.Cluster.Status), eks.ClusterStatusActive)
+}
+
 func (d *DiscoveredState) SetOwnedScalingGroups(groups []*autoscaling.Group) {
 	d.OwnedScalingGroups = groups
 }
@@ -418,6 +565,12 @@ func (d *DiscoveredState) SetClusterNodes(nodes *corev1.NodeList) {
 func (d *DiscoveredState) GetClusterNodes() *corev1.NodeList {
 	return d.ClusterNodes
 }
+func (d *DiscoveredState) SetRoleNodes(nodes *corev1.NodeList) {
+	d.RoleNodes = nodes
+}
+func (d *DiscoveredState) GetRoleNodes() *corev1.NodeList {
+	return d.RoleNodes
+}
 func (d *DiscoveredState) GetRunningInstanceTypes() []string {
 	types := make([]string, 0)
 	if d.ScalingGroup == nil {
@@ -439,7 +592,30 @@ func (d *DiscoveredState) SetSubFamilyFlexiblePool(pool map[string][]InstanceSpe
 	}
 }
 
-func subFamilyFlexiblePool(offerings []*ec2.InstanceTypeOffering, typeInfo []*ec2.InstanceTypeInfo) map[string][]InstanceSpec {
+// isSameInstancePoolFamily returns true if two instance types can substitute each other, by default types of the same family
+// and generation are substitutable, otherwise their instance classes must be part of the same group of families
+func isSameInstancePoolFamily(families [][]string, x, y string) bool {
+	if len(families) == 0 {
+		return strings.EqualFold(awsprovider.GetInstanceFamily(x), awsprovider.GetInstanceFamily(y)) &&
+			strings.EqualFold(awsprovider.GetInstanceGeneration(x), awsprovider.GetInstanceGeneration(y))
+	}
+
+	var (
+		xClass = strings.Split(x, ".")[0]
+		yClass = strings.Split(y, ".")[0]
+	)
+	if strings.EqualFold(xClass, yClass) {
+		return true
+	}
+	for _, family := range families {
+		if common.ContainsEqualFold(family, xClass) {
+			return common.ContainsEqualFold(family, yClass)
+		}
+	}
+	return false
+}
+
+func subFamilyFlexiblePool(offerings []*ec2.InstanceTypeOffering, typeInfo []*ec2.InstanceTypeInfo, families [][]string) map[string][]InstanceSpec {
 	var (
 		DefaultOfferingWeight = "1"
 		pool                  = make(map[string][]InstanceSpec, 0)
@@ -447,13 +623,11 @@ func subFamilyFlexiblePool(offerings []*ec2.InstanceTypeOffering, typeInfo []*ec
 
 	for _, t := range offerings {
 		var (
-			offeringType      = aws.StringValue(t.InstanceType)
-			desiredArchs      = awsprovider.GetInstanceArchitectures(typeInfo, offeringType)
-			desiredFamily     = awsprovider.GetInstanceFamily(offeringType)
-			desiredGeneration = awsprovider.GetInstanceGeneration(offeringType)
-			cpu               = awsprovider.GetOfferingVCPU(typeInfo, offeringType)
-			mem               = awsprovider.GetOfferingMemory(typeInfo, offeringType)
-			spec              = InstanceSpec{
+			offeringType = aws.StringValue(t.InstanceType)
+			desiredArchs = awsprovider.GetInstanceArchitectures(typeInfo, offeringType)
+			cpu          = awsprovider.GetOfferingVCPU(typeInfo, offeringType)
+			mem          = awsprovider.GetOfferingMemory(typeInfo, offeringType)
+			spec         = InstanceSpec{
 				Type:   offeringType,
 				Weight: DefaultOfferingWeight,
 			}
@@ -467,8 +641,6 @@ func subFamilyFlexiblePool(offerings []*ec2.InstanceTypeOffering, typeInfo []*ec
 				instanceType = aws.StringValue(i.InstanceType)
 				instanceVCPU = aws.Int64Value(i.VCpuInfo.DefaultVCpus)
 				instanceMem  = aws.Int64Value(i.MemoryInfo.SizeInMiB)
-				family       = awsprovider.GetInstanceFamily(instanceType)
-				generation   = awsprovider.GetInstanceGeneration(instanceType)
 				spec         = InstanceSpec{
 					Type:   instanceType,
 					Weight: DefaultOfferingWeight,
@@ -484,10 +656,7 @@ func subFamilyFlexiblePool(offerings []*ec2.InstanceTypeOffering, typeInfo []*ec
 				continue
 			}
 
-			if !strings.EqualFold(family, desiredFamily) {
-				continue
-			}
-			if !strings.EqualFold(generation, desiredGeneration) {
+			if !isSameInstancePoolFamily(families, offeringType, instanceType) {
 				continue
 			}
 			if strings.EqualFold(offeringType, instanceType) {
@@ -27,8 +27,11 @@ import (
 	"github.com/aws/aws-sdk-go/service/eks"
 	"github.com/aws/aws-sdk-go/service/iam"
 	"github.com/keikoproj/instance-manager/api/instancemgr/v1alpha1"
+	kubeprovider "github.com/keikoproj/instance-manager/controllers/providers/kubernetes"
 	"github.com/keikoproj/instance-manager/controllers/provisioners"
 	"github.com/onsi/gomega"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
 )
@@ -116,6 +119,62 @@ func TestCloudDiscoveryPositive(t *testing.T) {
 	g.Expect(status.GetCurrentMax()).To(gomega.Equal(6))
 }
 
+func TestCloudDiscoveryScalingGroupPages(t *testing.T) {
+	var (
+		g       = gomega.NewGomegaWithT(t)
+		k       = MockKubernetesClientSet()
+		ig      = MockInstanceGroup()
+		asgMock = NewAutoScalingMocker()
+		iamMock = NewIamMocker()
+		eksMock = NewEksMocker()
+		ec2Mock = NewEc2Mocker()
+		ssmMock = NewSsmMocker()
+	)
+
+	w := MockAwsWorker(asgMock, iamMock, eksMock, ec2Mock, ssmMock)
+	ctx := MockContext(ig, k, w)
+	state := ctx.GetDiscoveredState()
+	configuration := ig.GetEKSConfiguration()
+
+	iamMock.Role = &iam.Role{
+		RoleName: aws.String("some-role"),
+		Arn:      aws.String("some-arn"),
+	}
+
+	var (
+		clusterName       = "some-cluster"
+		resourceName      = "some-instance-group"
+		resourceNamespace = "default"
+		ownershipTag      = MockTagDescription(provisioners.TagClusterName, clusterName)
+		otherClusterTag   = MockTagDescription(provisioners.TagClusterName, "other-cluster")
+		nameTag           = MockTagDescription(provisioners.TagInstanceGroupName, resourceName)
+		namespaceTag      = MockTagDescription(provisioners.TagInstanceGroupNamespace, resourceNamespace)
+		otherNamespaceTag = MockTagDescription(provisioners.TagInstanceGroupNamespace, "other-namespace")
+		targetGroup       = MockScalingGroup("scaling-group-3", false, ownershipTag, nameTag, namespaceTag)
+	)
+
+	ig.SetName(resourceName)
+	ig.SetNamespace(resourceNamespace)
+	configuration.SetClusterName(clusterName)
+
+	asgMock.AutoScalingGroupPages = [][]*autoscaling.Group{
+		{
+			MockScalingGroup("scaling-group-1", false, otherClusterTag, nameTag, namespaceTag),
+			MockScalingGroup("scaling-group-2", false, ownershipTag, nameTag, otherNamespaceTag),
+		},
+		{
+			MockScalingGroup("scaling-group-4", false, otherClusterTag),
+			targetGroup,
+		},
+	}
+
+	err := ctx.CloudDiscovery()
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(state.GetOwnedScalingGroups()).To(gomega.HaveLen(2))
+	g.Expect(state.IsProvisioned()).To(gomega.BeTrue())
+	g.Expect(state.GetScalingGroup()).To(gomega.Equal(targetGroup))
+}
+
 func TestCloudDiscoveryWithTemplatePositive(t *testing.T) {
 	var (
 		g       = gomega.NewGomegaWithT(t)
@@ -329,7 +388,54 @@ func TestDeriveSubFamilyFlexiblePool(t *testing.T) {
 		},
 	}
 
-	p := subFamilyFlexiblePool(mockOfferings, mockInfo)
+	p := subFamilyFlexiblePool(mockOfferings, mockInfo, nil)
+	g.Expect(p).To(gomega.Equal(expectedPool))
+}
+
+func TestDeriveSubFamilyFlexiblePoolWithFamilies(t *testing.T) {
+	var (
+		g = gomega.NewGomegaWithT(t)
+	)
+
+	mockOfferings := MockTypeOffering("us-west-2", "m5.large", "m5a.large", "m5n.large", "m5zn.large", "c5.large")
+
+	mockInfo := MockTypeInfo(
+		MockInstanceTypeInfo{"m5.large", 2, 8192, "amd64"},
+		MockInstanceTypeInfo{"m5a.large", 2, 8192, "amd64"},
+		MockInstanceTypeInfo{"m5n.large", 2, 8192, "amd64"},
+		MockInstanceTypeInfo{"m5zn.large", 2, 8192, "amd64"},
+		MockInstanceTypeInfo{"c5.large", 2, 8192, "amd64"},
+	)
+
+	families := [][]string{
+		{"m5", "m5a", "m5n"},
+		{"m5zn"},
+	}
+
+	expectedPool := make(map[string][]InstanceSpec, 0)
+	expectedPool["m5.large"] = []InstanceSpec{
+		{Type: "m5.large", Weight: "1"},
+		{Type: "m5a.large", Weight: "1"},
+		{Type: "m5n.large", Weight: "1"},
+	}
+	expectedPool["m5a.large"] = []InstanceSpec{
+		{Type: "m5a.large", Weight: "1"},
+		{Type: "m5.large", Weight: "1"},
+		{Type: "m5n.large", Weight: "1"},
+	}
+	expectedPool["m5n.large"] = []InstanceSpec{
+		{Type: "m5n.large", Weight: "1"},
+		{Type: "m5.large", Weight: "1"},
+		{Type: "m5a.large", Weight: "1"},
+	}
+	expectedPool["m5zn.large"] = []InstanceSpec{
+		{Type: "m5zn.large", Weight: "1"},
+	}
+	expectedPool["c5.large"] = []InstanceSpec{
+		{Type: "c5.large", Weight: "1"},
+	}
+
+	p := subFamilyFlexiblePool(mockOfferings, mockInfo, families)
 	g.Expect(p).To(gomega.Equal(expectedPool))
 }
 
@@ -368,6 +474,382 @@ func TestCloudDiscoveryExistingRole(t *testing.T) {
 	g.Expect(state.GetInstanceProfile()).To(gomega.Equal(iamMock.InstanceProfile))
 }
 
+func TestCloudDiscoverySharedInstanceProfile(t *testing.T) {
+	var (
+		g       = gomega.NewGomegaWithT(t)
+		k       = MockKubernetesClientSet()
+		ig      = MockInstanceGroup()
+		asgMock = NewAutoScalingMocker()
+		iamMock = NewIamMocker()
+		eksMock = NewEksMocker()
+		ec2Mock = NewEc2Mocker()
+		ssmMock = NewSsmMocker()
+	)
+
+	w := MockAwsWorker(asgMock, iamMock, eksMock, ec2Mock, ssmMock)
+	ctx := MockContext(ig, k, w)
+	state := ctx.GetDiscoveredState()
+
+	iamMock.Role = &iam.Role{
+		RoleName: aws.String("shared-role"),
+		Arn:      aws.String("shared-role-arn"),
+	}
+
+	iamMock.InstanceProfile = &iam.InstanceProfile{
+		InstanceProfileName: aws.String("shared-profile"),
+		Roles:               []*iam.Role{iamMock.Role},
+	}
+
+	ig.SetAnnotations(map[string]string{
+		SharedInstanceProfileAnnotation: "shared-profile",
+	})
+
+	err := ctx.CloudDiscovery()
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(state.GetRole()).To(gomega.Equal(iamMock.Role))
+	g.Expect(state.GetInstanceProfile()).To(gomega.Equal(iamMock.InstanceProfile))
+
+	// shared instance-profile is never created by the instance group
+	err = ctx.CreateManagedRole()
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(iamMock.AttachRolePolicyCallCount).To(gomega.BeZero())
+
+	iamMock.GetInstanceProfileErr = errors.New("not found")
+	err = ctx.CloudDiscovery()
+	g.Expect(err).To(gomega.HaveOccurred())
+}
+
+func TestCloudDiscoveryKeyPair(t *testing.T) {
+	var (
+		g       = gomega.NewGomegaWithT(t)
+		k       = MockKubernetesClientSet()
+		ig      = MockInstanceGroup()
+		asgMock = NewAutoScalingMocker()
+		iamMock = NewIamMocker()
+		eksMock = NewEksMocker()
+		ec2Mock = NewEc2Mocker()
+		ssmMock = NewSsmMocker()
+	)
+
+	w := MockAwsWorker(asgMock, iamMock, eksMock, ec2Mock, ssmMock)
+	ctx := MockContext(ig, k, w)
+	configuration := ig.GetEKSConfiguration()
+
+	iamMock.Role = &iam.Role{
+		RoleName: aws.String("some-role"),
+		Arn:      aws.String("some-arn"),
+	}
+
+	ec2Mock.KeyPairs = []*ec2.KeyPairInfo{
+		{
+			KeyName: aws.String("my-key"),
+		},
+	}
+
+	// empty key pair is allowed
+	err := ctx.CloudDiscovery()
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+
+	configuration.KeyPairName = "my-key"
+	err = ctx.CloudDiscovery()
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+
+	configuration.KeyPairName = "my-kye"
+	err = ctx.CloudDiscovery()
+	g.Expect(err).To(gomega.HaveOccurred())
+	g.Expect(err.Error()).To(gomega.ContainSubstring("key pair 'my-kye' referenced in 'keyPairName' does not exist"))
+}
+
+func TestCloudDiscoveryImageTag(t *testing.T) {
+	var (
+		g       = gomega.NewGomegaWithT(t)
+		k       = MockKubernetesClientSet()
+		ig      = MockInstanceGroup()
+		asgMock = NewAutoScalingMocker()
+		iamMock = NewIamMocker()
+		eksMock = NewEksMocker()
+		ec2Mock = NewEc2Mocker()
+		ssmMock = NewSsmMocker()
+	)
+
+	w := MockAwsWorker(asgMock, iamMock, eksMock, ec2Mock, ssmMock)
+	ctx := MockContext(ig, k, w)
+	configuration := ig.GetEKSConfiguration()
+
+	iamMock.Role = &iam.Role{
+		RoleName: aws.String("some-role"),
+		Arn:      aws.String("some-arn"),
+	}
+
+	mockImage := func(id, created, channel string) *ec2.Image {
+		return &ec2.Image{
+			ImageId:      aws.String(id),
+			CreationDate: aws.String(created),
+			Tags: []*ec2.Tag{
+				{Key: aws.String("channel"), Value: aws.String(channel)},
+			},
+		}
+	}
+	ec2Mock.Images = []*ec2.Image{
+		mockImage("ami-stable-old", "2024-01-10T08:00:00.000Z", "stable"),
+		mockImage("ami-stable-new", "2024-03-02T08:00:00.000Z", "stable"),
+		mockImage("ami-stable-older", "2023-12-01T08:00:00.000Z", "stable"),
+		mockImage("ami-beta", "2024-04-01T08:00:00.000Z", "beta"),
+	}
+
+	// the newest image with the tag is used
+	configuration.Image = "tag:channel=stable"
+	err := ctx.CloudDiscovery()
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(configuration.Image).To(gomega.Equal("ami-stable-new"))
+
+	configuration.Image = "tag:channel=nightly"
+	err = ctx.CloudDiscovery()
+	g.Expect(err).To(gomega.HaveOccurred())
+	g.Expect(err.Error()).To(gomega.ContainSubstring("no AMI owned by the account found with tag 'channel=nightly'"))
+}
+
+func TestCloudDiscoveryContainerRuntime(t *testing.T) {
+	var (
+		g       = gomega.NewGomegaWithT(t)
+		k       = MockKubernetesClientSet()
+		ig      = MockInstanceGroup()
+		asgMock = NewAutoScalingMocker()
+		iamMock = NewIamMocker()
+		eksMock = NewEksMocker()
+		ec2Mock = NewEc2Mocker()
+		ssmMock = NewSsmMocker()
+	)
+
+	w := MockAwsWorker(asgMock, iamMock, eksMock, ec2Mock, ssmMock)
+	ctx := MockContext(ig, k, w)
+	configuration := ig.GetEKSConfiguration()
+
+	iamMock.Role = &iam.Role{
+		RoleName: aws.String("some-role"),
+		Arn:      aws.String("some-arn"),
+	}
+
+	// containerd cannot be selected on clusters before 1.21
+	configuration.BootstrapOptions = &v1alpha1.BootstrapOptions{
+		ContainerRuntime: v1alpha1.ContainerDRuntime,
+	}
+	err := ctx.CloudDiscovery()
+	g.Expect(err).To(gomega.HaveOccurred())
+	g.Expect(err.Error()).To(gomega.Equal("validation failed, 'bootstrapOptions.containerRuntime' cannot be 'containerd' for cluster version 1.18, requires kubernetes 1.21 or above"))
+
+	// docker is allowed on clusters before 1.24
+	configuration.BootstrapOptions.ContainerRuntime = v1alpha1.DockerRuntime
+	err = ctx.CloudDiscovery()
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+
+	eksMock.EksCluster = MockEksCluster("1.23")
+	err = ctx.CloudDiscovery()
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+
+	eksMock.EksCluster = MockEksCluster("1.24")
+	err = ctx.CloudDiscovery()
+	g.Expect(err).To(gomega.HaveOccurred())
+	g.Expect(err.Error()).To(gomega.Equal("validation failed, 'bootstrapOptions.containerRuntime' cannot be 'dockerd' for cluster version 1.24, use 'containerd' instead"))
+
+	configuration.BootstrapOptions.ContainerRuntime = v1alpha1.ContainerDRuntime
+	err = ctx.CloudDiscovery()
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+}
+
+func TestCloudDiscoveryOsFamily(t *testing.T) {
+	var (
+		g       = gomega.NewGomegaWithT(t)
+		k       = MockKubernetesClientSet()
+		ig      = MockInstanceGroup()
+		asgMock = NewAutoScalingMocker()
+		iamMock = NewIamMocker()
+		eksMock = NewEksMocker()
+		ec2Mock = NewEc2Mocker()
+		ssmMock = NewSsmMocker()
+	)
+
+	w := MockAwsWorker(asgMock, iamMock, eksMock, ec2Mock, ssmMock)
+	ctx := MockContext(ig, k, w)
+
+	iamMock.Role = &iam.Role{
+		RoleName: aws.String("some-role"),
+		Arn:      aws.String("some-arn"),
+	}
+
+	// absent annotation defaults to amazonlinux2
+	err := ctx.CloudDiscovery()
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(ctx.GetOsFamily()).To(gomega.Equal(OsFamilyAmazonLinux2))
+
+	// unsupported values are rejected rather than defaulted
+	ig.SetAnnotations(map[string]string{OsFamilyAnnotation: "amazonlinux"})
+	err = ctx.CloudDiscovery()
+	g.Expect(err).To(gomega.HaveOccurred())
+	g.Expect(err.Error()).To(gomega.ContainSubstring("validation failed, annotation 'instancemgr.keikoproj.io/os-family' has unsupported value 'amazonlinux'"))
+
+	// instance groups with an unsupported value can still be deleted
+	ig.SetDeletionTimestamp(&metav1.Time{Time: time.Now()})
+	err = ctx.CloudDiscovery()
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	ig.SetDeletionTimestamp(nil)
+
+	ig.SetAnnotations(map[string]string{OsFamilyAnnotation: OsFamilyBottleRocket})
+	err = ctx.CloudDiscovery()
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(ctx.GetOsFamily()).To(gomega.Equal(OsFamilyBottleRocket))
+}
+
+func TestCloudDiscoveryPrivateEndpoint(t *testing.T) {
+	var (
+		g       = gomega.NewGomegaWithT(t)
+		k       = MockKubernetesClientSet()
+		ig      = MockInstanceGroup()
+		asgMock = NewAutoScalingMocker()
+		iamMock = NewIamMocker()
+		eksMock = NewEksMocker()
+		ec2Mock = NewEc2Mocker()
+		ssmMock = NewSsmMocker()
+	)
+
+	w := MockAwsWorker(asgMock, iamMock, eksMock, ec2Mock, ssmMock)
+	ctx := MockContext(ig, k, w)
+	configuration := ig.GetEKSConfiguration()
+	state := ctx.GetDiscoveredState()
+
+	iamMock.Role = &iam.Role{
+		RoleName: aws.String("some-role"),
+		Arn:      aws.String("some-arn"),
+	}
+
+	eksMock.EksCluster.ResourcesVpcConfig = &eks.VpcConfigResponse{
+		VpcId:                 aws.String("vpc-1"),
+		EndpointPrivateAccess: aws.Bool(true),
+		EndpointPublicAccess:  aws.Bool(false),
+	}
+	ec2Mock.Subnets = []*ec2.Subnet{
+		{SubnetId: aws.String("subnet-1"), VpcId: aws.String("vpc-1")},
+		{SubnetId: aws.String("subnet-2"), VpcId: aws.String("vpc-2")},
+	}
+	configuration.Subnets = []string{"subnet-1", "subnet-2"}
+
+	err := ctx.CloudDiscovery()
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(state.IsPrivateEndpointOnly()).To(gomega.BeTrue())
+
+	unreachable, err := ctx.GetEndpointUnreachableSubnets()
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(unreachable).To(gomega.Equal([]string{"subnet-2"}))
+
+	events, err := k.Kubernetes.CoreV1().Events(ig.GetNamespace()).List(context.Background(), metav1.ListOptions{})
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(events.Items).To(gomega.HaveLen(1))
+	g.Expect(events.Items[0].Reason).To(gomega.Equal(string(kubeprovider.EndpointUnreachableEvent)))
+	g.Expect(events.Items[0].Message).To(gomega.ContainSubstring("subnet-2"))
+	g.Expect(ig.GetStatus().GetConditionStatus(v1alpha1.EndpointUnreachable)).To(gomega.Equal(corev1.ConditionTrue))
+
+	// the event is only published when the unreachable subnets change
+	err = ctx.CloudDiscovery()
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+
+	events, err = k.Kubernetes.CoreV1().Events(ig.GetNamespace()).List(context.Background(), metav1.ListOptions{})
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(events.Items).To(gomega.HaveLen(1))
+
+	// public endpoints are reachable from any subnet
+	eksMock.EksCluster.ResourcesVpcConfig.EndpointPublicAccess = aws.Bool(true)
+	err = ctx.CloudDiscovery()
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(state.IsPrivateEndpointOnly()).To(gomega.BeFalse())
+	g.Expect(ig.GetStatus().GetConditionStatus(v1alpha1.EndpointUnreachable)).To(gomega.Equal(corev1.ConditionUnknown))
+
+	events, err = k.Kubernetes.CoreV1().Events(ig.GetNamespace()).List(context.Background(), metav1.ListOptions{})
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(events.Items).To(gomega.HaveLen(1))
+}
+
+func TestCloudDiscoveryPrivateEndpointReusesSubnets(t *testing.T) {
+	var (
+		g       = gomega.NewGomegaWithT(t)
+		k       = MockKubernetesClientSet()
+		ig      = MockInstanceGroup()
+		asgMock = NewAutoScalingMocker()
+		iamMock = NewIamMocker()
+		eksMock = NewEksMocker()
+		ec2Mock = NewEc2Mocker()
+		ssmMock = NewSsmMocker()
+	)
+
+	w := MockAwsWorker(asgMock, iamMock, eksMock, ec2Mock, ssmMock)
+	ctx := MockContext(ig, k, w)
+	configuration := ig.GetEKSConfiguration()
+
+	iamMock.Role = &iam.Role{
+		RoleName: aws.String("some-role"),
+		Arn:      aws.String("some-arn"),
+	}
+
+	eksMock.EksCluster.ResourcesVpcConfig = &eks.VpcConfigResponse{
+		VpcId:                 aws.String("vpc-1"),
+		EndpointPrivateAccess: aws.Bool(true),
+		EndpointPublicAccess:  aws.Bool(false),
+	}
+	ec2Mock.Subnets = []*ec2.Subnet{
+		{SubnetId: aws.String("subnet-1"), VpcId: aws.String("vpc-1"), AvailabilityZone: aws.String("us-west-2a")},
+		{SubnetId: aws.String("subnet-2"), VpcId: aws.String("vpc-2"), AvailabilityZone: aws.String("us-west-2a")},
+	}
+	configuration.Subnets = []string{"subnet-1", "subnet-2"}
+	ig.SetAnnotations(map[string]string{AvailabilityZonesAnnotation: "us-west-2a"})
+
+	// subnets described while filtering by availability zone are reused by the endpoint check
+	err := ctx.CloudDiscovery()
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(ec2Mock.DescribeSubnetsCallCount).To(gomega.Equal(uint(1)))
+	g.Expect(ig.GetStatus().GetConditionMessage(v1alpha1.EndpointUnreachable)).To(gomega.Equal("subnets subnet-2 are outside of the cluster vpc vpc-1"))
+}
+
+func TestCloudDiscoveryAvailabilityZones(t *testing.T) {
+	var (
+		g       = gomega.NewGomegaWithT(t)
+		k       = MockKubernetesClientSet()
+		ig      = MockInstanceGroup()
+		asgMock = NewAutoScalingMocker()
+		iamMock = NewIamMocker()
+		eksMock = NewEksMocker()
+		ec2Mock = NewEc2Mocker()
+		ssmMock = NewSsmMocker()
+	)
+
+	w := MockAwsWorker(asgMock, iamMock, eksMock, ec2Mock, ssmMock)
+	ctx := MockContext(ig, k, w)
+	configuration := ig.GetEKSConfiguration()
+
+	iamMock.Role = &iam.Role{
+		RoleName: aws.String("some-role"),
+		Arn:      aws.String("some-arn"),
+	}
+
+	ec2Mock.Subnets = []*ec2.Subnet{
+		{SubnetId: aws.String("subnet-1"), AvailabilityZone: aws.String("us-west-2a")},
+		{SubnetId: aws.String("subnet-2"), AvailabilityZone: aws.String("us-west-2b")},
+	}
+	configuration.Subnets = []string{"subnet-1", "subnet-2"}
+
+	ig.GetAnnotations()[AvailabilityZonesAnnotation] = "us-west-2b"
+	err := ctx.CloudDiscovery()
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(ctx.GetDiscoveredState().GetSubnets()).To(gomega.Equal([]string{"subnet-2"}))
+
+	ig.GetAnnotations()[AvailabilityZonesAnnotation] = "us-west-2c"
+	err = ctx.CloudDiscovery()
+	g.Expect(err).To(gomega.MatchError(gomega.ContainSubstring("no subnets found in availability zones us-west-2c")))
+
+	// subnets which cannot be resolved fail the discovery instead of reaching the scaling group
+	ec2Mock.DescribeSubnetsErr = errors.New("an error occured")
+	err = ctx.CloudDiscovery()
+	g.Expect(err).To(gomega.MatchError(gomega.ContainSubstring("failed to resolve subnets")))
+}
+
 func TestCloudDiscoverySpotPrice(t *testing.T) {
 	var (
 		g       = gomega.NewGomegaWithT(t)
@@ -447,6 +929,281 @@ func TestCloudDiscoverySpotPrice(t *testing.T) {
 	g.Expect(configuration.GetSpotPrice()).To(gomega.BeEmpty())
 }
 
+func TestCloudDiscoveryCustomSpotRecommendationSource(t *testing.T) {
+	var (
+		g       = gomega.NewGomegaWithT(t)
+		k       = MockKubernetesClientSet()
+		ig      = MockInstanceGroup()
+		asgMock = NewAutoScalingMocker()
+		iamMock = NewIamMocker()
+		eksMock = NewEksMocker()
+		ec2Mock = NewEc2Mocker()
+		ssmMock = NewSsmMocker()
+	)
+
+	w := MockAwsWorker(asgMock, iamMock, eksMock, ec2Mock, ssmMock)
+	ctx := MockContext(ig, k, w)
+	status := ig.GetStatus()
+	configuration := ig.GetEKSConfiguration()
+
+	source, err := kubeprovider.NewSpotRecommendationSource("CustomSpotAdvice", "AutoScalingGroup")
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	ctx.SpotRecommendationSource = source
+
+	iamMock.Role = &iam.Role{
+		RoleName: aws.String("some-role"),
+		Arn:      aws.String("some-arn"),
+	}
+
+	var (
+		clusterName           = "some-cluster"
+		resourceName          = "some-instance-group"
+		resourceNamespace     = "default"
+		ownedScalingGroupName = "scaling-group-1"
+		ownershipTag          = MockTagDescription(provisioners.TagClusterName, clusterName)
+		nameTag               = MockTagDescription(provisioners.TagInstanceGroupName, resourceName)
+		namespaceTag          = MockTagDescription(provisioners.TagInstanceGroupNamespace, resourceNamespace)
+	)
+
+	ig.SetName(resourceName)
+	ig.SetNamespace(resourceNamespace)
+	configuration.SetClusterName(clusterName)
+	asgMock.AutoScalingGroups = []*autoscaling.Group{
+		MockScalingGroup(ownedScalingGroupName, false, ownershipTag, nameTag, namespaceTag),
+	}
+
+	// events with the default reason are ignored when a custom reason is configured
+	defaultEvent := MockSpotEvent("1", ownedScalingGroupName, "0.80", true, time.Now())
+	defaultEvent.InvolvedObject.Kind = "AutoScalingGroup"
+	_, err = k.Kubernetes.CoreV1().Events("").Create(context.Background(), defaultEvent, metav1.CreateOptions{})
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+
+	err = ctx.CloudDiscovery()
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(status.GetUsingSpotRecommendation()).To(gomega.BeFalse())
+	g.Expect(configuration.GetSpotPrice()).To(gomega.BeEmpty())
+
+	// events with the custom reason but a different involved object kind are ignored
+	otherKindEvent := MockSpotEvent("2", ownedScalingGroupName, "0.85", true, time.Now())
+	otherKindEvent.Reason = "CustomSpotAdvice"
+	otherKindEvent.InvolvedObject.Kind = "Node"
+	_, err = k.Kubernetes.CoreV1().Events("").Create(context.Background(), otherKindEvent, metav1.CreateOptions{})
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+
+	err = ctx.CloudDiscovery()
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(configuration.GetSpotPrice()).To(gomega.BeEmpty())
+
+	customEvent := MockSpotEvent("3", ownedScalingGroupName, "0.90", true, time.Now())
+	customEvent.Reason = "CustomSpotAdvice"
+	customEvent.InvolvedObject.Kind = "AutoScalingGroup"
+	_, err = k.Kubernetes.CoreV1().Events("").Create(context.Background(), customEvent, metav1.CreateOptions{})
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+
+	err = ctx.CloudDiscovery()
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(status.GetUsingSpotRecommendation()).To(gomega.BeTrue())
+	g.Expect(configuration.GetSpotPrice()).To(gomega.Equal("0.90"))
+}
+
+func TestDiscoverSpotInterruptions(t *testing.T) {
+	var (
+		g       = gomega.NewGomegaWithT(t)
+		k       = MockKubernetesClientSet()
+		ig      = MockInstanceGroup()
+		asgMock = NewAutoScalingMocker()
+		iamMock = NewIamMocker()
+		eksMock = NewEksMocker()
+		ec2Mock = NewEc2Mocker()
+		ssmMock = NewSsmMocker()
+		status  = ig.GetStatus()
+	)
+
+	w := MockAwsWorker(asgMock, iamMock, eksMock, ec2Mock, ssmMock)
+	ctx := MockContext(ig, k, w)
+	state := ctx.GetDiscoveredState()
+
+	scalingGroup := MockScalingGroup("asg-1", true)
+	scalingGroup.Instances = MockScalingInstances(2, 0)
+	state.SetScalingGroup(scalingGroup)
+
+	nodes := &corev1.NodeList{}
+	for _, instance := range scalingGroup.Instances {
+		nodes.Items = append(nodes.Items, *MockNode(aws.StringValue(instance.InstanceId), corev1.ConditionTrue))
+	}
+	state.SetClusterNodes(nodes)
+
+	// interruptions of nodes outside the instance group are ignored
+	now := time.Now().Truncate(time.Second)
+	_, err := k.Kubernetes.CoreV1().Events("").Create(context.Background(), MockSpotInterruptionEvent("1", "node-i-999999999", now), metav1.CreateOptions{})
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	err = ctx.discoverSpotInterruptions()
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(status.GetSpotInterruptions()).To(gomega.Equal(0))
+
+	_, err = k.Kubernetes.CoreV1().Events("").Create(context.Background(), MockSpotInterruptionEvent("2", "node-i-000000000", now), metav1.CreateOptions{})
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	err = ctx.discoverSpotInterruptions()
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(status.GetSpotInterruptions()).To(gomega.Equal(1))
+	g.Expect(status.GetLastSpotInterruptionTime()).To(gomega.Equal(now))
+
+	// previously counted interruptions are not counted again
+	err = ctx.discoverSpotInterruptions()
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(status.GetSpotInterruptions()).To(gomega.Equal(1))
+
+	// interruptions observed in the same second as the last counted one are counted once
+	_, err = k.Kubernetes.CoreV1().Events("").Create(context.Background(), MockSpotInterruptionEvent("4", "node-i-000000001", now), metav1.CreateOptions{})
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	err = ctx.discoverSpotInterruptions()
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(status.GetSpotInterruptions()).To(gomega.Equal(2))
+	g.Expect(status.GetLastSpotInterruptionTime()).To(gomega.Equal(now))
+	err = ctx.discoverSpotInterruptions()
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(status.GetSpotInterruptions()).To(gomega.Equal(2))
+
+	_, err = k.Kubernetes.CoreV1().Events("").Create(context.Background(), MockSpotInterruptionEvent("3", "node-i-000000001", now.Add(time.Minute)), metav1.CreateOptions{})
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	err = ctx.discoverSpotInterruptions()
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(status.GetSpotInterruptions()).To(gomega.Equal(3))
+	g.Expect(status.GetLastSpotInterruptionUIDs()).To(gomega.Equal([]string{"node-i-000000001-3"}))
+}
+
+func TestDiscoverScalingActivities(t *testing.T) {
+	var (
+		g       = gomega.NewGomegaWithT(t)
+		k       = MockKubernetesClientSet()
+		ig      = MockInstanceGroup()
+		asgMock = NewAutoScalingMocker()
+		iamMock = NewIamMocker()
+		eksMock = NewEksMocker()
+		ec2Mock = NewEc2Mocker()
+		ssmMock = NewSsmMocker()
+		status  = ig.GetStatus()
+	)
+
+	w := MockAwsWorker(asgMock, iamMock, eksMock, ec2Mock, ssmMock)
+	ctx := MockContext(ig, k, w)
+	state := ctx.GetDiscoveredState()
+	state.Publisher.Client = k.Kubernetes
+	state.SetScalingGroup(MockScalingGroup("asg-1", true))
+
+	failedActivityEvents := func() int {
+		events, err := k.Kubernetes.CoreV1().Events("").List(context.Background(), metav1.ListOptions{})
+		g.Expect(err).NotTo(gomega.HaveOccurred())
+		var count int
+		for _, e := range events.Items {
+			if e.Reason == string(kubeprovider.ScalingActivityFailedEvent) {
+				count++
+			}
+		}
+		return count
+	}
+
+	capacityMessage := "We currently do not have sufficient m5.large capacity in the Availability Zone you requested (us-west-2a)."
+	asgMock.ScalingActivities = []*autoscaling.Activity{
+		{
+			Description: aws.String("Launching a new EC2 instance"),
+			StatusCode:  aws.String(autoscaling.ScalingActivityStatusCodeInProgress),
+		},
+		{
+			Description:   aws.String("Launching a new EC2 instance.  Status Reason: " + capacityMessage),
+			StatusCode:    aws.String(autoscaling.ScalingActivityStatusCodeFailed),
+			StatusMessage: aws.String(capacityMessage),
+		},
+		{
+			Description: aws.String("Launching a new EC2 instance: i-000000000"),
+			StatusCode:  aws.String(autoscaling.ScalingActivityStatusCodeSuccessful),
+		},
+	}
+
+	// the most recent completed activity failed
+	err := ctx.discoverScalingActivities()
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(status.GetConditionStatus(v1alpha1.ScalingActivityFailed)).To(gomega.Equal(corev1.ConditionTrue))
+	g.Expect(status.GetConditionMessage(v1alpha1.ScalingActivityFailed)).To(gomega.Equal(capacityMessage))
+	g.Expect(failedActivityEvents()).To(gomega.Equal(1))
+
+	// the same failure is not published again
+	err = ctx.discoverScalingActivities()
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(failedActivityEvents()).To(gomega.Equal(1))
+
+	// a successful activity clears the condition
+	asgMock.ScalingActivities = append([]*autoscaling.Activity{
+		{
+			Description: aws.String("Launching a new EC2 instance: i-000000001"),
+			StatusCode:  aws.String(autoscaling.ScalingActivityStatusCodeSuccessful),
+		},
+	}, asgMock.ScalingActivities...)
+	err = ctx.discoverScalingActivities()
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(status.GetConditionStatus(v1alpha1.ScalingActivityFailed)).To(gomega.Equal(corev1.ConditionUnknown))
+}
+
+func TestCloudDiscoveryDeprecatedInstanceType(t *testing.T) {
+	var (
+		g       = gomega.NewGomegaWithT(t)
+		k       = MockKubernetesClientSet()
+		ig      = MockInstanceGroup()
+		asgMock = NewAutoScalingMocker()
+		iamMock = NewIamMocker()
+		eksMock = NewEksMocker()
+		ec2Mock = NewEc2Mocker()
+		ssmMock = NewSsmMocker()
+		status  = ig.GetStatus()
+	)
+
+	w := MockAwsWorker(asgMock, iamMock, eksMock, ec2Mock, ssmMock)
+	ctx := MockContext(ig, k, w)
+	configuration := ig.GetEKSConfiguration()
+
+	iamMock.Role = &iam.Role{
+		RoleName: aws.String("some-role"),
+		Arn:      aws.String("some-arn"),
+	}
+
+	deprecatedEvents := func() int {
+		events, err := k.Kubernetes.CoreV1().Events("").List(context.Background(), metav1.ListOptions{})
+		g.Expect(err).NotTo(gomega.HaveOccurred())
+		var count int
+		for _, e := range events.Items {
+			if e.Reason == string(kubeprovider.DeprecatedInstanceTypeEvent) {
+				count++
+			}
+		}
+		return count
+	}
+
+	ec2Mock.InstanceTypes = MockTypeInfo(
+		MockInstanceTypeInfo{InstanceType: "m3.medium", VCpus: 1, MemoryMib: 3840, Arch: "x86_64"},
+		MockInstanceTypeInfo{InstanceType: "m5.large", VCpus: 2, MemoryMib: 8192, Arch: "x86_64"},
+	)
+	ec2Mock.InstanceTypes[0].CurrentGeneration = aws.Bool(false)
+	ec2Mock.InstanceTypes[1].CurrentGeneration = aws.Bool(true)
+
+	// previous generation types are a warning, the reconcile proceeds
+	configuration.InstanceType = "m3.medium"
+	err := ctx.CloudDiscovery()
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(status.GetConditionStatus(v1alpha1.DeprecatedInstanceType)).To(gomega.Equal(corev1.ConditionTrue))
+	g.Expect(status.GetConditionMessage(v1alpha1.DeprecatedInstanceType)).To(gomega.Equal("instance types m3.medium are previous generation"))
+	g.Expect(deprecatedEvents()).To(gomega.Equal(1))
+
+	// the same warning is not published again
+	err = ctx.CloudDiscovery()
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(deprecatedEvents()).To(gomega.Equal(1))
+
+	configuration.InstanceType = "m5.large"
+	err = ctx.CloudDiscovery()
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(status.GetConditionStatus(v1alpha1.DeprecatedInstanceType)).To(gomega.Equal(corev1.ConditionUnknown))
+}
+
 func TestLaunchConfigDeletion(t *testing.T) {
 	var (
 		g       = gomega.NewGomegaWithT(t)
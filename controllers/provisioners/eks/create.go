@@ -27,12 +27,21 @@ import (
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/autoscaling"
 	"github.com/keikoproj/instance-manager/controllers/common"
+	corev1 "k8s.io/api/core/v1"
 )
 
+// CreateConditionTypes are the conditions reflecting the progress of Create, in the order the resources are created
+var CreateConditionTypes = []v1alpha1.InstanceGroupConditionType{
+	v1alpha1.RoleCreated,
+	v1alpha1.ScalingConfigurationCreated,
+	v1alpha1.ScalingGroupCreated,
+}
+
 func (ctx *EksInstanceGroupContext) Create() error {
 	var (
 		instanceGroup   = ctx.GetInstanceGroup()
 		state           = ctx.GetDiscoveredState()
+		status          = instanceGroup.GetStatus()
 		scalingConfig   = state.GetScalingConfiguration()
 		configuration   = instanceGroup.GetEKSConfiguration()
 		args            = ctx.GetBootstrapArgs()
@@ -42,20 +51,33 @@ func (ctx *EksInstanceGroupContext) Create() error {
 		mounts          = ctx.GetMountOpts()
 		userData        = ctx.GetBasicUserData(clusterName, args, kubeletArgs, userDataPayload, mounts)
 		sgs             = ctx.ResolveSecurityGroups()
-		spotPrice       = configuration.GetSpotPrice()
+		spotPrice       = ctx.GetSpotPrice()
 		placement       = configuration.GetPlacement()
 		metadataOptions = configuration.GetMetadataOptions()
 	)
 
 	ctx.SetState(v1alpha1.ReconcileModifying)
+	ctx.SetRenderedUserData(userData)
+	ctx.InitCreateConditions()
 
 	// no need to create a role if one is already provided
 	err := ctx.CreateManagedRole()
 	if err != nil {
 		return errors.Wrap(err, "failed to create scaling group role")
 	}
+	status.SetCondition(v1alpha1.NewInstanceGroupCondition(v1alpha1.RoleCreated, corev1.ConditionTrue))
 	instanceProfile := state.GetInstanceProfile()
 
+	// requeue instead of blocking while a new instance-profile propagates
+	if !ctx.AwsWorker.IsInstanceProfilePropagated(instanceProfile) {
+		ctx.Log.Info("waiting for instance-profile propagation", "instancegroup", instanceGroup.NamespacedName(), "instanceprofile", aws.StringValue(instanceProfile.InstanceProfileName))
+		return nil
+	}
+
+	if ctx.HasExistingLaunchTemplate() && !scalingConfig.Provisioned() {
+		return errors.Errorf("launch template %v does not exist", configuration.GetExistingLaunchTemplateName())
+	}
+
 	var configName = scalingConfig.Name()
 
 	if common.StringEmpty(configName) {
@@ -63,34 +85,63 @@ func (ctx *EksInstanceGroupContext) Create() error {
 	}
 
 	config := &scaling.CreateConfigurationInput{
-		Name:                  configName,
-		IamInstanceProfileArn: aws.StringValue(instanceProfile.Arn),
-		ImageId:               configuration.Image,
-		InstanceType:          configuration.InstanceType,
-		KeyName:               configuration.KeyPairName,
-		SecurityGroups:        sgs,
-		Volumes:               configuration.Volumes,
-		UserData:              userData,
-		SpotPrice:             spotPrice,
-		LicenseSpecifications: configuration.LicenseSpecifications,
-		Placement:             placement,
-		MetadataOptions:       metadataOptions,
+		Name:                          configName,
+		IamInstanceProfileArn:         aws.StringValue(instanceProfile.Arn),
+		ImageId:                       configuration.Image,
+		InstanceType:                  configuration.InstanceType,
+		KeyName:                       configuration.KeyPairName,
+		SecurityGroups:                sgs,
+		Volumes:                       configuration.Volumes,
+		UserData:                      userData,
+		SpotPrice:                     spotPrice,
+		LicenseSpecifications:         configuration.LicenseSpecifications,
+		Placement:                     placement,
+		MetadataOptions:               metadataOptions,
+		CapacityReservationId:         configuration.GetCapacityReservationId(),
+		CapacityReservationPreference: configuration.GetCapacityReservationPreference(),
+		VolumeTags:                    ctx.GetVolumeTags(),
 	}
+	ctx.SetLaunchTemplateOwnership(config)
 
 	if err := scalingConfig.Create(config); err != nil {
 		return errors.Wrap(err, "failed to create scaling configuration")
 	}
+	status.SetCondition(v1alpha1.NewInstanceGroupCondition(v1alpha1.ScalingConfigurationCreated, corev1.ConditionTrue))
+
+	if _, err := ctx.UpdateOverrideTemplates(config); err != nil {
+		return errors.Wrap(err, "failed to create override launch templates")
+	}
 
 	// create scaling group
 	err = ctx.CreateScalingGroup(configName)
 	if err != nil {
 		return errors.Wrap(err, "failed to create scaling group")
 	}
+	status.SetCondition(v1alpha1.NewInstanceGroupCondition(v1alpha1.ScalingGroupCreated, corev1.ConditionTrue))
 
 	ctx.SetState(v1alpha1.ReconcileModified)
 	return nil
 }
 
+// InitCreateConditions sets the create conditions which are not yet set to false, so that the status shows which resources
+// are still pending
+func (ctx *EksInstanceGroupContext) InitCreateConditions() {
+	status := ctx.GetInstanceGroup().GetStatus()
+	for _, t := range CreateConditionTypes {
+		if status.GetConditionStatus(t) == corev1.ConditionUnknown {
+			status.SetCondition(v1alpha1.NewInstanceGroupCondition(t, corev1.ConditionFalse))
+		}
+	}
+}
+
+// RemoveCreateConditions removes the create conditions once the instance group's resources exist
+func (ctx *EksInstanceGroupContext) RemoveCreateConditions() {
+	status := ctx.GetInstanceGroup().GetStatus()
+	for _, t := range CreateConditionTypes {
+		status.RemoveCondition(t)
+	}
+}
+
 func (ctx *EksInstanceGroupContext) CreateScalingGroup(name string) error {
 	var (
 		instanceGroup = ctx.GetInstanceGroup()
@@ -107,12 +158,41 @@ func (ctx *EksInstanceGroupContext) CreateScalingGroup(name string) error {
 	}
 
 	input := &autoscaling.CreateAutoScalingGroupInput{
-		AutoScalingGroupName: aws.String(asgName),
-		DesiredCapacity:      aws.Int64(spec.GetMinSize()),
-		MinSize:              aws.Int64(spec.GetMinSize()),
-		MaxSize:              aws.Int64(spec.GetMaxSize()),
-		VPCZoneIdentifier:    aws.String(common.ConcatenateList(ctx.ResolveSubnets(), ",")),
-		Tags:                 tags,
+		AutoScalingGroupName:             aws.String(asgName),
+		DesiredCapacity:                  aws.Int64(spec.GetMinSize()),
+		MinSize:                          aws.Int64(spec.GetMinSize()),
+		MaxSize:                          aws.Int64(spec.GetMaxSize()),
+		VPCZoneIdentifier:                aws.String(common.ConcatenateList(state.GetSubnets(), ",")),
+		Tags:                             tags,
+		CapacityRebalance:                configuration.GetCapacityRebalance(),
+		NewInstancesProtectedFromScaleIn: configuration.GetNewInstancesProtectedFromScaleIn(),
+	}
+
+	if healthCheckType := configuration.GetHealthCheckType(); !common.StringEmpty(healthCheckType) {
+		input.HealthCheckType = aws.String(healthCheckType)
+	}
+
+	if gracePeriod := configuration.GetHealthCheckGracePeriod(); gracePeriod > 0 {
+		input.HealthCheckGracePeriod = aws.Int64(gracePeriod)
+	}
+
+	if cooldown := configuration.GetDefaultCooldown(); cooldown > 0 {
+		input.DefaultCooldown = aws.Int64(cooldown)
+	}
+
+	if policy := configuration.GetMaintenancePolicy(); policy != nil {
+		input.InstanceMaintenancePolicy = &autoscaling.InstanceMaintenancePolicy{
+			MinHealthyPercentage: policy.MinHealthyPercentage,
+			MaxHealthyPercentage: policy.MaxHealthyPercentage,
+		}
+	}
+
+	if arns := configuration.GetTargetGroupARNs(); !common.SliceEmpty(arns) {
+		input.TargetGroupARNs = aws.StringSlice(arns)
+	}
+
+	if names := configuration.GetLoadBalancerNames(); !common.SliceEmpty(names) {
+		input.LoadBalancerNames = aws.StringSlice(names)
 	}
 
 	if spec.IsLaunchConfiguration() {
@@ -122,11 +202,15 @@ func (ctx *EksInstanceGroupContext) CreateScalingGroup(name string) error {
 
 	if spec.IsLaunchTemplate() {
 		if policy := configuration.GetMixedInstancesPolicy(); policy != nil {
-			input.MixedInstancesPolicy = ctx.GetDesiredMixedInstancesPolicy(name)
+			desiredPolicy, err := ctx.GetDesiredMixedInstancesPolicy(name)
+			if err != nil {
+				return errors.Wrap(err, "failed to get mixed instances policy")
+			}
+			input.MixedInstancesPolicy = desiredPolicy
 		} else {
 			input.LaunchTemplate = &autoscaling.LaunchTemplateSpecification{
 				LaunchTemplateName: aws.String(name),
-				Version:            aws.String("$Latest"),
+				Version:            aws.String(ctx.GetLaunchTemplateVersion()),
 			}
 		}
 		status.SetActiveLaunchTemplateName(name)
@@ -138,6 +222,9 @@ func (ctx *EksInstanceGroupContext) CreateScalingGroup(name string) error {
 	}
 
 	ctx.Log.Info("created scaling group", "instancegroup", instanceGroup.NamespacedName(), "scalinggroup", asgName)
+	status.SetAttachedTargetGroupARNs(configuration.GetTargetGroupARNs())
+	status.SetAttachedLoadBalancerNames(configuration.GetLoadBalancerNames())
+	status.SetWeightedCapacityUnit(configuration.GetMixedInstancesPolicy().GetWeightedCapacityUnit())
 
 	if err := ctx.UpdateScalingProcesses(asgName); err != nil {
 		return err
@@ -158,22 +245,19 @@ func (ctx *EksInstanceGroupContext) CreateScalingGroup(name string) error {
 func (ctx *EksInstanceGroupContext) CreateManagedRole() error {
 	var (
 		instanceGroup = ctx.GetInstanceGroup()
+		status        = instanceGroup.GetStatus()
 		state         = ctx.GetDiscoveredState()
 		configuration = instanceGroup.GetEKSConfiguration()
-		roleName      = ctx.ResourcePrefix
+		roleName      = ctx.GetManagedRoleName()
+		profileName   = ctx.GetManagedInstanceProfileName()
 	)
 
-	if configuration.HasExistingRole() {
+	if configuration.HasExistingRole() || ctx.HasSharedInstanceProfile() {
 		// avoid updating if using an existing role
 		return nil
 	}
 
-	if len(roleName) > 63 {
-		// use a hash of the actual name in case we exceed the max length
-		roleName = common.StringMD5(roleName)
-	}
-
-	role, profile, err := ctx.AwsWorker.CreateScalingGroupRole(roleName)
+	role, profile, err := ctx.AwsWorker.CreateScalingGroupRole(roleName, profileName, ctx.GetIAMTags())
 	if err != nil {
 		return errors.Wrap(err, "failed to create scaling group role")
 	}
@@ -185,6 +269,16 @@ func (ctx *EksInstanceGroupContext) CreateManagedRole() error {
 
 	ctx.Log.Info("reconciled managed role", "instancegroup", instanceGroup.NamespacedName(), "iamrole", roleName)
 
+	// an instance-profile created under a previous name is retired, it is deleted once the nodes are rotated
+	if previous := status.GetInstanceProfileName(); !common.StringEmpty(previous) && previous != profileName {
+		retired := status.GetRetiredInstanceProfileNames()
+		if !common.ContainsString(retired, previous) {
+			status.SetRetiredInstanceProfileNames(append(retired, previous))
+		}
+		ctx.Log.Info("retired instance-profile", "instancegroup", instanceGroup.NamespacedName(), "instanceprofile", previous)
+	}
+	status.SetInstanceProfileName(profileName)
+
 	state.SetRole(role)
 	state.SetInstanceProfile(profile)
 
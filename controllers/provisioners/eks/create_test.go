@@ -18,7 +18,9 @@ package eks
 import (
 	"fmt"
 	"testing"
+	"time"
 
+	awsprovider "github.com/keikoproj/instance-manager/controllers/providers/aws"
 	kubeprovider "github.com/keikoproj/instance-manager/controllers/providers/kubernetes"
 	"github.com/keikoproj/instance-manager/controllers/provisioners/eks/scaling"
 
@@ -28,8 +30,10 @@ import (
 	"github.com/aws/aws-sdk-go/service/ec2"
 	"github.com/aws/aws-sdk-go/service/iam"
 	"github.com/keikoproj/instance-manager/api/instancemgr/v1alpha1"
+	"github.com/keikoproj/instance-manager/controllers/provisioners"
 	"github.com/onsi/gomega"
 	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
 )
 
 func TestCreateManagedRolePositive(t *testing.T) {
@@ -73,6 +77,226 @@ func TestCreateManagedRolePositive(t *testing.T) {
 	g.Expect(ctx.GetState()).To(gomega.Equal(v1alpha1.ReconcileModified))
 }
 
+func TestCreateManagedRoleWithTags(t *testing.T) {
+	var (
+		g       = gomega.NewGomegaWithT(t)
+		k       = MockKubernetesClientSet()
+		ig      = MockInstanceGroup()
+		asgMock = NewAutoScalingMocker()
+		iamMock = NewIamMocker()
+		eksMock = NewEksMocker()
+		ec2Mock = NewEc2Mocker()
+		ssmMock = NewSsmMocker()
+	)
+
+	w := MockAwsWorker(asgMock, iamMock, eksMock, ec2Mock, ssmMock)
+	ctx := MockContext(ig, k, w)
+
+	ig.GetEKSConfiguration().Tags = []map[string]string{
+		{
+			"key":   "team",
+			"value": "platform",
+		},
+	}
+
+	// Mock role/profile do not exist so they are always created
+	iamMock.GetRoleErr = errors.New("not found")
+	iamMock.GetInstanceProfileErr = errors.New("not found")
+
+	expectedTags := []*iam.Tag{
+		{Key: aws.String(provisioners.TagClusterName), Value: aws.String(ig.GetEKSConfiguration().GetClusterName())},
+		{Key: aws.String(provisioners.TagInstanceGroupNamespace), Value: aws.String(ig.GetNamespace())},
+		{Key: aws.String(provisioners.TagInstanceGroupName), Value: aws.String(ig.GetName())},
+		{Key: aws.String("team"), Value: aws.String("platform")},
+	}
+
+	err := ctx.CreateManagedRole()
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(iamMock.CreateRoleInput).NotTo(gomega.BeNil())
+	g.Expect(iamMock.CreateRoleInput.Tags).To(gomega.Equal(expectedTags))
+	g.Expect(iamMock.CreateInstanceProfileInput).NotTo(gomega.BeNil())
+	g.Expect(iamMock.CreateInstanceProfileInput.Tags).To(gomega.Equal(expectedTags))
+}
+
+func TestGetIAMTags(t *testing.T) {
+	var (
+		g       = gomega.NewGomegaWithT(t)
+		k       = MockKubernetesClientSet()
+		ig      = MockInstanceGroup()
+		asgMock = NewAutoScalingMocker()
+		iamMock = NewIamMocker()
+		eksMock = NewEksMocker()
+		ec2Mock = NewEc2Mocker()
+		ssmMock = NewSsmMocker()
+	)
+
+	w := MockAwsWorker(asgMock, iamMock, eksMock, ec2Mock, ssmMock)
+	ctx := MockContext(ig, k, w)
+
+	// custom tags reusing a key of another tag are skipped
+	ig.GetEKSConfiguration().Tags = []map[string]string{
+		{"key": provisioners.TagInstanceGroupName, "value": "other-name"},
+		{"key": "team", "value": "platform"},
+		{"key": "Team", "value": "other-team"},
+	}
+	tags := ctx.GetIAMTags()
+	g.Expect(tags).To(gomega.HaveLen(4))
+	g.Expect(aws.StringValue(tags[2].Value)).To(gomega.Equal(ig.GetName()))
+	g.Expect(aws.StringValue(tags[3].Value)).To(gomega.Equal("platform"))
+
+	// custom tags beyond the IAM tag limit are not applied
+	ig.GetEKSConfiguration().Tags = make([]map[string]string, 0)
+	for i := 0; i < v1alpha1.TagMaxCount; i++ {
+		ig.GetEKSConfiguration().Tags = append(ig.GetEKSConfiguration().Tags, map[string]string{"key": fmt.Sprintf("tag-%v", i), "value": "value"})
+	}
+	g.Expect(ctx.GetIAMTags()).To(gomega.HaveLen(v1alpha1.TagMaxCount))
+}
+
+func TestGetVolumeTags(t *testing.T) {
+	var (
+		g       = gomega.NewGomegaWithT(t)
+		k       = MockKubernetesClientSet()
+		ig      = MockInstanceGroup()
+		asgMock = NewAutoScalingMocker()
+		iamMock = NewIamMocker()
+		eksMock = NewEksMocker()
+		ec2Mock = NewEc2Mocker()
+		ssmMock = NewSsmMocker()
+	)
+
+	w := MockAwsWorker(asgMock, iamMock, eksMock, ec2Mock, ssmMock)
+	ctx := MockContext(ig, k, w)
+
+	ig.GetEKSConfiguration().Tags = []map[string]string{
+		{"key": "team", "value": "platform"},
+		{"key": "cost-center", "value": "1234"},
+	}
+	g.Expect(ctx.GetVolumeTags()).To(gomega.Equal(map[string]string{"team": "platform", "cost-center": "1234"}))
+}
+
+func TestCreateWithInstanceProfilePropagation(t *testing.T) {
+	var (
+		g       = gomega.NewGomegaWithT(t)
+		k       = MockKubernetesClientSet()
+		ig      = MockInstanceGroup()
+		asgMock = NewAutoScalingMocker()
+		iamMock = NewIamMocker()
+		eksMock = NewEksMocker()
+		ec2Mock = NewEc2Mocker()
+		ssmMock = NewSsmMocker()
+	)
+
+	w := MockAwsWorker(asgMock, iamMock, eksMock, ec2Mock, ssmMock)
+	ctx := MockContext(ig, k, w)
+	state := ctx.GetDiscoveredState()
+	state.SetCluster(MockEksCluster("1.15"))
+	state.Publisher.Client = k.Kubernetes
+	state.ScalingConfiguration = &scaling.LaunchConfiguration{
+		AwsWorker: w,
+	}
+
+	propagationDelay := awsprovider.DefaultInstanceProfilePropagationDelay
+	awsprovider.DefaultInstanceProfilePropagationDelay = time.Hour
+	defer func() {
+		awsprovider.DefaultInstanceProfilePropagationDelay = propagationDelay
+	}()
+
+	iamMock.GetRoleErr = errors.New("not found")
+	iamMock.GetInstanceProfileErr = errors.New("not found")
+	iamMock.Role = &iam.Role{RoleName: aws.String("some-role")}
+	iamMock.InstanceProfile = &iam.InstanceProfile{
+		InstanceProfileName: aws.String("some-profile"),
+		Arn:                 aws.String("some-profile-arn"),
+		CreateDate:          aws.Time(time.Now()),
+	}
+
+	// reconcile should not block while the instance-profile propagates
+	start := time.Now()
+	err := ctx.Create()
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(time.Since(start)).To(gomega.BeNumerically("<", time.Second))
+	g.Expect(ctx.GetState()).To(gomega.Equal(v1alpha1.ReconcileModifying))
+	g.Expect(provisioners.IsRetryable(ig)).To(gomega.BeTrue())
+	g.Expect(asgMock.CreateAutoScalingGroupInput).To(gomega.BeNil())
+
+	// once propagated, the scaling group is created
+	iamMock.InstanceProfile.CreateDate = aws.Time(time.Now().Add(-2 * time.Hour))
+	err = ctx.Create()
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(ctx.GetState()).To(gomega.Equal(v1alpha1.ReconcileModified))
+	g.Expect(asgMock.CreateAutoScalingGroupInput).NotTo(gomega.BeNil())
+}
+
+func TestCreateConditions(t *testing.T) {
+	var (
+		g       = gomega.NewGomegaWithT(t)
+		k       = MockKubernetesClientSet()
+		ig      = MockInstanceGroup()
+		asgMock = NewAutoScalingMocker()
+		iamMock = NewIamMocker()
+		eksMock = NewEksMocker()
+		ec2Mock = NewEc2Mocker()
+		ssmMock = NewSsmMocker()
+		status  = ig.GetStatus()
+	)
+
+	w := MockAwsWorker(asgMock, iamMock, eksMock, ec2Mock, ssmMock)
+	ctx := MockContext(ig, k, w)
+	state := ctx.GetDiscoveredState()
+	state.SetCluster(MockEksCluster("1.15"))
+	state.Publisher.Client = k.Kubernetes
+	state.ScalingConfiguration = &scaling.LaunchConfiguration{
+		AwsWorker: w,
+	}
+
+	propagationDelay := awsprovider.DefaultInstanceProfilePropagationDelay
+	awsprovider.DefaultInstanceProfilePropagationDelay = time.Hour
+	defer func() {
+		awsprovider.DefaultInstanceProfilePropagationDelay = propagationDelay
+	}()
+
+	iamMock.GetRoleErr = errors.New("not found")
+	iamMock.GetInstanceProfileErr = errors.New("not found")
+	iamMock.Role = &iam.Role{RoleName: aws.String("some-role")}
+	iamMock.InstanceProfile = &iam.InstanceProfile{
+		InstanceProfileName: aws.String("some-profile"),
+		Arn:                 aws.String("some-profile-arn"),
+		CreateDate:          aws.Time(time.Now()),
+	}
+
+	expectConditions := func(role, config, group corev1.ConditionStatus) {
+		g.Expect(status.GetConditionStatus(v1alpha1.RoleCreated)).To(gomega.Equal(role))
+		g.Expect(status.GetConditionStatus(v1alpha1.ScalingConfigurationCreated)).To(gomega.Equal(config))
+		g.Expect(status.GetConditionStatus(v1alpha1.ScalingGroupCreated)).To(gomega.Equal(group))
+	}
+
+	// role is created, instance-profile is still propagating
+	err := ctx.Create()
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	expectConditions(corev1.ConditionTrue, corev1.ConditionFalse, corev1.ConditionFalse)
+
+	// scaling configuration is created, scaling group creation fails
+	iamMock.InstanceProfile.CreateDate = aws.Time(time.Now().Add(-2 * time.Hour))
+	asgMock.CreateAutoScalingGroupErr = errors.New("some error")
+	err = ctx.Create()
+	g.Expect(err).To(gomega.HaveOccurred())
+	expectConditions(corev1.ConditionTrue, corev1.ConditionTrue, corev1.ConditionFalse)
+
+	// scaling group is created
+	asgMock.CreateAutoScalingGroupErr = nil
+	err = ctx.Create()
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	expectConditions(corev1.ConditionTrue, corev1.ConditionTrue, corev1.ConditionTrue)
+
+	// conditions are not duplicated when create is retried
+	err = ctx.Create()
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(status.GetConditions()).To(gomega.HaveLen(len(CreateConditionTypes)))
+
+	ctx.RemoveCreateConditions()
+	expectConditions(corev1.ConditionUnknown, corev1.ConditionUnknown, corev1.ConditionUnknown)
+}
+
 func TestCreateLaunchConfigurationPositive(t *testing.T) {
 	var (
 		g       = gomega.NewGomegaWithT(t)
@@ -183,6 +407,296 @@ func TestCreateScalingGroupPositive(t *testing.T) {
 	g.Expect(ctx.GetState()).To(gomega.Equal(v1alpha1.ReconcileModified))
 }
 
+func TestCreateScalingGroupWithCapacityRebalance(t *testing.T) {
+	var (
+		g       = gomega.NewGomegaWithT(t)
+		k       = MockKubernetesClientSet()
+		ig      = MockInstanceGroup()
+		asgMock = NewAutoScalingMocker()
+		iamMock = NewIamMocker()
+		eksMock = NewEksMocker()
+		ec2Mock = NewEc2Mocker()
+		ssmMock = NewSsmMocker()
+	)
+
+	w := MockAwsWorker(asgMock, iamMock, eksMock, ec2Mock, ssmMock)
+	ctx := MockContext(ig, k, w)
+
+	// skip role creation
+	ig.GetEKSConfiguration().SetInstanceProfileName("some-profile")
+	ig.GetEKSConfiguration().SetRoleName("some-role")
+	ig.GetEKSConfiguration().CapacityRebalance = aws.Bool(true)
+
+	ctx.SetDiscoveredState(&DiscoveredState{
+		Publisher: kubeprovider.EventPublisher{
+			Client: k.Kubernetes,
+		},
+		Cluster: MockEksCluster(""),
+		ScalingConfiguration: &scaling.LaunchConfiguration{
+			AwsWorker: w,
+		},
+	})
+
+	err := ctx.CreateScalingGroup("some-launch-configuration")
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(asgMock.CreateAutoScalingGroupInput).NotTo(gomega.BeNil())
+	g.Expect(aws.BoolValue(asgMock.CreateAutoScalingGroupInput.CapacityRebalance)).To(gomega.BeTrue())
+
+	// existing scaling group without capacity rebalance is updated
+	mockScalingGroup := MockScalingGroup("some-scaling-group", false)
+	ctx.GetDiscoveredState().SetScalingGroup(mockScalingGroup)
+	g.Expect(ctx.ScalingGroupUpdateNeeded("some-launch-configuration")).To(gomega.BeTrue())
+
+	_, err = ctx.UpdateScalingGroup("some-launch-configuration", &ctx.GetDiscoveredState().ScalingConfiguration)
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(aws.BoolValue(asgMock.UpdateAutoScalingGroupInput.CapacityRebalance)).To(gomega.BeTrue())
+}
+
+func TestCreateScalingGroupWithScaleInProtection(t *testing.T) {
+	var (
+		g       = gomega.NewGomegaWithT(t)
+		k       = MockKubernetesClientSet()
+		ig      = MockInstanceGroup()
+		asgMock = NewAutoScalingMocker()
+		iamMock = NewIamMocker()
+		eksMock = NewEksMocker()
+		ec2Mock = NewEc2Mocker()
+		ssmMock = NewSsmMocker()
+	)
+
+	w := MockAwsWorker(asgMock, iamMock, eksMock, ec2Mock, ssmMock)
+	ctx := MockContext(ig, k, w)
+
+	// skip role creation
+	ig.GetEKSConfiguration().SetInstanceProfileName("some-profile")
+	ig.GetEKSConfiguration().SetRoleName("some-role")
+	ig.GetEKSConfiguration().NewInstancesProtectedFromScaleIn = aws.Bool(true)
+
+	ctx.SetDiscoveredState(&DiscoveredState{
+		Publisher: kubeprovider.EventPublisher{
+			Client: k.Kubernetes,
+		},
+		Cluster: MockEksCluster(""),
+		ScalingConfiguration: &scaling.LaunchConfiguration{
+			AwsWorker: w,
+		},
+	})
+
+	err := ctx.CreateScalingGroup("some-launch-configuration")
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(asgMock.CreateAutoScalingGroupInput).NotTo(gomega.BeNil())
+	g.Expect(aws.BoolValue(asgMock.CreateAutoScalingGroupInput.NewInstancesProtectedFromScaleIn)).To(gomega.BeTrue())
+
+	// existing scaling group without scale-in protection is updated
+	mockScalingGroup := MockScalingGroup("some-scaling-group", false)
+	mockScalingGroup.NewInstancesProtectedFromScaleIn = aws.Bool(false)
+	ctx.GetDiscoveredState().SetScalingGroup(mockScalingGroup)
+	g.Expect(ctx.ScalingGroupUpdateNeeded("some-launch-configuration")).To(gomega.BeTrue())
+
+	_, err = ctx.UpdateScalingGroup("some-launch-configuration", &ctx.GetDiscoveredState().ScalingConfiguration)
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(aws.BoolValue(asgMock.UpdateAutoScalingGroupInput.NewInstancesProtectedFromScaleIn)).To(gomega.BeTrue())
+}
+
+func TestCreateScalingGroupWithLoadBalancers(t *testing.T) {
+	var (
+		g       = gomega.NewGomegaWithT(t)
+		k       = MockKubernetesClientSet()
+		ig      = MockInstanceGroup()
+		asgMock = NewAutoScalingMocker()
+		iamMock = NewIamMocker()
+		eksMock = NewEksMocker()
+		ec2Mock = NewEc2Mocker()
+		ssmMock = NewSsmMocker()
+	)
+
+	w := MockAwsWorker(asgMock, iamMock, eksMock, ec2Mock, ssmMock)
+	ctx := MockContext(ig, k, w)
+
+	tg := "arn:aws:elasticloadbalancing:us-west-2:123456789012:targetgroup/tg-1/1234567890123456"
+	ig.GetEKSConfiguration().SetInstanceProfileName("some-profile")
+	ig.GetEKSConfiguration().SetRoleName("some-role")
+	ig.GetEKSConfiguration().SetTargetGroupARNs([]string{tg})
+	ig.GetEKSConfiguration().SetLoadBalancerNames([]string{"lb-1"})
+
+	ctx.SetDiscoveredState(&DiscoveredState{
+		Publisher: kubeprovider.EventPublisher{
+			Client: k.Kubernetes,
+		},
+		Cluster: MockEksCluster(""),
+		ScalingConfiguration: &scaling.LaunchConfiguration{
+			AwsWorker: w,
+		},
+	})
+
+	// attachments made at creation are tracked so that they can be detached later
+	err := ctx.CreateScalingGroup("some-launch-configuration")
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(aws.StringValueSlice(asgMock.CreateAutoScalingGroupInput.TargetGroupARNs)).To(gomega.Equal([]string{tg}))
+	g.Expect(aws.StringValueSlice(asgMock.CreateAutoScalingGroupInput.LoadBalancerNames)).To(gomega.Equal([]string{"lb-1"}))
+	g.Expect(ig.GetStatus().GetAttachedTargetGroupARNs()).To(gomega.Equal([]string{tg}))
+	g.Expect(ig.GetStatus().GetAttachedLoadBalancerNames()).To(gomega.Equal([]string{"lb-1"}))
+}
+
+func TestCreateScalingGroupWithHealthCheck(t *testing.T) {
+	var (
+		g       = gomega.NewGomegaWithT(t)
+		k       = MockKubernetesClientSet()
+		ig      = MockInstanceGroup()
+		asgMock = NewAutoScalingMocker()
+		iamMock = NewIamMocker()
+		eksMock = NewEksMocker()
+		ec2Mock = NewEc2Mocker()
+		ssmMock = NewSsmMocker()
+	)
+
+	w := MockAwsWorker(asgMock, iamMock, eksMock, ec2Mock, ssmMock)
+	ctx := MockContext(ig, k, w)
+
+	// skip role creation
+	ig.GetEKSConfiguration().SetInstanceProfileName("some-profile")
+	ig.GetEKSConfiguration().SetRoleName("some-role")
+	ig.GetEKSConfiguration().HealthCheckType = "ELB"
+	ig.GetEKSConfiguration().HealthCheckGracePeriod = 120
+
+	ctx.SetDiscoveredState(&DiscoveredState{
+		Publisher: kubeprovider.EventPublisher{
+			Client: k.Kubernetes,
+		},
+		Cluster: MockEksCluster(""),
+		ScalingConfiguration: &scaling.LaunchConfiguration{
+			AwsWorker: w,
+		},
+	})
+
+	err := ctx.CreateScalingGroup("some-launch-configuration")
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(aws.StringValue(asgMock.CreateAutoScalingGroupInput.HealthCheckType)).To(gomega.Equal("ELB"))
+	g.Expect(aws.Int64Value(asgMock.CreateAutoScalingGroupInput.HealthCheckGracePeriod)).To(gomega.Equal(int64(120)))
+
+	// existing scaling group with EC2 health checks is updated
+	mockScalingGroup := MockScalingGroup("some-scaling-group", false)
+	mockScalingGroup.HealthCheckType = aws.String("EC2")
+	mockScalingGroup.HealthCheckGracePeriod = aws.Int64(300)
+	ctx.GetDiscoveredState().SetScalingGroup(mockScalingGroup)
+	g.Expect(ctx.ScalingGroupUpdateNeeded("some-launch-configuration")).To(gomega.BeTrue())
+
+	_, err = ctx.UpdateScalingGroup("some-launch-configuration", &ctx.GetDiscoveredState().ScalingConfiguration)
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(aws.StringValue(asgMock.UpdateAutoScalingGroupInput.HealthCheckType)).To(gomega.Equal("ELB"))
+	g.Expect(aws.Int64Value(asgMock.UpdateAutoScalingGroupInput.HealthCheckGracePeriod)).To(gomega.Equal(int64(120)))
+}
+
+func TestCreateScalingGroupWithDefaultCooldown(t *testing.T) {
+	var (
+		g       = gomega.NewGomegaWithT(t)
+		k       = MockKubernetesClientSet()
+		ig      = MockInstanceGroup()
+		asgMock = NewAutoScalingMocker()
+		iamMock = NewIamMocker()
+		eksMock = NewEksMocker()
+		ec2Mock = NewEc2Mocker()
+		ssmMock = NewSsmMocker()
+	)
+
+	w := MockAwsWorker(asgMock, iamMock, eksMock, ec2Mock, ssmMock)
+	ctx := MockContext(ig, k, w)
+
+	// skip role creation
+	ig.GetEKSConfiguration().SetInstanceProfileName("some-profile")
+	ig.GetEKSConfiguration().SetRoleName("some-role")
+	ig.GetEKSConfiguration().DefaultCooldown = 60
+
+	ctx.SetDiscoveredState(&DiscoveredState{
+		Publisher: kubeprovider.EventPublisher{
+			Client: k.Kubernetes,
+		},
+		Cluster: MockEksCluster(""),
+		ScalingConfiguration: &scaling.LaunchConfiguration{
+			AwsWorker: w,
+		},
+	})
+
+	err := ctx.CreateScalingGroup("some-launch-configuration")
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(aws.Int64Value(asgMock.CreateAutoScalingGroupInput.DefaultCooldown)).To(gomega.Equal(int64(60)))
+
+	// existing scaling group with the AWS default cooldown is updated
+	mockScalingGroup := MockScalingGroup("some-scaling-group", false)
+	mockScalingGroup.DefaultCooldown = aws.Int64(300)
+	ctx.GetDiscoveredState().SetScalingGroup(mockScalingGroup)
+	g.Expect(ctx.ScalingGroupUpdateNeeded("some-launch-configuration")).To(gomega.BeTrue())
+
+	_, err = ctx.UpdateScalingGroup("some-launch-configuration", &ctx.GetDiscoveredState().ScalingConfiguration)
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(aws.Int64Value(asgMock.UpdateAutoScalingGroupInput.DefaultCooldown)).To(gomega.Equal(int64(60)))
+
+	// cooldown is left to AWS when unset
+	ig.GetEKSConfiguration().DefaultCooldown = 0
+	_, err = ctx.UpdateScalingGroup("some-launch-configuration", &ctx.GetDiscoveredState().ScalingConfiguration)
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(asgMock.UpdateAutoScalingGroupInput.DefaultCooldown).To(gomega.BeNil())
+}
+
+func TestCreateScalingGroupWithMaintenancePolicy(t *testing.T) {
+	var (
+		g       = gomega.NewGomegaWithT(t)
+		k       = MockKubernetesClientSet()
+		ig      = MockInstanceGroup()
+		asgMock = NewAutoScalingMocker()
+		iamMock = NewIamMocker()
+		eksMock = NewEksMocker()
+		ec2Mock = NewEc2Mocker()
+		ssmMock = NewSsmMocker()
+	)
+
+	w := MockAwsWorker(asgMock, iamMock, eksMock, ec2Mock, ssmMock)
+	ctx := MockContext(ig, k, w)
+
+	// skip role creation
+	ig.GetEKSConfiguration().SetInstanceProfileName("some-profile")
+	ig.GetEKSConfiguration().SetRoleName("some-role")
+	ig.GetEKSConfiguration().MaintenancePolicy = &v1alpha1.MaintenancePolicySpec{
+		MinHealthyPercentage: aws.Int64(90),
+		MaxHealthyPercentage: aws.Int64(120),
+	}
+
+	ctx.SetDiscoveredState(&DiscoveredState{
+		Publisher: kubeprovider.EventPublisher{
+			Client: k.Kubernetes,
+		},
+		Cluster: MockEksCluster(""),
+		ScalingConfiguration: &scaling.LaunchConfiguration{
+			AwsWorker: w,
+		},
+	})
+
+	expectedPolicy := &autoscaling.InstanceMaintenancePolicy{
+		MinHealthyPercentage: aws.Int64(90),
+		MaxHealthyPercentage: aws.Int64(120),
+	}
+
+	err := ctx.CreateScalingGroup("some-launch-configuration")
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(asgMock.CreateAutoScalingGroupInput.InstanceMaintenancePolicy).To(gomega.Equal(expectedPolicy))
+
+	// existing scaling group without a maintenance policy is updated
+	mockScalingGroup := MockScalingGroup("some-scaling-group", false)
+	ctx.GetDiscoveredState().SetScalingGroup(mockScalingGroup)
+	g.Expect(ctx.ScalingGroupUpdateNeeded("some-launch-configuration")).To(gomega.BeTrue())
+
+	_, err = ctx.UpdateScalingGroup("some-launch-configuration", &ctx.GetDiscoveredState().ScalingConfiguration)
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(asgMock.UpdateAutoScalingGroupInput.InstanceMaintenancePolicy).To(gomega.Equal(expectedPolicy))
+
+	// existing scaling group with a different maintenance policy is updated
+	mockScalingGroup.InstanceMaintenancePolicy = &autoscaling.InstanceMaintenancePolicy{
+		MinHealthyPercentage: aws.Int64(100),
+		MaxHealthyPercentage: aws.Int64(120),
+	}
+	g.Expect(ctx.ScalingGroupUpdateNeeded("some-launch-configuration")).To(gomega.BeTrue())
+}
+
 func TestCreateNoOp(t *testing.T) {
 	var (
 		g       = gomega.NewGomegaWithT(t)
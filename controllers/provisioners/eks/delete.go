@@ -16,9 +16,15 @@ limitations under the License.
 package eks
 
 import (
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/autoscaling"
+	"github.com/aws/aws-sdk-go/service/ec2"
 	"github.com/keikoproj/instance-manager/controllers/provisioners/eks/scaling"
 
 	"github.com/keikoproj/instance-manager/api/instancemgr/v1alpha1"
+	"github.com/keikoproj/instance-manager/controllers/common"
 	kubeprovider "github.com/keikoproj/instance-manager/controllers/providers/kubernetes"
 	"github.com/pkg/errors"
 
@@ -31,6 +37,7 @@ func (ctx *EksInstanceGroupContext) Delete() error {
 		role          = state.GetRole()
 		roleARN       = aws.StringValue(role.Arn)
 		scalingConfig = state.GetScalingConfiguration()
+		configName    = scalingConfig.Name()
 	)
 
 	ctx.SetState(v1alpha1.ReconcileDeleting)
@@ -45,12 +52,24 @@ func (ctx *EksInstanceGroupContext) Delete() error {
 		return errors.Wrap(err, "failed to remove auth role")
 	}
 
-	// delete launchconfig
-	if err := scalingConfig.Delete(&scaling.DeleteConfigurationInput{
-		Prefix:    ctx.ResourcePrefix,
-		DeleteAll: true,
-	}); err != nil {
-		return errors.Wrap(err, "failed to delete launch configuration")
+	// delete launchconfig, an existing launch template is only extended and is left in place
+	if !ctx.HasExistingLaunchTemplate() {
+		if err := scalingConfig.Delete(&scaling.DeleteConfigurationInput{
+			Prefix:    ctx.ResourcePrefix,
+			DeleteAll: true,
+		}); err != nil {
+			return errors.Wrap(err, "failed to delete launch configuration")
+		}
+	}
+
+	// delete launch templates of instance type overrides
+	if err := ctx.DeleteOverrideTemplates(configName); err != nil {
+		return errors.Wrap(err, "failed to delete override launch templates")
+	}
+
+	// the scaling group is deleted, so launch templates retired by switching to an existing launch template are unused
+	if err := ctx.DeleteRetiredLaunchTemplates(nil); err != nil {
+		return errors.Wrap(err, "failed to delete retired launch templates")
 	}
 
 	// delete the managed IAM role if one was created
@@ -62,6 +81,43 @@ func (ctx *EksInstanceGroupContext) Delete() error {
 	return nil
 }
 
+// DeleteOverrideTemplates deletes instance type override launch templates of a scaling configuration, except the retained
+// ones. Only templates tagged as owned by the instance group, or named after one of its override instance types, are deleted
+func (ctx *EksInstanceGroupContext) DeleteOverrideTemplates(name string, retain ...string) error {
+	var (
+		instanceGroup = ctx.GetInstanceGroup()
+		overrideNames = make([]string, 0)
+	)
+
+	if common.StringEmpty(name) {
+		return nil
+	}
+
+	templates, err := ctx.AwsWorker.DescribeLaunchTemplates()
+	if err != nil {
+		return errors.Wrap(err, "failed to describe launch templates")
+	}
+
+	for instanceType := range ctx.GetOverrideImages() {
+		overrideNames = append(overrideNames, GetOverrideTemplateName(name, instanceType))
+	}
+
+	for _, t := range templates {
+		templateName := aws.StringValue(t.LaunchTemplateName)
+		if !strings.HasPrefix(templateName, name+"-") || common.ContainsEqualFold(retain, templateName) {
+			continue
+		}
+		if !ctx.IsOwnedLaunchTemplate(t) && !common.ContainsEqualFold(overrideNames, templateName) {
+			continue
+		}
+		if err := ctx.AwsWorker.DeleteLaunchTemplate(templateName); err != nil {
+			return errors.Wrapf(err, "failed to delete override launch template %v", templateName)
+		}
+		ctx.Log.Info("deleted override launch template", "instancegroup", instanceGroup.NamespacedName(), "launchtemplate", templateName)
+	}
+	return nil
+}
+
 func (ctx *EksInstanceGroupContext) DeleteScalingGroup() error {
 	var (
 		state         = ctx.GetDiscoveredState()
@@ -91,18 +147,82 @@ func (ctx *EksInstanceGroupContext) DeleteManagedRole() error {
 		additionalPolicies = configuration.GetManagedPolicies()
 		role               = state.GetRole()
 		roleName           = aws.StringValue(role.RoleName)
+		profileName        = ctx.GetManagedInstanceProfileName()
 	)
 
-	if !state.HasRole() || configuration.HasExistingRole() {
+	if !state.HasRole() || configuration.HasExistingRole() || ctx.HasSharedInstanceProfile() {
 		return nil
 	}
 
+	if profile := state.GetInstanceProfile(); profile != nil && profile.InstanceProfileName != nil {
+		profileName = aws.StringValue(profile.InstanceProfileName)
+	}
+
+	if err := ctx.DeleteRetiredInstanceProfiles(); err != nil {
+		return err
+	}
+
 	managedPolicies := ctx.GetManagedPoliciesList(additionalPolicies)
 
-	err := ctx.AwsWorker.DeleteScalingGroupRole(roleName, managedPolicies)
+	err := ctx.AwsWorker.DeleteScalingGroupRole(roleName, profileName, managedPolicies)
 	if err != nil {
 		return err
 	}
 	ctx.Log.Info("deleted scaling group role", "instancegroup", instanceGroup.NamespacedName(), "iamrole", roleName)
 	return nil
 }
+
+// DeleteRetiredInstanceProfiles deletes the instance-profiles which were created under a previous name
+func (ctx *EksInstanceGroupContext) DeleteRetiredInstanceProfiles() error {
+	var (
+		instanceGroup = ctx.GetInstanceGroup()
+		status        = instanceGroup.GetStatus()
+		roleName      = ctx.GetManagedRoleName()
+		retired       = status.GetRetiredInstanceProfileNames()
+	)
+
+	for len(retired) > 0 {
+		profileName := retired[0]
+		if err := ctx.AwsWorker.DeleteInstanceProfile(profileName, roleName); err != nil {
+			return errors.Wrapf(err, "failed to delete retired instance-profile %v", profileName)
+		}
+		ctx.Log.Info("deleted retired instance-profile", "instancegroup", instanceGroup.NamespacedName(), "instanceprofile", profileName)
+		retired = retired[1:]
+		status.SetRetiredInstanceProfileNames(retired)
+	}
+	return nil
+}
+
+// DeleteRetiredLaunchTemplates deletes the launch templates which were replaced by an existing launch template, once the
+// scaling group no longer references them
+func (ctx *EksInstanceGroupContext) DeleteRetiredLaunchTemplates(scalingGroup *autoscaling.Group) error {
+	var (
+		instanceGroup = ctx.GetInstanceGroup()
+		status        = instanceGroup.GetStatus()
+		retired       = status.GetRetiredLaunchTemplateNames()
+		remaining     = make([]string, 0)
+	)
+
+	// templates deleted before a failure are not found when retried
+	for _, templateName := range retired {
+		if IsLaunchTemplateReferenced(scalingGroup, templateName) {
+			remaining = append(remaining, templateName)
+			continue
+		}
+		if err := ctx.AwsWorker.DeleteLaunchTemplate(templateName); err != nil {
+			if aerr, ok := err.(awserr.Error); !ok || aerr.Code() != ec2.LaunchTemplateErrorCodeLaunchTemplateNameDoesNotExist {
+				return errors.Wrapf(err, "failed to delete retired launch template %v", templateName)
+			}
+		}
+		if err := ctx.DeleteOverrideTemplates(templateName); err != nil {
+			return errors.Wrapf(err, "failed to delete override launch templates of %v", templateName)
+		}
+		ctx.Log.Info("deleted retired launch template", "instancegroup", instanceGroup.NamespacedName(), "launchtemplate", templateName)
+	}
+
+	if len(remaining) == 0 {
+		remaining = nil
+	}
+	status.SetRetiredLaunchTemplateNames(remaining)
+	return nil
+}
@@ -22,6 +22,7 @@ import (
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/aws-sdk-go/service/autoscaling"
+	"github.com/aws/aws-sdk-go/service/ec2"
 	"github.com/aws/aws-sdk-go/service/iam"
 	awsauth "github.com/keikoproj/aws-auth/pkg/mapper"
 	"github.com/keikoproj/instance-manager/api/instancemgr/v1alpha1"
@@ -63,6 +64,70 @@ func TestDeletePositive(t *testing.T) {
 	g.Expect(ctx.GetState()).To(gomega.Equal(v1alpha1.ReconcileDeleting))
 }
 
+func TestDeleteRetiredLaunchTemplates(t *testing.T) {
+	var (
+		g             = gomega.NewGomegaWithT(t)
+		k             = MockKubernetesClientSet()
+		ig            = MockInstanceGroup()
+		spec          = ig.GetEKSSpec()
+		configuration = ig.GetEKSConfiguration()
+		status        = ig.GetStatus()
+		asgMock       = NewAutoScalingMocker()
+		iamMock       = NewIamMocker()
+		eksMock       = NewEksMocker()
+		ec2Mock       = NewEc2Mocker()
+		ssmMock       = NewSsmMocker()
+	)
+
+	w := MockAwsWorker(asgMock, iamMock, eksMock, ec2Mock, ssmMock)
+	ctx := MockContext(ig, k, w)
+
+	spec.Type = v1alpha1.LaunchTemplate
+	configuration.ExistingLaunchTemplateName = "existing-launch-template"
+	previous := ctx.ResourcePrefix + "-20230101000000"
+
+	tags := make([]*ec2.Tag, 0)
+	for key, value := range ctx.GetLaunchTemplateTags() {
+		tags = append(tags, &ec2.Tag{Key: aws.String(key), Value: aws.String(value)})
+	}
+	ec2Mock.LaunchTemplates = []*ec2.LaunchTemplate{
+		{
+			LaunchTemplateName: aws.String(previous + "-m5.xlarge"),
+			Tags:               tags,
+		},
+		{
+			LaunchTemplateName: aws.String(previous + "-other"),
+		},
+	}
+
+	// launch templates not named by the controller are not retired
+	ctx.RetireLaunchTemplate("other-launch-template")
+	ctx.RetireLaunchTemplate("existing-launch-template")
+	g.Expect(status.GetRetiredLaunchTemplateNames()).To(gomega.BeEmpty())
+
+	ctx.RetireLaunchTemplate(previous)
+	ctx.RetireLaunchTemplate(previous)
+	g.Expect(status.GetRetiredLaunchTemplateNames()).To(gomega.Equal([]string{previous}))
+
+	// the retired launch template is kept while the scaling group references it
+	scalingGroup := &autoscaling.Group{
+		LaunchTemplate: &autoscaling.LaunchTemplateSpecification{
+			LaunchTemplateName: aws.String(previous),
+		},
+	}
+	err := ctx.DeleteRetiredLaunchTemplates(scalingGroup)
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(ec2Mock.DeletedLaunchTemplateNames).To(gomega.BeEmpty())
+	g.Expect(status.GetRetiredLaunchTemplateNames()).To(gomega.Equal([]string{previous}))
+
+	// it is deleted with its owned override templates once the scaling group references the existing launch template
+	scalingGroup.LaunchTemplate.LaunchTemplateName = aws.String("existing-launch-template")
+	err = ctx.DeleteRetiredLaunchTemplates(scalingGroup)
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(ec2Mock.DeletedLaunchTemplateNames).To(gomega.Equal([]string{previous, previous + "-m5.xlarge"}))
+	g.Expect(status.GetRetiredLaunchTemplateNames()).To(gomega.BeEmpty())
+}
+
 func TestDeleteManagedRoleNegative(t *testing.T) {
 	var (
 		g             = gomega.NewGomegaWithT(t)
@@ -17,10 +17,13 @@ package eks
 
 import (
 	"fmt"
+	"regexp"
 	"sync"
+	"time"
 
 	corev1 "k8s.io/api/core/v1"
 
+	"github.com/aws/aws-sdk-go/service/autoscaling"
 	"github.com/go-logr/logr"
 
 	"github.com/keikoproj/instance-manager/api/instancemgr/v1alpha1"
@@ -40,10 +43,82 @@ const (
 	CustomNetworkingEnabledAnnotation                 = "instancemgr.keikoproj.io/custom-networking-enabled"
 	CustomNetworkingHostPodsAnnotation                = "instancemgr.keikoproj.io/custom-networking-host-pods"
 	CustomNetworkingPrefixAssignmentEnabledAnnotation = "instancemgr.keikoproj.io/custom-networking-prefix-assignment-enabled"
+	SharedInstanceProfileAnnotation                   = "instancemgr.keikoproj.io/shared-instance-profile"
+	CompressUserDataAnnotation                        = "instancemgr.keikoproj.io/compress-userdata"
+	DaemonSetReadinessAnnotation                      = "instancemgr.keikoproj.io/daemonset-readiness"
+	DaemonSetReadinessNamesAnnotation                 = "instancemgr.keikoproj.io/daemonset-readiness-names"
+	PropagateAnnotationsAnnotation                    = "instancemgr.keikoproj.io/propagate-annotations"
+	ForceDefaultVersionAnnotation                     = "instancemgr.keikoproj.io/force-default-version"
+	InstanceRefreshAnnotation                         = "instancemgr.keikoproj.io/instance-refresh"
+	ManagedPoliciesAnnotation                         = "instancemgr.keikoproj.io/managed-policies"
+	CapacityTypeLabelAnnotation                       = "instancemgr.keikoproj.io/capacity-type-label"
+	InstanceTypeReadinessAnnotation                   = "instancemgr.keikoproj.io/instance-type-readiness"
+	StartupTaintAnnotation                            = "instancemgr.keikoproj.io/startup-taint"
+	DumpUserDataAnnotation                            = "instancemgr.keikoproj.io/dump-userdata"
+	MaxPodsCalculationAnnotation                      = "instancemgr.keikoproj.io/max-pods-calculation"
+	AvailabilityZonesAnnotation                       = "instancemgr.keikoproj.io/availability-zones"
+	InstanceGroupLabelAnnotation                      = "instancemgr.keikoproj.io/instance-group-label"
+	ForceUpgradeAnnotation                            = "instancemgr.keikoproj.io/force-upgrade"
+	ReconcileNodeTaintsAnnotation                     = "instancemgr.keikoproj.io/reconcile-node-taints"
+	AppliedTaintsAnnotation                           = "instancemgr.keikoproj.io/applied-taints"
 
-	OsFamilyWindows      = "windows"
-	OsFamilyBottleRocket = "bottlerocket"
-	OsFamilyAmazonLinux2 = "amazonlinux2"
+	SecurityGroupTagPrefix = "sg-tag:"
+
+	DaemonSetReadinessNamespace = "kube-system"
+
+	ManagedPoliciesModeAppend  = "append"
+	ManagedPoliciesModeReplace = "replace"
+
+	CapacityTypeOnDemand = "on-demand"
+	CapacityTypeSpot     = "spot"
+
+	// MaxPodsCalculationDisabled leaves max-pods to the CNI/kubelet default
+	MaxPodsCalculationDisabled = "disabled"
+
+	// DockershimRemovedConstraint matches cluster versions which no longer support the docker container runtime
+	DockershimRemovedConstraint = ">= 1.24-0"
+	// InstanceRefreshScaleInProtectedInstances replaces scale-in protected instances during an instance refresh, so that
+	// a rotation reaches every node of the group
+	InstanceRefreshScaleInProtectedInstances = autoscaling.ScaleInProtectedInstancesRefresh
+	// InstanceRefreshStandbyInstances leaves instances in standby untouched by an instance refresh, they were taken out of
+	// service on purpose
+	InstanceRefreshStandbyInstances = autoscaling.StandbyInstancesIgnore
+	// ContainerdSupportedConstraint matches cluster versions whose bootstrap supports the containerd container runtime
+	ContainerdSupportedConstraint = ">= 1.21-0"
+	// DefaultLegacyRoleLabelCutoff is the first cluster version which no longer uses the old style node role label
+	DefaultLegacyRoleLabelCutoff = "1.16"
+
+	OsFamilyWindows         = "windows"
+	OsFamilyBottleRocket    = "bottlerocket"
+	OsFamilyAmazonLinux2    = "amazonlinux2"
+	OsFamilyAmazonLinux2023 = "al2023"
+
+	// DefaultUserDataShebang is the interpreter line of the amazonlinux2 userData unless overridden by bootstrapOptions
+	DefaultUserDataShebang = "#!/bin/bash"
+
+	// CredentialProviderConfigPath is where the image credential provider config is written on amazonlinux2 and al2023 nodes
+	CredentialProviderConfigPath = "/etc/eks/image-credential-provider/instance-manager-config.json"
+	// DefaultCredentialProviderBinDir is the directory of the credential provider binaries shipped with the EKS AMI
+	DefaultCredentialProviderBinDir = "/etc/eks/image-credential-provider"
+	// DefaultCredentialProviderName is the ECR credential provider configured by the EKS AMI for the node's own account
+	DefaultCredentialProviderName = "ecr-credential-provider"
+	// DefaultCredentialProviderCacheDuration matches the validity of an ECR authorization token
+	DefaultCredentialProviderCacheDuration = "12h"
+
+	// DefaultStartupTaint is registered on nodes when the startup-taint annotation is "true", it is removed by the cilium
+	// agent once networking on the node is ready
+	DefaultStartupTaint = "node.cilium.io/agent-not-ready=true:NoExecute"
+
+	// UserDataDumpMaxLength is the maximum length of the rendered userData published in an event
+	UserDataDumpMaxLength = 4096
+	// UserDataRedactedValue replaces secret-like values in dumped userData
+	UserDataRedactedValue = "REDACTED"
+
+	// NodeReadinessTimeoutReason is the state transition reason of instance groups whose nodes did not become ready in time
+	NodeReadinessTimeoutReason = "NodeReadinessTimeout"
+
+	// ScalingActivitiesMaxRecords is the number of recent scaling activities checked for failures
+	ScalingActivitiesMaxRecords = 10
 )
 
 var (
@@ -53,11 +128,28 @@ var (
 	RoleOldLabelFmt           = "node-role.kubernetes.io/%s=\"\""
 	InstanceMgrLifecycleLabel = "instancemgr.keikoproj.io/lifecycle"
 	InstanceMgrImageLabel     = "instancemgr.keikoproj.io/image"
+	InstanceGroupLabel        = "instancemgr.keikoproj.io/instance-group"
+	InstanceTypeLabel         = "node.kubernetes.io/instance-type"
+	CapacityTypeLabel         = "node.kubernetes.io/capacity-type"
 
-	AllowedOsFamilies      = []string{OsFamilyWindows, OsFamilyBottleRocket, OsFamilyAmazonLinux2}
-	DefaultManagedPolicies = []string{"AmazonEKSWorkerNodePolicy", "AmazonEC2ContainerRegistryReadOnly"}
-	CNIManagedPolicy       = "AmazonEKS_CNI_Policy"
-	SupportedArchitectures = []string{"x86_64", "arm64"}
+	AllowedOsFamilies          = []string{OsFamilyWindows, OsFamilyBottleRocket, OsFamilyAmazonLinux2, OsFamilyAmazonLinux2023}
+	DefaultManagedPolicies     = []string{"AmazonEKSWorkerNodePolicy", "AmazonEC2ContainerRegistryReadOnly"}
+	CNIManagedPolicy           = "AmazonEKS_CNI_Policy"
+	SupportedArchitectures     = []string{"x86_64", "arm64"}
+	DefaultReadinessDaemonSets = []string{"aws-node", "kube-proxy"}
+
+	// UserDataSecretRegex matches assignments to keys ending in a secret-like name, e.g. API_TOKEN=abc, values which
+	// reference shell variables or commands such as $TOKEN are not matched
+	UserDataSecretRegex = regexp.MustCompile(`(?i)([\w.-]*(?:password|passwd|secret|token|credentials?|api[_-]?key|access[_-]?key)["']?\s*[=:]\s*)("[^"$]*"|'[^']*'|[^\s"'$]\S*)`)
+
+	// DefaultCredentialProviderMatchImages are the images the EKS AMI configures the ECR credential provider for
+	DefaultCredentialProviderMatchImages = []string{
+		"*.dkr.ecr.*.amazonaws.com",
+		"*.dkr.ecr.*.amazonaws.com.cn",
+		"*.dkr.ecr-fips.*.amazonaws.com",
+		"*.dkr.ecr.*.c2s.ic.gov",
+		"*.dkr.ecr.*.sc2s.sgov.gov",
+	}
 )
 
 // New constructs a new instance group provisioner of EKS type
@@ -78,6 +170,15 @@ func New(p provisioners.ProvisionerInput) *EksInstanceGroupContext {
 		ConfigRetention:            p.ConfigRetention,
 		Metrics:                    p.Metrics,
 		DisableWinClusterInjection: p.DisableWinClusterInjection,
+		NodeRelabel:                p.NodeRelabel,
+		SpotRecommendationSource:   p.SpotRecommendationSource,
+		ReconcileShortcutInterval:  p.ReconcileShortcutInterval,
+		ReconcileShortcutCount:     p.ReconcileShortcutCount,
+		LastReconcile:              p.LastReconcile,
+		NodeReadinessTimeout:       p.NodeReadinessTimeout,
+		EnableUserDataDump:         p.EnableUserDataDump,
+		LegacyRoleLabelCutoff:      p.LegacyRoleLabelCutoff,
+		PreviousState:              instanceGroup.GetState(),
 	}
 
 	ctx.SetState(v1alpha1.ReconcileInit)
@@ -99,6 +200,15 @@ type EksInstanceGroupContext struct {
 	ResourcePrefix             string
 	Metrics                    *common.MetricsCollector
 	DisableWinClusterInjection bool
+	NodeRelabel                bool
+	SpotRecommendationSource   kubeprovider.SpotRecommendationSource
+	ReconcileShortcutInterval  time.Duration
+	ReconcileShortcutCount     int
+	LastReconcile              provisioners.ReconcileRecord
+	NodeReadinessTimeout       time.Duration
+	EnableUserDataDump         bool
+	LegacyRoleLabelCutoff      string
+	PreviousState              v1alpha1.ReconcileState
 }
 
 type UserDataPayload struct {
@@ -125,6 +235,14 @@ type EKSUserData struct {
 	PostBootstrap    []string
 	MountOptions     []MountOpts
 	MaxPods          int64
+	Shebang          string
+	// CredentialProviderConfig is the rendered CredentialProviderConfig written to amazonlinux2 and al2023 nodes
+	CredentialProviderConfig     string
+	CredentialProviderConfigPath string
+	// ServiceCIDR is the service CIDR of the cluster, required by the al2023 NodeConfig
+	ServiceCIDR string
+	// KubeletFlags are the kubelet arguments passed in the al2023 NodeConfig
+	KubeletFlags []string
 }
 
 func (ctx *EksInstanceGroupContext) GetInstanceGroup() *v1alpha1.InstanceGroup {
@@ -18,6 +18,7 @@ package eks
 import (
 	"flag"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
@@ -41,6 +42,7 @@ import (
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/intstr"
 	dynamic "k8s.io/client-go/dynamic/fake"
 	"k8s.io/client-go/kubernetes/fake"
@@ -505,6 +507,24 @@ func MockSpotEvent(id, scalingGroup, price string, useSpot bool, ts time.Time) *
 	return event
 }
 
+func MockSpotInterruptionEvent(id, nodeName string, ts time.Time) *corev1.Event {
+	event := &corev1.Event{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: fmt.Sprintf("%v.%v", nodeName, id),
+			UID:  types.UID(fmt.Sprintf("%v-%v", nodeName, id)),
+		},
+		LastTimestamp: metav1.Time{Time: ts},
+		Reason:        kubeprovider.SpotInterruptionReason,
+		Message:       "Spot Interruption notice for instance was sent",
+		Type:          "Normal",
+		InvolvedObject: corev1.ObjectReference{
+			Kind: "Node",
+			Name: nodeName,
+		},
+	}
+	return event
+}
+
 func MockEnabledMetrics(metrics ...string) []*autoscaling.EnabledMetric {
 	mockMetrics := make([]*autoscaling.EnabledMetric, 0)
 	for _, m := range metrics {
@@ -521,6 +541,7 @@ type MockAutoScalingClient struct {
 	autoscalingiface.AutoScalingAPI
 	DescribeLaunchConfigurationsErr        error
 	DescribeAutoScalingGroupsErr           error
+	AutoScalingGroupPages                  [][]*autoscaling.Group
 	CreateLaunchConfigurationErr           error
 	DeleteLaunchConfigurationErr           error
 	CreateAutoScalingGroupErr              error
@@ -536,21 +557,47 @@ type MockAutoScalingClient struct {
 	DescribeWarmPoolErr                    error
 	DeleteWarmPoolErr                      error
 	PutWarmPoolErr                         error
+	DescribeAutoScalingGroupsCallCount     uint
 	DeleteLaunchConfigurationCallCount     uint
+	TerminateInstanceCallCount             uint
 	PutLifecycleHookCallCount              uint
 	DeleteLifecycleHookCallCount           uint
 	PutWarmPoolCallCount                   uint
 	DeleteWarmPoolCallCount                uint
 	DescribeWarmPoolCallCount              uint
+	UpdateAutoScalingGroupCallCount        uint
+	AttachLoadBalancerTargetGroupsInput    *autoscaling.AttachLoadBalancerTargetGroupsInput
+	DetachLoadBalancerTargetGroupsInput    *autoscaling.DetachLoadBalancerTargetGroupsInput
+	AttachLoadBalancersInput               *autoscaling.AttachLoadBalancersInput
+	DetachLoadBalancersInput               *autoscaling.DetachLoadBalancersInput
+	CreateAutoScalingGroupInput            *autoscaling.CreateAutoScalingGroupInput
+	UpdateAutoScalingGroupInput            *autoscaling.UpdateAutoScalingGroupInput
+	StartInstanceRefreshInput              *autoscaling.StartInstanceRefreshInput
+	StartInstanceRefreshCallCount          uint
+	InstanceRefreshes                      []*autoscaling.InstanceRefresh
 	LaunchConfiguration                    *autoscaling.LaunchConfiguration
 	LaunchConfigurations                   []*autoscaling.LaunchConfiguration
 	AutoScalingGroup                       *autoscaling.Group
 	AutoScalingGroups                      []*autoscaling.Group
 	WarmPoolInstances                      []*autoscaling.Instance
 	LifecycleHooks                         []*autoscaling.LifecycleHook
+	ScalingActivities                      []*autoscaling.Activity
+	DescribeScalingActivitiesErr           error
+	CompleteLifecycleActionInput           *autoscaling.CompleteLifecycleActionInput
+	CompleteLifecycleActionCallCount       uint
+	EnableMetricsCollectionInput           *autoscaling.EnableMetricsCollectionInput
+	SuspendProcessesInput                  *autoscaling.ScalingProcessQuery
+	ResumeProcessesInput                   *autoscaling.ScalingProcessQuery
+}
+
+func (a *MockAutoScalingClient) CompleteLifecycleAction(input *autoscaling.CompleteLifecycleActionInput) (*autoscaling.CompleteLifecycleActionOutput, error) {
+	a.CompleteLifecycleActionInput = input
+	a.CompleteLifecycleActionCallCount++
+	return &autoscaling.CompleteLifecycleActionOutput{}, nil
 }
 
 func (a *MockAutoScalingClient) EnableMetricsCollection(input *autoscaling.EnableMetricsCollectionInput) (*autoscaling.EnableMetricsCollectionOutput, error) {
+	a.EnableMetricsCollectionInput = input
 	return &autoscaling.EnableMetricsCollectionOutput{}, a.EnableMetricsCollectionErr
 }
 
@@ -559,6 +606,7 @@ func (a *MockAutoScalingClient) DisableMetricsCollection(input *autoscaling.Disa
 }
 
 func (a *MockAutoScalingClient) TerminateInstanceInAutoScalingGroup(input *autoscaling.TerminateInstanceInAutoScalingGroupInput) (*autoscaling.TerminateInstanceInAutoScalingGroupOutput, error) {
+	a.TerminateInstanceCallCount++
 	return &autoscaling.TerminateInstanceInAutoScalingGroupOutput{}, a.TerminateInstanceInAutoScalingGroupErr
 }
 
@@ -584,6 +632,7 @@ func (a *MockAutoScalingClient) DeleteLaunchConfiguration(input *autoscaling.Del
 }
 
 func (a *MockAutoScalingClient) CreateAutoScalingGroup(input *autoscaling.CreateAutoScalingGroupInput) (*autoscaling.CreateAutoScalingGroupOutput, error) {
+	a.CreateAutoScalingGroupInput = input
 	return &autoscaling.CreateAutoScalingGroupOutput{}, a.CreateAutoScalingGroupErr
 }
 
@@ -596,6 +645,20 @@ func (a *MockAutoScalingClient) DescribeAutoScalingGroups(input *autoscaling.Des
 }
 
 func (a *MockAutoScalingClient) DescribeAutoScalingGroupsPages(input *autoscaling.DescribeAutoScalingGroupsInput, callback func(*autoscaling.DescribeAutoScalingGroupsOutput, bool) bool) error {
+	a.DescribeAutoScalingGroupsCallCount++
+	if len(a.AutoScalingGroupPages) > 0 {
+		for i, groups := range a.AutoScalingGroupPages {
+			lastPage := i == len(a.AutoScalingGroupPages)-1
+			page := &autoscaling.DescribeAutoScalingGroupsOutput{AutoScalingGroups: groups}
+			if !lastPage {
+				page.NextToken = aws.String(fmt.Sprintf("page-%v", i+1))
+			}
+			if !callback(page, lastPage) {
+				break
+			}
+		}
+		return a.DescribeAutoScalingGroupsErr
+	}
 	page, err := a.DescribeAutoScalingGroups(input)
 	if err != nil {
 		return err
@@ -614,14 +677,18 @@ func (a *MockAutoScalingClient) DescribeLaunchConfigurationsPages(input *autosca
 }
 
 func (a *MockAutoScalingClient) UpdateAutoScalingGroup(input *autoscaling.UpdateAutoScalingGroupInput) (*autoscaling.UpdateAutoScalingGroupOutput, error) {
+	a.UpdateAutoScalingGroupCallCount++
+	a.UpdateAutoScalingGroupInput = input
 	return &autoscaling.UpdateAutoScalingGroupOutput{}, a.UpdateAutoScalingGroupErr
 }
 
 func (a *MockAutoScalingClient) SuspendProcesses(input *autoscaling.ScalingProcessQuery) (*autoscaling.SuspendProcessesOutput, error) {
+	a.SuspendProcessesInput = input
 	return &autoscaling.SuspendProcessesOutput{}, a.UpdateSuspendProcessesErr
 }
 
 func (a *MockAutoScalingClient) ResumeProcesses(input *autoscaling.ScalingProcessQuery) (*autoscaling.ResumeProcessesOutput, error) {
+	a.ResumeProcessesInput = input
 	return &autoscaling.ResumeProcessesOutput{}, a.UpdateSuspendProcessesErr
 }
 
@@ -629,11 +696,35 @@ func (a *MockAutoScalingClient) DescribeLifecycleHooks(input *autoscaling.Descri
 	return &autoscaling.DescribeLifecycleHooksOutput{LifecycleHooks: a.LifecycleHooks}, a.DescribeLifecycleHooksErr
 }
 
+func (a *MockAutoScalingClient) DescribeScalingActivities(input *autoscaling.DescribeScalingActivitiesInput) (*autoscaling.DescribeScalingActivitiesOutput, error) {
+	return &autoscaling.DescribeScalingActivitiesOutput{Activities: a.ScalingActivities}, a.DescribeScalingActivitiesErr
+}
+
 func (a *MockAutoScalingClient) DeleteLifecycleHook(input *autoscaling.DeleteLifecycleHookInput) (*autoscaling.DeleteLifecycleHookOutput, error) {
 	a.DeleteLifecycleHookCallCount++
 	return &autoscaling.DeleteLifecycleHookOutput{}, a.DeleteLifecycleHookErr
 }
 
+func (a *MockAutoScalingClient) AttachLoadBalancerTargetGroups(input *autoscaling.AttachLoadBalancerTargetGroupsInput) (*autoscaling.AttachLoadBalancerTargetGroupsOutput, error) {
+	a.AttachLoadBalancerTargetGroupsInput = input
+	return &autoscaling.AttachLoadBalancerTargetGroupsOutput{}, nil
+}
+
+func (a *MockAutoScalingClient) DetachLoadBalancerTargetGroups(input *autoscaling.DetachLoadBalancerTargetGroupsInput) (*autoscaling.DetachLoadBalancerTargetGroupsOutput, error) {
+	a.DetachLoadBalancerTargetGroupsInput = input
+	return &autoscaling.DetachLoadBalancerTargetGroupsOutput{}, nil
+}
+
+func (a *MockAutoScalingClient) AttachLoadBalancers(input *autoscaling.AttachLoadBalancersInput) (*autoscaling.AttachLoadBalancersOutput, error) {
+	a.AttachLoadBalancersInput = input
+	return &autoscaling.AttachLoadBalancersOutput{}, nil
+}
+
+func (a *MockAutoScalingClient) DetachLoadBalancers(input *autoscaling.DetachLoadBalancersInput) (*autoscaling.DetachLoadBalancersOutput, error) {
+	a.DetachLoadBalancersInput = input
+	return &autoscaling.DetachLoadBalancersOutput{}, nil
+}
+
 func (a *MockAutoScalingClient) PutLifecycleHook(input *autoscaling.PutLifecycleHookInput) (*autoscaling.PutLifecycleHookOutput, error) {
 	a.PutLifecycleHookCallCount++
 	return &autoscaling.PutLifecycleHookOutput{}, a.PutLifecycleHookErr
@@ -654,27 +745,111 @@ func (a *MockAutoScalingClient) PutWarmPool(input *autoscaling.PutWarmPoolInput)
 	return &autoscaling.PutWarmPoolOutput{}, a.PutWarmPoolErr
 }
 
+func (a *MockAutoScalingClient) StartInstanceRefresh(input *autoscaling.StartInstanceRefreshInput) (*autoscaling.StartInstanceRefreshOutput, error) {
+	a.StartInstanceRefreshInput = input
+	a.StartInstanceRefreshCallCount++
+	return &autoscaling.StartInstanceRefreshOutput{
+		InstanceRefreshId: aws.String(fmt.Sprintf("refresh-%v", a.StartInstanceRefreshCallCount)),
+	}, nil
+}
+
+func (a *MockAutoScalingClient) DescribeInstanceRefreshes(input *autoscaling.DescribeInstanceRefreshesInput) (*autoscaling.DescribeInstanceRefreshesOutput, error) {
+	return &autoscaling.DescribeInstanceRefreshesOutput{
+		InstanceRefreshes: a.InstanceRefreshes,
+	}, nil
+}
+
 type MockEc2Client struct {
 	ec2iface.EC2API
 	DescribeSubnetsErr                   error
+	DescribeSubnetsCallCount             uint
 	DescribeSecurityGroupsErr            error
+	DescribeKeyPairsErr                  error
 	CreateLaunchTemplateCallCount        uint
+	CreateLaunchTemplateInput            *ec2.CreateLaunchTemplateInput
 	CreateLaunchTemplateVersionCallCount uint
+	CreateLaunchTemplateVersionInput     *ec2.CreateLaunchTemplateVersionInput
 	ModifyLaunchTemplateCallCount        uint
 	DeleteLaunchTemplateCallCount        uint
+	DeletedLaunchTemplateNames           []string
 	Subnets                              []*ec2.Subnet
 	SecurityGroups                       []*ec2.SecurityGroup
 	LaunchTemplates                      []*ec2.LaunchTemplate
 	LaunchTemplateVersions               []*ec2.LaunchTemplateVersion
 	InstanceTypeOfferings                []*ec2.InstanceTypeOffering
 	InstanceTypes                        []*ec2.InstanceTypeInfo
+	KeyPairs                             []*ec2.KeyPairInfo
+	Images                               []*ec2.Image
+	Instances                            []*ec2.Instance
+	ModifyInstanceAttributeCallCount     uint
+}
+
+func (c *MockEc2Client) DescribeInstancesPages(input *ec2.DescribeInstancesInput, callback func(*ec2.DescribeInstancesOutput, bool) bool) error {
+	out := &ec2.DescribeInstancesOutput{
+		Reservations: []*ec2.Reservation{{Instances: c.Instances}},
+	}
+	callback(out, false)
+	return nil
+}
+
+func (c *MockEc2Client) ModifyInstanceAttribute(input *ec2.ModifyInstanceAttributeInput) (*ec2.ModifyInstanceAttributeOutput, error) {
+	c.ModifyInstanceAttributeCallCount++
+	for _, instance := range c.Instances {
+		if aws.StringValue(instance.InstanceId) == aws.StringValue(input.InstanceId) && input.SourceDestCheck != nil {
+			instance.SourceDestCheck = input.SourceDestCheck.Value
+		}
+	}
+	return &ec2.ModifyInstanceAttributeOutput{}, nil
+}
+
+func (c *MockEc2Client) DescribeKeyPairs(input *ec2.DescribeKeyPairsInput) (*ec2.DescribeKeyPairsOutput, error) {
+	out := &ec2.DescribeKeyPairsOutput{}
+	for _, k := range c.KeyPairs {
+		for _, f := range input.Filters {
+			if common.ContainsString(aws.StringValueSlice(f.Values), aws.StringValue(k.KeyName)) {
+				out.KeyPairs = append(out.KeyPairs, k)
+			}
+		}
+	}
+	return out, c.DescribeKeyPairsErr
+}
+
+func (c *MockEc2Client) DescribeImages(input *ec2.DescribeImagesInput) (*ec2.DescribeImagesOutput, error) {
+	out := &ec2.DescribeImagesOutput{}
+	for _, image := range c.Images {
+		matches := true
+		for _, f := range input.Filters {
+			key := strings.TrimPrefix(aws.StringValue(f.Name), "tag:")
+			if key == aws.StringValue(f.Name) {
+				continue
+			}
+			var found bool
+			for _, tag := range image.Tags {
+				if aws.StringValue(tag.Key) == key && common.ContainsString(aws.StringValueSlice(f.Values), aws.StringValue(tag.Value)) {
+					found = true
+				}
+			}
+			matches = matches && found
+		}
+		if matches {
+			out.Images = append(out.Images, image)
+		}
+	}
+	return out, nil
 }
 
 func (c *MockEc2Client) CreateLaunchTemplate(input *ec2.CreateLaunchTemplateInput) (*ec2.CreateLaunchTemplateOutput, error) {
 	c.CreateLaunchTemplateCallCount++
+	c.CreateLaunchTemplateInput = input
 	return &ec2.CreateLaunchTemplateOutput{}, nil
 }
 
+func (c *MockEc2Client) DeleteLaunchTemplate(input *ec2.DeleteLaunchTemplateInput) (*ec2.DeleteLaunchTemplateOutput, error) {
+	c.DeleteLaunchTemplateCallCount++
+	c.DeletedLaunchTemplateNames = append(c.DeletedLaunchTemplateNames, aws.StringValue(input.LaunchTemplateName))
+	return &ec2.DeleteLaunchTemplateOutput{}, nil
+}
+
 func (c *MockEc2Client) ModifyLaunchTemplate(input *ec2.ModifyLaunchTemplateInput) (*ec2.ModifyLaunchTemplateOutput, error) {
 	c.ModifyLaunchTemplateCallCount++
 	return &ec2.ModifyLaunchTemplateOutput{}, nil
@@ -682,6 +857,7 @@ func (c *MockEc2Client) ModifyLaunchTemplate(input *ec2.ModifyLaunchTemplateInpu
 
 func (c *MockEc2Client) CreateLaunchTemplateVersion(input *ec2.CreateLaunchTemplateVersionInput) (*ec2.CreateLaunchTemplateVersionOutput, error) {
 	c.CreateLaunchTemplateVersionCallCount++
+	c.CreateLaunchTemplateVersionInput = input
 	out := &ec2.CreateLaunchTemplateVersionOutput{
 		LaunchTemplateVersion: &ec2.LaunchTemplateVersion{
 			VersionNumber: aws.Int64(1),
@@ -766,6 +942,7 @@ func (c *MockEc2Client) DescribeSecurityGroups(input *ec2.DescribeSecurityGroups
 }
 
 func (c *MockEc2Client) DescribeSubnets(input *ec2.DescribeSubnetsInput) (*ec2.DescribeSubnetsOutput, error) {
+	c.DescribeSubnetsCallCount++
 	return &ec2.DescribeSubnetsOutput{Subnets: c.Subnets}, c.DescribeSubnetsErr
 }
 
@@ -798,6 +975,8 @@ type MockIamClient struct {
 	Role                              *iam.Role
 	InstanceProfile                   *iam.InstanceProfile
 	AttachedPolicies                  []*iam.AttachedPolicy
+	CreateRoleInput                   *iam.CreateRoleInput
+	CreateInstanceProfileInput        *iam.CreateInstanceProfileInput
 }
 
 func (i *MockIamClient) ListAttachedRolePolicies(input *iam.ListAttachedRolePoliciesInput) (*iam.ListAttachedRolePoliciesOutput, error) {
@@ -817,6 +996,7 @@ func (i *MockIamClient) ListAttachedRolePoliciesPages(input *iam.ListAttachedRol
 }
 
 func (i *MockIamClient) CreateRole(input *iam.CreateRoleInput) (*iam.CreateRoleOutput, error) {
+	i.CreateRoleInput = input
 	if i.Role != nil {
 		return &iam.CreateRoleOutput{Role: i.Role}, i.CreateRoleErr
 	}
@@ -832,6 +1012,7 @@ func (i *MockIamClient) DeleteRole(input *iam.DeleteRoleInput) (*iam.DeleteRoleO
 }
 
 func (i *MockIamClient) CreateInstanceProfile(input *iam.CreateInstanceProfileInput) (*iam.CreateInstanceProfileOutput, error) {
+	i.CreateInstanceProfileInput = input
 	if i.InstanceProfile != nil {
 		return &iam.CreateInstanceProfileOutput{InstanceProfile: i.InstanceProfile}, i.CreateInstanceProfileErr
 	}
@@ -870,7 +1051,27 @@ func (i *MockIamClient) WaitUntilInstanceProfileExists(input *iam.GetInstancePro
 
 type MockSsmClient struct {
 	ssmiface.SSMAPI
-	parameterMap map[string]string
+	parameterMap                  map[string]string
+	StartAutomationExecutionInput *ssm.StartAutomationExecutionInput
+	StartAutomationExecutionCount uint
+	AutomationExecutionStatus     string
+}
+
+func (i *MockSsmClient) StartAutomationExecution(input *ssm.StartAutomationExecutionInput) (*ssm.StartAutomationExecutionOutput, error) {
+	i.StartAutomationExecutionInput = input
+	i.StartAutomationExecutionCount++
+	return &ssm.StartAutomationExecutionOutput{
+		AutomationExecutionId: aws.String(aws.StringValue(input.ClientToken)),
+	}, nil
+}
+
+func (i *MockSsmClient) GetAutomationExecution(input *ssm.GetAutomationExecutionInput) (*ssm.GetAutomationExecutionOutput, error) {
+	return &ssm.GetAutomationExecutionOutput{
+		AutomationExecution: &ssm.AutomationExecution{
+			AutomationExecutionId:     input.AutomationExecutionId,
+			AutomationExecutionStatus: aws.String(i.AutomationExecutionStatus),
+		},
+	}, nil
 }
 
 func (i *MockSsmClient) GetParameter(input *ssm.GetParameterInput) (*ssm.GetParameterOutput, error) {
@@ -17,31 +17,43 @@ package eks
 
 import (
 	"bytes"
+	"compress/gzip"
 	"context"
 	"encoding/base64"
+	"encoding/json"
 	"fmt"
+	"io"
 	"reflect"
 	"sort"
 	"strconv"
 	"strings"
-	"text/template"
+	"time"
+	"unicode/utf8"
 
 	"github.com/Masterminds/semver"
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/arn"
 	"github.com/aws/aws-sdk-go/service/autoscaling"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/iam"
+	"github.com/aws/aws-sdk-go/service/ssm"
 	"github.com/keikoproj/instance-manager/api/instancemgr/v1alpha1"
 	"github.com/keikoproj/instance-manager/controllers/common"
 	awsprovider "github.com/keikoproj/instance-manager/controllers/providers/aws"
 	kubeprovider "github.com/keikoproj/instance-manager/controllers/providers/kubernetes"
 	"github.com/keikoproj/instance-manager/controllers/provisioners"
+	"github.com/keikoproj/instance-manager/controllers/provisioners/eks/scaling"
 	"github.com/pkg/errors"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
 )
 
-func (ctx *EksInstanceGroupContext) ResolveSubnets() []string {
+// ResolveSubnets returns the sorted IDs of the group's subnets, subnets referenced by name are looked up in the cluster vpc
+// and the result is restricted to the group's availability zones
+func (ctx *EksInstanceGroupContext) ResolveSubnets() ([]string, error) {
 	var (
 		instanceGroup = ctx.GetInstanceGroup()
 		configuration = instanceGroup.GetEKSConfiguration()
@@ -49,6 +61,8 @@ func (ctx *EksInstanceGroupContext) ResolveSubnets() []string {
 		resolved      = make([]string, 0)
 	)
 
+	// subnets described while resolving are reused by later checks of the same discovery
+	state.SetSubnetInfo(nil)
 	for _, s := range configuration.GetSubnets() {
 		if strings.HasPrefix(s, "subnet-") {
 			resolved = append(resolved, s)
@@ -57,13 +71,13 @@ func (ctx *EksInstanceGroupContext) ResolveSubnets() []string {
 
 		sn, err := ctx.AwsWorker.SubnetByName(s, state.GetVPCId())
 		if err != nil {
-			ctx.Log.Error(err, "failed to resolve subnet id by name", "subnet", s)
-			continue
+			return nil, errors.Wrapf(err, "failed to resolve subnet id of '%v'", s)
 		}
 		if sn == nil {
 			ctx.Log.Error(errors.New("subnet not found"), "failed to resolve subnet by name", "subnet", s)
 			continue
 		}
+		state.SetSubnetInfo(append(state.GetSubnetInfo(), sn))
 		resolved = append(resolved, aws.StringValue(sn.SubnetId))
 	}
 
@@ -76,8 +90,112 @@ func (ctx *EksInstanceGroupContext) ResolveSubnets() []string {
 		}
 	}
 
+	if zones := ctx.GetAvailabilityZones(); len(zones) > 0 {
+		filtered, err := ctx.filterSubnetsByZone(dedupe, zones)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to filter subnets by availability zone")
+		}
+		dedupe = filtered
+	}
+
 	sort.Strings(dedupe)
-	return dedupe
+	return dedupe, nil
+}
+
+// GetAvailabilityZones returns the availability zones the group's subnets are restricted to
+func (ctx *EksInstanceGroupContext) GetAvailabilityZones() []string {
+	var (
+		instanceGroup = ctx.GetInstanceGroup()
+		annotations   = instanceGroup.GetAnnotations()
+		zones         = make([]string, 0)
+	)
+
+	for _, zone := range strings.Split(annotations[AvailabilityZonesAnnotation], ",") {
+		if zone = strings.TrimSpace(zone); !common.StringEmpty(zone) {
+			zones = append(zones, zone)
+		}
+	}
+	return zones
+}
+
+// filterSubnetsByZone returns the subnets which are in one of the given availability zones
+func (ctx *EksInstanceGroupContext) filterSubnetsByZone(subnetIds, zones []string) ([]string, error) {
+	filtered := make([]string, 0)
+	if len(subnetIds) == 0 {
+		return filtered, nil
+	}
+
+	subnets, err := ctx.describeSubnets(subnetIds)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, subnet := range subnets {
+		id := aws.StringValue(subnet.SubnetId)
+		if !common.ContainsString(subnetIds, id) || common.ContainsString(filtered, id) {
+			continue
+		}
+		if common.ContainsEqualFold(zones, aws.StringValue(subnet.AvailabilityZone)) {
+			filtered = append(filtered, id)
+		}
+	}
+	return filtered, nil
+}
+
+// describeSubnets returns the given subnets, subnets which were already described during discovery are reused and only
+// the remaining subnets are described
+func (ctx *EksInstanceGroupContext) describeSubnets(subnetIds []string) ([]*ec2.Subnet, error) {
+	var (
+		state     = ctx.GetDiscoveredState()
+		described = make([]*ec2.Subnet, 0)
+		missing   = make([]string, 0)
+	)
+
+	for _, id := range subnetIds {
+		if subnet := state.GetSubnetById(id); subnet != nil {
+			described = append(described, subnet)
+			continue
+		}
+		missing = append(missing, id)
+	}
+
+	if len(missing) == 0 {
+		return described, nil
+	}
+
+	subnets, err := ctx.AwsWorker.DescribeSubnetsById(missing)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to describe subnets")
+	}
+	state.SetSubnetInfo(append(state.GetSubnetInfo(), subnets...))
+	return append(described, subnets...), nil
+}
+
+// GetEndpointUnreachableSubnets returns the group's subnets which are outside of the cluster vpc, nodes launched in these
+// subnets cannot resolve or reach a private-only cluster endpoint
+func (ctx *EksInstanceGroupContext) GetEndpointUnreachableSubnets() ([]string, error) {
+	var (
+		state       = ctx.GetDiscoveredState()
+		vpcID       = state.GetVPCId()
+		unreachable = make([]string, 0)
+	)
+
+	subnetIds := state.GetSubnets()
+	if len(subnetIds) == 0 {
+		return unreachable, nil
+	}
+
+	subnets, err := ctx.describeSubnets(subnetIds)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, subnet := range subnets {
+		if !strings.EqualFold(aws.StringValue(subnet.VpcId), vpcID) {
+			unreachable = append(unreachable, aws.StringValue(subnet.SubnetId))
+		}
+	}
+	return unreachable, nil
 }
 
 func (ctx *EksInstanceGroupContext) ResolveSecurityGroups() []string {
@@ -89,6 +207,21 @@ func (ctx *EksInstanceGroupContext) ResolveSecurityGroups() []string {
 	)
 
 	for _, g := range configuration.GetSecurityGroups() {
+		if strings.HasPrefix(g, SecurityGroupTagPrefix) {
+			tag := strings.SplitN(strings.TrimPrefix(g, SecurityGroupTagPrefix), "=", 2)
+			if len(tag) != 2 || common.StringEmpty(tag[0]) {
+				ctx.Log.Error(errors.New("invalid security group tag reference"), "security group tag must be in the form sg-tag:key=value", "security-group", g)
+				continue
+			}
+			sg, err := ctx.AwsWorker.SecurityGroupByTag(tag[0], tag[1], state.GetVPCId())
+			if err != nil {
+				ctx.Log.Error(err, "failed to resolve security group by tag", "security-group", g)
+				continue
+			}
+			resolved = append(resolved, aws.StringValue(sg.GroupId))
+			continue
+		}
+
 		if strings.HasPrefix(g, "sg-") {
 			resolved = append(resolved, g)
 			continue
@@ -112,89 +245,22 @@ func (ctx *EksInstanceGroupContext) ResolveSecurityGroups() []string {
 
 func (ctx *EksInstanceGroupContext) GetBasicUserData(clusterName, args string, kubeletExtraArgs string, payload UserDataPayload, mounts []MountOpts) string {
 	var (
-		instanceGroup    = ctx.GetInstanceGroup()
-		configuration    = instanceGroup.GetEKSConfiguration()
-		state            = ctx.GetDiscoveredState()
-		apiEndpoint      = state.GetClusterEndpoint()
-		clusterCa        = state.GetClusterCA()
+		apiEndpoint      = ctx.GetClusterEndpoint()
+		clusterCa        = ctx.GetClusterCA()
 		osFamily         = ctx.GetOsFamily()
 		nodeLabels       = ctx.GetComputedLabels()
-		nodeTaints       = configuration.GetTaints()
+		nodeTaints       = ctx.GetComputedTaints()
 		bootstrapOptions = ctx.GetComputedBootstrapOptions()
 	)
 	var maxPods int64 = 0
+	var shebang = DefaultUserDataShebang
 
 	if bootstrapOptions != nil {
 		maxPods = bootstrapOptions.MaxPods
+		if !common.StringEmpty(bootstrapOptions.UserDataShebang) {
+			shebang = strings.TrimRight(bootstrapOptions.UserDataShebang, "\n")
+		}
 	}
-	var UserDataTemplate string
-	switch strings.ToLower(osFamily) {
-	case OsFamilyWindows:
-		UserDataTemplate = `
-<powershell>
-  {{range $pre := .PreBootstrap}}{{$pre}}{{end}}
-  [string]$EKSBinDir = "$env:ProgramFiles\Amazon\EKS"
-  [string]$EKSBootstrapScriptName = 'Start-EKSBootstrap.ps1'
-  [string]$EKSBootstrapScriptFile = "$EKSBinDir\$EKSBootstrapScriptName"
-  [string]$IMDSToken=(curl -UseBasicParsing -Method PUT "http://169.254.169.254/latest/api/token" -H @{ "X-aws-ec2-metadata-token-ttl-seconds" = "21600"} | % { Echo $_.Content})
-  [string]$InstanceID=(curl -UseBasicParsing -Method GET "http://169.254.169.254/latest/meta-data/instance-id" -H @{ "X-aws-ec2-metadata-token" = "$IMDSToken"} | % { Echo $_.Content})
-  [string]$Lifecycle=(curl -UseBasicParsing -Method GET "http://169.254.169.254/latest/meta-data/autoscaling/target-lifecycle-state" -H @{ "X-aws-ec2-metadata-token" = "$IMDSToken"} | % { Echo $_.Content})
-  if ($Lifecycle -like "*Warmed*") {
-    Echo "Not starting Kubelet due to warmed state."
-    & C:\ProgramData\Amazon\EC2-Windows\Launch\Scripts\InitializeInstance.ps1 -Schedule
-  } else {
-    & $EKSBootstrapScriptFile -EKSClusterName {{ .ClusterName }} {{ .Arguments }} 3>&1 4>&1 5>&1 6>&1
-    {{range $post := .PostBootstrap}}{{$post}}{{end}}
-  }
-</powershell>`
-	case OsFamilyBottleRocket:
-		UserDataTemplate = `
-{{range $pre := .PreBootstrap}}{{$pre}}{{end}}
-[settings.kubernetes]
-api-server   = "{{ .ApiEndpoint }}"
-cluster-certificate = "{{ .ClusterCA }}"
-cluster-name = "{{ .ClusterName }}"
-{{- if .MaxPods}}
-max-pods = {{ .MaxPods }}
-{{- end}}
-[settings.kubernetes.node-labels]
-{{- range $key, $value := .NodeLabels }}
-"{{ $key }}" = "{{ $value }}"
-{{- end}}
-[settings.kubernetes.node-taints]
-{{- range .NodeTaints}}
-"{{ .Key }}" = "{{ .Value }}:{{ .Effect }}"
-{{- end}}
-{{range $post := .PostBootstrap}}{{$post}}{{end}}
-`
-	case OsFamilyAmazonLinux2:
-		UserDataTemplate = `#!/bin/bash
-{{range $pre := .PreBootstrap}}{{$pre}}{{end}}
-{{- range .MountOptions}}
-mkfs.{{ .FileSystem | ToLower }} {{ .Device }}
-mkdir {{ .Mount }}
-mount {{ .Device }} {{ .Mount }}
-mount
-{{- if .Persistance}}
-echo "{{ .Device}}    {{ .Mount }}    {{ .FileSystem | ToLower }}    defaults    0    2" >> /etc/fstab
-{{- end}}
-{{- end}}
-if [[ $(type -P $(which aws)) ]] && [[ $(type -P $(which jq)) ]] ; then
-	TOKEN=$(curl -X PUT "http://169.254.169.254/latest/api/token" -H "X-aws-ec2-metadata-token-ttl-seconds: 21600")
-	INSTANCE_ID=$(curl url -H "X-aws-ec2-metadata-token: $TOKEN" http://169.254.169.254/latest/meta-data/instance-id)
-	REGION=$(curl url -H "X-aws-ec2-metadata-token: $TOKEN" http://169.254.169.254/latest/meta-data/placement/region)
-	LIFECYCLE=$(curl url -H "X-aws-ec2-metadata-token: $TOKEN" http://169.254.169.254/latest/meta-data/autoscaling/target-lifecycle-state)
-	if [[ $LIFECYCLE == *"Warmed"* ]]; then
-		rm /var/lib/cloud/instances/$INSTANCE_ID/sem/config_scripts_user
-		exit 0
-	fi
-fi
-set -o xtrace
-/etc/eks/bootstrap.sh {{ .ClusterName }} {{ .Arguments }}
-set +o xtrace
-{{range $post := .PostBootstrap}}{{$post}}{{end}}`
-	}
-
 	data := EKSUserData{
 		ApiEndpoint:      apiEndpoint,
 		ClusterCA:        clusterCa,
@@ -207,816 +273,2662 @@ set +o xtrace
 		PreBootstrap:     payload.PreBootstrap,
 		PostBootstrap:    payload.PostBootstrap,
 		MountOptions:     mounts,
+		Shebang:          shebang,
+	}
+	if bootstrapOptions != nil && bootstrapOptions.CredentialProvider != nil && ctx.IsCredentialProviderSupported() {
+		data.CredentialProviderConfig = ctx.GetCredentialProviderConfig()
+		data.CredentialProviderConfigPath = CredentialProviderConfigPath
+	}
+	if strings.EqualFold(osFamily, OsFamilyAmazonLinux2023) {
+		// nodeadm has no bootstrap script arguments, the kubelet flags are passed in the NodeConfig
+		data.KubeletFlags = strings.Fields(kubeletExtraArgs)
+		if cluster := ctx.GetDiscoveredState().GetCluster(); cluster != nil && cluster.KubernetesNetworkConfig != nil {
+			data.ServiceCIDR = aws.StringValue(cluster.KubernetesNetworkConfig.ServiceIpv4Cidr)
+		}
 	}
+	renderer := GetUserDataRenderer(osFamily)
 	out := &bytes.Buffer{}
-	tmpl := template.New("userData").Funcs(template.FuncMap{
-		"ToLower": strings.ToLower,
-	})
-	var err error
-	if tmpl, err = tmpl.Parse(UserDataTemplate); err != nil {
-		ctx.Log.Error(err, "failed to parse userData template")
+	if err := renderer.Render(out, data); err != nil {
+		ctx.Log.Error(err, "failed to render userData", "osFamily", osFamily)
+	}
+
+	// cloud-init can consume gzip compressed userdata, this allows larger scripts to fit under the size limit
+	if renderer.Compressible() && ctx.IsUserDataCompressed() {
+		compressed := &bytes.Buffer{}
+		writer := gzip.NewWriter(compressed)
+		if _, err := writer.Write(out.Bytes()); err != nil {
+			ctx.Log.Error(err, "failed to compress userData")
+		}
+		if err := writer.Close(); err != nil {
+			ctx.Log.Error(err, "failed to compress userData")
+		}
+		return base64.StdEncoding.EncodeToString(compressed.Bytes())
 	}
-	tmpl.Execute(out, data)
 	return base64.StdEncoding.EncodeToString(out.Bytes())
 }
 
-func (ctx *EksInstanceGroupContext) GetUserDataStages() UserDataPayload {
+// DecodeUserData returns the plain text of userData rendered by GetBasicUserData
+func (ctx *EksInstanceGroupContext) DecodeUserData(userData string) (string, error) {
+	decoded, err := base64.StdEncoding.DecodeString(userData)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to decode userData")
+	}
+	if GetUserDataRenderer(ctx.GetOsFamily()).Compressible() && ctx.IsUserDataCompressed() {
+		reader, err := gzip.NewReader(bytes.NewReader(decoded))
+		if err != nil {
+			return "", errors.Wrap(err, "failed to decompress userData")
+		}
+		defer reader.Close()
+		if decoded, err = io.ReadAll(reader); err != nil {
+			return "", errors.Wrap(err, "failed to decompress userData")
+		}
+	}
+	return string(decoded), nil
+}
 
+// SetRenderedUserData records a hash of the rendered userData in the status. When userData dumps are enabled on the
+// controller and requested via the dump-userdata annotation, the decoded userData is published as an event whenever it
+// differs from the last published userData, secret-like values are redacted and the payload is truncated to
+// UserDataDumpMaxLength
+func (ctx *EksInstanceGroupContext) SetRenderedUserData(userData string) {
 	var (
 		instanceGroup = ctx.GetInstanceGroup()
-		configuration = instanceGroup.GetEKSConfiguration()
-		userData      = configuration.GetUserData()
+		annotations   = instanceGroup.GetAnnotations()
+		status        = instanceGroup.GetStatus()
+		state         = ctx.GetDiscoveredState()
 	)
 
-	payload := UserDataPayload{}
+	plain, err := ctx.DecodeUserData(userData)
+	if err != nil {
+		ctx.Log.Error(err, "failed to decode rendered userData", "instancegroup", instanceGroup.NamespacedName())
+		return
+	}
 
-	for _, stage := range userData {
-		switch {
-		case strings.EqualFold(stage.Stage, v1alpha1.PreBootstrapStage):
-			data, err := common.GetDecodedString(stage.Data)
-			if err != nil {
-				ctx.Log.Error(err, "failed to decode base64 stage data", "stage", stage.Stage, "data", stage.Data)
-			}
-			payload.PreBootstrap = append(payload.PreBootstrap, data)
-		case strings.EqualFold(stage.Stage, v1alpha1.PostBootstrapStage):
-			data, err := common.GetDecodedString(stage.Data)
-			if err != nil {
-				ctx.Log.Error(err, "failed to decode base64 stage data", "stage", stage.Stage, "data", stage.Data)
-			}
-			payload.PostBootstrap = append(payload.PostBootstrap, data)
-		default:
-			ctx.Log.Info("invalid userdata stage will not be rendered", "stage", stage.Stage, "data", stage.Data)
-		}
+	hash := common.StringMD5(plain)
+	status.SetRenderedUserDataHash(hash)
+
+	// the last published hash is forgotten while dumps are off, so that they are published again once turned on
+	if !ctx.EnableUserDataDump || !strings.EqualFold(annotations[DumpUserDataAnnotation], "true") {
+		status.SetDumpedUserDataHash("")
+		return
 	}
-	return payload
+	if hash == status.GetDumpedUserDataHash() {
+		return
+	}
+	status.SetDumpedUserDataHash(hash)
+
+	plain = truncateUserData(RedactUserData(plain), UserDataDumpMaxLength)
+	state.Publisher.Publish(kubeprovider.UserDataRenderedEvent, "instancegroup", instanceGroup.NamespacedName(), "hash", hash, "userdata", plain)
 }
 
-func (ctx *EksInstanceGroupContext) GetMountOpts() []MountOpts {
+// truncateUserData truncates userData to at most max bytes without splitting a multi-byte character
+func truncateUserData(userData string, max int) string {
+	if len(userData) <= max {
+		return userData
+	}
+	n := max
+	for n > 0 && !utf8.RuneStart(userData[n]) {
+		n--
+	}
+	return userData[:n]
+}
+
+// RedactUserData replaces the values of secret-like assignments in userData, e.g. API_TOKEN=abc or password: abc
+func RedactUserData(userData string) string {
+	return UserDataSecretRegex.ReplaceAllString(userData, "${1}"+UserDataRedactedValue)
+}
+
+func (ctx *EksInstanceGroupContext) IsUserDataCompressed() bool {
 	var (
-		mountOpts     = make([]MountOpts, 0)
 		instanceGroup = ctx.GetInstanceGroup()
-		configuration = instanceGroup.GetEKSConfiguration()
-		volumes       = configuration.GetVolumes()
+		annotations   = instanceGroup.GetAnnotations()
 	)
+	return strings.EqualFold(annotations[CompressUserDataAnnotation], "true")
+}
 
-	for _, vol := range volumes {
-		if vol.MountOptions == nil {
-			continue
-		}
-		if !common.ContainsEqualFold(v1alpha1.AllowedFileSystemTypes, vol.MountOptions.FileSystem) {
-			ctx.Log.Error(errors.New("file system type unsupported"), "file-system", vol.MountOptions.FileSystem, "allowed-values", v1alpha1.AllowedFileSystemTypes)
-			continue
-		}
-		if common.StringEmpty(vol.MountOptions.Mount) || !strings.HasPrefix(vol.MountOptions.Mount, "/") {
-			ctx.Log.Error(errors.New("provided mount path is invalid"), "volume", vol.Name, "mount", vol.MountOptions.Mount)
-			continue
-		}
-
-		var persistance bool
-		if vol.MountOptions.Persistance == nil {
-			persistance = true
-		}
-
-		mountOpts = append(mountOpts, MountOpts{
-			FileSystem:  vol.MountOptions.FileSystem,
-			Device:      vol.Name,
-			Mount:       vol.MountOptions.Mount,
-			Persistance: persistance,
-		})
-	}
-	return mountOpts
+// IsMaxPodsCalculationDisabled returns true if max-pods should not be passed to the kubelet, e.g. when a CNI which does not
+// follow the ENI based pod density is used
+func (ctx *EksInstanceGroupContext) IsMaxPodsCalculationDisabled() bool {
+	var (
+		instanceGroup = ctx.GetInstanceGroup()
+		annotations   = instanceGroup.GetAnnotations()
+	)
+	return strings.EqualFold(annotations[MaxPodsCalculationAnnotation], MaxPodsCalculationDisabled)
 }
 
-func (ctx *EksInstanceGroupContext) GetAddedTags(asgName string) []*autoscaling.Tag {
+// IsForceDefaultVersion returns true if the scaling group should reference the launch template's default version, which
+// is always moved to the newest version as soon as it is created
+func (ctx *EksInstanceGroupContext) IsForceDefaultVersion() bool {
 	var (
-		tags             []*autoscaling.Tag
-		instanceGroup    = ctx.GetInstanceGroup()
-		configuration    = instanceGroup.GetEKSConfiguration()
-		clusterName      = configuration.GetClusterName()
-		annotations      = instanceGroup.GetAnnotations()
-		labels           = ctx.GetComputedLabels()
-		taints           = configuration.GetTaints()
-		osFamily         = ctx.GetOsFamily()
-		state            = ctx.GetDiscoveredState()
-		instanceTypeInfo = state.GetInstanceTypeInfo()
+		instanceGroup = ctx.GetInstanceGroup()
+		annotations   = instanceGroup.GetAnnotations()
 	)
+	return strings.EqualFold(annotations[ForceDefaultVersionAnnotation], "true")
+}
 
-	tags = append(tags, ctx.AwsWorker.NewTag("Name", asgName, asgName))
-	tags = append(tags, ctx.AwsWorker.NewTag(provisioners.TagKubernetesCluster, clusterName, asgName))
-	tags = append(tags, ctx.AwsWorker.NewTag(provisioners.TagClusterName, clusterName, asgName))
-	tags = append(tags, ctx.AwsWorker.NewTag(provisioners.TagInstanceGroupNamespace, instanceGroup.GetNamespace(), asgName))
-	tags = append(tags, ctx.AwsWorker.NewTag(provisioners.TagInstanceGroupName, instanceGroup.GetName(), asgName))
+// IsForceUpgradeRequested returns true if the force-upgrade annotation holds a token which differs from the last token
+// that forced an upgrade, regardless of whether the scaling configuration has drifted
+func (ctx *EksInstanceGroupContext) IsForceUpgradeRequested() bool {
+	var (
+		instanceGroup = ctx.GetInstanceGroup()
+		annotations   = instanceGroup.GetAnnotations()
+		status        = instanceGroup.GetStatus()
+		token         = annotations[ForceUpgradeAnnotation]
+	)
+	return !common.StringEmpty(token) && token != status.GetLastForceUpgradeToken()
+}
 
-	if annotations[ClusterAutoscalerEnabledAnnotation] == "true" {
-		tags = append(tags, ctx.AwsWorker.NewTag(fmt.Sprintf("k8s.io/cluster-autoscaler/%v", clusterName), "owned", asgName))
-		tags = append(tags, ctx.AwsWorker.NewTag("k8s.io/cluster-autoscaler/enabled", "true", asgName))
+// IsInstanceRefreshEnabled returns true if node rotation should be delegated to an autoscaling instance refresh
+func (ctx *EksInstanceGroupContext) IsInstanceRefreshEnabled() bool {
+	var (
+		instanceGroup = ctx.GetInstanceGroup()
+		annotations   = instanceGroup.GetAnnotations()
+	)
+	return strings.EqualFold(annotations[InstanceRefreshAnnotation], "true")
+}
 
-		switch strings.ToLower(osFamily) {
-		case OsFamilyWindows:
-			tags = append(tags, ctx.AwsWorker.NewTag("k8s.io/cluster-autoscaler/node-template/label/kubernetes.io/os", "windows", asgName))
-			tags = append(tags, ctx.AwsWorker.NewTag("k8s.io/cluster-autoscaler/node-template/label/kubernetes.io/arch", "amd64", asgName))
+// GetInstanceRefreshPreferences returns the preferences of an instance refresh, scale-in protected instances are
+// replaced and standby instances are left to the operator, checkpoints are only set if configured
+func (ctx *EksInstanceGroupContext) GetInstanceRefreshPreferences() *autoscaling.RefreshPreferences {
+	var (
+		strategy = ctx.GetUpgradeStrategy()
+		refresh  = strategy.GetInstanceRefresh()
+	)
+	preferences := &autoscaling.RefreshPreferences{
+		ScaleInProtectedInstances: aws.String(InstanceRefreshScaleInProtectedInstances),
+		StandbyInstances:          aws.String(InstanceRefreshStandbyInstances),
+	}
+	if refresh == nil || len(refresh.CheckpointPercentages) == 0 {
+		return preferences
+	}
+	preferences.CheckpointPercentages = aws.Int64Slice(refresh.CheckpointPercentages)
+	if refresh.CheckpointDelay != 0 {
+		preferences.CheckpointDelay = aws.Int64(refresh.CheckpointDelay)
+	}
+	return preferences
+}
 
-			instanceTypeNetworkInfo := awsprovider.GetInstanceTypeNetworkInfo(instanceTypeInfo, configuration.InstanceType)
-			if instanceTypeNetworkInfo != nil {
-				numberOfIps := aws.Int64Value(instanceTypeNetworkInfo.Ipv4AddressesPerInterface) - 1
-				tags = append(tags, ctx.AwsWorker.NewTag("k8s.io/cluster-autoscaler/node-template/resources/vpc.amazonaws.com/PrivateIPv4Address", strconv.FormatInt(numberOfIps, 10), asgName))
-			}
-		default:
-			tags = append(tags, ctx.AwsWorker.NewTag("k8s.io/cluster-autoscaler/node-template/label/kubernetes.io/os", "linux", asgName))
-		}
+// IsManagedPoliciesReplaced returns true if only the user supplied managed policies should be attached to the role
+func (ctx *EksInstanceGroupContext) IsManagedPoliciesReplaced() bool {
+	var (
+		instanceGroup = ctx.GetInstanceGroup()
+		annotations   = instanceGroup.GetAnnotations()
+	)
+	return strings.EqualFold(annotations[ManagedPoliciesAnnotation], ManagedPoliciesModeReplace)
+}
 
-		for label, labelValue := range labels {
-			tags = append(tags, ctx.AwsWorker.NewTag(fmt.Sprintf("k8s.io/cluster-autoscaler/node-template/label/%v", label), labelValue, asgName))
-		}
+// IsCapacityTypeLabelEnabled returns true if nodes should be labeled with their capacity type
+func (ctx *EksInstanceGroupContext) IsCapacityTypeLabelEnabled() bool {
+	var (
+		instanceGroup = ctx.GetInstanceGroup()
+		annotations   = instanceGroup.GetAnnotations()
+	)
+	return strings.EqualFold(annotations[CapacityTypeLabelAnnotation], "true")
+}
 
-		for _, taint := range taints {
-			tagValue := fmt.Sprintf("%v:%v", taint.Value, taint.Effect)
-			tag := ctx.AwsWorker.NewTag(fmt.Sprintf("k8s.io/cluster-autoscaler/node-template/taint/%s", taint.Key), tagValue, asgName)
-			tags = append(tags, tag)
-		}
-	}
+// IsInstanceGroupLabelEnabled returns true if nodes should be labeled with the name of their instance group
+func (ctx *EksInstanceGroupContext) IsInstanceGroupLabelEnabled() bool {
+	var (
+		instanceGroup = ctx.GetInstanceGroup()
+		annotations   = instanceGroup.GetAnnotations()
+	)
+	return strings.EqualFold(annotations[InstanceGroupLabelAnnotation], "true")
+}
 
-	// custom tags
-	for _, tagSlice := range configuration.GetTags() {
-		tags = append(tags, ctx.AwsWorker.NewTag(tagSlice["key"], tagSlice["value"], asgName))
+// GetLaunchTemplateVersion returns the launch template version the scaling group should reference, the default version of
+// an existing launch template is not managed by the controller so the latest version is always referenced
+func (ctx *EksInstanceGroupContext) GetLaunchTemplateVersion() string {
+	if ctx.IsForceDefaultVersion() && !ctx.HasExistingLaunchTemplate() {
+		return awsprovider.LaunchTemplateDefaultVersionKey
 	}
-	return tags
+	return awsprovider.LaunchTemplateLatestVersionKey
 }
 
-func (ctx *EksInstanceGroupContext) GetRemovedTags(asgName string) []*autoscaling.Tag {
+// ClusterVersionMatches returns whether the discovered cluster version satisfies a semver constraint
+func (ctx *EksInstanceGroupContext) ClusterVersionMatches(constraint string) (bool, error) {
 	var (
-		removal      []*autoscaling.Tag
-		state        = ctx.GetDiscoveredState()
-		scalingGroup = state.GetScalingGroup()
-		addedTags    = ctx.GetAddedTags(asgName)
+		state          = ctx.GetDiscoveredState()
+		clusterVersion = state.GetClusterVersion()
 	)
 
-	for _, tag := range scalingGroup.Tags {
-		var match bool
-		for _, t := range addedTags {
-			if aws.StringValue(t.Key) == aws.StringValue(tag.Key) {
-				match = true
-			}
-		}
-		if !match {
-			matchedTag := ctx.AwsWorker.NewTag(aws.StringValue(tag.Key), aws.StringValue(tag.Value), asgName)
-			removal = append(removal, matchedTag)
-		}
+	ver, err := semver.NewVersion(clusterVersion)
+	if err != nil {
+		return false, errors.Wrapf(err, "failed to parse cluster version '%v'", clusterVersion)
 	}
 
-	return removal
+	c, err := semver.NewConstraint(constraint)
+	if err != nil {
+		return false, errors.Wrapf(err, "failed to parse version constraint '%v'", constraint)
+	}
+	return c.Check(ver), nil
 }
 
-func (ctx *EksInstanceGroupContext) UpdateScalingProcesses(asgName string) error {
+// GetLegacyRoleLabelConstraint returns the constraint matching cluster versions whose nodes are labeled with the old style
+// role label
+func (ctx *EksInstanceGroupContext) GetLegacyRoleLabelConstraint() string {
+	cutoff := ctx.LegacyRoleLabelCutoff
+	if common.StringEmpty(cutoff) {
+		cutoff = DefaultLegacyRoleLabelCutoff
+	}
+	return fmt.Sprintf("< %v-0", cutoff)
+}
+
+// ValidateOsFamily returns an error if the os-family annotation is set to an unsupported value, an absent annotation
+// defaults to amazonlinux2
+func (ctx *EksInstanceGroupContext) ValidateOsFamily() error {
 	var (
-		instanceGroup         = ctx.GetInstanceGroup()
-		configuration         = instanceGroup.GetEKSConfiguration()
-		state                 = ctx.GetDiscoveredState()
-		scalingGroup          = state.GetScalingGroup()
-		specSuspendProcesses  = configuration.GetSuspendProcesses()
-		groupSuspendProcesses []string
+		instanceGroup = ctx.GetInstanceGroup()
+		annotations   = instanceGroup.GetAnnotations()
 	)
 
-	// handle 'all' metrics provided
-	if common.ContainsEqualFold(specSuspendProcesses, "all") {
-		specSuspendProcesses = awsprovider.DefaultSuspendProcesses
+	v, exists := annotations[OsFamilyAnnotation]
+	if !exists {
+		return nil
 	}
+	if !common.ContainsEqualFold(AllowedOsFamilies, v) {
+		return errors.Errorf("validation failed, annotation '%v' has unsupported value '%v', allowed values are %v", OsFamilyAnnotation, v, AllowedOsFamilies)
+	}
+	return nil
+}
 
-	for _, element := range scalingGroup.SuspendedProcesses {
-		groupSuspendProcesses = append(groupSuspendProcesses, *element.ProcessName)
+// ValidateBootstrapOptions returns an error if the configured bootstrap options are not supported by the cluster version
+func (ctx *EksInstanceGroupContext) ValidateBootstrapOptions() error {
+	var (
+		instanceGroup    = ctx.GetInstanceGroup()
+		configuration    = instanceGroup.GetEKSConfiguration()
+		bootstrapOptions = configuration.BootstrapOptions
+		state            = ctx.GetDiscoveredState()
+		clusterVersion   = state.GetClusterVersion()
+	)
+
+	if bootstrapOptions == nil {
+		return nil
 	}
 
-	if suspend := common.Difference(specSuspendProcesses, groupSuspendProcesses); len(suspend) > 0 {
-		if err := ctx.AwsWorker.SetSuspendProcesses(asgName, suspend); err != nil {
+	switch bootstrapOptions.ContainerRuntime {
+	case v1alpha1.ContainerDRuntime:
+		supported, err := ctx.ClusterVersionMatches(ContainerdSupportedConstraint)
+		if err != nil {
 			return err
 		}
-		ctx.Log.Info("suspended scaling processes", "instancegroup", instanceGroup.NamespacedName(), "scalinggroup", asgName, "processes", suspend)
-	}
-
-	if resume := common.Difference(groupSuspendProcesses, specSuspendProcesses); len(resume) > 0 {
-		if err := ctx.AwsWorker.SetResumeProcesses(asgName, resume); err != nil {
+		if !supported {
+			return errors.Errorf("validation failed, 'bootstrapOptions.containerRuntime' cannot be '%v' for cluster version %v, requires kubernetes 1.21 or above", v1alpha1.ContainerDRuntime, clusterVersion)
+		}
+	case v1alpha1.DockerRuntime:
+		removed, err := ctx.ClusterVersionMatches(DockershimRemovedConstraint)
+		if err != nil {
 			return err
 		}
-		ctx.Log.Info("resumed scaling processes", "instancegroup", instanceGroup.NamespacedName(), "scalinggroup", asgName, "processes", resume)
+		if removed {
+			return errors.Errorf("validation failed, 'bootstrapOptions.containerRuntime' cannot be '%v' for cluster version %v, use '%v' instead", v1alpha1.DockerRuntime, clusterVersion, v1alpha1.ContainerDRuntime)
+		}
 	}
-
 	return nil
 }
 
-func (ctx *EksInstanceGroupContext) GetTaintList() []string {
+// UpdateNodeLabels ensures the lifecycle and instance type labels are present on the group's member nodes, this is only
+// done when node relabeling is enabled
+func (ctx *EksInstanceGroupContext) UpdateNodeLabels() error {
 	var (
-		taintList     []string
 		instanceGroup = ctx.GetInstanceGroup()
-		configuration = instanceGroup.GetEKSConfiguration()
-		taints        = configuration.GetTaints()
+		status        = instanceGroup.GetStatus()
+		lifecycle     = status.GetLifecycle()
+		state         = ctx.GetDiscoveredState()
+		scalingGroup  = state.GetScalingGroup()
 	)
 
-	if len(taints) > 0 {
-		for _, t := range taints {
-			taintList = append(taintList, fmt.Sprintf("%v=%v:%v", t.Key, t.Value, t.Effect))
+	if !ctx.NodeRelabel {
+		return nil
+	}
+
+	instanceTypes := make(map[string]string)
+	if scalingGroup != nil {
+		for _, instance := range scalingGroup.Instances {
+			instanceTypes[aws.StringValue(instance.InstanceId)] = aws.StringValue(instance.InstanceType)
 		}
 	}
-	sort.Strings(taintList)
-	return taintList
+
+	for _, node := range ctx.GetMemberNodes() {
+		nodeLabels := node.GetLabels()
+		patchLabels := make(map[string]string)
+		if !common.StringEmpty(lifecycle) && nodeLabels[InstanceMgrLifecycleLabel] != lifecycle {
+			patchLabels[InstanceMgrLifecycleLabel] = lifecycle
+		}
+
+		instanceId := common.GetLastElementBy(node.Spec.ProviderID, "/")
+		if _, ok := nodeLabels[InstanceTypeLabel]; !ok {
+			if instanceType, ok := instanceTypes[instanceId]; ok && !common.StringEmpty(instanceType) {
+				patchLabels[InstanceTypeLabel] = instanceType
+			}
+		}
+
+		if len(patchLabels) == 0 {
+			continue
+		}
+
+		patch := map[string]interface{}{
+			"metadata": map[string]interface{}{
+				"labels": patchLabels,
+			},
+		}
+		patchJSON, err := json.Marshal(patch)
+		if err != nil {
+			return errors.Wrap(err, "failed to marshal node labels")
+		}
+
+		if _, err := ctx.KubernetesClient.Kubernetes.CoreV1().Nodes().Patch(context.Background(), node.GetName(), types.MergePatchType, patchJSON, metav1.PatchOptions{}); err != nil {
+			return errors.Wrapf(err, "failed to patch labels of node %v", node.GetName())
+		}
+		ctx.Log.Info("updated node labels", "instancegroup", instanceGroup.NamespacedName(), "node", node.GetName(), "labels", patchLabels)
+	}
+	return nil
 }
 
-func (ctx *EksInstanceGroupContext) GetComputedLabels() map[string]string {
+// GetPropagatedAnnotations returns the instance group annotations which should be applied to its nodes
+func (ctx *EksInstanceGroupContext) GetPropagatedAnnotations() map[string]string {
 	var (
-		isOverride    bool
-		labelMap      = make(map[string]string)
 		instanceGroup = ctx.GetInstanceGroup()
-		status        = instanceGroup.GetStatus()
 		annotations   = instanceGroup.GetAnnotations()
-		configuration = instanceGroup.GetEKSConfiguration()
-		customLabels  = configuration.GetLabels()
+		propagated    = make(map[string]string)
 	)
 
-	// get custom labels
-	if len(customLabels) > 0 {
-		for k, v := range customLabels {
-			labelMap[k] = v
-		}
+	val, ok := annotations[PropagateAnnotationsAnnotation]
+	if !ok || common.StringEmpty(val) {
+		return propagated
 	}
 
-	// allow override default labels
-	if val, ok := annotations[OverrideDefaultLabelsAnnotation]; ok {
-		isOverride = true
-		overrideLabels := strings.Split(val, ",")
-		for _, label := range overrideLabels {
-			keyVal := strings.Split(label, "=")
-			if len(keyVal) == 2 {
-				labelMap[keyVal[0]] = keyVal[1]
-			} else {
-				labelMap[keyVal[0]] = ""
-			}
+	for _, key := range strings.Split(val, ",") {
+		key = strings.TrimSpace(key)
+		if common.StringEmpty(key) || key == PropagateAnnotationsAnnotation {
+			continue
+		}
+		if v, ok := annotations[key]; ok {
+			propagated[key] = v
 		}
 	}
+	return propagated
+}
 
-	if !isOverride {
-		// add default labels
-		labelMap[RoleNewLabel] = instanceGroup.GetName()
+// UpdateNodeAnnotations applies the propagated instance group annotations to the group's member nodes
+func (ctx *EksInstanceGroupContext) UpdateNodeAnnotations() error {
+	var (
+		instanceGroup = ctx.GetInstanceGroup()
+		annotations   = ctx.GetPropagatedAnnotations()
+	)
 
-		// add the old style role label if the cluster's k8s version is < 1.16
-		clusterVersion := ctx.DiscoveredState.GetClusterVersion()
-		ver, err := semver.NewVersion(clusterVersion)
-		if err != nil {
-			ctx.Log.Error(err, "Failed parsing the cluster's kubernetes version", "instancegroup", instanceGroup.NamespacedName())
-			labelMap[fmt.Sprintf(RoleOldLabel, instanceGroup.GetName())] = ""
-		} else {
-			c, _ := semver.NewConstraint("< 1.16-0")
-			if c.Check(ver) {
-				labelMap[fmt.Sprintf(RoleOldLabel, instanceGroup.GetName())] = ""
+	if len(annotations) == 0 {
+		return nil
+	}
+
+	for _, node := range ctx.GetMemberNodes() {
+		nodeAnnotations := node.GetAnnotations()
+		patchAnnotations := make(map[string]string)
+		for k, v := range annotations {
+			if existing, ok := nodeAnnotations[k]; !ok || existing != v {
+				patchAnnotations[k] = v
 			}
 		}
-	}
 
-	switch status.GetLifecycle() {
-	case v1alpha1.LifecycleStateNormal:
-		labelMap[InstanceMgrLifecycleLabel] = v1alpha1.LifecycleStateNormal
-	case v1alpha1.LifecycleStateSpot:
-		labelMap[InstanceMgrLifecycleLabel] = v1alpha1.LifecycleStateSpot
-	case v1alpha1.LifecycleStateMixed:
-		labelMap[InstanceMgrLifecycleLabel] = v1alpha1.LifecycleStateMixed
-	}
+		if len(patchAnnotations) == 0 {
+			continue
+		}
 
-	labelMap[InstanceMgrImageLabel] = configuration.GetImage()
+		patch := map[string]interface{}{
+			"metadata": map[string]interface{}{
+				"annotations": patchAnnotations,
+			},
+		}
+		patchJSON, err := json.Marshal(patch)
+		if err != nil {
+			return errors.Wrap(err, "failed to marshal node annotations")
+		}
 
-	return labelMap
+		if _, err := ctx.KubernetesClient.Kubernetes.CoreV1().Nodes().Patch(context.Background(), node.GetName(), types.MergePatchType, patchJSON, metav1.PatchOptions{}); err != nil {
+			return errors.Wrapf(err, "failed to patch annotations of node %v", node.GetName())
+		}
+		ctx.Log.Info("updated node annotations", "instancegroup", instanceGroup.NamespacedName(), "node", node.GetName(), "annotations", patchAnnotations)
+	}
+	return nil
 }
 
-func (ctx *EksInstanceGroupContext) GetLabelList() []string {
+// GetMemberNodes returns the nodes which run on instances of the discovered scaling group, unlike the role label they
+// also distinguish instance groups with the same name in different namespaces
+func (ctx *EksInstanceGroupContext) GetMemberNodes() []corev1.Node {
 	var (
-		labelList []string
+		state        = ctx.GetDiscoveredState()
+		nodes        = state.GetClusterNodes()
+		scalingGroup = state.GetScalingGroup()
+		members      = make([]corev1.Node, 0)
 	)
 
-	for key, value := range ctx.GetComputedLabels() {
-		if value == "" {
-			value = "\"\""
+	if nodes == nil || scalingGroup == nil {
+		return members
+	}
+
+	instanceIds := make([]string, 0)
+	for _, instance := range scalingGroup.Instances {
+		instanceIds = append(instanceIds, aws.StringValue(instance.InstanceId))
+	}
+
+	for _, node := range nodes.Items {
+		if common.ContainsString(instanceIds, common.GetLastElementBy(node.Spec.ProviderID, "/")) {
+			members = append(members, node)
 		}
-		labelList = append(labelList, fmt.Sprintf("%v=%v", key, value))
 	}
-	sort.Strings(labelList)
-	return labelList
+	return members
 }
 
-func (ctx *EksInstanceGroupContext) GetComputedBootstrapOptions() *v1alpha1.BootstrapOptions {
+// UpdateNodeTaints applies the configured taints to the group's nodes when node taint reconciliation is enabled. The
+// taints applied to a node are recorded in an annotation, so that taints which are removed from the spec are also
+// removed from the node, while taints added by others, e.g. the startup taint, are left untouched
+func (ctx *EksInstanceGroupContext) UpdateNodeTaints() error {
 	var (
 		instanceGroup = ctx.GetInstanceGroup()
-		state         = ctx.GetDiscoveredState()
+		annotations   = instanceGroup.GetAnnotations()
 		configuration = instanceGroup.GetEKSConfiguration()
+		desired       = configuration.GetTaints()
+		nodeClient    = ctx.KubernetesClient.Kubernetes.CoreV1().Nodes()
 	)
-	if instanceGroup.GetAnnotations()[CustomNetworkingEnabledAnnotation] == "true" {
-		hostNetworkPods, err := strconv.ParseInt(instanceGroup.GetAnnotations()[CustomNetworkingHostPodsAnnotation], 10, 64)
-		if err != nil {
-			hostNetworkPods = 2 //Default on EKS. Kube-Proxy and AWS VPC CNI
-		}
 
-		instanceTypeNetworkInfo := awsprovider.GetInstanceTypeNetworkInfo(state.GetInstanceTypeInfo(), configuration.InstanceType)
-		var prefixAssignmentEnabled = instanceGroup.GetAnnotations()[CustomNetworkingPrefixAssignmentEnabledAnnotation] == "true"
-		var maxPods int64 = 0
+	if !strings.EqualFold(annotations[ReconcileNodeTaintsAnnotation], "true") {
+		return nil
+	}
 
-		var enis = aws.Int64Value(instanceTypeNetworkInfo.MaximumNetworkInterfaces) - 1 //Primary interface is not used for pod networking when custom networking is enabled
-		var ipsPerInterface int64 = 1
-		if prefixAssignmentEnabled {
-			ipsPerInterface = 16 //Number of ips in a /28 block
-		}
+	desiredList := make([]string, 0)
+	for _, t := range desired {
+		desiredList = append(desiredList, formatTaint(t))
+	}
+	sort.Strings(desiredList)
+	appliedValue := strings.Join(desiredList, ",")
 
-		// Don't set maxPods above Kubernetes-recommended 110 per node for large clusters.
-		maxPods = common.Min(enis*((aws.Int64Value(instanceTypeNetworkInfo.Ipv4AddressesPerInterface)-1)*ipsPerInterface)+hostNetworkPods, 110)
+	for _, member := range ctx.GetMemberNodes() {
+		name := member.GetName()
 
-		if configuration.BootstrapOptions == nil {
-			return &v1alpha1.BootstrapOptions{
-				MaxPods: maxPods,
+		// taints are computed from a fresh read of the node, the patch fails if the node was modified since
+		err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+			node, err := nodeClient.Get(context.Background(), name, metav1.GetOptions{})
+			if err != nil {
+				return err
 			}
-		} else {
-			bootstrapOptions := *configuration.BootstrapOptions
-			if bootstrapOptions.MaxPods == 0 {
-				bootstrapOptions.MaxPods = maxPods
+
+			applied := make([]corev1.Taint, 0)
+			if val := node.GetAnnotations()[AppliedTaintsAnnotation]; !common.StringEmpty(val) {
+				for _, s := range strings.Split(val, ",") {
+					if t, err := parseTaint(s); err == nil {
+						applied = append(applied, t)
+					}
+				}
 			}
-			return &bootstrapOptions
+
+			taints, changed := getNodeTaintsUpdate(node.Spec.Taints, applied, desired)
+			if !changed && node.GetAnnotations()[AppliedTaintsAnnotation] == appliedValue {
+				return nil
+			}
+
+			patch := map[string]interface{}{
+				"metadata": map[string]interface{}{
+					"resourceVersion": node.GetResourceVersion(),
+					"annotations": map[string]string{
+						AppliedTaintsAnnotation: appliedValue,
+					},
+				},
+				"spec": map[string]interface{}{
+					"taints": taints,
+				},
+			}
+			patchJSON, err := json.Marshal(patch)
+			if err != nil {
+				return errors.Wrap(err, "failed to marshal node taints")
+			}
+
+			if _, err := nodeClient.Patch(context.Background(), name, types.MergePatchType, patchJSON, metav1.PatchOptions{}); err != nil {
+				return err
+			}
+			ctx.Log.Info("updated node taints", "instancegroup", instanceGroup.NamespacedName(), "node", name, "taints", desiredList)
+			return nil
+		})
+		if err != nil {
+			return errors.Wrapf(err, "failed to patch taints of node %v", name)
 		}
 	}
-	return configuration.BootstrapOptions
+	return nil
 }
 
-func (ctx *EksInstanceGroupContext) GetBootstrapArgs() string {
+// getNodeTaintsUpdate returns the taints of a node after applying the desired taints and removing previously applied
+// taints which are no longer desired, and whether they differ from the current taints
+func getNodeTaintsUpdate(current, applied, desired []corev1.Taint) ([]corev1.Taint, bool) {
 	var (
-		bootstrapOptions = ctx.GetComputedBootstrapOptions()
-		state            = ctx.GetDiscoveredState()
-		osFamily         = ctx.GetOsFamily()
-		cluster          = state.GetCluster()
-		clusterIP        = ctx.AwsWorker.GetDNSClusterIP(cluster)
+		changed bool
+		taints  = make([]corev1.Taint, 0)
 	)
-	var sb strings.Builder
-	switch strings.ToLower(osFamily) {
-	case OsFamilyWindows:
-		if state.Cluster != nil && !ctx.DisableWinClusterInjection {
-			sb.WriteString(fmt.Sprintf("-Base64ClusterCA %v ", aws.StringValue(state.Cluster.CertificateAuthority.Data)))
-			sb.WriteString(fmt.Sprintf("-APIServerEndpoint %v ", aws.StringValue(state.Cluster.Endpoint)))
-		}
-		if bootstrapOptions != nil && bootstrapOptions.ContainerRuntime != "" {
-			sb.WriteString(fmt.Sprintf("-ContainerRuntime %v ", bootstrapOptions.ContainerRuntime))
-		}
-		sb.WriteString(fmt.Sprintf("-KubeletExtraArgs '%v'", ctx.GetKubeletExtraArgs()))
-	case OsFamilyAmazonLinux2:
-		if bootstrapOptions != nil && bootstrapOptions.MaxPods > 0 {
-			sb.WriteString("--use-max-pods false ")
-		}
 
-		if bootstrapOptions != nil && bootstrapOptions.ContainerRuntime != "" {
-			sb.WriteString(fmt.Sprintf("--container-runtime %v ", bootstrapOptions.ContainerRuntime))
-		}
-		if state.Cluster != nil {
-			sb.WriteString(fmt.Sprintf("--b64-cluster-ca %v ", aws.StringValue(state.Cluster.CertificateAuthority.Data)))
-			sb.WriteString(fmt.Sprintf("--apiserver-endpoint %v ", aws.StringValue(state.Cluster.Endpoint)))
-			if !common.StringEmpty(clusterIP) {
-				sb.WriteString(fmt.Sprintf("--dns-cluster-ip %v ", clusterIP))
+	matches := func(list []corev1.Taint, t corev1.Taint) (corev1.Taint, bool) {
+		for _, other := range list {
+			if t.MatchTaint(&other) {
+				return other, true
 			}
 		}
+		return corev1.Taint{}, false
+	}
 
-		sb.WriteString(fmt.Sprintf("--kubelet-extra-args '%v'", ctx.GetKubeletExtraArgs()))
+	for _, t := range current {
+		if d, ok := matches(desired, t); ok {
+			if d.Value != t.Value {
+				changed = true
+				continue
+			}
+			taints = append(taints, t)
+			continue
+		}
+		if _, ok := matches(applied, t); ok {
+			changed = true
+			continue
+		}
+		taints = append(taints, t)
 	}
 
-	return sb.String()
+	for _, d := range desired {
+		if _, ok := matches(taints, d); !ok {
+			taints = append(taints, d)
+			changed = true
+		}
+	}
+	return taints, changed
 }
 
-func (ctx *EksInstanceGroupContext) GetKubeletExtraArgs() string {
+// GetReadinessDaemonSets returns the daemonsets which must have running pods on a node before it is considered ready,
+// and false if daemonset readiness is not enabled
+func (ctx *EksInstanceGroupContext) GetReadinessDaemonSets() ([]string, bool) {
 	var (
-		instanceGroup    = ctx.GetInstanceGroup()
-		configuration    = instanceGroup.GetEKSConfiguration()
-		bootstrapArgs    = configuration.GetBootstrapArguments()
-		bootstrapOptions = ctx.GetComputedBootstrapOptions()
+		instanceGroup = ctx.GetInstanceGroup()
+		annotations   = instanceGroup.GetAnnotations()
 	)
 
-	labelsFlag := fmt.Sprintf("--node-labels=%v", strings.Join(ctx.GetLabelList(), ","))
-	taintsFlag := fmt.Sprintf("--register-with-taints=%v", strings.Join(ctx.GetTaintList(), ","))
-	var sb strings.Builder
-	sb.WriteString(fmt.Sprintf("%v %v %v", labelsFlag, taintsFlag, bootstrapArgs))
-	if bootstrapOptions != nil && bootstrapOptions.MaxPods > 0 {
-		sb.WriteString(fmt.Sprintf(" --max-pods=%v", bootstrapOptions.MaxPods))
+	if !strings.EqualFold(annotations[DaemonSetReadinessAnnotation], "true") {
+		return nil, false
 	}
-	return sb.String()
+
+	val, ok := annotations[DaemonSetReadinessNamesAnnotation]
+	if !ok || common.StringEmpty(val) {
+		return DefaultReadinessDaemonSets, true
+	}
+
+	daemonSets := make([]string, 0)
+	for _, name := range strings.Split(val, ",") {
+		name = strings.TrimSpace(name)
+		if common.StringEmpty(name) {
+			continue
+		}
+		daemonSets = append(daemonSets, name)
+	}
+	return daemonSets, true
 }
 
-func (ctx *EksInstanceGroupContext) discoverSpotPrice() error {
+// IsDaemonSetPodsReady returns true if the required daemonset pods are running on all nodes backing instanceIds
+func (ctx *EksInstanceGroupContext) IsDaemonSetPodsReady(instanceIds []string) (bool, error) {
 	var (
-		instanceGroup    = ctx.GetInstanceGroup()
-		state            = ctx.GetDiscoveredState()
-		status           = instanceGroup.GetStatus()
-		configuration    = instanceGroup.GetEKSConfiguration()
-		scalingGroup     = state.GetScalingGroup()
-		scalingGroupName = aws.StringValue(scalingGroup.AutoScalingGroupName)
+		state = ctx.GetDiscoveredState()
+		nodes = state.GetClusterNodes()
 	)
 
-	// Ignore recommendations until instance group is provisioned
-	if !state.IsProvisioned() {
-		return nil
+	daemonSets, ok := ctx.GetReadinessDaemonSets()
+	if !ok || len(daemonSets) == 0 {
+		return true, nil
 	}
 
-	// get latest spot recommendations from events
-	recommendation, err := kubeprovider.GetSpotRecommendation(ctx.KubernetesClient.Kubernetes, scalingGroupName)
+	pods, err := ctx.KubernetesClient.Kubernetes.CoreV1().Pods(DaemonSetReadinessNamespace).List(context.Background(), metav1.ListOptions{})
 	if err != nil {
-		configuration.SetSpotPrice("")
-		return err
+		return false, errors.Wrap(err, "failed to list daemonset pods")
 	}
 
-	// in the case there are no recommendations, which should turn of spot unless it's manually set
-	if reflect.DeepEqual(recommendation, kubeprovider.SpotRecommendation{}) {
-		// if it was not using a recommendation before and spec has a spot price it means it was manually configured
-		if !status.GetUsingSpotRecommendation() && configuration.GetSpotPrice() != "" {
-			ctx.Log.Info("using manually configured spot price", "instancegroup", instanceGroup.NamespacedName(), "spotPrice", configuration.GetSpotPrice())
-		} else {
+	for _, id := range instanceIds {
+		for _, node := range nodes.Items {
+			if common.GetLastElementBy(node.Spec.ProviderID, "/") != id {
+				continue
+			}
+			if !kubeprovider.IsDaemonSetPodsRunning(pods, node.GetName(), daemonSets) {
+				ctx.Log.Info("waiting for daemonset pods", "node", node.GetName(), "daemonsets", daemonSets)
+				return false, nil
+			}
+		}
+	}
+	return true, nil
+}
+
+// GetReadinessInstanceTypes returns the instance types which must each have at least one ready node before a mixed
+// instances group is considered ready, and false if instance type readiness is not enabled
+func (ctx *EksInstanceGroupContext) GetReadinessInstanceTypes() ([]string, bool) {
+	var (
+		instanceGroup = ctx.GetInstanceGroup()
+		annotations   = instanceGroup.GetAnnotations()
+		configuration = instanceGroup.GetEKSConfiguration()
+	)
+
+	val, ok := annotations[InstanceTypeReadinessAnnotation]
+	if !ok || common.StringEmpty(val) || strings.EqualFold(val, "false") {
+		return nil, false
+	}
+
+	if configuration.GetMixedInstancesPolicy() == nil {
+		return nil, false
+	}
+
+	// the primary instance type is required by default
+	if strings.EqualFold(val, "true") {
+		return []string{configuration.InstanceType}, true
+	}
+
+	instanceTypes := make([]string, 0)
+	for _, t := range strings.Split(val, ",") {
+		t = strings.TrimSpace(t)
+		if common.StringEmpty(t) {
+			continue
+		}
+		instanceTypes = append(instanceTypes, t)
+	}
+	return instanceTypes, true
+}
+
+// IsInstanceTypeQuorumReady returns true if every required instance type has at least one ready node backing instanceIds
+func (ctx *EksInstanceGroupContext) IsInstanceTypeQuorumReady(instanceIds []string) bool {
+	var (
+		state = ctx.GetDiscoveredState()
+		nodes = state.GetClusterNodes()
+	)
+
+	instanceTypes, ok := ctx.GetReadinessInstanceTypes()
+	if !ok || len(instanceTypes) == 0 {
+		return true
+	}
+
+	readyTypes := make([]string, 0)
+	for _, node := range nodes.Items {
+		id := common.GetLastElementBy(node.Spec.ProviderID, "/")
+		if !common.ContainsString(instanceIds, id) || !kubeprovider.IsNodeReady(node) {
+			continue
+		}
+		readyTypes = append(readyTypes, node.GetLabels()[InstanceTypeLabel])
+	}
+
+	for _, t := range instanceTypes {
+		if !common.ContainsEqualFold(readyTypes, t) {
+			ctx.Log.Info("waiting for ready node of required instance type", "instancegroup", ctx.GetInstanceGroup().NamespacedName(), "instancetype", t)
+			return false
+		}
+	}
+	return true
+}
+
+func (ctx *EksInstanceGroupContext) GetUserDataStages() UserDataPayload {
+
+	var (
+		instanceGroup = ctx.GetInstanceGroup()
+		configuration = instanceGroup.GetEKSConfiguration()
+		userData      = configuration.GetUserData()
+	)
+
+	payload := UserDataPayload{}
+
+	// merged stages may not be in spec order, sort by priority while keeping spec order for equal priorities
+	stages := make([]v1alpha1.UserDataStage, len(userData))
+	copy(stages, userData)
+	sort.SliceStable(stages, func(i, j int) bool {
+		return stages[i].Priority < stages[j].Priority
+	})
+
+	for _, stage := range stages {
+		switch {
+		case strings.EqualFold(stage.Stage, v1alpha1.PreBootstrapStage):
+			data, err := common.GetDecodedString(stage.Data)
+			if err != nil {
+				ctx.Log.Error(err, "failed to decode base64 stage data", "stage", stage.Stage, "data", stage.Data)
+			}
+			payload.PreBootstrap = append(payload.PreBootstrap, data)
+		case strings.EqualFold(stage.Stage, v1alpha1.PostBootstrapStage):
+			data, err := common.GetDecodedString(stage.Data)
+			if err != nil {
+				ctx.Log.Error(err, "failed to decode base64 stage data", "stage", stage.Stage, "data", stage.Data)
+			}
+			payload.PostBootstrap = append(payload.PostBootstrap, data)
+		default:
+			ctx.Log.Info("invalid userdata stage will not be rendered", "stage", stage.Stage, "data", stage.Data)
+		}
+	}
+	return payload
+}
+
+func (ctx *EksInstanceGroupContext) GetMountOpts() []MountOpts {
+	var (
+		mountOpts     = make([]MountOpts, 0)
+		instanceGroup = ctx.GetInstanceGroup()
+		configuration = instanceGroup.GetEKSConfiguration()
+		volumes       = configuration.GetVolumes()
+	)
+
+	for _, vol := range volumes {
+		if vol.MountOptions == nil {
+			continue
+		}
+		if !common.ContainsEqualFold(v1alpha1.AllowedFileSystemTypes, vol.MountOptions.FileSystem) {
+			ctx.Log.Error(errors.New("file system type unsupported"), "file-system", vol.MountOptions.FileSystem, "allowed-values", v1alpha1.AllowedFileSystemTypes)
+			continue
+		}
+		if common.StringEmpty(vol.MountOptions.Mount) || !strings.HasPrefix(vol.MountOptions.Mount, "/") {
+			ctx.Log.Error(errors.New("provided mount path is invalid"), "volume", vol.Name, "mount", vol.MountOptions.Mount)
+			continue
+		}
+
+		var persistance bool
+		if vol.MountOptions.Persistance == nil {
+			persistance = true
+		}
+
+		mountOpts = append(mountOpts, MountOpts{
+			FileSystem:  vol.MountOptions.FileSystem,
+			Device:      vol.Name,
+			Mount:       vol.MountOptions.Mount,
+			Persistance: persistance,
+		})
+	}
+	return mountOpts
+}
+
+func (ctx *EksInstanceGroupContext) GetAddedTags(asgName string) []*autoscaling.Tag {
+	var (
+		tags             []*autoscaling.Tag
+		instanceGroup    = ctx.GetInstanceGroup()
+		configuration    = instanceGroup.GetEKSConfiguration()
+		clusterName      = configuration.GetClusterName()
+		annotations      = instanceGroup.GetAnnotations()
+		labels           = ctx.GetComputedLabels()
+		taints           = configuration.GetTaints()
+		osFamily         = ctx.GetOsFamily()
+		state            = ctx.GetDiscoveredState()
+		instanceTypeInfo = state.GetInstanceTypeInfo()
+	)
+
+	tags = append(tags, ctx.AwsWorker.NewTag("Name", asgName, asgName))
+	tags = append(tags, ctx.AwsWorker.NewTag(provisioners.TagKubernetesCluster, clusterName, asgName))
+	tags = append(tags, ctx.AwsWorker.NewTag(provisioners.TagClusterName, clusterName, asgName))
+	tags = append(tags, ctx.AwsWorker.NewTag(provisioners.TagInstanceGroupNamespace, instanceGroup.GetNamespace(), asgName))
+	tags = append(tags, ctx.AwsWorker.NewTag(provisioners.TagInstanceGroupName, instanceGroup.GetName(), asgName))
+
+	if annotations[ClusterAutoscalerEnabledAnnotation] == "true" {
+		tags = append(tags, ctx.AwsWorker.NewTag(fmt.Sprintf("k8s.io/cluster-autoscaler/%v", clusterName), "owned", asgName))
+		tags = append(tags, ctx.AwsWorker.NewTag("k8s.io/cluster-autoscaler/enabled", "true", asgName))
+
+		switch strings.ToLower(osFamily) {
+		case OsFamilyWindows:
+			tags = append(tags, ctx.AwsWorker.NewTag("k8s.io/cluster-autoscaler/node-template/label/kubernetes.io/os", "windows", asgName))
+			tags = append(tags, ctx.AwsWorker.NewTag("k8s.io/cluster-autoscaler/node-template/label/kubernetes.io/arch", "amd64", asgName))
+
+			instanceTypeNetworkInfo := awsprovider.GetInstanceTypeNetworkInfo(instanceTypeInfo, configuration.InstanceType)
+			if instanceTypeNetworkInfo != nil {
+				numberOfIps := aws.Int64Value(instanceTypeNetworkInfo.Ipv4AddressesPerInterface) - 1
+				tags = append(tags, ctx.AwsWorker.NewTag("k8s.io/cluster-autoscaler/node-template/resources/vpc.amazonaws.com/PrivateIPv4Address", strconv.FormatInt(numberOfIps, 10), asgName))
+			}
+		default:
+			tags = append(tags, ctx.AwsWorker.NewTag("k8s.io/cluster-autoscaler/node-template/label/kubernetes.io/os", "linux", asgName))
+		}
+
+		for label, labelValue := range labels {
+			tags = append(tags, ctx.AwsWorker.NewTag(fmt.Sprintf("k8s.io/cluster-autoscaler/node-template/label/%v", label), labelValue, asgName))
+		}
+
+		for _, taint := range taints {
+			tagValue := fmt.Sprintf("%v:%v", taint.Value, taint.Effect)
+			tag := ctx.AwsWorker.NewTag(fmt.Sprintf("k8s.io/cluster-autoscaler/node-template/taint/%s", taint.Key), tagValue, asgName)
+			tags = append(tags, tag)
+		}
+	}
+
+	// custom tags
+	for _, tagSlice := range configuration.GetTags() {
+		tags = append(tags, ctx.AwsWorker.NewTag(tagSlice["key"], tagSlice["value"], asgName))
+	}
+	return tags
+}
+
+// GetVolumeTags returns the custom tags to apply to volumes created by the launch template
+func (ctx *EksInstanceGroupContext) GetVolumeTags() map[string]string {
+	var (
+		instanceGroup = ctx.GetInstanceGroup()
+		configuration = instanceGroup.GetEKSConfiguration()
+		tags          = make(map[string]string)
+	)
+
+	for _, tagSlice := range configuration.GetTags() {
+		tags[tagSlice["key"]] = tagSlice["value"]
+	}
+	return tags
+}
+
+// GetIAMTags returns the tags to apply to a managed role and instance-profile, the controller's identity tags followed by
+// the custom tags of the instance group. Custom tags which reuse a key of another tag are skipped, and custom tags beyond
+// the IAM tag limit are not applied
+func (ctx *EksInstanceGroupContext) GetIAMTags() []*iam.Tag {
+	var (
+		instanceGroup = ctx.GetInstanceGroup()
+		configuration = instanceGroup.GetEKSConfiguration()
+		tags          = []*iam.Tag{
+			{Key: aws.String(provisioners.TagClusterName), Value: aws.String(configuration.GetClusterName())},
+			{Key: aws.String(provisioners.TagInstanceGroupNamespace), Value: aws.String(instanceGroup.GetNamespace())},
+			{Key: aws.String(provisioners.TagInstanceGroupName), Value: aws.String(instanceGroup.GetName())},
+		}
+		keys  = []string{provisioners.TagClusterName, provisioners.TagInstanceGroupNamespace, provisioners.TagInstanceGroupName}
+		added int
+	)
+
+	for _, tagSlice := range configuration.GetTags() {
+		key := tagSlice["key"]
+		// IAM tag keys are case insensitive
+		if common.ContainsEqualFold(keys, key) {
+			continue
+		}
+		if added == v1alpha1.IAMTagMaxCount {
+			ctx.Log.Info("tag limit reached, custom tags are not applied to IAM resources", "instancegroup", instanceGroup.NamespacedName(), "limit", v1alpha1.IAMTagMaxCount)
+			break
+		}
+		keys = append(keys, key)
+		tags = append(tags, &iam.Tag{Key: aws.String(key), Value: aws.String(tagSlice["value"])})
+		added++
+	}
+	return tags
+}
+
+func (ctx *EksInstanceGroupContext) GetRemovedTags(asgName string) []*autoscaling.Tag {
+	var (
+		removal      []*autoscaling.Tag
+		state        = ctx.GetDiscoveredState()
+		scalingGroup = state.GetScalingGroup()
+		addedTags    = ctx.GetAddedTags(asgName)
+	)
+
+	for _, tag := range scalingGroup.Tags {
+		var match bool
+		for _, t := range addedTags {
+			if aws.StringValue(t.Key) == aws.StringValue(tag.Key) {
+				match = true
+			}
+		}
+		if !match {
+			matchedTag := ctx.AwsWorker.NewTag(aws.StringValue(tag.Key), aws.StringValue(tag.Value), asgName)
+			removal = append(removal, matchedTag)
+		}
+	}
+
+	return removal
+}
+
+func (ctx *EksInstanceGroupContext) UpdateScalingProcesses(asgName string) error {
+	var (
+		instanceGroup         = ctx.GetInstanceGroup()
+		configuration         = instanceGroup.GetEKSConfiguration()
+		state                 = ctx.GetDiscoveredState()
+		scalingGroup          = state.GetScalingGroup()
+		specSuspendProcesses  = v1alpha1.NormalizeSuspendProcesses(configuration.GetSuspendProcesses())
+		groupSuspendProcesses []string
+	)
+
+	// handle 'all' processes provided, removing 'all' resumes every suspended process
+	if common.ContainsEqualFold(specSuspendProcesses, "all") {
+		specSuspendProcesses = awsprovider.DefaultSuspendProcesses
+	}
+
+	for _, element := range scalingGroup.SuspendedProcesses {
+		groupSuspendProcesses = append(groupSuspendProcesses, *element.ProcessName)
+	}
+
+	if suspend := common.Difference(specSuspendProcesses, groupSuspendProcesses); len(suspend) > 0 {
+		if err := ctx.AwsWorker.SetSuspendProcesses(asgName, suspend); err != nil {
+			return err
+		}
+		ctx.Log.Info("suspended scaling processes", "instancegroup", instanceGroup.NamespacedName(), "scalinggroup", asgName, "processes", suspend)
+	}
+
+	if resume := common.Difference(groupSuspendProcesses, specSuspendProcesses); len(resume) > 0 {
+		if err := ctx.AwsWorker.SetResumeProcesses(asgName, resume); err != nil {
+			return err
+		}
+		ctx.Log.Info("resumed scaling processes", "instancegroup", instanceGroup.NamespacedName(), "scalinggroup", asgName, "processes", resume)
+	}
+
+	return nil
+}
+
+func (ctx *EksInstanceGroupContext) GetTaintList() []string {
+	var (
+		taintList []string
+		taints    = ctx.GetComputedTaints()
+	)
+
+	if len(taints) > 0 {
+		for _, t := range taints {
+			taintList = append(taintList, formatTaint(t))
+		}
+	}
+	sort.Strings(taintList)
+	return taintList
+}
+
+// formatTaint renders a taint as key=value:effect, taints without a value are rendered as key:effect
+func formatTaint(t corev1.Taint) string {
+	if common.StringEmpty(t.Value) {
+		return fmt.Sprintf("%v:%v", t.Key, t.Effect)
+	}
+	return fmt.Sprintf("%v=%v:%v", t.Key, t.Value, t.Effect)
+}
+
+// GetStartupTaint returns the taint requested by the startup-taint annotation, the value is either "true" for the
+// DefaultStartupTaint, or a taint in the form key[=value]:effect which the CNI in use removes once it is ready
+func (ctx *EksInstanceGroupContext) GetStartupTaint() (corev1.Taint, bool) {
+	var (
+		instanceGroup = ctx.GetInstanceGroup()
+		annotations   = instanceGroup.GetAnnotations()
+		value         = annotations[StartupTaintAnnotation]
+	)
+
+	if common.StringEmpty(value) {
+		return corev1.Taint{}, false
+	}
+	if strings.EqualFold(value, "true") {
+		value = DefaultStartupTaint
+	}
+
+	taint, err := parseTaint(value)
+	if err != nil {
+		ctx.Log.Error(err, "failed to parse startup taint annotation", "instancegroup", instanceGroup.NamespacedName(), "value", value)
+		return corev1.Taint{}, false
+	}
+	return taint, true
+}
+
+// GetComputedTaints returns the configured taints, and the startup taint when it is enabled and not already configured
+func (ctx *EksInstanceGroupContext) GetComputedTaints() []corev1.Taint {
+	var (
+		instanceGroup = ctx.GetInstanceGroup()
+		configuration = instanceGroup.GetEKSConfiguration()
+		taints        = configuration.GetTaints()
+	)
+
+	startupTaint, ok := ctx.GetStartupTaint()
+	if !ok {
+		return taints
+	}
+	for _, t := range taints {
+		if t.MatchTaint(&startupTaint) {
+			return taints
+		}
+	}
+
+	computed := make([]corev1.Taint, 0, len(taints)+1)
+	computed = append(computed, taints...)
+	return append(computed, startupTaint)
+}
+
+// parseTaint parses a taint in the form key[=value]:effect
+func parseTaint(s string) (corev1.Taint, error) {
+	var taint corev1.Taint
+
+	parts := strings.Split(s, ":")
+	if len(parts) != 2 || common.StringEmpty(parts[0]) {
+		return taint, errors.Errorf("invalid taint '%v', must be in the form key[=value]:effect", s)
+	}
+	if !common.ContainsString(v1alpha1.AllowedTaintEffects, parts[1]) {
+		return taint, errors.Errorf("invalid taint '%v', effect must be one of %+v", s, v1alpha1.AllowedTaintEffects)
+	}
+
+	keyValue := strings.SplitN(parts[0], "=", 2)
+	taint.Key = keyValue[0]
+	if len(keyValue) == 2 {
+		taint.Value = keyValue[1]
+	}
+	taint.Effect = corev1.TaintEffect(parts[1])
+	return taint, nil
+}
+
+func (ctx *EksInstanceGroupContext) GetComputedLabels() map[string]string {
+	var (
+		isOverride    bool
+		labelMap      = make(map[string]string)
+		instanceGroup = ctx.GetInstanceGroup()
+		status        = instanceGroup.GetStatus()
+		annotations   = instanceGroup.GetAnnotations()
+		configuration = instanceGroup.GetEKSConfiguration()
+		customLabels  = configuration.GetLabels()
+	)
+
+	// get custom labels
+	if len(customLabels) > 0 {
+		for k, v := range customLabels {
+			labelMap[k] = v
+		}
+	}
+
+	// allow override default labels
+	if val, ok := annotations[OverrideDefaultLabelsAnnotation]; ok {
+		isOverride = true
+		overrideLabels := strings.Split(val, ",")
+		for _, label := range overrideLabels {
+			keyVal := strings.Split(label, "=")
+			if len(keyVal) == 2 {
+				labelMap[keyVal[0]] = keyVal[1]
+			} else {
+				labelMap[keyVal[0]] = ""
+			}
+		}
+	}
+
+	if !isOverride {
+		// add default labels
+		labelMap[RoleNewLabel] = instanceGroup.GetName()
+
+		// add the old style role label if the cluster's k8s version is below the cutoff version (default 1.16)
+		isLegacy, err := ctx.ClusterVersionMatches(ctx.GetLegacyRoleLabelConstraint())
+		if err != nil {
+			ctx.Log.Error(err, "Failed parsing the cluster's kubernetes version", "instancegroup", instanceGroup.NamespacedName())
+			labelMap[fmt.Sprintf(RoleOldLabel, instanceGroup.GetName())] = ""
+		} else if isLegacy {
+			labelMap[fmt.Sprintf(RoleOldLabel, instanceGroup.GetName())] = ""
+		}
+	}
+
+	switch status.GetLifecycle() {
+	case v1alpha1.LifecycleStateNormal:
+		labelMap[InstanceMgrLifecycleLabel] = v1alpha1.LifecycleStateNormal
+	case v1alpha1.LifecycleStateSpot:
+		labelMap[InstanceMgrLifecycleLabel] = v1alpha1.LifecycleStateSpot
+	case v1alpha1.LifecycleStateMixed:
+		labelMap[InstanceMgrLifecycleLabel] = v1alpha1.LifecycleStateMixed
+	}
+
+	labelMap[InstanceMgrImageLabel] = configuration.GetImage()
+
+	// mixed groups run both capacity types so the label cannot be set at the group level
+	if ctx.IsCapacityTypeLabelEnabled() {
+		if _, ok := labelMap[CapacityTypeLabel]; !ok {
+			switch status.GetLifecycle() {
+			case v1alpha1.LifecycleStateNormal:
+				labelMap[CapacityTypeLabel] = CapacityTypeOnDemand
+			case v1alpha1.LifecycleStateSpot:
+				labelMap[CapacityTypeLabel] = CapacityTypeSpot
+			}
+		}
+	}
+
+	// unlike the role label, the instance group label is not affected by the default labels override
+	if ctx.IsInstanceGroupLabelEnabled() {
+		if _, ok := labelMap[InstanceGroupLabel]; !ok {
+			labelMap[InstanceGroupLabel] = instanceGroup.GetName()
+		}
+	}
+
+	return labelMap
+}
+
+func (ctx *EksInstanceGroupContext) GetLabelList() []string {
+	var (
+		labelList []string
+	)
+
+	for key, value := range ctx.GetComputedLabels() {
+		if value == "" {
+			value = "\"\""
+		}
+		labelList = append(labelList, fmt.Sprintf("%v=%v", key, value))
+	}
+	sort.Strings(labelList)
+	return labelList
+}
+
+func (ctx *EksInstanceGroupContext) GetComputedBootstrapOptions() *v1alpha1.BootstrapOptions {
+	var (
+		instanceGroup = ctx.GetInstanceGroup()
+		state         = ctx.GetDiscoveredState()
+		configuration = instanceGroup.GetEKSConfiguration()
+	)
+	if ctx.IsMaxPodsCalculationDisabled() {
+		if configuration.BootstrapOptions == nil {
+			return nil
+		}
+		bootstrapOptions := *configuration.BootstrapOptions
+		bootstrapOptions.MaxPods = 0
+		return &bootstrapOptions
+	}
+	if instanceGroup.GetAnnotations()[CustomNetworkingEnabledAnnotation] == "true" {
+		hostNetworkPods, err := strconv.ParseInt(instanceGroup.GetAnnotations()[CustomNetworkingHostPodsAnnotation], 10, 64)
+		if err != nil {
+			hostNetworkPods = 2 //Default on EKS. Kube-Proxy and AWS VPC CNI
+		}
+
+		instanceTypeNetworkInfo := awsprovider.GetInstanceTypeNetworkInfo(state.GetInstanceTypeInfo(), configuration.InstanceType)
+		var prefixAssignmentEnabled = instanceGroup.GetAnnotations()[CustomNetworkingPrefixAssignmentEnabledAnnotation] == "true"
+		var maxPods int64 = 0
+
+		var enis = aws.Int64Value(instanceTypeNetworkInfo.MaximumNetworkInterfaces) - 1 //Primary interface is not used for pod networking when custom networking is enabled
+		var ipsPerInterface int64 = 1
+		if prefixAssignmentEnabled {
+			ipsPerInterface = 16 //Number of ips in a /28 block
+		}
+
+		// Don't set maxPods above Kubernetes-recommended 110 per node for large clusters.
+		maxPods = common.Min(enis*((aws.Int64Value(instanceTypeNetworkInfo.Ipv4AddressesPerInterface)-1)*ipsPerInterface)+hostNetworkPods, 110)
+
+		if configuration.BootstrapOptions == nil {
+			return &v1alpha1.BootstrapOptions{
+				MaxPods: maxPods,
+			}
+		} else {
+			bootstrapOptions := *configuration.BootstrapOptions
+			if bootstrapOptions.MaxPods == 0 {
+				bootstrapOptions.MaxPods = maxPods
+			}
+			return &bootstrapOptions
+		}
+	}
+	return configuration.BootstrapOptions
+}
+
+// GetClusterCA returns the cluster certificate authority, preferring the configured override over the discovered value
+func (ctx *EksInstanceGroupContext) GetClusterCA() string {
+	var (
+		configuration = ctx.GetInstanceGroup().GetEKSConfiguration()
+		state         = ctx.GetDiscoveredState()
+	)
+	if ca := configuration.GetClusterCA(); !common.StringEmpty(ca) {
+		return ca
+	}
+	return state.GetClusterCA()
+}
+
+// GetClusterEndpoint returns the cluster API server endpoint, preferring the configured override over the discovered value
+func (ctx *EksInstanceGroupContext) GetClusterEndpoint() string {
+	var (
+		configuration = ctx.GetInstanceGroup().GetEKSConfiguration()
+		state         = ctx.GetDiscoveredState()
+	)
+	if endpoint := configuration.GetApiServerEndpoint(); !common.StringEmpty(endpoint) {
+		return endpoint
+	}
+	return state.GetClusterEndpoint()
+}
+
+func (ctx *EksInstanceGroupContext) GetBootstrapArgs() string {
+	var (
+		bootstrapOptions = ctx.GetComputedBootstrapOptions()
+		state            = ctx.GetDiscoveredState()
+		osFamily         = ctx.GetOsFamily()
+		cluster          = state.GetCluster()
+		clusterIP        = ctx.AwsWorker.GetDNSClusterIP(cluster)
+		apiEndpoint      = ctx.GetClusterEndpoint()
+		clusterCA        = ctx.GetClusterCA()
+		injectCluster    = !common.StringEmpty(apiEndpoint) && !common.StringEmpty(clusterCA)
+	)
+	var sb strings.Builder
+	switch strings.ToLower(osFamily) {
+	case OsFamilyWindows:
+		if injectCluster && !ctx.DisableWinClusterInjection {
+			sb.WriteString(fmt.Sprintf("-Base64ClusterCA %v ", clusterCA))
+			sb.WriteString(fmt.Sprintf("-APIServerEndpoint %v ", apiEndpoint))
+			if !common.StringEmpty(clusterIP) {
+				sb.WriteString(fmt.Sprintf("-DNSClusterIP %v ", clusterIP))
+			}
+		}
+		if bootstrapOptions != nil && bootstrapOptions.ContainerRuntime != "" {
+			sb.WriteString(fmt.Sprintf("-ContainerRuntime %v ", bootstrapOptions.ContainerRuntime))
+		}
+		sb.WriteString(fmt.Sprintf("-KubeletExtraArgs '%v'", ctx.GetKubeletExtraArgs()))
+	case OsFamilyAmazonLinux2:
+		// the bootstrap script calculates max-pods from the ENI limits unless it is told otherwise
+		if (bootstrapOptions != nil && bootstrapOptions.MaxPods > 0) || ctx.IsMaxPodsCalculationDisabled() {
+			sb.WriteString("--use-max-pods false ")
+		}
+
+		if bootstrapOptions != nil && bootstrapOptions.ContainerRuntime != "" {
+			sb.WriteString(fmt.Sprintf("--container-runtime %v ", bootstrapOptions.ContainerRuntime))
+		}
+		if injectCluster {
+			sb.WriteString(fmt.Sprintf("--b64-cluster-ca %v ", clusterCA))
+			sb.WriteString(fmt.Sprintf("--apiserver-endpoint %v ", apiEndpoint))
+			if !common.StringEmpty(clusterIP) {
+				sb.WriteString(fmt.Sprintf("--dns-cluster-ip %v ", clusterIP))
+			}
+		}
+
+		sb.WriteString(fmt.Sprintf("--kubelet-extra-args '%v'", ctx.GetKubeletExtraArgs()))
+	}
+
+	return sb.String()
+}
+
+func (ctx *EksInstanceGroupContext) GetKubeletExtraArgs() string {
+	var (
+		instanceGroup    = ctx.GetInstanceGroup()
+		configuration    = instanceGroup.GetEKSConfiguration()
+		bootstrapArgs    = configuration.GetBootstrapArguments()
+		bootstrapOptions = ctx.GetComputedBootstrapOptions()
+	)
+
+	var structuredFlags []string
+	if bootstrapOptions != nil {
+		for _, f := range []struct {
+			flag   string
+			values map[string]string
+			sep    string
+		}{
+			{flag: "--system-reserved", values: bootstrapOptions.SystemReserved, sep: "="},
+			{flag: "--kube-reserved", values: bootstrapOptions.KubeReserved, sep: "="},
+			{flag: "--eviction-hard", values: bootstrapOptions.EvictionHard, sep: "<"},
+		} {
+			if len(f.values) == 0 {
+				continue
+			}
+			// structured options take precedence over the same flag passed in raw arguments
+			bootstrapArgs = removeFlag(bootstrapArgs, f.flag)
+			structuredFlags = append(structuredFlags, fmt.Sprintf("%v=%v", f.flag, joinMapValues(f.values, f.sep)))
+		}
+	}
+
+	// the credential provider config is written to amazonlinux2 and al2023 nodes, al2023 nodes receive these flags in
+	// the nodeadm NodeConfig
+	if bootstrapOptions != nil && bootstrapOptions.CredentialProvider != nil && ctx.IsCredentialProviderSupported() {
+		binDir := bootstrapOptions.CredentialProvider.BinDir
+		if common.StringEmpty(binDir) {
+			binDir = DefaultCredentialProviderBinDir
+		}
+		bootstrapArgs = removeFlag(bootstrapArgs, "--image-credential-provider-config")
+		bootstrapArgs = removeFlag(bootstrapArgs, "--image-credential-provider-bin-dir")
+		structuredFlags = append(structuredFlags,
+			fmt.Sprintf("--image-credential-provider-config=%v", CredentialProviderConfigPath),
+			fmt.Sprintf("--image-credential-provider-bin-dir=%v", binDir),
+		)
+	}
+
+	labelsFlag := fmt.Sprintf("--node-labels=%v", strings.Join(ctx.GetLabelList(), ","))
+	taintsFlag := fmt.Sprintf("--register-with-taints=%v", strings.Join(ctx.GetTaintList(), ","))
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("%v %v %v", labelsFlag, taintsFlag, bootstrapArgs))
+	if bootstrapOptions != nil && bootstrapOptions.MaxPods > 0 {
+		sb.WriteString(fmt.Sprintf(" --max-pods=%v", bootstrapOptions.MaxPods))
+	}
+	for _, flag := range structuredFlags {
+		sb.WriteString(fmt.Sprintf(" %v", flag))
+	}
+	return sb.String()
+}
+
+type credentialProviderConfig struct {
+	APIVersion string               `json:"apiVersion"`
+	Kind       string               `json:"kind"`
+	Providers  []credentialProvider `json:"providers"`
+}
+
+type credentialProvider struct {
+	Name                 string   `json:"name"`
+	MatchImages          []string `json:"matchImages"`
+	DefaultCacheDuration string   `json:"defaultCacheDuration"`
+	APIVersion           string   `json:"apiVersion"`
+	Args                 []string `json:"args,omitempty"`
+}
+
+// IsCredentialProviderSupported returns true if the OS family of the instance group can be configured with a credential provider
+func (ctx *EksInstanceGroupContext) IsCredentialProviderSupported() bool {
+	osFamily := ctx.GetOsFamily()
+	return strings.EqualFold(osFamily, OsFamilyAmazonLinux2) || strings.EqualFold(osFamily, OsFamilyAmazonLinux2023)
+}
+
+// GetCredentialProviderConfig renders the kubelet CredentialProviderConfig of the instance group's credential provider.
+// The config replaces the one of the EKS AMI, so the AMI's ECR credential provider is kept unless it is configured
+// explicitly or the provider binaries are in a different directory
+func (ctx *EksInstanceGroupContext) GetCredentialProviderConfig() string {
+	bootstrapOptions := ctx.GetComputedBootstrapOptions()
+	if bootstrapOptions == nil || bootstrapOptions.CredentialProvider == nil {
+		return ""
+	}
+
+	provider := bootstrapOptions.CredentialProvider
+	cacheDuration := provider.DefaultCacheDuration
+	if common.StringEmpty(cacheDuration) {
+		cacheDuration = DefaultCredentialProviderCacheDuration
+	}
+
+	providers := make([]credentialProvider, 0)
+	binDir := strings.TrimRight(provider.BinDir, "/")
+	if provider.Name != DefaultCredentialProviderName && (common.StringEmpty(binDir) || binDir == DefaultCredentialProviderBinDir) {
+		providers = append(providers, credentialProvider{
+			Name:                 DefaultCredentialProviderName,
+			MatchImages:          DefaultCredentialProviderMatchImages,
+			DefaultCacheDuration: DefaultCredentialProviderCacheDuration,
+			APIVersion:           "credentialprovider.kubelet.k8s.io/v1",
+		})
+	}
+	providers = append(providers, credentialProvider{
+		Name:                 provider.Name,
+		MatchImages:          provider.MatchImages,
+		DefaultCacheDuration: cacheDuration,
+		APIVersion:           "credentialprovider.kubelet.k8s.io/v1",
+		Args:                 provider.Args,
+	})
+
+	config := credentialProviderConfig{
+		APIVersion: "kubelet.config.k8s.io/v1",
+		Kind:       "CredentialProviderConfig",
+		Providers:  providers,
+	}
+	out, err := json.MarshalIndent(config, "", "  ")
+	if err != nil {
+		ctx.Log.Error(err, "failed to render credential provider config")
+		return ""
+	}
+	return string(out)
+}
+
+// joinMapValues renders a map as sorted key/value pairs, e.g. cpu=100m,memory=100Mi
+func joinMapValues(values map[string]string, sep string) string {
+	pairs := make([]string, 0, len(values))
+	for k, v := range values {
+		pairs = append(pairs, fmt.Sprintf("%v%v%v", k, sep, v))
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
+
+// removeFlag removes a flag and its value from a raw argument string, in either --flag=value or --flag value form
+func removeFlag(args, flag string) string {
+	var (
+		fields = strings.Fields(args)
+		kept   = make([]string, 0, len(fields))
+	)
+	for i := 0; i < len(fields); i++ {
+		switch {
+		case strings.HasPrefix(fields[i], flag+"="):
+			continue
+		case fields[i] == flag:
+			if i+1 < len(fields) && !strings.HasPrefix(fields[i+1], "-") {
+				i++
+			}
+			continue
+		}
+		kept = append(kept, fields[i])
+	}
+	return strings.Join(kept, " ")
+}
+
+// GetSpotPrice returns the spot price of the scaling configuration, mixed instance groups request spot instances through
+// the instances distribution of their scaling group instead, and launch templates only use it when opted in with spotMarketOptions
+func (ctx *EksInstanceGroupContext) GetSpotPrice() string {
+	var (
+		instanceGroup = ctx.GetInstanceGroup()
+		configuration = instanceGroup.GetEKSConfiguration()
+	)
+	if configuration.GetMixedInstancesPolicy() != nil {
+		return ""
+	}
+	if instanceGroup.GetEKSSpec().IsLaunchTemplate() && !configuration.GetSpotMarketOptions() {
+		return ""
+	}
+	return configuration.GetSpotPrice()
+}
+
+func (ctx *EksInstanceGroupContext) discoverSpotPrice() error {
+	var (
+		instanceGroup    = ctx.GetInstanceGroup()
+		state            = ctx.GetDiscoveredState()
+		status           = instanceGroup.GetStatus()
+		configuration    = instanceGroup.GetEKSConfiguration()
+		scalingGroup     = state.GetScalingGroup()
+		scalingGroupName = aws.StringValue(scalingGroup.AutoScalingGroupName)
+	)
+
+	// Ignore recommendations until instance group is provisioned
+	if !state.IsProvisioned() {
+		return nil
+	}
+
+	// get latest spot recommendations from events
+	recommendation, err := kubeprovider.GetSpotRecommendation(ctx.KubernetesClient.Kubernetes, ctx.SpotRecommendationSource, scalingGroupName)
+	if err != nil {
+		configuration.SetSpotPrice("")
+		return err
+	}
+
+	// in the case there are no recommendations, which should turn of spot unless it's manually set
+	if reflect.DeepEqual(recommendation, kubeprovider.SpotRecommendation{}) {
+		// if it was not using a recommendation before and spec has a spot price it means it was manually configured
+		if !status.GetUsingSpotRecommendation() && configuration.GetSpotPrice() != "" {
+			ctx.Log.Info("using manually configured spot price", "instancegroup", instanceGroup.NamespacedName(), "spotPrice", configuration.GetSpotPrice())
+		} else {
 			// if recommendation was used, set flag to false
 			status.SetUsingSpotRecommendation(false)
 		}
-		return nil
+		return nil
+	}
+
+	// set the recommendation given
+	status.SetUsingSpotRecommendation(true)
+
+	if recommendation.UseSpot {
+		ctx.Log.Info("spot enabled with spot price recommendation", "instancegroup", instanceGroup.NamespacedName(), "spotPrice", recommendation.SpotPrice)
+		configuration.SetSpotPrice(recommendation.SpotPrice)
+	} else {
+		ctx.Log.Info("spot disabled due to recommendation", "instancegroup", instanceGroup.NamespacedName())
+		configuration.SetSpotPrice("")
+	}
+	return nil
+}
+
+func (ctx *EksInstanceGroupContext) discoverSpotInterruptions() error {
+	var (
+		instanceGroup = ctx.GetInstanceGroup()
+		state         = ctx.GetDiscoveredState()
+		status        = instanceGroup.GetStatus()
+		scalingGroup  = state.GetScalingGroup()
+		nodes         = state.GetClusterNodes()
+		nodeNames     = make([]string, 0)
+	)
+
+	if scalingGroup == nil || nodes == nil {
+		return nil
+	}
+
+	for _, instance := range scalingGroup.Instances {
+		id := aws.StringValue(instance.InstanceId)
+		for _, node := range nodes.Items {
+			if common.GetLastElementBy(node.Spec.ProviderID, "/") == id {
+				nodeNames = append(nodeNames, node.GetName())
+			}
+		}
+	}
+
+	if len(nodeNames) == 0 {
+		return nil
+	}
+
+	// only consider interruptions which were not previously counted, event timestamps have a precision of seconds so the
+	// events observed at the time of the last counted interruption are told apart by their UID
+	var (
+		lastInterruption = status.GetLastSpotInterruptionTime()
+		countedUIDs      = status.GetLastSpotInterruptionUIDs()
+		interruptions    = make([]corev1.Event, 0)
+	)
+	events, err := kubeprovider.GetSpotInterruptions(ctx.KubernetesClient.Kubernetes, nodeNames, lastInterruption)
+	if err != nil {
+		return err
+	}
+
+	for _, event := range events {
+		if kubeprovider.GetEventTime(event).Equal(lastInterruption) && common.ContainsString(countedUIDs, string(event.GetUID())) {
+			continue
+		}
+		interruptions = append(interruptions, event)
+	}
+
+	if len(interruptions) == 0 {
+		return nil
+	}
+
+	for _, event := range interruptions {
+		eventTime := kubeprovider.GetEventTime(event)
+		switch {
+		case eventTime.After(lastInterruption):
+			lastInterruption = eventTime
+			countedUIDs = []string{string(event.GetUID())}
+		case eventTime.Equal(lastInterruption):
+			countedUIDs = append(countedUIDs, string(event.GetUID()))
+		}
+	}
+
+	ctx.Log.Info("spot interruptions detected", "instancegroup", instanceGroup.NamespacedName(), "count", len(interruptions))
+	status.IncSpotInterruptions(len(interruptions))
+	status.SetLastSpotInterruptionTime(lastInterruption)
+	status.SetLastSpotInterruptionUIDs(countedUIDs)
+	ctx.Metrics.AddSpotInterruptions(instanceGroup.NamespacedName(), len(interruptions))
+	return nil
+}
+
+// discoverScalingActivities sets the ScalingActivityFailed condition when the most recent completed scaling activity of
+// the scaling group failed, e.g. due to insufficient capacity, and removes it once an activity succeeds
+func (ctx *EksInstanceGroupContext) discoverScalingActivities() error {
+	var (
+		instanceGroup = ctx.GetInstanceGroup()
+		state         = ctx.GetDiscoveredState()
+		status        = instanceGroup.GetStatus()
+		scalingGroup  = state.GetScalingGroup()
+	)
+
+	if scalingGroup == nil {
+		return nil
+	}
+
+	activities, err := ctx.AwsWorker.DescribeScalingActivities(aws.StringValue(scalingGroup.AutoScalingGroupName), ScalingActivitiesMaxRecords)
+	if err != nil {
+		return err
+	}
+
+	// activities are returned newest first, activities which are still in progress are skipped
+	for _, activity := range activities {
+		switch aws.StringValue(activity.StatusCode) {
+		case autoscaling.ScalingActivityStatusCodeSuccessful:
+			status.RemoveCondition(v1alpha1.ScalingActivityFailed)
+			return nil
+		case autoscaling.ScalingActivityStatusCodeFailed:
+			message := aws.StringValue(activity.StatusMessage)
+			if status.GetConditionStatus(v1alpha1.ScalingActivityFailed) != corev1.ConditionTrue || status.GetConditionMessage(v1alpha1.ScalingActivityFailed) != message {
+				ctx.Log.Info("scaling activity failed", "instancegroup", instanceGroup.NamespacedName(), "activity", aws.StringValue(activity.Description), "reason", message)
+				state.Publisher.Publish(kubeprovider.ScalingActivityFailedEvent, "instancegroup", instanceGroup.NamespacedName(), "activity", aws.StringValue(activity.Description), "reason", message)
+			}
+			condition := v1alpha1.NewInstanceGroupCondition(v1alpha1.ScalingActivityFailed, corev1.ConditionTrue)
+			condition.Message = message
+			status.SetCondition(condition)
+			return nil
+		}
+	}
+	return nil
+}
+
+// discoverEndpointReachability sets the EndpointUnreachable condition when the cluster endpoint is private-only and some
+// of the group's subnets are outside of the cluster vpc, the condition is a warning and does not block the reconcile
+func (ctx *EksInstanceGroupContext) discoverEndpointReachability() {
+	var (
+		instanceGroup = ctx.GetInstanceGroup()
+		state         = ctx.GetDiscoveredState()
+		status        = instanceGroup.GetStatus()
+		vpcID         = state.GetVPCId()
+	)
+
+	if !state.IsPrivateEndpointOnly() {
+		status.RemoveCondition(v1alpha1.EndpointUnreachable)
+		return
+	}
+
+	unreachable, err := ctx.GetEndpointUnreachableSubnets()
+	if err != nil {
+		ctx.Log.Error(err, "failed to verify subnets can reach the private cluster endpoint", "instancegroup", instanceGroup.NamespacedName())
+		return
+	}
+
+	if len(unreachable) == 0 {
+		status.RemoveCondition(v1alpha1.EndpointUnreachable)
+		return
+	}
+
+	message := fmt.Sprintf("subnets %v are outside of the cluster vpc %v", strings.Join(unreachable, ","), vpcID)
+	if status.GetConditionStatus(v1alpha1.EndpointUnreachable) != corev1.ConditionTrue || status.GetConditionMessage(v1alpha1.EndpointUnreachable) != message {
+		ctx.Log.Info("subnets are outside of the cluster vpc and cannot reach the private-only cluster endpoint", "instancegroup", instanceGroup.NamespacedName(), "subnets", unreachable, "vpc", vpcID)
+		state.Publisher.Publish(kubeprovider.EndpointUnreachableEvent, "instancegroup", instanceGroup.NamespacedName(), "subnets", strings.Join(unreachable, ","), "vpc", vpcID)
+	}
+	condition := v1alpha1.NewInstanceGroupCondition(v1alpha1.EndpointUnreachable, corev1.ConditionTrue)
+	condition.Message = message
+	status.SetCondition(condition)
+}
+
+// discoverDeprecatedInstanceTypes sets the DeprecatedInstanceType condition when the group is configured with previous
+// generation instance types, the condition is a warning and does not block the reconcile
+func (ctx *EksInstanceGroupContext) discoverDeprecatedInstanceTypes() {
+	var (
+		instanceGroup        = ctx.GetInstanceGroup()
+		configuration        = instanceGroup.GetEKSConfiguration()
+		mixedInstancesPolicy = configuration.GetMixedInstancesPolicy()
+		state                = ctx.GetDiscoveredState()
+		status               = instanceGroup.GetStatus()
+		typeInfo             = state.GetInstanceTypeInfo()
+		instanceTypes        = []string{configuration.InstanceType}
+		deprecated           = make([]string, 0)
+	)
+
+	if mixedInstancesPolicy != nil {
+		for _, t := range mixedInstancesPolicy.InstanceTypes {
+			instanceTypes = append(instanceTypes, t.Type)
+		}
+	}
+
+	for _, t := range instanceTypes {
+		if awsprovider.IsPreviousGenerationInstanceType(typeInfo, t) && !common.ContainsString(deprecated, t) {
+			deprecated = append(deprecated, t)
+		}
+	}
+
+	if len(deprecated) == 0 {
+		status.RemoveCondition(v1alpha1.DeprecatedInstanceType)
+		return
+	}
+
+	message := fmt.Sprintf("instance types %v are previous generation", strings.Join(deprecated, ","))
+	if status.GetConditionStatus(v1alpha1.DeprecatedInstanceType) != corev1.ConditionTrue || status.GetConditionMessage(v1alpha1.DeprecatedInstanceType) != message {
+		ctx.Log.Info("previous generation instance types configured", "instancegroup", instanceGroup.NamespacedName(), "instancetypes", deprecated)
+		state.Publisher.Publish(kubeprovider.DeprecatedInstanceTypeEvent, "instancegroup", instanceGroup.NamespacedName(), "instancetypes", strings.Join(deprecated, ","))
+	}
+	condition := v1alpha1.NewInstanceGroupCondition(v1alpha1.DeprecatedInstanceType, corev1.ConditionTrue)
+	condition.Message = message
+	status.SetCondition(condition)
+}
+
+func (ctx *EksInstanceGroupContext) findOwnedScalingGroups(groups []*autoscaling.Group) []*autoscaling.Group {
+	var (
+		filteredGroups = make([]*autoscaling.Group, 0)
+		instanceGroup  = ctx.GetInstanceGroup()
+		configuration  = instanceGroup.GetEKSConfiguration()
+		clusterName    = configuration.GetClusterName()
+	)
+
+	for _, group := range groups {
+		for _, tag := range group.Tags {
+			var (
+				key   = aws.StringValue(tag.Key)
+				value = aws.StringValue(tag.Value)
+			)
+			// if group has the same cluster tag it's owned by the controller
+			if key == provisioners.TagClusterName && strings.EqualFold(value, clusterName) {
+				filteredGroups = append(filteredGroups, group)
+			}
+		}
+	}
+	return filteredGroups
+}
+
+func (ctx *EksInstanceGroupContext) findTargetScalingGroup(groups []*autoscaling.Group) *autoscaling.Group {
+	var (
+		instanceGroup = ctx.GetInstanceGroup()
+	)
+
+	for _, group := range groups {
+		var nameMatch, namespaceMatch bool
+		for _, tag := range group.Tags {
+			var (
+				key   = aws.StringValue(tag.Key)
+				value = aws.StringValue(tag.Value)
+			)
+			// must match both name and namespace tag
+			if key == provisioners.TagInstanceGroupName && value == instanceGroup.GetName() {
+				nameMatch = true
+			}
+			if key == provisioners.TagInstanceGroupNamespace && value == instanceGroup.GetNamespace() {
+				namespaceMatch = true
+			}
+		}
+		if nameMatch && namespaceMatch {
+			return group
+		}
+	}
+
+	return nil
+}
+
+// ScalingGroupDrifted compares the spec against the discovered scaling group, a difference while the spec generation
+// was already applied means the scaling group was modified outside of the controller
+func (ctx *EksInstanceGroupContext) ScalingGroupDrifted(group *autoscaling.Group) bool {
+	var (
+		instanceGroup = ctx.GetInstanceGroup()
+		spec          = instanceGroup.GetEKSSpec()
+		configuration = instanceGroup.GetEKSConfiguration()
+		status        = instanceGroup.GetStatus()
+		state         = ctx.GetDiscoveredState()
+		specSubnets   = state.GetSubnets()
+		drift         bool
+	)
+
+	if group == nil {
+		return false
+	}
+
+	// if the spec has changed since it was last applied, differences are expected
+	if status.GetAppliedGeneration() != instanceGroup.GetGeneration() {
+		return false
+	}
+
+	if minSize := aws.Int64Value(group.MinSize); minSize != spec.GetMinSize() {
+		ctx.Log.Info("detected drift", "reason", "scaling group min-size has changed", "instancegroup", instanceGroup.NamespacedName(),
+			"previousValue", spec.GetMinSize(),
+			"newValue", minSize,
+		)
+		drift = true
+	}
+
+	if maxSize := aws.Int64Value(group.MaxSize); maxSize != spec.GetMaxSize() {
+		ctx.Log.Info("detected drift", "reason", "scaling group max-size has changed", "instancegroup", instanceGroup.NamespacedName(),
+			"previousValue", spec.GetMaxSize(),
+			"newValue", maxSize,
+		)
+		drift = true
+	}
+
+	if healthCheckType := configuration.GetHealthCheckType(); !common.StringEmpty(healthCheckType) && !strings.EqualFold(healthCheckType, aws.StringValue(group.HealthCheckType)) {
+		ctx.Log.Info("detected drift", "reason", "scaling group health check type has changed", "instancegroup", instanceGroup.NamespacedName(),
+			"previousValue", healthCheckType,
+			"newValue", aws.StringValue(group.HealthCheckType),
+		)
+		drift = true
+	}
+
+	if gracePeriod := configuration.GetHealthCheckGracePeriod(); gracePeriod > 0 && gracePeriod != aws.Int64Value(group.HealthCheckGracePeriod) {
+		ctx.Log.Info("detected drift", "reason", "scaling group health check grace period has changed", "instancegroup", instanceGroup.NamespacedName(),
+			"previousValue", gracePeriod,
+			"newValue", aws.Int64Value(group.HealthCheckGracePeriod),
+		)
+		drift = true
+	}
+
+	if cooldown := configuration.GetDefaultCooldown(); cooldown > 0 && cooldown != aws.Int64Value(group.DefaultCooldown) {
+		ctx.Log.Info("detected drift", "reason", "scaling group default cooldown has changed", "instancegroup", instanceGroup.NamespacedName(),
+			"previousValue", cooldown,
+			"newValue", aws.Int64Value(group.DefaultCooldown),
+		)
+		drift = true
+	}
+
+	groupSubnets := strings.Split(aws.StringValue(group.VPCZoneIdentifier), ",")
+	if len(specSubnets) > 0 && !common.StringSliceEqualFold(specSubnets, groupSubnets) {
+		ctx.Log.Info("detected drift", "reason", "scaling group subnets have changed", "instancegroup", instanceGroup.NamespacedName(),
+			"previousValue", specSubnets,
+			"newValue", groupSubnets,
+		)
+		drift = true
+	}
+
+	var activeName, groupName string
+	switch {
+	case group.LaunchConfigurationName != nil:
+		activeName = status.GetActiveLaunchConfigurationName()
+		groupName = aws.StringValue(group.LaunchConfigurationName)
+	case group.LaunchTemplate != nil:
+		activeName = status.GetActiveLaunchTemplateName()
+		groupName = aws.StringValue(group.LaunchTemplate.LaunchTemplateName)
+	case group.MixedInstancesPolicy != nil && group.MixedInstancesPolicy.LaunchTemplate != nil:
+		activeName = status.GetActiveLaunchTemplateName()
+		groupName = aws.StringValue(group.MixedInstancesPolicy.LaunchTemplate.LaunchTemplateSpecification.LaunchTemplateName)
+	}
+
+	if !common.StringEmpty(activeName) && !strings.EqualFold(activeName, groupName) {
+		ctx.Log.Info("detected drift", "reason", "scaling group launch configuration has changed", "instancegroup", instanceGroup.NamespacedName(),
+			"previousValue", activeName,
+			"newValue", groupName,
+		)
+		drift = true
+	}
+
+	return drift
+}
+
+// GetNodeReadinessTimeout returns how long nodes may remain not ready before the instance group errors, 0 waits indefinitely
+func (ctx *EksInstanceGroupContext) GetNodeReadinessTimeout() time.Duration {
+	var (
+		instanceGroup = ctx.GetInstanceGroup()
+		spec          = instanceGroup.GetEKSSpec()
+	)
+	if timeout := spec.GetNodeReadinessTimeout(); timeout > 0 {
+		return time.Duration(timeout) * time.Second
+	}
+	return ctx.NodeReadinessTimeout
+}
+
+// UpdateNodeReadyCondition updates the NodesReady condition and returns true if the desired nodes are ready. The instance
+// group transitions to ReconcileErr when its nodes remain not ready for longer than the node readiness timeout
+func (ctx *EksInstanceGroupContext) UpdateNodeReadyCondition() bool {
+	var (
+		state         = ctx.GetDiscoveredState()
+		instanceGroup = ctx.GetInstanceGroup()
+		status        = instanceGroup.GetStatus()
+		timeout       = ctx.GetNodeReadinessTimeout()
+	)
+
+	if ctx.updateNodeReadyCondition() {
+		status.SetNodesNotReadySince(time.Time{})
+		return true
+	}
+
+	if state.GetScalingGroup() == nil {
+		return false
+	}
+
+	notReadySince := status.GetNodesNotReadySince()
+	if notReadySince.IsZero() {
+		status.SetNodesNotReadySince(time.Now())
+		return false
+	}
+
+	if timeout > 0 && time.Since(notReadySince) > timeout {
+		ctx.Log.Info("nodes did not become ready within timeout", "instancegroup", instanceGroup.NamespacedName(), "timeout", timeout, "notReadySince", notReadySince)
+		ctx.SetState(v1alpha1.ReconcileErr)
+		status.SetStateTransitionReason(NodeReadinessTimeoutReason)
+		state.NodeReadinessTimedOut = true
+	}
+	return false
+}
+
+func (ctx *EksInstanceGroupContext) updateNodeReadyCondition() bool {
+	var (
+		state         = ctx.GetDiscoveredState()
+		instanceGroup = ctx.GetInstanceGroup()
+		status        = instanceGroup.GetStatus()
+		scalingGroup  = state.GetScalingGroup()
+		desiredCount  = int(aws.Int64Value(scalingGroup.DesiredCapacity))
+		nodes         = state.GetClusterNodes()
+	)
+
+	if scalingGroup == nil {
+		return false
+	}
+
+	ctx.Log.Info("waiting for node readiness conditions", "instancegroup", instanceGroup.NamespacedName())
+	capacity := GetInstancesCapacity(scalingGroup.Instances)
+	if instanceGroup.GetEKSConfiguration().GetMixedInstancesPolicy().IsWeighted() {
+		// weighted instances may exceed the desired capacity by up to the weight of a single instance
+		if capacity < desiredCount {
+			return false
+		}
+	} else if capacity != desiredCount {
+		// if instances don't match desired, a scaling activity is in progress
+		return false
+	}
+
+	instanceIds := make([]string, 0)
+	for _, instance := range scalingGroup.Instances {
+		instanceIds = append(instanceIds, aws.StringValue(instance.InstanceId))
+	}
+
+	instances := strings.Join(instanceIds, ",")
+
+	// the capacity has been verified above, every instance must have a ready node
+	ok, err := kubeprovider.IsDesiredNodesReady(nodes, instanceIds, len(instanceIds))
+	if err != nil {
+		ctx.Log.Error(err, "could not update node conditions", "instancegroup", instanceGroup.NamespacedName())
+		return false
+	}
+	if ok {
+		ok, err = ctx.IsDaemonSetPodsReady(instanceIds)
+		if err != nil {
+			ctx.Log.Error(err, "could not update node conditions", "instancegroup", instanceGroup.NamespacedName())
+			return false
+		}
+	}
+	if ok {
+		ok = ctx.IsInstanceTypeQuorumReady(instanceIds)
+	}
+	if ok {
+		if !state.IsNodesReady() {
+			state.Publisher.Publish(kubeprovider.NodesReadyEvent, "instancegroup", instanceGroup.NamespacedName(), "instances", instances)
+		}
+		ctx.Log.Info("desired nodes are ready", "instancegroup", instanceGroup.NamespacedName(), "instances", instances)
+		state.SetNodesReady(true)
+		status.SetCondition(v1alpha1.NewInstanceGroupCondition(v1alpha1.NodesReady, corev1.ConditionTrue))
+		return true
+	}
+
+	if state.IsNodesReady() {
+		state.Publisher.Publish(kubeprovider.NodesNotReadyEvent, "instancegroup", instanceGroup.NamespacedName(), "instances", instances)
+	}
+	ctx.Log.Info("desired nodes are not ready", "instancegroup", instanceGroup.NamespacedName(), "instances", instances)
+	state.SetNodesReady(false)
+	status.SetCondition(v1alpha1.NewInstanceGroupCondition(v1alpha1.NodesReady, corev1.ConditionFalse))
+	return false
+}
+
+func (ctx *EksInstanceGroupContext) GetEnabledMetrics() ([]string, bool) {
+	var (
+		instanceGroup  = ctx.GetInstanceGroup()
+		configuration  = instanceGroup.GetEKSConfiguration()
+		metrics        = configuration.GetMetricsCollection()
+		granularity    = configuration.GetMetricsGranularity()
+		state          = ctx.GetDiscoveredState()
+		scalingGroup   = state.GetScalingGroup()
+		enableMetrics  = make([]string, 0)
+		enabledMetrics = make([]string, 0)
+		desiredMetrics []string
+	)
+
+	// handle 'all' metrics provided
+	if common.ContainsEqualFold(metrics, "all") {
+		desiredMetrics = awsprovider.DefaultAutoscalingMetrics
+	} else {
+		desiredMetrics = metrics
+	}
+
+	// get all already enabled metrics, metrics enabled at a different granularity are enabled again
+	for _, m := range scalingGroup.EnabledMetrics {
+		if !strings.EqualFold(aws.StringValue(m.Granularity), granularity) {
+			continue
+		}
+		enabledMetrics = append(enabledMetrics, aws.StringValue(m.Metric))
+	}
+
+	// add desired which are not enabled
+	for _, m := range desiredMetrics {
+		if !common.ContainsString(enabledMetrics, m) {
+			enableMetrics = append(enableMetrics, m)
+		}
+	}
+
+	if common.SliceEmpty(enableMetrics) {
+		return enableMetrics, false
+	}
+
+	return enableMetrics, true
+}
+
+func (ctx *EksInstanceGroupContext) GetDisabledMetrics() ([]string, bool) {
+	var (
+		instanceGroup   = ctx.GetInstanceGroup()
+		configuration   = instanceGroup.GetEKSConfiguration()
+		metrics         = configuration.GetMetricsCollection()
+		state           = ctx.GetDiscoveredState()
+		scalingGroup    = state.GetScalingGroup()
+		disabledMetrics = make([]string, 0)
+		desiredMetrics  []string
+	)
+
+	// handle 'all' metrics provided
+	if common.ContainsEqualFold(metrics, "all") {
+		desiredMetrics = awsprovider.DefaultAutoscalingMetrics
+	} else {
+		desiredMetrics = metrics
+	}
+
+	// find metrics that need to be disabled
+	for _, m := range scalingGroup.EnabledMetrics {
+		metricName := aws.StringValue(m.Metric)
+		if !common.ContainsString(desiredMetrics, metricName) {
+			disabledMetrics = append(disabledMetrics, metricName)
+		}
+	}
+
+	if common.SliceEmpty(disabledMetrics) {
+		return disabledMetrics, false
+	}
+
+	return disabledMetrics, true
+}
+
+func (ctx *EksInstanceGroupContext) UpdateMetricsCollection(asgName string) error {
+	var (
+		instanceGroup = ctx.GetInstanceGroup()
+		configuration = instanceGroup.GetEKSConfiguration()
+	)
+
+	if metrics, ok := ctx.GetDisabledMetrics(); ok {
+		if err := ctx.AwsWorker.DisableMetrics(asgName, metrics); err != nil {
+			return errors.Wrapf(err, "failed to disable metrics %v", metrics)
+		}
+		ctx.Log.Info("disabled metrics collection", "instancegroup", instanceGroup.NamespacedName(), "metrics", metrics)
+	}
+
+	if metrics, ok := ctx.GetEnabledMetrics(); ok {
+		if err := ctx.AwsWorker.EnableMetrics(asgName, metrics, configuration.GetMetricsGranularity()); err != nil {
+			return errors.Wrapf(err, "failed to enable metrics %v", metrics)
+		}
+		ctx.Log.Info("enabled metrics collection", "instancegroup", instanceGroup.NamespacedName(), "metrics", metrics)
+	}
+	return nil
+}
+
+// getExistingHooks returns the lifecycle hooks of the scaling group as hook specs
+func (ctx *EksInstanceGroupContext) getExistingHooks() []v1alpha1.LifecycleHookSpec {
+	var (
+		state         = ctx.GetDiscoveredState()
+		existingHooks = []v1alpha1.LifecycleHookSpec{}
+	)
+
+	for _, h := range state.LifecycleHooks {
+		hook := v1alpha1.LifecycleHookSpec{
+			Name:             aws.StringValue(h.LifecycleHookName),
+			Lifecycle:        aws.StringValue(h.LifecycleTransition),
+			DefaultResult:    aws.StringValue(h.DefaultResult),
+			HeartbeatTimeout: aws.Int64Value(h.HeartbeatTimeout),
+			NotificationArn:  aws.StringValue(h.NotificationTargetARN),
+			Metadata:         aws.StringValue(h.NotificationMetadata),
+			RoleArn:          aws.StringValue(h.RoleARN),
+		}
+		existingHooks = append(existingHooks, hook)
+	}
+	return existingHooks
+}
+
+func getHookNames(hooks []v1alpha1.LifecycleHookSpec) map[string]bool {
+	names := make(map[string]bool, len(hooks))
+	for _, h := range hooks {
+		names[h.Name] = true
+	}
+	return names
+}
+
+// GetRemovedHooks returns the names of scaling group hooks which are no longer desired
+func (ctx *EksInstanceGroupContext) GetRemovedHooks() ([]string, bool) {
+	var (
+		instanceGroup = ctx.GetInstanceGroup()
+		configuration = instanceGroup.GetEKSConfiguration()
+		desiredNames  = getHookNames(configuration.GetLifecycleHooks())
+	)
+
+	removeHooks := make([]string, 0)
+	for _, e := range ctx.getExistingHooks() {
+		if !desiredNames[e.Name] {
+			removeHooks = append(removeHooks, e.Name)
+		}
+	}
+
+	if len(removeHooks) == 0 {
+		return []string{}, false
+	}
+
+	return removeHooks, true
+}
+
+// GetAddedHooks returns the desired hooks which do not exist on the scaling group
+func (ctx *EksInstanceGroupContext) GetAddedHooks() ([]v1alpha1.LifecycleHookSpec, bool) {
+	var (
+		instanceGroup = ctx.GetInstanceGroup()
+		configuration = instanceGroup.GetEKSConfiguration()
+		desiredHooks  = configuration.GetLifecycleHooks()
+		existingNames = getHookNames(ctx.getExistingHooks())
+	)
+
+	addHooks := make([]v1alpha1.LifecycleHookSpec, 0)
+	for _, d := range desiredHooks {
+		if !existingNames[d.Name] {
+			addHooks = append(addHooks, d)
+		}
+	}
+
+	if len(addHooks) == 0 {
+		return addHooks, false
+	}
+
+	return addHooks, true
+}
+
+// GetUpdatedHooks returns the desired hooks which exist on the scaling group with different properties, these are
+// updated in place
+func (ctx *EksInstanceGroupContext) GetUpdatedHooks() ([]v1alpha1.LifecycleHookSpec, bool) {
+	var (
+		instanceGroup = ctx.GetInstanceGroup()
+		configuration = instanceGroup.GetEKSConfiguration()
+		desiredHooks  = configuration.GetLifecycleHooks()
+		existingHooks = ctx.getExistingHooks()
+		existingNames = getHookNames(existingHooks)
+	)
+
+	updateHooks := make([]v1alpha1.LifecycleHookSpec, 0)
+	for _, d := range desiredHooks {
+		if existingNames[d.Name] && !d.ExistInSlice(existingHooks) {
+			updateHooks = append(updateHooks, d)
+		}
+	}
+
+	if len(updateHooks) == 0 {
+		return updateHooks, false
+	}
+
+	return updateHooks, true
+}
+
+func (ctx *EksInstanceGroupContext) UpdateWarmPool(asgName string) error {
+	var (
+		instanceGroup  = ctx.GetInstanceGroup()
+		spec           = instanceGroup.GetEKSSpec()
+		state          = ctx.GetDiscoveredState()
+		scalingGroup   = state.GetScalingGroup()
+		warmPoolConfig = scalingGroup.WarmPoolConfiguration
+	)
+
+	var warmPoolConfigured bool
+
+	if warmPoolConfig != nil {
+		warmPoolConfigured = true
 	}
 
-	// set the recommendation given
-	status.SetUsingSpotRecommendation(true)
+	// spec has no warm pool
+	if !spec.HasWarmPool() {
+		// scaling group has warm pool
+		if warmPoolConfigured {
+			// it's already deleting
+			if aws.StringValue(warmPoolConfig.Status) == autoscaling.WarmPoolStatusPendingDelete {
+				return nil
+			}
+			// delete it
+			if err := ctx.AwsWorker.DeleteWarmPool(asgName); err != nil {
+				return errors.Wrapf(err, "failed to delete warm pool for scaling group %v", asgName)
+			}
+		}
+		return nil
+	} else { // warm pool exists in spec
+
+		// check if update/create is needed
+		var (
+			updateRequired bool
+			max            = spec.WarmPool.MaxSize
+			min            = spec.WarmPool.MinSize
+		)
+
+		if warmPoolConfigured {
+			if min != aws.Int64Value(warmPoolConfig.MinSize) {
+				updateRequired = true
+			}
+			if max != aws.Int64Value(warmPoolConfig.MaxGroupPreparedCapacity) {
+				updateRequired = true
+			}
+		}
 
-	if recommendation.UseSpot {
-		ctx.Log.Info("spot enabled with spot price recommendation", "instancegroup", instanceGroup.NamespacedName(), "spotPrice", recommendation.SpotPrice)
-		configuration.SetSpotPrice(recommendation.SpotPrice)
-	} else {
-		ctx.Log.Info("spot disabled due to recommendation", "instancegroup", instanceGroup.NamespacedName())
-		configuration.SetSpotPrice("")
+		// update or create warm pool
+		if updateRequired || !warmPoolConfigured {
+			if err := ctx.AwsWorker.UpdateWarmPool(asgName, min, max); err != nil {
+				return errors.Wrapf(err, "failed to delete warm pool for scaling group %v", asgName)
+			}
+		}
 	}
+
 	return nil
 }
 
-func (ctx *EksInstanceGroupContext) findOwnedScalingGroups(groups []*autoscaling.Group) []*autoscaling.Group {
+func (ctx *EksInstanceGroupContext) GetAddedTargetGroups() ([]string, bool) {
 	var (
-		filteredGroups = make([]*autoscaling.Group, 0)
-		instanceGroup  = ctx.GetInstanceGroup()
-		configuration  = instanceGroup.GetEKSConfiguration()
-		clusterName    = configuration.GetClusterName()
+		instanceGroup = ctx.GetInstanceGroup()
+		state         = ctx.GetDiscoveredState()
+		configuration = instanceGroup.GetEKSConfiguration()
+		scalingGroup  = state.GetScalingGroup()
+		existing      = make([]string, 0)
+		added         = make([]string, 0)
 	)
 
-	for _, group := range groups {
-		for _, tag := range group.Tags {
-			var (
-				key   = aws.StringValue(tag.Key)
-				value = aws.StringValue(tag.Value)
-			)
-			// if group has the same cluster tag it's owned by the controller
-			if key == provisioners.TagClusterName && strings.EqualFold(value, clusterName) {
-				filteredGroups = append(filteredGroups, group)
-			}
+	if scalingGroup != nil {
+		existing = aws.StringValueSlice(scalingGroup.TargetGroupARNs)
+	}
+
+	for _, tg := range configuration.GetTargetGroupARNs() {
+		if !common.ContainsString(existing, tg) {
+			added = append(added, tg)
 		}
 	}
-	return filteredGroups
+
+	return added, len(added) > 0
 }
 
-func (ctx *EksInstanceGroupContext) findTargetScalingGroup(groups []*autoscaling.Group) *autoscaling.Group {
+// GetRemovedTargetGroups returns the target groups attached by the controller which are no longer in the spec, target
+// groups attached outside of the controller are left in place
+func (ctx *EksInstanceGroupContext) GetRemovedTargetGroups() ([]string, bool) {
 	var (
-		instanceGroup  = ctx.GetInstanceGroup()
-		nameMatch      bool
-		namespaceMatch bool
+		instanceGroup = ctx.GetInstanceGroup()
+		state         = ctx.GetDiscoveredState()
+		status        = instanceGroup.GetStatus()
+		configuration = instanceGroup.GetEKSConfiguration()
+		scalingGroup  = state.GetScalingGroup()
+		removed       = make([]string, 0)
 	)
 
-	for _, group := range groups {
-		for _, tag := range group.Tags {
-			var (
-				key   = aws.StringValue(tag.Key)
-				value = aws.StringValue(tag.Value)
-			)
-			// must match both name and namespace tag
-			if key == provisioners.TagInstanceGroupName && value == instanceGroup.GetName() {
-				nameMatch = true
-			}
-			if key == provisioners.TagInstanceGroupNamespace && value == instanceGroup.GetNamespace() {
-				namespaceMatch = true
-			}
+	if scalingGroup == nil {
+		return removed, false
+	}
+
+	for _, tg := range aws.StringValueSlice(scalingGroup.TargetGroupARNs) {
+		if !common.ContainsString(status.GetAttachedTargetGroupARNs(), tg) {
+			continue
 		}
-		if nameMatch && namespaceMatch {
-			return group
+		if !common.ContainsString(configuration.GetTargetGroupARNs(), tg) {
+			removed = append(removed, tg)
 		}
 	}
 
-	return nil
+	return removed, len(removed) > 0
 }
 
-func (ctx *EksInstanceGroupContext) UpdateNodeReadyCondition() bool {
+func (ctx *EksInstanceGroupContext) GetAddedLoadBalancers() ([]string, bool) {
 	var (
+		instanceGroup = ctx.GetInstanceGroup()
 		state         = ctx.GetDiscoveredState()
+		configuration = instanceGroup.GetEKSConfiguration()
+		scalingGroup  = state.GetScalingGroup()
+		existing      = make([]string, 0)
+		added         = make([]string, 0)
+	)
+
+	if scalingGroup != nil {
+		existing = aws.StringValueSlice(scalingGroup.LoadBalancerNames)
+	}
+
+	for _, name := range configuration.GetLoadBalancerNames() {
+		if !common.ContainsString(existing, name) {
+			added = append(added, name)
+		}
+	}
+
+	return added, len(added) > 0
+}
+
+// GetRemovedLoadBalancers returns the load balancers attached by the controller which are no longer in the spec, load
+// balancers attached outside of the controller are left in place
+func (ctx *EksInstanceGroupContext) GetRemovedLoadBalancers() ([]string, bool) {
+	var (
 		instanceGroup = ctx.GetInstanceGroup()
+		state         = ctx.GetDiscoveredState()
 		status        = instanceGroup.GetStatus()
+		configuration = instanceGroup.GetEKSConfiguration()
 		scalingGroup  = state.GetScalingGroup()
-		desiredCount  = int(aws.Int64Value(scalingGroup.DesiredCapacity))
-		nodes         = state.GetClusterNodes()
+		removed       = make([]string, 0)
 	)
 
 	if scalingGroup == nil {
-		return false
+		return removed, false
 	}
 
-	ctx.Log.Info("waiting for node readiness conditions", "instancegroup", instanceGroup.NamespacedName())
-	if len(scalingGroup.Instances) != desiredCount {
-		// if instances don't match desired, a scaling activity is in progress
-		return false
+	for _, name := range aws.StringValueSlice(scalingGroup.LoadBalancerNames) {
+		if !common.ContainsString(status.GetAttachedLoadBalancerNames(), name) {
+			continue
+		}
+		if !common.ContainsString(configuration.GetLoadBalancerNames(), name) {
+			removed = append(removed, name)
+		}
+	}
+
+	return removed, len(removed) > 0
+}
+
+// UpdateSourceDestCheck applies the desired source/dest check attribute to the scaling group's instances, since the
+// attribute cannot be set through a launch configuration it is applied after instances are launched
+func (ctx *EksInstanceGroupContext) UpdateSourceDestCheck() error {
+	var (
+		instanceGroup   = ctx.GetInstanceGroup()
+		configuration   = instanceGroup.GetEKSConfiguration()
+		sourceDestCheck = configuration.GetSourceDestCheck()
+		state           = ctx.GetDiscoveredState()
+		scalingGroup    = state.GetScalingGroup()
+		instanceIds     = make([]string, 0)
+	)
+
+	if sourceDestCheck == nil {
+		return nil
 	}
 
-	instanceIds := make([]string, 0)
 	for _, instance := range scalingGroup.Instances {
 		instanceIds = append(instanceIds, aws.StringValue(instance.InstanceId))
 	}
+	if len(instanceIds) == 0 {
+		return nil
+	}
 
-	instances := strings.Join(instanceIds, ",")
-
-	var conditions []v1alpha1.InstanceGroupCondition
-	ok, err := kubeprovider.IsDesiredNodesReady(nodes, instanceIds, desiredCount)
+	instances, err := ctx.AwsWorker.DescribeInstancesById(instanceIds)
 	if err != nil {
-		ctx.Log.Error(err, "could not update node conditions", "instancegroup", instanceGroup.NamespacedName())
-		return false
+		return errors.Wrap(err, "failed to describe instances")
 	}
-	if ok {
-		if !state.IsNodesReady() {
-			state.Publisher.Publish(kubeprovider.NodesReadyEvent, "instancegroup", instanceGroup.NamespacedName(), "instances", instances)
+
+	desired := aws.BoolValue(sourceDestCheck)
+	for _, instance := range instances {
+		if aws.BoolValue(instance.SourceDestCheck) == desired {
+			continue
 		}
-		ctx.Log.Info("desired nodes are ready", "instancegroup", instanceGroup.NamespacedName(), "instances", instances)
-		state.SetNodesReady(true)
-		conditions = append(conditions, v1alpha1.NewInstanceGroupCondition(v1alpha1.NodesReady, corev1.ConditionTrue))
-		status.SetConditions(conditions)
-		return true
+		id := aws.StringValue(instance.InstanceId)
+		if err := ctx.AwsWorker.SetSourceDestCheck(id, desired); err != nil {
+			return errors.Wrapf(err, "failed to modify source/dest check of instance %v", id)
+		}
+		ctx.Log.Info("modified source/dest check", "instancegroup", instanceGroup.NamespacedName(), "instance", id, "sourcedestcheck", desired)
 	}
+	return nil
+}
 
-	if state.IsNodesReady() {
-		state.Publisher.Publish(kubeprovider.NodesNotReadyEvent, "instancegroup", instanceGroup.NamespacedName(), "instances", instances)
+// SeedAttachedLoadBalancers records the spec target groups and load balancers which are already attached to the
+// scaling group when nothing has been recorded yet, e.g. on the first reconcile of an existing scaling group
+func (ctx *EksInstanceGroupContext) SeedAttachedLoadBalancers() {
+	var (
+		instanceGroup = ctx.GetInstanceGroup()
+		state         = ctx.GetDiscoveredState()
+		status        = instanceGroup.GetStatus()
+		configuration = instanceGroup.GetEKSConfiguration()
+		scalingGroup  = state.GetScalingGroup()
+	)
+
+	if len(status.GetAttachedTargetGroupARNs()) == 0 {
+		attached := make([]string, 0)
+		for _, tg := range configuration.GetTargetGroupARNs() {
+			if common.ContainsString(aws.StringValueSlice(scalingGroup.TargetGroupARNs), tg) {
+				attached = append(attached, tg)
+			}
+		}
+		status.SetAttachedTargetGroupARNs(attached)
+	}
+
+	if len(status.GetAttachedLoadBalancerNames()) == 0 {
+		attached := make([]string, 0)
+		for _, name := range configuration.GetLoadBalancerNames() {
+			if common.ContainsString(aws.StringValueSlice(scalingGroup.LoadBalancerNames), name) {
+				attached = append(attached, name)
+			}
+		}
+		status.SetAttachedLoadBalancerNames(attached)
 	}
-	ctx.Log.Info("desired nodes are not ready", "instancegroup", instanceGroup.NamespacedName(), "instances", instances)
-	state.SetNodesReady(false)
-	conditions = append(conditions, v1alpha1.NewInstanceGroupCondition(v1alpha1.NodesReady, corev1.ConditionFalse))
-	status.SetConditions(conditions)
-	return false
 }
 
-func (ctx *EksInstanceGroupContext) GetEnabledMetrics() ([]string, bool) {
+func (ctx *EksInstanceGroupContext) UpdateLoadBalancers(asgName string) error {
 	var (
-		instanceGroup  = ctx.GetInstanceGroup()
-		configuration  = instanceGroup.GetEKSConfiguration()
-		metrics        = configuration.GetMetricsCollection()
-		state          = ctx.GetDiscoveredState()
-		scalingGroup   = state.GetScalingGroup()
-		enableMetrics  = make([]string, 0)
-		enabledMetrics = make([]string, 0)
-		desiredMetrics []string
+		instanceGroup = ctx.GetInstanceGroup()
+		status        = instanceGroup.GetStatus()
+		configuration = instanceGroup.GetEKSConfiguration()
 	)
 
-	// handle 'all' metrics provided
-	if common.ContainsEqualFold(metrics, "all") {
-		desiredMetrics = awsprovider.DefaultAutoscalingMetrics
-	} else {
-		desiredMetrics = metrics
+	ctx.SeedAttachedLoadBalancers()
+
+	if arns, ok := ctx.GetRemovedTargetGroups(); ok {
+		if err := ctx.AwsWorker.DetachLoadBalancerTargetGroups(asgName, arns); err != nil {
+			return errors.Wrapf(err, "failed to detach target groups %v", arns)
+		}
+		ctx.Log.Info("detached target groups", "instancegroup", instanceGroup.NamespacedName(), "targetgroups", arns)
 	}
 
-	// get all already enabled metrics
-	for _, m := range scalingGroup.EnabledMetrics {
-		enabledMetrics = append(enabledMetrics, aws.StringValue(m.Metric))
+	if arns, ok := ctx.GetAddedTargetGroups(); ok {
+		if err := ctx.AwsWorker.AttachLoadBalancerTargetGroups(asgName, arns); err != nil {
+			return errors.Wrapf(err, "failed to attach target groups %v", arns)
+		}
+		ctx.Log.Info("attached target groups", "instancegroup", instanceGroup.NamespacedName(), "targetgroups", arns)
+	}
 
+	if names, ok := ctx.GetRemovedLoadBalancers(); ok {
+		if err := ctx.AwsWorker.DetachLoadBalancers(asgName, names); err != nil {
+			return errors.Wrapf(err, "failed to detach load balancers %v", names)
+		}
+		ctx.Log.Info("detached load balancers", "instancegroup", instanceGroup.NamespacedName(), "loadbalancers", names)
 	}
 
-	// add desired which are not enabled
-	for _, m := range desiredMetrics {
-		if !common.ContainsString(enabledMetrics, m) {
-			enableMetrics = append(enableMetrics, m)
+	if names, ok := ctx.GetAddedLoadBalancers(); ok {
+		if err := ctx.AwsWorker.AttachLoadBalancers(asgName, names); err != nil {
+			return errors.Wrapf(err, "failed to attach load balancers %v", names)
 		}
+		ctx.Log.Info("attached load balancers", "instancegroup", instanceGroup.NamespacedName(), "loadbalancers", names)
 	}
 
-	if common.SliceEmpty(enableMetrics) {
-		return enableMetrics, false
+	status.SetAttachedTargetGroupARNs(configuration.GetTargetGroupARNs())
+	status.SetAttachedLoadBalancerNames(configuration.GetLoadBalancerNames())
+	return nil
+}
+
+func getLifecycleHookInput(asgName string, hook v1alpha1.LifecycleHookSpec) *autoscaling.PutLifecycleHookInput {
+	input := &autoscaling.PutLifecycleHookInput{
+		AutoScalingGroupName: aws.String(asgName),
+		LifecycleHookName:    aws.String(hook.Name),
+		DefaultResult:        aws.String(hook.DefaultResult),
+		HeartbeatTimeout:     aws.Int64(hook.HeartbeatTimeout),
+		LifecycleTransition:  aws.String(hook.Lifecycle),
 	}
 
-	return enableMetrics, true
+	if !common.StringEmpty(hook.Metadata) {
+		input.NotificationMetadata = aws.String(hook.Metadata)
+	}
+
+	if !common.StringEmpty(hook.RoleArn) {
+		input.RoleARN = aws.String(hook.RoleArn)
+	}
+
+	if !common.StringEmpty(hook.NotificationArn) {
+		input.NotificationTargetARN = aws.String(hook.NotificationArn)
+	}
+	return input
 }
 
-func (ctx *EksInstanceGroupContext) GetDisabledMetrics() ([]string, bool) {
+func (ctx *EksInstanceGroupContext) UpdateLifecycleHooks(asgName string) error {
 	var (
-		instanceGroup   = ctx.GetInstanceGroup()
-		configuration   = instanceGroup.GetEKSConfiguration()
-		metrics         = configuration.GetMetricsCollection()
-		state           = ctx.GetDiscoveredState()
-		scalingGroup    = state.GetScalingGroup()
-		disabledMetrics = make([]string, 0)
-		desiredMetrics  []string
+		instanceGroup = ctx.GetInstanceGroup()
 	)
 
-	// handle 'all' metrics provided
-	if common.ContainsEqualFold(metrics, "all") {
-		desiredMetrics = awsprovider.DefaultAutoscalingMetrics
-	} else {
-		desiredMetrics = metrics
+	if hooks, ok := ctx.GetRemovedHooks(); ok {
+		for _, hook := range hooks {
+			if err := ctx.AwsWorker.DeleteLifecycleHook(asgName, hook); err != nil {
+				return errors.Wrapf(err, "failed to remove lifecycle hook %v", hook)
+			}
+			ctx.Log.Info("deleting lifecycle hook", "instancegroup", instanceGroup.NamespacedName(), "hook", hook)
+		}
+	}
+
+	if hooks, ok := ctx.GetAddedHooks(); ok {
+		for _, hook := range hooks {
+			if err := ctx.AwsWorker.CreateLifecycleHook(getLifecycleHookInput(asgName, hook)); err != nil {
+				return errors.Wrapf(err, "failed to add lifecycle hook %v", hook)
+			}
+			ctx.Log.Info("creating lifecycle hook", "instancegroup", instanceGroup.NamespacedName(), "hook", hook)
+		}
+	}
+
+	// PutLifecycleHook updates existing hooks in place
+	if hooks, ok := ctx.GetUpdatedHooks(); ok {
+		for _, hook := range hooks {
+			if err := ctx.AwsWorker.CreateLifecycleHook(getLifecycleHookInput(asgName, hook)); err != nil {
+				return errors.Wrapf(err, "failed to update lifecycle hook %v", hook)
+			}
+			ctx.Log.Info("updating lifecycle hook", "instancegroup", instanceGroup.NamespacedName(), "hook", hook)
+		}
 	}
+	return nil
+}
+
+// UpdateLifecycleHookDocuments runs the SSM documents of lifecycle hooks for the instances waiting on them, and completes
+// the lifecycle actions of instances whose executions have ended
+func (ctx *EksInstanceGroupContext) UpdateLifecycleHookDocuments() error {
+	var (
+		instanceGroup = ctx.GetInstanceGroup()
+		configuration = instanceGroup.GetEKSConfiguration()
+		state         = ctx.GetDiscoveredState()
+		scalingGroup  = state.GetScalingGroup()
+	)
+
+	state.PendingLifecycleActions = 0
+	if scalingGroup == nil {
+		return nil
+	}
+	asgName := aws.StringValue(scalingGroup.AutoScalingGroupName)
+
+	for _, hook := range configuration.GetLifecycleHooks() {
+		if !hook.HasSSMDocument() {
+			continue
+		}
+
+		var (
+			document       = hook.SSMDocument
+			instanceIdName = document.InstanceIdParameter
+			waitState      = autoscaling.LifecycleStatePendingWait
+		)
+		if common.StringEmpty(instanceIdName) {
+			instanceIdName = v1alpha1.LifecycleHookDefaultInstanceIdParameter
+		}
+		if strings.EqualFold(hook.Lifecycle, awsprovider.LifecycleHookTransitionTerminate) {
+			waitState = autoscaling.LifecycleStateTerminatingWait
+		}
+
+		for _, instance := range scalingGroup.Instances {
+			if aws.StringValue(instance.LifecycleState) != waitState {
+				continue
+			}
+			instanceId := aws.StringValue(instance.InstanceId)
+
+			parameters := make(map[string][]string)
+			for k, v := range document.Parameters {
+				parameters[k] = v
+			}
+			parameters[instanceIdName] = []string{instanceId}
+
+			// the token is unique per instance and hook so that every reconcile refers to the same execution
+			token := common.StringMD5(fmt.Sprintf("%v/%v/%v", asgName, hook.Name, instanceId))
+			token = fmt.Sprintf("%v-%v-%v-%v-%v", token[0:8], token[8:12], token[12:16], token[16:20], token[20:32])
+
+			executionId, err := ctx.AwsWorker.StartAutomationExecution(document.Name, document.Version, parameters, token)
+			if err != nil {
+				return errors.Wrapf(err, "failed to start automation execution of document %v for instance %v", document.Name, instanceId)
+			}
+
+			status, err := ctx.AwsWorker.GetAutomationExecutionStatus(executionId)
+			if err != nil {
+				return errors.Wrapf(err, "failed to get automation execution %v", executionId)
+			}
 
-	// find metrics that need to be disabled
-	for _, m := range scalingGroup.EnabledMetrics {
-		metricName := aws.StringValue(m.Metric)
-		if !common.ContainsString(desiredMetrics, metricName) {
-			disabledMetrics = append(disabledMetrics, metricName)
+			var result string
+			switch status {
+			case ssm.AutomationExecutionStatusSuccess:
+				result = v1alpha1.LifecycleHookResultContinue
+			case ssm.AutomationExecutionStatusFailed, ssm.AutomationExecutionStatusTimedOut, ssm.AutomationExecutionStatusCancelled:
+				result = hook.DefaultResult
+			default:
+				ctx.Log.Info("waiting for lifecycle hook automation execution", "instancegroup", instanceGroup.NamespacedName(), "hook", hook.Name, "instance", instanceId, "execution", executionId, "status", status)
+				state.PendingLifecycleActions++
+				continue
+			}
+
+			if err := ctx.AwsWorker.CompleteLifecycleAction(asgName, hook.Name, instanceId, result); err != nil {
+				return errors.Wrapf(err, "failed to complete lifecycle action of instance %v", instanceId)
+			}
+			ctx.Log.Info("completed lifecycle action", "instancegroup", instanceGroup.NamespacedName(), "hook", hook.Name, "instance", instanceId, "execution", executionId, "status", status, "result", result)
 		}
 	}
+	return nil
+}
 
-	if common.SliceEmpty(disabledMetrics) {
-		return disabledMetrics, false
+// GetManagedRoleName returns the name of the controller-created IAM role
+func (ctx *EksInstanceGroupContext) GetManagedRoleName() string {
+	roleName := ctx.ResourcePrefix
+	if len(roleName) >= awsprovider.MaxRoleNameLength {
+		// use a hash of the actual name in case we exceed the max length
+		roleName = common.StringMD5(roleName)
 	}
-
-	return disabledMetrics, true
+	return roleName
 }
 
-func (ctx *EksInstanceGroupContext) UpdateMetricsCollection(asgName string) error {
+// GetManagedInstanceProfileName returns the name of the controller-created instance-profile, prefixed by instanceProfileNamePrefix
+func (ctx *EksInstanceGroupContext) GetManagedInstanceProfileName() string {
 	var (
 		instanceGroup = ctx.GetInstanceGroup()
+		configuration = instanceGroup.GetEKSConfiguration()
+		prefix        = configuration.GetInstanceProfileNamePrefix()
+		profileName   = fmt.Sprintf("%v%v", prefix, ctx.GetManagedRoleName())
 	)
 
-	if metrics, ok := ctx.GetDisabledMetrics(); ok {
-		if err := ctx.AwsWorker.DisableMetrics(asgName, metrics); err != nil {
-			return errors.Wrapf(err, "failed to disable metrics %v", metrics)
-		}
-		ctx.Log.Info("disabled metrics collection", "instancegroup", instanceGroup.NamespacedName(), "metrics", metrics)
-	}
-
-	if metrics, ok := ctx.GetEnabledMetrics(); ok {
-		if err := ctx.AwsWorker.EnableMetrics(asgName, metrics); err != nil {
-			return errors.Wrapf(err, "failed to enable metrics %v", metrics)
-		}
-		ctx.Log.Info("enabled metrics collection", "instancegroup", instanceGroup.NamespacedName(), "metrics", metrics)
+	if len(profileName) > awsprovider.MaxInstanceProfileNameLength {
+		// keep the name unique with a hash of the actual name in case we exceed the max length
+		hash := common.StringMD5(profileName)
+		profileName = fmt.Sprintf("%v-%v", profileName[:awsprovider.MaxInstanceProfileNameLength-len(hash)-1], hash)
 	}
-	return nil
+	return profileName
 }
 
-func (ctx *EksInstanceGroupContext) GetRemovedHooks() ([]string, bool) {
+// GetSharedInstanceProfileName returns the name of an existing instance-profile shared across instance groups
+func (ctx *EksInstanceGroupContext) GetSharedInstanceProfileName() string {
 	var (
 		instanceGroup = ctx.GetInstanceGroup()
-		state         = ctx.GetDiscoveredState()
-		configuration = instanceGroup.GetEKSConfiguration()
-		desiredHooks  = configuration.GetLifecycleHooks()
+		annotations   = instanceGroup.GetAnnotations()
 	)
+	return annotations[SharedInstanceProfileAnnotation]
+}
 
-	existingHooks := []v1alpha1.LifecycleHookSpec{}
-	for _, h := range state.LifecycleHooks {
-		hook := v1alpha1.LifecycleHookSpec{
-			Name:             aws.StringValue(h.LifecycleHookName),
-			Lifecycle:        aws.StringValue(h.LifecycleTransition),
-			DefaultResult:    aws.StringValue(h.DefaultResult),
-			HeartbeatTimeout: aws.Int64Value(h.HeartbeatTimeout),
-			NotificationArn:  aws.StringValue(h.NotificationTargetARN),
-			Metadata:         aws.StringValue(h.NotificationMetadata),
-			RoleArn:          aws.StringValue(h.RoleARN),
-		}
-		existingHooks = append(existingHooks, hook)
-	}
+// HasSharedInstanceProfile returns true if the instance group reuses an existing instance-profile
+func (ctx *EksInstanceGroupContext) HasSharedInstanceProfile() bool {
+	return !common.StringEmpty(ctx.GetSharedInstanceProfileName())
+}
 
-	removeHooks := make([]string, 0)
-	for _, e := range existingHooks {
-		if !e.ExistInSlice(desiredHooks) {
-			removeHooks = append(removeHooks, e.Name)
-		}
-	}
+// HasExistingLaunchTemplate returns true if the instance group extends a pre-created launch template
+func (ctx *EksInstanceGroupContext) HasExistingLaunchTemplate() bool {
+	var (
+		instanceGroup = ctx.GetInstanceGroup()
+		spec          = instanceGroup.GetEKSSpec()
+		configuration = instanceGroup.GetEKSConfiguration()
+	)
+	return spec.IsLaunchTemplate() && !common.StringEmpty(configuration.GetExistingLaunchTemplateName())
+}
 
-	if len(removeHooks) == 0 {
-		return []string{}, false
+// GetLaunchTemplateTags returns the tags which mark a launch template as created by the controller for the instance group
+func (ctx *EksInstanceGroupContext) GetLaunchTemplateTags() map[string]string {
+	var (
+		instanceGroup = ctx.GetInstanceGroup()
+		configuration = instanceGroup.GetEKSConfiguration()
+	)
+	return map[string]string{
+		provisioners.TagClusterName:            configuration.GetClusterName(),
+		provisioners.TagInstanceGroupNamespace: instanceGroup.GetNamespace(),
+		provisioners.TagInstanceGroupName:      instanceGroup.GetName(),
 	}
-
-	return removeHooks, true
 }
 
-func (ctx *EksInstanceGroupContext) GetAddedHooks() ([]v1alpha1.LifecycleHookSpec, bool) {
+// IsOwnedLaunchTemplate returns true if the launch template was created by the controller for the instance group
+func (ctx *EksInstanceGroupContext) IsOwnedLaunchTemplate(template *ec2.LaunchTemplate) bool {
 	var (
 		instanceGroup = ctx.GetInstanceGroup()
-		state         = ctx.GetDiscoveredState()
-		configuration = instanceGroup.GetEKSConfiguration()
-		desiredHooks  = configuration.GetLifecycleHooks()
+		namespace     string
+		name          string
 	)
 
-	existingHooks := []v1alpha1.LifecycleHookSpec{}
-	for _, h := range state.LifecycleHooks {
-		hook := v1alpha1.LifecycleHookSpec{
-			Name:             aws.StringValue(h.LifecycleHookName),
-			Lifecycle:        aws.StringValue(h.LifecycleTransition),
-			DefaultResult:    aws.StringValue(h.DefaultResult),
-			HeartbeatTimeout: aws.Int64Value(h.HeartbeatTimeout),
-			NotificationArn:  aws.StringValue(h.NotificationTargetARN),
-			Metadata:         aws.StringValue(h.NotificationMetadata),
-			RoleArn:          aws.StringValue(h.RoleARN),
-		}
-		existingHooks = append(existingHooks, hook)
+	if template == nil {
+		return false
 	}
 
-	addHooks := make([]v1alpha1.LifecycleHookSpec, 0)
-	for _, d := range desiredHooks {
-		if !d.ExistInSlice(existingHooks) {
-			addHooks = append(addHooks, d)
+	for _, tag := range template.Tags {
+		switch aws.StringValue(tag.Key) {
+		case provisioners.TagInstanceGroupNamespace:
+			namespace = aws.StringValue(tag.Value)
+		case provisioners.TagInstanceGroupName:
+			name = aws.StringValue(tag.Value)
 		}
 	}
-
-	if len(addHooks) == 0 {
-		return addHooks, false
-	}
-
-	return addHooks, true
+	return namespace == instanceGroup.GetNamespace() && name == instanceGroup.GetName()
 }
 
-func (ctx *EksInstanceGroupContext) UpdateWarmPool(asgName string) error {
+// SetLaunchTemplateOwnership marks launch templates created from the configuration as owned, new versions of an existing
+// launch template are based on its default version, which is left unchanged
+func (ctx *EksInstanceGroupContext) SetLaunchTemplateOwnership(config *scaling.CreateConfigurationInput) {
 	var (
-		instanceGroup  = ctx.GetInstanceGroup()
-		spec           = instanceGroup.GetEKSSpec()
-		state          = ctx.GetDiscoveredState()
-		scalingGroup   = state.GetScalingGroup()
-		warmPoolConfig = scalingGroup.WarmPoolConfiguration
+		state         = ctx.GetDiscoveredState()
+		scalingConfig = state.GetScalingConfiguration()
 	)
 
-	var warmPoolConfigured bool
-
-	if warmPoolConfig != nil {
-		warmPoolConfigured = true
+	config.TemplateTags = ctx.GetLaunchTemplateTags()
+	if !ctx.HasExistingLaunchTemplate() {
+		return
 	}
 
-	// spec has no warm pool
-	if !spec.HasWarmPool() {
-		// scaling group has warm pool
-		if warmPoolConfigured {
-			// it's already deleting
-			if aws.StringValue(warmPoolConfig.Status) == autoscaling.WarmPoolStatusPendingDelete {
-				return nil
-			}
-			// delete it
-			if err := ctx.AwsWorker.DeleteWarmPool(asgName); err != nil {
-				return errors.Wrapf(err, "failed to delete warm pool for scaling group %v", asgName)
-			}
-		}
-		return nil
-	} else { // warm pool exists in spec
-
-		// check if update/create is needed
-		var (
-			updateRequired bool
-			max            = spec.WarmPool.MaxSize
-			min            = spec.WarmPool.MinSize
-		)
-
-		if warmPoolConfigured {
-			if min != aws.Int64Value(warmPoolConfig.MinSize) {
-				updateRequired = true
-			}
-			if max != aws.Int64Value(warmPoolConfig.MaxGroupPreparedCapacity) {
-				updateRequired = true
-			}
-		}
-
-		// update or create warm pool
-		if updateRequired || !warmPoolConfigured {
-			if err := ctx.AwsWorker.UpdateWarmPool(asgName, min, max); err != nil {
-				return errors.Wrapf(err, "failed to delete warm pool for scaling group %v", asgName)
-			}
-		}
+	config.Unowned = true
+	config.VersionDescription = ctx.GetLaunchTemplateVersionDescription()
+	if lt, ok := scalingConfig.(*scaling.LaunchTemplate); ok && lt.TargetResource != nil && lt.TargetResource.DefaultVersionNumber != nil {
+		config.SourceVersion = common.Int64ToStr(aws.Int64Value(lt.TargetResource.DefaultVersionNumber))
 	}
+}
 
-	return nil
+// GetLaunchTemplateVersionDescription returns the description of the versions created by the controller on an existing
+// launch template, only these versions are pruned
+func (ctx *EksInstanceGroupContext) GetLaunchTemplateVersionDescription() string {
+	return fmt.Sprintf("created by instance-manager for %v", ctx.GetInstanceGroup().NamespacedName())
 }
 
-func (ctx *EksInstanceGroupContext) UpdateLifecycleHooks(asgName string) error {
+// RetireLaunchTemplate records a launch template created by the controller which is replaced by an existing launch
+// template, it is deleted once the scaling group no longer references it
+func (ctx *EksInstanceGroupContext) RetireLaunchTemplate(name string) {
 	var (
 		instanceGroup = ctx.GetInstanceGroup()
+		status        = instanceGroup.GetStatus()
+		configuration = instanceGroup.GetEKSConfiguration()
+		retired       = status.GetRetiredLaunchTemplateNames()
 	)
 
-	if hooks, ok := ctx.GetRemovedHooks(); ok {
-		for _, hook := range hooks {
-			if err := ctx.AwsWorker.DeleteLifecycleHook(asgName, hook); err != nil {
-				return errors.Wrapf(err, "failed to remove lifecycle hook %v", hook)
-			}
-			ctx.Log.Info("deleting lifecycle hook", "instancegroup", instanceGroup.NamespacedName(), "hook", hook)
-		}
+	if common.StringEmpty(name) || strings.EqualFold(name, configuration.GetExistingLaunchTemplateName()) {
+		return
+	}
+	// only launch templates named by the controller are owned by it
+	if !strings.HasPrefix(name, ctx.ResourcePrefix+"-") || common.ContainsString(retired, name) {
+		return
 	}
 
-	if hooks, ok := ctx.GetAddedHooks(); ok {
-		for _, hook := range hooks {
-			input := &autoscaling.PutLifecycleHookInput{
-				AutoScalingGroupName: aws.String(asgName),
-				LifecycleHookName:    aws.String(hook.Name),
-				DefaultResult:        aws.String(hook.DefaultResult),
-				HeartbeatTimeout:     aws.Int64(hook.HeartbeatTimeout),
-				LifecycleTransition:  aws.String(hook.Lifecycle),
-			}
-
-			if !common.StringEmpty(hook.Metadata) {
-				input.NotificationMetadata = aws.String(hook.Metadata)
-			}
-
-			if !common.StringEmpty(hook.RoleArn) {
-				input.RoleARN = aws.String(hook.RoleArn)
-			}
-
-			if !common.StringEmpty(hook.NotificationArn) {
-				input.NotificationTargetARN = aws.String(hook.NotificationArn)
-			}
+	status.SetRetiredLaunchTemplateNames(append(retired, name))
+	ctx.Log.Info("retired launch template", "instancegroup", instanceGroup.NamespacedName(), "launchtemplate", name)
+}
 
-			if err := ctx.AwsWorker.CreateLifecycleHook(input); err != nil {
-				return errors.Wrapf(err, "failed to add lifecycle hook %v", hook)
-			}
-			ctx.Log.Info("creating lifecycle hook", "instancegroup", instanceGroup.NamespacedName(), "hook", hook)
+// IsLaunchTemplateReferenced returns true if the scaling group references the launch template or its override templates
+func IsLaunchTemplateReferenced(group *autoscaling.Group, name string) bool {
+	if group == nil {
+		return false
+	}
+	if strings.EqualFold(awsprovider.GetScalingConfigName(group), name) {
+		return true
+	}
+	if group.MixedInstancesPolicy == nil || group.MixedInstancesPolicy.LaunchTemplate == nil {
+		return false
+	}
+	for _, override := range group.MixedInstancesPolicy.LaunchTemplate.Overrides {
+		if override.LaunchTemplateSpecification == nil {
+			continue
+		}
+		if strings.HasPrefix(aws.StringValue(override.LaunchTemplateSpecification.LaunchTemplateName), name+"-") {
+			return true
 		}
 	}
-	return nil
+	return false
 }
 
 func (ctx *EksInstanceGroupContext) GetManagedPoliciesList(additionalPolicies []string) []string {
@@ -1042,14 +2954,31 @@ func (ctx *EksInstanceGroupContext) GetManagedPoliciesList(additionalPolicies []
 		}
 	}
 
+	requiredPolicies := make([]string, 0)
 	for _, name := range DefaultManagedPolicies {
-		managedPolicies = append(managedPolicies, fmt.Sprintf("%s/%s", awsprovider.IAMPolicyPrefix, name))
+		requiredPolicies = append(requiredPolicies, fmt.Sprintf("%s/%s", awsprovider.IAMPolicyPrefix, name))
 	}
 
 	if !irsaEnabled {
-		managedPolicies = append(managedPolicies, fmt.Sprintf("%s/%s", awsprovider.IAMPolicyPrefix, CNIManagedPolicy))
+		requiredPolicies = append(requiredPolicies, fmt.Sprintf("%s/%s", awsprovider.IAMPolicyPrefix, CNIManagedPolicy))
+	}
+
+	// in replace mode the user is expected to provide the policies required by EKS nodes
+	if ctx.IsManagedPoliciesReplaced() {
+		missing := make([]string, 0)
+		for _, policy := range requiredPolicies {
+			if !common.ContainsString(managedPolicies, policy) {
+				missing = append(missing, policy)
+			}
+		}
+		if len(missing) > 0 {
+			ctx.Log.Info("managed policies are replaced without policies required by EKS nodes, nodes may fail to join the cluster", "instancegroup", instanceGroup.NamespacedName(), "missing", missing)
+		}
+		return managedPolicies
 	}
 
+	managedPolicies = append(managedPolicies, requiredPolicies...)
+
 	return managedPolicies
 }
 
@@ -1090,7 +3019,7 @@ func (ctx *EksInstanceGroupContext) RemoveAuthRole(arn string) error {
 	return common.RemoveAuthConfigMap(ctx.KubernetesClient.Kubernetes, []string{arn}, []string{osFamily})
 }
 
-func (ctx *EksInstanceGroupContext) GetOverrides() []*autoscaling.LaunchTemplateOverrides {
+func (ctx *EksInstanceGroupContext) GetOverrides(name string) ([]*autoscaling.LaunchTemplateOverrides, error) {
 	var (
 		instanceGroup = ctx.GetInstanceGroup()
 		configuration = instanceGroup.GetEKSConfiguration()
@@ -1101,7 +3030,7 @@ func (ctx *EksInstanceGroupContext) GetOverrides() []*autoscaling.LaunchTemplate
 	)
 	overrides := []*autoscaling.LaunchTemplateOverrides{}
 	if mixedPolicy == nil {
-		return overrides
+		return overrides, nil
 	}
 
 	// Create overrides from specific instanceTypes or derive from instancePool
@@ -1112,10 +3041,18 @@ func (ctx *EksInstanceGroupContext) GetOverrides() []*autoscaling.LaunchTemplate
 		})
 		for _, instance := range mixedPolicy.InstanceTypes {
 			weightStr := strconv.FormatInt(instance.Weight, 10)
-			overrides = append(overrides, &autoscaling.LaunchTemplateOverrides{
+			override := &autoscaling.LaunchTemplateOverrides{
 				InstanceType:     aws.String(instance.Type),
 				WeightedCapacity: aws.String(weightStr),
-			})
+			}
+			// types with their own image are launched from a dedicated override template
+			if !common.StringEmpty(instance.Image) {
+				override.LaunchTemplateSpecification = &autoscaling.LaunchTemplateSpecification{
+					LaunchTemplateName: aws.String(GetOverrideTemplateName(name, instance.Type)),
+					Version:            aws.String(awsprovider.LaunchTemplateLatestVersionKey),
+				}
+			}
+			overrides = append(overrides, override)
 		}
 	} else if mixedPolicy.InstancePool != nil {
 		if strings.EqualFold(*mixedPolicy.InstancePool, string(SubFamilyFlexible)) {
@@ -1147,21 +3084,155 @@ func (ctx *EksInstanceGroupContext) GetOverrides() []*autoscaling.LaunchTemplate
 		}
 	}
 
-	return overrides
+	// when capacity is expressed in vCPUs every override weighs as many units as it has vCPUs, so that min/max and the
+	// desired capacity of the scaling group are interpreted consistently regardless of the types which are launched
+	if mixedPolicy.IsVCPUWeighted() {
+		for _, o := range overrides {
+			instanceType := aws.StringValue(o.InstanceType)
+			vcpu := awsprovider.GetOfferingVCPU(state.GetInstanceTypeInfo(), instanceType)
+			if vcpu <= 0 {
+				return nil, errors.Errorf("failed to weigh instance type %v by vCPU, instance type info not found", instanceType)
+			}
+			o.WeightedCapacity = aws.String(strconv.FormatInt(vcpu, 10))
+		}
+	}
+
+	return overrides, nil
+}
+
+// GetInstancesCapacity returns the capacity of the scaling group's instances in the unit of the scaling group's
+// desired capacity, instances without a weighted capacity count as a single unit
+func GetInstancesCapacity(instances []*autoscaling.Instance) int {
+	var capacity int
+	for _, instance := range instances {
+		weight, err := strconv.Atoi(aws.StringValue(instance.WeightedCapacity))
+		if err != nil || weight < 1 {
+			weight = 1
+		}
+		capacity += weight
+	}
+	return capacity
+}
+
+// GetOverrideTemplateName returns the name of the launch template used by an instance type override with a custom image
+func GetOverrideTemplateName(name, instanceType string) string {
+	return fmt.Sprintf("%v-%v", name, instanceType)
 }
 
-func (ctx *EksInstanceGroupContext) GetDesiredMixedInstancesPolicy(name string) *autoscaling.MixedInstancesPolicy {
+// GetOverrideImages returns a map of instance type to image for instance types which override the group's image
+func (ctx *EksInstanceGroupContext) GetOverrideImages() map[string]string {
 	var (
 		instanceGroup = ctx.GetInstanceGroup()
 		configuration = instanceGroup.GetEKSConfiguration()
 		mixedPolicy   = configuration.GetMixedInstancesPolicy()
+		images        = make(map[string]string)
 	)
 
 	if mixedPolicy == nil {
-		return nil
+		return images
+	}
+
+	for _, t := range mixedPolicy.InstanceTypes {
+		if !common.StringEmpty(t.Image) {
+			images[t.Type] = t.Image
+		}
+	}
+	return images
+}
+
+// GetBaseCapacityFromPercentage translates a percentage of the current desired capacity, or min size if the
+// scaling group does not exist yet, into an absolute on-demand base capacity, rounding up
+func (ctx *EksInstanceGroupContext) GetBaseCapacityFromPercentage(percentage int) int64 {
+	var (
+		instanceGroup = ctx.GetInstanceGroup()
+		spec          = instanceGroup.GetEKSSpec()
+		state         = ctx.GetDiscoveredState()
+		scalingGroup  = state.GetScalingGroup()
+		capacity      = spec.GetMinSize()
+	)
+
+	if scalingGroup != nil && aws.Int64Value(scalingGroup.DesiredCapacity) > capacity {
+		capacity = aws.Int64Value(scalingGroup.DesiredCapacity)
+	}
+
+	return (capacity*int64(percentage) + 99) / 100
+}
+
+// GetDesiredMinSize returns the min size the scaling group should be updated to. Raising the min size above the desired
+// capacity scales the group up, while nodes are not ready and a previous scale-up has not stabilized the increase is
+// deferred and the current min size is kept to avoid scaling up a group which is unable to launch healthy nodes
+func (ctx *EksInstanceGroupContext) GetDesiredMinSize() int64 {
+	var (
+		instanceGroup = ctx.GetInstanceGroup()
+		spec          = instanceGroup.GetEKSSpec()
+		status        = instanceGroup.GetStatus()
+		state         = ctx.GetDiscoveredState()
+		scalingGroup  = state.GetScalingGroup()
+		minSize       = spec.GetMinSize()
+	)
+
+	if scalingGroup == nil {
+		return minSize
+	}
+
+	var (
+		currentMin = aws.Int64Value(scalingGroup.MinSize)
+		desired    = aws.Int64Value(scalingGroup.DesiredCapacity)
+	)
+
+	if minSize <= currentMin || minSize <= desired {
+		return minSize
+	}
+
+	if status.GetNodesReadyCondition() != corev1.ConditionFalse {
+		return minSize
+	}
+
+	// desired capacity may be in vCPUs, compare it with the weighted capacity of the ready instances
+	if int64(ctx.GetReadyCapacity()) >= desired {
+		return minSize
+	}
+	return currentMin
+}
+
+// GetReadyCapacity returns the capacity of the scaling group's instances which have a ready node, in the unit of the
+// scaling group's desired capacity
+func (ctx *EksInstanceGroupContext) GetReadyCapacity() int {
+	var (
+		state        = ctx.GetDiscoveredState()
+		scalingGroup = state.GetScalingGroup()
+		nodes        = state.GetClusterNodes()
+		ready        = make([]*autoscaling.Instance, 0)
+	)
+
+	if nodes == nil {
+		return 0
+	}
+
+	for _, instance := range scalingGroup.Instances {
+		id := aws.StringValue(instance.InstanceId)
+		if len(kubeprovider.GetReadyNodesByInstance([]string{id}, nodes)) > 0 {
+			ready = append(ready, instance)
+		}
+	}
+	return GetInstancesCapacity(ready)
+}
+
+func (ctx *EksInstanceGroupContext) GetDesiredMixedInstancesPolicy(name string) (*autoscaling.MixedInstancesPolicy, error) {
+	var (
+		instanceGroup = ctx.GetInstanceGroup()
+		configuration = instanceGroup.GetEKSConfiguration()
+		mixedPolicy   = configuration.GetMixedInstancesPolicy()
+	)
+
+	if mixedPolicy == nil {
+		return nil, nil
 	}
 
-	overrides := ctx.GetOverrides()
+	overrides, err := ctx.GetOverrides(name)
+	if err != nil {
+		return nil, err
+	}
 
 	var allocationStrategy string
 	strategy := common.StringValue(mixedPolicy.Strategy)
@@ -1173,10 +3244,13 @@ func (ctx *EksInstanceGroupContext) GetDesiredMixedInstancesPolicy(name string)
 	}
 
 	var baseCapacity *int64
-	if mixedPolicy.BaseCapacity == nil {
-		baseCapacity = aws.Int64(0)
-	} else {
+	switch {
+	case mixedPolicy.BaseCapacityPercentage != nil:
+		baseCapacity = aws.Int64(ctx.GetBaseCapacityFromPercentage(*mixedPolicy.BaseCapacityPercentage))
+	case mixedPolicy.BaseCapacity != nil:
 		baseCapacity = mixedPolicy.BaseCapacity
+	default:
+		baseCapacity = aws.Int64(0)
 	}
 
 	spotRatio := common.IntOrStrValue(mixedPolicy.SpotRatio)
@@ -1192,13 +3266,13 @@ func (ctx *EksInstanceGroupContext) GetDesiredMixedInstancesPolicy(name string)
 		LaunchTemplate: &autoscaling.LaunchTemplate{
 			LaunchTemplateSpecification: &autoscaling.LaunchTemplateSpecification{
 				LaunchTemplateName: aws.String(name),
-				Version:            aws.String(awsprovider.LaunchTemplateLatestVersionKey),
+				Version:            aws.String(ctx.GetLaunchTemplateVersion()),
 			},
 			Overrides: overrides,
 		},
 	}
 
-	return policy
+	return policy, nil
 }
 
 func FilterSupportedArch(architectures []string) string {
@@ -16,18 +16,29 @@ limitations under the License.
 package eks
 
 import (
+	"bytes"
+	"compress/gzip"
+	"context"
 	"encoding/base64"
 	"fmt"
+	"io"
 	"sort"
 	"strings"
 	"testing"
+	"time"
+	"unicode/utf8"
 
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/autoscaling"
 	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/eks"
+	"github.com/aws/aws-sdk-go/service/ssm"
 	"github.com/keikoproj/instance-manager/api/instancemgr/v1alpha1"
+	"github.com/keikoproj/instance-manager/controllers/common"
 	awsprovider "github.com/keikoproj/instance-manager/controllers/providers/aws"
 	kubeprovider "github.com/keikoproj/instance-manager/controllers/providers/kubernetes"
 	"github.com/onsi/gomega"
@@ -82,6 +93,140 @@ func TestAutoscalerTags(t *testing.T) {
 
 }
 
+func TestAutoscalerTagsRemoved(t *testing.T) {
+	var (
+		g       = gomega.NewGomegaWithT(t)
+		k       = MockKubernetesClientSet()
+		ig      = MockInstanceGroup()
+		asgMock = NewAutoScalingMocker()
+		iamMock = NewIamMocker()
+		eksMock = NewEksMocker()
+		ec2Mock = NewEc2Mocker()
+		ssmMock = NewSsmMocker()
+	)
+
+	w := MockAwsWorker(asgMock, iamMock, eksMock, ec2Mock, ssmMock)
+
+	ig.Annotations = map[string]string{
+		ClusterAutoscalerEnabledAnnotation: "true",
+	}
+	ig.GetEKSConfiguration().Labels = map[string]string{"foo": "bar"}
+	ctx := MockContext(ig, k, w)
+
+	// scaling group tagged while cluster-autoscaler was enabled
+	scalingGroup := &autoscaling.Group{
+		AutoScalingGroupName: aws.String("foo"),
+	}
+	ctx.SetDiscoveredState(&DiscoveredState{
+		ScalingGroup: scalingGroup,
+	})
+
+	autoscalerTags := make([]string, 0)
+	for _, tag := range ctx.GetAddedTags("foo") {
+		scalingGroup.Tags = append(scalingGroup.Tags, &autoscaling.TagDescription{
+			Key:   tag.Key,
+			Value: tag.Value,
+		})
+		if strings.HasPrefix(aws.StringValue(tag.Key), "k8s.io/cluster-autoscaler/") {
+			autoscalerTags = append(autoscalerTags, aws.StringValue(tag.Key))
+		}
+	}
+	g.Expect(autoscalerTags).To(gomega.ContainElements(
+		"k8s.io/cluster-autoscaler/enabled",
+		"k8s.io/cluster-autoscaler/"+ig.GetEKSConfiguration().GetClusterName(),
+		"k8s.io/cluster-autoscaler/node-template/label/foo",
+	))
+	g.Expect(ctx.GetRemovedTags("foo")).To(gomega.BeEmpty())
+	g.Expect(ctx.TagsUpdateNeeded()).To(gomega.BeFalse())
+
+	// toggle cluster-autoscaler off, only the autoscaler tags are removed
+	delete(ig.Annotations, ClusterAutoscalerEnabledAnnotation)
+
+	removed := make([]string, 0)
+	for _, tag := range ctx.GetRemovedTags("foo") {
+		removed = append(removed, aws.StringValue(tag.Key))
+	}
+	g.Expect(removed).To(gomega.ConsistOf(autoscalerTags))
+	g.Expect(ctx.TagsUpdateNeeded()).To(gomega.BeTrue())
+}
+
+func TestGetTaintList(t *testing.T) {
+	var (
+		g       = gomega.NewGomegaWithT(t)
+		k       = MockKubernetesClientSet()
+		ig      = MockInstanceGroup()
+		asgMock = NewAutoScalingMocker()
+		iamMock = NewIamMocker()
+		eksMock = NewEksMocker()
+		ec2Mock = NewEc2Mocker()
+		ssmMock = NewSsmMocker()
+	)
+
+	w := MockAwsWorker(asgMock, iamMock, eksMock, ec2Mock, ssmMock)
+	ctx := MockContext(ig, k, w)
+
+	ig.GetEKSConfiguration().Taints = []corev1.Taint{
+		{
+			Key:    "red",
+			Value:  "green",
+			Effect: corev1.TaintEffectNoSchedule,
+		},
+		{
+			Key:    "dedicated",
+			Effect: corev1.TaintEffectNoExecute,
+		},
+	}
+
+	g.Expect(ctx.GetTaintList()).To(gomega.Equal([]string{"dedicated:NoExecute", "red=green:NoSchedule"}))
+	g.Expect(ctx.GetKubeletExtraArgs()).To(gomega.ContainSubstring("--register-with-taints=dedicated:NoExecute,red=green:NoSchedule"))
+}
+
+func TestGetTaintListStartupTaint(t *testing.T) {
+	var (
+		g       = gomega.NewGomegaWithT(t)
+		k       = MockKubernetesClientSet()
+		ig      = MockInstanceGroup()
+		asgMock = NewAutoScalingMocker()
+		iamMock = NewIamMocker()
+		eksMock = NewEksMocker()
+		ec2Mock = NewEc2Mocker()
+		ssmMock = NewSsmMocker()
+	)
+
+	w := MockAwsWorker(asgMock, iamMock, eksMock, ec2Mock, ssmMock)
+	ctx := MockContext(ig, k, w)
+
+	ig.GetEKSConfiguration().Taints = []corev1.Taint{
+		{
+			Key:    "red",
+			Value:  "green",
+			Effect: corev1.TaintEffectNoSchedule,
+		},
+	}
+
+	tests := []struct {
+		annotation string
+		expected   []string
+	}{
+		{annotation: "", expected: []string{"red=green:NoSchedule"}},
+		{annotation: "true", expected: []string{DefaultStartupTaint, "red=green:NoSchedule"}},
+		{annotation: "example.com/cni-not-ready:NoSchedule", expected: []string{"example.com/cni-not-ready:NoSchedule", "red=green:NoSchedule"}},
+		{annotation: "red=green:NoSchedule", expected: []string{"red=green:NoSchedule"}},
+		{annotation: "example.com/cni-not-ready", expected: []string{"red=green:NoSchedule"}},
+		{annotation: "example.com/cni-not-ready:Invalid", expected: []string{"red=green:NoSchedule"}},
+	}
+
+	for i, tc := range tests {
+		t.Logf("#%v -> %v", i, tc.annotation)
+		ig.Annotations[StartupTaintAnnotation] = tc.annotation
+		g.Expect(ctx.GetTaintList()).To(gomega.Equal(tc.expected))
+		g.Expect(ctx.GetKubeletExtraArgs()).To(gomega.ContainSubstring("--register-with-taints=" + strings.Join(tc.expected, ",")))
+	}
+
+	// the configured taints are not modified
+	g.Expect(ig.GetEKSConfiguration().GetTaints()).To(gomega.HaveLen(1))
+}
+
 func TestGetBasicUserDataAmazonLinux2(t *testing.T) {
 	var (
 		k             = MockKubernetesClientSet()
@@ -173,6 +318,344 @@ bar`
 	}
 }
 
+func TestGetBasicUserDataCredentialProvider(t *testing.T) {
+	var (
+		g             = gomega.NewGomegaWithT(t)
+		k             = MockKubernetesClientSet()
+		ig            = MockInstanceGroup()
+		asgMock       = NewAutoScalingMocker()
+		iamMock       = NewIamMocker()
+		eksMock       = NewEksMocker()
+		ec2Mock       = NewEc2Mocker()
+		ssmMock       = NewSsmMocker()
+		configuration = ig.GetEKSConfiguration()
+	)
+
+	w := MockAwsWorker(asgMock, iamMock, eksMock, ec2Mock, ssmMock)
+	ctx := MockContext(ig, k, w)
+
+	configuration.BootstrapArguments = "--image-credential-provider-config=/etc/eks/image-credential-provider/config.json"
+	configuration.BootstrapOptions = &v1alpha1.BootstrapOptions{
+		CredentialProvider: &v1alpha1.CredentialProviderSpec{
+			Name:        "ecr-credential-provider",
+			MatchImages: []string{"*.dkr.ecr.*.amazonaws.com", "*.dkr.ecr.*.amazonaws.com.cn"},
+			Args:        []string{"get-credentials"},
+		},
+	}
+
+	render := func() string {
+		var (
+			args            = ctx.GetBootstrapArgs()
+			kubeletArgs     = ctx.GetKubeletExtraArgs()
+			userDataPayload = ctx.GetUserDataStages()
+			mounts          = ctx.GetMountOpts()
+		)
+		decoded, err := base64.StdEncoding.DecodeString(ctx.GetBasicUserData("foo", args, kubeletArgs, userDataPayload, mounts))
+		g.Expect(err).NotTo(gomega.HaveOccurred())
+		return string(decoded)
+	}
+
+	// amazonlinux2 writes the config file and points the kubelet at it
+	expectedConfig := `cat <<'EOF' > /etc/eks/image-credential-provider/instance-manager-config.json
+{
+  "apiVersion": "kubelet.config.k8s.io/v1",
+  "kind": "CredentialProviderConfig",
+  "providers": [
+    {
+      "name": "ecr-credential-provider",
+      "matchImages": [
+        "*.dkr.ecr.*.amazonaws.com",
+        "*.dkr.ecr.*.amazonaws.com.cn"
+      ],
+      "defaultCacheDuration": "12h",
+      "apiVersion": "credentialprovider.kubelet.k8s.io/v1",
+      "args": [
+        "get-credentials"
+      ]
+    }
+  ]
+}
+EOF
+set -o xtrace`
+	userData := render()
+	g.Expect(userData).To(gomega.ContainSubstring(expectedConfig))
+	g.Expect(userData).To(gomega.ContainSubstring("--image-credential-provider-config=/etc/eks/image-credential-provider/instance-manager-config.json --image-credential-provider-bin-dir=/etc/eks/image-credential-provider'"))
+	g.Expect(strings.Count(userData, "--image-credential-provider-config")).To(gomega.Equal(1))
+
+	configuration.BootstrapOptions.CredentialProvider.BinDir = "/opt/bin"
+	g.Expect(render()).To(gomega.ContainSubstring("--image-credential-provider-bin-dir=/opt/bin'"))
+
+	// other providers in the default bin dir keep the AMI's ecr-credential-provider
+	configuration.BootstrapOptions.CredentialProvider = &v1alpha1.CredentialProviderSpec{
+		Name:        "registry-credential-provider",
+		MatchImages: []string{"registry.example.com"},
+	}
+	userData = render()
+	g.Expect(userData).To(gomega.ContainSubstring(`"name": "ecr-credential-provider"`))
+	g.Expect(userData).To(gomega.ContainSubstring(`"name": "registry-credential-provider"`))
+	g.Expect(strings.Index(userData, `"ecr-credential-provider"`)).To(gomega.BeNumerically("<", strings.Index(userData, `"registry-credential-provider"`)))
+
+	// providers in a custom bin dir replace it
+	configuration.BootstrapOptions.CredentialProvider.BinDir = "/opt/bin"
+	userData = render()
+	g.Expect(userData).NotTo(gomega.ContainSubstring(`"name": "ecr-credential-provider"`))
+	g.Expect(userData).To(gomega.ContainSubstring(`"name": "registry-credential-provider"`))
+
+	// al2023 writes the config file and passes the flags in the NodeConfig
+	cluster := MockEksCluster("1.30")
+	cluster.KubernetesNetworkConfig = &eks.KubernetesNetworkConfigResponse{ServiceIpv4Cidr: aws.String("172.20.0.0/16")}
+	ctx.GetDiscoveredState().SetCluster(cluster)
+	ig.Annotations[OsFamilyAnnotation] = OsFamilyAmazonLinux2023
+	configuration.BootstrapOptions.CredentialProvider.BinDir = ""
+	userData = render()
+	g.Expect(userData).To(gomega.ContainSubstring("Content-Type: application/node.eks.aws"))
+	g.Expect(userData).To(gomega.ContainSubstring("    name: foo\n"))
+	g.Expect(userData).To(gomega.ContainSubstring("    cidr: 172.20.0.0/16\n"))
+	g.Expect(userData).To(gomega.ContainSubstring(`    - "--image-credential-provider-config=/etc/eks/image-credential-provider/instance-manager-config.json"`))
+	g.Expect(userData).To(gomega.ContainSubstring(`    - "--image-credential-provider-bin-dir=/etc/eks/image-credential-provider"`))
+	g.Expect(userData).To(gomega.ContainSubstring("cat <<'EOF' > /etc/eks/image-credential-provider/instance-manager-config.json"))
+	g.Expect(userData).To(gomega.ContainSubstring(`"name": "registry-credential-provider"`))
+	g.Expect(userData).NotTo(gomega.ContainSubstring("bootstrap.sh"))
+
+	// other os families are not configured
+	ig.Annotations[OsFamilyAnnotation] = OsFamilyBottleRocket
+	g.Expect(render()).NotTo(gomega.ContainSubstring("credential-provider"))
+}
+
+func TestGetBootstrapArgsClusterOverrides(t *testing.T) {
+	var (
+		g             = gomega.NewGomegaWithT(t)
+		k             = MockKubernetesClientSet()
+		ig            = MockInstanceGroup()
+		asgMock       = NewAutoScalingMocker()
+		iamMock       = NewIamMocker()
+		eksMock       = NewEksMocker()
+		ec2Mock       = NewEc2Mocker()
+		ssmMock       = NewSsmMocker()
+		configuration = ig.GetEKSConfiguration()
+	)
+
+	w := MockAwsWorker(asgMock, iamMock, eksMock, ec2Mock, ssmMock)
+	ctx := MockContext(ig, k, w)
+	ctx.GetDiscoveredState().SetCluster(MockEksCluster("1.27"))
+
+	// discovered values are used by default
+	args := ctx.GetBootstrapArgs()
+	g.Expect(args).To(gomega.ContainSubstring("--b64-cluster-ca dGVzdA== --apiserver-endpoint foo.amazonaws.com "))
+
+	// overrides win over discovered values
+	configuration.ClusterCA = "b3ZlcnJpZGU="
+	configuration.ApiServerEndpoint = "https://api.example.internal"
+	args = ctx.GetBootstrapArgs()
+	g.Expect(args).To(gomega.ContainSubstring("--b64-cluster-ca b3ZlcnJpZGU= --apiserver-endpoint https://api.example.internal "))
+	g.Expect(args).NotTo(gomega.ContainSubstring("dGVzdA=="))
+
+	// overrides are injected even when the cluster cannot be described
+	ctx.GetDiscoveredState().SetCluster(nil)
+	g.Expect(ctx.GetBootstrapArgs()).To(gomega.ContainSubstring("--b64-cluster-ca b3ZlcnJpZGU= --apiserver-endpoint https://api.example.internal "))
+
+	ig.Annotations[OsFamilyAnnotation] = OsFamilyWindows
+	g.Expect(ctx.GetBootstrapArgs()).To(gomega.ContainSubstring("-Base64ClusterCA b3ZlcnJpZGU= -APIServerEndpoint https://api.example.internal "))
+}
+
+func TestGetBasicUserDataCompressed(t *testing.T) {
+	var (
+		g       = gomega.NewGomegaWithT(t)
+		k       = MockKubernetesClientSet()
+		ig      = MockInstanceGroup()
+		asgMock = NewAutoScalingMocker()
+		iamMock = NewIamMocker()
+		eksMock = NewEksMocker()
+		ec2Mock = NewEc2Mocker()
+		ssmMock = NewSsmMocker()
+	)
+
+	w := MockAwsWorker(asgMock, iamMock, eksMock, ec2Mock, ssmMock)
+	ctx := MockContext(ig, k, w)
+
+	var (
+		args            = ctx.GetBootstrapArgs()
+		kubeletArgs     = ctx.GetKubeletExtraArgs()
+		userDataPayload = ctx.GetUserDataStages()
+		mounts          = ctx.GetMountOpts()
+	)
+
+	plain := ctx.GetBasicUserData("foo", args, kubeletArgs, userDataPayload, mounts)
+	expected, err := base64.StdEncoding.DecodeString(plain)
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+
+	ig.Annotations[CompressUserDataAnnotation] = "true"
+	compressed := ctx.GetBasicUserData("foo", args, kubeletArgs, userDataPayload, mounts)
+	g.Expect(compressed).NotTo(gomega.Equal(plain))
+
+	// rendering is deterministic to avoid drift
+	g.Expect(ctx.GetBasicUserData("foo", args, kubeletArgs, userDataPayload, mounts)).To(gomega.Equal(compressed))
+
+	decoded, err := base64.StdEncoding.DecodeString(compressed)
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	reader, err := gzip.NewReader(bytes.NewReader(decoded))
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	decompressed, err := io.ReadAll(reader)
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(string(decompressed)).To(gomega.Equal(string(expected)))
+
+	// other os families are not compressed
+	ig.Annotations[OsFamilyAnnotation] = OsFamilyBottleRocket
+	decoded, err = base64.StdEncoding.DecodeString(ctx.GetBasicUserData("foo", args, kubeletArgs, userDataPayload, mounts))
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(string(decoded)).To(gomega.ContainSubstring("[settings.kubernetes]"))
+}
+
+func TestGetBasicUserDataShebang(t *testing.T) {
+	var (
+		g       = gomega.NewGomegaWithT(t)
+		k       = MockKubernetesClientSet()
+		ig      = MockInstanceGroup()
+		asgMock = NewAutoScalingMocker()
+		iamMock = NewIamMocker()
+		eksMock = NewEksMocker()
+		ec2Mock = NewEc2Mocker()
+		ssmMock = NewSsmMocker()
+	)
+
+	w := MockAwsWorker(asgMock, iamMock, eksMock, ec2Mock, ssmMock)
+	ctx := MockContext(ig, k, w)
+
+	var (
+		args            = ctx.GetBootstrapArgs()
+		kubeletArgs     = ctx.GetKubeletExtraArgs()
+		userDataPayload = ctx.GetUserDataStages()
+		mounts          = ctx.GetMountOpts()
+	)
+
+	decoded, err := base64.StdEncoding.DecodeString(ctx.GetBasicUserData("foo", args, kubeletArgs, userDataPayload, mounts))
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(string(decoded)).To(gomega.HavePrefix(DefaultUserDataShebang + "\n"))
+
+	ig.GetEKSConfiguration().BootstrapOptions = &v1alpha1.BootstrapOptions{
+		UserDataShebang: "#!/usr/bin/env bash\nset -euo pipefail\n",
+	}
+	decoded, err = base64.StdEncoding.DecodeString(ctx.GetBasicUserData("foo", args, kubeletArgs, userDataPayload, mounts))
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(string(decoded)).To(gomega.HavePrefix("#!/usr/bin/env bash\nset -euo pipefail\n"))
+	g.Expect(string(decoded)).NotTo(gomega.ContainSubstring(DefaultUserDataShebang))
+}
+
+func TestSetRenderedUserData(t *testing.T) {
+	var (
+		g       = gomega.NewGomegaWithT(t)
+		k       = MockKubernetesClientSet()
+		ig      = MockInstanceGroup()
+		asgMock = NewAutoScalingMocker()
+		iamMock = NewIamMocker()
+		eksMock = NewEksMocker()
+		ec2Mock = NewEc2Mocker()
+		ssmMock = NewSsmMocker()
+	)
+
+	w := MockAwsWorker(asgMock, iamMock, eksMock, ec2Mock, ssmMock)
+	ctx := MockContext(ig, k, w)
+	ctx.GetDiscoveredState().Publisher.Client = k.Kubernetes
+
+	var (
+		args            = ctx.GetBootstrapArgs()
+		kubeletArgs     = ctx.GetKubeletExtraArgs()
+		userDataPayload = ctx.GetUserDataStages()
+		mounts          = ctx.GetMountOpts()
+	)
+
+	listEvents := func() []corev1.Event {
+		events, err := k.Kubernetes.CoreV1().Events("").List(context.Background(), metav1.ListOptions{})
+		g.Expect(err).NotTo(gomega.HaveOccurred())
+		return events.Items
+	}
+
+	userData := ctx.GetBasicUserData("foo", args, kubeletArgs, userDataPayload, mounts)
+	plain, err := ctx.DecodeUserData(userData)
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(plain).To(gomega.ContainSubstring("/etc/eks/bootstrap.sh foo"))
+
+	// the hash is always recorded, events are only published on demand
+	ctx.SetRenderedUserData(userData)
+	g.Expect(ig.GetStatus().GetRenderedUserDataHash()).To(gomega.Equal(common.StringMD5(plain)))
+	g.Expect(listEvents()).To(gomega.BeEmpty())
+
+	// the annotation has no effect unless dumps are enabled on the controller
+	ig.Annotations[DumpUserDataAnnotation] = "true"
+	userDataPayload.PreBootstrap = append(userDataPayload.PreBootstrap, "export API_TOKEN=abc123\n")
+	userData = ctx.GetBasicUserData("foo", args, kubeletArgs, userDataPayload, mounts)
+	ctx.SetRenderedUserData(userData)
+	g.Expect(listEvents()).To(gomega.BeEmpty())
+
+	// a changed userData is published with secret-like values redacted
+	ctx.EnableUserDataDump = true
+	userData = ctx.GetBasicUserData("bar", args, kubeletArgs, userDataPayload, mounts)
+	ctx.SetRenderedUserData(userData)
+	events := listEvents()
+	g.Expect(events).To(gomega.HaveLen(1))
+	g.Expect(events[0].Reason).To(gomega.Equal(string(kubeprovider.UserDataRenderedEvent)))
+	g.Expect(events[0].Message).To(gomega.ContainSubstring("/etc/eks/bootstrap.sh bar"))
+	g.Expect(events[0].Message).To(gomega.ContainSubstring("API_TOKEN=REDACTED"))
+	g.Expect(events[0].Message).NotTo(gomega.ContainSubstring("abc123"))
+
+	// an unchanged userData is not published again, compressed userData results in the same hash
+	ctx.SetRenderedUserData(userData)
+	ig.Annotations[CompressUserDataAnnotation] = "true"
+	compressed := ctx.GetBasicUserData("bar", args, kubeletArgs, userDataPayload, mounts)
+	g.Expect(compressed).NotTo(gomega.Equal(userData))
+	ctx.SetRenderedUserData(compressed)
+	plain, err = ctx.DecodeUserData(compressed)
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(ig.GetStatus().GetRenderedUserDataHash()).To(gomega.Equal(common.StringMD5(plain)))
+	g.Expect(listEvents()).To(gomega.HaveLen(1))
+
+	// newly requesting a dump publishes an unchanged userData
+	delete(ig.Annotations, DumpUserDataAnnotation)
+	ctx.SetRenderedUserData(compressed)
+	g.Expect(listEvents()).To(gomega.HaveLen(1))
+	ig.Annotations[DumpUserDataAnnotation] = "true"
+	ctx.SetRenderedUserData(compressed)
+	g.Expect(listEvents()).To(gomega.HaveLen(2))
+}
+
+func TestTruncateUserData(t *testing.T) {
+	var (
+		g = gomega.NewGomegaWithT(t)
+	)
+
+	g.Expect(truncateUserData("abc", 5)).To(gomega.Equal("abc"))
+	g.Expect(truncateUserData("abcdef", 3)).To(gomega.Equal("abc"))
+
+	// multi-byte characters are not split
+	g.Expect(truncateUserData("abécd", 3)).To(gomega.Equal("ab"))
+	g.Expect(truncateUserData("abécd", 4)).To(gomega.Equal("abé"))
+	g.Expect(utf8.ValidString(truncateUserData(strings.Repeat("€", 2000), UserDataDumpMaxLength))).To(gomega.BeTrue())
+}
+
+func TestRedactUserData(t *testing.T) {
+	var (
+		g = gomega.NewGomegaWithT(t)
+	)
+
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{input: "export API_TOKEN=abc123", expected: "export API_TOKEN=REDACTED"},
+		{input: `password: "my secret"`, expected: "password: REDACTED"},
+		{input: "--db-password='abc 123' --verbose", expected: "--db-password=REDACTED --verbose"},
+		{input: "AWS_SECRET_ACCESS_KEY = abc", expected: "AWS_SECRET_ACCESS_KEY = REDACTED"},
+		{input: "/etc/eks/bootstrap.sh foo --b64-cluster-ca abc", expected: "/etc/eks/bootstrap.sh foo --b64-cluster-ca abc"},
+		{input: `TOKEN=$(curl -X PUT -H "X-aws-ec2-metadata-token-ttl-seconds: 21600")`, expected: `TOKEN=$(curl -X PUT -H "X-aws-ec2-metadata-token-ttl-seconds: 21600")`},
+		{input: `-H "X-aws-ec2-metadata-token: $TOKEN"`, expected: `-H "X-aws-ec2-metadata-token: $TOKEN"`},
+	}
+
+	for i, tc := range tests {
+		t.Logf("Test #%v - %+v", i, tc)
+		g.Expect(RedactUserData(tc.input)).To(gomega.Equal(tc.expected))
+	}
+}
+
 func TestGetBasicUserDataWindows(t *testing.T) {
 	var (
 		k             = MockKubernetesClientSet()
@@ -230,7 +713,7 @@ func TestGetBasicUserDataWindows(t *testing.T) {
     Echo "Not starting Kubelet due to warmed state."
     & C:\ProgramData\Amazon\EC2-Windows\Launch\Scripts\InitializeInstance.ps1 -Schedule
   } else {
-    & $EKSBootstrapScriptFile -EKSClusterName foo -Base64ClusterCA dGVzdA== -APIServerEndpoint foo.amazonaws.com -ContainerRuntime containerd -KubeletExtraArgs '--node-labels=foo=bar,instancemgr.keikoproj.io/image=ami-123456789012,node.kubernetes.io/role=instance-group-1 --register-with-taints=foo=bar:NoSchedule --eviction-hard=memory.available<300Mi,nodefs.available<5% --system-reserved=memory=2.5Gi --v=2 --max-pods=4' 3>&1 4>&1 5>&1 6>&1
+    & $EKSBootstrapScriptFile -EKSClusterName foo -Base64ClusterCA dGVzdA== -APIServerEndpoint foo.amazonaws.com -DNSClusterIP 172.20.0.10 -ContainerRuntime containerd -KubeletExtraArgs '--node-labels=foo=bar,instancemgr.keikoproj.io/image=ami-123456789012,node.kubernetes.io/role=instance-group-1 --register-with-taints=foo=bar:NoSchedule --eviction-hard=memory.available<300Mi,nodefs.available<5% --system-reserved=memory=2.5Gi --v=2 --max-pods=4' 3>&1 4>&1 5>&1 6>&1
     bar
   }
 </powershell>`
@@ -327,6 +810,41 @@ func TestGetBasicUserDataWindowsWithInjectionDisabled(t *testing.T) {
 	}
 }
 
+func TestGetBasicUserDataWindowsLifecycleCheck(t *testing.T) {
+	var (
+		g       = gomega.NewGomegaWithT(t)
+		k       = MockKubernetesClientSet()
+		ig      = MockInstanceGroup()
+		asgMock = NewAutoScalingMocker()
+		iamMock = NewIamMocker()
+		eksMock = NewEksMocker()
+		ec2Mock = NewEc2Mocker()
+		ssmMock = NewSsmMocker()
+	)
+
+	w := MockAwsWorker(asgMock, iamMock, eksMock, ec2Mock, ssmMock)
+	ctx := MockContext(ig, k, w)
+	ig.Annotations[OsFamilyAnnotation] = OsFamilyWindows
+
+	imdsLifecycleCheck := `[string]$Lifecycle=(curl -UseBasicParsing -Method GET "http://169.254.169.254/latest/meta-data/autoscaling/target-lifecycle-state" -H @{ "X-aws-ec2-metadata-token" = "$IMDSToken"} | % { Echo $_.Content})`
+
+	for _, disableInjection := range []bool{false, true} {
+		ctx.DisableWinClusterInjection = disableInjection
+		userData := ctx.GetBasicUserData("foo", ctx.GetBootstrapArgs(), ctx.GetKubeletExtraArgs(), ctx.GetUserDataStages(), ctx.GetMountOpts())
+		decoded, err := base64.StdEncoding.DecodeString(userData)
+		g.Expect(err).NotTo(gomega.HaveOccurred())
+
+		userDataString := string(decoded)
+		g.Expect(userDataString).To(gomega.ContainSubstring(imdsLifecycleCheck))
+		g.Expect(userDataString).NotTo(gomega.ContainSubstring("Get-ASAutoScalingInstance"))
+		if disableInjection {
+			g.Expect(userDataString).NotTo(gomega.ContainSubstring("-DNSClusterIP"))
+		} else {
+			g.Expect(userDataString).To(gomega.ContainSubstring("-DNSClusterIP 172.20.0.10"))
+		}
+	}
+}
+
 func TestCustomNetworkingMaxPods(t *testing.T) {
 	var (
 		k       = MockKubernetesClientSet()
@@ -472,7 +990,7 @@ func TestResolveSecurityGroups(t *testing.T) {
 	}
 }
 
-func TestResolveSubnets(t *testing.T) {
+func TestResolveSecurityGroupsByTag(t *testing.T) {
 	var (
 		g       = gomega.NewGomegaWithT(t)
 		k       = MockKubernetesClientSet()
@@ -488,18 +1006,82 @@ func TestResolveSubnets(t *testing.T) {
 	w := MockAwsWorker(asgMock, iamMock, eksMock, ec2Mock, ssmMock)
 	ctx := MockContext(ig, k, w)
 
+	taggedGroup := func(id, key, value string) *ec2.SecurityGroup {
+		return &ec2.SecurityGroup{
+			GroupId: aws.String(id),
+			Tags: []*ec2.Tag{
+				{
+					Key:   aws.String(key),
+					Value: aws.String(value),
+				},
+			},
+		}
+	}
+
+	ec2Mock.SecurityGroups = []*ec2.SecurityGroup{
+		taggedGroup("sg-111", "purpose", "nodes"),
+		taggedGroup("sg-222", "purpose", "shared"),
+		taggedGroup("sg-333", "purpose", "shared"),
+		MockSecurityGroup("sg-444", true, "my-sg-4"),
+	}
+
 	tests := []struct {
 		requested []string
-		subnets   []*ec2.Subnet
 		result    []string
-		withErr   bool
 	}{
-		{requested: []string{"subnet-111", "subnet-222"}, subnets: []*ec2.Subnet{MockSubnet("subnet-111", false, ""), MockSubnet("subnet-222", false, "")}, result: []string{"subnet-111", "subnet-222"}, withErr: false},
-		{requested: []string{"subnet-111", "subnet-111", "subnet-222"}, subnets: []*ec2.Subnet{MockSubnet("subnet-111", false, ""), MockSubnet("subnet-222", false, "")}, result: []string{"subnet-111", "subnet-222"}, withErr: false},
-		{requested: []string{"my-subnet-1", "subnet-222"}, subnets: []*ec2.Subnet{MockSubnet("subnet-111", true, "my-subnet-1"), MockSubnet("subnet-222", false, "")}, result: []string{"subnet-111", "subnet-222"}, withErr: false},
-		{requested: []string{"my-subnet-1", "my-subnet-2"}, subnets: []*ec2.Subnet{MockSubnet("subnet-111", true, "my-subnet-1"), MockSubnet("subnet-222", true, "my-subnet-2")}, result: []string{"subnet-111", "subnet-222"}, withErr: false},
-		{requested: []string{"my-subnet-2", "my-subnet-1"}, subnets: []*ec2.Subnet{MockSubnet("subnet-111", true, "my-subnet-1"), MockSubnet("subnet-222", true, "my-subnet-2")}, result: []string{"subnet-111", "subnet-222"}, withErr: false},
-		{requested: []string{"my-subnet-1"}, subnets: []*ec2.Subnet{MockSubnet("subnet-111", true, "my-subnet-1")}, result: []string{}, withErr: true},
+		{requested: []string{"sg-tag:purpose=nodes"}, result: []string{"sg-111"}},
+		{requested: []string{"sg-tag:purpose=nodes", "my-sg-4", "sg-555"}, result: []string{"sg-111", "sg-444", "sg-555"}},
+		// ambiguous match is not resolved
+		{requested: []string{"sg-tag:purpose=shared", "sg-555"}, result: []string{"sg-555"}},
+		// no match is not resolved
+		{requested: []string{"sg-tag:purpose=missing"}, result: []string{}},
+		// invalid reference is not resolved
+		{requested: []string{"sg-tag:purpose"}, result: []string{}},
+	}
+
+	for i, tc := range tests {
+		t.Logf("Test #%v - %+v", i, tc)
+		config.NodeSecurityGroups = tc.requested
+		groups := ctx.ResolveSecurityGroups()
+		g.Expect(groups).To(gomega.Equal(tc.result))
+	}
+
+	_, err := w.SecurityGroupByTag("purpose", "shared", "")
+	g.Expect(err).To(gomega.HaveOccurred())
+	g.Expect(err.Error()).To(gomega.ContainSubstring("found 2 security groups with tag purpose=shared"))
+
+	_, err = w.SecurityGroupByTag("purpose", "missing", "")
+	g.Expect(err).To(gomega.HaveOccurred())
+}
+
+func TestResolveSubnets(t *testing.T) {
+	var (
+		g       = gomega.NewGomegaWithT(t)
+		k       = MockKubernetesClientSet()
+		ig      = MockInstanceGroup()
+		config  = ig.GetEKSConfiguration()
+		asgMock = NewAutoScalingMocker()
+		iamMock = NewIamMocker()
+		eksMock = NewEksMocker()
+		ec2Mock = NewEc2Mocker()
+		ssmMock = NewSsmMocker()
+	)
+
+	w := MockAwsWorker(asgMock, iamMock, eksMock, ec2Mock, ssmMock)
+	ctx := MockContext(ig, k, w)
+
+	tests := []struct {
+		requested []string
+		subnets   []*ec2.Subnet
+		result    []string
+		withErr   bool
+	}{
+		{requested: []string{"subnet-111", "subnet-222"}, subnets: []*ec2.Subnet{MockSubnet("subnet-111", false, ""), MockSubnet("subnet-222", false, "")}, result: []string{"subnet-111", "subnet-222"}, withErr: false},
+		{requested: []string{"subnet-111", "subnet-111", "subnet-222"}, subnets: []*ec2.Subnet{MockSubnet("subnet-111", false, ""), MockSubnet("subnet-222", false, "")}, result: []string{"subnet-111", "subnet-222"}, withErr: false},
+		{requested: []string{"my-subnet-1", "subnet-222"}, subnets: []*ec2.Subnet{MockSubnet("subnet-111", true, "my-subnet-1"), MockSubnet("subnet-222", false, "")}, result: []string{"subnet-111", "subnet-222"}, withErr: false},
+		{requested: []string{"my-subnet-1", "my-subnet-2"}, subnets: []*ec2.Subnet{MockSubnet("subnet-111", true, "my-subnet-1"), MockSubnet("subnet-222", true, "my-subnet-2")}, result: []string{"subnet-111", "subnet-222"}, withErr: false},
+		{requested: []string{"my-subnet-2", "my-subnet-1"}, subnets: []*ec2.Subnet{MockSubnet("subnet-111", true, "my-subnet-1"), MockSubnet("subnet-222", true, "my-subnet-2")}, result: []string{"subnet-111", "subnet-222"}, withErr: false},
+		{requested: []string{"my-subnet-1"}, subnets: []*ec2.Subnet{MockSubnet("subnet-111", true, "my-subnet-1")}, result: nil, withErr: true},
 		{requested: []string{"my-subnet-1", "my-subnet-2"}, subnets: []*ec2.Subnet{MockSubnet("subnet-111", true, "my-subnet-2")}, result: []string{"subnet-111"}, withErr: false},
 	}
 
@@ -511,11 +1093,69 @@ func TestResolveSubnets(t *testing.T) {
 		if tc.withErr {
 			ec2Mock.DescribeSubnetsErr = errors.New("an error occured")
 		}
-		groups := ctx.ResolveSubnets()
+		groups, err := ctx.ResolveSubnets()
+		if tc.withErr {
+			g.Expect(err).To(gomega.HaveOccurred())
+		} else {
+			g.Expect(err).NotTo(gomega.HaveOccurred())
+		}
 		g.Expect(groups).To(gomega.Equal(tc.result))
 	}
 }
 
+func TestResolveSubnetsByAvailabilityZone(t *testing.T) {
+	var (
+		g       = gomega.NewGomegaWithT(t)
+		k       = MockKubernetesClientSet()
+		ig      = MockInstanceGroup()
+		config  = ig.GetEKSConfiguration()
+		asgMock = NewAutoScalingMocker()
+		iamMock = NewIamMocker()
+		eksMock = NewEksMocker()
+		ec2Mock = NewEc2Mocker()
+		ssmMock = NewSsmMocker()
+	)
+
+	w := MockAwsWorker(asgMock, iamMock, eksMock, ec2Mock, ssmMock)
+	ctx := MockContext(ig, k, w)
+
+	ec2Mock.Subnets = []*ec2.Subnet{
+		{SubnetId: aws.String("subnet-111"), AvailabilityZone: aws.String("us-west-2a")},
+		{SubnetId: aws.String("subnet-222"), AvailabilityZone: aws.String("us-west-2b")},
+		{SubnetId: aws.String("subnet-333"), AvailabilityZone: aws.String("us-west-2c")},
+		{SubnetId: aws.String("subnet-444"), AvailabilityZone: aws.String("us-west-2a")},
+	}
+	config.Subnets = []string{"subnet-111", "subnet-222", "subnet-333"}
+
+	tests := []struct {
+		zones   string
+		result  []string
+		withErr bool
+	}{
+		{zones: "", result: []string{"subnet-111", "subnet-222", "subnet-333"}},
+		{zones: "us-west-2a", result: []string{"subnet-111"}},
+		{zones: "us-west-2c, us-west-2b", result: []string{"subnet-222", "subnet-333"}},
+		{zones: "us-west-2d", result: []string{}},
+		{zones: "us-west-2a", result: nil, withErr: true},
+	}
+
+	for i, tc := range tests {
+		t.Logf("Test #%v - %+v", i, tc)
+		ig.GetAnnotations()[AvailabilityZonesAnnotation] = tc.zones
+		ec2Mock.DescribeSubnetsErr = nil
+		if tc.withErr {
+			ec2Mock.DescribeSubnetsErr = errors.New("an error occured")
+		}
+		subnets, err := ctx.ResolveSubnets()
+		if tc.withErr {
+			g.Expect(err).To(gomega.HaveOccurred())
+		} else {
+			g.Expect(err).NotTo(gomega.HaveOccurred())
+		}
+		g.Expect(subnets).To(gomega.Equal(tc.result))
+	}
+}
+
 func TestGetDisabledMetrics(t *testing.T) {
 	var (
 		g       = gomega.NewGomegaWithT(t)
@@ -621,6 +1261,105 @@ func TestGetEnabledMetrics(t *testing.T) {
 	metrics, ok = ctx.GetEnabledMetrics()
 	g.Expect(ok).To(gomega.BeTrue())
 	g.Expect(metrics).To(gomega.ContainElement("GroupMaxSize"))
+
+	// Metrics enabled at a different granularity are enabled again
+	ctx.SetDiscoveredState(&DiscoveredState{
+		Publisher: kubeprovider.EventPublisher{
+			Client: k.Kubernetes,
+		},
+		ScalingGroup: &autoscaling.Group{
+			EnabledMetrics: []*autoscaling.EnabledMetric{
+				{Metric: aws.String("GroupMinSize"), Granularity: aws.String("5Minute")},
+				{Metric: aws.String("GroupMaxSize"), Granularity: aws.String("1Minute")},
+			},
+		},
+	})
+
+	metrics, ok = ctx.GetEnabledMetrics()
+	g.Expect(ok).To(gomega.BeTrue())
+	g.Expect(metrics).To(gomega.ConsistOf("GroupMinSize"))
+}
+
+func TestUpdateMetricsCollectionGranularity(t *testing.T) {
+	var (
+		g       = gomega.NewGomegaWithT(t)
+		k       = MockKubernetesClientSet()
+		ig      = MockInstanceGroup()
+		asgMock = NewAutoScalingMocker()
+		iamMock = NewIamMocker()
+		eksMock = NewEksMocker()
+		ec2Mock = NewEc2Mocker()
+		ssmMock = NewSsmMocker()
+	)
+
+	w := MockAwsWorker(asgMock, iamMock, eksMock, ec2Mock, ssmMock)
+	ctx := MockContext(ig, k, w)
+
+	ig.GetEKSConfiguration().SetMetricsCollection([]string{"GroupMinSize"})
+	ig.GetEKSConfiguration().MetricsGranularity = v1alpha1.MetricsGranularityOneMinute
+	ctx.SetDiscoveredState(&DiscoveredState{
+		Publisher: kubeprovider.EventPublisher{
+			Client: k.Kubernetes,
+		},
+		ScalingGroup: &autoscaling.Group{
+			EnabledMetrics: MockEnabledMetrics(),
+		},
+	})
+
+	err := ctx.UpdateMetricsCollection("some-scaling-group")
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(asgMock.EnableMetricsCollectionInput).NotTo(gomega.BeNil())
+	g.Expect(aws.StringValue(asgMock.EnableMetricsCollectionInput.Granularity)).To(gomega.Equal(v1alpha1.MetricsGranularityOneMinute))
+	g.Expect(aws.StringValueSlice(asgMock.EnableMetricsCollectionInput.Metrics)).To(gomega.ConsistOf("GroupMinSize"))
+}
+
+func TestUpdateScalingProcesses(t *testing.T) {
+	var (
+		g       = gomega.NewGomegaWithT(t)
+		k       = MockKubernetesClientSet()
+		ig      = MockInstanceGroup()
+		asgMock = NewAutoScalingMocker()
+		iamMock = NewIamMocker()
+		eksMock = NewEksMocker()
+		ec2Mock = NewEc2Mocker()
+		ssmMock = NewSsmMocker()
+	)
+
+	w := MockAwsWorker(asgMock, iamMock, eksMock, ec2Mock, ssmMock)
+	ctx := MockContext(ig, k, w)
+
+	scalingGroup := &autoscaling.Group{
+		SuspendedProcesses: []*autoscaling.SuspendedProcess{
+			{ProcessName: aws.String("Launch")},
+		},
+	}
+	ctx.SetDiscoveredState(&DiscoveredState{
+		Publisher: kubeprovider.EventPublisher{
+			Client: k.Kubernetes,
+		},
+		ScalingGroup: scalingGroup,
+	})
+
+	// specific processes listed with 'all' are ignored
+	ig.GetEKSConfiguration().SetSuspendProcesses([]string{"Launch", "all", "Launch"})
+	err := ctx.UpdateScalingProcesses("some-scaling-group")
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(asgMock.SuspendProcessesInput).NotTo(gomega.BeNil())
+	g.Expect(aws.StringValueSlice(asgMock.SuspendProcessesInput.ScalingProcesses)).To(gomega.ConsistOf(common.Difference(awsprovider.DefaultSuspendProcesses, []string{"Launch"})))
+	g.Expect(asgMock.ResumeProcessesInput).To(gomega.BeNil())
+
+	// switching from 'all' to none resumes every process
+	scalingGroup.SuspendedProcesses = []*autoscaling.SuspendedProcess{}
+	for _, p := range awsprovider.DefaultSuspendProcesses {
+		scalingGroup.SuspendedProcesses = append(scalingGroup.SuspendedProcesses, &autoscaling.SuspendedProcess{ProcessName: aws.String(p)})
+	}
+	asgMock.SuspendProcessesInput = nil
+	ig.GetEKSConfiguration().SetSuspendProcesses([]string{})
+	err = ctx.UpdateScalingProcesses("some-scaling-group")
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(asgMock.SuspendProcessesInput).To(gomega.BeNil())
+	g.Expect(asgMock.ResumeProcessesInput).NotTo(gomega.BeNil())
+	g.Expect(aws.StringValueSlice(asgMock.ResumeProcessesInput.ScalingProcesses)).To(gomega.ConsistOf(awsprovider.DefaultSuspendProcesses))
 }
 
 func TestGetLabelList(t *testing.T) {
@@ -693,6 +1432,149 @@ func TestGetLabelList(t *testing.T) {
 	}
 }
 
+func TestGetComputedLabelsCapacityType(t *testing.T) {
+	var (
+		g             = gomega.NewGomegaWithT(t)
+		k             = MockKubernetesClientSet()
+		ig            = MockInstanceGroup()
+		configuration = ig.GetEKSConfiguration()
+		status        = ig.GetStatus()
+		asgMock       = NewAutoScalingMocker()
+		iamMock       = NewIamMocker()
+		eksMock       = NewEksMocker()
+		ec2Mock       = NewEc2Mocker()
+		ssmMock       = NewSsmMocker()
+		enabled       = map[string]string{CapacityTypeLabelAnnotation: "true"}
+	)
+
+	w := MockAwsWorker(asgMock, iamMock, eksMock, ec2Mock, ssmMock)
+	ctx := MockContext(ig, k, w)
+	ctx.SetDiscoveredState(&DiscoveredState{
+		Publisher: kubeprovider.EventPublisher{
+			Client: k.Kubernetes,
+		},
+		Cluster: MockEksCluster("1.20"),
+	})
+
+	tests := []struct {
+		lifecycle           string
+		annotations         map[string]string
+		instanceGroupLabels map[string]string
+		expectedLabel       string
+	}{
+		// label is opt-in
+		{lifecycle: v1alpha1.LifecycleStateNormal, expectedLabel: ""},
+		{lifecycle: v1alpha1.LifecycleStateSpot, expectedLabel: ""},
+		// label matches the lifecycle
+		{lifecycle: v1alpha1.LifecycleStateNormal, annotations: enabled, expectedLabel: CapacityTypeOnDemand},
+		{lifecycle: v1alpha1.LifecycleStateSpot, annotations: enabled, expectedLabel: CapacityTypeSpot},
+		// mixed groups are not labeled
+		{lifecycle: v1alpha1.LifecycleStateMixed, annotations: enabled, expectedLabel: ""},
+		// custom labels are not overridden
+		{lifecycle: v1alpha1.LifecycleStateSpot, annotations: enabled, instanceGroupLabels: map[string]string{CapacityTypeLabel: "custom"}, expectedLabel: "custom"},
+	}
+
+	for i, tc := range tests {
+		t.Logf("Test #%v - %+v", i, tc)
+		configuration.SetLabels(tc.instanceGroupLabels)
+		ig.SetAnnotations(tc.annotations)
+		status.SetLifecycle(tc.lifecycle)
+		labels := ctx.GetComputedLabels()
+		if tc.expectedLabel == "" {
+			g.Expect(labels).NotTo(gomega.HaveKey(CapacityTypeLabel))
+		} else {
+			g.Expect(labels).To(gomega.HaveKeyWithValue(CapacityTypeLabel, tc.expectedLabel))
+		}
+	}
+}
+
+func TestGetComputedLabelsLegacyRoleLabelCutoff(t *testing.T) {
+	var (
+		g       = gomega.NewGomegaWithT(t)
+		k       = MockKubernetesClientSet()
+		ig      = MockInstanceGroup()
+		asgMock = NewAutoScalingMocker()
+		iamMock = NewIamMocker()
+		eksMock = NewEksMocker()
+		ec2Mock = NewEc2Mocker()
+		ssmMock = NewSsmMocker()
+	)
+
+	w := MockAwsWorker(asgMock, iamMock, eksMock, ec2Mock, ssmMock)
+	ctx := MockContext(ig, k, w)
+	oldLabel := fmt.Sprintf(RoleOldLabel, ig.GetName())
+
+	tests := []struct {
+		clusterVersion string
+		cutoff         string
+		expectOldLabel bool
+	}{
+		// default cutoff is 1.16
+		{clusterVersion: "1.15", cutoff: "", expectOldLabel: true},
+		{clusterVersion: "1.16", cutoff: "", expectOldLabel: false},
+		// custom cutoff
+		{clusterVersion: "1.16", cutoff: "1.18", expectOldLabel: true},
+		{clusterVersion: "1.18", cutoff: "1.18", expectOldLabel: false},
+		{clusterVersion: "1.15", cutoff: "1.14", expectOldLabel: false},
+	}
+
+	for i, tc := range tests {
+		t.Logf("Test #%v - %+v", i, tc)
+		ctx.LegacyRoleLabelCutoff = tc.cutoff
+		ctx.SetDiscoveredState(&DiscoveredState{
+			Publisher: kubeprovider.EventPublisher{
+				Client: k.Kubernetes,
+			},
+			Cluster: MockEksCluster(tc.clusterVersion),
+		})
+		labels := ctx.GetComputedLabels()
+		g.Expect(labels).To(gomega.HaveKeyWithValue(RoleNewLabel, ig.GetName()))
+		if tc.expectOldLabel {
+			g.Expect(labels).To(gomega.HaveKey(oldLabel))
+		} else {
+			g.Expect(labels).NotTo(gomega.HaveKey(oldLabel))
+		}
+	}
+}
+
+func TestGetLabelListInstanceGroupLabel(t *testing.T) {
+	var (
+		g       = gomega.NewGomegaWithT(t)
+		k       = MockKubernetesClientSet()
+		ig      = MockInstanceGroup()
+		asgMock = NewAutoScalingMocker()
+		iamMock = NewIamMocker()
+		eksMock = NewEksMocker()
+		ec2Mock = NewEc2Mocker()
+		ssmMock = NewSsmMocker()
+	)
+
+	w := MockAwsWorker(asgMock, iamMock, eksMock, ec2Mock, ssmMock)
+	ctx := MockContext(ig, k, w)
+	ctx.SetDiscoveredState(&DiscoveredState{
+		Publisher: kubeprovider.EventPublisher{
+			Client: k.Kubernetes,
+		},
+		Cluster: MockEksCluster("1.20"),
+	})
+
+	label := fmt.Sprintf("%v=%v", InstanceGroupLabel, ig.GetName())
+
+	// label is opt-in
+	g.Expect(ctx.GetLabelList()).NotTo(gomega.ContainElement(label))
+
+	ig.SetAnnotations(map[string]string{InstanceGroupLabelAnnotation: "true"})
+	g.Expect(ctx.GetLabelList()).To(gomega.ContainElement(label))
+
+	// label is kept when the default labels are overridden
+	ig.SetAnnotations(map[string]string{
+		InstanceGroupLabelAnnotation:    "true",
+		OverrideDefaultLabelsAnnotation: "custom-role=worker",
+	})
+	g.Expect(ctx.GetLabelList()).To(gomega.ContainElement(label))
+	g.Expect(ctx.GetLabelList()).NotTo(gomega.ContainElement(fmt.Sprintf("%v=%v", RoleNewLabel, ig.GetName())))
+}
+
 func TestGetMountOpts(t *testing.T) {
 	var (
 		g             = gomega.NewGomegaWithT(t)
@@ -881,12 +1763,13 @@ func TestGetOverrides(t *testing.T) {
 		configuration.MixedInstancesPolicy = tc.mixedInstancesSpec
 		state.ScalingGroup = tc.scalingGroup
 		ig.Spec.EKSSpec.EKSConfiguration.InstanceType = tc.primaryType
-		overrides := ctx.GetOverrides()
+		overrides, err := ctx.GetOverrides("some-template")
+		g.Expect(err).NotTo(gomega.HaveOccurred())
 		g.Expect(overrides).To(gomega.ConsistOf(tc.expectedOverrides))
 	}
 }
 
-func TestGetUserDataStages(t *testing.T) {
+func TestGetOverridesWithImage(t *testing.T) {
 	var (
 		g             = gomega.NewGomegaWithT(t)
 		k             = MockKubernetesClientSet()
@@ -901,30 +1784,228 @@ func TestGetUserDataStages(t *testing.T) {
 
 	w := MockAwsWorker(asgMock, iamMock, eksMock, ec2Mock, ssmMock)
 	ctx := MockContext(ig, k, w)
+	state := ctx.GetDiscoveredState()
+	state.ScalingGroup = MockScalingGroup("asg-1", true)
 
-	tests := []struct {
-		preBootstrapScript  []string
-		postBootstrapScript []string
-		bootstrapScript     string
-		expectedPayload     UserDataPayload
-	}{
-		{},
-		{preBootstrapScript: []string{""}, postBootstrapScript: []string{""}, expectedPayload: UserDataPayload{PreBootstrap: []string{""}, PostBootstrap: []string{""}}},
-		{preBootstrapScript: []string{"dGVzdA=="}, postBootstrapScript: []string{"dGVzdDE="}, expectedPayload: UserDataPayload{PreBootstrap: []string{"test"}, PostBootstrap: []string{"test1"}}},
-		{preBootstrapScript: []string{"prebootstrap1"}, postBootstrapScript: []string{"postbootstrap"}, expectedPayload: UserDataPayload{PreBootstrap: []string{"prebootstrap1"}, PostBootstrap: []string{"postbootstrap"}}},
-		{preBootstrapScript: []string{"prebootstrap1", "prebootstrap2"}, postBootstrapScript: []string{"postbootstrap1", "postbootstrap2"}, expectedPayload: UserDataPayload{PreBootstrap: []string{"prebootstrap1", "prebootstrap2"}, PostBootstrap: []string{"postbootstrap1", "postbootstrap2"}}},
+	configuration.InstanceType = "m5.xlarge"
+	configuration.MixedInstancesPolicy = &v1alpha1.MixedInstancesPolicySpec{
+		InstanceTypes: []*v1alpha1.InstanceTypeSpec{
+			{
+				Type:   "m5a.xlarge",
+				Weight: 1,
+			},
+			{
+				Type:   "m6g.xlarge",
+				Weight: 1,
+				Image:  "ami-arm64",
+			},
+		},
 	}
 
-	for i, tc := range tests {
-		t.Logf("Test #%v - %+v", i, tc)
-		configuration.UserData = []v1alpha1.UserDataStage{}
-		for _, data := range tc.preBootstrapScript {
-			configuration.UserData = append(configuration.UserData, v1alpha1.UserDataStage{
-				Name:  fmt.Sprintf("stage-%v", i),
-				Stage: v1alpha1.PreBootstrapStage,
-				Data:  data,
-			})
-		}
+	overrides, err := ctx.GetOverrides("some-template")
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(overrides).To(gomega.ConsistOf(
+		&autoscaling.LaunchTemplateOverrides{
+			InstanceType:     aws.String("m5.xlarge"),
+			WeightedCapacity: aws.String("1"),
+		},
+		&autoscaling.LaunchTemplateOverrides{
+			InstanceType:     aws.String("m5a.xlarge"),
+			WeightedCapacity: aws.String("1"),
+		},
+		&autoscaling.LaunchTemplateOverrides{
+			InstanceType:     aws.String("m6g.xlarge"),
+			WeightedCapacity: aws.String("1"),
+			LaunchTemplateSpecification: &autoscaling.LaunchTemplateSpecification{
+				LaunchTemplateName: aws.String("some-template-m6g.xlarge"),
+				Version:            aws.String("$Latest"),
+			},
+		},
+	))
+	g.Expect(ctx.GetOverrideImages()).To(gomega.Equal(map[string]string{"m6g.xlarge": "ami-arm64"}))
+}
+
+func TestGetOverridesWeightedCapacity(t *testing.T) {
+	var (
+		g             = gomega.NewGomegaWithT(t)
+		k             = MockKubernetesClientSet()
+		ig            = MockInstanceGroup()
+		configuration = ig.GetEKSConfiguration()
+		asgMock       = NewAutoScalingMocker()
+		iamMock       = NewIamMocker()
+		eksMock       = NewEksMocker()
+		ec2Mock       = NewEc2Mocker()
+		ssmMock       = NewSsmMocker()
+	)
+
+	w := MockAwsWorker(asgMock, iamMock, eksMock, ec2Mock, ssmMock)
+	ctx := MockContext(ig, k, w)
+	state := ctx.GetDiscoveredState()
+	state.ScalingGroup = MockScalingGroup("asg-1", true)
+	state.InstanceTypeInfo = MockTypeInfo(
+		MockInstanceTypeInfo{InstanceType: "m5.xlarge", VCpus: 4, Arch: "x86_64"},
+		MockInstanceTypeInfo{InstanceType: "m5.2xlarge", VCpus: 8, Arch: "x86_64"},
+	)
+
+	configuration.InstanceType = "m5.2xlarge"
+	configuration.MixedInstancesPolicy = &v1alpha1.MixedInstancesPolicySpec{
+		InstanceTypes: []*v1alpha1.InstanceTypeSpec{
+			{
+				Type:   "m5.xlarge",
+				Weight: 1,
+			},
+		},
+		WeightedCapacityUnit: aws.String(v1alpha1.WeightedCapacityUnitVCPU),
+	}
+
+	// overrides are weighted by vCPU, including types which are still running in the group
+	overrides, err := ctx.GetOverrides("some-template")
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(overrides).To(gomega.ConsistOf(
+		&autoscaling.LaunchTemplateOverrides{
+			InstanceType:     aws.String("m5.2xlarge"),
+			WeightedCapacity: aws.String("8"),
+		},
+		&autoscaling.LaunchTemplateOverrides{
+			InstanceType:     aws.String("m5.xlarge"),
+			WeightedCapacity: aws.String("4"),
+		},
+	))
+
+	// desired capacity is interpreted in vCPUs, a 12 vCPU group with an 8 and a 4 vCPU instance is at capacity
+	state.ScalingGroup.DesiredCapacity = aws.Int64(12)
+	state.ScalingGroup.Instances = []*autoscaling.Instance{
+		{InstanceId: aws.String("i-0000000000"), InstanceType: aws.String("m5.2xlarge"), WeightedCapacity: aws.String("8")},
+		{InstanceId: aws.String("i-1111111111"), InstanceType: aws.String("m5.xlarge"), WeightedCapacity: aws.String("4")},
+	}
+	g.Expect(GetInstancesCapacity(state.ScalingGroup.Instances)).To(gomega.Equal(12))
+
+	// nodes are ready once every instance has a ready node, regardless of the desired capacity in vCPUs
+	state.Publisher = kubeprovider.EventPublisher{Client: k.Kubernetes}
+	state.ClusterNodes = &corev1.NodeList{
+		Items: []corev1.Node{
+			*MockNode("i-0000000000", corev1.ConditionTrue),
+			*MockNode("i-1111111111", corev1.ConditionTrue),
+		},
+	}
+	g.Expect(ctx.updateNodeReadyCondition()).To(gomega.BeTrue())
+
+	// manually weighted instances may also exceed the desired capacity
+	configuration.MixedInstancesPolicy.WeightedCapacityUnit = nil
+	configuration.MixedInstancesPolicy.InstanceTypes[0].Weight = 4
+	state.ScalingGroup.DesiredCapacity = aws.Int64(10)
+	g.Expect(ctx.updateNodeReadyCondition()).To(gomega.BeTrue())
+	configuration.MixedInstancesPolicy.InstanceTypes[0].Weight = 1
+	configuration.MixedInstancesPolicy.WeightedCapacityUnit = aws.String(v1alpha1.WeightedCapacityUnitVCPU)
+
+	// without weights instances are counted individually
+	g.Expect(GetInstancesCapacity(MockScalingInstances(1, 2))).To(gomega.Equal(3))
+
+	// types without instance type info cannot be weighted
+	state.InstanceTypeInfo = MockTypeInfo(
+		MockInstanceTypeInfo{InstanceType: "m5.2xlarge", VCpus: 8, Arch: "x86_64"},
+	)
+	_, err = ctx.GetOverrides("some-template")
+	g.Expect(err).To(gomega.HaveOccurred())
+}
+
+func TestGetBaseCapacityFromPercentage(t *testing.T) {
+	var (
+		g             = gomega.NewGomegaWithT(t)
+		k             = MockKubernetesClientSet()
+		ig            = MockInstanceGroup()
+		configuration = ig.GetEKSConfiguration()
+		asgMock       = NewAutoScalingMocker()
+		iamMock       = NewIamMocker()
+		eksMock       = NewEksMocker()
+		ec2Mock       = NewEc2Mocker()
+		ssmMock       = NewSsmMocker()
+	)
+
+	w := MockAwsWorker(asgMock, iamMock, eksMock, ec2Mock, ssmMock)
+	ctx := MockContext(ig, k, w)
+	state := ctx.GetDiscoveredState()
+
+	spotRatio := intstr.FromInt(50)
+	configuration.MixedInstancesPolicy = &v1alpha1.MixedInstancesPolicySpec{
+		SpotRatio: &spotRatio,
+		InstanceTypes: []*v1alpha1.InstanceTypeSpec{
+			{
+				Type:   "m5a.xlarge",
+				Weight: 1,
+			},
+		},
+	}
+
+	tests := []struct {
+		minSize         int64
+		desiredCapacity *int64
+		percentage      int
+		expectedBase    int64
+	}{
+		{minSize: 10, percentage: 20, expectedBase: 2},
+		{minSize: 3, percentage: 20, expectedBase: 1},
+		{minSize: 0, percentage: 50, expectedBase: 0},
+		{minSize: 2, desiredCapacity: aws.Int64(10), percentage: 25, expectedBase: 3},
+		{minSize: 5, desiredCapacity: aws.Int64(4), percentage: 100, expectedBase: 5},
+	}
+
+	for i, tc := range tests {
+		t.Logf("Test #%v - %+v", i, tc)
+		ig.Spec.EKSSpec.MinSize = tc.minSize
+		state.ScalingGroup = nil
+		if tc.desiredCapacity != nil {
+			state.ScalingGroup = MockScalingGroup("asg-1", true)
+			state.ScalingGroup.DesiredCapacity = tc.desiredCapacity
+		}
+		configuration.MixedInstancesPolicy.BaseCapacityPercentage = &tc.percentage
+		g.Expect(ctx.GetBaseCapacityFromPercentage(tc.percentage)).To(gomega.Equal(tc.expectedBase))
+
+		policy, err := ctx.GetDesiredMixedInstancesPolicy("some-template")
+		g.Expect(err).NotTo(gomega.HaveOccurred())
+		g.Expect(policy.InstancesDistribution.OnDemandBaseCapacity).To(gomega.Equal(aws.Int64(tc.expectedBase)))
+	}
+}
+
+func TestGetUserDataStages(t *testing.T) {
+	var (
+		g             = gomega.NewGomegaWithT(t)
+		k             = MockKubernetesClientSet()
+		ig            = MockInstanceGroup()
+		configuration = ig.GetEKSConfiguration()
+		asgMock       = NewAutoScalingMocker()
+		iamMock       = NewIamMocker()
+		eksMock       = NewEksMocker()
+		ec2Mock       = NewEc2Mocker()
+		ssmMock       = NewSsmMocker()
+	)
+
+	w := MockAwsWorker(asgMock, iamMock, eksMock, ec2Mock, ssmMock)
+	ctx := MockContext(ig, k, w)
+
+	tests := []struct {
+		preBootstrapScript  []string
+		postBootstrapScript []string
+		bootstrapScript     string
+		expectedPayload     UserDataPayload
+	}{
+		{},
+		{preBootstrapScript: []string{""}, postBootstrapScript: []string{""}, expectedPayload: UserDataPayload{PreBootstrap: []string{""}, PostBootstrap: []string{""}}},
+		{preBootstrapScript: []string{"dGVzdA=="}, postBootstrapScript: []string{"dGVzdDE="}, expectedPayload: UserDataPayload{PreBootstrap: []string{"test"}, PostBootstrap: []string{"test1"}}},
+		{preBootstrapScript: []string{"prebootstrap1"}, postBootstrapScript: []string{"postbootstrap"}, expectedPayload: UserDataPayload{PreBootstrap: []string{"prebootstrap1"}, PostBootstrap: []string{"postbootstrap"}}},
+		{preBootstrapScript: []string{"prebootstrap1", "prebootstrap2"}, postBootstrapScript: []string{"postbootstrap1", "postbootstrap2"}, expectedPayload: UserDataPayload{PreBootstrap: []string{"prebootstrap1", "prebootstrap2"}, PostBootstrap: []string{"postbootstrap1", "postbootstrap2"}}},
+	}
+
+	for i, tc := range tests {
+		t.Logf("Test #%v - %+v", i, tc)
+		configuration.UserData = []v1alpha1.UserDataStage{}
+		for _, data := range tc.preBootstrapScript {
+			configuration.UserData = append(configuration.UserData, v1alpha1.UserDataStage{
+				Name:  fmt.Sprintf("stage-%v", i),
+				Stage: v1alpha1.PreBootstrapStage,
+				Data:  data,
+			})
+		}
 
 		for _, data := range tc.postBootstrapScript {
 			configuration.UserData = append(configuration.UserData, v1alpha1.UserDataStage{
@@ -944,6 +2025,79 @@ func TestGetUserDataStages(t *testing.T) {
 	}
 }
 
+func TestGetKubeletExtraArgsStructuredReservations(t *testing.T) {
+	var (
+		g             = gomega.NewGomegaWithT(t)
+		k             = MockKubernetesClientSet()
+		ig            = MockInstanceGroup()
+		asgMock       = NewAutoScalingMocker()
+		iamMock       = NewIamMocker()
+		eksMock       = NewEksMocker()
+		ec2Mock       = NewEc2Mocker()
+		ssmMock       = NewSsmMocker()
+		configuration = ig.GetEKSConfiguration()
+	)
+
+	w := MockAwsWorker(asgMock, iamMock, eksMock, ec2Mock, ssmMock)
+	ctx := MockContext(ig, k, w)
+
+	configuration.BootstrapArguments = "--system-reserved=memory=2.5Gi --v=2 --eviction-hard memory.available<300Mi"
+	configuration.BootstrapOptions = &v1alpha1.BootstrapOptions{
+		SystemReserved: map[string]string{
+			"memory": "1Gi",
+			"cpu":    "100m",
+		},
+		KubeReserved: map[string]string{
+			"memory": "500Mi",
+		},
+		EvictionHard: map[string]string{
+			"nodefs.available": "10%",
+			"memory.available": "100Mi",
+		},
+	}
+
+	args := ctx.GetKubeletExtraArgs()
+	g.Expect(args).To(gomega.HaveSuffix("--v=2 --system-reserved=cpu=100m,memory=1Gi --kube-reserved=memory=500Mi --eviction-hard=memory.available<100Mi,nodefs.available<10%"))
+	g.Expect(strings.Count(args, "--system-reserved")).To(gomega.Equal(1))
+	g.Expect(strings.Count(args, "--eviction-hard")).To(gomega.Equal(1))
+
+	// raw arguments are left untouched when no structured options are set
+	configuration.BootstrapOptions = &v1alpha1.BootstrapOptions{}
+	args = ctx.GetKubeletExtraArgs()
+	g.Expect(args).To(gomega.HaveSuffix("--system-reserved=memory=2.5Gi --v=2 --eviction-hard memory.available<300Mi"))
+}
+
+func TestGetUserDataStagesPriority(t *testing.T) {
+	var (
+		g             = gomega.NewGomegaWithT(t)
+		k             = MockKubernetesClientSet()
+		ig            = MockInstanceGroup()
+		configuration = ig.GetEKSConfiguration()
+		asgMock       = NewAutoScalingMocker()
+		iamMock       = NewIamMocker()
+		eksMock       = NewEksMocker()
+		ec2Mock       = NewEc2Mocker()
+		ssmMock       = NewSsmMocker()
+	)
+
+	w := MockAwsWorker(asgMock, iamMock, eksMock, ec2Mock, ssmMock)
+	ctx := MockContext(ig, k, w)
+
+	configuration.UserData = []v1alpha1.UserDataStage{
+		{Name: "pre-late", Stage: v1alpha1.PreBootstrapStage, Data: "pre-late", Priority: 10},
+		{Name: "post-default", Stage: v1alpha1.PostBootstrapStage, Data: "post-default"},
+		{Name: "pre-default-1", Stage: v1alpha1.PreBootstrapStage, Data: "pre-default-1"},
+		{Name: "pre-early", Stage: v1alpha1.PreBootstrapStage, Data: "pre-early", Priority: -5},
+		{Name: "post-early", Stage: v1alpha1.PostBootstrapStage, Data: "post-early", Priority: -1},
+		{Name: "pre-default-2", Stage: v1alpha1.PreBootstrapStage, Data: "pre-default-2"},
+	}
+
+	payload := ctx.GetUserDataStages()
+	g.Expect(payload.PreBootstrap).To(gomega.Equal([]string{"pre-early", "pre-default-1", "pre-default-2", "pre-late"}))
+	g.Expect(payload.PostBootstrap).To(gomega.Equal([]string{"post-early", "post-default"}))
+	g.Expect(configuration.UserData[0].Name).To(gomega.Equal("pre-late"))
+}
+
 func TestMaxPodsSetCorrectly(t *testing.T) {
 	var (
 		k                         = MockKubernetesClientSet()
@@ -1003,6 +2157,48 @@ func TestMaxPodsSetCorrectly(t *testing.T) {
 	}
 }
 
+func TestMaxPodsCalculationDisabled(t *testing.T) {
+	var (
+		g       = gomega.NewGomegaWithT(t)
+		k       = MockKubernetesClientSet()
+		asgMock = NewAutoScalingMocker()
+		iamMock = NewIamMocker()
+		eksMock = NewEksMocker()
+		ec2Mock = NewEc2Mocker()
+		ssmMock = NewSsmMocker()
+	)
+
+	w := MockAwsWorker(asgMock, iamMock, eksMock, ec2Mock, ssmMock)
+
+	disabled := map[string]string{
+		MaxPodsCalculationAnnotation:      MaxPodsCalculationDisabled,
+		CustomNetworkingEnabledAnnotation: "true",
+	}
+
+	ig := MockInstanceGroup()
+	ig.SetAnnotations(disabled)
+	ig.GetEKSConfiguration().BootstrapOptions = &v1alpha1.BootstrapOptions{
+		MaxPods: 15,
+	}
+	ctx := MockContext(ig, k, w)
+
+	g.Expect(ctx.GetComputedBootstrapOptions().MaxPods).To(gomega.BeZero())
+	g.Expect(ig.GetEKSConfiguration().BootstrapOptions.MaxPods).To(gomega.Equal(int64(15)))
+	g.Expect(ctx.GetKubeletExtraArgs()).NotTo(gomega.ContainSubstring("--max-pods"))
+	g.Expect(ctx.GetBootstrapArgs()).To(gomega.HavePrefix("--use-max-pods false "))
+
+	bottleRocketIg := MockBottleRocketInstanceGroup()
+	bottleRocketIg.GetAnnotations()[MaxPodsCalculationAnnotation] = MaxPodsCalculationDisabled
+	bottleRocketIg.GetEKSConfiguration().BootstrapOptions = &v1alpha1.BootstrapOptions{
+		MaxPods: 15,
+	}
+	ctx = MockContext(bottleRocketIg, k, w)
+
+	userData, err := base64.StdEncoding.DecodeString(ctx.GetBasicUserData("", ctx.GetBootstrapArgs(), "", UserDataPayload{}, []MountOpts{}))
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(string(userData)).NotTo(gomega.ContainSubstring("max-pods"))
+}
+
 func TestBootstrapDataForOSFamily(t *testing.T) {
 	var (
 		k              = MockKubernetesClientSet()
@@ -1128,78 +2324,324 @@ func TestUpdateLifecycleHooks(t *testing.T) {
 	}
 }
 
-func TestUpdateWarmPool(t *testing.T) {
+func TestUpdateLifecycleHooksInPlace(t *testing.T) {
 	var (
-		g       = gomega.NewGomegaWithT(t)
-		k       = MockKubernetesClientSet()
-		ig      = MockInstanceGroup()
-		asgMock = NewAutoScalingMocker()
-		iamMock = NewIamMocker()
-		eksMock = NewEksMocker()
-		ec2Mock = NewEc2Mocker()
-		ssmMock = NewSsmMocker()
+		g             = gomega.NewGomegaWithT(t)
+		k             = MockKubernetesClientSet()
+		ig            = MockInstanceGroup()
+		configuration = ig.GetEKSConfiguration()
+		asgMock       = NewAutoScalingMocker()
+		iamMock       = NewIamMocker()
+		eksMock       = NewEksMocker()
+		ec2Mock       = NewEc2Mocker()
+		ssmMock       = NewSsmMocker()
 	)
 
 	w := MockAwsWorker(asgMock, iamMock, eksMock, ec2Mock, ssmMock)
 	ctx := MockContext(ig, k, w)
 
-	tests := []struct {
-		warmPoolSpec          *v1alpha1.WarmPoolSpec
-		warmPoolConfiguration *autoscaling.WarmPoolConfiguration
-		shouldDelete          bool
-		shouldUpdate          bool
-		shouldRequeue         bool
-	}{
-		// no update/delete needed
-		{warmPoolConfiguration: nil, warmPoolSpec: nil},
-		// enable warm pool - update
-		{warmPoolConfiguration: nil, warmPoolSpec: MockWarmPoolSpec(-1, 0), shouldUpdate: true},
-		// disable warm pool - delete
-		{warmPoolConfiguration: MockWarmPool(-1, 0, ""), warmPoolSpec: nil, shouldDelete: true},
-		// scale change (min)
-		{warmPoolConfiguration: MockWarmPool(-1, 1, ""), warmPoolSpec: MockWarmPoolSpec(-1, 0), shouldUpdate: true},
-		{warmPoolConfiguration: MockWarmPool(-1, 0, ""), warmPoolSpec: MockWarmPoolSpec(-1, 1), shouldUpdate: true},
-		// scale change (max)
-		{warmPoolConfiguration: MockWarmPool(3, 0, ""), warmPoolSpec: MockWarmPoolSpec(-1, 0), shouldUpdate: true},
-		{warmPoolConfiguration: MockWarmPool(-1, 0, ""), warmPoolSpec: MockWarmPoolSpec(3, 0), shouldUpdate: true},
-		// deleting - should requeue
-		{warmPoolConfiguration: MockWarmPool(-1, 0, autoscaling.WarmPoolStatusPendingDelete), warmPoolSpec: nil, shouldRequeue: true},
+	ctx.SetDiscoveredState(&DiscoveredState{
+		Publisher: kubeprovider.EventPublisher{
+			Client: k.Kubernetes,
+		},
+		LifecycleHooks: []*autoscaling.LifecycleHook{
+			{
+				LifecycleHookName:   aws.String("my-hook"),
+				LifecycleTransition: aws.String("autoscaling:EC2_INSTANCE_TERMINATING"),
+				DefaultResult:       aws.String("CONTINUE"),
+				HeartbeatTimeout:    aws.Int64(300),
+			},
+		},
+	})
+
+	hook := v1alpha1.LifecycleHookSpec{
+		Name:             "my-hook",
+		Lifecycle:        "autoscaling:EC2_INSTANCE_TERMINATING",
+		DefaultResult:    "CONTINUE",
+		HeartbeatTimeout: 300,
 	}
 
-	for i, tc := range tests {
-		t.Logf("test #%v", i)
-		asgMock.DeleteWarmPoolCallCount = 0
-		asgMock.PutWarmPoolCallCount = 0
-		ig.Spec.EKSSpec.WarmPool = tc.warmPoolSpec
-		scalingGroup := MockScalingGroup("my-asg", false)
-		scalingGroup.WarmPoolConfiguration = tc.warmPoolConfiguration
+	// unchanged hooks are not updated
+	configuration.SetLifecycleHooks([]v1alpha1.LifecycleHookSpec{hook})
+	_, ok := ctx.GetUpdatedHooks()
+	g.Expect(ok).To(gomega.BeFalse())
 
-		ctx.SetDiscoveredState(&DiscoveredState{
-			Publisher: kubeprovider.EventPublisher{
-				Client: k.Kubernetes,
-			},
-			ScalingGroup: scalingGroup,
-		})
+	// a changed heartbeat timeout updates the existing hook without deleting it
+	hook.HeartbeatTimeout = 600
+	configuration.SetLifecycleHooks([]v1alpha1.LifecycleHookSpec{hook})
 
-		err := ctx.UpdateWarmPool("my-asg")
-		g.Expect(err).NotTo(gomega.HaveOccurred())
-		if tc.shouldDelete {
-			g.Expect(asgMock.DeleteWarmPoolCallCount).To(gomega.Equal(uint(1)))
-		}
-		if tc.shouldUpdate {
-			g.Expect(asgMock.PutWarmPoolCallCount).To(gomega.Equal(uint(1)))
-		}
-		if tc.shouldRequeue {
-			g.Expect(asgMock.DeleteWarmPoolCallCount).To(gomega.Equal(uint(0)))
-			g.Expect(asgMock.PutWarmPoolCallCount).To(gomega.Equal(uint(0)))
-		}
-	}
+	removed, ok := ctx.GetRemovedHooks()
+	g.Expect(ok).To(gomega.BeFalse())
+	g.Expect(removed).To(gomega.BeEmpty())
+
+	added, ok := ctx.GetAddedHooks()
+	g.Expect(ok).To(gomega.BeFalse())
+	g.Expect(added).To(gomega.BeEmpty())
+
+	updated, ok := ctx.GetUpdatedHooks()
+	g.Expect(ok).To(gomega.BeTrue())
+	g.Expect(updated).To(gomega.Equal([]v1alpha1.LifecycleHookSpec{hook}))
+
+	err := ctx.UpdateLifecycleHooks("my-asg")
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(asgMock.PutLifecycleHookCallCount).To(gomega.Equal(uint(1)))
+	g.Expect(asgMock.DeleteLifecycleHookCallCount).To(gomega.BeZero())
 }
 
-func TestFilterSupportedArch(t *testing.T) {
+func TestUpdateSourceDestCheck(t *testing.T) {
 	var (
-		g = gomega.NewGomegaWithT(t)
-	)
+		g             = gomega.NewGomegaWithT(t)
+		k             = MockKubernetesClientSet()
+		ig            = MockInstanceGroup()
+		configuration = ig.GetEKSConfiguration()
+		asgMock       = NewAutoScalingMocker()
+		iamMock       = NewIamMocker()
+		eksMock       = NewEksMocker()
+		ec2Mock       = NewEc2Mocker()
+		ssmMock       = NewSsmMocker()
+	)
+
+	w := MockAwsWorker(asgMock, iamMock, eksMock, ec2Mock, ssmMock)
+	ctx := MockContext(ig, k, w)
+	state := ctx.GetDiscoveredState()
+
+	state.ScalingGroup = MockScalingGroup("asg-1", false)
+	state.ScalingGroup.Instances = MockScalingInstances(0, 2)
+	ec2Mock.Instances = []*ec2.Instance{
+		{InstanceId: aws.String("i-100000000"), SourceDestCheck: aws.Bool(true)},
+		{InstanceId: aws.String("i-100000001"), SourceDestCheck: aws.Bool(false)},
+	}
+
+	// attribute is left untouched when not configured
+	err := ctx.UpdateSourceDestCheck()
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(ec2Mock.ModifyInstanceAttributeCallCount).To(gomega.Equal(uint(0)))
+
+	configuration.SourceDestCheck = aws.Bool(false)
+	err = ctx.UpdateSourceDestCheck()
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(ec2Mock.ModifyInstanceAttributeCallCount).To(gomega.Equal(uint(1)))
+	for _, instance := range ec2Mock.Instances {
+		g.Expect(aws.BoolValue(instance.SourceDestCheck)).To(gomega.BeFalse())
+	}
+
+	// instances which already match are not modified again
+	err = ctx.UpdateSourceDestCheck()
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(ec2Mock.ModifyInstanceAttributeCallCount).To(gomega.Equal(uint(1)))
+}
+
+func TestUpdateLoadBalancers(t *testing.T) {
+	var (
+		g             = gomega.NewGomegaWithT(t)
+		k             = MockKubernetesClientSet()
+		ig            = MockInstanceGroup()
+		configuration = ig.GetEKSConfiguration()
+		asgMock       = NewAutoScalingMocker()
+		iamMock       = NewIamMocker()
+		eksMock       = NewEksMocker()
+		ec2Mock       = NewEc2Mocker()
+		ssmMock       = NewSsmMocker()
+	)
+
+	w := MockAwsWorker(asgMock, iamMock, eksMock, ec2Mock, ssmMock)
+	ctx := MockContext(ig, k, w)
+
+	tg1 := "arn:aws:elasticloadbalancing:us-west-2:123456789012:targetgroup/tg-1/1234567890123456"
+	tg2 := "arn:aws:elasticloadbalancing:us-west-2:123456789012:targetgroup/tg-2/1234567890123456"
+	tg3 := "arn:aws:elasticloadbalancing:us-west-2:123456789012:targetgroup/tg-3/1234567890123456"
+
+	tests := []struct {
+		asgTargetGroups       []string
+		attachedTargetGroups  []string
+		desiredTargetGroups   []string
+		asgLoadBalancers      []string
+		attachedLoadBalancers []string
+		desiredLoadBalancers  []string
+		expectedAttached      []string
+		expectedDetached      []string
+		expectedAttachedLBs   []string
+		expectedDetachedLBs   []string
+	}{
+		{},
+		{asgTargetGroups: []string{tg1, tg2}, attachedTargetGroups: []string{tg1, tg2}, desiredTargetGroups: []string{tg1, tg2}},
+		{asgTargetGroups: []string{tg1}, attachedTargetGroups: []string{tg1}, desiredTargetGroups: []string{tg1, tg2, tg3}, expectedAttached: []string{tg2, tg3}},
+		{asgTargetGroups: []string{tg1, tg2}, attachedTargetGroups: []string{tg1, tg2}, desiredTargetGroups: []string{tg1}, expectedDetached: []string{tg2}},
+		{asgTargetGroups: []string{tg1, tg2}, attachedTargetGroups: []string{tg1, tg2}, desiredTargetGroups: []string{tg3}, expectedAttached: []string{tg3}, expectedDetached: []string{tg1, tg2}},
+		// target groups and load balancers attached outside of the controller are not detached
+		{asgTargetGroups: []string{tg1, tg2}, attachedTargetGroups: []string{tg1}, expectedDetached: []string{tg1}},
+		{asgLoadBalancers: []string{"lb-1", "lb-3"}, attachedLoadBalancers: []string{"lb-1"}, desiredLoadBalancers: []string{"lb-2"}, expectedAttachedLBs: []string{"lb-2"}, expectedDetachedLBs: []string{"lb-1"}},
+	}
+
+	for i, tc := range tests {
+		t.Logf("Test #%v - %+v", i, tc)
+		asgMock.AttachLoadBalancerTargetGroupsInput = nil
+		asgMock.DetachLoadBalancerTargetGroupsInput = nil
+		asgMock.AttachLoadBalancersInput = nil
+		asgMock.DetachLoadBalancersInput = nil
+
+		scalingGroup := MockScalingGroup("my-asg", true)
+		scalingGroup.TargetGroupARNs = aws.StringSlice(tc.asgTargetGroups)
+		scalingGroup.LoadBalancerNames = aws.StringSlice(tc.asgLoadBalancers)
+		ctx.GetDiscoveredState().SetScalingGroup(scalingGroup)
+		configuration.SetTargetGroupARNs(tc.desiredTargetGroups)
+		configuration.SetLoadBalancerNames(tc.desiredLoadBalancers)
+		ig.GetStatus().SetAttachedTargetGroupARNs(tc.attachedTargetGroups)
+		ig.GetStatus().SetAttachedLoadBalancerNames(tc.attachedLoadBalancers)
+
+		err := ctx.UpdateLoadBalancers("my-asg")
+		g.Expect(err).NotTo(gomega.HaveOccurred())
+		g.Expect(ig.GetStatus().GetAttachedTargetGroupARNs()).To(gomega.Equal(tc.desiredTargetGroups))
+		g.Expect(ig.GetStatus().GetAttachedLoadBalancerNames()).To(gomega.Equal(tc.desiredLoadBalancers))
+
+		if len(tc.expectedAttached) > 0 {
+			g.Expect(aws.StringValueSlice(asgMock.AttachLoadBalancerTargetGroupsInput.TargetGroupARNs)).To(gomega.Equal(tc.expectedAttached))
+		} else {
+			g.Expect(asgMock.AttachLoadBalancerTargetGroupsInput).To(gomega.BeNil())
+		}
+
+		if len(tc.expectedDetached) > 0 {
+			g.Expect(aws.StringValueSlice(asgMock.DetachLoadBalancerTargetGroupsInput.TargetGroupARNs)).To(gomega.Equal(tc.expectedDetached))
+		} else {
+			g.Expect(asgMock.DetachLoadBalancerTargetGroupsInput).To(gomega.BeNil())
+		}
+
+		if len(tc.expectedAttachedLBs) > 0 {
+			g.Expect(aws.StringValueSlice(asgMock.AttachLoadBalancersInput.LoadBalancerNames)).To(gomega.Equal(tc.expectedAttachedLBs))
+		} else {
+			g.Expect(asgMock.AttachLoadBalancersInput).To(gomega.BeNil())
+		}
+
+		if len(tc.expectedDetachedLBs) > 0 {
+			g.Expect(aws.StringValueSlice(asgMock.DetachLoadBalancersInput.LoadBalancerNames)).To(gomega.Equal(tc.expectedDetachedLBs))
+		} else {
+			g.Expect(asgMock.DetachLoadBalancersInput).To(gomega.BeNil())
+		}
+	}
+}
+
+func TestSeedAttachedLoadBalancers(t *testing.T) {
+	var (
+		g             = gomega.NewGomegaWithT(t)
+		k             = MockKubernetesClientSet()
+		ig            = MockInstanceGroup()
+		status        = ig.GetStatus()
+		configuration = ig.GetEKSConfiguration()
+		asgMock       = NewAutoScalingMocker()
+		iamMock       = NewIamMocker()
+		eksMock       = NewEksMocker()
+		ec2Mock       = NewEc2Mocker()
+		ssmMock       = NewSsmMocker()
+	)
+
+	w := MockAwsWorker(asgMock, iamMock, eksMock, ec2Mock, ssmMock)
+	ctx := MockContext(ig, k, w)
+
+	tg1 := "arn:aws:elasticloadbalancing:us-west-2:123456789012:targetgroup/tg-1/1234567890123456"
+	tg2 := "arn:aws:elasticloadbalancing:us-west-2:123456789012:targetgroup/tg-2/1234567890123456"
+	tg3 := "arn:aws:elasticloadbalancing:us-west-2:123456789012:targetgroup/tg-3/1234567890123456"
+
+	scalingGroup := MockScalingGroup("my-asg", true)
+	scalingGroup.TargetGroupARNs = aws.StringSlice([]string{tg1, tg2})
+	scalingGroup.LoadBalancerNames = aws.StringSlice([]string{"lb-1", "lb-2"})
+	ctx.GetDiscoveredState().SetScalingGroup(scalingGroup)
+	configuration.SetTargetGroupARNs([]string{tg1, tg3})
+	configuration.SetLoadBalancerNames([]string{"lb-1"})
+
+	// spec values which are already attached are recorded on the first reconcile
+	ctx.SeedAttachedLoadBalancers()
+	g.Expect(status.GetAttachedTargetGroupARNs()).To(gomega.Equal([]string{tg1}))
+	g.Expect(status.GetAttachedLoadBalancerNames()).To(gomega.Equal([]string{"lb-1"}))
+
+	// once recorded, the status is left as is
+	status.SetAttachedTargetGroupARNs([]string{tg2})
+	status.SetAttachedLoadBalancerNames([]string{"lb-2"})
+	ctx.SeedAttachedLoadBalancers()
+	g.Expect(status.GetAttachedTargetGroupARNs()).To(gomega.Equal([]string{tg2}))
+	g.Expect(status.GetAttachedLoadBalancerNames()).To(gomega.Equal([]string{"lb-2"}))
+
+	// removing a seeded target group from the spec detaches it
+	status.SetAttachedTargetGroupARNs(nil)
+	status.SetAttachedLoadBalancerNames(nil)
+	ctx.SeedAttachedLoadBalancers()
+	configuration.SetTargetGroupARNs([]string{tg3})
+	removed, ok := ctx.GetRemovedTargetGroups()
+	g.Expect(ok).To(gomega.BeTrue())
+	g.Expect(removed).To(gomega.Equal([]string{tg1}))
+}
+
+func TestUpdateWarmPool(t *testing.T) {
+	var (
+		g       = gomega.NewGomegaWithT(t)
+		k       = MockKubernetesClientSet()
+		ig      = MockInstanceGroup()
+		asgMock = NewAutoScalingMocker()
+		iamMock = NewIamMocker()
+		eksMock = NewEksMocker()
+		ec2Mock = NewEc2Mocker()
+		ssmMock = NewSsmMocker()
+	)
+
+	w := MockAwsWorker(asgMock, iamMock, eksMock, ec2Mock, ssmMock)
+	ctx := MockContext(ig, k, w)
+
+	tests := []struct {
+		warmPoolSpec          *v1alpha1.WarmPoolSpec
+		warmPoolConfiguration *autoscaling.WarmPoolConfiguration
+		shouldDelete          bool
+		shouldUpdate          bool
+		shouldRequeue         bool
+	}{
+		// no update/delete needed
+		{warmPoolConfiguration: nil, warmPoolSpec: nil},
+		// enable warm pool - update
+		{warmPoolConfiguration: nil, warmPoolSpec: MockWarmPoolSpec(-1, 0), shouldUpdate: true},
+		// disable warm pool - delete
+		{warmPoolConfiguration: MockWarmPool(-1, 0, ""), warmPoolSpec: nil, shouldDelete: true},
+		// scale change (min)
+		{warmPoolConfiguration: MockWarmPool(-1, 1, ""), warmPoolSpec: MockWarmPoolSpec(-1, 0), shouldUpdate: true},
+		{warmPoolConfiguration: MockWarmPool(-1, 0, ""), warmPoolSpec: MockWarmPoolSpec(-1, 1), shouldUpdate: true},
+		// scale change (max)
+		{warmPoolConfiguration: MockWarmPool(3, 0, ""), warmPoolSpec: MockWarmPoolSpec(-1, 0), shouldUpdate: true},
+		{warmPoolConfiguration: MockWarmPool(-1, 0, ""), warmPoolSpec: MockWarmPoolSpec(3, 0), shouldUpdate: true},
+		// deleting - should requeue
+		{warmPoolConfiguration: MockWarmPool(-1, 0, autoscaling.WarmPoolStatusPendingDelete), warmPoolSpec: nil, shouldRequeue: true},
+	}
+
+	for i, tc := range tests {
+		t.Logf("test #%v", i)
+		asgMock.DeleteWarmPoolCallCount = 0
+		asgMock.PutWarmPoolCallCount = 0
+		ig.Spec.EKSSpec.WarmPool = tc.warmPoolSpec
+		scalingGroup := MockScalingGroup("my-asg", false)
+		scalingGroup.WarmPoolConfiguration = tc.warmPoolConfiguration
+
+		ctx.SetDiscoveredState(&DiscoveredState{
+			Publisher: kubeprovider.EventPublisher{
+				Client: k.Kubernetes,
+			},
+			ScalingGroup: scalingGroup,
+		})
+
+		err := ctx.UpdateWarmPool("my-asg")
+		g.Expect(err).NotTo(gomega.HaveOccurred())
+		if tc.shouldDelete {
+			g.Expect(asgMock.DeleteWarmPoolCallCount).To(gomega.Equal(uint(1)))
+		}
+		if tc.shouldUpdate {
+			g.Expect(asgMock.PutWarmPoolCallCount).To(gomega.Equal(uint(1)))
+		}
+		if tc.shouldRequeue {
+			g.Expect(asgMock.DeleteWarmPoolCallCount).To(gomega.Equal(uint(0)))
+			g.Expect(asgMock.PutWarmPoolCallCount).To(gomega.Equal(uint(0)))
+		}
+	}
+}
+
+func TestFilterSupportedArch(t *testing.T) {
+	var (
+		g = gomega.NewGomegaWithT(t)
+	)
 
 	tests := []struct {
 		name          string
@@ -1294,3 +2736,688 @@ func TestGetEksLatestAmi(t *testing.T) {
 
 	}
 }
+
+func TestGetManagedInstanceProfileName(t *testing.T) {
+	var (
+		g       = gomega.NewGomegaWithT(t)
+		k       = MockKubernetesClientSet()
+		ig      = MockInstanceGroup()
+		asgMock = NewAutoScalingMocker()
+		iamMock = NewIamMocker()
+		eksMock = NewEksMocker()
+		ec2Mock = NewEc2Mocker()
+		ssmMock = NewSsmMocker()
+	)
+
+	w := MockAwsWorker(asgMock, iamMock, eksMock, ec2Mock, ssmMock)
+	ctx := MockContext(ig, k, w)
+	configuration := ig.GetEKSConfiguration()
+
+	// without a prefix the instance-profile name matches the role name
+	g.Expect(ctx.GetManagedRoleName()).To(gomega.Equal("my-cluster-instance-manager-instance-group-1"))
+	g.Expect(ctx.GetManagedInstanceProfileName()).To(gomega.Equal(ctx.GetManagedRoleName()))
+
+	configuration.SetInstanceProfileNamePrefix("corp-")
+	g.Expect(ctx.GetManagedInstanceProfileName()).To(gomega.Equal("corp-my-cluster-instance-manager-instance-group-1"))
+
+	// long resource names are hashed
+	ctx.ResourcePrefix = strings.Repeat("a", 70)
+	g.Expect(ctx.GetManagedRoleName()).To(gomega.HaveLen(32))
+	g.Expect(ctx.GetManagedInstanceProfileName()).To(gomega.Equal(fmt.Sprintf("corp-%v", ctx.GetManagedRoleName())))
+
+	// generated names of valid instance groups are truncated to the IAM limit with a hashed suffix
+	configuration.SetInstanceProfileNamePrefix("")
+	configuration.SetSubnets([]string{"subnet-1"})
+	configuration.NodeSecurityGroups = []string{"sg-1"}
+	configuration.KeyPairName = "keypair"
+	err := ig.Validate(v1alpha1.NewValidationOverrides(nil, nil, strings.Repeat("p", 120)))
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	name := ctx.GetManagedInstanceProfileName()
+	g.Expect(name).To(gomega.HaveLen(awsprovider.MaxInstanceProfileNameLength))
+	g.Expect(strings.HasPrefix(name, strings.Repeat("p", 95))).To(gomega.BeTrue())
+
+	// prefixes which only differ past the limit do not collide
+	configuration.SetInstanceProfileNamePrefix(strings.Repeat("p", 119) + "q")
+	g.Expect(ctx.GetManagedInstanceProfileName()).To(gomega.HaveLen(awsprovider.MaxInstanceProfileNameLength))
+	g.Expect(ctx.GetManagedInstanceProfileName()).NotTo(gomega.Equal(name))
+}
+
+func TestCreateManagedRoleRetiresInstanceProfile(t *testing.T) {
+	var (
+		g       = gomega.NewGomegaWithT(t)
+		k       = MockKubernetesClientSet()
+		ig      = MockInstanceGroup()
+		asgMock = NewAutoScalingMocker()
+		iamMock = NewIamMocker()
+		eksMock = NewEksMocker()
+		ec2Mock = NewEc2Mocker()
+		ssmMock = NewSsmMocker()
+	)
+
+	w := MockAwsWorker(asgMock, iamMock, eksMock, ec2Mock, ssmMock)
+	ctx := MockContext(ig, k, w)
+	status := ig.GetStatus()
+	configuration := ig.GetEKSConfiguration()
+
+	// the created instance-profile is recorded
+	err := ctx.CreateManagedRole()
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(status.GetInstanceProfileName()).To(gomega.Equal(ctx.GetManagedRoleName()))
+	g.Expect(status.GetRetiredInstanceProfileNames()).To(gomega.BeEmpty())
+
+	// a prefix change retires the previous instance-profile
+	configuration.SetInstanceProfileNamePrefix("corp-")
+	err = ctx.CreateManagedRole()
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(status.GetInstanceProfileName()).To(gomega.Equal("corp-" + ctx.GetManagedRoleName()))
+	g.Expect(status.GetRetiredInstanceProfileNames()).To(gomega.ConsistOf(ctx.GetManagedRoleName()))
+
+	// retired instance-profiles are deleted
+	err = ctx.DeleteRetiredInstanceProfiles()
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(status.GetRetiredInstanceProfileNames()).To(gomega.BeEmpty())
+	g.Expect(status.GetInstanceProfileName()).To(gomega.Equal("corp-" + ctx.GetManagedRoleName()))
+}
+
+func TestUpdateNodeReadyConditionDaemonSets(t *testing.T) {
+	var (
+		g       = gomega.NewGomegaWithT(t)
+		k       = MockKubernetesClientSet()
+		ig      = MockInstanceGroup()
+		asgMock = NewAutoScalingMocker()
+		iamMock = NewIamMocker()
+		eksMock = NewEksMocker()
+		ec2Mock = NewEc2Mocker()
+		ssmMock = NewSsmMocker()
+	)
+
+	w := MockAwsWorker(asgMock, iamMock, eksMock, ec2Mock, ssmMock)
+	ctx := MockContext(ig, k, w)
+	state := ctx.GetDiscoveredState()
+	state.Publisher = kubeprovider.EventPublisher{
+		Client: k.Kubernetes,
+	}
+
+	mockPod := func(daemonSet, nodeName string, phase corev1.PodPhase) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      fmt.Sprintf("%v-%v", daemonSet, nodeName),
+				Namespace: DaemonSetReadinessNamespace,
+				OwnerReferences: []metav1.OwnerReference{
+					{
+						Kind: "DaemonSet",
+						Name: daemonSet,
+					},
+				},
+			},
+			Spec: corev1.PodSpec{
+				NodeName: nodeName,
+			},
+			Status: corev1.PodStatus{
+				Phase: phase,
+			},
+		}
+	}
+
+	scalingGroup := MockScalingGroup("asg-1", true)
+	scalingGroup.Instances = MockScalingInstances(2, 0)
+	scalingGroup.DesiredCapacity = aws.Int64(2)
+	state.SetScalingGroup(scalingGroup)
+
+	nodes := &corev1.NodeList{}
+	for _, instance := range scalingGroup.Instances {
+		id := aws.StringValue(instance.InstanceId)
+		nodes.Items = append(nodes.Items, *MockNode(id, corev1.ConditionTrue))
+	}
+	state.SetClusterNodes(nodes)
+
+	pods := []*corev1.Pod{
+		mockPod("kube-proxy", "node-i-000000000", corev1.PodRunning),
+		mockPod("kube-proxy", "node-i-000000001", corev1.PodRunning),
+		mockPod("aws-node", "node-i-000000000", corev1.PodRunning),
+		mockPod("aws-node", "node-i-000000001", corev1.PodPending),
+	}
+	for _, pod := range pods {
+		_, err := k.Kubernetes.CoreV1().Pods(DaemonSetReadinessNamespace).Create(context.Background(), pod, metav1.CreateOptions{})
+		g.Expect(err).NotTo(gomega.HaveOccurred())
+	}
+
+	// without the annotation only node conditions are considered
+	g.Expect(ctx.UpdateNodeReadyCondition()).To(gomega.BeTrue())
+	g.Expect(state.IsNodesReady()).To(gomega.BeTrue())
+
+	// aws-node is pending on one of the nodes
+	ig.Annotations[DaemonSetReadinessAnnotation] = "true"
+	g.Expect(ctx.UpdateNodeReadyCondition()).To(gomega.BeFalse())
+	g.Expect(state.IsNodesReady()).To(gomega.BeFalse())
+	g.Expect(ig.GetStatus().GetConditions()).To(gomega.ContainElement(v1alpha1.NewInstanceGroupCondition(v1alpha1.NodesReady, corev1.ConditionFalse)))
+
+	// only kube-proxy is required
+	ig.Annotations[DaemonSetReadinessNamesAnnotation] = "kube-proxy"
+	g.Expect(ctx.UpdateNodeReadyCondition()).To(gomega.BeTrue())
+	g.Expect(state.IsNodesReady()).To(gomega.BeTrue())
+
+	// aws-node becomes running
+	delete(ig.Annotations, DaemonSetReadinessNamesAnnotation)
+	_, err := k.Kubernetes.CoreV1().Pods(DaemonSetReadinessNamespace).Update(context.Background(), mockPod("aws-node", "node-i-000000001", corev1.PodRunning), metav1.UpdateOptions{})
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(ctx.UpdateNodeReadyCondition()).To(gomega.BeTrue())
+	g.Expect(state.IsNodesReady()).To(gomega.BeTrue())
+}
+
+func TestUpdateNodeReadyConditionTimeout(t *testing.T) {
+	var (
+		g       = gomega.NewGomegaWithT(t)
+		k       = MockKubernetesClientSet()
+		ig      = MockInstanceGroup()
+		status  = ig.GetStatus()
+		asgMock = NewAutoScalingMocker()
+		iamMock = NewIamMocker()
+		eksMock = NewEksMocker()
+		ec2Mock = NewEc2Mocker()
+		ssmMock = NewSsmMocker()
+	)
+
+	w := MockAwsWorker(asgMock, iamMock, eksMock, ec2Mock, ssmMock)
+	ctx := MockContext(ig, k, w)
+	state := ctx.GetDiscoveredState()
+	state.Publisher = kubeprovider.EventPublisher{
+		Client: k.Kubernetes,
+	}
+
+	scalingGroup := MockScalingGroup("asg-1", true)
+	scalingGroup.Instances = MockScalingInstances(1, 0)
+	scalingGroup.DesiredCapacity = aws.Int64(1)
+	state.SetScalingGroup(scalingGroup)
+
+	setNodes := func(ready corev1.ConditionStatus) {
+		node := MockNode(aws.StringValue(scalingGroup.Instances[0].InstanceId), ready)
+		state.SetClusterNodes(&corev1.NodeList{Items: []corev1.Node{*node}})
+	}
+
+	// the not ready time is recorded, without a timeout the group waits indefinitely
+	setNodes(corev1.ConditionFalse)
+	ctx.SetState(v1alpha1.ReconcileModifying)
+	g.Expect(ctx.UpdateNodeReadyCondition()).To(gomega.BeFalse())
+	g.Expect(status.GetNodesNotReadySince()).NotTo(gomega.BeZero())
+	status.SetNodesNotReadySince(time.Now().Add(-time.Hour))
+	g.Expect(ctx.UpdateNodeReadyCondition()).To(gomega.BeFalse())
+	g.Expect(ctx.GetState()).To(gomega.Equal(v1alpha1.ReconcileModifying))
+
+	// the controller default applies unless the group sets a timeout
+	ctx.NodeReadinessTimeout = 2 * time.Hour
+	g.Expect(ctx.UpdateNodeReadyCondition()).To(gomega.BeFalse())
+	g.Expect(ctx.GetState()).To(gomega.Equal(v1alpha1.ReconcileModifying))
+
+	g.Expect(ctx.IsWaiting()).To(gomega.BeFalse())
+
+	// the errored instance group is requeued until its nodes are ready
+	ig.GetEKSSpec().NodeReadinessTimeout = 600
+	g.Expect(ctx.UpdateNodeReadyCondition()).To(gomega.BeFalse())
+	g.Expect(ctx.GetState()).To(gomega.Equal(v1alpha1.ReconcileErr))
+	g.Expect(ctx.IsWaiting()).To(gomega.BeTrue())
+	history := status.GetStateHistory()
+	g.Expect(history[len(history)-1].Reason).To(gomega.Equal(NodeReadinessTimeoutReason))
+
+	// ready nodes clear the not ready time
+	setNodes(corev1.ConditionTrue)
+	g.Expect(ctx.UpdateNodeReadyCondition()).To(gomega.BeTrue())
+	g.Expect(status.GetNodesNotReadySince()).To(gomega.BeZero())
+}
+
+func TestUpdateNodeReadyConditionInstanceTypes(t *testing.T) {
+	var (
+		g       = gomega.NewGomegaWithT(t)
+		k       = MockKubernetesClientSet()
+		ig      = MockInstanceGroup()
+		asgMock = NewAutoScalingMocker()
+		iamMock = NewIamMocker()
+		eksMock = NewEksMocker()
+		ec2Mock = NewEc2Mocker()
+		ssmMock = NewSsmMocker()
+	)
+
+	w := MockAwsWorker(asgMock, iamMock, eksMock, ec2Mock, ssmMock)
+	ctx := MockContext(ig, k, w)
+	state := ctx.GetDiscoveredState()
+	state.Publisher = kubeprovider.EventPublisher{
+		Client: k.Kubernetes,
+	}
+
+	configuration := ig.GetEKSConfiguration()
+	configuration.InstanceType = "m5.xlarge"
+	configuration.MixedInstancesPolicy = &v1alpha1.MixedInstancesPolicySpec{
+		InstanceTypes: []*v1alpha1.InstanceTypeSpec{
+			{Type: "m5.xlarge"},
+			{Type: "m5a.xlarge"},
+		},
+	}
+
+	scalingGroup := MockScalingGroup("asg-1", true)
+	scalingGroup.Instances = MockScalingInstances(2, 0)
+	scalingGroup.DesiredCapacity = aws.Int64(2)
+	state.SetScalingGroup(scalingGroup)
+
+	setNodes := func(instanceTypes ...string) {
+		nodes := &corev1.NodeList{}
+		for i, instance := range scalingGroup.Instances {
+			node := MockNode(aws.StringValue(instance.InstanceId), corev1.ConditionTrue)
+			node.Labels = map[string]string{InstanceTypeLabel: instanceTypes[i]}
+			nodes.Items = append(nodes.Items, *node)
+		}
+		state.SetClusterNodes(nodes)
+	}
+
+	// without the annotation the group may come up entirely on fallback types
+	setNodes("m5a.xlarge", "m5a.xlarge")
+	g.Expect(ctx.UpdateNodeReadyCondition()).To(gomega.BeTrue())
+
+	// the primary instance type is required
+	ig.Annotations[InstanceTypeReadinessAnnotation] = "true"
+	g.Expect(ctx.UpdateNodeReadyCondition()).To(gomega.BeFalse())
+	g.Expect(ig.GetStatus().GetConditions()).To(gomega.ContainElement(v1alpha1.NewInstanceGroupCondition(v1alpha1.NodesReady, corev1.ConditionFalse)))
+
+	setNodes("m5.xlarge", "m5a.xlarge")
+	g.Expect(ctx.UpdateNodeReadyCondition()).To(gomega.BeTrue())
+
+	// explicit list of required instance types
+	ig.Annotations[InstanceTypeReadinessAnnotation] = "m5.xlarge, m5a.xlarge"
+	g.Expect(ctx.UpdateNodeReadyCondition()).To(gomega.BeTrue())
+	setNodes("m5.xlarge", "m5.xlarge")
+	g.Expect(ctx.UpdateNodeReadyCondition()).To(gomega.BeFalse())
+
+	// groups without a mixed instances policy are not affected
+	configuration.MixedInstancesPolicy = nil
+	g.Expect(ctx.UpdateNodeReadyCondition()).To(gomega.BeTrue())
+}
+
+func TestUpdateNodeAnnotations(t *testing.T) {
+	var (
+		g       = gomega.NewGomegaWithT(t)
+		k       = MockKubernetesClientSet()
+		ig      = MockInstanceGroup()
+		asgMock = NewAutoScalingMocker()
+		iamMock = NewIamMocker()
+		eksMock = NewEksMocker()
+		ec2Mock = NewEc2Mocker()
+		ssmMock = NewSsmMocker()
+	)
+
+	w := MockAwsWorker(asgMock, iamMock, eksMock, ec2Mock, ssmMock)
+	ctx := MockContext(ig, k, w)
+	state := ctx.GetDiscoveredState()
+
+	ig.SetAnnotations(map[string]string{
+		PropagateAnnotationsAnnotation: "example.com/cost-center, example.com/team,example.com/missing",
+		"example.com/cost-center":      "1234",
+		"example.com/team":             "platform",
+		"example.com/not-propagated":   "value",
+	})
+
+	groupNode := MockNode("i-000000000", corev1.ConditionTrue)
+	groupNode.SetLabels(map[string]string{RoleNewLabel: ig.GetName()})
+	groupNode.SetAnnotations(map[string]string{"example.com/team": "other"})
+
+	otherNode := MockNode("i-000000001", corev1.ConditionTrue)
+	otherNode.SetLabels(map[string]string{RoleNewLabel: "other-group"})
+
+	// a group with the same name in another namespace has the same role label
+	otherNamespaceNode := MockNode("i-000000002", corev1.ConditionTrue)
+	otherNamespaceNode.SetLabels(map[string]string{RoleNewLabel: ig.GetName()})
+
+	nodes := &corev1.NodeList{}
+	for _, node := range []*corev1.Node{groupNode, otherNode, otherNamespaceNode} {
+		_, err := k.Kubernetes.CoreV1().Nodes().Create(context.Background(), node, metav1.CreateOptions{})
+		g.Expect(err).NotTo(gomega.HaveOccurred())
+		nodes.Items = append(nodes.Items, *node)
+	}
+	state.SetClusterNodes(nodes)
+	state.ScalingGroup = MockScalingGroup("asg-1", false)
+	state.ScalingGroup.Instances = []*autoscaling.Instance{{InstanceId: aws.String("i-000000000")}}
+
+	g.Expect(ctx.GetPropagatedAnnotations()).To(gomega.Equal(map[string]string{
+		"example.com/cost-center": "1234",
+		"example.com/team":        "platform",
+	}))
+
+	err := ctx.UpdateNodeAnnotations()
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+
+	node, err := k.Kubernetes.CoreV1().Nodes().Get(context.Background(), groupNode.GetName(), metav1.GetOptions{})
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(node.GetAnnotations()).To(gomega.HaveKeyWithValue("example.com/cost-center", "1234"))
+	g.Expect(node.GetAnnotations()).To(gomega.HaveKeyWithValue("example.com/team", "platform"))
+	g.Expect(node.GetAnnotations()).NotTo(gomega.HaveKey("example.com/not-propagated"))
+
+	for _, name := range []string{otherNode.GetName(), otherNamespaceNode.GetName()} {
+		node, err = k.Kubernetes.CoreV1().Nodes().Get(context.Background(), name, metav1.GetOptions{})
+		g.Expect(err).NotTo(gomega.HaveOccurred())
+		g.Expect(node.GetAnnotations()).NotTo(gomega.HaveKey("example.com/cost-center"))
+	}
+}
+
+func TestUpdateNodeTaints(t *testing.T) {
+	var (
+		g             = gomega.NewGomegaWithT(t)
+		k             = MockKubernetesClientSet()
+		ig            = MockInstanceGroup()
+		configuration = ig.GetEKSConfiguration()
+		asgMock       = NewAutoScalingMocker()
+		iamMock       = NewIamMocker()
+		eksMock       = NewEksMocker()
+		ec2Mock       = NewEc2Mocker()
+		ssmMock       = NewSsmMocker()
+	)
+
+	w := MockAwsWorker(asgMock, iamMock, eksMock, ec2Mock, ssmMock)
+	ctx := MockContext(ig, k, w)
+	state := ctx.GetDiscoveredState()
+
+	startupTaint := corev1.Taint{Key: "node.cilium.io/agent-not-ready", Value: "true", Effect: corev1.TaintEffectNoExecute}
+	dedicatedTaint := corev1.Taint{Key: "dedicated", Value: "infra", Effect: corev1.TaintEffectNoSchedule}
+
+	groupNode := MockNode("i-000000000", corev1.ConditionTrue)
+	groupNode.SetLabels(map[string]string{RoleNewLabel: ig.GetName()})
+	groupNode.Spec.Taints = []corev1.Taint{startupTaint}
+
+	otherNode := MockNode("i-000000001", corev1.ConditionTrue)
+	otherNode.SetLabels(map[string]string{RoleNewLabel: "other-group"})
+
+	// a group with the same name in another namespace has the same role label
+	otherNamespaceNode := MockNode("i-000000002", corev1.ConditionTrue)
+	otherNamespaceNode.SetLabels(map[string]string{RoleNewLabel: ig.GetName()})
+
+	nodes := &corev1.NodeList{}
+	for _, node := range []*corev1.Node{groupNode, otherNode, otherNamespaceNode} {
+		_, err := k.Kubernetes.CoreV1().Nodes().Create(context.Background(), node, metav1.CreateOptions{})
+		g.Expect(err).NotTo(gomega.HaveOccurred())
+		nodes.Items = append(nodes.Items, *node)
+	}
+	state.SetClusterNodes(nodes)
+	state.ScalingGroup = MockScalingGroup("asg-1", false)
+	state.ScalingGroup.Instances = []*autoscaling.Instance{{InstanceId: aws.String("i-000000000")}}
+	configuration.SetTaints([]corev1.Taint{dedicatedTaint})
+
+	// taints of existing nodes are only reconciled when enabled
+	err := ctx.UpdateNodeTaints()
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	node, err := k.Kubernetes.CoreV1().Nodes().Get(context.Background(), groupNode.GetName(), metav1.GetOptions{})
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(node.Spec.Taints).To(gomega.ConsistOf(startupTaint))
+
+	// a new taint is applied to existing nodes of the group
+	ig.SetAnnotations(map[string]string{ReconcileNodeTaintsAnnotation: "true"})
+	err = ctx.UpdateNodeTaints()
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+
+	node, err = k.Kubernetes.CoreV1().Nodes().Get(context.Background(), groupNode.GetName(), metav1.GetOptions{})
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(node.Spec.Taints).To(gomega.ConsistOf(startupTaint, dedicatedTaint))
+	g.Expect(node.GetAnnotations()).To(gomega.HaveKeyWithValue(AppliedTaintsAnnotation, "dedicated=infra:NoSchedule"))
+
+	for _, name := range []string{otherNode.GetName(), otherNamespaceNode.GetName()} {
+		node, err = k.Kubernetes.CoreV1().Nodes().Get(context.Background(), name, metav1.GetOptions{})
+		g.Expect(err).NotTo(gomega.HaveOccurred())
+		g.Expect(node.Spec.Taints).To(gomega.BeEmpty())
+	}
+
+	// a changed value replaces the taint, and a removed taint is removed while other taints are kept. Taints are
+	// computed from the current node, a taint added since the nodes were discovered is kept
+	node, err = k.Kubernetes.CoreV1().Nodes().Get(context.Background(), groupNode.GetName(), metav1.GetOptions{})
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	addedTaint := corev1.Taint{Key: "example.com/maintenance", Effect: corev1.TaintEffectNoSchedule}
+	node.Spec.Taints = append(node.Spec.Taints, addedTaint)
+	_, err = k.Kubernetes.CoreV1().Nodes().Update(context.Background(), node, metav1.UpdateOptions{})
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	updatedTaint := corev1.Taint{Key: "dedicated", Value: "system", Effect: corev1.TaintEffectNoSchedule}
+	configuration.SetTaints([]corev1.Taint{updatedTaint})
+
+	err = ctx.UpdateNodeTaints()
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	node, err = k.Kubernetes.CoreV1().Nodes().Get(context.Background(), groupNode.GetName(), metav1.GetOptions{})
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(node.Spec.Taints).To(gomega.ConsistOf(startupTaint, addedTaint, updatedTaint))
+
+	configuration.SetTaints(nil)
+
+	err = ctx.UpdateNodeTaints()
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	node, err = k.Kubernetes.CoreV1().Nodes().Get(context.Background(), groupNode.GetName(), metav1.GetOptions{})
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(node.Spec.Taints).To(gomega.ConsistOf(startupTaint, addedTaint))
+	g.Expect(node.GetAnnotations()).To(gomega.HaveKeyWithValue(AppliedTaintsAnnotation, ""))
+}
+
+func TestUpdateNodeLabels(t *testing.T) {
+	var (
+		g       = gomega.NewGomegaWithT(t)
+		k       = MockKubernetesClientSet()
+		ig      = MockInstanceGroup()
+		status  = ig.GetStatus()
+		asgMock = NewAutoScalingMocker()
+		iamMock = NewIamMocker()
+		eksMock = NewEksMocker()
+		ec2Mock = NewEc2Mocker()
+		ssmMock = NewSsmMocker()
+	)
+
+	w := MockAwsWorker(asgMock, iamMock, eksMock, ec2Mock, ssmMock)
+	ctx := MockContext(ig, k, w)
+	state := ctx.GetDiscoveredState()
+	ctx.NodeRelabel = true
+
+	scalingGroup := MockScalingGroup("asg-1", true)
+	scalingGroup.Instances = MockScalingInstances(2, 0)
+	for _, instance := range scalingGroup.Instances {
+		instance.InstanceType = aws.String("m5.large")
+	}
+	state.SetScalingGroup(scalingGroup)
+
+	nodes := &corev1.NodeList{}
+	for _, instance := range scalingGroup.Instances {
+		node := MockNode(aws.StringValue(instance.InstanceId), corev1.ConditionTrue)
+		node.SetLabels(map[string]string{
+			RoleNewLabel:              ig.GetName(),
+			InstanceMgrLifecycleLabel: v1alpha1.LifecycleStateNormal,
+		})
+		_, err := k.Kubernetes.CoreV1().Nodes().Create(context.Background(), node, metav1.CreateOptions{})
+		g.Expect(err).NotTo(gomega.HaveOccurred())
+		nodes.Items = append(nodes.Items, *node)
+	}
+
+	// a group with the same name in another namespace has the same role label
+	otherNamespaceNode := MockNode("i-999999999", corev1.ConditionTrue)
+	otherNamespaceNode.SetLabels(map[string]string{
+		RoleNewLabel:              ig.GetName(),
+		InstanceMgrLifecycleLabel: v1alpha1.LifecycleStateNormal,
+	})
+	_, err := k.Kubernetes.CoreV1().Nodes().Create(context.Background(), otherNamespaceNode, metav1.CreateOptions{})
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	state.SetClusterNodes(&corev1.NodeList{Items: append(nodes.Items, *otherNamespaceNode)})
+
+	// group transitions to spot, existing nodes are relabeled
+	status.SetLifecycle(v1alpha1.LifecycleStateSpot)
+	err = ctx.UpdateNodeLabels()
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+
+	node, err := k.Kubernetes.CoreV1().Nodes().Get(context.Background(), otherNamespaceNode.GetName(), metav1.GetOptions{})
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(node.GetLabels()).To(gomega.HaveKeyWithValue(InstanceMgrLifecycleLabel, v1alpha1.LifecycleStateNormal))
+
+	for _, n := range nodes.Items {
+		node, err := k.Kubernetes.CoreV1().Nodes().Get(context.Background(), n.GetName(), metav1.GetOptions{})
+		g.Expect(err).NotTo(gomega.HaveOccurred())
+		g.Expect(node.GetLabels()).To(gomega.HaveKeyWithValue(InstanceMgrLifecycleLabel, v1alpha1.LifecycleStateSpot))
+		g.Expect(node.GetLabels()).To(gomega.HaveKeyWithValue(InstanceTypeLabel, "m5.large"))
+		g.Expect(node.GetLabels()).To(gomega.HaveKeyWithValue(RoleNewLabel, ig.GetName()))
+	}
+
+	// nodes are not relabeled when node relabeling is disabled
+	ctx.NodeRelabel = false
+	status.SetLifecycle(v1alpha1.LifecycleStateNormal)
+	err = ctx.UpdateNodeLabels()
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+
+	node, err = k.Kubernetes.CoreV1().Nodes().Get(context.Background(), nodes.Items[0].GetName(), metav1.GetOptions{})
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(node.GetLabels()).To(gomega.HaveKeyWithValue(InstanceMgrLifecycleLabel, v1alpha1.LifecycleStateSpot))
+}
+
+func TestGetManagedPoliciesList(t *testing.T) {
+	var (
+		g       = gomega.NewGomegaWithT(t)
+		k       = MockKubernetesClientSet()
+		ig      = MockInstanceGroup()
+		asgMock = NewAutoScalingMocker()
+		iamMock = NewIamMocker()
+		eksMock = NewEksMocker()
+		ec2Mock = NewEc2Mocker()
+		ssmMock = NewSsmMocker()
+	)
+
+	w := MockAwsWorker(asgMock, iamMock, eksMock, ec2Mock, ssmMock)
+	ctx := MockContext(ig, k, w)
+
+	userPolicies := []string{"policy-1", "arn:aws:iam::12345679012:policy/policy-2"}
+	expectedUserPolicies := []string{
+		"arn:aws:iam::aws:policy/policy-1",
+		"arn:aws:iam::12345679012:policy/policy-2",
+	}
+
+	// default policies are appended to the user policies
+	policies := ctx.GetManagedPoliciesList(userPolicies)
+	g.Expect(policies).To(gomega.Equal(append(expectedUserPolicies,
+		"arn:aws:iam::aws:policy/AmazonEKSWorkerNodePolicy",
+		"arn:aws:iam::aws:policy/AmazonEC2ContainerRegistryReadOnly",
+		"arn:aws:iam::aws:policy/AmazonEKS_CNI_Policy",
+	)))
+
+	// an explicit append mode behaves the same as the default
+	ig.Annotations[ManagedPoliciesAnnotation] = ManagedPoliciesModeAppend
+	g.Expect(ctx.GetManagedPoliciesList(userPolicies)).To(gomega.Equal(policies))
+
+	// replace mode only includes the user policies
+	ig.Annotations[ManagedPoliciesAnnotation] = ManagedPoliciesModeReplace
+	g.Expect(ctx.GetManagedPoliciesList(userPolicies)).To(gomega.Equal(expectedUserPolicies))
+	g.Expect(ctx.GetManagedPoliciesList([]string{})).To(gomega.BeEmpty())
+}
+
+func TestUpdateLifecycleHookDocuments(t *testing.T) {
+	var (
+		g             = gomega.NewGomegaWithT(t)
+		k             = MockKubernetesClientSet()
+		ig            = MockInstanceGroup()
+		configuration = ig.GetEKSConfiguration()
+		asgMock       = NewAutoScalingMocker()
+		iamMock       = NewIamMocker()
+		eksMock       = NewEksMocker()
+		ec2Mock       = NewEc2Mocker()
+		ssmMock       = NewSsmMocker()
+	)
+
+	w := MockAwsWorker(asgMock, iamMock, eksMock, ec2Mock, ssmMock)
+	ctx := MockContext(ig, k, w)
+
+	configuration.SetLifecycleHooks([]v1alpha1.LifecycleHookSpec{
+		{
+			Name:          "notify-hook",
+			Lifecycle:     awsprovider.LifecycleHookTransitionLaunch,
+			DefaultResult: v1alpha1.LifecycleHookResultAbandon,
+		},
+		{
+			Name:          "document-hook",
+			Lifecycle:     awsprovider.LifecycleHookTransitionLaunch,
+			DefaultResult: v1alpha1.LifecycleHookResultAbandon,
+			SSMDocument: &v1alpha1.LifecycleHookDocument{
+				Name:       "my-document",
+				Version:    "2",
+				Parameters: map[string][]string{"Mode": {"strict"}},
+			},
+		},
+	})
+
+	scalingGroup := MockScalingGroup("asg-1", true)
+	scalingGroup.Instances = []*autoscaling.Instance{
+		{InstanceId: aws.String("i-1234"), LifecycleState: aws.String(autoscaling.LifecycleStatePendingWait)},
+		{InstanceId: aws.String("i-2345"), LifecycleState: aws.String(autoscaling.LifecycleStateInService)},
+	}
+	ctx.SetDiscoveredState(&DiscoveredState{
+		Publisher: kubeprovider.EventPublisher{
+			Client: k.Kubernetes,
+		},
+		ScalingGroup: scalingGroup,
+	})
+
+	tests := []struct {
+		executionStatus  string
+		expectedComplete uint
+		expectedResult   string
+		expectedWaiting  bool
+	}{
+		{executionStatus: ssm.AutomationExecutionStatusInProgress, expectedComplete: 0, expectedWaiting: true},
+		{executionStatus: ssm.AutomationExecutionStatusSuccess, expectedComplete: 1, expectedResult: v1alpha1.LifecycleHookResultContinue},
+		{executionStatus: ssm.AutomationExecutionStatusFailed, expectedComplete: 1, expectedResult: v1alpha1.LifecycleHookResultAbandon},
+	}
+
+	for i, tc := range tests {
+		t.Logf("test #%v - %+v", i, tc)
+		ssmMock.StartAutomationExecutionCount = 0
+		ssmMock.StartAutomationExecutionInput = nil
+		asgMock.CompleteLifecycleActionCallCount = 0
+		asgMock.CompleteLifecycleActionInput = nil
+		ssmMock.AutomationExecutionStatus = tc.executionStatus
+
+		err := ctx.UpdateLifecycleHookDocuments()
+		g.Expect(err).NotTo(gomega.HaveOccurred())
+
+		// only the waiting instance runs the document
+		g.Expect(ssmMock.StartAutomationExecutionCount).To(gomega.Equal(uint(1)))
+		input := ssmMock.StartAutomationExecutionInput
+		g.Expect(aws.StringValue(input.DocumentName)).To(gomega.Equal("my-document"))
+		g.Expect(aws.StringValue(input.DocumentVersion)).To(gomega.Equal("2"))
+		g.Expect(aws.StringValueSlice(input.Parameters["Mode"])).To(gomega.Equal([]string{"strict"}))
+		g.Expect(aws.StringValueSlice(input.Parameters[v1alpha1.LifecycleHookDefaultInstanceIdParameter])).To(gomega.Equal([]string{"i-1234"}))
+		g.Expect(aws.StringValue(input.ClientToken)).To(gomega.MatchRegexp(`^[a-f0-9]{8}-[a-f0-9]{4}-[a-f0-9]{4}-[a-f0-9]{4}-[a-f0-9]{12}$`))
+
+		g.Expect(asgMock.CompleteLifecycleActionCallCount).To(gomega.Equal(tc.expectedComplete))
+		g.Expect(ctx.IsWaiting()).To(gomega.Equal(tc.expectedWaiting))
+		if tc.expectedComplete > 0 {
+			complete := asgMock.CompleteLifecycleActionInput
+			g.Expect(aws.StringValue(complete.AutoScalingGroupName)).To(gomega.Equal("asg-1"))
+			g.Expect(aws.StringValue(complete.LifecycleHookName)).To(gomega.Equal("document-hook"))
+			g.Expect(aws.StringValue(complete.InstanceId)).To(gomega.Equal("i-1234"))
+			g.Expect(aws.StringValue(complete.LifecycleActionResult)).To(gomega.Equal(tc.expectedResult))
+		}
+	}
+}
+
+func TestGetSpotPrice(t *testing.T) {
+	var (
+		g       = gomega.NewGomegaWithT(t)
+		k       = MockKubernetesClientSet()
+		ig      = MockInstanceGroup()
+		asgMock = NewAutoScalingMocker()
+		iamMock = NewIamMocker()
+		eksMock = NewEksMocker()
+		ec2Mock = NewEc2Mocker()
+		ssmMock = NewSsmMocker()
+	)
+
+	w := MockAwsWorker(asgMock, iamMock, eksMock, ec2Mock, ssmMock)
+	ctx := MockContext(ig, k, w)
+	configuration := ig.GetEKSConfiguration()
+	configuration.SetSpotPrice("0.05")
+
+	ig.GetEKSSpec().Type = v1alpha1.LaunchConfiguration
+	g.Expect(ctx.GetSpotPrice()).To(gomega.Equal("0.05"))
+
+	// launch templates ignore the spot price unless opted in
+	ig.GetEKSSpec().Type = v1alpha1.LaunchTemplate
+	g.Expect(ctx.GetSpotPrice()).To(gomega.BeEmpty())
+	configuration.SpotMarketOptions = true
+	g.Expect(ctx.GetSpotPrice()).To(gomega.Equal("0.05"))
+
+	// mixed instance groups use the instances distribution instead
+	configuration.MixedInstancesPolicy = &v1alpha1.MixedInstancesPolicySpec{}
+	g.Expect(ctx.GetSpotPrice()).To(gomega.BeEmpty())
+}
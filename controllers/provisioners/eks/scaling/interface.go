@@ -42,6 +42,10 @@ type DeleteConfigurationInput struct {
 	Prefix         string
 	DeleteAll      bool
 	RetainVersions int
+	ScalingGroup   *autoscaling.Group
+	// VersionDescription limits pruning to the versions created with this description, versions of a launch template
+	// not owned by the controller are otherwise left alone
+	VersionDescription string
 }
 
 type DiscoverConfigurationInput struct {
@@ -50,18 +54,31 @@ type DiscoverConfigurationInput struct {
 }
 
 type CreateConfigurationInput struct {
-	Name                  string
-	IamInstanceProfileArn string
-	ImageId               string
-	InstanceType          string
-	KeyName               string
-	SecurityGroups        []string
-	Volumes               []v1alpha1.NodeVolume
-	UserData              string
-	SpotPrice             string
-	LicenseSpecifications []string
-	Placement             *v1alpha1.PlacementSpec
-	MetadataOptions       *v1alpha1.MetadataOptions
+	Name                          string
+	IamInstanceProfileArn         string
+	ImageId                       string
+	InstanceType                  string
+	KeyName                       string
+	SecurityGroups                []string
+	Volumes                       []v1alpha1.NodeVolume
+	UserData                      string
+	SpotPrice                     string
+	LicenseSpecifications         []string
+	Placement                     *v1alpha1.PlacementSpec
+	MetadataOptions               *v1alpha1.MetadataOptions
+	CapacityReservationId         string
+	CapacityReservationPreference string
+	VolumeTags                    map[string]string
+	// ForceVersion creates a new version of a provisioned launch template even if it has not drifted
+	ForceVersion bool
+	// TemplateTags are added to launch templates created by the controller to mark them as owned
+	TemplateTags map[string]string
+	// Unowned marks a pre-created launch template, new versions are based on SourceVersion and its default version is
+	// never modified
+	Unowned       bool
+	SourceVersion string
+	// VersionDescription is set on new versions of a launch template to identify the versions created by the controller
+	VersionDescription string
 }
 
 func ConvertToLaunchTemplate(resource interface{}) *ec2.LaunchTemplate {
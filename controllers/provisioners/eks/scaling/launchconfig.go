@@ -85,13 +85,16 @@ func (lc *LaunchConfiguration) Create(input *CreateConfigurationInput) error {
 		IamInstanceProfile:      aws.String(input.IamInstanceProfileArn),
 		ImageId:                 aws.String(input.ImageId),
 		InstanceType:            aws.String(input.InstanceType),
-		KeyName:                 aws.String(input.KeyName),
 		SecurityGroups:          aws.StringSlice(input.SecurityGroups),
 		UserData:                aws.String(input.UserData),
 		BlockDeviceMappings:     devices,
 		MetadataOptions:         lc.metadataOptions(input.MetadataOptions),
 	}
 
+	if !common.StringEmpty(input.KeyName) {
+		opts.KeyName = aws.String(input.KeyName)
+	}
+
 	if !common.StringEmpty(input.SpotPrice) {
 		opts.SpotPrice = aws.String(input.SpotPrice)
 	}
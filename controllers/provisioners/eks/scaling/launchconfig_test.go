@@ -44,10 +44,12 @@ type MockAutoScalingClient struct {
 	CreateLaunchConfigurationErr       error
 	DeleteLaunchConfigurationErr       error
 	DeleteLaunchConfigurationCallCount int
+	CreateLaunchConfigurationInput     *autoscaling.CreateLaunchConfigurationInput
 	LaunchConfigurations               []*autoscaling.LaunchConfiguration
 }
 
 func (a *MockAutoScalingClient) CreateLaunchConfiguration(input *autoscaling.CreateLaunchConfigurationInput) (*autoscaling.CreateLaunchConfigurationOutput, error) {
+	a.CreateLaunchConfigurationInput = input
 	return &autoscaling.CreateLaunchConfigurationOutput{}, a.CreateLaunchConfigurationErr
 }
 
@@ -190,6 +192,8 @@ func TestLaunchConfigurationCreate(t *testing.T) {
 		},
 	})
 	g.Expect(err).NotTo(gomega.HaveOccurred())
+	// an empty key pair is omitted
+	g.Expect(asgMock.CreateLaunchConfigurationInput.KeyName).To(gomega.BeNil())
 
 	resourceList = append(resourceList, &autoscaling.LaunchConfiguration{
 		LaunchConfigurationName: aws.String("my-launch-config"),
@@ -25,6 +25,7 @@ import (
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/autoscaling"
 	"github.com/aws/aws-sdk-go/service/ec2"
 	"github.com/keikoproj/instance-manager/controllers/common"
 	awsprovider "github.com/keikoproj/instance-manager/controllers/providers/aws"
@@ -94,34 +95,56 @@ func (lt *LaunchTemplate) Create(input *CreateConfigurationInput) error {
 		IamInstanceProfile: &ec2.LaunchTemplateIamInstanceProfileSpecificationRequest{
 			Arn: aws.String(input.IamInstanceProfileArn),
 		},
-		ImageId:               aws.String(input.ImageId),
-		InstanceType:          aws.String(input.InstanceType),
-		KeyName:               aws.String(input.KeyName),
-		SecurityGroupIds:      aws.StringSlice(input.SecurityGroups),
-		UserData:              aws.String(input.UserData),
-		BlockDeviceMappings:   lt.blockDeviceListRequest(input.Volumes),
-		LicenseSpecifications: lt.LaunchTemplateLicenseConfigurationRequest(input.LicenseSpecifications),
-		Placement:             lt.launchTemplatePlacementRequest(input.Placement),
-		MetadataOptions:       lt.metadataOptionsRequest(input.MetadataOptions),
+		ImageId:                          aws.String(input.ImageId),
+		InstanceType:                     aws.String(input.InstanceType),
+		SecurityGroupIds:                 aws.StringSlice(input.SecurityGroups),
+		UserData:                         aws.String(input.UserData),
+		BlockDeviceMappings:              lt.blockDeviceListRequest(input.Volumes),
+		LicenseSpecifications:            lt.LaunchTemplateLicenseConfigurationRequest(input.LicenseSpecifications),
+		Placement:                        lt.launchTemplatePlacementRequest(input.Placement),
+		MetadataOptions:                  lt.metadataOptionsRequest(input.MetadataOptions),
+		CapacityReservationSpecification: lt.capacityReservationRequest(input.CapacityReservationId, input.CapacityReservationPreference),
+		TagSpecifications:                lt.volumeTagSpecificationsRequest(input.VolumeTags),
+		InstanceMarketOptions:            lt.instanceMarketOptionsRequest(input.SpotPrice),
+	}
+
+	// an empty key name is rejected, instances are launched without a key pair when it is omitted
+	if !common.StringEmpty(input.KeyName) {
+		templateData.KeyName = aws.String(input.KeyName)
 	}
 
 	if !lt.Provisioned() {
 		if err := lt.CreateLaunchTemplate(&ec2.CreateLaunchTemplateInput{
 			LaunchTemplateName: aws.String(input.Name),
 			LaunchTemplateData: templateData,
+			TagSpecifications:  lt.templateTagSpecifications(input.TemplateTags),
 		}); err != nil {
 			return err
 		}
-	} else if lt.Drifted(input) {
-		createdVersion, err := lt.CreateLaunchTemplateVersion(&ec2.CreateLaunchTemplateVersionInput{
+	} else if input.ForceVersion || lt.Drifted(input) {
+		versionInput := &ec2.CreateLaunchTemplateVersionInput{
 			LaunchTemplateName: aws.String(input.Name),
 			LaunchTemplateData: templateData,
-		})
+		}
+		if !common.StringEmpty(input.SourceVersion) {
+			versionInput.SourceVersion = aws.String(input.SourceVersion)
+		}
+		if !common.StringEmpty(input.VersionDescription) {
+			versionInput.VersionDescription = aws.String(input.VersionDescription)
+		}
+
+		createdVersion, err := lt.CreateLaunchTemplateVersion(versionInput)
 		if err != nil {
 			return err
 		}
 		lt.TargetVersions = append(lt.TargetVersions, createdVersion)
 
+		// the default version of a launch template not owned by the controller is left as is
+		if input.Unowned {
+			lt.LatestVersion = lt.getVersion(*createdVersion.VersionNumber)
+			return nil
+		}
+
 		var modified *ec2.LaunchTemplate
 		v := common.Int64ToStr(*createdVersion.VersionNumber)
 		if modified, err = lt.UpdateLaunchTemplateDefaultVersion(input.Name, v); err != nil {
@@ -129,11 +152,46 @@ func (lt *LaunchTemplate) Create(input *CreateConfigurationInput) error {
 		}
 		lt.TargetResource = modified
 		lt.LatestVersion = lt.getVersion(*createdVersion.VersionNumber)
+		lt.markDefaultVersion(*createdVersion.VersionNumber)
 	}
 
 	return nil
 }
 
+// SetLatestAsDefault makes the latest version of the launch template its default version, and returns whether the
+// default version was modified
+func (lt *LaunchTemplate) SetLatestAsDefault(name string) (bool, error) {
+	if !lt.Provisioned() || lt.LatestVersion == nil {
+		return false, nil
+	}
+
+	latest := aws.Int64Value(lt.LatestVersion.VersionNumber)
+	if aws.BoolValue(lt.LatestVersion.DefaultVersion) || aws.Int64Value(lt.TargetResource.DefaultVersionNumber) == latest {
+		return false, nil
+	}
+
+	modified, err := lt.UpdateLaunchTemplateDefaultVersion(name, common.Int64ToStr(latest))
+	if err != nil {
+		return false, err
+	}
+	if modified != nil {
+		lt.TargetResource = modified
+	}
+	lt.markDefaultVersion(latest)
+	return true, nil
+}
+
+// markDefaultVersion updates the discovered versions after the default version has been modified, so that retention
+// pruning protects the new default version rather than the previous one
+func (lt *LaunchTemplate) markDefaultVersion(version int64) {
+	for _, v := range lt.TargetVersions {
+		v.DefaultVersion = aws.Bool(aws.Int64Value(v.VersionNumber) == version)
+	}
+	if lt.TargetResource != nil {
+		lt.TargetResource.DefaultVersionNumber = aws.Int64(version)
+	}
+}
+
 func (lt *LaunchTemplate) Delete(input *DeleteConfigurationInput) error {
 	if input.RetainVersions == 0 {
 		input.RetainVersions = DefaultConfigVersionRetention
@@ -151,7 +209,16 @@ func (lt *LaunchTemplate) Delete(input *DeleteConfigurationInput) error {
 		return nil
 	}
 
-	sortedVersions := sortVersions(lt.TargetVersions)
+	versions := lt.TargetVersions
+	if !common.StringEmpty(input.VersionDescription) {
+		versions = make([]*ec2.LaunchTemplateVersion, 0)
+		for _, v := range lt.TargetVersions {
+			if aws.StringValue(v.VersionDescription) == input.VersionDescription {
+				versions = append(versions, v)
+			}
+		}
+	}
+	sortedVersions := sortVersions(versions)
 
 	var deletable []*ec2.LaunchTemplateVersion
 	if len(sortedVersions) > input.RetainVersions {
@@ -159,9 +226,15 @@ func (lt *LaunchTemplate) Delete(input *DeleteConfigurationInput) error {
 		deletable = sortedVersions[:d]
 	}
 
+	// versions outside the retention window may still be the latest/default or referenced by the scaling group
+	protected := lt.protectedVersions(input.Name, input.ScalingGroup)
+
 	deletableVersions := make([]string, 0)
 	for _, d := range deletable {
 		versionNumber := aws.Int64Value(d.VersionNumber)
+		if protected[versionNumber] || aws.BoolValue(d.DefaultVersion) {
+			continue
+		}
 		versionString := strconv.FormatInt(versionNumber, 10)
 		deletableVersions = append(deletableVersions, versionString)
 	}
@@ -179,6 +252,66 @@ func (lt *LaunchTemplate) Delete(input *DeleteConfigurationInput) error {
 	return nil
 }
 
+// protectedVersions returns the version numbers of a launch template which must not be deleted
+func (lt *LaunchTemplate) protectedVersions(name string, scalingGroup *autoscaling.Group) map[int64]bool {
+	var (
+		protected      = make(map[int64]bool)
+		latestVersion  int64
+		defaultVersion int64
+	)
+
+	if lt.TargetResource != nil {
+		latestVersion = aws.Int64Value(lt.TargetResource.LatestVersionNumber)
+		defaultVersion = aws.Int64Value(lt.TargetResource.DefaultVersionNumber)
+	}
+	if lt.LatestVersion != nil && aws.Int64Value(lt.LatestVersion.VersionNumber) > latestVersion {
+		latestVersion = aws.Int64Value(lt.LatestVersion.VersionNumber)
+	}
+	protected[latestVersion] = true
+	protected[defaultVersion] = true
+
+	if scalingGroup == nil {
+		return protected
+	}
+
+	reference := func(templateName, version *string) {
+		if !strings.EqualFold(aws.StringValue(templateName), name) {
+			return
+		}
+		switch v := aws.StringValue(version); v {
+		case "", awsprovider.LaunchTemplateLatestVersionKey:
+			protected[latestVersion] = true
+		case awsprovider.LaunchTemplateDefaultVersionKey:
+			protected[defaultVersion] = true
+		default:
+			if n, err := strconv.ParseInt(v, 10, 64); err == nil {
+				protected[n] = true
+			}
+		}
+	}
+
+	if spec := scalingGroup.LaunchTemplate; spec != nil {
+		reference(spec.LaunchTemplateName, spec.Version)
+	}
+	if policy := scalingGroup.MixedInstancesPolicy; policy != nil && policy.LaunchTemplate != nil {
+		if spec := policy.LaunchTemplate.LaunchTemplateSpecification; spec != nil {
+			reference(spec.LaunchTemplateName, spec.Version)
+		}
+		for _, override := range policy.LaunchTemplate.Overrides {
+			if spec := override.LaunchTemplateSpecification; spec != nil {
+				reference(spec.LaunchTemplateName, spec.Version)
+			}
+		}
+	}
+	for _, instance := range scalingGroup.Instances {
+		if spec := instance.LaunchTemplate; spec != nil {
+			reference(spec.LaunchTemplateName, spec.Version)
+		}
+	}
+
+	return protected
+}
+
 func (lt *LaunchTemplate) Drifted(input *CreateConfigurationInput) bool {
 	var (
 		latestVersion = lt.LatestVersion
@@ -281,6 +414,36 @@ func (lt *LaunchTemplate) Drifted(input *CreateConfigurationInput) bool {
 		drift = true
 	}
 
+	capacityReservation := lt.capacityReservation(input.CapacityReservationId, input.CapacityReservationPreference)
+	if !reflect.DeepEqual(capacityReservation, latestVersion.LaunchTemplateData.CapacityReservationSpecification) {
+		log.Info("detected drift", "reason", "capacity reservation has changed", "instancegroup", lt.OwnerName,
+			"previousValue", latestVersion.LaunchTemplateData.CapacityReservationSpecification,
+			"newValue", capacityReservation,
+		)
+		drift = true
+	}
+
+	// a spot price change only creates a new version of the launch template
+	spotPrice := spotMaxPrice(latestVersion.LaunchTemplateData.InstanceMarketOptions)
+	if spotPrice != input.SpotPrice {
+		log.Info("detected drift", "reason", "spot price has changed", "instancegroup", lt.OwnerName,
+			"previousValue", spotPrice,
+			"newValue", input.SpotPrice,
+		)
+		drift = true
+	}
+
+	// versions without a volume tag specification predate volume tagging, their volume tags are adopted as the baseline
+	// so that existing groups are not rotated, the tags are applied with the next version that is created
+	volumeTags, volumeTagged := volumeTags(latestVersion.LaunchTemplateData.TagSpecifications)
+	if volumeTagged && tagsDrifted(volumeTags, input.VolumeTags) {
+		log.Info("detected drift", "reason", "volume tags have changed", "instancegroup", lt.OwnerName,
+			"previousValue", volumeTags,
+			"newValue", input.VolumeTags,
+		)
+		drift = true
+	}
+
 	if !drift {
 		log.Info("drift not detected", "instancegroup", lt.OwnerName)
 	}
@@ -320,7 +483,12 @@ func (lt *LaunchTemplate) RotationNeeded(input *DiscoverConfigurationInput) bool
 			return true
 		}
 
-		if aws.StringValue(instance.LaunchTemplate.LaunchTemplateName) != configName {
+		instanceConfigName := aws.StringValue(instance.LaunchTemplate.LaunchTemplateName)
+		if strings.HasPrefix(instanceConfigName, configName+"-") {
+			// instances launched from an instance type override template are evaluated against that template
+			continue
+		}
+		if instanceConfigName != configName {
 			return true
 		}
 		currentVersion := aws.StringValue(instance.LaunchTemplate.Version)
@@ -360,22 +528,74 @@ func (lt *LaunchTemplate) metadataOptions(input *v1alpha1.MetadataOptions) *ec2.
 	if input == nil {
 		return nil
 	}
-	return &ec2.LaunchTemplateInstanceMetadataOptions{
+	options := &ec2.LaunchTemplateInstanceMetadataOptions{
 		HttpEndpoint:            aws.String(input.HttpEndpoint),
 		HttpPutResponseHopLimit: aws.Int64(input.HttpPutHopLimit),
 		HttpTokens:              aws.String(input.HttpTokens),
 	}
+	if input.InstanceMetadataTags {
+		options.InstanceMetadataTags = aws.String(v1alpha1.MetadataOptionEnabled)
+	}
+	return options
 }
 
 func (lt *LaunchTemplate) metadataOptionsRequest(input *v1alpha1.MetadataOptions) *ec2.LaunchTemplateInstanceMetadataOptionsRequest {
 	if input == nil {
 		return nil
 	}
-	return &ec2.LaunchTemplateInstanceMetadataOptionsRequest{
+	options := &ec2.LaunchTemplateInstanceMetadataOptionsRequest{
 		HttpEndpoint:            aws.String(input.HttpEndpoint),
 		HttpPutResponseHopLimit: aws.Int64(input.HttpPutHopLimit),
 		HttpTokens:              aws.String(input.HttpTokens),
 	}
+	if input.InstanceMetadataTags {
+		options.InstanceMetadataTags = aws.String(v1alpha1.MetadataOptionEnabled)
+	}
+	return options
+}
+
+func (lt *LaunchTemplate) capacityReservation(id, preference string) *ec2.LaunchTemplateCapacityReservationSpecificationResponse {
+	switch {
+	case !common.StringEmpty(id):
+		return &ec2.LaunchTemplateCapacityReservationSpecificationResponse{
+			CapacityReservationTarget: &ec2.CapacityReservationTargetResponse{
+				CapacityReservationId: aws.String(id),
+			},
+		}
+	case !common.StringEmpty(preference):
+		return &ec2.LaunchTemplateCapacityReservationSpecificationResponse{
+			CapacityReservationPreference: aws.String(preference),
+		}
+	}
+	return nil
+}
+
+func (lt *LaunchTemplate) capacityReservationRequest(id, preference string) *ec2.LaunchTemplateCapacityReservationSpecificationRequest {
+	switch {
+	case !common.StringEmpty(id):
+		return &ec2.LaunchTemplateCapacityReservationSpecificationRequest{
+			CapacityReservationTarget: &ec2.CapacityReservationTarget{
+				CapacityReservationId: aws.String(id),
+			},
+		}
+	case !common.StringEmpty(preference):
+		return &ec2.LaunchTemplateCapacityReservationSpecificationRequest{
+			CapacityReservationPreference: aws.String(preference),
+		}
+	}
+	return nil
+}
+
+func (lt *LaunchTemplate) instanceMarketOptionsRequest(spotPrice string) *ec2.LaunchTemplateInstanceMarketOptionsRequest {
+	if common.StringEmpty(spotPrice) {
+		return nil
+	}
+	return &ec2.LaunchTemplateInstanceMarketOptionsRequest{
+		MarketType: aws.String(ec2.MarketTypeSpot),
+		SpotOptions: &ec2.LaunchTemplateSpotMarketOptionsRequest{
+			MaxPrice: aws.String(spotPrice),
+		},
+	}
 }
 
 func (lt *LaunchTemplate) launchTemplatePlacement(input *v1alpha1.PlacementSpec) *ec2.LaunchTemplatePlacement {
@@ -385,6 +605,60 @@ func (lt *LaunchTemplate) launchTemplatePlacement(input *v1alpha1.PlacementSpec)
 	return lt.LaunchTemplatePlacement(input.AvailabilityZone, input.HostResourceGroupArn, input.Tenancy)
 }
 
+func (lt *LaunchTemplate) volumeTagSpecificationsRequest(tags map[string]string) []*ec2.LaunchTemplateTagSpecificationRequest {
+	if len(tags) == 0 {
+		return nil
+	}
+
+	keys := make([]string, 0, len(tags))
+	for k := range tags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	ec2Tags := make([]*ec2.Tag, 0, len(keys))
+	for _, k := range keys {
+		ec2Tags = append(ec2Tags, &ec2.Tag{
+			Key:   aws.String(k),
+			Value: aws.String(tags[k]),
+		})
+	}
+
+	return []*ec2.LaunchTemplateTagSpecificationRequest{
+		{
+			ResourceType: aws.String(ec2.ResourceTypeVolume),
+			Tags:         ec2Tags,
+		},
+	}
+}
+
+func (lt *LaunchTemplate) templateTagSpecifications(tags map[string]string) []*ec2.TagSpecification {
+	if len(tags) == 0 {
+		return nil
+	}
+
+	keys := make([]string, 0, len(tags))
+	for k := range tags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	ec2Tags := make([]*ec2.Tag, 0, len(keys))
+	for _, k := range keys {
+		ec2Tags = append(ec2Tags, &ec2.Tag{
+			Key:   aws.String(k),
+			Value: aws.String(tags[k]),
+		})
+	}
+
+	return []*ec2.TagSpecification{
+		{
+			ResourceType: aws.String(ec2.ResourceTypeLaunchTemplate),
+			Tags:         ec2Tags,
+		},
+	}
+}
+
 func (lt *LaunchTemplate) getVersion(id int64) *ec2.LaunchTemplateVersion {
 	for _, v := range lt.TargetVersions {
 		n := aws.Int64Value(v.VersionNumber)
@@ -395,6 +669,43 @@ func (lt *LaunchTemplate) getVersion(id int64) *ec2.LaunchTemplateVersion {
 	return nil
 }
 
+// spotMaxPrice returns the spot price of the market options of a launch template version, or an empty string for on-demand
+func spotMaxPrice(options *ec2.LaunchTemplateInstanceMarketOptions) string {
+	if options == nil || aws.StringValue(options.MarketType) != ec2.MarketTypeSpot || options.SpotOptions == nil {
+		return ""
+	}
+	return aws.StringValue(options.SpotOptions.MaxPrice)
+}
+
+func volumeTags(specs []*ec2.LaunchTemplateTagSpecification) (map[string]string, bool) {
+	var (
+		tags  = make(map[string]string)
+		found bool
+	)
+	for _, spec := range specs {
+		if aws.StringValue(spec.ResourceType) != ec2.ResourceTypeVolume {
+			continue
+		}
+		found = true
+		for _, t := range spec.Tags {
+			tags[aws.StringValue(t.Key)] = aws.StringValue(t.Value)
+		}
+	}
+	return tags, found
+}
+
+func tagsDrifted(existing, desired map[string]string) bool {
+	if len(existing) != len(desired) {
+		return true
+	}
+	for k, v := range desired {
+		if existingValue, ok := existing[k]; !ok || existingValue != v {
+			return true
+		}
+	}
+	return false
+}
+
 func sortTemplateDevices(devices []*ec2.LaunchTemplateBlockDeviceMapping) []*ec2.LaunchTemplateBlockDeviceMapping {
 	if len(devices) == 0 {
 		return []*ec2.LaunchTemplateBlockDeviceMapping{}
@@ -77,15 +77,21 @@ type MockEc2Client struct {
 	DeleteLaunchTemplateCallCount         int
 	LaunchTemplates                       []*ec2.LaunchTemplate
 	LaunchTemplateVersions                []*ec2.LaunchTemplateVersion
+	CreateLaunchTemplateInput             *ec2.CreateLaunchTemplateInput
+	CreateLaunchTemplateVersionInput      *ec2.CreateLaunchTemplateVersionInput
+	ModifyLaunchTemplateInput             *ec2.ModifyLaunchTemplateInput
+	DeletedLaunchTemplateVersions         []string
 }
 
 func (c *MockEc2Client) CreateLaunchTemplate(input *ec2.CreateLaunchTemplateInput) (*ec2.CreateLaunchTemplateOutput, error) {
 	c.CreateLaunchTemplateCallCount++
+	c.CreateLaunchTemplateInput = input
 	return &ec2.CreateLaunchTemplateOutput{}, c.CreateLaunchTemplateErr
 }
 
 func (c *MockEc2Client) DeleteLaunchTemplateVersions(input *ec2.DeleteLaunchTemplateVersionsInput) (*ec2.DeleteLaunchTemplateVersionsOutput, error) {
 	c.DeletedLaunchTemplateVersionCount = len(input.Versions)
+	c.DeletedLaunchTemplateVersions = aws.StringValueSlice(input.Versions)
 	c.DeleteLaunchTemplateVersionsCallCount++
 	return &ec2.DeleteLaunchTemplateVersionsOutput{}, nil
 }
@@ -97,6 +103,7 @@ func (c *MockEc2Client) DeleteLaunchTemplate(input *ec2.DeleteLaunchTemplateInpu
 
 func (c *MockEc2Client) ModifyLaunchTemplate(input *ec2.ModifyLaunchTemplateInput) (*ec2.ModifyLaunchTemplateOutput, error) {
 	c.ModifyLaunchTemplateCallCount++
+	c.ModifyLaunchTemplateInput = input
 	out := &ec2.ModifyLaunchTemplateOutput{
 		LaunchTemplate: MockLaunchTemplate(*input.LaunchTemplateName),
 	}
@@ -105,6 +112,7 @@ func (c *MockEc2Client) ModifyLaunchTemplate(input *ec2.ModifyLaunchTemplateInpu
 
 func (c *MockEc2Client) CreateLaunchTemplateVersion(input *ec2.CreateLaunchTemplateVersionInput) (*ec2.CreateLaunchTemplateVersionOutput, error) {
 	c.CreateLaunchTemplateVersionCallCount++
+	c.CreateLaunchTemplateVersionInput = input
 	out := &ec2.CreateLaunchTemplateVersionOutput{
 		LaunchTemplateVersion: &ec2.LaunchTemplateVersion{
 			VersionNumber: aws.Int64(1),
@@ -266,6 +274,8 @@ func TestLaunchTemplateCreate(t *testing.T) {
 		},
 	})
 	g.Expect(err).NotTo(gomega.HaveOccurred())
+	// an empty key pair is omitted
+	g.Expect(ec2Mock.CreateLaunchTemplateInput.LaunchTemplateData.KeyName).To(gomega.BeNil())
 
 	resourceList = append(resourceList, &ec2.LaunchTemplate{
 		LaunchTemplateName: aws.String("my-launch-template"),
@@ -320,6 +330,290 @@ func TestLaunchTemplateCreate(t *testing.T) {
 	g.Expect(err).NotTo(gomega.HaveOccurred())
 }
 
+func TestLaunchTemplateCreateWithInstanceMetadataTags(t *testing.T) {
+	var (
+		g       = gomega.NewGomegaWithT(t)
+		asgMock = &MockAutoScalingClient{}
+		ec2Mock = &MockEc2Client{}
+	)
+
+	w := awsprovider.AwsWorker{
+		AsgClient: asgMock,
+		Ec2Client: ec2Mock,
+	}
+
+	discoveryInput := &DiscoverConfigurationInput{
+		ScalingGroup: &autoscaling.Group{
+			AutoScalingGroupName: aws.String("my-asg"),
+			LaunchTemplate: &autoscaling.LaunchTemplateSpecification{
+				LaunchTemplateName: aws.String("my-launch-template"),
+			},
+		},
+	}
+
+	ec2Mock.LaunchTemplates = []*ec2.LaunchTemplate{MockLaunchTemplate("my-launch-template")}
+	lt, err := NewLaunchTemplate("", w, discoveryInput)
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	lt.LatestVersion = MockLaunchTemplateVersion()
+
+	err = lt.Create(&CreateConfigurationInput{
+		Name:           "my-launch-template",
+		SecurityGroups: []string{},
+		MetadataOptions: &v1alpha1.MetadataOptions{
+			HttpEndpoint:         "enabled",
+			HttpTokens:           "required",
+			HttpPutHopLimit:      1,
+			InstanceMetadataTags: true,
+		},
+	})
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(ec2Mock.CreateLaunchTemplateVersionCallCount).To(gomega.Equal(1))
+
+	metadataOptions := ec2Mock.CreateLaunchTemplateVersionInput.LaunchTemplateData.MetadataOptions
+	g.Expect(metadataOptions).NotTo(gomega.BeNil())
+	g.Expect(aws.StringValue(metadataOptions.InstanceMetadataTags)).To(gomega.Equal("enabled"))
+	g.Expect(aws.StringValue(metadataOptions.HttpEndpoint)).To(gomega.Equal("enabled"))
+}
+
+func TestLaunchTemplateCreateWithVolumeTags(t *testing.T) {
+	var (
+		g       = gomega.NewGomegaWithT(t)
+		asgMock = &MockAutoScalingClient{}
+		ec2Mock = &MockEc2Client{}
+	)
+
+	w := awsprovider.AwsWorker{
+		AsgClient: asgMock,
+		Ec2Client: ec2Mock,
+	}
+
+	discoveryInput := &DiscoverConfigurationInput{
+		ScalingGroup: &autoscaling.Group{
+			AutoScalingGroupName: aws.String("my-asg"),
+			LaunchTemplate: &autoscaling.LaunchTemplateSpecification{
+				LaunchTemplateName: aws.String("my-launch-template"),
+			},
+		},
+	}
+
+	ec2Mock.LaunchTemplates = []*ec2.LaunchTemplate{MockLaunchTemplate("my-launch-template")}
+	lt, err := NewLaunchTemplate("", w, discoveryInput)
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	lt.LatestVersion = MockLaunchTemplateVersion()
+
+	input := &CreateConfigurationInput{
+		Name:           "my-launch-template",
+		SecurityGroups: []string{},
+		VolumeTags: map[string]string{
+			"team":        "platform",
+			"cost-center": "1234",
+		},
+	}
+	// versions created before volume tagging seed the baseline and are not drifted
+	g.Expect(lt.Drifted(input)).To(gomega.BeFalse())
+
+	// the tags are applied with the next version that is created
+	input.ForceVersion = true
+	err = lt.Create(input)
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(ec2Mock.CreateLaunchTemplateVersionCallCount).To(gomega.Equal(1))
+
+	tagSpecifications := ec2Mock.CreateLaunchTemplateVersionInput.LaunchTemplateData.TagSpecifications
+	g.Expect(tagSpecifications).To(gomega.Equal([]*ec2.LaunchTemplateTagSpecificationRequest{
+		{
+			ResourceType: aws.String(ec2.ResourceTypeVolume),
+			Tags: []*ec2.Tag{
+				{Key: aws.String("cost-center"), Value: aws.String("1234")},
+				{Key: aws.String("team"), Value: aws.String("platform")},
+			},
+		},
+	}))
+
+	lt.LatestVersion = MockLaunchTemplateVersion()
+	lt.LatestVersion.LaunchTemplateData.TagSpecifications = []*ec2.LaunchTemplateTagSpecification{
+		{
+			ResourceType: aws.String(ec2.ResourceTypeVolume),
+			Tags: []*ec2.Tag{
+				{Key: aws.String("cost-center"), Value: aws.String("1234")},
+				{Key: aws.String("team"), Value: aws.String("platform")},
+			},
+		},
+	}
+	input.ForceVersion = false
+	g.Expect(lt.Drifted(input)).To(gomega.BeFalse())
+
+	input.VolumeTags["team"] = "storage"
+	g.Expect(lt.Drifted(input)).To(gomega.BeTrue())
+}
+
+func TestLaunchTemplateCreateWithCapacityReservation(t *testing.T) {
+	var (
+		g       = gomega.NewGomegaWithT(t)
+		asgMock = &MockAutoScalingClient{}
+		ec2Mock = &MockEc2Client{}
+	)
+
+	w := awsprovider.AwsWorker{
+		AsgClient: asgMock,
+		Ec2Client: ec2Mock,
+	}
+
+	discoveryInput := &DiscoverConfigurationInput{
+		ScalingGroup: &autoscaling.Group{
+			AutoScalingGroupName: aws.String("my-asg"),
+			LaunchTemplate: &autoscaling.LaunchTemplateSpecification{
+				LaunchTemplateName: aws.String("my-launch-template"),
+			},
+		},
+	}
+
+	ec2Mock.LaunchTemplates = []*ec2.LaunchTemplate{MockLaunchTemplate("my-launch-template")}
+	lt, err := NewLaunchTemplate("", w, discoveryInput)
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	lt.LatestVersion = MockLaunchTemplateVersion()
+
+	input := &CreateConfigurationInput{
+		Name:                  "my-launch-template",
+		SecurityGroups:        []string{},
+		CapacityReservationId: "cr-0123456789abcdef0",
+	}
+	g.Expect(lt.Drifted(input)).To(gomega.BeTrue())
+
+	err = lt.Create(input)
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(ec2Mock.CreateLaunchTemplateVersionCallCount).To(gomega.Equal(1))
+
+	spec := ec2Mock.CreateLaunchTemplateVersionInput.LaunchTemplateData.CapacityReservationSpecification
+	g.Expect(spec).NotTo(gomega.BeNil())
+	g.Expect(spec.CapacityReservationPreference).To(gomega.BeNil())
+	g.Expect(aws.StringValue(spec.CapacityReservationTarget.CapacityReservationId)).To(gomega.Equal("cr-0123456789abcdef0"))
+
+	lt.LatestVersion = MockLaunchTemplateVersion()
+	lt.LatestVersion.LaunchTemplateData.CapacityReservationSpecification = &ec2.LaunchTemplateCapacityReservationSpecificationResponse{
+		CapacityReservationTarget: &ec2.CapacityReservationTargetResponse{
+			CapacityReservationId: aws.String("cr-0123456789abcdef0"),
+		},
+	}
+	g.Expect(lt.Drifted(input)).To(gomega.BeFalse())
+
+	input.CapacityReservationId = ""
+	input.CapacityReservationPreference = v1alpha1.CapacityReservationPreferenceNone
+	g.Expect(lt.Drifted(input)).To(gomega.BeTrue())
+}
+
+func TestLaunchTemplateCreateWithSpotPrice(t *testing.T) {
+	var (
+		g       = gomega.NewGomegaWithT(t)
+		asgMock = &MockAutoScalingClient{}
+		ec2Mock = &MockEc2Client{}
+	)
+
+	w := awsprovider.AwsWorker{
+		AsgClient: asgMock,
+		Ec2Client: ec2Mock,
+	}
+
+	discoveryInput := &DiscoverConfigurationInput{
+		ScalingGroup: &autoscaling.Group{
+			AutoScalingGroupName: aws.String("my-asg"),
+			LaunchTemplate: &autoscaling.LaunchTemplateSpecification{
+				LaunchTemplateName: aws.String("my-launch-template"),
+			},
+		},
+	}
+
+	ec2Mock.LaunchTemplates = []*ec2.LaunchTemplate{MockLaunchTemplate("my-launch-template")}
+	lt, err := NewLaunchTemplate("", w, discoveryInput)
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	lt.LatestVersion = MockLaunchTemplateVersion()
+	lt.LatestVersion.LaunchTemplateData.InstanceMarketOptions = &ec2.LaunchTemplateInstanceMarketOptions{
+		MarketType: aws.String(ec2.MarketTypeSpot),
+		SpotOptions: &ec2.LaunchTemplateSpotMarketOptions{
+			MaxPrice: aws.String("0.05"),
+		},
+	}
+
+	input := &CreateConfigurationInput{
+		Name:           "my-launch-template",
+		SecurityGroups: []string{},
+		SpotPrice:      "0.05",
+	}
+	g.Expect(lt.Drifted(input)).To(gomega.BeFalse())
+
+	// a spot price change creates a new version of the existing launch template
+	input.SpotPrice = "0.07"
+	g.Expect(lt.Drifted(input)).To(gomega.BeTrue())
+
+	err = lt.Create(input)
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(ec2Mock.CreateLaunchTemplateCallCount).To(gomega.Equal(0))
+	g.Expect(ec2Mock.CreateLaunchTemplateVersionCallCount).To(gomega.Equal(1))
+	g.Expect(aws.StringValue(ec2Mock.CreateLaunchTemplateVersionInput.LaunchTemplateName)).To(gomega.Equal("my-launch-template"))
+
+	options := ec2Mock.CreateLaunchTemplateVersionInput.LaunchTemplateData.InstanceMarketOptions
+	g.Expect(options).NotTo(gomega.BeNil())
+	g.Expect(aws.StringValue(options.MarketType)).To(gomega.Equal(ec2.MarketTypeSpot))
+	g.Expect(aws.StringValue(options.SpotOptions.MaxPrice)).To(gomega.Equal("0.07"))
+
+	// removing the spot price switches back to on-demand
+	lt.LatestVersion = MockLaunchTemplateVersion()
+	lt.LatestVersion.LaunchTemplateData.InstanceMarketOptions = &ec2.LaunchTemplateInstanceMarketOptions{
+		MarketType: aws.String(ec2.MarketTypeSpot),
+		SpotOptions: &ec2.LaunchTemplateSpotMarketOptions{
+			MaxPrice: aws.String("0.07"),
+		},
+	}
+	g.Expect(lt.Drifted(input)).To(gomega.BeFalse())
+	input.SpotPrice = ""
+	g.Expect(lt.Drifted(input)).To(gomega.BeTrue())
+}
+
+func TestLaunchTemplateCreateForceVersion(t *testing.T) {
+	var (
+		g       = gomega.NewGomegaWithT(t)
+		asgMock = &MockAutoScalingClient{}
+		ec2Mock = &MockEc2Client{}
+	)
+
+	w := awsprovider.AwsWorker{
+		AsgClient: asgMock,
+		Ec2Client: ec2Mock,
+	}
+
+	discoveryInput := &DiscoverConfigurationInput{
+		ScalingGroup: &autoscaling.Group{
+			AutoScalingGroupName: aws.String("my-asg"),
+			LaunchTemplate: &autoscaling.LaunchTemplateSpecification{
+				LaunchTemplateName: aws.String("my-launch-template"),
+			},
+		},
+	}
+
+	ec2Mock.LaunchTemplates = []*ec2.LaunchTemplate{MockLaunchTemplate("my-launch-template")}
+	lt, err := NewLaunchTemplate("", w, discoveryInput)
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	lt.LatestVersion = MockLaunchTemplateVersion()
+
+	input := &CreateConfigurationInput{
+		Name:           "my-launch-template",
+		SecurityGroups: []string{},
+	}
+	g.Expect(lt.Drifted(input)).To(gomega.BeFalse())
+
+	// a template which has not drifted is left as is
+	err = lt.Create(input)
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(ec2Mock.CreateLaunchTemplateVersionCallCount).To(gomega.Equal(0))
+
+	// forcing a version creates one regardless of drift
+	lt.LatestVersion = MockLaunchTemplateVersion()
+	input.ForceVersion = true
+	err = lt.Create(input)
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(ec2Mock.CreateLaunchTemplateCallCount).To(gomega.Equal(0))
+	g.Expect(ec2Mock.CreateLaunchTemplateVersionCallCount).To(gomega.Equal(1))
+}
+
 func TestLaunchTemplateDelete(t *testing.T) {
 	var (
 		g       = gomega.NewGomegaWithT(t)
@@ -435,6 +729,204 @@ func TestLaunchTemplateDelete(t *testing.T) {
 	ec2Mock.DeleteLaunchTemplateErr = nil
 }
 
+func TestLaunchTemplateDeleteOrphanedVersions(t *testing.T) {
+	var (
+		g       = gomega.NewGomegaWithT(t)
+		asgMock = &MockAutoScalingClient{}
+		ec2Mock = &MockEc2Client{}
+	)
+
+	w := awsprovider.AwsWorker{
+		AsgClient: asgMock,
+		Ec2Client: ec2Mock,
+	}
+
+	scalingGroup := &autoscaling.Group{
+		AutoScalingGroupName: aws.String("my-asg"),
+		LaunchTemplate: &autoscaling.LaunchTemplateSpecification{
+			LaunchTemplateName: aws.String("my-launch-template"),
+			Version:            aws.String("$Latest"),
+		},
+		Instances: []*autoscaling.Instance{
+			MockLaunchTemplateScalingInstance("i-1111", "my-launch-template", "2"),
+			MockLaunchTemplateScalingInstance("i-2222", "other-launch-template", "4"),
+		},
+	}
+
+	now := time.Now()
+	versions := make([]*ec2.LaunchTemplateVersion, 0)
+	for i := 1; i <= 8; i++ {
+		versions = append(versions, &ec2.LaunchTemplateVersion{
+			LaunchTemplateName: aws.String("my-launch-template"),
+			VersionNumber:      aws.Int64(int64(i)),
+			DefaultVersion:     aws.Bool(i == 3),
+			CreateTime:         aws.Time(now.Add(time.Duration(i-10) * time.Minute)),
+		})
+	}
+
+	ec2Mock.LaunchTemplateVersions = versions
+	ec2Mock.LaunchTemplates = []*ec2.LaunchTemplate{
+		{
+			LaunchTemplateName:   aws.String("my-launch-template"),
+			LatestVersionNumber:  aws.Int64(8),
+			DefaultVersionNumber: aws.Int64(3),
+		},
+	}
+
+	lt, err := NewLaunchTemplate("", w, &DiscoverConfigurationInput{ScalingGroup: scalingGroup})
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+
+	// versions 1-6 are outside the retention window, 2 is used by an instance and 3 is the default
+	err = lt.Delete(&DeleteConfigurationInput{
+		Name:           "my-launch-template",
+		RetainVersions: 2,
+		ScalingGroup:   scalingGroup,
+	})
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(ec2Mock.DeleteLaunchTemplateVersionsCallCount).To(gomega.Equal(1))
+	g.Expect(ec2Mock.DeletedLaunchTemplateVersions).To(gomega.Equal([]string{"1", "4", "5", "6"}))
+	ec2Mock.DeleteLaunchTemplateVersionsCallCount = 0
+
+	// nothing is deleted when all versions outside the retention window are referenced
+	scalingGroup.Instances = []*autoscaling.Instance{
+		MockLaunchTemplateScalingInstance("i-1111", "my-launch-template", "1"),
+		MockLaunchTemplateScalingInstance("i-2222", "my-launch-template", "2"),
+	}
+	err = lt.Delete(&DeleteConfigurationInput{
+		Name:           "my-launch-template",
+		RetainVersions: 5,
+		ScalingGroup:   scalingGroup,
+	})
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(ec2Mock.DeleteLaunchTemplateVersionsCallCount).To(gomega.Equal(0))
+}
+
+func TestLaunchTemplateDeleteDescribedVersions(t *testing.T) {
+	var (
+		g           = gomega.NewGomegaWithT(t)
+		asgMock     = &MockAutoScalingClient{}
+		ec2Mock     = &MockEc2Client{}
+		description = "created by instance-manager for instance-manager/my-instance-group"
+	)
+
+	w := awsprovider.AwsWorker{
+		AsgClient: asgMock,
+		Ec2Client: ec2Mock,
+	}
+
+	scalingGroup := &autoscaling.Group{
+		AutoScalingGroupName: aws.String("my-asg"),
+		LaunchTemplate: &autoscaling.LaunchTemplateSpecification{
+			LaunchTemplateName: aws.String("existing-launch-template"),
+			Version:            aws.String("$Latest"),
+		},
+	}
+
+	// versions 2 and 4 were created by the user, 3 and 5-8 by the controller
+	now := time.Now()
+	versions := make([]*ec2.LaunchTemplateVersion, 0)
+	for i := 1; i <= 8; i++ {
+		version := &ec2.LaunchTemplateVersion{
+			LaunchTemplateName: aws.String("existing-launch-template"),
+			VersionNumber:      aws.Int64(int64(i)),
+			DefaultVersion:     aws.Bool(i == 1),
+			CreateTime:         aws.Time(now.Add(time.Duration(i-10) * time.Minute)),
+		}
+		if i == 3 || i > 4 {
+			version.VersionDescription = aws.String(description)
+		}
+		versions = append(versions, version)
+	}
+
+	ec2Mock.LaunchTemplateVersions = versions
+	ec2Mock.LaunchTemplates = []*ec2.LaunchTemplate{
+		{
+			LaunchTemplateName:   aws.String("existing-launch-template"),
+			LatestVersionNumber:  aws.Int64(8),
+			DefaultVersionNumber: aws.Int64(1),
+		},
+	}
+
+	lt, err := NewLaunchTemplate("", w, &DiscoverConfigurationInput{ScalingGroup: scalingGroup})
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+
+	// the retention window only counts the versions created by the controller
+	err = lt.Delete(&DeleteConfigurationInput{
+		Name:               "existing-launch-template",
+		RetainVersions:     2,
+		ScalingGroup:       scalingGroup,
+		VersionDescription: description,
+	})
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(ec2Mock.DeleteLaunchTemplateVersionsCallCount).To(gomega.Equal(1))
+	g.Expect(ec2Mock.DeletedLaunchTemplateVersions).To(gomega.Equal([]string{"3", "5", "6"}))
+}
+
+func TestLaunchTemplateSetLatestAsDefault(t *testing.T) {
+	var (
+		g       = gomega.NewGomegaWithT(t)
+		asgMock = &MockAutoScalingClient{}
+		ec2Mock = &MockEc2Client{}
+	)
+
+	w := awsprovider.AwsWorker{
+		AsgClient: asgMock,
+		Ec2Client: ec2Mock,
+	}
+
+	scalingGroup := &autoscaling.Group{
+		AutoScalingGroupName: aws.String("my-asg"),
+		LaunchTemplate: &autoscaling.LaunchTemplateSpecification{
+			LaunchTemplateName: aws.String("my-launch-template"),
+			Version:            aws.String("$Default"),
+		},
+	}
+
+	now := time.Now()
+	versions := make([]*ec2.LaunchTemplateVersion, 0)
+	for i := 1; i <= 8; i++ {
+		versions = append(versions, &ec2.LaunchTemplateVersion{
+			LaunchTemplateName: aws.String("my-launch-template"),
+			VersionNumber:      aws.Int64(int64(i)),
+			DefaultVersion:     aws.Bool(i == 3),
+			CreateTime:         aws.Time(now.Add(time.Duration(i-10) * time.Minute)),
+		})
+	}
+
+	ec2Mock.LaunchTemplateVersions = versions
+	ec2Mock.LaunchTemplates = []*ec2.LaunchTemplate{
+		{
+			LaunchTemplateName:   aws.String("my-launch-template"),
+			LatestVersionNumber:  aws.Int64(8),
+			DefaultVersionNumber: aws.Int64(3),
+		},
+	}
+
+	lt, err := NewLaunchTemplate("", w, &DiscoverConfigurationInput{ScalingGroup: scalingGroup})
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+
+	modified, err := lt.SetLatestAsDefault("my-launch-template")
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(modified).To(gomega.BeTrue())
+	g.Expect(ec2Mock.ModifyLaunchTemplateCallCount).To(gomega.Equal(1))
+	g.Expect(aws.StringValue(ec2Mock.ModifyLaunchTemplateInput.DefaultVersion)).To(gomega.Equal("8"))
+
+	// latest version is already the default
+	modified, err = lt.SetLatestAsDefault("my-launch-template")
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(modified).To(gomega.BeFalse())
+	g.Expect(ec2Mock.ModifyLaunchTemplateCallCount).To(gomega.Equal(1))
+
+	// the previous default version is no longer protected from pruning
+	err = lt.Delete(&DeleteConfigurationInput{
+		Name:           "my-launch-template",
+		RetainVersions: 2,
+		ScalingGroup:   scalingGroup,
+	})
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(ec2Mock.DeletedLaunchTemplateVersions).To(gomega.Equal([]string{"1", "2", "3", "4", "5", "6"}))
+}
+
 func TestLaunchTemplateRotationNeeded(t *testing.T) {
 	var (
 		g       = gomega.NewGomegaWithT(t)
@@ -512,6 +1004,11 @@ func TestLaunchTemplateDrifted(t *testing.T) {
 			},
 		},
 	}
+
+	// versions created without a key pair omit the key name
+	withoutKeyName := MockLaunchTemplateVersion()
+	withoutKeyName.LaunchTemplateData.KeyName = nil
+
 	tests := []struct {
 		launchTemplate *ec2.LaunchTemplate
 		latestVersion  *ec2.LaunchTemplateVersion
@@ -524,6 +1021,12 @@ func TestLaunchTemplateDrifted(t *testing.T) {
 			input:          &CreateConfigurationInput{},
 			shouldDrift:    false,
 		},
+		{
+			launchTemplate: MockLaunchTemplate("my-launch-template"),
+			latestVersion:  withoutKeyName,
+			input:          &CreateConfigurationInput{},
+			shouldDrift:    false,
+		},
 		{
 			launchTemplate: MockLaunchTemplate("my-launch-template"),
 			latestVersion:  nil,
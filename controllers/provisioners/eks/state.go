@@ -16,8 +16,15 @@ limitations under the License.
 package eks
 
 import (
+	"context"
+	"fmt"
+	"time"
+
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/keikoproj/instance-manager/api/instancemgr/v1alpha1"
+	kubeprovider "github.com/keikoproj/instance-manager/controllers/providers/kubernetes"
+	"github.com/keikoproj/instance-manager/controllers/provisioners"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 const (
@@ -58,6 +65,13 @@ func (ctx *EksInstanceGroupContext) StateDiscovery() {
 		}
 	} else {
 		// resource is not being deleted
+		if !state.IsClusterActive() {
+			// cluster is not active, e.g. mid-update, defer create/update until it is
+			status := aws.StringValue(state.GetCluster().Status)
+			ctx.Log.Info("cluster is not active, deferring reconcile", "instancegroup", instanceGroup.NamespacedName(), "status", status)
+			state.Publisher.Publish(kubeprovider.ClusterNotActiveEvent, "instancegroup", instanceGroup.NamespacedName(), "status", status)
+			return
+		}
 		if provisioned {
 			// scaling group exists
 			ctx.SetState(v1alpha1.ReconcileInitUpdate)
@@ -69,7 +83,129 @@ func (ctx *EksInstanceGroupContext) StateDiscovery() {
 
 }
 
+// IsDeferred returns true if the reconcile was deferred during state discovery because the cluster is not active
+func (ctx *EksInstanceGroupContext) IsDeferred() bool {
+	var (
+		instanceGroup = ctx.GetInstanceGroup()
+		state         = ctx.GetDiscoveredState()
+	)
+	return instanceGroup.GetState() == v1alpha1.ReconcileInit && !state.IsClusterActive()
+}
+
+// IsWaiting returns true while instances wait on lifecycle hook automation executions, their lifecycle actions are
+// completed by the update which only runs when the instance group is reconciled again. An instance group whose nodes
+// did not become ready in time is also requeued, so that it recovers from the error state once they are ready
+func (ctx *EksInstanceGroupContext) IsWaiting() bool {
+	state := ctx.GetDiscoveredState()
+	return state.PendingLifecycleActions > 0 || state.NodeReadinessTimedOut
+}
+
+// GetScalingMetrics returns the desired capacity of the discovered scaling group and the number of its instances which
+// are ready nodes. A reconcile shortcut reports the desired capacity of the last full reconcile and its ready role nodes
+func (ctx *EksInstanceGroupContext) GetScalingMetrics() (int, int, bool) {
+	var (
+		state        = ctx.GetDiscoveredState()
+		scalingGroup = state.ScalingGroup
+		nodes        = state.GetClusterNodes()
+	)
+
+	// GetScalingGroup returns an empty group when none was discovered
+	if scalingGroup == nil {
+		if roleNodes := state.GetRoleNodes(); roleNodes != nil {
+			return ctx.LastReconcile.Desired, len(roleNodes.Items), true
+		}
+		return 0, 0, false
+	}
+
+	desired := int(aws.Int64Value(scalingGroup.DesiredCapacity))
+	if nodes == nil {
+		return desired, 0, true
+	}
+
+	instanceIds := make([]string, 0)
+	for _, instance := range scalingGroup.Instances {
+		instanceIds = append(instanceIds, aws.StringValue(instance.InstanceId))
+	}
+	return desired, len(kubeprovider.GetReadyNodesByInstance(instanceIds, nodes)), true
+}
+
 func (ctx *EksInstanceGroupContext) IsReady() bool {
 	instanceGroup := ctx.GetInstanceGroup()
 	return instanceGroup.GetState() == v1alpha1.ReconcileModified
 }
+
+// ReconcileShortcut returns true if cloud discovery can be skipped because the instance group was fully reconciled within
+// the shortcut interval, its desired state has not changed since and its nodes are ready. A full reconcile is forced once
+// the shortcut has been taken the configured number of times in a row, so that drift is still detected periodically
+func (ctx *EksInstanceGroupContext) ReconcileShortcut() bool {
+	var (
+		instanceGroup = ctx.GetInstanceGroup()
+		lastReconcile = ctx.LastReconcile.Time
+	)
+
+	if ctx.ReconcileShortcutInterval == 0 || ctx.PreviousState != v1alpha1.ReconcileReady {
+		return false
+	}
+
+	if !instanceGroup.GetDeletionTimestamp().IsZero() {
+		return false
+	}
+
+	if lastReconcile.IsZero() || time.Since(lastReconcile) > ctx.ReconcileShortcutInterval {
+		return false
+	}
+
+	if ctx.LastReconcile.Shortcuts >= ctx.ReconcileShortcutCount {
+		return false
+	}
+
+	if ctx.LastReconcile.Hash != provisioners.GetReconcileHash(instanceGroup) {
+		return false
+	}
+
+	ready, err := ctx.IsRoleNodesReady()
+	if err != nil {
+		ctx.Log.Error(err, "failed to verify node readiness", "instancegroup", instanceGroup.NamespacedName())
+		return false
+	}
+	if !ready {
+		return false
+	}
+
+	ctx.Log.Info("instance group unchanged, skipping cloud discovery", "instancegroup", instanceGroup.NamespacedName(), "lastReconcile", lastReconcile)
+	ctx.SetState(v1alpha1.ReconcileReady)
+	return true
+}
+
+// IsRoleNodesReady returns true if all nodes labeled with the instance group's role are ready, and at least as many
+// nodes as the group's minimum size exist
+func (ctx *EksInstanceGroupContext) IsRoleNodesReady() (bool, error) {
+	var (
+		instanceGroup = ctx.GetInstanceGroup()
+		annotations   = instanceGroup.GetAnnotations()
+		status        = instanceGroup.GetStatus()
+	)
+
+	// nodes cannot be selected by role when the default labels are overridden
+	if _, ok := annotations[OverrideDefaultLabelsAnnotation]; ok {
+		return false, nil
+	}
+
+	selector := fmt.Sprintf("%v=%v", RoleNewLabel, instanceGroup.GetName())
+	nodes, err := ctx.KubernetesClient.Kubernetes.CoreV1().Nodes().List(context.Background(), metav1.ListOptions{LabelSelector: selector})
+	if err != nil {
+		return false, err
+	}
+
+	if len(nodes.Items) < status.GetCurrentMin() {
+		return false, nil
+	}
+
+	for _, node := range nodes.Items {
+		if !kubeprovider.IsNodeReady(node) {
+			return false, nil
+		}
+	}
+	ctx.GetDiscoveredState().SetRoleNodes(nodes)
+	return true, nil
+}
@@ -16,14 +16,22 @@ limitations under the License.
 package eks
 
 import (
+	"context"
 	"testing"
 	"time"
 
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/autoscaling"
+	"github.com/aws/aws-sdk-go/service/eks"
+	"github.com/aws/aws-sdk-go/service/iam"
 	"github.com/keikoproj/instance-manager/api/instancemgr/v1alpha1"
+	"github.com/keikoproj/instance-manager/controllers/common"
+	kubeprovider "github.com/keikoproj/instance-manager/controllers/providers/kubernetes"
+	"github.com/keikoproj/instance-manager/controllers/provisioners"
 	"github.com/onsi/gomega"
 )
 
@@ -79,6 +87,60 @@ func TestStateDiscovery(t *testing.T) {
 	}
 }
 
+func TestStateDiscoveryClusterNotActive(t *testing.T) {
+	var (
+		g       = gomega.NewGomegaWithT(t)
+		k       = MockKubernetesClientSet()
+		ig      = MockInstanceGroup()
+		asgMock = NewAutoScalingMocker()
+		iamMock = NewIamMocker()
+		eksMock = NewEksMocker()
+		ec2Mock = NewEc2Mocker()
+		ssmMock = NewSsmMocker()
+	)
+
+	w := MockAwsWorker(asgMock, iamMock, eksMock, ec2Mock, ssmMock)
+	ctx := MockContext(ig, k, w)
+
+	cluster := MockEksCluster("1.18")
+	cluster.Status = aws.String(eks.ClusterStatusUpdating)
+
+	ig.SetState(v1alpha1.ReconcileInit)
+	ctx.SetDiscoveredState(&DiscoveredState{
+		Publisher: kubeprovider.EventPublisher{
+			Client: k.Kubernetes,
+		},
+		Provisioned:  true,
+		ScalingGroup: &autoscaling.Group{},
+		Cluster:      cluster,
+	})
+
+	// create/update is deferred while the cluster is updating
+	ctx.StateDiscovery()
+	g.Expect(ctx.GetState()).To(gomega.Equal(v1alpha1.ReconcileInit))
+	g.Expect(ctx.IsDeferred()).To(gomega.BeTrue())
+	g.Expect(provisioners.IsRetryable(ig)).To(gomega.BeTrue())
+
+	events, err := k.Kubernetes.CoreV1().Events("").List(context.Background(), metav1.ListOptions{})
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(events.Items).To(gomega.HaveLen(1))
+	g.Expect(events.Items[0].Reason).To(gomega.Equal(string(kubeprovider.ClusterNotActiveEvent)))
+
+	// deletion is not deferred
+	ig.SetDeletionTimestamp(&metav1.Time{Time: time.Now()})
+	ctx.StateDiscovery()
+	g.Expect(ctx.GetState()).To(gomega.Equal(v1alpha1.ReconcileInitDelete))
+	g.Expect(ctx.IsDeferred()).To(gomega.BeFalse())
+	ig.SetDeletionTimestamp(nil)
+
+	// reconcile proceeds once the cluster is active
+	cluster.Status = aws.String(eks.ClusterStatusActive)
+	ig.SetState(v1alpha1.ReconcileInit)
+	ctx.StateDiscovery()
+	g.Expect(ctx.GetState()).To(gomega.Equal(v1alpha1.ReconcileInitUpdate))
+	g.Expect(ctx.IsDeferred()).To(gomega.BeFalse())
+}
+
 func TestIsReady(t *testing.T) {
 	var (
 		g       = gomega.NewGomegaWithT(t)
@@ -116,3 +178,158 @@ func TestIsReady(t *testing.T) {
 	}
 
 }
+
+func TestReconcileShortcut(t *testing.T) {
+	var (
+		g       = gomega.NewGomegaWithT(t)
+		k       = MockKubernetesClientSet()
+		ig      = MockInstanceGroup()
+		asgMock = NewAutoScalingMocker()
+		iamMock = NewIamMocker()
+		eksMock = NewEksMocker()
+		ec2Mock = NewEc2Mocker()
+		ssmMock = NewSsmMocker()
+	)
+
+	w := MockAwsWorker(asgMock, iamMock, eksMock, ec2Mock, ssmMock)
+	record := provisioners.ReconcileRecord{}
+
+	iamMock.Role = &iam.Role{
+		RoleName: aws.String("some-role"),
+		Arn:      aws.String("some-arn"),
+	}
+	eksMock.EksCluster = &eks.Cluster{
+		ResourcesVpcConfig: &eks.VpcConfigResponse{
+			VpcId: aws.String("vpc-1234567890"),
+		},
+	}
+
+	node := MockNode("i-0000000000", corev1.ConditionTrue)
+	node.Labels = map[string]string{RoleNewLabel: ig.GetName()}
+	_, err := k.Kubernetes.CoreV1().Nodes().Create(context.Background(), node, metav1.CreateOptions{})
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+
+	// mimics the controller, cloud discovery only runs when the shortcut is not taken
+	reconcile := func(interval time.Duration) *EksInstanceGroupContext {
+		ctx := New(provisioners.ProvisionerInput{
+			AwsWorker:                 w,
+			Kubernetes:                k,
+			InstanceGroup:             ig,
+			Log:                       ctrl.Log.WithName("unit-test").WithName("InstanceGroup"),
+			Metrics:                   common.NewMetricsCollector(),
+			ReconcileShortcutInterval: interval,
+			ReconcileShortcutCount:    2,
+			LastReconcile:             record,
+		})
+		ctx.DiscoveredState = &DiscoveredState{
+			Publisher: kubeprovider.EventPublisher{},
+			Cluster:   MockEksCluster("1.18"),
+		}
+		if ctx.ReconcileShortcut() {
+			record.Shortcuts++
+		} else {
+			_ = ctx.CloudDiscovery()
+			ctx.SetState(v1alpha1.ReconcileReady)
+			record = provisioners.ReconcileRecord{Hash: provisioners.GetReconcileHash(ig), Time: time.Now()}
+		}
+		return ctx
+	}
+
+	ig.SetState(v1alpha1.ReconcileReady)
+	record = provisioners.ReconcileRecord{Hash: provisioners.GetReconcileHash(ig), Time: time.Now()}
+
+	// unchanged within the interval, AWS is not called
+	reconcile(time.Minute)
+	g.Expect(asgMock.DescribeAutoScalingGroupsCallCount).To(gomega.BeZero())
+	g.Expect(ig.GetState()).To(gomega.Equal(v1alpha1.ReconcileReady))
+	reconcile(time.Minute)
+	g.Expect(asgMock.DescribeAutoScalingGroupsCallCount).To(gomega.BeZero())
+	g.Expect(record.Shortcuts).To(gomega.Equal(2))
+
+	// every Nth reconcile runs a full discovery to detect drift
+	reconcile(time.Minute)
+	g.Expect(asgMock.DescribeAutoScalingGroupsCallCount).To(gomega.BeEquivalentTo(1))
+	g.Expect(record.Shortcuts).To(gomega.BeZero())
+	reconcile(time.Minute)
+	g.Expect(asgMock.DescribeAutoScalingGroupsCallCount).To(gomega.BeEquivalentTo(1))
+
+	// disabled
+	reconcile(0)
+	g.Expect(asgMock.DescribeAutoScalingGroupsCallCount).To(gomega.BeEquivalentTo(2))
+
+	// interval elapsed since the last full reconcile
+	record = provisioners.ReconcileRecord{Hash: provisioners.GetReconcileHash(ig), Time: time.Now().Add(-2 * time.Minute)}
+	reconcile(time.Minute)
+	g.Expect(asgMock.DescribeAutoScalingGroupsCallCount).To(gomega.BeEquivalentTo(3))
+
+	// spec changed
+	ig.GetEKSSpec().MaxSize = 10
+	reconcile(time.Minute)
+	g.Expect(asgMock.DescribeAutoScalingGroupsCallCount).To(gomega.BeEquivalentTo(4))
+	reconcile(time.Minute)
+	g.Expect(asgMock.DescribeAutoScalingGroupsCallCount).To(gomega.BeEquivalentTo(4))
+
+	// nodes not ready
+	node.Status.Conditions[0].Status = corev1.ConditionFalse
+	_, err = k.Kubernetes.CoreV1().Nodes().Update(context.Background(), node, metav1.UpdateOptions{})
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	reconcile(time.Minute)
+	g.Expect(asgMock.DescribeAutoScalingGroupsCallCount).To(gomega.BeEquivalentTo(5))
+}
+
+func TestGetScalingMetrics(t *testing.T) {
+	var (
+		g       = gomega.NewGomegaWithT(t)
+		k       = MockKubernetesClientSet()
+		ig      = MockInstanceGroup()
+		asgMock = NewAutoScalingMocker()
+		iamMock = NewIamMocker()
+		eksMock = NewEksMocker()
+		ec2Mock = NewEc2Mocker()
+		ssmMock = NewSsmMocker()
+	)
+
+	w := MockAwsWorker(asgMock, iamMock, eksMock, ec2Mock, ssmMock)
+	ctx := MockContext(ig, k, w)
+	state := ctx.GetDiscoveredState()
+
+	// nothing is reported before the scaling group is discovered
+	_, _, ok := ctx.GetScalingMetrics()
+	g.Expect(ok).To(gomega.BeFalse())
+
+	state.SetScalingGroup(&autoscaling.Group{
+		DesiredCapacity: aws.Int64(3),
+		Instances: []*autoscaling.Instance{
+			{InstanceId: aws.String("i-1111")},
+			{InstanceId: aws.String("i-2222")},
+			{InstanceId: aws.String("i-3333")},
+		},
+	})
+	desired, readyNodes, ok := ctx.GetScalingMetrics()
+	g.Expect(ok).To(gomega.BeTrue())
+	g.Expect(desired).To(gomega.Equal(3))
+	g.Expect(readyNodes).To(gomega.BeZero())
+
+	// only ready nodes of the scaling group's instances are counted
+	state.SetClusterNodes(&corev1.NodeList{Items: []corev1.Node{
+		*MockNode("i-1111", corev1.ConditionTrue),
+		*MockNode("i-2222", corev1.ConditionFalse),
+		*MockNode("i-4444", corev1.ConditionTrue),
+	}})
+	desired, readyNodes, ok = ctx.GetScalingMetrics()
+	g.Expect(ok).To(gomega.BeTrue())
+	g.Expect(desired).To(gomega.Equal(3))
+	g.Expect(readyNodes).To(gomega.Equal(1))
+
+	// a shortcut reconcile reports the desired capacity of the last full reconcile and the ready role nodes
+	ctx.LastReconcile = provisioners.ReconcileRecord{Desired: 4}
+	ctx.SetDiscoveredState(&DiscoveredState{})
+	ctx.GetDiscoveredState().SetRoleNodes(&corev1.NodeList{Items: []corev1.Node{
+		*MockNode("i-1111", corev1.ConditionTrue),
+		*MockNode("i-2222", corev1.ConditionTrue),
+	}})
+	desired, readyNodes, ok = ctx.GetScalingMetrics()
+	g.Expect(ok).To(gomega.BeTrue())
+	g.Expect(desired).To(gomega.Equal(4))
+	g.Expect(readyNodes).To(gomega.Equal(2))
+}
@@ -18,8 +18,10 @@ package eks
 import (
 	"fmt"
 	"reflect"
+	"sort"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/pkg/errors"
 
@@ -50,12 +52,23 @@ func (ctx *EksInstanceGroupContext) Update() error {
 		mounts          = ctx.GetMountOpts()
 		userData        = ctx.GetBasicUserData(clusterName, args, kubeletArgs, userDataPayload, mounts)
 		sgs             = ctx.ResolveSecurityGroups()
-		spotPrice       = configuration.GetSpotPrice()
+		spotPrice       = ctx.GetSpotPrice()
 		placement       = configuration.GetPlacement()
 		metadataOptions = configuration.GetMetadataOptions()
 	)
 
 	ctx.SetState(v1alpha1.ReconcileModifying)
+	ctx.SetRenderedUserData(userData)
+	// the scaling group exists, so create progress is no longer relevant
+	ctx.RemoveCreateConditions()
+
+	// minSize, maxSize and baseCapacity of an existing scaling group are expressed in the unit it was created with,
+	// switching the unit would reinterpret them
+	unit := configuration.GetMixedInstancesPolicy().GetWeightedCapacityUnit()
+	if previous := status.GetWeightedCapacityUnit(); !common.StringEmpty(previous) && !strings.EqualFold(previous, unit) {
+		return errors.Errorf("mixedInstancesPolicy.weightedCapacityUnit cannot be changed from '%v' to '%v' while the scaling group exists", previous, unit)
+	}
+	status.SetWeightedCapacityUnit(unit)
 
 	// make sure our managed role exists if instance group has not provided one
 	err := ctx.CreateManagedRole()
@@ -64,23 +77,45 @@ func (ctx *EksInstanceGroupContext) Update() error {
 	}
 	instanceProfile := state.GetInstanceProfile()
 
+	// requeue instead of blocking while a new instance-profile propagates
+	if !ctx.AwsWorker.IsInstanceProfilePropagated(instanceProfile) {
+		ctx.Log.Info("waiting for instance-profile propagation", "instancegroup", instanceGroup.NamespacedName(), "instanceprofile", aws.StringValue(instanceProfile.InstanceProfileName))
+		return nil
+	}
+
+	if ctx.HasExistingLaunchTemplate() && !scalingConfig.Provisioned() {
+		return errors.Errorf("launch template %v does not exist", configuration.GetExistingLaunchTemplateName())
+	}
+
 	config := &scaling.CreateConfigurationInput{
-		Name:                  scalingConfig.Name(),
-		IamInstanceProfileArn: aws.StringValue(instanceProfile.Arn),
-		ImageId:               configuration.Image,
-		InstanceType:          configuration.InstanceType,
-		KeyName:               configuration.KeyPairName,
-		SecurityGroups:        sgs,
-		Volumes:               configuration.Volumes,
-		UserData:              userData,
-		SpotPrice:             spotPrice,
-		LicenseSpecifications: configuration.LicenseSpecifications,
-		Placement:             placement,
-		MetadataOptions:       metadataOptions,
+		Name:                          scalingConfig.Name(),
+		IamInstanceProfileArn:         aws.StringValue(instanceProfile.Arn),
+		ImageId:                       configuration.Image,
+		InstanceType:                  configuration.InstanceType,
+		KeyName:                       configuration.KeyPairName,
+		SecurityGroups:                sgs,
+		Volumes:                       configuration.Volumes,
+		UserData:                      userData,
+		SpotPrice:                     spotPrice,
+		LicenseSpecifications:         configuration.LicenseSpecifications,
+		Placement:                     placement,
+		MetadataOptions:               metadataOptions,
+		CapacityReservationId:         configuration.GetCapacityReservationId(),
+		CapacityReservationPreference: configuration.GetCapacityReservationPreference(),
+		VolumeTags:                    ctx.GetVolumeTags(),
+	}
+	ctx.SetLaunchTemplateOwnership(config)
+
+	// a changed force-upgrade token creates a new scaling configuration, instances of the previous configuration are
+	// then rotated by the upgrade strategy
+	forceUpgrade := ctx.IsForceUpgradeRequested()
+	if forceUpgrade {
+		ctx.Log.Info("force upgrade requested", "instancegroup", instanceGroup.NamespacedName(), "token", instanceGroup.GetAnnotations()[ForceUpgradeAnnotation])
+		config.ForceVersion = true
 	}
 
 	// create new launchconfig if it has drifted
-	if scalingConfig.Drifted(config) {
+	if scalingConfig.Drifted(config) || forceUpgrade {
 		if spec.IsLaunchConfiguration() || common.StringEmpty(config.Name) {
 			config.Name = fmt.Sprintf("%v-%v", ctx.ResourcePrefix, common.GetTimeString())
 		}
@@ -88,7 +123,26 @@ func (ctx *EksInstanceGroupContext) Update() error {
 		if err := scalingConfig.Create(config); err != nil {
 			return errors.Wrap(err, "failed to create scaling configuration")
 		}
+		if forceUpgrade {
+			status.SetLastForceUpgradeToken(instanceGroup.GetAnnotations()[ForceUpgradeAnnotation])
+		}
+	}
 
+	// the default version may have been moved by a failed reconcile or outside of the controller, the default version of
+	// an existing launch template is owned by the user
+	if launchTemplate, ok := scalingConfig.(*scaling.LaunchTemplate); ok && ctx.IsForceDefaultVersion() && !ctx.HasExistingLaunchTemplate() {
+		if _, err := launchTemplate.SetLatestAsDefault(config.Name); err != nil {
+			return errors.Wrap(err, "failed to set latest launch template version as default")
+		}
+	}
+
+	overrideRotationNeeded, err := ctx.UpdateOverrideTemplates(config)
+	if err != nil {
+		return errors.Wrap(err, "failed to update override launch templates")
+	}
+	if overrideRotationNeeded {
+		ctx.Log.Info("node rotation required for instance type overrides", "instancegroup", instanceGroup.NamespacedName(), "scalingconfig", config.Name)
+		rotationNeeded = true
 	}
 
 	if scalingConfig.RotationNeeded(&scaling.DiscoverConfigurationInput{
@@ -110,6 +164,12 @@ func (ctx *EksInstanceGroupContext) Update() error {
 		}
 	}
 
+	// re-apply the desired state if the scaling group was modified outside of the controller
+	if status.GetDriftDetected() {
+		ctx.Log.Info("scaling group has drifted from desired state", "instancegroup", instanceGroup.NamespacedName(), "scalinggroup", aws.StringValue(scalingGroup.AutoScalingGroupName))
+		state.Publisher.Publish(kubeprovider.ConfigurationDriftEvent, "instancegroup", instanceGroup.NamespacedName(), "scalinggroup", aws.StringValue(scalingGroup.AutoScalingGroupName))
+	}
+
 	// update scaling group
 	updated, err := ctx.UpdateScalingGroup(config.Name, &scalingConfig)
 	if err != nil {
@@ -133,20 +193,136 @@ func (ctx *EksInstanceGroupContext) Update() error {
 		ctx.Log.Info("failed to bootstrap role, will retry", "error", err, "instancegroup", instanceGroup.NamespacedName())
 	}
 
+	if err := ctx.UpdateNodeLabels(); err != nil {
+		return errors.Wrap(err, "failed to update node labels")
+	}
+
+	if err := ctx.UpdateNodeAnnotations(); err != nil {
+		return errors.Wrap(err, "failed to update node annotations")
+	}
+
+	if err := ctx.UpdateNodeTaints(); err != nil {
+		return errors.Wrap(err, "failed to update node taints")
+	}
+
+	if err := ctx.UpdateSourceDestCheck(); err != nil {
+		return errors.Wrap(err, "failed to update source/dest check")
+	}
+
+	if err := ctx.UpdateLifecycleHookDocuments(); err != nil {
+		return errors.Wrap(err, "failed to update lifecycle hook documents")
+	}
+
 	// update readiness conditions
 	nodesReady := ctx.UpdateNodeReadyCondition()
 	if nodesReady {
 		ctx.SetState(v1alpha1.ReconcileModified)
 	}
 	if rotationNeeded {
+		// defer disruptive updates until the maintenance window opens, requeue until then
+		if !instanceGroup.InMaintenanceWindow(time.Now()) {
+			ctx.Log.Info("deferring node rotation until maintenance window", "instancegroup", instanceGroup.NamespacedName(), "window", instanceGroup.GetAnnotations()[v1alpha1.MaintenanceWindowAnnotationKey])
+			ctx.SetState(v1alpha1.ReconcileModifying)
+			return ctx.UncordonDrainingNodes()
+		}
 		ctx.SetState(v1alpha1.ReconcileInitUpgrade)
 	} else {
 		status.SetStrategyRetryCount(0)
+		// a rotation which is no longer needed leaves no cordoned nodes behind
+		if err := ctx.UncordonDrainingNodes(); err != nil {
+			return errors.Wrap(err, "failed to uncordon nodes")
+		}
 	}
 
 	return nil
 }
 
+// UpdateOverrideTemplates creates or updates the launch templates of instance type overrides which use a custom image,
+// and returns whether instances of an overridden type need to be rotated
+func (ctx *EksInstanceGroupContext) UpdateOverrideTemplates(config *scaling.CreateConfigurationInput) (bool, error) {
+	var (
+		rotationNeeded bool
+		instanceGroup  = ctx.GetInstanceGroup()
+		spec           = instanceGroup.GetEKSSpec()
+		state          = ctx.GetDiscoveredState()
+		scalingGroup   = state.GetScalingGroup()
+		images         = ctx.GetOverrideImages()
+	)
+
+	if !spec.IsLaunchTemplate() || common.StringEmpty(config.Name) {
+		return false, nil
+	}
+
+	instanceTypes := make([]string, 0)
+	for t := range images {
+		instanceTypes = append(instanceTypes, t)
+	}
+	sort.Strings(instanceTypes)
+
+	templateNames := make([]string, 0)
+	for _, instanceType := range instanceTypes {
+		name := GetOverrideTemplateName(config.Name, instanceType)
+		templateNames = append(templateNames, name)
+
+		lt, err := scaling.NewLaunchTemplate(instanceGroup.NamespacedName(), ctx.AwsWorker, &scaling.DiscoverConfigurationInput{
+			TargetConfigName: name,
+		})
+		if err != nil {
+			return false, errors.Wrapf(err, "failed to discover override launch template %v", name)
+		}
+
+		// override templates are always created and owned by the controller
+		overrideConfig := *config
+		overrideConfig.Name = name
+		overrideConfig.TemplateTags = ctx.GetLaunchTemplateTags()
+		overrideConfig.Unowned = false
+		overrideConfig.SourceVersion = ""
+		overrideConfig.VersionDescription = ""
+		overrideConfig.ImageId = images[instanceType]
+		overrideConfig.InstanceType = instanceType
+
+		if err := lt.Create(&overrideConfig); err != nil {
+			return false, errors.Wrapf(err, "failed to create override launch template %v", name)
+		}
+
+		if err := lt.Delete(&scaling.DeleteConfigurationInput{
+			Name:           name,
+			RetainVersions: ctx.ConfigRetention,
+			ScalingGroup:   scalingGroup,
+		}); err != nil {
+			return false, errors.Wrapf(err, "failed to delete override launch template versions %v", name)
+		}
+
+		if scalingGroup == nil {
+			continue
+		}
+
+		var latestVersion string
+		if lt.LatestVersion != nil {
+			latestVersion = common.Int64ToStr(aws.Int64Value(lt.LatestVersion.VersionNumber))
+		}
+
+		// instances of an overridden type must be launched from the latest version of its override template
+		for _, instance := range scalingGroup.Instances {
+			if !strings.EqualFold(aws.StringValue(instance.InstanceType), instanceType) || instance.LaunchTemplate == nil {
+				continue
+			}
+			if aws.StringValue(instance.LaunchTemplate.LaunchTemplateName) != name {
+				rotationNeeded = true
+			}
+			if !common.StringEmpty(latestVersion) && aws.StringValue(instance.LaunchTemplate.Version) != latestVersion {
+				rotationNeeded = true
+			}
+		}
+	}
+
+	if err := ctx.DeleteOverrideTemplates(config.Name, templateNames...); err != nil {
+		return false, err
+	}
+
+	return rotationNeeded, nil
+}
+
 func (ctx *EksInstanceGroupContext) UpdateScalingGroup(configName string, scalingConfig *scaling.Configuration) (bool, error) {
 	var (
 		asgUpdated    bool
@@ -159,29 +335,80 @@ func (ctx *EksInstanceGroupContext) UpdateScalingGroup(configName string, scalin
 		asgName       = aws.StringValue(scalingGroup.AutoScalingGroupName)
 		tags          = ctx.GetAddedTags(asgName)
 		rmTags        = ctx.GetRemovedTags(asgName)
+		minSize       = ctx.GetDesiredMinSize()
 	)
 
+	if minSize != spec.GetMinSize() {
+		ctx.Log.Info("deferring min size increase until nodes are ready", "instancegroup", instanceGroup.NamespacedName(),
+			"minSize", spec.GetMinSize(),
+			"currentMinSize", minSize,
+			"desiredCapacity", aws.Int64Value(scalingGroup.DesiredCapacity),
+		)
+	}
+
+	// desired capacity is only set when the scaling group is created, updates leave it to the scaling group or to
+	// cluster-autoscaler, AWS moves it within the new min/max bounds when needed
 	input := &autoscaling.UpdateAutoScalingGroupInput{
-		AutoScalingGroupName: aws.String(asgName),
-		MinSize:              aws.Int64(spec.GetMinSize()),
-		MaxSize:              aws.Int64(spec.GetMaxSize()),
-		VPCZoneIdentifier:    aws.String(common.ConcatenateList(ctx.ResolveSubnets(), ",")),
+		AutoScalingGroupName:             aws.String(asgName),
+		MinSize:                          aws.Int64(minSize),
+		MaxSize:                          aws.Int64(spec.GetMaxSize()),
+		VPCZoneIdentifier:                aws.String(common.ConcatenateList(state.GetSubnets(), ",")),
+		CapacityRebalance:                configuration.GetCapacityRebalance(),
+		NewInstancesProtectedFromScaleIn: configuration.GetNewInstancesProtectedFromScaleIn(),
+	}
+
+	if healthCheckType := configuration.GetHealthCheckType(); !common.StringEmpty(healthCheckType) {
+		input.HealthCheckType = aws.String(healthCheckType)
+	}
+
+	if gracePeriod := configuration.GetHealthCheckGracePeriod(); gracePeriod > 0 {
+		input.HealthCheckGracePeriod = aws.Int64(gracePeriod)
+	}
+
+	if cooldown := configuration.GetDefaultCooldown(); cooldown > 0 {
+		input.DefaultCooldown = aws.Int64(cooldown)
+	}
+
+	if policy := configuration.GetMaintenancePolicy(); policy != nil {
+		input.InstanceMaintenancePolicy = &autoscaling.InstanceMaintenancePolicy{
+			MinHealthyPercentage: policy.MinHealthyPercentage,
+			MaxHealthyPercentage: policy.MaxHealthyPercentage,
+		}
 	}
 
 	if spec.IsLaunchConfiguration() {
 		input.LaunchConfigurationName = aws.String(configName)
-		status.SetActiveLaunchConfigurationName(configName)
 	}
 	if spec.IsLaunchTemplate() {
 		if policy := configuration.GetMixedInstancesPolicy(); policy != nil {
-			input.MixedInstancesPolicy = ctx.GetDesiredMixedInstancesPolicy(configName)
+			desiredPolicy, err := ctx.GetDesiredMixedInstancesPolicy(configName)
+			if err != nil {
+				return asgUpdated, errors.Wrap(err, "failed to get mixed instances policy")
+			}
+			input.MixedInstancesPolicy = desiredPolicy
 		} else {
 			input.LaunchTemplate = &autoscaling.LaunchTemplateSpecification{
 				LaunchTemplateName: aws.String(configName),
-				Version:            aws.String("$Latest"),
+				Version:            aws.String(ctx.GetLaunchTemplateVersion()),
 			}
 		}
+	}
 
+	if ctx.ScalingGroupUpdateNeeded(configName) {
+		ctx.Log.V(1).Info("scaling group update needed", "instancegroup", instanceGroup.NamespacedName(), "scalinggroup", asgName, "existing", scalingGroup, "update", input)
+		err := ctx.AwsWorker.UpdateScalingGroup(input)
+		if err != nil {
+			return asgUpdated, err
+		}
+		asgUpdated = true
+		ctx.Log.Info("updated scaling group", "instancegroup", instanceGroup.NamespacedName(), "scalinggroup", asgName)
+	}
+
+	// drift detection compares against the last applied state, it is only recorded once the scaling group reflects it
+	if spec.IsLaunchConfiguration() {
+		status.SetActiveLaunchConfigurationName(configName)
+	}
+	if spec.IsLaunchTemplate() {
 		status.SetActiveLaunchTemplateName(configName)
 		switch scalingConfigType := (*scalingConfig).(type) {
 		case *scaling.LaunchTemplate:
@@ -194,20 +421,12 @@ func (ctx *EksInstanceGroupContext) UpdateScalingGroup(configName string, scalin
 			//LaunchConfiguration to LaunchTemplate migration. Latest version is the initial version.
 			status.SetLatestTemplateVersion("1")
 		}
-
-	}
-
-	if ctx.ScalingGroupUpdateNeeded(configName) {
-		err := ctx.AwsWorker.UpdateScalingGroup(input)
-		if err != nil {
-			return asgUpdated, err
-		}
-		asgUpdated = true
-		ctx.Log.Info("updated scaling group", "instancegroup", instanceGroup.NamespacedName(), "scalinggroup", asgName)
 	}
-
-	status.SetCurrentMin(int(spec.GetMinSize()))
+	status.SetCurrentMin(int(minSize))
 	status.SetCurrentMax(int(spec.GetMaxSize()))
+	if minSize == spec.GetMinSize() {
+		status.SetAppliedGeneration(instanceGroup.GetGeneration())
+	}
 
 	if ctx.TagsUpdateNeeded() {
 		err := ctx.AwsWorker.UpdateScalingGroupTags(tags, rmTags)
@@ -226,6 +445,9 @@ func (ctx *EksInstanceGroupContext) UpdateScalingGroup(configName string, scalin
 	if err := ctx.UpdateLifecycleHooks(asgName); err != nil {
 		return asgUpdated, err
 	}
+	if err := ctx.UpdateLoadBalancers(asgName); err != nil {
+		return asgUpdated, err
+	}
 	if err := ctx.UpdateWarmPool(asgName); err != nil {
 		return asgUpdated, err
 	}
@@ -272,14 +494,20 @@ func (ctx *EksInstanceGroupContext) ScalingGroupUpdateNeeded(configName string)
 	var (
 		instanceGroup  = ctx.GetInstanceGroup()
 		spec           = instanceGroup.GetEKSSpec()
+		configuration  = instanceGroup.GetEKSConfiguration()
 		state          = ctx.GetDiscoveredState()
 		scalingGroup   = state.GetScalingGroup()
 		zoneIdentifier = aws.StringValue(scalingGroup.VPCZoneIdentifier)
 		groupSubnets   = strings.Split(zoneIdentifier, ",")
-		specSubnets    = ctx.ResolveSubnets()
-		desiredPolicy  = ctx.GetDesiredMixedInstancesPolicy(configName)
+		specSubnets    = state.GetSubnets()
 	)
 
+	// the update surfaces the error of a policy which cannot be computed
+	desiredPolicy, err := ctx.GetDesiredMixedInstancesPolicy(configName)
+	if err != nil {
+		return true
+	}
+
 	var name string
 	switch {
 	case scalingGroup.LaunchConfigurationName != nil:
@@ -298,12 +526,25 @@ func (ctx *EksInstanceGroupContext) ScalingGroupUpdateNeeded(configName string)
 		if desiredPolicy != nil {
 			return true
 		}
+		if version := aws.StringValue(scalingGroup.LaunchTemplate.Version); version != "" && version != ctx.GetLaunchTemplateVersion() {
+			return true
+		}
 	case scalingGroup.MixedInstancesPolicy != nil:
 		name = aws.StringValue(scalingGroup.MixedInstancesPolicy.LaunchTemplate.LaunchTemplateSpecification.LaunchTemplateName)
 		scalingGroup.MixedInstancesPolicy.LaunchTemplate.LaunchTemplateSpecification.LaunchTemplateId = nil
+		for _, override := range scalingGroup.MixedInstancesPolicy.LaunchTemplate.Overrides {
+			if override.LaunchTemplateSpecification != nil {
+				override.LaunchTemplateSpecification.LaunchTemplateId = nil
+			}
+		}
 		if desiredPolicy == nil {
 			return true
 		}
+		// a base capacity percentage follows the desired capacity which changes with every scaling activity, the base
+		// capacity alone is not drift and is recomputed when the scaling group is updated, e.g. when min or max drift
+		if configuration.GetMixedInstancesPolicy().BaseCapacityPercentage != nil && scalingGroup.MixedInstancesPolicy.InstancesDistribution != nil {
+			desiredPolicy.InstancesDistribution.OnDemandBaseCapacity = scalingGroup.MixedInstancesPolicy.InstancesDistribution.OnDemandBaseCapacity
+		}
 		if !reflect.DeepEqual(scalingGroup.MixedInstancesPolicy, desiredPolicy) {
 			return true
 		}
@@ -313,7 +554,7 @@ func (ctx *EksInstanceGroupContext) ScalingGroupUpdateNeeded(configName string)
 		return true
 	}
 
-	if spec.GetMinSize() != aws.Int64Value(scalingGroup.MinSize) {
+	if ctx.GetDesiredMinSize() != aws.Int64Value(scalingGroup.MinSize) {
 		return true
 	}
 
@@ -325,6 +566,36 @@ func (ctx *EksInstanceGroupContext) ScalingGroupUpdateNeeded(configName string)
 		return true
 	}
 
+	if rebalance := configuration.GetCapacityRebalance(); rebalance != nil && aws.BoolValue(rebalance) != aws.BoolValue(scalingGroup.CapacityRebalance) {
+		return true
+	}
+
+	if protected := configuration.GetNewInstancesProtectedFromScaleIn(); protected != nil && aws.BoolValue(protected) != aws.BoolValue(scalingGroup.NewInstancesProtectedFromScaleIn) {
+		return true
+	}
+
+	if healthCheckType := configuration.GetHealthCheckType(); !common.StringEmpty(healthCheckType) && !strings.EqualFold(healthCheckType, aws.StringValue(scalingGroup.HealthCheckType)) {
+		return true
+	}
+
+	if gracePeriod := configuration.GetHealthCheckGracePeriod(); gracePeriod > 0 && gracePeriod != aws.Int64Value(scalingGroup.HealthCheckGracePeriod) {
+		return true
+	}
+
+	if cooldown := configuration.GetDefaultCooldown(); cooldown > 0 && cooldown != aws.Int64Value(scalingGroup.DefaultCooldown) {
+		return true
+	}
+
+	if policy := configuration.GetMaintenancePolicy(); policy != nil {
+		current := scalingGroup.InstanceMaintenancePolicy
+		if current == nil {
+			return true
+		}
+		if aws.Int64Value(policy.MinHealthyPercentage) != aws.Int64Value(current.MinHealthyPercentage) || aws.Int64Value(policy.MaxHealthyPercentage) != aws.Int64Value(current.MaxHealthyPercentage) {
+			return true
+		}
+	}
+
 	return false
 }
 
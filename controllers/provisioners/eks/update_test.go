@@ -17,9 +17,12 @@ package eks
 
 import (
 	"context"
+	"fmt"
 	"testing"
+	"time"
 
 	kubeprovider "github.com/keikoproj/instance-manager/controllers/providers/kubernetes"
+	"github.com/keikoproj/instance-manager/controllers/provisioners"
 	"github.com/keikoproj/instance-manager/controllers/provisioners/eks/scaling"
 
 	"github.com/aws/aws-sdk-go/aws"
@@ -121,6 +124,57 @@ func TestUpdateWithDriftRotationPositive(t *testing.T) {
 	g.Expect(ctx.GetState()).To(gomega.Equal(v1alpha1.ReconcileModifying))
 }
 
+func TestUpdateWeightedCapacityUnitChange(t *testing.T) {
+	var (
+		g             = gomega.NewGomegaWithT(t)
+		k             = MockKubernetesClientSet()
+		ig            = MockInstanceGroup()
+		configuration = ig.GetEKSConfiguration()
+		status        = ig.GetStatus()
+		asgMock       = NewAutoScalingMocker()
+		iamMock       = NewIamMocker()
+		eksMock       = NewEksMocker()
+		ec2Mock       = NewEc2Mocker()
+		ssmMock       = NewSsmMocker()
+	)
+
+	w := MockAwsWorker(asgMock, iamMock, eksMock, ec2Mock, ssmMock)
+	ctx := MockContext(ig, k, w)
+
+	mockScalingGroup := &autoscaling.Group{
+		AutoScalingGroupName: aws.String("some-scaling-group"),
+		DesiredCapacity:      aws.Int64(1),
+	}
+	asgMock.AutoScalingGroups = []*autoscaling.Group{mockScalingGroup}
+
+	ctx.SetDiscoveredState(&DiscoveredState{
+		Publisher: kubeprovider.EventPublisher{
+			Client: k.Kubernetes,
+		},
+		ScalingGroup: mockScalingGroup,
+		ScalingConfiguration: &scaling.LaunchConfiguration{
+			AwsWorker: w,
+		},
+		InstanceProfile: &iam.InstanceProfile{
+			Arn: aws.String("some-instance-arn"),
+		},
+		Cluster: MockEksCluster("1.15"),
+	})
+
+	// the unit of an existing scaling group is recorded
+	err := ctx.Update()
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(status.GetWeightedCapacityUnit()).To(gomega.Equal(v1alpha1.WeightedCapacityUnitInstance))
+
+	// switching the unit of an existing scaling group is rejected
+	configuration.MixedInstancesPolicy = &v1alpha1.MixedInstancesPolicySpec{
+		WeightedCapacityUnit: aws.String(v1alpha1.WeightedCapacityUnitVCPU),
+	}
+	err = ctx.Update()
+	g.Expect(err).To(gomega.HaveOccurred())
+	g.Expect(status.GetWeightedCapacityUnit()).To(gomega.Equal(v1alpha1.WeightedCapacityUnitInstance))
+}
+
 func TestUpdateWithLaunchTemplate(t *testing.T) {
 	var (
 		g             = gomega.NewGomegaWithT(t)
@@ -246,55 +300,478 @@ func TestUpdateWithLaunchTemplate(t *testing.T) {
 			},
 		},
 	}
-	configuration.MixedInstancesPolicy = &v1alpha1.MixedInstancesPolicySpec{
-		InstancePool: &pool,
-		SpotRatio:    &ratio,
-		Strategy:     &strategy,
-	}
-
+	configuration.MixedInstancesPolicy = &v1alpha1.MixedInstancesPolicySpec{
+		InstancePool: &pool,
+		SpotRatio:    &ratio,
+		Strategy:     &strategy,
+	}
+
+	ec2Mock.LaunchTemplates = []*ec2.LaunchTemplate{
+		{
+			LaunchTemplateName: aws.String("some-launch-template"),
+		},
+	}
+	state.ScalingConfiguration.Discover(&scaling.DiscoverConfigurationInput{
+		ScalingGroup: mockScalingGroup,
+	})
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+
+	err = ctx.Update()
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(ec2Mock.CreateLaunchTemplateVersionCallCount).To(gomega.Equal(uint(1)))
+	g.Expect(ec2Mock.ModifyLaunchTemplateCallCount).To(gomega.Equal(uint(1)))
+	g.Expect(ctx.GetState()).To(gomega.Equal(v1alpha1.ReconcileModifying))
+
+	ec2Mock.CreateLaunchTemplateVersionCallCount = 0
+	ec2Mock.ModifyLaunchTemplateCallCount = 0
+	instances := []*v1alpha1.InstanceTypeSpec{
+		{
+			Type:   "x1.large",
+			Weight: 1,
+		},
+		{
+			Type:   "x2.large",
+			Weight: 1,
+		},
+	}
+	configuration.MixedInstancesPolicy = &v1alpha1.MixedInstancesPolicySpec{
+		InstanceTypes: instances,
+		SpotRatio:     &ratio,
+		Strategy:      &strategy,
+	}
+	state.ScalingConfiguration.Discover(&scaling.DiscoverConfigurationInput{
+		ScalingGroup: mockScalingGroup,
+	})
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+
+	err = ctx.Update()
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(ec2Mock.CreateLaunchTemplateVersionCallCount).To(gomega.Equal(uint(1)))
+	g.Expect(ec2Mock.ModifyLaunchTemplateCallCount).To(gomega.Equal(uint(1)))
+	g.Expect(ctx.GetState()).To(gomega.Equal(v1alpha1.ReconcileModifying))
+}
+
+func TestUpdateWithForceUpgradeToken(t *testing.T) {
+	var (
+		g       = gomega.NewGomegaWithT(t)
+		k       = MockKubernetesClientSet()
+		ig      = MockInstanceGroup()
+		spec    = ig.GetEKSSpec()
+		status  = ig.GetStatus()
+		asgMock = NewAutoScalingMocker()
+		iamMock = NewIamMocker()
+		eksMock = NewEksMocker()
+		ec2Mock = NewEc2Mocker()
+		ssmMock = NewSsmMocker()
+	)
+
+	w := MockAwsWorker(asgMock, iamMock, eksMock, ec2Mock, ssmMock)
+	ctx := MockContext(ig, k, w)
+
+	spec.Type = v1alpha1.LaunchTemplate
+
+	mockScalingGroup := &autoscaling.Group{
+		AutoScalingGroupName: aws.String("some-scaling-group"),
+		DesiredCapacity:      aws.Int64(1),
+		LaunchTemplate: &autoscaling.LaunchTemplateSpecification{
+			LaunchTemplateName: aws.String("some-launch-template"),
+		},
+		Instances: []*autoscaling.Instance{
+			{
+				InstanceId: aws.String("i-1234"),
+				LaunchTemplate: &autoscaling.LaunchTemplateSpecification{
+					LaunchTemplateName: aws.String("some-launch-template"),
+					Version:            aws.String("1"),
+				},
+			},
+		},
+	}
+	asgMock.AutoScalingGroups = []*autoscaling.Group{mockScalingGroup}
+	ec2Mock.LaunchTemplates = []*ec2.LaunchTemplate{
+		{
+			LaunchTemplateName:  aws.String("some-launch-template"),
+			LatestVersionNumber: aws.Int64(1),
+		},
+	}
+
+	ctx.SetDiscoveredState(&DiscoveredState{
+		Publisher: kubeprovider.EventPublisher{
+			Client: k.Kubernetes,
+		},
+		ScalingGroup: mockScalingGroup,
+		ScalingConfiguration: &scaling.LaunchTemplate{
+			AwsWorker: w,
+			TargetResource: &ec2.LaunchTemplate{
+				LaunchTemplateName:  aws.String("some-launch-template"),
+				LatestVersionNumber: aws.Int64(1),
+			},
+		},
+		InstanceProfile: &iam.InstanceProfile{
+			Arn: aws.String("some-instance-arn"),
+		},
+		Cluster: MockEksCluster("1.15"),
+	})
+
+	// without a token the upgrade path only follows drift
+	g.Expect(ctx.IsForceUpgradeRequested()).To(gomega.BeFalse())
+
+	// a new token creates a new launch template version and records the token
+	ig.SetAnnotations(map[string]string{ForceUpgradeAnnotation: "token-1"})
+	g.Expect(ctx.IsForceUpgradeRequested()).To(gomega.BeTrue())
+
+	err := ctx.Update()
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(ec2Mock.CreateLaunchTemplateVersionCallCount).To(gomega.Equal(uint(1)))
+	g.Expect(status.GetLastForceUpgradeToken()).To(gomega.Equal("token-1"))
+	g.Expect(ctx.IsForceUpgradeRequested()).To(gomega.BeFalse())
+
+	// changing the token again forces another upgrade
+	ig.SetAnnotations(map[string]string{ForceUpgradeAnnotation: "token-2"})
+	g.Expect(ctx.IsForceUpgradeRequested()).To(gomega.BeTrue())
+}
+
+func TestUpdateWithExistingLaunchTemplate(t *testing.T) {
+	var (
+		g             = gomega.NewGomegaWithT(t)
+		k             = MockKubernetesClientSet()
+		ig            = MockInstanceGroup()
+		spec          = ig.GetEKSSpec()
+		configuration = ig.GetEKSConfiguration()
+		asgMock       = NewAutoScalingMocker()
+		iamMock       = NewIamMocker()
+		eksMock       = NewEksMocker()
+		ec2Mock       = NewEc2Mocker()
+		ssmMock       = NewSsmMocker()
+	)
+
+	w := MockAwsWorker(asgMock, iamMock, eksMock, ec2Mock, ssmMock)
+	ctx := MockContext(ig, k, w)
+
+	spec.Type = v1alpha1.LaunchTemplate
+	configuration.ExistingLaunchTemplateName = "existing-launch-template"
+
+	mockScalingGroup := &autoscaling.Group{
+		AutoScalingGroupName: aws.String("some-scaling-group"),
+		DesiredCapacity:      aws.Int64(1),
+		LaunchTemplate: &autoscaling.LaunchTemplateSpecification{
+			LaunchTemplateName: aws.String("existing-launch-template"),
+		},
+	}
+	asgMock.AutoScalingGroups = []*autoscaling.Group{mockScalingGroup}
+
+	ec2Mock.LaunchTemplates = []*ec2.LaunchTemplate{
+		{
+			LaunchTemplateName:   aws.String("existing-launch-template"),
+			LatestVersionNumber:  aws.Int64(1),
+			DefaultVersionNumber: aws.Int64(1),
+		},
+		{
+			LaunchTemplateName: aws.String("existing-launch-template-other"),
+		},
+	}
+	ec2Mock.LaunchTemplateVersions = []*ec2.LaunchTemplateVersion{
+		{
+			LaunchTemplateName: aws.String("existing-launch-template"),
+			VersionNumber:      aws.Int64(1),
+			DefaultVersion:     aws.Bool(true),
+			LaunchTemplateData: &ec2.ResponseLaunchTemplateData{
+				ImageId: aws.String("ami-base"),
+				IamInstanceProfile: &ec2.LaunchTemplateIamInstanceProfileSpecification{
+					Arn: aws.String("aws:arn:some-role"),
+				},
+			},
+		},
+	}
+
+	lt, err := scaling.NewLaunchTemplate(ig.NamespacedName(), w, &scaling.DiscoverConfigurationInput{
+		ScalingGroup:     mockScalingGroup,
+		TargetConfigName: configuration.GetExistingLaunchTemplateName(),
+	})
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(lt.Provisioned()).To(gomega.BeTrue())
+
+	ctx.SetDiscoveredState(&DiscoveredState{
+		Publisher: kubeprovider.EventPublisher{
+			Client: k.Kubernetes,
+		},
+		ScalingGroup:         mockScalingGroup,
+		ScalingConfiguration: lt,
+		InstanceProfile: &iam.InstanceProfile{
+			Arn: aws.String("some-instance-arn"),
+		},
+		IAMRole: &iam.Role{},
+		Cluster: MockEksCluster("1.15"),
+	})
+
+	// new versions are created on the existing launch template
+	err = ctx.Update()
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(ec2Mock.CreateLaunchTemplateCallCount).To(gomega.Equal(uint(0)))
+	g.Expect(ec2Mock.CreateLaunchTemplateVersionCallCount).To(gomega.Equal(uint(1)))
+	g.Expect(aws.StringValue(ec2Mock.CreateLaunchTemplateVersionInput.LaunchTemplateName)).To(gomega.Equal("existing-launch-template"))
+	g.Expect(aws.StringValue(ec2Mock.CreateLaunchTemplateVersionInput.SourceVersion)).To(gomega.Equal("1"))
+	g.Expect(aws.StringValue(ec2Mock.CreateLaunchTemplateVersionInput.VersionDescription)).To(gomega.Equal(ctx.GetLaunchTemplateVersionDescription()))
+	g.Expect(ec2Mock.ModifyLaunchTemplateCallCount).To(gomega.Equal(uint(0)))
+	g.Expect(ig.GetStatus().GetActiveLaunchTemplateName()).To(gomega.Equal("existing-launch-template"))
+
+	// the existing launch template and templates sharing its prefix are not deleted with the instance group
+	err = ctx.Delete()
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(ec2Mock.DeleteLaunchTemplateCallCount).To(gomega.Equal(uint(0)))
+
+	// a missing launch template is not created
+	ctx.SetDiscoveredState(&DiscoveredState{
+		Publisher: kubeprovider.EventPublisher{
+			Client: k.Kubernetes,
+		},
+		ScalingGroup: mockScalingGroup,
+		ScalingConfiguration: &scaling.LaunchTemplate{
+			AwsWorker: w,
+		},
+		InstanceProfile: &iam.InstanceProfile{
+			Arn: aws.String("some-instance-arn"),
+		},
+		Cluster: MockEksCluster("1.15"),
+	})
+	err = ctx.Update()
+	g.Expect(err).To(gomega.HaveOccurred())
+	g.Expect(ec2Mock.CreateLaunchTemplateCallCount).To(gomega.Equal(uint(0)))
+}
+
+func TestUpdateWithInstanceRefreshCheckpoints(t *testing.T) {
+	var (
+		g             = gomega.NewGomegaWithT(t)
+		k             = MockKubernetesClientSet()
+		ig            = MockInstanceGroup()
+		status        = ig.GetStatus()
+		configuration = ig.GetEKSConfiguration()
+		asgMock       = NewAutoScalingMocker()
+		iamMock       = NewIamMocker()
+		eksMock       = NewEksMocker()
+		ec2Mock       = NewEc2Mocker()
+		ssmMock       = NewSsmMocker()
+	)
+
+	w := MockAwsWorker(asgMock, iamMock, eksMock, ec2Mock, ssmMock)
+	ctx := MockContext(ig, k, w)
+	configuration.Subnets = []string{"subnet-1"}
+
+	annotations := ig.GetAnnotations()
+	annotations[InstanceRefreshAnnotation] = "true"
+	ig.SetAnnotations(annotations)
+
+	ig.Spec.AwsUpgradeStrategy.InstanceRefresh = &v1alpha1.InstanceRefreshStrategy{
+		CheckpointPercentages: []int64{20, 50, 100},
+		CheckpointDelay:       600,
+	}
+
+	mockScalingGroup := &autoscaling.Group{
+		AutoScalingGroupName:    aws.String("some-scaling-group"),
+		LaunchConfigurationName: aws.String("some-launch-config"),
+		DesiredCapacity:         aws.Int64(1),
+		MinSize:                 aws.Int64(1),
+		MaxSize:                 aws.Int64(3),
+		VPCZoneIdentifier:       aws.String("subnet-1"),
+		Instances: []*autoscaling.Instance{
+			{
+				InstanceId: aws.String("i-1234"),
+				// wrong launch-config causes rotation
+				LaunchConfigurationName: aws.String("some-wrong-launch-config"),
+			},
+		},
+	}
+	asgMock.AutoScalingGroups = []*autoscaling.Group{mockScalingGroup}
+
+	ctx.SetDiscoveredState(&DiscoveredState{
+		Publisher: kubeprovider.EventPublisher{
+			Client: k.Kubernetes,
+		},
+		ScalingGroup: mockScalingGroup,
+		Subnets:      configuration.GetSubnets(),
+		InstanceProfile: &iam.InstanceProfile{
+			Arn: aws.String("some-instance-arn"),
+		},
+		ClusterNodes: &corev1.NodeList{
+			Items: []corev1.Node{*MockNode("i-1234", corev1.ConditionTrue)},
+		},
+		Cluster: MockEksCluster("1.15"),
+	})
+
+	userData := ctx.GetBasicUserData(configuration.GetClusterName(), ctx.GetBootstrapArgs(), ctx.GetKubeletExtraArgs(), ctx.GetUserDataStages(), ctx.GetMountOpts())
+	mockLaunchConfig := MockLaunchConfigFromInput(&autoscaling.CreateLaunchConfigurationInput{
+		LaunchConfigurationName: aws.String("some-launch-config"),
+		ImageId:                 aws.String(configuration.Image),
+		InstanceType:            aws.String(configuration.InstanceType),
+		IamInstanceProfile:      aws.String("some-instance-arn"),
+		SpotPrice:               aws.String(configuration.GetSpotPrice()),
+		KeyName:                 aws.String(configuration.KeyPairName),
+		UserData:                aws.String(userData),
+	})
+	state := ctx.GetDiscoveredState()
+	state.ScalingConfiguration = &scaling.LaunchConfiguration{
+		AwsWorker:      w,
+		TargetResource: mockLaunchConfig,
+	}
+
+	// node rotation is deferred until the maintenance window opens
+	now := time.Now().UTC()
+	annotations[v1alpha1.MaintenanceWindowAnnotationKey] = fmt.Sprintf("%v-%v", now.Add(time.Hour).Format("15:04"), now.Add(2*time.Hour).Format("15:04"))
+	ig.SetAnnotations(annotations)
+	err := ctx.Update()
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(ctx.GetState()).To(gomega.Equal(v1alpha1.ReconcileModifying))
+	g.Expect(asgMock.StartInstanceRefreshCallCount).To(gomega.Equal(uint(0)))
+
+	// the instance refresh is started by the upgrade
+	delete(annotations, v1alpha1.MaintenanceWindowAnnotationKey)
+	ig.SetAnnotations(annotations)
+	err = ctx.Update()
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(ctx.GetState()).To(gomega.Equal(v1alpha1.ReconcileInitUpgrade))
+	g.Expect(asgMock.StartInstanceRefreshCallCount).To(gomega.Equal(uint(0)))
+
+	err = ctx.UpgradeNodes()
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(asgMock.StartInstanceRefreshCallCount).To(gomega.Equal(uint(1)))
+	g.Expect(aws.StringValue(asgMock.StartInstanceRefreshInput.AutoScalingGroupName)).To(gomega.Equal("some-scaling-group"))
+	g.Expect(status.GetInstanceRefreshId()).To(gomega.Equal("refresh-1"))
+
+	preferences := asgMock.StartInstanceRefreshInput.Preferences
+	g.Expect(preferences).NotTo(gomega.BeNil())
+	g.Expect(aws.Int64ValueSlice(preferences.CheckpointPercentages)).To(gomega.Equal([]int64{20, 50, 100}))
+	g.Expect(aws.Int64Value(preferences.CheckpointDelay)).To(gomega.Equal(int64(600)))
+	g.Expect(aws.StringValue(preferences.ScaleInProtectedInstances)).To(gomega.Equal(autoscaling.ScaleInProtectedInstancesRefresh))
+	g.Expect(aws.StringValue(preferences.StandbyInstances)).To(gomega.Equal(autoscaling.StandbyInstancesIgnore))
+
+	tests := []struct {
+		refreshStatus     string
+		expectedStarts    uint
+		expectedRefreshId string
+		expectedState     v1alpha1.ReconcileState
+	}{
+		// an ongoing refresh is resumed
+		{refreshStatus: autoscaling.InstanceRefreshStatusInProgress, expectedStarts: 1, expectedRefreshId: "refresh-1", expectedState: v1alpha1.ReconcileInitUpgrade},
+		// a failed refresh is started again
+		{refreshStatus: autoscaling.InstanceRefreshStatusFailed, expectedStarts: 2, expectedRefreshId: "refresh-2", expectedState: v1alpha1.ReconcileInitUpgrade},
+		{refreshStatus: autoscaling.InstanceRefreshStatusSuccessful, expectedStarts: 2, expectedRefreshId: "", expectedState: v1alpha1.ReconcileModified},
+	}
+
+	for i, tc := range tests {
+		t.Logf("test #%v - %+v", i, tc)
+		ctx.SetState(v1alpha1.ReconcileInitUpgrade)
+		asgMock.InstanceRefreshes = []*autoscaling.InstanceRefresh{
+			{
+				InstanceRefreshId: aws.String(status.GetInstanceRefreshId()),
+				Status:            aws.String(tc.refreshStatus),
+			},
+		}
+		err = ctx.UpgradeNodes()
+		g.Expect(err).NotTo(gomega.HaveOccurred())
+		g.Expect(asgMock.StartInstanceRefreshCallCount).To(gomega.Equal(tc.expectedStarts))
+		g.Expect(status.GetInstanceRefreshId()).To(gomega.Equal(tc.expectedRefreshId))
+		g.Expect(ctx.GetState()).To(gomega.Equal(tc.expectedState))
+	}
+
+	// a refresh which is already active is resumed when its ID was not recorded
+	asgMock.InstanceRefreshes = []*autoscaling.InstanceRefresh{
+		{
+			InstanceRefreshId: aws.String("refresh-external"),
+			Status:            aws.String(autoscaling.InstanceRefreshStatusPending),
+		},
+	}
+	err = ctx.UpgradeNodes()
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(asgMock.StartInstanceRefreshCallCount).To(gomega.Equal(uint(2)))
+	g.Expect(status.GetInstanceRefreshId()).To(gomega.Equal("refresh-external"))
+
+	// without checkpoints only the handling of protected and standby instances is set
+	ig.Spec.AwsUpgradeStrategy.InstanceRefresh = nil
+	g.Expect(ctx.GetInstanceRefreshPreferences()).To(gomega.Equal(&autoscaling.RefreshPreferences{
+		ScaleInProtectedInstances: aws.String(autoscaling.ScaleInProtectedInstancesRefresh),
+		StandbyInstances:          aws.String(autoscaling.StandbyInstancesIgnore),
+	}))
+}
+
+func TestUpdateOverrideTemplates(t *testing.T) {
+	var (
+		g             = gomega.NewGomegaWithT(t)
+		k             = MockKubernetesClientSet()
+		ig            = MockInstanceGroup()
+		spec          = ig.GetEKSSpec()
+		configuration = ig.GetEKSConfiguration()
+		asgMock       = NewAutoScalingMocker()
+		iamMock       = NewIamMocker()
+		eksMock       = NewEksMocker()
+		ec2Mock       = NewEc2Mocker()
+		ssmMock       = NewSsmMocker()
+	)
+
+	w := MockAwsWorker(asgMock, iamMock, eksMock, ec2Mock, ssmMock)
+	ctx := MockContext(ig, k, w)
+	state := ctx.GetDiscoveredState()
+
+	spec.Type = v1alpha1.LaunchTemplate
+	configuration.MixedInstancesPolicy = &v1alpha1.MixedInstancesPolicySpec{
+		InstanceTypes: []*v1alpha1.InstanceTypeSpec{
+			{
+				Type:   "m6g.xlarge",
+				Weight: 1,
+				Image:  "ami-arm64",
+			},
+		},
+	}
+	state.ScalingGroup = &autoscaling.Group{
+		AutoScalingGroupName: aws.String("some-scaling-group"),
+		Instances: []*autoscaling.Instance{
+			{
+				InstanceId:   aws.String("i-1234"),
+				InstanceType: aws.String("m6g.xlarge"),
+				LaunchTemplate: &autoscaling.LaunchTemplateSpecification{
+					LaunchTemplateName: aws.String("some-launch-template"),
+					Version:            aws.String("1"),
+				},
+			},
+		},
+	}
 	ec2Mock.LaunchTemplates = []*ec2.LaunchTemplate{
 		{
 			LaunchTemplateName: aws.String("some-launch-template"),
 		},
-	}
-	state.ScalingConfiguration.Discover(&scaling.DiscoverConfigurationInput{
-		ScalingGroup: mockScalingGroup,
-	})
-	g.Expect(err).NotTo(gomega.HaveOccurred())
-
-	err = ctx.Update()
-	g.Expect(err).NotTo(gomega.HaveOccurred())
-	g.Expect(ec2Mock.CreateLaunchTemplateVersionCallCount).To(gomega.Equal(uint(1)))
-	g.Expect(ec2Mock.ModifyLaunchTemplateCallCount).To(gomega.Equal(uint(1)))
-	g.Expect(ctx.GetState()).To(gomega.Equal(v1alpha1.ReconcileModifying))
-
-	ec2Mock.CreateLaunchTemplateVersionCallCount = 0
-	ec2Mock.ModifyLaunchTemplateCallCount = 0
-	instances := []*v1alpha1.InstanceTypeSpec{
 		{
-			Type:   "x1.large",
-			Weight: 1,
+			LaunchTemplateName: aws.String("some-launch-template-c6g.xlarge"),
+			Tags: []*ec2.Tag{
+				{Key: aws.String(provisioners.TagInstanceGroupNamespace), Value: aws.String(ig.GetNamespace())},
+				{Key: aws.String(provisioners.TagInstanceGroupName), Value: aws.String(ig.GetName())},
+			},
 		},
 		{
-			Type:   "x2.large",
-			Weight: 1,
+			LaunchTemplateName: aws.String("some-launch-template-unmanaged"),
 		},
 	}
-	configuration.MixedInstancesPolicy = &v1alpha1.MixedInstancesPolicySpec{
-		InstanceTypes: instances,
-		SpotRatio:     &ratio,
-		Strategy:      &strategy,
+
+	config := &scaling.CreateConfigurationInput{
+		Name:         "some-launch-template",
+		ImageId:      "ami-amd64",
+		InstanceType: "m5.xlarge",
 	}
-	state.ScalingConfiguration.Discover(&scaling.DiscoverConfigurationInput{
-		ScalingGroup: mockScalingGroup,
-	})
-	g.Expect(err).NotTo(gomega.HaveOccurred())
 
-	err = ctx.Update()
+	// override template is created and tagged as owned, a stale owned override template is removed while a template
+	// not owned by the instance group is kept, and instances are rotated
+	rotationNeeded, err := ctx.UpdateOverrideTemplates(config)
 	g.Expect(err).NotTo(gomega.HaveOccurred())
-	g.Expect(ec2Mock.CreateLaunchTemplateVersionCallCount).To(gomega.Equal(uint(1)))
-	g.Expect(ec2Mock.ModifyLaunchTemplateCallCount).To(gomega.Equal(uint(1)))
-	g.Expect(ctx.GetState()).To(gomega.Equal(v1alpha1.ReconcileModifying))
+	g.Expect(rotationNeeded).To(gomega.BeTrue())
+	g.Expect(ec2Mock.CreateLaunchTemplateCallCount).To(gomega.Equal(uint(1)))
+	g.Expect(ec2Mock.CreateLaunchTemplateInput.TagSpecifications).To(gomega.HaveLen(1))
+	g.Expect(aws.StringValue(ec2Mock.CreateLaunchTemplateInput.TagSpecifications[0].ResourceType)).To(gomega.Equal(ec2.ResourceTypeLaunchTemplate))
+	g.Expect(ec2Mock.DeleteLaunchTemplateCallCount).To(gomega.Equal(uint(1)))
+
+	// no override templates for launch configurations
+	ec2Mock.CreateLaunchTemplateCallCount = 0
+	spec.Type = v1alpha1.LaunchConfiguration
+	rotationNeeded, err = ctx.UpdateOverrideTemplates(config)
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(rotationNeeded).To(gomega.BeFalse())
+	g.Expect(ec2Mock.CreateLaunchTemplateCallCount).To(gomega.Equal(uint(0)))
 }
 
 func TestUpdateWithRotationPositive(t *testing.T) {
@@ -375,6 +852,99 @@ func TestUpdateWithRotationPositive(t *testing.T) {
 	g.Expect(ctx.GetState()).To(gomega.Equal(v1alpha1.ReconcileModifying))
 }
 
+func TestUpdateWithRotationMaintenanceWindow(t *testing.T) {
+	var (
+		g             = gomega.NewGomegaWithT(t)
+		k             = MockKubernetesClientSet()
+		ig            = MockInstanceGroup()
+		configuration = ig.GetEKSConfiguration()
+		asgMock       = NewAutoScalingMocker()
+		iamMock       = NewIamMocker()
+		eksMock       = NewEksMocker()
+		ec2Mock       = NewEc2Mocker()
+		ssmMock       = NewSsmMocker()
+	)
+
+	w := MockAwsWorker(asgMock, iamMock, eksMock, ec2Mock, ssmMock)
+	ctx := MockContext(ig, k, w)
+	configuration.Subnets = []string{"subnet-1"}
+
+	mockScalingGroup := &autoscaling.Group{
+		AutoScalingGroupName:    aws.String("some-scaling-group"),
+		LaunchConfigurationName: aws.String("some-launch-config"),
+		DesiredCapacity:         aws.Int64(1),
+		MinSize:                 aws.Int64(1),
+		MaxSize:                 aws.Int64(3),
+		VPCZoneIdentifier:       aws.String("subnet-1"),
+		Instances: []*autoscaling.Instance{
+			{
+				InstanceId: aws.String("i-1234"),
+				// wrong launch-config causes rotation
+				LaunchConfigurationName: aws.String("some-wrong-launch-config"),
+			},
+		},
+	}
+
+	ctx.SetDiscoveredState(&DiscoveredState{
+		Publisher: kubeprovider.EventPublisher{
+			Client: k.Kubernetes,
+		},
+		ScalingGroup: mockScalingGroup,
+		Subnets:      configuration.GetSubnets(),
+		InstanceProfile: &iam.InstanceProfile{
+			Arn: aws.String("some-instance-arn"),
+		},
+		ClusterNodes: &corev1.NodeList{
+			Items: []corev1.Node{*MockNode("i-1234", corev1.ConditionTrue)},
+		},
+		Cluster: MockEksCluster("1.15"),
+	})
+
+	userData := ctx.GetBasicUserData(configuration.GetClusterName(), ctx.GetBootstrapArgs(), ctx.GetKubeletExtraArgs(), ctx.GetUserDataStages(), ctx.GetMountOpts())
+	mockLaunchConfig := MockLaunchConfigFromInput(&autoscaling.CreateLaunchConfigurationInput{
+		LaunchConfigurationName: aws.String("some-launch-config"),
+		ImageId:                 aws.String(configuration.Image),
+		InstanceType:            aws.String(configuration.InstanceType),
+		IamInstanceProfile:      aws.String("some-instance-arn"),
+		SpotPrice:               aws.String(configuration.GetSpotPrice()),
+		KeyName:                 aws.String(configuration.KeyPairName),
+		UserData:                aws.String(userData),
+	})
+	state := ctx.GetDiscoveredState()
+	state.ScalingConfiguration = &scaling.LaunchConfiguration{
+		AwsWorker:      w,
+		TargetResource: mockLaunchConfig,
+	}
+
+	now := time.Now().UTC()
+	tests := []struct {
+		window        string
+		expectedState v1alpha1.ReconcileState
+	}{
+		// no maintenance window, upgrade proceeds
+		{window: "", expectedState: v1alpha1.ReconcileInitUpgrade},
+		// outside the maintenance window, upgrade is deferred and requeued
+		{window: fmt.Sprintf("%v-%v", now.Add(time.Hour).Format("15:04"), now.Add(2*time.Hour).Format("15:04")), expectedState: v1alpha1.ReconcileModifying},
+		// inside the maintenance window, upgrade proceeds
+		{window: fmt.Sprintf("%v-%v", now.Add(-time.Hour).Format("15:04"), now.Add(time.Hour).Format("15:04")), expectedState: v1alpha1.ReconcileInitUpgrade},
+	}
+
+	for i, tc := range tests {
+		t.Logf("Test #%v - %+v", i, tc)
+		annotations := ig.GetAnnotations()
+		if tc.window == "" {
+			delete(annotations, v1alpha1.MaintenanceWindowAnnotationKey)
+		} else {
+			annotations[v1alpha1.MaintenanceWindowAnnotationKey] = tc.window
+		}
+		ig.SetAnnotations(annotations)
+
+		err := ctx.Update()
+		g.Expect(err).NotTo(gomega.HaveOccurred())
+		g.Expect(ctx.GetState()).To(gomega.Equal(tc.expectedState))
+	}
+}
+
 func TestLaunchConfigurationDrifted(t *testing.T) {
 	var (
 		g       = gomega.NewGomegaWithT(t)
@@ -558,6 +1128,248 @@ func TestUpdateScalingGroupNegative(t *testing.T) {
 	g.Expect(ctx.GetState()).To(gomega.Equal(v1alpha1.ReconcileModifying))
 }
 
+func TestUpdateWithScalingGroupDrift(t *testing.T) {
+	var (
+		g       = gomega.NewGomegaWithT(t)
+		k       = MockKubernetesClientSet()
+		ig      = MockInstanceGroup()
+		spec    = ig.GetEKSSpec()
+		status  = ig.GetStatus()
+		asgMock = NewAutoScalingMocker()
+		iamMock = NewIamMocker()
+		eksMock = NewEksMocker()
+		ec2Mock = NewEc2Mocker()
+		ssmMock = NewSsmMocker()
+	)
+
+	w := MockAwsWorker(asgMock, iamMock, eksMock, ec2Mock, ssmMock)
+	ctx := MockContext(ig, k, w)
+
+	input := &autoscaling.CreateLaunchConfigurationInput{
+		LaunchConfigurationName: aws.String("some-launch-configuration"),
+		ImageId:                 aws.String("ami-123456789012"),
+		InstanceType:            aws.String("m5.large"),
+		IamInstanceProfile:      aws.String("some-instance-arn"),
+		SecurityGroups:          []*string{},
+	}
+	mockLaunchConfig := MockLaunchConfigFromInput(input)
+
+	// scaling group min-size was modified outside of the controller
+	mockScalingGroup := MockScalingGroup("some-scaling-group", false)
+	mockScalingGroup.MinSize = aws.Int64(2)
+	mockScalingGroup.MaxSize = aws.Int64(spec.GetMaxSize())
+	asgMock.AutoScalingGroups = []*autoscaling.Group{mockScalingGroup}
+
+	// the current spec generation was already applied
+	ig.SetGeneration(2)
+	status.SetAppliedGeneration(2)
+	status.SetActiveLaunchConfigurationName("some-launch-configuration")
+
+	ctx.SetDiscoveredState(&DiscoveredState{
+		Publisher: kubeprovider.EventPublisher{
+			Client: k.Kubernetes,
+		},
+		ScalingGroup: mockScalingGroup,
+		ScalingConfiguration: &scaling.LaunchConfiguration{
+			AwsWorker:      w,
+			TargetResource: mockLaunchConfig,
+		},
+		InstanceProfile: &iam.InstanceProfile{
+			Arn: aws.String("some-instance-arn"),
+		},
+		Cluster: MockEksCluster("1.15"),
+	})
+
+	// health check settings set in the spec are compared as well
+	ig.GetEKSConfiguration().HealthCheckType = "ELB"
+	mockScalingGroup.HealthCheckType = aws.String("EC2")
+	g.Expect(ctx.ScalingGroupDrifted(mockScalingGroup)).To(gomega.BeTrue())
+	mockScalingGroup.MinSize = aws.Int64(spec.GetMinSize())
+	g.Expect(ctx.ScalingGroupDrifted(mockScalingGroup)).To(gomega.BeTrue())
+	mockScalingGroup.HealthCheckType = aws.String("ELB")
+	g.Expect(ctx.ScalingGroupDrifted(mockScalingGroup)).To(gomega.BeFalse())
+	mockScalingGroup.MinSize = aws.Int64(2)
+
+	g.Expect(ctx.ScalingGroupDrifted(mockScalingGroup)).To(gomega.BeTrue())
+	status.SetDriftDetected(true)
+
+	// status is not updated when the update fails
+	ig.SetGeneration(3)
+	asgMock.UpdateAutoScalingGroupErr = errors.New("some-error")
+	err := ctx.Update()
+	g.Expect(err).To(gomega.HaveOccurred())
+	g.Expect(status.GetAppliedGeneration()).To(gomega.Equal(int64(2)))
+	asgMock.UpdateAutoScalingGroupErr = nil
+	asgMock.UpdateAutoScalingGroupCallCount = 0
+
+	err = ctx.Update()
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(asgMock.UpdateAutoScalingGroupCallCount).To(gomega.Equal(uint(1)))
+	g.Expect(status.GetCurrentMin()).To(gomega.Equal(int(spec.GetMinSize())))
+	g.Expect(status.GetAppliedGeneration()).To(gomega.Equal(int64(3)))
+
+	events, err := k.Kubernetes.CoreV1().Events("").List(context.Background(), metav1.ListOptions{})
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	var driftEvents int
+	for _, e := range events.Items {
+		if e.Reason == string(kubeprovider.ConfigurationDriftEvent) {
+			driftEvents++
+		}
+	}
+	// published on every attempt until the desired state is re-applied
+	g.Expect(driftEvents).To(gomega.Equal(2))
+
+	// a spec change is not considered drift
+	ig.SetGeneration(4)
+	g.Expect(ctx.ScalingGroupDrifted(mockScalingGroup)).To(gomega.BeFalse())
+}
+
+func TestUpdateScalingGroupPreservesDesiredCapacity(t *testing.T) {
+	var (
+		g       = gomega.NewGomegaWithT(t)
+		k       = MockKubernetesClientSet()
+		ig      = MockInstanceGroup()
+		spec    = ig.GetEKSSpec()
+		asgMock = NewAutoScalingMocker()
+		iamMock = NewIamMocker()
+		eksMock = NewEksMocker()
+		ec2Mock = NewEc2Mocker()
+		ssmMock = NewSsmMocker()
+	)
+
+	w := MockAwsWorker(asgMock, iamMock, eksMock, ec2Mock, ssmMock)
+	ctx := MockContext(ig, k, w)
+
+	// desired capacity was scaled up by cluster-autoscaler
+	ig.Annotations[ClusterAutoscalerEnabledAnnotation] = "true"
+	spec.MinSize = 1
+	spec.MaxSize = 10
+	mockScalingGroup := MockScalingGroup("some-scaling-group", false)
+	mockScalingGroup.MinSize = aws.Int64(1)
+	mockScalingGroup.MaxSize = aws.Int64(5)
+	mockScalingGroup.DesiredCapacity = aws.Int64(4)
+
+	ctx.SetDiscoveredState(&DiscoveredState{
+		Publisher: kubeprovider.EventPublisher{
+			Client: k.Kubernetes,
+		},
+		ScalingGroup: mockScalingGroup,
+		ScalingConfiguration: &scaling.LaunchConfiguration{
+			AwsWorker: w,
+		},
+		Cluster: MockEksCluster("1.15"),
+	})
+
+	// only min/max are updated, desired capacity is left to the scaling group
+	_, err := ctx.UpdateScalingGroup("some-launch-configuration", &ctx.GetDiscoveredState().ScalingConfiguration)
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(asgMock.UpdateAutoScalingGroupInput).NotTo(gomega.BeNil())
+	g.Expect(aws.Int64Value(asgMock.UpdateAutoScalingGroupInput.MinSize)).To(gomega.Equal(int64(1)))
+	g.Expect(aws.Int64Value(asgMock.UpdateAutoScalingGroupInput.MaxSize)).To(gomega.Equal(int64(10)))
+	g.Expect(asgMock.UpdateAutoScalingGroupInput.DesiredCapacity).To(gomega.BeNil())
+
+	// a difference in desired capacity alone is not drift
+	mockScalingGroup.MaxSize = aws.Int64(10)
+	mockScalingGroup.DesiredCapacity = aws.Int64(7)
+	g.Expect(ctx.ScalingGroupDrifted(mockScalingGroup)).To(gomega.BeFalse())
+}
+
+func TestUpdateScalingGroupDefersScaleUpWhileNotReady(t *testing.T) {
+	var (
+		g       = gomega.NewGomegaWithT(t)
+		k       = MockKubernetesClientSet()
+		ig      = MockInstanceGroup()
+		spec    = ig.GetEKSSpec()
+		status  = ig.GetStatus()
+		asgMock = NewAutoScalingMocker()
+		iamMock = NewIamMocker()
+		eksMock = NewEksMocker()
+		ec2Mock = NewEc2Mocker()
+		ssmMock = NewSsmMocker()
+	)
+
+	w := MockAwsWorker(asgMock, iamMock, eksMock, ec2Mock, ssmMock)
+	ctx := MockContext(ig, k, w)
+
+	// a previous scale-up to 2 instances has not produced ready nodes
+	spec.MinSize = 4
+	spec.MaxSize = 10
+	mockScalingGroup := MockScalingGroup("some-scaling-group", false)
+	mockScalingGroup.MinSize = aws.Int64(2)
+	mockScalingGroup.MaxSize = aws.Int64(10)
+	mockScalingGroup.DesiredCapacity = aws.Int64(2)
+	mockScalingGroup.Instances = MockScalingInstances(2, 0)
+
+	ctx.SetDiscoveredState(&DiscoveredState{
+		Publisher: kubeprovider.EventPublisher{
+			Client: k.Kubernetes,
+		},
+		ScalingGroup: mockScalingGroup,
+		ScalingConfiguration: &scaling.LaunchConfiguration{
+			AwsWorker: w,
+		},
+		ClusterNodes: &corev1.NodeList{
+			Items: []corev1.Node{
+				*MockNode("i-000000000", corev1.ConditionFalse),
+				*MockNode("i-000000001", corev1.ConditionFalse),
+			},
+		},
+		Cluster: MockEksCluster("1.15"),
+	})
+	status.SetCondition(v1alpha1.NewInstanceGroupCondition(v1alpha1.NodesReady, corev1.ConditionFalse))
+
+	// the min size increase is deferred, which would raise the desired capacity
+	g.Expect(ctx.GetDesiredMinSize()).To(gomega.Equal(int64(2)))
+	_, err := ctx.UpdateScalingGroup("some-launch-configuration", &ctx.GetDiscoveredState().ScalingConfiguration)
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(asgMock.UpdateAutoScalingGroupInput).NotTo(gomega.BeNil())
+	g.Expect(aws.Int64Value(asgMock.UpdateAutoScalingGroupInput.MinSize)).To(gomega.Equal(int64(2)))
+	g.Expect(asgMock.UpdateAutoScalingGroupInput.DesiredCapacity).To(gomega.BeNil())
+	g.Expect(status.GetCurrentMin()).To(gomega.Equal(2))
+
+	// a deferred increase is not drift, the spec generation is not applied until the min size is raised
+	ig.SetGeneration(1)
+	g.Expect(status.GetAppliedGeneration()).To(gomega.Equal(int64(0)))
+	g.Expect(ctx.ScalingGroupDrifted(mockScalingGroup)).To(gomega.BeFalse())
+
+	// once the previous scale-up has stabilized the min size is raised
+	ctx.GetDiscoveredState().ClusterNodes.Items = []corev1.Node{
+		*MockNode("i-000000000", corev1.ConditionTrue),
+		*MockNode("i-000000001", corev1.ConditionTrue),
+	}
+	g.Expect(ctx.GetDesiredMinSize()).To(gomega.Equal(int64(4)))
+
+	// nodes which are ready do not defer the increase
+	ctx.GetDiscoveredState().ClusterNodes.Items = []corev1.Node{}
+	status.SetCondition(v1alpha1.NewInstanceGroupCondition(v1alpha1.NodesReady, corev1.ConditionTrue))
+	g.Expect(ctx.GetDesiredMinSize()).To(gomega.Equal(int64(4)))
+
+	// with vCPU weighted capacity the ready instances are compared by their weight rather than their count, a 12 vCPU
+	// group with only its 4 vCPU instance ready has not stabilized
+	status.SetCondition(v1alpha1.NewInstanceGroupCondition(v1alpha1.NodesReady, corev1.ConditionFalse))
+	spec.MinSize = 16
+	mockScalingGroup.MinSize = aws.Int64(12)
+	mockScalingGroup.DesiredCapacity = aws.Int64(12)
+	mockScalingGroup.Instances = []*autoscaling.Instance{
+		{InstanceId: aws.String("i-000000000"), InstanceType: aws.String("m5.2xlarge"), WeightedCapacity: aws.String("8")},
+		{InstanceId: aws.String("i-000000001"), InstanceType: aws.String("m5.xlarge"), WeightedCapacity: aws.String("4")},
+	}
+	ctx.GetDiscoveredState().ClusterNodes.Items = []corev1.Node{
+		*MockNode("i-000000000", corev1.ConditionFalse),
+		*MockNode("i-000000001", corev1.ConditionTrue),
+	}
+	g.Expect(ctx.GetReadyCapacity()).To(gomega.Equal(4))
+	g.Expect(ctx.GetDesiredMinSize()).To(gomega.Equal(int64(12)))
+
+	ctx.GetDiscoveredState().ClusterNodes.Items[0] = *MockNode("i-000000000", corev1.ConditionTrue)
+	g.Expect(ctx.GetReadyCapacity()).To(gomega.Equal(12))
+	g.Expect(ctx.GetDesiredMinSize()).To(gomega.Equal(int64(16)))
+
+	// decreasing the min size is never deferred
+	spec.MinSize = 1
+	g.Expect(ctx.GetDesiredMinSize()).To(gomega.Equal(int64(1)))
+}
+
 func TestScalingGroupUpdatePredicate(t *testing.T) {
 	var (
 		g             = gomega.NewGomegaWithT(t)
@@ -606,6 +1418,7 @@ func TestScalingGroupUpdatePredicate(t *testing.T) {
 				Client: k.Kubernetes,
 			},
 			ScalingGroup: tc.input,
+			Subnets:      configuration.GetSubnets(),
 			ScalingConfiguration: &scaling.LaunchConfiguration{
 				AwsWorker: w,
 				TargetResource: &autoscaling.LaunchConfiguration{
@@ -618,6 +1431,77 @@ func TestScalingGroupUpdatePredicate(t *testing.T) {
 	}
 }
 
+func TestScalingGroupUpdatePredicateBaseCapacityPercentage(t *testing.T) {
+	var (
+		g             = gomega.NewGomegaWithT(t)
+		k             = MockKubernetesClientSet()
+		ig            = MockInstanceGroup()
+		spec          = ig.GetEKSSpec()
+		configuration = ig.GetEKSConfiguration()
+		asgMock       = NewAutoScalingMocker()
+		iamMock       = NewIamMocker()
+		eksMock       = NewEksMocker()
+		ec2Mock       = NewEc2Mocker()
+		ssmMock       = NewSsmMocker()
+	)
+
+	w := MockAwsWorker(asgMock, iamMock, eksMock, ec2Mock, ssmMock)
+	ctx := MockContext(ig, k, w)
+	spec.Type = v1alpha1.LaunchTemplate
+	spec.MinSize = int64(2)
+	spec.MaxSize = int64(20)
+	configuration.SetSubnets([]string{"subnet-1", "subnet-2", "subnet-3"})
+
+	percentage := 50
+	spotRatio := intstr.FromInt(50)
+	configuration.MixedInstancesPolicy = &v1alpha1.MixedInstancesPolicySpec{
+		SpotRatio:              &spotRatio,
+		BaseCapacityPercentage: &percentage,
+		InstanceTypes: []*v1alpha1.InstanceTypeSpec{
+			{
+				Type:   "m5a.xlarge",
+				Weight: 1,
+			},
+		},
+	}
+
+	mockScalingGroup := MockScalingGroup("asg-1", false)
+	mockScalingGroup.LaunchConfigurationName = nil
+	mockScalingGroup.MinSize = aws.Int64(2)
+	mockScalingGroup.MaxSize = aws.Int64(20)
+	mockScalingGroup.DesiredCapacity = aws.Int64(4)
+
+	ctx.SetDiscoveredState(&DiscoveredState{
+		Cluster: MockEksCluster(""),
+		Publisher: kubeprovider.EventPublisher{
+			Client: k.Kubernetes,
+		},
+		ScalingGroup: mockScalingGroup,
+		Subnets:      configuration.GetSubnets(),
+		ScalingConfiguration: &scaling.LaunchTemplate{
+			AwsWorker:      w,
+			TargetResource: &ec2.LaunchTemplate{},
+		},
+	})
+
+	policy, err := ctx.GetDesiredMixedInstancesPolicy("some-launch-template")
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(aws.Int64Value(policy.InstancesDistribution.OnDemandBaseCapacity)).To(gomega.Equal(int64(2)))
+	mockScalingGroup.MixedInstancesPolicy = policy
+	g.Expect(ctx.ScalingGroupUpdateNeeded("some-launch-template")).To(gomega.BeFalse())
+
+	// a change of desired capacity alone does not update the scaling group
+	mockScalingGroup.DesiredCapacity = aws.Int64(10)
+	g.Expect(ctx.ScalingGroupUpdateNeeded("some-launch-template")).To(gomega.BeFalse())
+
+	// the base capacity is recomputed once max drifts
+	spec.MaxSize = int64(30)
+	g.Expect(ctx.ScalingGroupUpdateNeeded("some-launch-template")).To(gomega.BeTrue())
+	policy, err = ctx.GetDesiredMixedInstancesPolicy("some-launch-template")
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(aws.Int64Value(policy.InstancesDistribution.OnDemandBaseCapacity)).To(gomega.Equal(int64(5)))
+}
+
 func TestUpdateManagedPolicies(t *testing.T) {
 	var (
 		g             = gomega.NewGomegaWithT(t)
@@ -723,6 +1607,7 @@ func TestUpdateWithLatestAmiID(t *testing.T) {
 	testLatestAmiID := "ami-12345678"
 	testCustomAmi := "ami-98765432"
 	testBottlerocketAmi := "ami-56789101"
+	testAL2023Ami := "ami-23456789"
 	testWindowsAmi := "ami-56789201"
 
 	w := MockAwsWorker(asgMock, iamMock, eksMock, ec2Mock, ssmMock)
@@ -753,6 +1638,7 @@ func TestUpdateWithLatestAmiID(t *testing.T) {
 		"/aws/service/eks/optimized-ami/1.18/amazon-linux-2/recommended/image_id":                      testLatestAmiID,
 		"/aws/service/eks/optimized-ami/1.18/amazon-linux-2/amazon-eks-node-1.18-v20220226/image_id":   testCustomAmi,
 		"/aws/service/bottlerocket/aws-k8s-1.18/x86_64/1.6.1/image_id":                                 testBottlerocketAmi,
+		"/aws/service/eks/optimized-ami/1.18/amazon-linux-2023/x86_64/standard/recommended/image_id":   testAL2023Ami,
 		"/aws/service/ami-windows-latest/Windows_Server-2019-English-Core-EKS_Optimized-1.18/image_id": testWindowsAmi,
 	}
 	ec2Mock.InstanceTypes = []*ec2.InstanceTypeInfo{
@@ -780,6 +1666,11 @@ func TestUpdateWithLatestAmiID(t *testing.T) {
 			expectedAmi: testBottlerocketAmi,
 			osFamily:    "bottlerocket",
 		},
+		{
+			igImage:     "latest",
+			expectedAmi: testAL2023Ami,
+			osFamily:    "al2023",
+		},
 		{
 			igImage:     "ssm://latest",
 			expectedAmi: testWindowsAmi,
@@ -50,9 +50,27 @@ func (ctx *EksInstanceGroupContext) UpgradeNodes() error {
 		return nil
 	}
 
+	// nodes cordoned by the rolling update strategy are returned to service when another strategy takes over
+	if ctx.IsInstanceRefreshEnabled() || strategyType != kubeprovider.RollingUpdateStrategyName {
+		if err := ctx.UncordonDrainingNodes(); err != nil {
+			return err
+		}
+	}
+
 	// process the upgrade strategy
-	switch strategyType {
-	case kubeprovider.CRDStrategyName:
+	switch {
+	case ctx.IsInstanceRefreshEnabled():
+		ok, err := ctx.ProcessInstanceRefresh()
+		if err != nil {
+			state.Publisher.Publish(kubeprovider.InstanceGroupUpgradeFailedEvent, "instancegroup", instanceGroup.NamespacedName(), "type", "instance-refresh", "error", err.Error())
+			ctx.SetState(v1alpha1.ReconcileErr)
+			return errors.Wrap(err, "failed to process instance refresh")
+		}
+		if ok {
+			break
+		}
+		return nil
+	case strategyType == kubeprovider.CRDStrategyName:
 		ok, err := kubeprovider.ProcessCRDStrategy(ctx.KubernetesClient.KubeDynamic, instanceGroup, scalingConfigName)
 		if err != nil {
 			state.Publisher.Publish(kubeprovider.InstanceGroupUpgradeFailedEvent, "instancegroup", instanceGroup.NamespacedName(), "type", kubeprovider.CRDStrategyName, "error", err.Error())
@@ -63,7 +81,7 @@ func (ctx *EksInstanceGroupContext) UpgradeNodes() error {
 			break
 		}
 		return nil
-	case kubeprovider.RollingUpdateStrategyName:
+	case strategyType == kubeprovider.RollingUpdateStrategyName:
 		req := ctx.NewRollingUpdateRequest()
 		ok, err := kubeprovider.ProcessRollingUpgradeStrategy(req)
 		if err != nil {
@@ -80,12 +98,79 @@ func (ctx *EksInstanceGroupContext) UpgradeNodes() error {
 	}
 	ctx.Log.Info("strategy processing completed", "instancegroup", instanceGroup.NamespacedName(), "strategy", strategy.GetType())
 
+	// nodes no longer use instance-profiles retired by a rename once the strategy has completed
+	if err := ctx.DeleteRetiredInstanceProfiles(); err != nil {
+		return err
+	}
+
 	if ctx.UpdateNodeReadyCondition() {
 		ctx.SetState(v1alpha1.ReconcileModified)
 	}
 	return nil
 }
 
+// ProcessInstanceRefresh rotates the nodes with an autoscaling instance refresh and returns true once it succeeds. The
+// refresh is tracked in status so that following reconciles resume it, a refresh which did not succeed is started again
+func (ctx *EksInstanceGroupContext) ProcessInstanceRefresh() (bool, error) {
+	var (
+		instanceGroup = ctx.GetInstanceGroup()
+		status        = instanceGroup.GetStatus()
+		state         = ctx.GetDiscoveredState()
+		scalingGroup  = state.GetScalingGroup()
+		asgName       = aws.StringValue(scalingGroup.AutoScalingGroupName)
+		refreshId     = status.GetInstanceRefreshId()
+	)
+
+	refreshes, err := ctx.AwsWorker.DescribeInstanceRefreshes(asgName)
+	if err != nil {
+		return false, errors.Wrap(err, "failed to describe instance refreshes")
+	}
+
+	var refresh *autoscaling.InstanceRefresh
+	for _, r := range refreshes {
+		if !common.StringEmpty(refreshId) && aws.StringValue(r.InstanceRefreshId) == refreshId {
+			refresh = r
+			break
+		}
+	}
+
+	// a refresh which is already active, e.g. started before its ID was recorded, is resumed instead of started again
+	if refresh == nil {
+		for _, r := range refreshes {
+			if common.ContainsEqualFold([]string{autoscaling.InstanceRefreshStatusPending, autoscaling.InstanceRefreshStatusInProgress}, aws.StringValue(r.Status)) {
+				refresh = r
+				break
+			}
+		}
+	}
+
+	if refresh != nil {
+		refreshId = aws.StringValue(refresh.InstanceRefreshId)
+		status.SetInstanceRefreshId(refreshId)
+
+		switch refreshStatus := aws.StringValue(refresh.Status); refreshStatus {
+		case autoscaling.InstanceRefreshStatusSuccessful:
+			ctx.Log.Info("instance refresh completed", "instancegroup", instanceGroup.NamespacedName(), "scalinggroup", asgName, "refresh", refreshId)
+			status.SetInstanceRefreshId("")
+			return true, nil
+		case autoscaling.InstanceRefreshStatusPending, autoscaling.InstanceRefreshStatusInProgress, autoscaling.InstanceRefreshStatusCancelling, autoscaling.InstanceRefreshStatusRollbackInProgress:
+			ctx.Log.Info("waiting for instance refresh", "instancegroup", instanceGroup.NamespacedName(), "scalinggroup", asgName, "refresh", refreshId, "status", refreshStatus, "percentage", aws.Int64Value(refresh.PercentageComplete))
+			return false, nil
+		default:
+			ctx.Log.Info("instance refresh did not succeed, will restart", "instancegroup", instanceGroup.NamespacedName(), "scalinggroup", asgName, "refresh", refreshId, "status", refreshStatus, "reason", aws.StringValue(refresh.StatusReason))
+			state.Publisher.Publish(kubeprovider.InstanceGroupUpgradeFailedEvent, "instancegroup", instanceGroup.NamespacedName(), "type", "instance-refresh", "error", aws.StringValue(refresh.StatusReason))
+		}
+	}
+
+	refreshId, err = ctx.AwsWorker.StartInstanceRefresh(asgName, ctx.GetInstanceRefreshPreferences())
+	if err != nil {
+		return false, errors.Wrap(err, "failed to start instance refresh")
+	}
+	ctx.Log.Info("started instance refresh", "instancegroup", instanceGroup.NamespacedName(), "scalinggroup", asgName, "refresh", refreshId)
+	status.SetInstanceRefreshId(refreshId)
+	return false, nil
+}
+
 func (ctx *EksInstanceGroupContext) BootstrapNodes() error {
 	var (
 		state         = ctx.GetDiscoveredState()
@@ -215,6 +300,25 @@ func (ctx *EksInstanceGroupContext) getDriftedInstances(instances []*autoscaling
 	return needsUpdate
 }
 
+// UncordonDrainingNodes returns the nodes of the group which were cordoned by a rolling update to service once the
+// rotation stopped, e.g. because the change was reverted or the rotation was deferred or handed to another strategy
+func (ctx *EksInstanceGroupContext) UncordonDrainingNodes() error {
+	var (
+		state        = ctx.GetDiscoveredState()
+		scalingGroup = state.GetScalingGroup()
+		instanceIds  = make([]string, 0)
+	)
+
+	if scalingGroup == nil {
+		return nil
+	}
+
+	for _, instance := range scalingGroup.Instances {
+		instanceIds = append(instanceIds, aws.StringValue(instance.InstanceId))
+	}
+	return kubeprovider.UncordonDrainingNodes(ctx.KubernetesClient.Kubernetes, state.GetClusterNodes(), instanceIds)
+}
+
 func (ctx *EksInstanceGroupContext) NewRollingUpdateRequest() *kubeprovider.RollingUpdateRequest {
 	var (
 		needsUpdate    []string
@@ -225,6 +329,7 @@ func (ctx *EksInstanceGroupContext) NewRollingUpdateRequest() *kubeprovider.Roll
 		desiredCount   = int(aws.Int64Value(scalingGroup.DesiredCapacity))
 		strategy       = instanceGroup.GetUpgradeStrategy().GetRollingUpdateType()
 		maxUnavailable = strategy.GetMaxUnavailable()
+		batchSize      = strategy.GetBatchSize()
 		asgName        = aws.StringValue(scalingGroup.AutoScalingGroupName)
 	)
 
@@ -252,10 +357,18 @@ func (ctx *EksInstanceGroupContext) NewRollingUpdateRequest() *kubeprovider.Roll
 		unavailableInt = 1
 	}
 
+	var batchInt int
+	if batchSize != nil {
+		batchInt, _ = intstr.GetValueFromIntOrPercent(batchSize, allCount, true)
+	}
+
 	return &kubeprovider.RollingUpdateRequest{
 		AwsWorker:        ctx.AwsWorker,
+		Kubernetes:       ctx.KubernetesClient.Kubernetes,
 		ClusterNodes:     state.GetClusterNodes(),
 		MaxUnavailable:   unavailableInt,
+		BatchSize:        batchInt,
+		DrainTimeout:     strategy.GetDrainTimeout(),
 		DesiredCapacity:  desiredCount,
 		AllInstances:     allInstances,
 		UpdateTargets:    needsUpdate,
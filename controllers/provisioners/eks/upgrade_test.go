@@ -17,7 +17,9 @@ package eks
 
 import (
 	"context"
+	"fmt"
 	"testing"
+	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/autoscaling"
@@ -28,9 +30,13 @@ import (
 	"github.com/onsi/gomega"
 	"github.com/pkg/errors"
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/kubernetes/fake"
+	clienttesting "k8s.io/client-go/testing"
 )
 
 func TestUpgradeCRDStrategyValidation(t *testing.T) {
@@ -336,6 +342,271 @@ func TestUpgradeRollingUpdateStrategyPositive(t *testing.T) {
 	}
 }
 
+func TestUpgradeRollingUpdateStrategyBatchSize(t *testing.T) {
+	var (
+		g       = gomega.NewGomegaWithT(t)
+		k       = MockKubernetesClientSet()
+		ig      = MockInstanceGroup()
+		asgMock = NewAutoScalingMocker()
+		iamMock = NewIamMocker()
+		eksMock = NewEksMocker()
+		ec2Mock = NewEc2Mocker()
+		ssmMock = NewSsmMocker()
+	)
+
+	w := MockAwsWorker(asgMock, iamMock, eksMock, ec2Mock, ssmMock)
+	ctx := MockContext(ig, k, w)
+
+	scalingInstances := MockScalingInstances(0, 3)
+	for _, instance := range scalingInstances {
+		id := aws.StringValue(instance.InstanceId)
+		node := MockNode(id, corev1.ConditionTrue)
+		_, err := k.Kubernetes.CoreV1().Nodes().Create(context.Background(), node, metav1.CreateOptions{})
+		g.Expect(err).NotTo(gomega.HaveOccurred())
+
+		pod := &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      fmt.Sprintf("pod-%v", id),
+				Namespace: "default",
+			},
+			Spec: corev1.PodSpec{
+				NodeName: node.GetName(),
+			},
+		}
+		_, err = k.Kubernetes.CoreV1().Pods("default").Create(context.Background(), pod, metav1.CreateOptions{})
+		g.Expect(err).NotTo(gomega.HaveOccurred())
+	}
+
+	nodes, err := k.Kubernetes.CoreV1().Nodes().List(context.Background(), metav1.ListOptions{})
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+
+	maxUnavailable := intstr.FromInt(3)
+	batchSize := intstr.FromInt(1)
+	strategy := MockAwsRollingUpdateStrategy(&maxUnavailable)
+	strategy.RollingUpdateType.SetBatchSize(&batchSize)
+	ig.SetUpgradeStrategy(strategy)
+
+	mockScalingGroup := &autoscaling.Group{
+		AutoScalingGroupName:    aws.String("some-scaling-group"),
+		Instances:               scalingInstances,
+		DesiredCapacity:         aws.Int64(int64(len(scalingInstances))),
+		LaunchConfigurationName: aws.String("some-launch-config"),
+	}
+	scalingConfig, err := scaling.NewLaunchConfiguration("", w, &scaling.DiscoverConfigurationInput{ScalingGroup: mockScalingGroup})
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+
+	ctx.SetDiscoveredState(&DiscoveredState{
+		Publisher: kubeprovider.EventPublisher{
+			Client: k.Kubernetes,
+		},
+		ScalingGroup:         mockScalingGroup,
+		ScalingConfiguration: scalingConfig,
+		ClusterNodes:         nodes,
+	})
+
+	ig.SetState(v1alpha1.ReconcileModifying)
+	err = ctx.UpgradeNodes()
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(ctx.GetState()).To(gomega.Equal(v1alpha1.ReconcileModifying))
+
+	// only a single batch should be cordoned and drained, it is not terminated while the evicted pod is running
+	g.Expect(asgMock.TerminateInstanceCallCount).To(gomega.Equal(uint(0)))
+
+	var cordoned []string
+	updatedNodes, err := k.Kubernetes.CoreV1().Nodes().List(context.Background(), metav1.ListOptions{})
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	for _, node := range updatedNodes.Items {
+		if node.Spec.Unschedulable {
+			cordoned = append(cordoned, node.GetName())
+			g.Expect(node.GetAnnotations()).To(gomega.HaveKey(kubeprovider.DrainStartedAnnotationKey))
+		}
+	}
+	g.Expect(cordoned).To(gomega.Equal([]string{"node-i-100000000"}))
+
+	var evictions int
+	for _, action := range k.Kubernetes.(*fake.Clientset).Actions() {
+		if action.GetVerb() == "create" && action.GetSubresource() == "eviction" {
+			evictions++
+		}
+	}
+	g.Expect(evictions).To(gomega.Equal(1))
+
+	// the draining batch is persisted on its nodes, a different order of targets does not cordon another batch
+	mockScalingGroup.Instances = []*autoscaling.Instance{scalingInstances[2], scalingInstances[1], scalingInstances[0]}
+	ctx.GetDiscoveredState().ClusterNodes = updatedNodes
+	err = ctx.UpgradeNodes()
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(asgMock.TerminateInstanceCallCount).To(gomega.Equal(uint(0)))
+
+	cordoned = nil
+	updatedNodes, err = k.Kubernetes.CoreV1().Nodes().List(context.Background(), metav1.ListOptions{})
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	for _, node := range updatedNodes.Items {
+		if node.Spec.Unschedulable {
+			cordoned = append(cordoned, node.GetName())
+		}
+	}
+	g.Expect(cordoned).To(gomega.Equal([]string{"node-i-100000000"}))
+
+	// the batch is terminated once the evicted pod is gone
+	err = k.Kubernetes.CoreV1().Pods("default").Delete(context.Background(), "pod-i-100000000", metav1.DeleteOptions{})
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	ctx.GetDiscoveredState().ClusterNodes = updatedNodes
+	err = ctx.UpgradeNodes()
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(asgMock.TerminateInstanceCallCount).To(gomega.Equal(uint(1)))
+}
+
+func TestUpgradeRollingUpdateStrategyDrainTimeout(t *testing.T) {
+	var (
+		g       = gomega.NewGomegaWithT(t)
+		k       = MockKubernetesClientSet()
+		ig      = MockInstanceGroup()
+		asgMock = NewAutoScalingMocker()
+		iamMock = NewIamMocker()
+		eksMock = NewEksMocker()
+		ec2Mock = NewEc2Mocker()
+		ssmMock = NewSsmMocker()
+	)
+
+	w := MockAwsWorker(asgMock, iamMock, eksMock, ec2Mock, ssmMock)
+	ctx := MockContext(ig, k, w)
+
+	scalingInstances := MockScalingInstances(0, 1)
+	id := aws.StringValue(scalingInstances[0].InstanceId)
+	node := MockNode(id, corev1.ConditionTrue)
+	node.Spec.Unschedulable = true
+	node.Annotations = map[string]string{
+		kubeprovider.DrainStartedAnnotationKey: time.Now().Add(-time.Minute).UTC().Format(time.RFC3339),
+	}
+	_, err := k.Kubernetes.CoreV1().Nodes().Create(context.Background(), node, metav1.CreateOptions{})
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "some-pod",
+			Namespace: "default",
+		},
+		Spec: corev1.PodSpec{
+			NodeName: node.GetName(),
+		},
+	}
+	_, err = k.Kubernetes.CoreV1().Pods("default").Create(context.Background(), pod, metav1.CreateOptions{})
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+
+	nodes, err := k.Kubernetes.CoreV1().Nodes().List(context.Background(), metav1.ListOptions{})
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+
+	maxUnavailable := intstr.FromInt(1)
+	batchSize := intstr.FromInt(1)
+	strategy := MockAwsRollingUpdateStrategy(&maxUnavailable)
+	strategy.RollingUpdateType.SetBatchSize(&batchSize)
+	strategy.RollingUpdateType.SetDrainTimeout(120)
+	ig.SetUpgradeStrategy(strategy)
+
+	mockScalingGroup := &autoscaling.Group{
+		AutoScalingGroupName:    aws.String("some-scaling-group"),
+		Instances:               scalingInstances,
+		DesiredCapacity:         aws.Int64(1),
+		LaunchConfigurationName: aws.String("some-launch-config"),
+	}
+	scalingConfig, err := scaling.NewLaunchConfiguration("", w, &scaling.DiscoverConfigurationInput{ScalingGroup: mockScalingGroup})
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+
+	ctx.SetDiscoveredState(&DiscoveredState{
+		Publisher: kubeprovider.EventPublisher{
+			Client: k.Kubernetes,
+		},
+		ScalingGroup:         mockScalingGroup,
+		ScalingConfiguration: scalingConfig,
+		ClusterNodes:         nodes,
+	})
+
+	// evictions are blocked by a disruption budget
+	k.Kubernetes.(*fake.Clientset).PrependReactor("create", "pods", func(action clienttesting.Action) (bool, runtime.Object, error) {
+		if action.GetSubresource() != "eviction" {
+			return false, nil, nil
+		}
+		return true, nil, apierrors.NewTooManyRequests("cannot evict pod as it would violate the pod's disruption budget", 10)
+	})
+
+	// the node is not terminated while its pod is running within the drain timeout
+	ig.SetState(v1alpha1.ReconcileModifying)
+	err = ctx.UpgradeNodes()
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(asgMock.TerminateInstanceCallCount).To(gomega.Equal(uint(0)))
+
+	// the node is terminated once the drain timeout expires, even though evictions are still blocked
+	strategy.RollingUpdateType.SetDrainTimeout(30)
+	err = ctx.UpgradeNodes()
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(asgMock.TerminateInstanceCallCount).To(gomega.Equal(uint(1)))
+}
+
+func TestUpgradeRollingUpdateStrategyUncordonAbandoned(t *testing.T) {
+	var (
+		g       = gomega.NewGomegaWithT(t)
+		k       = MockKubernetesClientSet()
+		ig      = MockInstanceGroup()
+		asgMock = NewAutoScalingMocker()
+		iamMock = NewIamMocker()
+		eksMock = NewEksMocker()
+		ec2Mock = NewEc2Mocker()
+		ssmMock = NewSsmMocker()
+	)
+
+	w := MockAwsWorker(asgMock, iamMock, eksMock, ec2Mock, ssmMock)
+	ctx := MockContext(ig, k, w)
+
+	// the instance is no longer a rotation target, but its node was cordoned by a previous rotation
+	scalingInstances := MockScalingInstances(1, 0)
+	node := MockNode(aws.StringValue(scalingInstances[0].InstanceId), corev1.ConditionTrue)
+	node.Spec.Unschedulable = true
+	node.Annotations = map[string]string{
+		kubeprovider.DrainStartedAnnotationKey: time.Now().UTC().Format(time.RFC3339),
+	}
+	_, err := k.Kubernetes.CoreV1().Nodes().Create(context.Background(), node, metav1.CreateOptions{})
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+
+	nodes, err := k.Kubernetes.CoreV1().Nodes().List(context.Background(), metav1.ListOptions{})
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+
+	maxUnavailable := intstr.FromInt(1)
+	batchSize := intstr.FromInt(1)
+	strategy := MockAwsRollingUpdateStrategy(&maxUnavailable)
+	strategy.RollingUpdateType.SetBatchSize(&batchSize)
+	ig.SetUpgradeStrategy(strategy)
+
+	mockScalingGroup := &autoscaling.Group{
+		AutoScalingGroupName:    aws.String("some-scaling-group"),
+		Instances:               scalingInstances,
+		DesiredCapacity:         aws.Int64(1),
+		LaunchConfigurationName: aws.String("some-launch-config"),
+	}
+	scalingConfig, err := scaling.NewLaunchConfiguration("", w, &scaling.DiscoverConfigurationInput{ScalingGroup: mockScalingGroup})
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+
+	ctx.SetDiscoveredState(&DiscoveredState{
+		Publisher: kubeprovider.EventPublisher{
+			Client: k.Kubernetes,
+		},
+		ScalingGroup:         mockScalingGroup,
+		ScalingConfiguration: scalingConfig,
+		ClusterNodes:         nodes,
+	})
+
+	ig.SetState(v1alpha1.ReconcileModifying)
+	err = ctx.UpgradeNodes()
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(asgMock.TerminateInstanceCallCount).To(gomega.Equal(uint(0)))
+
+	// the node is schedulable again and no longer marked as draining
+	updated, err := k.Kubernetes.CoreV1().Nodes().Get(context.Background(), node.GetName(), metav1.GetOptions{})
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(updated.Spec.Unschedulable).To(gomega.BeFalse())
+	g.Expect(updated.GetAnnotations()).NotTo(gomega.HaveKey(kubeprovider.DrainStartedAnnotationKey))
+}
+
 func TestRotateWarmPool(t *testing.T) {
 	var (
 		g       = gomega.NewGomegaWithT(t)
@@ -0,0 +1,206 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package eks
+
+import (
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"text/template"
+)
+
+// UserDataRenderer renders the userData of an OS family, new OS families can be supported by implementing a
+// renderer and registering it with RegisterUserDataRenderer
+type UserDataRenderer interface {
+	// Render writes the userData for the given bootstrap data
+	Render(w io.Writer, data EKSUserData) error
+	// Compressible returns true if the OS can consume gzip compressed userData
+	Compressible() bool
+}
+
+// TemplateUserDataRenderer renders userData from a text/template
+type TemplateUserDataRenderer struct {
+	Template      string
+	GzipSupported bool
+}
+
+func (r *TemplateUserDataRenderer) Render(w io.Writer, data EKSUserData) error {
+	tmpl, err := template.New("userData").Funcs(template.FuncMap{
+		"ToLower": strings.ToLower,
+		"Quote":   strconv.Quote,
+	}).Parse(r.Template)
+	if err != nil {
+		return err
+	}
+	return tmpl.Execute(w, data)
+}
+
+func (r *TemplateUserDataRenderer) Compressible() bool {
+	return r.GzipSupported
+}
+
+var (
+	userDataRenderersLock sync.RWMutex
+	userDataRenderers     = map[string]UserDataRenderer{
+		OsFamilyWindows:         &TemplateUserDataRenderer{Template: WindowsUserDataTemplate},
+		OsFamilyBottleRocket:    &TemplateUserDataRenderer{Template: BottleRocketUserDataTemplate},
+		OsFamilyAmazonLinux2:    &TemplateUserDataRenderer{Template: AmazonLinux2UserDataTemplate, GzipSupported: true},
+		OsFamilyAmazonLinux2023: &TemplateUserDataRenderer{Template: AmazonLinux2023UserDataTemplate},
+	}
+)
+
+// RegisterUserDataRenderer registers the renderer for an OS family and adds the family to AllowedOsFamilies,
+// registering an existing family replaces its renderer, renderers should be registered before the controller starts
+func RegisterUserDataRenderer(osFamily string, renderer UserDataRenderer) {
+	userDataRenderersLock.Lock()
+	defer userDataRenderersLock.Unlock()
+
+	osFamily = strings.ToLower(osFamily)
+	if _, ok := userDataRenderers[osFamily]; !ok {
+		AllowedOsFamilies = append(AllowedOsFamilies, osFamily)
+	}
+	userDataRenderers[osFamily] = renderer
+}
+
+// GetUserDataRenderer returns the renderer registered for an OS family, falling back to the amazonlinux2 renderer
+func GetUserDataRenderer(osFamily string) UserDataRenderer {
+	userDataRenderersLock.RLock()
+	defer userDataRenderersLock.RUnlock()
+
+	if renderer, ok := userDataRenderers[strings.ToLower(osFamily)]; ok {
+		return renderer
+	}
+	return userDataRenderers[OsFamilyAmazonLinux2]
+}
+
+const (
+	WindowsUserDataTemplate = `
+<powershell>
+  {{range $pre := .PreBootstrap}}{{$pre}}{{end}}
+  [string]$EKSBinDir = "$env:ProgramFiles\Amazon\EKS"
+  [string]$EKSBootstrapScriptName = 'Start-EKSBootstrap.ps1'
+  [string]$EKSBootstrapScriptFile = "$EKSBinDir\$EKSBootstrapScriptName"
+  [string]$IMDSToken=(curl -UseBasicParsing -Method PUT "http://169.254.169.254/latest/api/token" -H @{ "X-aws-ec2-metadata-token-ttl-seconds" = "21600"} | % { Echo $_.Content})
+  [string]$InstanceID=(curl -UseBasicParsing -Method GET "http://169.254.169.254/latest/meta-data/instance-id" -H @{ "X-aws-ec2-metadata-token" = "$IMDSToken"} | % { Echo $_.Content})
+  [string]$Lifecycle=(curl -UseBasicParsing -Method GET "http://169.254.169.254/latest/meta-data/autoscaling/target-lifecycle-state" -H @{ "X-aws-ec2-metadata-token" = "$IMDSToken"} | % { Echo $_.Content})
+  if ($Lifecycle -like "*Warmed*") {
+    Echo "Not starting Kubelet due to warmed state."
+    & C:\ProgramData\Amazon\EC2-Windows\Launch\Scripts\InitializeInstance.ps1 -Schedule
+  } else {
+    & $EKSBootstrapScriptFile -EKSClusterName {{ .ClusterName }} {{ .Arguments }} 3>&1 4>&1 5>&1 6>&1
+    {{range $post := .PostBootstrap}}{{$post}}{{end}}
+  }
+</powershell>`
+
+	BottleRocketUserDataTemplate = `
+{{range $pre := .PreBootstrap}}{{$pre}}{{end}}
+[settings.kubernetes]
+api-server   = "{{ .ApiEndpoint }}"
+cluster-certificate = "{{ .ClusterCA }}"
+cluster-name = "{{ .ClusterName }}"
+{{- if .MaxPods}}
+max-pods = {{ .MaxPods }}
+{{- end}}
+[settings.kubernetes.node-labels]
+{{- range $key, $value := .NodeLabels }}
+"{{ $key }}" = "{{ $value }}"
+{{- end}}
+[settings.kubernetes.node-taints]
+{{- range .NodeTaints}}
+"{{ .Key }}" = "{{ .Value }}:{{ .Effect }}"
+{{- end}}
+{{range $post := .PostBootstrap}}{{$post}}{{end}}
+`
+
+	AmazonLinux2UserDataTemplate = `{{ .Shebang }}
+{{range $pre := .PreBootstrap}}{{$pre}}{{end}}
+{{- range .MountOptions}}
+mkfs.{{ .FileSystem | ToLower }} {{ .Device }}
+mkdir {{ .Mount }}
+mount {{ .Device }} {{ .Mount }}
+mount
+{{- if .Persistance}}
+echo "{{ .Device}}    {{ .Mount }}    {{ .FileSystem | ToLower }}    defaults    0    2" >> /etc/fstab
+{{- end}}
+{{- end}}
+if [[ $(type -P $(which aws)) ]] && [[ $(type -P $(which jq)) ]] ; then
+	TOKEN=$(curl -X PUT "http://169.254.169.254/latest/api/token" -H "X-aws-ec2-metadata-token-ttl-seconds: 21600")
+	INSTANCE_ID=$(curl url -H "X-aws-ec2-metadata-token: $TOKEN" http://169.254.169.254/latest/meta-data/instance-id)
+	REGION=$(curl url -H "X-aws-ec2-metadata-token: $TOKEN" http://169.254.169.254/latest/meta-data/placement/region)
+	LIFECYCLE=$(curl url -H "X-aws-ec2-metadata-token: $TOKEN" http://169.254.169.254/latest/meta-data/autoscaling/target-lifecycle-state)
+	if [[ $LIFECYCLE == *"Warmed"* ]]; then
+		rm /var/lib/cloud/instances/$INSTANCE_ID/sem/config_scripts_user
+		exit 0
+	fi
+fi
+{{- if .CredentialProviderConfig}}
+mkdir -p $(dirname {{ .CredentialProviderConfigPath }})
+cat <<'EOF' > {{ .CredentialProviderConfigPath }}
+{{ .CredentialProviderConfig }}
+EOF
+{{- end}}
+set -o xtrace
+/etc/eks/bootstrap.sh {{ .ClusterName }} {{ .Arguments }}
+set +o xtrace
+{{range $post := .PostBootstrap}}{{$post}}{{end}}`
+
+	// AmazonLinux2023UserDataTemplate renders a nodeadm NodeConfig, the script part runs before nodeadm starts the kubelet
+	AmazonLinux2023UserDataTemplate = `MIME-Version: 1.0
+Content-Type: multipart/mixed; boundary="//"
+
+--//
+Content-Type: application/node.eks.aws
+
+---
+apiVersion: node.eks.aws/v1alpha1
+kind: NodeConfig
+spec:
+  cluster:
+    name: {{ .ClusterName }}
+    apiServerEndpoint: {{ .ApiEndpoint }}
+    certificateAuthority: {{ .ClusterCA }}
+    cidr: {{ .ServiceCIDR }}
+  kubelet:
+    flags:
+{{- range .KubeletFlags}}
+    - {{ Quote . }}
+{{- end}}
+
+--//
+Content-Type: text/x-shellscript; charset="us-ascii"
+
+{{ .Shebang }}
+{{range $pre := .PreBootstrap}}{{$pre}}{{end}}
+{{- range .MountOptions}}
+mkfs.{{ .FileSystem | ToLower }} {{ .Device }}
+mkdir {{ .Mount }}
+mount {{ .Device }} {{ .Mount }}
+mount
+{{- if .Persistance}}
+echo "{{ .Device}}    {{ .Mount }}    {{ .FileSystem | ToLower }}    defaults    0    2" >> /etc/fstab
+{{- end}}
+{{- end}}
+{{- if .CredentialProviderConfig}}
+mkdir -p $(dirname {{ .CredentialProviderConfigPath }})
+cat <<'EOF' > {{ .CredentialProviderConfigPath }}
+{{ .CredentialProviderConfig }}
+EOF
+{{- end}}
+{{range $post := .PostBootstrap}}{{$post}}{{end}}
+--//--
+`
+)
@@ -0,0 +1,74 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package eks
+
+import (
+	"encoding/base64"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/onsi/gomega"
+)
+
+type fakeUserDataRenderer struct{}
+
+func (r *fakeUserDataRenderer) Render(w io.Writer, data EKSUserData) error {
+	_, err := fmt.Fprintf(w, "cluster=%v endpoint=%v", data.ClusterName, data.ApiEndpoint)
+	return err
+}
+
+func (r *fakeUserDataRenderer) Compressible() bool {
+	return false
+}
+
+func TestRegisterUserDataRenderer(t *testing.T) {
+	var (
+		g       = gomega.NewGomegaWithT(t)
+		k       = MockKubernetesClientSet()
+		ig      = MockInstanceGroup()
+		asgMock = NewAutoScalingMocker()
+		iamMock = NewIamMocker()
+		eksMock = NewEksMocker()
+		ec2Mock = NewEc2Mocker()
+		ssmMock = NewSsmMocker()
+	)
+
+	w := MockAwsWorker(asgMock, iamMock, eksMock, ec2Mock, ssmMock)
+	ctx := MockContext(ig, k, w)
+
+	allowed := AllowedOsFamilies
+	defer func() {
+		AllowedOsFamilies = allowed
+		delete(userDataRenderers, "flatcar")
+	}()
+
+	// unregistered families are rejected
+	ig.SetAnnotations(map[string]string{OsFamilyAnnotation: "flatcar"})
+	g.Expect(ctx.ValidateOsFamily()).To(gomega.HaveOccurred())
+
+	RegisterUserDataRenderer("Flatcar", &fakeUserDataRenderer{})
+	g.Expect(ctx.ValidateOsFamily()).NotTo(gomega.HaveOccurred())
+	g.Expect(AllowedOsFamilies).To(gomega.ContainElement("flatcar"))
+
+	userData := ctx.GetBasicUserData("foo", "", "", UserDataPayload{}, nil)
+	decoded, err := base64.StdEncoding.DecodeString(userData)
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(string(decoded)).To(gomega.Equal("cluster=foo endpoint=foo.amazonaws.com"))
+
+	// unknown families fall back to amazonlinux2
+	g.Expect(GetUserDataRenderer("unknown")).To(gomega.Equal(userDataRenderers[OsFamilyAmazonLinux2]))
+}
@@ -21,10 +21,12 @@ import (
 	"strconv"
 
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/arn"
 	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/aws-sdk-go/service/eks"
 	"github.com/aws/aws-sdk-go/service/iam"
 	v1alpha1 "github.com/keikoproj/instance-manager/api/instancemgr/v1alpha1"
+	"github.com/keikoproj/instance-manager/controllers/common"
 	awsprovider "github.com/keikoproj/instance-manager/controllers/providers/aws"
 	"github.com/keikoproj/instance-manager/controllers/provisioners"
 	"github.com/pkg/errors"
@@ -132,7 +134,8 @@ func (ctx *FargateInstanceGroupContext) Create() error {
 	var arn string
 	instanceGroup := ctx.GetInstanceGroup()
 	spec := instanceGroup.GetEKSFargateSpec()
-	if instanceGroup.GetEKSFargateSpec().GetPodExecutionRoleArn() == "" {
+	// the default role is only managed when an existing pod execution role is not provided
+	if !spec.HasExistingRole() {
 		err := ctx.AwsWorker.CreateDefaultFargateRole()
 		if err == nil {
 			ctx.Log.Info("Created default role",
@@ -170,6 +173,26 @@ func (ctx *FargateInstanceGroupContext) Create() error {
 			"instancegroup",
 			instanceGroup.NamespacedName())
 
+		err = ctx.updateManagedPolicies()
+		if err != nil {
+			ctx.Log.Error(err,
+				"Failed to update the managed policies of role",
+				"instancegroup",
+				instanceGroup.NamespacedName())
+			return err
+		}
+
+		// requeue instead of blocking while a new default role propagates
+		if remaining := ctx.AwsWorker.GetPropagationDelayRemaining(role.CreateDate); remaining > 0 {
+			ctx.Log.Info("waiting for default role propagation",
+				"instancegroup",
+				instanceGroup.NamespacedName(),
+				"remaining",
+				remaining)
+			ctx.RequeueInterval = remaining
+			return nil
+		}
+
 	} else {
 		arn = spec.GetPodExecutionRoleArn()
 	}
@@ -207,6 +230,18 @@ func (ctx *FargateInstanceGroupContext) Create() error {
 func (ctx *FargateInstanceGroupContext) CloudDiscovery() error {
 	ctx.processParameters()
 
+	spec := ctx.GetInstanceGroup().GetEKSFargateSpec()
+	if !spec.HasExistingRole() {
+		roleName := ctx.AwsWorker.Parameters["DefaultRoleName"].(string)
+		if _, ok := ctx.AwsWorker.RoleExist(roleName); ok {
+			policies, err := ctx.AwsWorker.ListRolePolicies(roleName)
+			if err != nil {
+				return errors.Wrap(err, "failed to list attached role policies")
+			}
+			ctx.DiscoveredState.AttachedPolicies = policies
+		}
+	}
+
 	profile, err := ctx.AwsWorker.DescribeFargateProfile()
 	if err != nil {
 		profile = &eks.FargateProfile{
@@ -226,7 +261,26 @@ func (ctx *FargateInstanceGroupContext) Delete() error {
 	spec := instanceGroup.GetEKSFargateSpec()
 
 	worker := ctx.AwsWorker
-	if spec.GetPodExecutionRoleArn() == "" {
+	// never delete a provided pod execution role, only the default role created by the controller
+	if !spec.HasExistingRole() {
+		// additional policies must be detached before the role can be deleted
+		if attached := ctx.getAttachedManagedPolicies(); len(attached) > 0 {
+			err := worker.DetachManagedPolicies(worker.Parameters["DefaultRoleName"].(string), attached)
+			if err != nil {
+				ctx.Log.Error(err,
+					"Detaching the managed policies failed.",
+					"instancegroup",
+					instanceGroup.NamespacedName())
+				return err
+			}
+			ctx.Log.Info("Detached managed policies.",
+				"instancegroup",
+				instanceGroup.NamespacedName(),
+				"policies",
+				attached)
+			return nil
+		}
+
 		err := worker.DetachDefaultPolicyFromDefaultRole()
 		// Policy was detached
 		if err == nil {
@@ -300,6 +354,14 @@ func (ctx *FargateInstanceGroupContext) Delete() error {
 
 func (ctx *FargateInstanceGroupContext) Update() error {
 	instanceGroup := ctx.GetInstanceGroup()
+	spec := instanceGroup.GetEKSFargateSpec()
+	// the policies of the default role can change, the fargate profile itself is immutable
+	if !spec.HasExistingRole() {
+		if err := ctx.updateManagedPolicies(); err != nil {
+			return err
+		}
+	}
+
 	annos := instanceGroup.GetObjectMeta().GetAnnotations()
 	// If there is a last-applied-configuration then assume
 	// this is an update and throw an exception
@@ -369,3 +431,72 @@ func (ctx *FargateInstanceGroupContext) GetState() v1alpha1.ReconcileState {
 func (ctx *FargateInstanceGroupContext) Locked() bool {
 	return false
 }
+
+// getManagedPoliciesList returns the ARNs of the additional managed policies of the default role
+func (ctx *FargateInstanceGroupContext) getManagedPoliciesList() []string {
+	spec := ctx.GetInstanceGroup().GetEKSFargateSpec()
+	managedPolicies := make([]string, 0)
+	for _, name := range spec.GetManagedPolicies() {
+		switch {
+		case arn.IsARN(name):
+			managedPolicies = append(managedPolicies, name)
+		default:
+			managedPolicies = append(managedPolicies, fmt.Sprintf("%s/%s", awsprovider.IAMPolicyPrefix, name))
+		}
+	}
+	return managedPolicies
+}
+
+// getAttachedManagedPolicies returns the ARNs of the policies attached to the default role, except the default policy
+func (ctx *FargateInstanceGroupContext) getAttachedManagedPolicies() []string {
+	attached := make([]string, 0)
+	for _, p := range ctx.GetDiscoveredState().GetAttachedPolicies() {
+		policyArn := aws.StringValue(p.PolicyArn)
+		if policyArn == awsprovider.DefaultFargatePolicyArn {
+			continue
+		}
+		attached = append(attached, policyArn)
+	}
+	return attached
+}
+
+// updateManagedPolicies attaches and detaches the additional managed policies of the default role
+func (ctx *FargateInstanceGroupContext) updateManagedPolicies() error {
+	var (
+		instanceGroup   = ctx.GetInstanceGroup()
+		roleName        = ctx.AwsWorker.Parameters["DefaultRoleName"].(string)
+		managedPolicies = ctx.getManagedPoliciesList()
+		attached        = ctx.getAttachedManagedPolicies()
+		needsAttach     = make([]string, 0)
+		needsDetach     = make([]string, 0)
+	)
+
+	for _, policy := range managedPolicies {
+		if !common.ContainsString(attached, policy) {
+			needsAttach = append(needsAttach, policy)
+		}
+	}
+
+	for _, policy := range attached {
+		if !common.ContainsString(managedPolicies, policy) {
+			needsDetach = append(needsDetach, policy)
+		}
+	}
+
+	if len(needsAttach) == 0 && len(needsDetach) == 0 {
+		return nil
+	}
+
+	err := ctx.AwsWorker.AttachManagedPolicies(roleName, needsAttach)
+	if err != nil {
+		return err
+	}
+
+	err = ctx.AwsWorker.DetachManagedPolicies(roleName, needsDetach)
+	if err != nil {
+		return err
+	}
+
+	ctx.Log.Info("updated managed policies", "instancegroup", instanceGroup.NamespacedName(), "iamrole", roleName)
+	return nil
+}
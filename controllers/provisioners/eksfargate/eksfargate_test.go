@@ -16,6 +16,7 @@ limitations under the License.
 package eksfargate
 
 import (
+	"reflect"
 	"strings"
 	"testing"
 	"time"
@@ -91,10 +92,28 @@ type stubIAM struct {
 	DetachRolePolicyFail                  bool
 	MakeDeleteRoleFail                    bool
 	DeleteRoleNoSuchEntityException       bool
+	CreateRoleCallCount                   uint
+	DeleteRoleCallCount                   uint
+	AttachRolePolicyCallCount             uint
+	DetachRolePolicyCallCount             uint
+	AttachedPolicies                      []*iam.AttachedPolicy
+	AttachedPolicyArns                    []string
+	DetachedPolicyArns                    []string
+	RoleCreateDate                        *time.Time
+}
+
+func (s *stubIAM) ListAttachedRolePoliciesPages(input *iam.ListAttachedRolePoliciesInput, callback func(*iam.ListAttachedRolePoliciesOutput, bool) bool) error {
+	page := &iam.ListAttachedRolePoliciesOutput{
+		AttachedPolicies: s.AttachedPolicies,
+	}
+	callback(page, false)
+	return nil
 }
 
 func (s *stubIAM) DetachRolePolicy(input *iam.DetachRolePolicyInput) (*iam.DetachRolePolicyOutput, error) {
+	s.DetachRolePolicyCallCount++
 	if s.DetachRolePolicyFail == false {
+		s.DetachedPolicyArns = append(s.DetachedPolicyArns, aws.StringValue(input.PolicyArn))
 		output := &iam.DetachRolePolicyOutput{}
 		return output, nil
 	} else {
@@ -105,6 +124,7 @@ func (s *stubIAM) DetachRolePolicy(input *iam.DetachRolePolicyInput) (*iam.Detac
 	}
 }
 func (s *stubIAM) DeleteRole(input *iam.DeleteRoleInput) (*iam.DeleteRoleOutput, error) {
+	s.DeleteRoleCallCount++
 	if s.MakeDeleteRoleFail == false {
 		output := &iam.DeleteRoleOutput{}
 		return output, nil
@@ -116,6 +136,7 @@ func (s *stubIAM) DeleteRole(input *iam.DeleteRoleInput) (*iam.DeleteRoleOutput,
 	}
 }
 func (s *stubIAM) CreateRole(input *iam.CreateRoleInput) (*iam.CreateRoleOutput, error) {
+	s.CreateRoleCallCount++
 	if s.MakeCreateRoleFail == false {
 		if s.CreateRoleDupFound {
 			return nil, awserr.New(iam.ErrCodeEntityAlreadyExistsException, "duplicate found", errors.New(""))
@@ -134,7 +155,8 @@ func (s *stubIAM) GetRole(input *iam.GetRoleInput) (*iam.GetRoleOutput, error) {
 	if s.MakeGetRoleFail == false {
 		output := &iam.GetRoleOutput{
 			Role: &iam.Role{
-				Arn: aws.String("eksfargate::dummy_arn"),
+				Arn:        aws.String("eksfargate::dummy_arn"),
+				CreateDate: s.RoleCreateDate,
 			},
 		}
 		return output, nil
@@ -143,7 +165,9 @@ func (s *stubIAM) GetRole(input *iam.GetRoleInput) (*iam.GetRoleOutput, error) {
 	}
 }
 func (s *stubIAM) AttachRolePolicy(input *iam.AttachRolePolicyInput) (*iam.AttachRolePolicyOutput, error) {
+	s.AttachRolePolicyCallCount++
 	if s.MakeAttachRolePolicyFail == false {
+		s.AttachedPolicyArns = append(s.AttachedPolicyArns, aws.StringValue(input.PolicyArn))
 		return &iam.AttachRolePolicyOutput{}, nil
 	} else {
 		return nil, errors.New("attach role policy failed")
@@ -246,9 +270,6 @@ func (f *FakeIG) getInstanceGroup() *v1alpha1.InstanceGroup {
 }
 func (u *EksFargateUnitTest) BuildProvisioner(t *testing.T) *FargateInstanceGroupContext {
 
-	// Speeds up sleep in the AttachRolePolicy logic
-	awsprovider.DefaultInstanceProfilePropagationDelay = time.Millisecond * 1
-
 	aws := &awsprovider.AwsWorker{
 		EksClient: &stubEKS{
 			ProfileBasic:            u.ProfileBasic,
@@ -562,6 +583,34 @@ func TestCreateWithSuppliedArnSuccessProfileCreation(t *testing.T) {
 		t.Fatal("TestCreateWithSuppliedArnSuccessProfileCreation: got error, expected: nil")
 	}
 }
+func TestCreateAndDeleteWithSuppliedArnSkipsRoleManagement(t *testing.T) {
+	ig := FakeIG{}
+	instanceGroup := ig.getInstanceGroup()
+	instanceGroup.Spec.EKSFargateSpec.SetClusterName("TestNameCluster")
+	instanceGroup.Spec.EKSFargateSpec.SetPodExecutionRoleArn("arn:aws:iam::123456789012:role/MyPodRole")
+	testCase := EksFargateUnitTest{
+		InstanceGroup: instanceGroup,
+		CheckArnFor:   "arn:aws:iam::123456789012:role/MyPodRole",
+	}
+	ctx := testCase.BuildProvisioner(t)
+	iamStub := ctx.AwsWorker.IamClient.(*stubIAM)
+
+	if err := ctx.Create(); err != nil {
+		t.Fatalf("TestCreateAndDeleteWithSuppliedArnSkipsRoleManagement: expected nil on create.  Got %v", err)
+	}
+	if err := ctx.Delete(); err != nil {
+		t.Fatalf("TestCreateAndDeleteWithSuppliedArnSkipsRoleManagement: expected nil on delete.  Got %v", err)
+	}
+	if iamStub.CreateRoleCallCount != 0 || iamStub.AttachRolePolicyCallCount != 0 {
+		t.Fatalf("TestCreateAndDeleteWithSuppliedArnSkipsRoleManagement: expected no role creation.  Got %v CreateRole and %v AttachRolePolicy calls", iamStub.CreateRoleCallCount, iamStub.AttachRolePolicyCallCount)
+	}
+	if iamStub.DeleteRoleCallCount != 0 || iamStub.DetachRolePolicyCallCount != 0 {
+		t.Fatalf("TestCreateAndDeleteWithSuppliedArnSkipsRoleManagement: expected no role deletion.  Got %v DeleteRole and %v DetachRolePolicy calls", iamStub.DeleteRoleCallCount, iamStub.DetachRolePolicyCallCount)
+	}
+	if instanceGroup.GetState() != v1alpha1.ReconcileDeleting {
+		t.Fatalf("TestCreateAndDeleteWithSuppliedArnSkipsRoleManagement: expected ReconcileDeleting state.  Got %v", instanceGroup.GetState())
+	}
+}
 func TestCreateWithoutArnCreateRoleFail(t *testing.T) {
 	ig := FakeIG{}
 	instanceGroup := ig.getInstanceGroup()
@@ -646,6 +695,37 @@ func TestCreateWithoutArnCreateProfileSucceeds(t *testing.T) {
 		t.Fatalf("TestCreateWithoutArnCreateProfileSucceeds: expected ReconcileModifying state.  Got %v", instanceGroup.GetState())
 	}
 }
+func TestCreateWithoutArnWaitsForRolePropagation(t *testing.T) {
+	ig := FakeIG{}
+	instanceGroup := ig.getInstanceGroup()
+	instanceGroup.Spec.EKSFargateSpec.SetClusterName("TestNameCluster")
+	testCase := EksFargateUnitTest{
+		InstanceGroup:      instanceGroup,
+		CreateRoleDupFound: true,
+	}
+	ctx := testCase.BuildProvisioner(t)
+	iamStub := ctx.AwsWorker.IamClient.(*stubIAM)
+	ctx.AwsWorker.InstanceProfilePropagationDelay = time.Minute
+
+	iamStub.RoleCreateDate = aws.Time(time.Now())
+	if err := ctx.Create(); err != nil {
+		t.Fatalf("TestCreateWithoutArnWaitsForRolePropagation: expected nil, got %v", err)
+	}
+	if instanceGroup.GetState() != v1alpha1.ReconcileInit {
+		t.Fatalf("TestCreateWithoutArnWaitsForRolePropagation: expected profile creation to wait, got state %v", instanceGroup.GetState())
+	}
+	if interval := ctx.GetRequeueInterval(); interval <= 0 || interval > time.Minute {
+		t.Fatalf("TestCreateWithoutArnWaitsForRolePropagation: expected a requeue within the propagation delay, got %v", interval)
+	}
+
+	iamStub.RoleCreateDate = aws.Time(time.Now().Add(-time.Minute))
+	if err := ctx.Create(); err != nil {
+		t.Fatalf("TestCreateWithoutArnWaitsForRolePropagation: expected nil, got %v", err)
+	}
+	if instanceGroup.GetState() != v1alpha1.ReconcileModifying {
+		t.Fatalf("TestCreateWithoutArnWaitsForRolePropagation: expected ReconcileModifying state.  Got %v", instanceGroup.GetState())
+	}
+}
 func TestCreateWithoutArnCreateProfileFails(t *testing.T) {
 	ig := FakeIG{}
 	instanceGroup := ig.getInstanceGroup()
@@ -710,6 +790,60 @@ func TestUpdate1(t *testing.T) {
 		t.Fatalf("TestUpdate1: bad error message.  Got %v", err.Error())
 	}
 }
+func TestUpdateManagedPolicies(t *testing.T) {
+	ig := FakeIG{}
+	instanceGroup := ig.getInstanceGroup()
+	instanceGroup.Spec.EKSFargateSpec.SetClusterName("TestNameCluster")
+	instanceGroup.Spec.EKSFargateSpec.SetManagedPolicies([]string{"CloudWatchAgentServerPolicy", "arn:aws:iam::123456789012:policy/custom"})
+	testCase := EksFargateUnitTest{
+		InstanceGroup:       instanceGroup,
+		ProfileFromDescribe: getProfile(eks.FargateProfileStatusActive),
+	}
+	ctx := testCase.BuildProvisioner(t)
+	iamStub := ctx.AwsWorker.IamClient.(*stubIAM)
+	iamStub.AttachedPolicies = []*iam.AttachedPolicy{
+		{PolicyArn: aws.String(awsprovider.DefaultFargatePolicyArn)},
+		{PolicyArn: aws.String("arn:aws:iam::aws:policy/AmazonS3ReadOnlyAccess")},
+	}
+
+	if err := ctx.CloudDiscovery(); err != nil {
+		t.Fatal(err)
+	}
+	if err := ctx.Update(); err != nil {
+		t.Fatalf("TestUpdateManagedPolicies: expected nil on update.  Got %v", err)
+	}
+	expectedAttached := []string{"arn:aws:iam::aws:policy/CloudWatchAgentServerPolicy", "arn:aws:iam::123456789012:policy/custom"}
+	if !reflect.DeepEqual(iamStub.AttachedPolicyArns, expectedAttached) {
+		t.Fatalf("TestUpdateManagedPolicies: expected %v to be attached.  Got %v", expectedAttached, iamStub.AttachedPolicyArns)
+	}
+	expectedDetached := []string{"arn:aws:iam::aws:policy/AmazonS3ReadOnlyAccess"}
+	if !reflect.DeepEqual(iamStub.DetachedPolicyArns, expectedDetached) {
+		t.Fatalf("TestUpdateManagedPolicies: expected %v to be detached.  Got %v", expectedDetached, iamStub.DetachedPolicyArns)
+	}
+
+	// removing the policies from the spec detaches them, the default policy is kept
+	instanceGroup.Spec.EKSFargateSpec.SetManagedPolicies(nil)
+	iamStub.AttachedPolicies = []*iam.AttachedPolicy{
+		{PolicyArn: aws.String(awsprovider.DefaultFargatePolicyArn)},
+		{PolicyArn: aws.String("arn:aws:iam::aws:policy/CloudWatchAgentServerPolicy")},
+		{PolicyArn: aws.String("arn:aws:iam::123456789012:policy/custom")},
+	}
+	iamStub.AttachedPolicyArns = nil
+	iamStub.DetachedPolicyArns = nil
+
+	if err := ctx.CloudDiscovery(); err != nil {
+		t.Fatal(err)
+	}
+	if err := ctx.Update(); err != nil {
+		t.Fatalf("TestUpdateManagedPolicies: expected nil on update.  Got %v", err)
+	}
+	if len(iamStub.AttachedPolicyArns) != 0 {
+		t.Fatalf("TestUpdateManagedPolicies: expected no policies to be attached.  Got %v", iamStub.AttachedPolicyArns)
+	}
+	if !reflect.DeepEqual(iamStub.DetachedPolicyArns, expectedAttached) {
+		t.Fatalf("TestUpdateManagedPolicies: expected %v to be detached.  Got %v", expectedAttached, iamStub.DetachedPolicyArns)
+	}
+}
 func TestDeleteWithArnDeleteProfileSuccess(t *testing.T) {
 	ig := FakeIG{}
 	instanceGroup := ig.getInstanceGroup()
@@ -16,14 +16,22 @@ limitations under the License.
 package eksfargate
 
 import (
+	"time"
+
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/iam"
 	"github.com/go-logr/logr"
 	"github.com/keikoproj/instance-manager/api/instancemgr/v1alpha1"
 	awsprovider "github.com/keikoproj/instance-manager/controllers/providers/aws"
 )
 
 type DiscoveredState struct {
-	ProfileStatus string
+	ProfileStatus    string
+	AttachedPolicies []*iam.AttachedPolicy
+}
+
+func (ds *DiscoveredState) GetAttachedPolicies() []*iam.AttachedPolicy {
+	return ds.AttachedPolicies
 }
 
 func (ds *DiscoveredState) GetProfileStatus() string {
@@ -38,6 +46,13 @@ type FargateInstanceGroupContext struct {
 	AwsWorker       awsprovider.AwsWorker
 	DiscoveredState DiscoveredState
 	Log             logr.Logger
+	// RequeueInterval is the remaining propagation delay of a new default role, zero uses the controller default
+	RequeueInterval time.Duration
+}
+
+// GetRequeueInterval returns how long to wait before the next reconcile of an ongoing state
+func (ctx *FargateInstanceGroupContext) GetRequeueInterval() time.Duration {
+	return ctx.RequeueInterval
 }
 
 func (ctx *FargateInstanceGroupContext) GetDiscoveredState() *DiscoveredState {
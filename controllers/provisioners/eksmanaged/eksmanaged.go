@@ -16,13 +16,16 @@ limitations under the License.
 package eksmanaged
 
 import (
-	"reflect"
 	"strings"
+	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/eks"
 	"github.com/keikoproj/instance-manager/api/instancemgr/v1alpha1"
 	awsprovider "github.com/keikoproj/instance-manager/controllers/providers/aws"
 	"github.com/keikoproj/instance-manager/controllers/provisioners"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
 )
 
 const (
@@ -31,8 +34,18 @@ const (
 	UnrecoverableErrorString       = "UnrecoverableError"
 	UnrecoverableDeleteErrorString = "UnrecoverableDeleteError"
 	ProvisionerName                = "eks-managed"
+
+	// DefaultPollInterval is how often a node group in an ongoing state is polled
+	DefaultPollInterval = 10 * time.Second
 )
 
+// NodeGroupTaintEffects maps kubernetes taint effects to their managed node group equivalent
+var NodeGroupTaintEffects = map[corev1.TaintEffect]string{
+	corev1.TaintEffectNoSchedule:       eks.TaintEffectNoSchedule,
+	corev1.TaintEffectPreferNoSchedule: eks.TaintEffectPreferNoSchedule,
+	corev1.TaintEffectNoExecute:        eks.TaintEffectNoExecute,
+}
+
 func (ctx *EksManagedInstanceGroupContext) CloudDiscovery() error {
 	ctx.processParameters()
 	var (
@@ -52,6 +65,9 @@ func (ctx *EksManagedInstanceGroupContext) CloudDiscovery() error {
 		currentStatus := aws.StringValue(createdResource.Status)
 		discoveredState.SetSelfNodeGroup(createdResource)
 		discoveredState.SetCurrentState(currentStatus)
+		if err := ctx.checkWaitTimeout(createdResource); err != nil {
+			return err
+		}
 		status.SetCurrentMax(int(aws.Int64Value(createdResource.ScalingConfig.MaxSize)))
 		status.SetCurrentMin(int(aws.Int64Value(createdResource.ScalingConfig.MinSize)))
 		status.SetLifecycle("normal")
@@ -151,7 +167,15 @@ func (ctx *EksManagedInstanceGroupContext) isUpdateNeeded() bool {
 		condition = true
 	}
 
-	if !reflect.DeepEqual(existingLabels, aws.StringValueMap(selfNodeGroup.Labels)) {
+	if _, ok := ctx.AwsWorker.GetLabelsUpdatePayload(aws.StringValueMap(selfNodeGroup.Labels), existingLabels); ok {
+		condition = true
+	}
+
+	if _, ok := ctx.AwsWorker.GetTaintsUpdatePayload(selfNodeGroup.Taints, ctx.getTaints()); ok {
+		condition = true
+	}
+
+	if awsprovider.IsNodeGroupUpdateConfigDrifted(selfNodeGroup.UpdateConfig, ctx.getUpdateConfig()) {
 		condition = true
 	}
 	return condition
@@ -171,7 +195,7 @@ func (ctx *EksManagedInstanceGroupContext) Update() error {
 	}
 
 	if ctx.isUpdateNeeded() {
-		err := ctx.AwsWorker.UpdateManagedNodeGroup(nodeGroup, desired, nodeLabels)
+		err := ctx.AwsWorker.UpdateManagedNodeGroup(nodeGroup, desired, nodeLabels, ctx.getTaints())
 		if err != nil {
 			return err
 		}
@@ -227,6 +251,8 @@ func New(p provisioners.ProvisionerInput) *EksManagedInstanceGroupContext {
 		AwsWorker:        p.AwsWorker,
 		Log:              p.Log.WithName("eks-managed"),
 		DiscoveredState:  &DiscoveredState{},
+		PollInterval:     p.ManagedPollInterval,
+		WaitTimeout:      p.ManagedWaitTimeout,
 	}
 
 	instanceGroup := ctx.GetInstanceGroup()
@@ -235,6 +261,29 @@ func New(p provisioners.ProvisionerInput) *EksManagedInstanceGroupContext {
 	return ctx
 }
 
+// checkWaitTimeout returns an error if a node group remained in an ongoing state for longer than the wait timeout, the
+// wait starts when the node group was last modified, or created if it was never modified
+func (ctx *EksManagedInstanceGroupContext) checkWaitTimeout(nodeGroup *eks.Nodegroup) error {
+	var (
+		state = aws.StringValue(nodeGroup.Status)
+		since = aws.TimeValue(nodeGroup.ModifiedAt)
+	)
+
+	if ctx.WaitTimeout <= 0 || !awsprovider.IsNodeGroupInConditionState(state, OngoingStateString) {
+		return nil
+	}
+	if since.IsZero() {
+		since = aws.TimeValue(nodeGroup.CreatedAt)
+	}
+	if since.IsZero() {
+		return nil
+	}
+	if elapsed := time.Since(since); elapsed > ctx.WaitTimeout {
+		return errors.Errorf("timed out waiting for managed node group in state %v, waited %v, timeout is %v", state, elapsed.Round(time.Second), ctx.WaitTimeout)
+	}
+	return nil
+}
+
 func (ctx *EksManagedInstanceGroupContext) processParameters() {
 	var (
 		instanceGroup = ctx.GetInstanceGroup()
@@ -258,5 +307,57 @@ func (ctx *EksManagedInstanceGroupContext) processParameters() {
 	params["Tags"] = configuration.Tags
 	params["MinSize"] = spec.GetMinSize()
 	params["MaxSize"] = spec.GetMaxSize()
+	params["Taints"] = ctx.getTaints()
+	params["UpdateConfig"] = ctx.getUpdateConfig()
 	ctx.AwsWorker.Parameters = params
 }
+
+// getTaints returns the desired node group taints, unset taints return nil which leaves existing taints alone, while an
+// empty list removes all taints
+func (ctx *EksManagedInstanceGroupContext) getTaints() []*eks.Taint {
+	var (
+		instanceGroup = ctx.GetInstanceGroup()
+		configuration = instanceGroup.GetEKSManagedConfiguration()
+		taints        = make([]*eks.Taint, 0)
+	)
+
+	if configuration.GetTaints() == nil {
+		return nil
+	}
+
+	for _, t := range configuration.GetTaints() {
+		taint := &eks.Taint{
+			Key:    aws.String(t.Key),
+			Effect: aws.String(NodeGroupTaintEffects[t.Effect]),
+		}
+		if t.Value != "" {
+			taint.Value = aws.String(t.Value)
+		}
+		taints = append(taints, taint)
+	}
+	return taints
+}
+
+func (ctx *EksManagedInstanceGroupContext) getUpdateConfig() *eks.NodegroupUpdateConfig {
+	var (
+		instanceGroup = ctx.GetInstanceGroup()
+		updateConfig  = instanceGroup.GetEKSManagedConfiguration().GetUpdateConfig()
+	)
+
+	if updateConfig == nil {
+		return nil
+	}
+
+	config := &eks.NodegroupUpdateConfig{}
+	if updateConfig.MaxUnavailable != 0 {
+		config.MaxUnavailable = aws.Int64(updateConfig.MaxUnavailable)
+	}
+	if updateConfig.MaxUnavailablePercentage != 0 {
+		config.MaxUnavailablePercentage = aws.Int64(updateConfig.MaxUnavailablePercentage)
+	}
+
+	if config.MaxUnavailable == nil && config.MaxUnavailablePercentage == nil {
+		return nil
+	}
+	return config
+}
@@ -31,6 +31,7 @@ import (
 	kubeprovider "github.com/keikoproj/instance-manager/controllers/providers/kubernetes"
 	"github.com/keikoproj/instance-manager/controllers/provisioners"
 	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -62,6 +63,8 @@ type stubEKS struct {
 	eksiface.EKSAPI
 	NodeGroup       *eks.Nodegroup
 	NodeGroupExists bool
+	CreateInput     *eks.CreateNodegroupInput
+	UpdateInput     *eks.UpdateNodegroupConfigInput
 }
 
 func (s *stubEKS) DescribeNodegroup(input *eks.DescribeNodegroupInput) (*eks.DescribeNodegroupOutput, error) {
@@ -75,11 +78,13 @@ func (s *stubEKS) DescribeNodegroup(input *eks.DescribeNodegroupInput) (*eks.Des
 }
 
 func (s *stubEKS) CreateNodegroup(input *eks.CreateNodegroupInput) (*eks.CreateNodegroupOutput, error) {
+	s.CreateInput = input
 	output := &eks.CreateNodegroupOutput{}
 	return output, nil
 }
 
 func (s *stubEKS) UpdateNodegroupConfig(input *eks.UpdateNodegroupConfigInput) (*eks.UpdateNodegroupConfigOutput, error) {
+	s.UpdateInput = input
 	output := &eks.UpdateNodegroupConfigOutput{}
 	return output, nil
 }
@@ -338,3 +343,278 @@ func TestStateDiscoveryDeleted(t *testing.T) {
 	}
 	testCase.Run(t)
 }
+
+func TestUpdateConfig(t *testing.T) {
+	var (
+		ig           = FakeIG{}
+		stub         = &stubEKS{}
+		nodeGroup    = getNodeGroup("ACTIVE")
+		kube         = kubeprovider.KubernetesClientSet{Kubernetes: fake.NewSimpleClientset()}
+		awsWorker    = awsprovider.AwsWorker{EksClient: stub}
+		updateConfig = &v1alpha1.EKSManagedUpdateConfig{MaxUnavailablePercentage: 25}
+	)
+
+	instanceGroup := ig.getInstanceGroup()
+	instanceGroup.GetEKSManagedConfiguration().UpdateConfig = updateConfig
+	nodeGroup.Labels = aws.StringMap(instanceGroup.GetEKSManagedConfiguration().NodeLabels)
+	nodeGroup.ScalingConfig.MinSize = aws.Int64(instanceGroup.Spec.EKSManagedSpec.GetMinSize())
+	nodeGroup.ScalingConfig.MaxSize = aws.Int64(instanceGroup.Spec.EKSManagedSpec.GetMaxSize())
+	nodeGroup.UpdateConfig = &eks.NodegroupUpdateConfig{MaxUnavailable: aws.Int64(1)}
+
+	ctx := New(provisioners.ProvisionerInput{
+		AwsWorker:     awsWorker,
+		Kubernetes:    kube,
+		InstanceGroup: instanceGroup,
+		Log:           ctrl.Log.WithName("unit-test").WithName("InstanceGroup"),
+	})
+
+	// create should pass the update config
+	if err := ctx.CloudDiscovery(); err != nil {
+		t.Fatal(err)
+	}
+	if err := ctx.Create(); err != nil {
+		t.Fatal(err)
+	}
+	if got := aws.Int64Value(stub.CreateInput.UpdateConfig.MaxUnavailablePercentage); got != 25 {
+		t.Fatalf("CreateNodegroup UpdateConfig.MaxUnavailablePercentage, expected: 25, got: %v", got)
+	}
+	if stub.CreateInput.UpdateConfig.MaxUnavailable != nil {
+		t.Fatalf("CreateNodegroup UpdateConfig.MaxUnavailable, expected: nil, got: %v", aws.Int64Value(stub.CreateInput.UpdateConfig.MaxUnavailable))
+	}
+
+	// update should pass the update config when it drifted from the node group
+	stub.NodeGroupExists = true
+	stub.NodeGroup = nodeGroup
+	if err := ctx.CloudDiscovery(); err != nil {
+		t.Fatal(err)
+	}
+	if err := ctx.Update(); err != nil {
+		t.Fatal(err)
+	}
+	if stub.UpdateInput == nil || stub.UpdateInput.UpdateConfig == nil {
+		t.Fatal("UpdateNodegroupConfig, expected update config to be passed")
+	}
+	if got := aws.Int64Value(stub.UpdateInput.UpdateConfig.MaxUnavailablePercentage); got != 25 {
+		t.Fatalf("UpdateNodegroupConfig UpdateConfig.MaxUnavailablePercentage, expected: 25, got: %v", got)
+	}
+
+	// update should not be needed once the node group matches
+	stub.UpdateInput = nil
+	nodeGroup.UpdateConfig = &eks.NodegroupUpdateConfig{MaxUnavailablePercentage: aws.Int64(25)}
+	if err := ctx.Update(); err != nil {
+		t.Fatal(err)
+	}
+	if stub.UpdateInput != nil {
+		t.Fatalf("UpdateNodegroupConfig, expected no update, got: %v", stub.UpdateInput)
+	}
+}
+
+func TestUpdateLabelsAndTaintsInPlace(t *testing.T) {
+	var (
+		ig        = FakeIG{}
+		stub      = &stubEKS{NodeGroupExists: true}
+		nodeGroup = getNodeGroup("ACTIVE")
+		kube      = kubeprovider.KubernetesClientSet{Kubernetes: fake.NewSimpleClientset()}
+		awsWorker = awsprovider.AwsWorker{EksClient: stub}
+	)
+
+	instanceGroup := ig.getInstanceGroup()
+	instanceGroup.GetEKSManagedConfiguration().Taints = []corev1.Taint{
+		{Key: "dedicated", Value: "infra", Effect: corev1.TaintEffectNoSchedule},
+	}
+	nodeGroup.Labels = aws.StringMap(map[string]string{"foo": "baz", "old": "label"})
+	nodeGroup.Taints = []*eks.Taint{
+		{Key: aws.String("dedicated"), Value: aws.String("infra"), Effect: aws.String(eks.TaintEffectNoSchedule)},
+	}
+	nodeGroup.ScalingConfig.MinSize = aws.Int64(instanceGroup.Spec.EKSManagedSpec.GetMinSize())
+	nodeGroup.ScalingConfig.MaxSize = aws.Int64(instanceGroup.Spec.EKSManagedSpec.GetMaxSize())
+	stub.NodeGroup = nodeGroup
+
+	ctx := New(provisioners.ProvisionerInput{
+		AwsWorker:     awsWorker,
+		Kubernetes:    kube,
+		InstanceGroup: instanceGroup,
+		Log:           ctrl.Log.WithName("unit-test").WithName("InstanceGroup"),
+	})
+
+	if err := ctx.CloudDiscovery(); err != nil {
+		t.Fatal(err)
+	}
+
+	// only labels changed, expect an in-place config update of the labels which keeps the scaling config
+	if err := ctx.Update(); err != nil {
+		t.Fatal(err)
+	}
+	if stub.UpdateInput == nil {
+		t.Fatal("UpdateNodegroupConfig, expected labels update")
+	}
+	if got := aws.StringValue(stub.UpdateInput.ClusterName); got != "EKS-Test" {
+		t.Fatalf("UpdateNodegroupConfig ClusterName, expected: EKS-Test, got: %v", got)
+	}
+	if got := stub.UpdateInput.ScalingConfig; aws.Int64Value(got.MinSize) != aws.Int64Value(nodeGroup.ScalingConfig.MinSize) || aws.Int64Value(got.MaxSize) != aws.Int64Value(nodeGroup.ScalingConfig.MaxSize) {
+		t.Fatalf("UpdateNodegroupConfig ScalingConfig, expected: %v, got: %v", nodeGroup.ScalingConfig, got)
+	}
+	if stub.UpdateInput.UpdateConfig != nil {
+		t.Fatalf("UpdateNodegroupConfig UpdateConfig, expected: nil, got: %v", stub.UpdateInput.UpdateConfig)
+	}
+	if got := instanceGroup.GetState(); got != v1alpha1.ReconcileModifying {
+		t.Fatalf("Update, expected state: %v, got: %v", v1alpha1.ReconcileModifying, got)
+	}
+	if got := aws.StringValueMap(stub.UpdateInput.Labels.AddOrUpdateLabels); got["foo"] != "bar" || len(got) != 1 {
+		t.Fatalf("UpdateNodegroupConfig AddOrUpdateLabels, expected: map[foo:bar], got: %v", got)
+	}
+	if got := aws.StringValueSlice(stub.UpdateInput.Labels.RemoveLabels); len(got) != 1 || got[0] != "old" {
+		t.Fatalf("UpdateNodegroupConfig RemoveLabels, expected: [old], got: %v", got)
+	}
+	if stub.UpdateInput.Taints != nil {
+		t.Fatalf("UpdateNodegroupConfig Taints, expected: nil, got: %v", stub.UpdateInput.Taints)
+	}
+
+	// taint value changed, expect the taint to be updated in place
+	stub.UpdateInput = nil
+	nodeGroup.Labels = aws.StringMap(instanceGroup.GetEKSManagedConfiguration().GetLabels())
+	nodeGroup.Taints[0].Value = aws.String("other")
+	if err := ctx.Update(); err != nil {
+		t.Fatal(err)
+	}
+	if stub.UpdateInput == nil || stub.UpdateInput.Taints == nil {
+		t.Fatal("UpdateNodegroupConfig, expected taints update")
+	}
+	if stub.UpdateInput.Labels != nil {
+		t.Fatalf("UpdateNodegroupConfig Labels, expected: nil, got: %v", stub.UpdateInput.Labels)
+	}
+	if got := stub.UpdateInput.Taints.AddOrUpdateTaints; len(got) != 1 || aws.StringValue(got[0].Key) != "dedicated" || aws.StringValue(got[0].Value) != "infra" || aws.StringValue(got[0].Effect) != eks.TaintEffectNoSchedule {
+		t.Fatalf("UpdateNodegroupConfig AddOrUpdateTaints, expected dedicated=infra:NO_SCHEDULE, got: %v", got)
+	}
+	if got := stub.UpdateInput.Taints.RemoveTaints; len(got) != 0 {
+		t.Fatalf("UpdateNodegroupConfig RemoveTaints, expected: [], got: %v", got)
+	}
+
+	// labels and taints match, expect no update
+	stub.UpdateInput = nil
+	nodeGroup.Taints[0].Value = aws.String("infra")
+	if err := ctx.Update(); err != nil {
+		t.Fatal(err)
+	}
+	if stub.UpdateInput != nil {
+		t.Fatalf("UpdateNodegroupConfig, expected no update, got: %v", stub.UpdateInput)
+	}
+	if got := instanceGroup.GetState(); got != v1alpha1.ReconcileModified {
+		t.Fatalf("Update, expected state: %v, got: %v", v1alpha1.ReconcileModified, got)
+	}
+}
+
+func TestUpdateUnsetTaintsLeavesNodeGroupTaints(t *testing.T) {
+	var (
+		ig        = FakeIG{}
+		stub      = &stubEKS{NodeGroupExists: true}
+		nodeGroup = getNodeGroup("ACTIVE")
+		kube      = kubeprovider.KubernetesClientSet{Kubernetes: fake.NewSimpleClientset()}
+		awsWorker = awsprovider.AwsWorker{EksClient: stub}
+	)
+
+	instanceGroup := ig.getInstanceGroup()
+	instanceGroup.GetEKSManagedConfiguration().Taints = nil
+	nodeGroup.Labels = aws.StringMap(instanceGroup.GetEKSManagedConfiguration().GetLabels())
+	nodeGroup.Taints = []*eks.Taint{
+		{Key: aws.String("external"), Value: aws.String("taint"), Effect: aws.String(eks.TaintEffectNoSchedule)},
+	}
+	nodeGroup.ScalingConfig.MinSize = aws.Int64(instanceGroup.Spec.EKSManagedSpec.GetMinSize())
+	nodeGroup.ScalingConfig.MaxSize = aws.Int64(instanceGroup.Spec.EKSManagedSpec.GetMaxSize())
+	stub.NodeGroup = nodeGroup
+
+	ctx := New(provisioners.ProvisionerInput{
+		AwsWorker:     awsWorker,
+		Kubernetes:    kube,
+		InstanceGroup: instanceGroup,
+		Log:           ctrl.Log.WithName("unit-test").WithName("InstanceGroup"),
+	})
+
+	if err := ctx.CloudDiscovery(); err != nil {
+		t.Fatal(err)
+	}
+
+	// unset taints, expect the existing node group taints to be left alone
+	if err := ctx.Update(); err != nil {
+		t.Fatal(err)
+	}
+	if stub.UpdateInput != nil {
+		t.Fatalf("UpdateNodegroupConfig, expected no update, got: %v", stub.UpdateInput)
+	}
+	if got := instanceGroup.GetState(); got != v1alpha1.ReconcileModified {
+		t.Fatalf("Update, expected state: %v, got: %v", v1alpha1.ReconcileModified, got)
+	}
+
+	// an empty list of taints, expect all node group taints to be removed
+	instanceGroup.GetEKSManagedConfiguration().Taints = []corev1.Taint{}
+	if err := ctx.Update(); err != nil {
+		t.Fatal(err)
+	}
+	if stub.UpdateInput == nil || stub.UpdateInput.Taints == nil {
+		t.Fatal("UpdateNodegroupConfig, expected taints update")
+	}
+	if got := stub.UpdateInput.Taints.AddOrUpdateTaints; len(got) != 0 {
+		t.Fatalf("UpdateNodegroupConfig AddOrUpdateTaints, expected: [], got: %v", got)
+	}
+	if got := stub.UpdateInput.Taints.RemoveTaints; len(got) != 1 || aws.StringValue(got[0].Key) != "external" {
+		t.Fatalf("UpdateNodegroupConfig RemoveTaints, expected: [external], got: %v", got)
+	}
+}
+
+func TestWaitIntervalAndTimeout(t *testing.T) {
+	var (
+		ig        = FakeIG{}
+		nodeGroup = getNodeGroup("CREATING")
+		stub      = &stubEKS{NodeGroupExists: true, NodeGroup: nodeGroup}
+		kube      = kubeprovider.KubernetesClientSet{Kubernetes: fake.NewSimpleClientset()}
+		awsWorker = awsprovider.AwsWorker{EksClient: stub}
+	)
+
+	input := provisioners.ProvisionerInput{
+		AwsWorker:     awsWorker,
+		Kubernetes:    kube,
+		InstanceGroup: ig.getInstanceGroup(),
+		Log:           ctrl.Log.WithName("unit-test").WithName("InstanceGroup"),
+	}
+
+	// the default interval is used when none is configured
+	ctx := New(input)
+	if got := ctx.GetRequeueInterval(); got != DefaultPollInterval {
+		t.Fatalf("GetRequeueInterval, expected: %v, got: %v", DefaultPollInterval, got)
+	}
+
+	input.ManagedPollInterval = 30 * time.Second
+	input.ManagedWaitTimeout = 20 * time.Minute
+	ctx = New(input)
+	if got := ctx.GetRequeueInterval(); got != 30*time.Second {
+		t.Fatalf("GetRequeueInterval, expected: %v, got: %v", 30*time.Second, got)
+	}
+
+	// a node group which is creating within the timeout keeps waiting
+	nodeGroup.CreatedAt = aws.Time(time.Now().Add(-10 * time.Minute))
+	if err := ctx.CloudDiscovery(); err != nil {
+		t.Fatal(err)
+	}
+	ctx.StateDiscovery()
+	if got := ctx.GetState(); got != v1alpha1.ReconcileModifying {
+		t.Fatalf("DiscoveredState, expected: %v, got: %v", v1alpha1.ReconcileModifying, got)
+	}
+
+	// the wait is measured from the last modification
+	nodeGroup.CreatedAt = aws.Time(time.Now().Add(-time.Hour))
+	nodeGroup.ModifiedAt = aws.Time(time.Now().Add(-time.Minute))
+	if err := ctx.CloudDiscovery(); err != nil {
+		t.Fatal(err)
+	}
+
+	nodeGroup.ModifiedAt = aws.Time(time.Now().Add(-30 * time.Minute))
+	if err := ctx.CloudDiscovery(); err == nil {
+		t.Fatal("CloudDiscovery, expected wait timeout error")
+	}
+
+	// finite states never time out
+	nodeGroup.Status = aws.String("ACTIVE")
+	if err := ctx.CloudDiscovery(); err != nil {
+		t.Fatal(err)
+	}
+}
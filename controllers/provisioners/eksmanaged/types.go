@@ -16,6 +16,8 @@ limitations under the License.
 package eksmanaged
 
 import (
+	"time"
+
 	"github.com/aws/aws-sdk-go/service/eks"
 	"github.com/go-logr/logr"
 	"github.com/keikoproj/instance-manager/api/instancemgr/v1alpha1"
@@ -34,6 +36,8 @@ type EksManagedInstanceGroupContext struct {
 	AwsWorker        aws.AwsWorker
 	DiscoveredState  *DiscoveredState
 	Log              logr.Logger
+	PollInterval     time.Duration
+	WaitTimeout      time.Duration
 }
 type DiscoveredState struct {
 	Provisioned   bool
@@ -72,6 +76,14 @@ func (ctx *EksManagedInstanceGroupContext) GetInstanceGroup() *v1alpha1.Instance
 	return &v1alpha1.InstanceGroup{}
 }
 
+// GetRequeueInterval returns how often a node group in an ongoing state is polled
+func (ctx *EksManagedInstanceGroupContext) GetRequeueInterval() time.Duration {
+	if ctx.PollInterval > 0 {
+		return ctx.PollInterval
+	}
+	return DefaultPollInterval
+}
+
 func (ctx *EksManagedInstanceGroupContext) GetState() v1alpha1.ReconcileState {
 	return ctx.InstanceGroup.GetState()
 }
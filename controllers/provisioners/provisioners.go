@@ -1,6 +1,10 @@
 package provisioners
 
 import (
+	"encoding/json"
+	"strings"
+	"time"
+
 	"github.com/go-logr/logr"
 	"github.com/keikoproj/instance-manager/api/instancemgr/v1alpha1"
 	corev1 "k8s.io/api/core/v1"
@@ -24,8 +28,21 @@ const (
 
 	ConfigurationExclusionAnnotationKey = "instancemgr.keikoproj.io/config-excluded"
 	UpgradeLockedAnnotationKey          = "instancemgr.keikoproj.io/lock-upgrades"
+	LogLevelAnnotationKey               = "instancemgr.keikoproj.io/log-level"
+
+	LogLevelDebug = "debug"
 )
 
+// ReconcileRecord is the bookkeeping of the last full reconcile of an instance group used by the reconcile shortcut, it is
+// kept in memory by the controller since persisting it in status would trigger another reconcile
+type ReconcileRecord struct {
+	Hash      string
+	Time      time.Time
+	Shortcuts int
+	// Desired is the desired capacity discovered by the full reconcile, shortcut reconciles report it as is
+	Desired int
+}
+
 type ProvisionerInput struct {
 	AwsWorker                  awsprovider.AwsWorker
 	Kubernetes                 kubeprovider.KubernetesClientSet
@@ -35,6 +52,16 @@ type ProvisionerInput struct {
 	ConfigRetention            int
 	Metrics                    *common.MetricsCollector
 	DisableWinClusterInjection bool
+	NodeRelabel                bool
+	SpotRecommendationSource   kubeprovider.SpotRecommendationSource
+	ReconcileShortcutInterval  time.Duration
+	ReconcileShortcutCount     int
+	LastReconcile              ReconcileRecord
+	NodeReadinessTimeout       time.Duration
+	EnableUserDataDump         bool
+	LegacyRoleLabelCutoff      string
+	ManagedPollInterval        time.Duration
+	ManagedWaitTimeout         time.Duration
 }
 
 var (
@@ -49,3 +76,63 @@ func IsRetryable(instanceGroup *v1alpha1.InstanceGroup) bool {
 	}
 	return true
 }
+
+// GetReconcileHash returns a hash of the desired state of an instance group, a change of the spec, annotations or the
+// configmap hash results in a different hash
+func GetReconcileHash(instanceGroup *v1alpha1.InstanceGroup) string {
+	desired := struct {
+		Spec        v1alpha1.InstanceGroupSpec
+		Annotations map[string]string
+		ConfigHash  string
+	}{
+		Spec:        instanceGroup.Spec,
+		Annotations: instanceGroup.GetAnnotations(),
+		ConfigHash:  instanceGroup.GetStatus().GetConfigHash(),
+	}
+	data, err := json.Marshal(desired)
+	if err != nil {
+		return ""
+	}
+	return common.StringMD5(string(data))
+}
+
+// GetInstanceGroupLogger returns the logger used to reconcile an instance group, instance groups annotated with a debug
+// log-level emit their verbose logs regardless of the controller's log level
+func GetInstanceGroupLogger(logger logr.Logger, instanceGroup *v1alpha1.InstanceGroup) logr.Logger {
+	if !strings.EqualFold(instanceGroup.GetAnnotations()[LogLevelAnnotationKey], LogLevelDebug) {
+		return logger
+	}
+
+	sink := logger.GetSink()
+	if sink == nil {
+		return logger
+	}
+	// account for the extra frame of the wrapping sink when reporting the caller
+	if s, ok := sink.(logr.CallDepthLogSink); ok {
+		sink = s.WithCallDepth(1)
+	}
+	return logger.WithSink(verboseLogSink{sink})
+}
+
+// verboseLogSink logs messages of any verbosity at the verbosity of the wrapped sink's info messages
+type verboseLogSink struct {
+	logr.LogSink
+}
+
+func (s verboseLogSink) Init(info logr.RuntimeInfo) {}
+
+func (s verboseLogSink) Enabled(level int) bool {
+	return s.LogSink.Enabled(0)
+}
+
+func (s verboseLogSink) Info(level int, msg string, keysAndValues ...interface{}) {
+	s.LogSink.Info(0, msg, keysAndValues...)
+}
+
+func (s verboseLogSink) WithValues(keysAndValues ...interface{}) logr.LogSink {
+	return verboseLogSink{s.LogSink.WithValues(keysAndValues...)}
+}
+
+func (s verboseLogSink) WithName(name string) logr.LogSink {
+	return verboseLogSink{s.LogSink.WithName(name)}
+}
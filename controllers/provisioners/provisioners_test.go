@@ -0,0 +1,74 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provisioners
+
+import (
+	"testing"
+
+	"github.com/go-logr/logr/funcr"
+	"github.com/keikoproj/instance-manager/api/instancemgr/v1alpha1"
+	"github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestGetInstanceGroupLogger(t *testing.T) {
+	var (
+		g        = gomega.NewGomegaWithT(t)
+		messages []string
+	)
+
+	// controller logger only emits info level messages
+	logger := funcr.New(func(prefix, args string) {
+		messages = append(messages, args)
+	}, funcr.Options{Verbosity: 0})
+
+	instanceGroup := &v1alpha1.InstanceGroup{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "instance-group-1",
+			Namespace:   "instance-manager",
+			Annotations: map[string]string{},
+		},
+	}
+
+	tests := []struct {
+		annotation      string
+		expectedVerbose bool
+	}{
+		{annotation: "", expectedVerbose: false},
+		{annotation: "info", expectedVerbose: false},
+		{annotation: LogLevelDebug, expectedVerbose: true},
+		{annotation: "DEBUG", expectedVerbose: true},
+	}
+
+	for i, tc := range tests {
+		t.Logf("test #%v - %+v", i, tc)
+		messages = nil
+		instanceGroup.Annotations[LogLevelAnnotationKey] = tc.annotation
+
+		log := GetInstanceGroupLogger(logger, instanceGroup).WithName("eks").WithValues("instancegroup", instanceGroup.NamespacedName())
+		g.Expect(log.V(1).Enabled()).To(gomega.Equal(tc.expectedVerbose))
+
+		log.V(1).Info("verbose message")
+		log.Info("info message")
+		if tc.expectedVerbose {
+			g.Expect(messages).To(gomega.HaveLen(2))
+			g.Expect(messages[0]).To(gomega.ContainSubstring("verbose message"))
+		} else {
+			g.Expect(messages).To(gomega.HaveLen(1))
+			g.Expect(messages[0]).To(gomega.ContainSubstring("info message"))
+		}
+	}
+}
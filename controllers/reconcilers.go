@@ -24,6 +24,7 @@ import (
 	"time"
 
 	v1alpha1 "github.com/keikoproj/instance-manager/api/instancemgr/v1alpha1"
+	"github.com/keikoproj/instance-manager/controllers/common"
 	awsprovider "github.com/keikoproj/instance-manager/controllers/providers/aws"
 	kubeprovider "github.com/keikoproj/instance-manager/controllers/providers/kubernetes"
 	"github.com/keikoproj/instance-manager/controllers/provisioners"
@@ -235,6 +236,13 @@ func (r *InstanceGroupReconciler) nodeReconciler(obj client.Object) []ctrl.Reque
 	return nil
 }
 
+func (r *InstanceGroupReconciler) spotRecommendationSource() kubeprovider.SpotRecommendationSource {
+	if r.SpotRecommendationSource.Reason == "" {
+		return kubeprovider.DefaultSpotRecommendationSource
+	}
+	return r.SpotRecommendationSource
+}
+
 func (r *InstanceGroupReconciler) spotEventReconciler(obj client.Object) []ctrl.Request {
 	unstructuredObj, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
 	if err != nil {
@@ -242,7 +250,11 @@ func (r *InstanceGroupReconciler) spotEventReconciler(obj client.Object) []ctrl.
 	}
 
 	if reason, ok, _ := unstructured.NestedString(unstructuredObj, "reason"); ok {
-		if reason != kubeprovider.SpotRecommendationReason {
+		if reason == kubeprovider.SpotInterruptionReason {
+			return r.spotInterruptionReconciler(unstructuredObj)
+		}
+		involvedObjectKind, _, _ := unstructured.NestedString(unstructuredObj, "involvedObject", "kind")
+		if !r.spotRecommendationSource().Matches(reason, involvedObjectKind) {
 			return nil
 		}
 	} else {
@@ -284,3 +296,43 @@ func (r *InstanceGroupReconciler) spotEventReconciler(obj client.Object) []ctrl.
 		},
 	}
 }
+
+func (r *InstanceGroupReconciler) spotInterruptionReconciler(unstructuredObj map[string]interface{}) []ctrl.Request {
+	nodeName, exists, err := unstructured.NestedString(unstructuredObj, "involvedObject", "name")
+	if err != nil || !exists {
+		r.Log.Error(err, "failed to process v1.event", "reason", kubeprovider.SpotInterruptionReason)
+		return nil
+	}
+
+	node, err := r.Auth.Kubernetes.Kubernetes.CoreV1().Nodes().Get(context.Background(), nodeName, metav1.GetOptions{})
+	if err != nil {
+		if !kerrors.IsNotFound(err) {
+			r.Log.Error(err, "failed to get interrupted node", "node", nodeName)
+		}
+		return nil
+	}
+
+	ctrl.Log.Info(fmt.Sprintf("spot interruption warning for node %v", nodeName))
+
+	instanceId := common.GetLastElementBy(node.Spec.ProviderID, "/")
+	tags, err := awsprovider.GetScalingGroupTagsByInstanceId(instanceId, r.Auth.Aws.AsgClient)
+	if err != nil {
+		return nil
+	}
+
+	instanceGroup := types.NamespacedName{}
+	instanceGroup.Name = awsprovider.GetTagValueByKey(tags, provisioners.TagInstanceGroupName)
+	instanceGroup.Namespace = awsprovider.GetTagValueByKey(tags, provisioners.TagInstanceGroupNamespace)
+	if instanceGroup.Name == "" || instanceGroup.Namespace == "" {
+		return nil
+	}
+
+	// interruptions are counted during cloud discovery, which the reconcile shortcut would skip
+	r.DeleteReconcileRecord(instanceGroup.String())
+
+	return []ctrl.Request{
+		{
+			NamespacedName: instanceGroup,
+		},
+	}
+}
@@ -21,13 +21,23 @@ import (
 	"os"
 	runt "runtime"
 	"sync"
+	"time"
 
+	"github.com/Masterminds/semver"
 	"github.com/keikoproj/aws-sdk-go-cache/cache"
 	instancemgrv1alpha1 "github.com/keikoproj/instance-manager/api/instancemgr/v1alpha1"
 	"github.com/keikoproj/instance-manager/controllers"
 	"github.com/keikoproj/instance-manager/controllers/common"
 	"github.com/keikoproj/instance-manager/controllers/providers/aws"
 	kubeprovider "github.com/keikoproj/instance-manager/controllers/providers/kubernetes"
+	"github.com/keikoproj/instance-manager/controllers/provisioners/eks"
+	"github.com/keikoproj/instance-manager/controllers/provisioners/eksmanaged"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
 	corev1 "k8s.io/api/core/v1"
 	kerrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -68,27 +78,57 @@ func main() {
 		metricsAddr                 string
 		configNamespace             string
 		spotRecommendationTime      float64
+		spotRecommendationReason    string
+		spotRecommendationKind      string
 		enableLeaderElection        bool
 		nodeRelabel                 bool
 		disableWinClusterInjection  bool
+		enableTracing               bool
 		maxParallel                 int
 		maxAPIRetries               int
 		configRetention             int
+		waiterRetries               int
+		waiterDuration              time.Duration
+		propagationDelay            time.Duration
+		shortcutInterval            time.Duration
+		shortcutCount               int
+		throttleBackoff             time.Duration
+		nodeReadinessTimeout        time.Duration
+		legacyRoleLabelCutoff       string
+		managedPollInterval         time.Duration
+		managedWaitTimeout          time.Duration
 		err                         error
 		defaultScalingConfiguration string
+		defaultProfileNamePrefix    string
+		enableUserDataDump          bool
 	)
 
 	flag.IntVar(&maxParallel, "max-workers", 5, "The number of maximum parallel reconciles")
 	flag.IntVar(&maxAPIRetries, "max-api-retries", 12, "The number of maximum retries for failed AWS API calls")
+	flag.IntVar(&waiterRetries, "waiter-retries", aws.DefaultWaiterRetries, "The number of retries when waiting for AWS resources to become ready")
+	flag.DurationVar(&waiterDuration, "waiter-duration", aws.DefaultWaiterDuration, "The interval between retries when waiting for AWS resources to become ready")
+	flag.DurationVar(&propagationDelay, "instance-profile-propagation-delay", aws.DefaultInstanceProfilePropagationDelay, "The time to wait for a newly created IAM instance-profile to propagate before using it")
 	flag.IntVar(&configRetention, "config-retention", 2, "The number of launch configuration/template versions to retain")
+	flag.DurationVar(&shortcutInterval, "reconcile-shortcut-interval", 0, "Skip cloud discovery of unchanged instance groups with ready nodes for this long after a full reconcile, disabled when 0")
+	flag.IntVar(&shortcutCount, "reconcile-shortcut-count", 10, "The number of consecutive reconciles which may skip cloud discovery before a full reconcile detects drift")
+	flag.DurationVar(&nodeReadinessTimeout, "node-readiness-timeout", 0, "The time the nodes of an instance group may remain not ready before it transitions to an error state, waits indefinitely when 0")
+	flag.DurationVar(&throttleBackoff, "throttle-backoff", controllers.DefaultThrottleBackoff, "The base delay before an instance group whose reconcile was throttled by AWS is requeued, doubled for every consecutive throttled reconcile")
+	flag.StringVar(&legacyRoleLabelCutoff, "legacy-role-label-cutoff-version", eks.DefaultLegacyRoleLabelCutoff, "Nodes of clusters running a kubernetes version below this version are labeled with the old style node-role.kubernetes.io/<name> label")
+	flag.DurationVar(&managedPollInterval, "managed-nodegroup-poll-interval", eksmanaged.DefaultPollInterval, "The interval between reconciles of eks-managed node groups which are creating, updating or deleting")
+	flag.DurationVar(&managedWaitTimeout, "managed-nodegroup-wait-timeout", 0, "The time an eks-managed node group may remain creating, updating or deleting before the instance group errors, waits indefinitely when 0")
 	flag.Float64Var(&spotRecommendationTime, "spot-recommendation-time", 10.0, "The maximum age of spot recommendation events to consider in minutes")
+	flag.StringVar(&spotRecommendationReason, "spot-recommendation-reason", kubeprovider.SpotRecommendationReason, "The reason of events published by the spot recommendation controller")
+	flag.StringVar(&spotRecommendationKind, "spot-recommendation-object-kind", "", "The involved object kind of spot recommendation events, events of any kind are considered when empty")
 	flag.StringVar(&configNamespace, "config-namespace", "instance-manager", "the namespace to watch for instance-manager configmap")
 	flag.StringVar(&metricsAddr, "metrics-addr", ":8080", "The address the metric endpoint binds to.")
 	flag.BoolVar(&enableLeaderElection, "enable-leader-election", false,
 		"Enable leader election for controller manager. Enabling this will ensure there is only one active controller manager.")
-	flag.BoolVar(&nodeRelabel, "node-relabel", true, "relabel nodes as they join with kubernetes.io/role label via controller")
-	flag.BoolVar(&disableWinClusterInjection, "disable-windows-cluster-ca-injection", false, "Setting this to true will cause the ClusterCA and Endpoint to not be injected for Windows nodes")
+	flag.BoolVar(&nodeRelabel, "node-relabel", true, "relabel nodes as they join with kubernetes.io/role label, and reconcile the lifecycle and instance-type labels of instance group nodes via controller")
+	flag.BoolVar(&disableWinClusterInjection, "disable-windows-cluster-ca-injection", false, "Setting this to true will cause the ClusterCA, Endpoint and DNS cluster IP to not be injected for Windows nodes")
 	flag.StringVar(&defaultScalingConfiguration, "default-scaling-configuration", "LaunchTemplate", "By default ASGs will have LaunchTemplate. Set this string to either 'LaunchConfiguration' or 'LaunchTemplate' to enforce defaults.")
+	flag.StringVar(&defaultProfileNamePrefix, "default-instance-profile-name-prefix", "", "The prefix prepended to the name of controller-created instance-profiles of instance groups which do not set instanceProfileNamePrefix")
+	flag.BoolVar(&enableUserDataDump, "enable-userdata-dump", false, "Allow instance groups to publish their rendered userData as an event with the instancemgr.keikoproj.io/dump-userdata annotation, secret-like values are redacted")
+	flag.BoolVar(&enableTracing, "enable-tracing", false, "Export OpenTelemetry traces of reconcile phases, the OTLP/HTTP exporter is configured with the standard OTEL_EXPORTER_OTLP_* environment variables")
 	flag.Parse()
 	ctrl.SetLogger(zap.New(zap.UseDevMode(true)))
 
@@ -102,6 +142,17 @@ func main() {
 		os.Exit(1)
 	}
 
+	spotRecommendationSource, err := kubeprovider.NewSpotRecommendationSource(spotRecommendationReason, spotRecommendationKind)
+	if err != nil {
+		setupLog.Error(err, "invalid spot recommendation configuration")
+		os.Exit(1)
+	}
+
+	if _, err := semver.NewVersion(legacyRoleLabelCutoff); err != nil {
+		setupLog.Error(err, "invalid legacy role label cutoff version", "version", legacyRoleLabelCutoff)
+		os.Exit(1)
+	}
+
 	metadata := aws.GetAwsEc2MetadataClient()
 	awsRegion, err := aws.GetRegion(metadata)
 	if err != nil {
@@ -131,6 +182,10 @@ func main() {
 		EksClient:   aws.GetAwsEksClient(awsRegion, cacheCfg, maxAPIRetries, controllerCollector),
 		SsmClient:   aws.GetAwsSsmClient(awsRegion, cacheCfg, maxAPIRetries, controllerCollector),
 		Ec2Metadata: metadata,
+
+		InstanceProfilePropagationDelay: propagationDelay,
+		WaiterDuration:                  waiterDuration,
+		WaiterRetries:                   waiterRetries,
 	}
 
 	metrics.Registry.MustRegister(cacheCollector, controllerCollector)
@@ -150,12 +205,44 @@ func main() {
 		setupLog.Info("instance-manager configmap does not exist, will not load defaults/boundaries")
 	}
 
+	var tracerProvider trace.TracerProvider = noop.NewTracerProvider()
+	if enableTracing {
+		exporter, err := otlptracehttp.New(context.Background())
+		if err != nil {
+			setupLog.Error(err, "unable to create trace exporter")
+			os.Exit(1)
+		}
+		traceResource, err := resource.New(context.Background(),
+			resource.WithAttributes(
+				attribute.String("service.name", "instance-manager"),
+				attribute.String("service.version", controllerVersion),
+			),
+			resource.WithFromEnv(),
+		)
+		if err != nil {
+			setupLog.Error(err, "unable to create trace resource")
+			os.Exit(1)
+		}
+		sdkTracerProvider := sdktrace.NewTracerProvider(
+			sdktrace.WithBatcher(exporter),
+			sdktrace.WithResource(traceResource),
+		)
+		defer func() {
+			if err := sdkTracerProvider.Shutdown(context.Background()); err != nil {
+				setupLog.Error(err, "failed to flush traces")
+			}
+		}()
+		tracerProvider = sdkTracerProvider
+		setupLog.Info("tracing enabled")
+	}
+
 	defaultScalingConfigurationType := instancemgrv1alpha1.ScalingConfigurationType(defaultScalingConfiguration)
 	err = (&controllers.InstanceGroupReconciler{
 		Metrics:                     controllerCollector,
 		ConfigMap:                   cm,
 		ConfigRetention:             configRetention,
 		SpotRecommendationTime:      spotRecommendationTime,
+		SpotRecommendationSource:    spotRecommendationSource,
 		ConfigNamespace:             configNamespace,
 		Namespaces:                  make(map[string]corev1.Namespace),
 		NamespacesLock:              &sync.RWMutex{},
@@ -165,6 +252,16 @@ func main() {
 		Log:                         ctrl.Log.WithName("controllers").WithName("instancegroup"),
 		MaxParallel:                 maxParallel,
 		DefaultScalingConfiguration: &defaultScalingConfigurationType,
+		DefaultProfileNamePrefix:    defaultProfileNamePrefix,
+		EnableUserDataDump:          enableUserDataDump,
+		ReconcileShortcutInterval:   shortcutInterval,
+		ReconcileShortcutCount:      shortcutCount,
+		ThrottleBackoff:             throttleBackoff,
+		NodeReadinessTimeout:        nodeReadinessTimeout,
+		LegacyRoleLabelCutoff:       legacyRoleLabelCutoff,
+		ManagedPollInterval:         managedPollInterval,
+		ManagedWaitTimeout:          managedWaitTimeout,
+		Tracer:                      tracerProvider.Tracer(controllers.TracerName),
 		Auth: &controllers.InstanceGroupAuthenticator{
 			Aws:        awsWorker,
 			Kubernetes: kube,